@@ -1,8 +1,11 @@
 package main
 
-import "github.com/wlchs/blog/internal/app"
+import (
+	"github.com/wlchs/blog/internal/app"
+	"os"
+)
 
-// main entry point
+// main entry point. Starts the blog engine or runs the command provided as the first argument.
 func main() {
-	app.Run()
+	os.Exit(app.Execute(os.Args[1:]))
 }
@@ -1,12 +1,20 @@
 package app
 
 import (
+	"fmt"
 	"github.com/wlchs/blog/internal/container"
 	"github.com/wlchs/blog/internal/controller"
 	"github.com/wlchs/blog/internal/db"
+	"github.com/wlchs/blog/internal/events"
 	"github.com/wlchs/blog/internal/jwt"
 	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mailer"
 	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/storage"
+	"go.uber.org/zap"
+	"os"
+	"strconv"
+	"time"
 )
 
 // Run initializes the application:
@@ -16,18 +24,149 @@ import (
 // - Bind application routes
 func Run() {
 	log := logger.CreateLogger()
+	cont := createContainer(log)
+
+	controller.CreateRoutes(cont)
+}
+
+// createContainer connects to the database and the file storage and creates the application container.
+func createContainer(log *zap.SugaredLogger) container.Container {
 	database := db.ConnectToMySQL()
 	rep := repository.CreateRepository(database)
+	analyticsRepository := repository.CreateAnalyticsRepository(log, rep)
+	collectionRepository := repository.CreateCollectionRepository(log, rep)
+	federationRepository := repository.CreateFederationRepository(log, rep)
+	fieldRepository := repository.CreateFieldRepository(log, rep)
+	mediaRepository := repository.CreateMediaRepository(log, rep)
+	newsletterRepository := repository.CreateNewsletterRepository(log, rep)
 	postRepository := repository.CreatePostRepository(log, rep)
+	reactionRepository := repository.CreateReactionRepository(log, rep)
+	relatedRepository := repository.CreateRelatedRepository(log, rep)
+	seriesRepository := repository.CreateSeriesRepository(log, rep)
 	userRepository := repository.CreateUserRepository(log, rep)
+	webhookRepository := repository.CreateWebhookRepository(log, rep)
+	webmentionRepository := repository.CreateWebmentionRepository(log, rep)
+	outboxRepository := repository.CreateOutboxRepository(log, rep)
 	jwtUtils := jwt.CreateTokenUtils(log)
+	fileStorage := createStorage(log)
 
-	cont := container.CreateContainer(
+	return container.CreateContainer(
 		log,
+		analyticsRepository,
+		collectionRepository,
+		federationRepository,
+		fieldRepository,
+		mediaRepository,
+		newsletterRepository,
 		postRepository,
+		reactionRepository,
+		relatedRepository,
+		seriesRepository,
 		userRepository,
+		webhookRepository,
+		webmentionRepository,
 		jwtUtils,
+		fileStorage,
+		events.CreateBus(log, outboxRepository),
+		createMailer(log),
 	)
+}
 
-	controller.CreateRoutes(cont)
+// createStorage instantiates the file storage selected by the STORAGE_DRIVER environment variable.
+// Files are kept in the local filesystem by default, "s3" selects an S3-compatible object storage,
+// which is required when running multiple instances of the blog engine.
+func createStorage(log *zap.SugaredLogger) storage.Storage {
+	switch driver := os.Getenv("STORAGE_DRIVER"); driver {
+	case "", "local":
+		return storage.CreateLocalStorage(log, mediaPath())
+
+	case "s3":
+		return storage.CreateS3Storage(log, s3Config(log))
+
+	default:
+		log.Fatalf("unknown storage driver: %s", driver)
+		return nil
+	}
+}
+
+// createMailer instantiates the mailer selected by the MAILER_DRIVER environment variable.
+// Emails are only stored in a local outbox and written to the log by default, "smtp" delivers them using an SMTP server.
+func createMailer(log *zap.SugaredLogger) mailer.Mailer {
+	switch driver := os.Getenv("MAILER_DRIVER"); driver {
+	case "", "outbox":
+		return mailer.CreateOutbox(log)
+
+	case "smtp":
+		return mailer.CreateSMTPMailer(log, smtpConfig(log))
+
+	default:
+		log.Fatalf("unknown mailer driver: %s", driver)
+		return nil
+	}
+}
+
+// mediaPath returns the directory of the local file storage.
+// It can be configured using the MEDIA_PATH environment variable, defaults to the media directory of the working directory.
+func mediaPath() string {
+	if p := os.Getenv("MEDIA_PATH"); p != "" {
+		return p
+	}
+	return "media"
+}
+
+// s3Config reads the connection settings of the object storage from the S3_* environment variables.
+func s3Config(log *zap.SugaredLogger) storage.S3Config {
+	config := storage.S3Config{
+		Endpoint:  os.Getenv("S3_ENDPOINT"),
+		Region:    os.Getenv("S3_REGION"),
+		Bucket:    os.Getenv("S3_BUCKET"),
+		AccessKey: os.Getenv("S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("S3_SECRET_KEY"),
+		PathStyle: os.Getenv("S3_PATH_STYLE") == "true",
+	}
+
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", config.Region)
+	}
+	if config.Bucket == "" {
+		log.Fatal("S3_BUCKET must be set when using the s3 storage driver")
+	}
+
+	if expiry := os.Getenv("S3_PRESIGN_EXPIRY"); expiry != "" {
+		d, err := time.ParseDuration(expiry)
+		if err != nil {
+			log.Fatalf("invalid S3_PRESIGN_EXPIRY: %v", err)
+		}
+		config.PresignExpiry = d
+	}
+
+	return config
+}
+
+// smtpConfig reads the connection settings of the SMTP server from the SMTP_* environment variables.
+func smtpConfig(log *zap.SugaredLogger) mailer.SMTPConfig {
+	config := mailer.SMTPConfig{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     587,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("MAIL_FROM"),
+	}
+
+	if config.Host == "" || config.From == "" {
+		log.Fatal("SMTP_HOST and MAIL_FROM must be set when using the smtp mailer driver")
+	}
+
+	if port := os.Getenv("SMTP_PORT"); port != "" {
+		p, err := strconv.Atoi(port)
+		if err != nil {
+			log.Fatalf("invalid SMTP_PORT: %v", err)
+		}
+		config.Port = p
+	}
+
+	return config
 }
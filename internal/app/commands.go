@@ -0,0 +1,242 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/storage"
+	"github.com/wlchs/blog/internal/types"
+	"io"
+	"os"
+)
+
+// usage describes the available commands.
+const usage = `Usage: blog [command]
+
+Commands:
+  serve               Start the blog engine (default)
+  import <directory>  Import the Markdown files of the directory as posts
+  export <directory>  Export every post to the local directory as Markdown files
+  import-wordpress <file>
+                      Import the posts and authors of a WordPress export file
+  build [-full] <directory>
+                      Render the public posts, pages and feeds into the local directory as a static site
+`
+
+// Execute runs the command selected by the command-line arguments and returns the exit code of the process.
+// Without a command the blog engine is started.
+func Execute(args []string) int {
+	if len(args) == 0 {
+		Run()
+		return 0
+	}
+
+	switch command, params := args[0], args[1:]; command {
+	case "serve":
+		Run()
+		return 0
+
+	case "import":
+		return runImport(params, os.Stdout, os.Stderr)
+
+	case "export":
+		return runExport(params, os.Stdout, os.Stderr)
+
+	case "import-wordpress":
+		return runWordPressImport(params, os.Stdout, os.Stderr)
+
+	case "build":
+		return runBuild(params, os.Stdout, os.Stderr)
+
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(os.Stdout, usage)
+		return 0
+
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n%s", command, usage)
+		return 2
+	}
+}
+
+// runImport imports a directory of Markdown files with YAML front matter.
+// The exit code is non-zero if any of the files had to be skipped or the related posts couldn't be refreshed.
+func runImport(params []string, stdout io.Writer, stderr io.Writer) int {
+	dir, ok := parseArgument("import", "<directory>", params, stderr)
+	if !ok {
+		return 2
+	}
+
+	log := logger.CreateLogger()
+	cont := createContainer(log)
+	postService := services.CreatePostService(cont)
+	// The imported posts are announced to the subscribers of the events, e.g. webhooks, the newsletter, the followers
+	// and the pages linked from the posts
+	services.CreateEventSubscribers(cont, postService)
+	// The user service ensures that the main user, the default author of the posts, exists
+	services.CreateUserService(cont)
+	markdownService := services.CreateMarkdownService(cont, postService)
+
+	report, err := markdownService.ImportPosts(dir)
+	if err != nil {
+		fmt.Fprintf(stderr, "import failed: %v\n", err)
+		return 1
+	}
+
+	printImportReport(stdout, report)
+	if !refreshRelatedPosts(cont, report, stderr) || len(report.Skipped) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runExport writes every post to a local directory as Markdown files with YAML front matter.
+// The files are only readable by the owner, since they contain private drafts and password hashes.
+func runExport(params []string, stdout io.Writer, stderr io.Writer) int {
+	dir, ok := parseArgument("export", "<directory>", params, stderr)
+	if !ok {
+		return 2
+	}
+
+	log := logger.CreateLogger()
+	cont := createContainer(log)
+	markdownService := services.CreateMarkdownService(cont, services.CreatePostService(cont))
+
+	exported, err := markdownService.ExportPosts(storage.CreateLocalStorage(log, dir))
+	if err != nil {
+		fmt.Fprintf(stderr, "export failed: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(stdout, "exported %d posts to %s\n", exported, dir)
+	return 0
+}
+
+// runWordPressImport imports the posts and authors of a WordPress eXtended RSS export file.
+// The exit code is non-zero if any of the posts had to be skipped or conflicts with an existing one, or the related posts
+// couldn't be refreshed.
+func runWordPressImport(params []string, stdout io.Writer, stderr io.Writer) int {
+	file, ok := parseArgument("import-wordpress", "<file>", params, stderr)
+	if !ok {
+		return 2
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		fmt.Fprintf(stderr, "import failed: %v\n", err)
+		return 1
+	}
+	defer f.Close()
+
+	log := logger.CreateLogger()
+	cont := createContainer(log)
+	postService := services.CreatePostService(cont)
+	services.CreateEventSubscribers(cont, postService)
+	wordPressService := services.CreateWordPressService(cont, postService)
+
+	report, err := wordPressService.ImportWXR(f)
+	if err != nil {
+		fmt.Fprintf(stderr, "import failed: %v\n", err)
+		return 1
+	}
+
+	printImportReport(stdout, report)
+	if !refreshRelatedPosts(cont, report, stderr) || len(report.Skipped) > 0 || len(report.Conflicts) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runBuild renders the static site into a local directory, whose files are readable by everyone, so it can be served as is.
+// Unless the -full flag is set, only the pages of the posts updated since the previous build are rendered again.
+func runBuild(params []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("build", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	full := flags.Bool("full", false, "render every page, even if it didn't change since the previous build")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: blog build [-full] <directory>")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(params); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	dir := flags.Arg(0)
+
+	log := logger.CreateLogger()
+	cont := createContainer(log)
+	siteService := services.CreateSiteService(cont, services.CreatePostService(cont))
+
+	report, err := siteService.Build(storage.CreateLocalStorageWithMode(log, dir, 0o644), *full)
+	if err != nil {
+		fmt.Fprintf(stderr, "build failed: %v\n", err)
+		return 1
+	}
+
+	for _, file := range report.Written {
+		fmt.Fprintf(stdout, "written  %s\n", file)
+	}
+	for _, file := range report.Removed {
+		fmt.Fprintf(stdout, "removed  %s\n", file)
+	}
+	fmt.Fprintf(stdout, "%d written, %d removed, %d unchanged\n", len(report.Written), len(report.Removed), report.Unchanged)
+	return 0
+}
+
+// parseArgument parses the parameters of the commands expecting a single argument.
+func parseArgument(command string, argument string, params []string, stderr io.Writer) (string, bool) {
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: blog %s %s\n", command, argument)
+	}
+
+	if err := flags.Parse(params); err != nil {
+		return "", false
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return "", false
+	}
+
+	return flags.Arg(0), true
+}
+
+// refreshRelatedPosts recomputes the related posts after posts were imported and reports whether it succeeded.
+// The blog engine only refreshes them in the background, which would never happen before the command exits.
+func refreshRelatedPosts(cont container.Container, report types.ImportReport, stderr io.Writer) bool {
+	if len(report.Created) == 0 && len(report.Updated) == 0 {
+		return true
+	}
+
+	if err := services.CreateRelatedService(cont).RefreshRelatedPosts(); err != nil {
+		fmt.Fprintf(stderr, "failed to refresh related posts: %v\n", err)
+		return false
+	}
+	return true
+}
+
+// printImportReport writes the result of an import in a human-readable form.
+func printImportReport(w io.Writer, report types.ImportReport) {
+	for _, userName := range report.CreatedUsers {
+		fmt.Fprintf(w, "user     %s (disabled)\n", userName)
+	}
+	for _, handle := range report.Created {
+		fmt.Fprintf(w, "created  %s\n", handle)
+	}
+	for _, handle := range report.Updated {
+		fmt.Fprintf(w, "updated  %s\n", handle)
+	}
+	for _, issue := range report.Conflicts {
+		fmt.Fprintf(w, "conflict %s: %s\n", issue.Item, issue.Reason)
+	}
+	for _, issue := range report.Skipped {
+		fmt.Fprintf(w, "skipped  %s: %s\n", issue.Item, issue.Reason)
+	}
+	fmt.Fprintf(w, "%d created, %d updated, %d conflicts, %d skipped\n", len(report.Created), len(report.Updated), len(report.Conflicts), len(report.Skipped))
+}
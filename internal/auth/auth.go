@@ -19,3 +19,9 @@ func HashString(s string) (string, error) {
 func CompareStringWithHash(s string, h string) bool {
 	return bcrypt.CompareHashAndPassword([]byte(h), []byte(s)) == nil
 }
+
+// IsHash checks whether the string is a hash created by HashString, e.g. before storing a hash read from an external source.
+func IsHash(h string) bool {
+	_, err := bcrypt.Cost([]byte(h))
+	return err == nil
+}
@@ -1,8 +1,11 @@
 package container
 
 import (
+	"github.com/wlchs/blog/internal/events"
 	"github.com/wlchs/blog/internal/jwt"
+	"github.com/wlchs/blog/internal/mailer"
 	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/storage"
 	"go.uber.org/zap"
 )
 
@@ -10,30 +13,72 @@ import (
 type Container interface {
 	GetLogger() *zap.SugaredLogger
 
+	GetAnalyticsRepository() repository.AnalyticsRepository
+	GetCollectionRepository() repository.CollectionRepository
+	GetFederationRepository() repository.FederationRepository
+	GetFieldRepository() repository.FieldRepository
+	GetMediaRepository() repository.MediaRepository
+	GetNewsletterRepository() repository.NewsletterRepository
 	GetPostRepository() repository.PostRepository
+	GetReactionRepository() repository.ReactionRepository
+	GetRelatedRepository() repository.RelatedRepository
+	GetSeriesRepository() repository.SeriesRepository
 	GetUserRepository() repository.UserRepository
+	GetWebhookRepository() repository.WebhookRepository
+	GetWebmentionRepository() repository.WebmentionRepository
 
 	GetJWTUtils() jwt.TokenUtils
+	GetStorage() storage.Storage
+	GetEventBus() events.Bus
+	GetMailer() mailer.Mailer
 }
 
 // container is the concrete implementation of the Container interface.
 type container struct {
 	logger *zap.SugaredLogger
 
-	postRepository repository.PostRepository
-	userRepository repository.UserRepository
+	analyticsRepository  repository.AnalyticsRepository
+	collectionRepository repository.CollectionRepository
+	federationRepository repository.FederationRepository
+	fieldRepository      repository.FieldRepository
+	mediaRepository      repository.MediaRepository
+	newsletterRepository repository.NewsletterRepository
+	postRepository       repository.PostRepository
+	reactionRepository   repository.ReactionRepository
+	relatedRepository    repository.RelatedRepository
+	seriesRepository     repository.SeriesRepository
+	userRepository       repository.UserRepository
+	webhookRepository    repository.WebhookRepository
+	webmentionRepository repository.WebmentionRepository
 
 	jwtUtils jwt.TokenUtils
+	storage  storage.Storage
+	eventBus events.Bus
+	mailer   mailer.Mailer
 }
 
 // CreateContainer instantiates the application container with all its necessary dependencies.
 func CreateContainer(
 	log *zap.SugaredLogger,
+	analyticsRepository repository.AnalyticsRepository,
+	collectionRepository repository.CollectionRepository,
+	federationRepository repository.FederationRepository,
+	fieldRepository repository.FieldRepository,
+	mediaRepository repository.MediaRepository,
+	newsletterRepository repository.NewsletterRepository,
 	postRepository repository.PostRepository,
+	reactionRepository repository.ReactionRepository,
+	relatedRepository repository.RelatedRepository,
+	seriesRepository repository.SeriesRepository,
 	userRepository repository.UserRepository,
+	webhookRepository repository.WebhookRepository,
+	webmentionRepository repository.WebmentionRepository,
 	jwtUtils jwt.TokenUtils,
+	fileStorage storage.Storage,
+	eventBus events.Bus,
+	mail mailer.Mailer,
 ) Container {
-	return &container{log, postRepository, userRepository, jwtUtils}
+	return &container{log, analyticsRepository, collectionRepository, federationRepository, fieldRepository, mediaRepository, newsletterRepository, postRepository, reactionRepository, relatedRepository, seriesRepository, userRepository, webhookRepository, webmentionRepository, jwtUtils, fileStorage, eventBus, mail}
 }
 
 // GetLogger returns the logger implementation stored in the container
@@ -41,17 +86,87 @@ func (cont container) GetLogger() *zap.SugaredLogger {
 	return cont.logger
 }
 
+// GetAnalyticsRepository returns the analytics repository implementation stored in the container
+func (cont container) GetAnalyticsRepository() repository.AnalyticsRepository {
+	return cont.analyticsRepository
+}
+
+// GetCollectionRepository returns the collection repository implementation stored in the container
+func (cont container) GetCollectionRepository() repository.CollectionRepository {
+	return cont.collectionRepository
+}
+
+// GetFederationRepository returns the federation repository implementation stored in the container
+func (cont container) GetFederationRepository() repository.FederationRepository {
+	return cont.federationRepository
+}
+
+// GetFieldRepository returns the custom field repository implementation stored in the container
+func (cont container) GetFieldRepository() repository.FieldRepository {
+	return cont.fieldRepository
+}
+
+// GetMediaRepository returns the media repository implementation stored in the container
+func (cont container) GetMediaRepository() repository.MediaRepository {
+	return cont.mediaRepository
+}
+
+// GetNewsletterRepository returns the newsletter repository implementation stored in the container
+func (cont container) GetNewsletterRepository() repository.NewsletterRepository {
+	return cont.newsletterRepository
+}
+
 // GetPostRepository returns the post repository implementation stored in the container
 func (cont container) GetPostRepository() repository.PostRepository {
 	return cont.postRepository
 }
 
+// GetReactionRepository returns the reaction repository implementation stored in the container
+func (cont container) GetReactionRepository() repository.ReactionRepository {
+	return cont.reactionRepository
+}
+
+// GetRelatedRepository returns the related post repository implementation stored in the container
+func (cont container) GetRelatedRepository() repository.RelatedRepository {
+	return cont.relatedRepository
+}
+
+// GetSeriesRepository returns the series repository implementation stored in the container
+func (cont container) GetSeriesRepository() repository.SeriesRepository {
+	return cont.seriesRepository
+}
+
 // GetUserRepository returns the user repository implementation stored in the container
 func (cont container) GetUserRepository() repository.UserRepository {
 	return cont.userRepository
 }
 
+// GetWebhookRepository returns the webhook repository implementation stored in the container
+func (cont container) GetWebhookRepository() repository.WebhookRepository {
+	return cont.webhookRepository
+}
+
+// GetWebmentionRepository returns the webmention repository implementation stored in the container
+func (cont container) GetWebmentionRepository() repository.WebmentionRepository {
+	return cont.webmentionRepository
+}
+
 // GetJWTUtils returns the JWT utility implementation stored in the container.
 func (cont container) GetJWTUtils() jwt.TokenUtils {
 	return cont.jwtUtils
 }
+
+// GetStorage returns the file storage implementation stored in the container.
+func (cont container) GetStorage() storage.Storage {
+	return cont.storage
+}
+
+// GetEventBus returns the event bus implementation stored in the container.
+func (cont container) GetEventBus() events.Bus {
+	return cont.eventBus
+}
+
+// GetMailer returns the mailer implementation stored in the container.
+func (cont container) GetMailer() mailer.Mailer {
+	return cont.mailer
+}
@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/types"
+	"net/http"
+	"strconv"
+)
+
+// AnalyticsController interface defining the middleware methods recording and reporting the views of the posts
+type AnalyticsController interface {
+	GetPostStats(c *gin.Context)
+	GetStats(c *gin.Context)
+	RecordView(c *gin.Context)
+}
+
+// analyticsController is a concrete implementation of the AnalyticsController interface
+type analyticsController struct {
+	cont             container.Container
+	analyticsService services.AnalyticsService
+}
+
+// CreateAnalyticsController instantiates an analytics controller using the application container.
+func CreateAnalyticsController(cont container.Container, analyticsService services.AnalyticsService) AnalyticsController {
+	return &analyticsController{cont, analyticsService}
+}
+
+// GetPostStats middleware. Top level handler of /posts/:id/stats GET requests.
+func (controller analyticsController) GetPostStats(c *gin.Context) {
+	analyticsService := controller.analyticsService
+
+	query, err := analyticsQuery(c)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	stats, err := analyticsService.GetPostStats(c.Param("id"), query, c.GetString("user"))
+
+	switch err.(type) {
+	case nil:
+		c.IndentedJSON(http.StatusOK, stats)
+
+	case errortypes.InvalidAnalyticsQueryError:
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+
+	case errortypes.PostEditForbiddenError:
+		_ = c.AbortWithError(http.StatusForbidden, err)
+
+	case errortypes.PostNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedAnalyticsError{})
+	}
+}
+
+// GetStats middleware. Top level handler of /stats GET requests, reporting the views of the posts of the user.
+func (controller analyticsController) GetStats(c *gin.Context) {
+	analyticsService := controller.analyticsService
+
+	query, err := analyticsQuery(c)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	stats, err := analyticsService.GetStats(query, c.GetString("user"))
+
+	switch err.(type) {
+	case nil:
+		c.IndentedJSON(http.StatusOK, stats)
+
+	case errortypes.InvalidAnalyticsQueryError:
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedAnalyticsError{})
+	}
+}
+
+// RecordView middleware. Can be used before the middlewares of /posts/:id GET requests to count the successful views.
+// Failing to record a view doesn't affect the response.
+func (controller analyticsController) RecordView(c *gin.Context) {
+	log := controller.cont.GetLogger()
+	analyticsService := controller.analyticsService
+
+	c.Next()
+
+	if c.Writer.Status() != http.StatusOK {
+		return
+	}
+
+	visit := types.PageVisit{Address: c.ClientIP(), UserAgent: c.Request.UserAgent(), Referrer: c.Request.Referer(), Host: c.Request.Host}
+	if err := analyticsService.RecordView(c.Param("id"), visit); err != nil {
+		log.Warnf("failed to record view of post %s: %v", c.Param("id"), err)
+	}
+}
+
+// analyticsQuery reads the range, the period and the limit of the statistics from the query parameters.
+func analyticsQuery(c *gin.Context) (types.AnalyticsQuery, error) {
+	query := types.AnalyticsQuery{From: c.Query("from"), To: c.Query("to"), Period: c.Query("period")}
+
+	if limit := c.Query("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil {
+			return types.AnalyticsQuery{}, errortypes.InvalidAnalyticsQueryError{Reason: "limit must be a number"}
+		}
+		query.Limit = value
+	}
+
+	return query, nil
+}
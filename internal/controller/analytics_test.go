@@ -0,0 +1,132 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/controller"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/test"
+	"github.com/wlchs/blog/internal/types"
+	"net/http/httptest"
+	"testing"
+)
+
+// analyticsTestContext contains commonly used services, controllers and other objects relevant for testing the AnalyticsController.
+type analyticsTestContext struct {
+	mockAnalyticsService *mocks.MockAnalyticsService
+	sut                  controller.AnalyticsController
+	ctx                  *gin.Context
+	rec                  *httptest.ResponseRecorder
+}
+
+// createAnalyticsControllerContext creates the context for testing the AnalyticsController and reduces code duplication.
+func createAnalyticsControllerContext(t *testing.T) *analyticsTestContext {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	mockAnalyticsService := mocks.NewMockAnalyticsService(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	sut := controller.CreateAnalyticsController(cont, mockAnalyticsService)
+	ctx, rec := test.CreateControllerContext()
+
+	return &analyticsTestContext{mockAnalyticsService, sut, ctx, rec}
+}
+
+// TestAnalyticsController_RecordView tests recording a successful view of a post.
+func TestAnalyticsController_RecordView(t *testing.T) {
+	t.Parallel()
+	c := createAnalyticsControllerContext(t)
+
+	c.ctx.AddParam("id", "hello")
+	c.ctx.Request.Host = "blog.example.com"
+	c.ctx.Request.RemoteAddr = "192.0.2.1:1234"
+	c.ctx.Request.Header.Set("User-Agent", "Mozilla/5.0")
+	c.ctx.Request.Header.Set("Referer", "https://example.org/links")
+	c.mockAnalyticsService.EXPECT().RecordView("hello", types.PageVisit{
+		Address:   "192.0.2.1",
+		UserAgent: "Mozilla/5.0",
+		Referrer:  "https://example.org/links",
+		Host:      "blog.example.com",
+	}).Return(nil)
+
+	c.sut.RecordView(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+}
+
+// TestAnalyticsController_RecordView_Not_Found tests ignoring the views of missing or inaccessible posts.
+func TestAnalyticsController_RecordView_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createAnalyticsControllerContext(t)
+
+	c.ctx.AddParam("id", "hello")
+	c.ctx.Status(404)
+
+	c.sut.RecordView(c.ctx)
+
+	assert.Equal(t, 404, c.ctx.Writer.Status(), "response status should be kept")
+}
+
+// TestAnalyticsController_GetStats tests retrieving the statistics of the posts of the user.
+func TestAnalyticsController_GetStats(t *testing.T) {
+	t.Parallel()
+	c := createAnalyticsControllerContext(t)
+
+	expected := types.AnalyticsStats{
+		Period:       types.AnalyticsPeriodDay,
+		Views:        3,
+		Visitors:     2,
+		Series:       []types.ViewCount{},
+		TopPosts:     []types.PostViews{{URLHandle: "hello", Title: "Hello", Views: 3, Visitors: 2}},
+		TopReferrers: []types.ReferrerViews{{Host: "example.org", Views: 3, Visitors: 2}},
+	}
+
+	c.ctx.Request.URL.RawQuery = "from=2024-05-01&to=2024-05-07&limit=5"
+	c.ctx.Set("user", "testAuthor")
+	c.mockAnalyticsService.EXPECT().GetStats(types.AnalyticsQuery{From: "2024-05-01", To: "2024-05-07", Limit: 5}, "testAuthor").Return(expected, nil)
+
+	c.sut.GetStats(c.ctx)
+
+	var output types.AnalyticsStats
+	_ = json.Unmarshal(c.rec.Body.Bytes(), &output)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, expected, output, "response body should match")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestAnalyticsController_GetStats_Invalid_Limit tests requesting statistics with a malformed limit.
+func TestAnalyticsController_GetStats_Invalid_Limit(t *testing.T) {
+	t.Parallel()
+	c := createAnalyticsControllerContext(t)
+
+	c.ctx.Request.URL.RawQuery = "limit=ten"
+
+	c.sut.GetStats(c.ctx)
+
+	assert.IsType(t, errortypes.InvalidAnalyticsQueryError{}, c.ctx.Errors.Last().Err, "incorrect error type")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
+
+// TestAnalyticsController_GetPostStats_Forbidden tests requesting the statistics of the post of another user.
+func TestAnalyticsController_GetPostStats_Forbidden(t *testing.T) {
+	t.Parallel()
+	c := createAnalyticsControllerContext(t)
+
+	expectedError := errortypes.PostEditForbiddenError{Post: types.Post{URLHandle: "hello"}, UserName: "otherUser"}
+
+	c.ctx.AddParam("id", "hello")
+	c.ctx.Request.URL.RawQuery = "period=hour"
+	c.ctx.Set("user", "otherUser")
+	c.mockAnalyticsService.EXPECT().GetPostStats("hello", types.AnalyticsQuery{Period: "hour"}, "otherUser").Return(types.AnalyticsStats{}, expectedError)
+
+	c.sut.GetPostStats(c.ctx)
+
+	assert.Equal(t, expectedError, c.ctx.Errors.Last().Err, "response should contain the expected error")
+	assert.Equal(t, 403, c.rec.Code, "incorrect response status")
+}
@@ -1,10 +1,12 @@
 package controller
 
 import (
+	"github.com/gin-gonic/gin/binding"
 	"github.com/wlchs/blog/internal/container"
 	"github.com/wlchs/blog/internal/errortypes"
 	"github.com/wlchs/blog/internal/types"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/wlchs/blog/internal/services"
@@ -12,8 +14,11 @@ import (
 
 // AuthController interface defining authentication-related methods to handler HTTP requests.
 type AuthController interface {
+	Identify(c *gin.Context)
 	Login(c *gin.Context)
 	Protect(c *gin.Context)
+	ProtectAdmin(c *gin.Context)
+	ProtectBearer(c *gin.Context)
 }
 
 // authController is a concrete implementation of the AuthController interface.
@@ -27,6 +32,20 @@ func CreateAuthController(cont container.Container, userService services.UserSer
 	return &authController{cont, userService}
 }
 
+// Identify middleware. Can be used before any middleware of public endpoints which behave differently for authenticated users.
+// If a valid token is provided, the user is stored in the context. Requests without a valid token are not rejected.
+func (auth authController) Identify(c *gin.Context) {
+	jwtUtils := auth.cont.GetJWTUtils()
+
+	if token := c.Request.Header.Get("X-Auth-Token"); token != "" {
+		if u, err := jwtUtils.ParseJWT(token); err == nil {
+			c.Set("user", u)
+		}
+	}
+
+	c.Next()
+}
+
 // Login middleware. Top level handler of /login POST requests.
 func (auth authController) Login(c *gin.Context) {
 	userService := auth.userService
@@ -61,3 +80,54 @@ func (auth authController) Protect(c *gin.Context) {
 		_ = c.AbortWithError(http.StatusUnauthorized, errortypes.InvalidAuthTokenError{})
 	}
 }
+
+// ProtectAdmin middleware. Can be used after the Protect middleware to make sure only administrators are able to use an endpoint.
+func (auth authController) ProtectAdmin(c *gin.Context) {
+	userService := auth.userService
+	user := c.GetString("user")
+
+	if !userService.IsAdmin(user) {
+		_ = c.AbortWithError(http.StatusForbidden, errortypes.AdminRightsRequiredError{UserName: user})
+		return
+	}
+
+	c.Next()
+}
+
+// ProtectBearer middleware. Works like the Protect middleware, but also accepts the token the way OAuth 2.0 clients such as
+// Micropub editors send it: as a Bearer token of the Authorization header or in the access_token field of form-encoded requests.
+func (auth authController) ProtectBearer(c *gin.Context) {
+	jwtUtils := auth.cont.GetJWTUtils()
+	token := bearerToken(c)
+
+	if token == "" {
+		c.Header("WWW-Authenticate", "Bearer")
+		_ = c.AbortWithError(http.StatusUnauthorized, errortypes.MissingAuthTokenError{})
+	} else if u, err := jwtUtils.ParseJWT(token); err == nil {
+		c.Set("user", u)
+		c.Next()
+	} else {
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		_ = c.AbortWithError(http.StatusUnauthorized, errortypes.InvalidAuthTokenError{})
+	}
+}
+
+// bearerToken extracts the token of a request from the X-Auth-Token header, the Authorization header or the access_token
+// field of a form-encoded body, in this order.
+func bearerToken(c *gin.Context) string {
+	if token := c.Request.Header.Get("X-Auth-Token"); token != "" {
+		return token
+	}
+
+	scheme, token, found := strings.Cut(c.Request.Header.Get("Authorization"), " ")
+	if found && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+
+	// Multipart bodies aren't parsed here, so the upload limits of the handlers still apply to them
+	if c.ContentType() == binding.MIMEPOSTForm {
+		return c.PostForm("access_token")
+	}
+
+	return ""
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/wlchs/blog/internal/test"
 	"github.com/wlchs/blog/internal/types"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 )
 
@@ -32,7 +33,7 @@ func createAuthControllerContext(t *testing.T) *authTestContext {
 	mockCtrl := gomock.NewController(t)
 	mockJwtUtils := mocks.NewMockTokenUtils(mockCtrl)
 	mockUserService := mocks.NewMockUserService(mockCtrl)
-	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, mockJwtUtils)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, mockJwtUtils, nil, nil, nil)
 	sut := controller.CreateAuthController(cont, mockUserService)
 	ctx, rec := test.CreateControllerContext()
 
@@ -146,3 +147,120 @@ func TestAuthController_Protect_Token_Invalid(t *testing.T) {
 	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
 	assert.Equal(t, 401, c.rec.Code, "incorrect response status")
 }
+
+// TestAuthController_Identify tests the identify middleware of the AuthController with a valid token.
+func TestAuthController_Identify(t *testing.T) {
+	t.Parallel()
+	c := createAuthControllerContext(t)
+
+	c.ctx.Request.Header.Add("X-Auth-Token", "token")
+	c.mockJwtUtils.EXPECT().ParseJWT("token").Return("test user", nil)
+
+	c.sut.Identify(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "expected no errors")
+	assert.Equal(t, "test user", c.ctx.GetString("user"), "incorrect user")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestAuthController_Identify_Token_Missing tests the identify middleware of the AuthController without token.
+func TestAuthController_Identify_Token_Missing(t *testing.T) {
+	t.Parallel()
+	c := createAuthControllerContext(t)
+
+	c.sut.Identify(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "anonymous requests shouldn't be rejected")
+	assert.Equal(t, "", c.ctx.GetString("user"), "no user should be set")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestAuthController_Identify_Token_Invalid tests the identify middleware of the AuthController with an invalid token.
+func TestAuthController_Identify_Token_Invalid(t *testing.T) {
+	t.Parallel()
+	c := createAuthControllerContext(t)
+
+	c.ctx.Request.Header.Add("X-Auth-Token", "token")
+	c.mockJwtUtils.EXPECT().ParseJWT("token").Return("", fmt.Errorf("internal error"))
+
+	c.sut.Identify(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "requests with invalid tokens shouldn't be rejected")
+	assert.Equal(t, "", c.ctx.GetString("user"), "no user should be set")
+}
+
+// TestAuthController_ProtectAdmin tests the admin protection middleware of the AuthController with an administrator.
+func TestAuthController_ProtectAdmin(t *testing.T) {
+	t.Parallel()
+	c := createAuthControllerContext(t)
+
+	c.ctx.Set("user", "admin")
+	c.mockUserService.EXPECT().IsAdmin("admin").Return(true)
+
+	c.sut.ProtectAdmin(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "expected no errors")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestAuthController_ProtectAdmin_Forbidden tests the admin protection middleware of the AuthController with a regular user.
+func TestAuthController_ProtectAdmin_Forbidden(t *testing.T) {
+	t.Parallel()
+	c := createAuthControllerContext(t)
+
+	expectedError := errortypes.AdminRightsRequiredError{UserName: "user"}
+
+	c.ctx.Set("user", "user")
+	c.mockUserService.EXPECT().IsAdmin("user").Return(false)
+
+	c.sut.ProtectAdmin(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 403, c.rec.Code, "incorrect response status")
+}
+
+// TestAuthController_ProtectBearer tests the bearer protection middleware of the AuthController with a token in the Authorization header.
+func TestAuthController_ProtectBearer(t *testing.T) {
+	t.Parallel()
+	c := createAuthControllerContext(t)
+
+	c.ctx.Request.Header.Add("Authorization", "Bearer token")
+	c.mockJwtUtils.EXPECT().ParseJWT("token").Return("test user", nil)
+
+	c.sut.ProtectBearer(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "expected no errors")
+	assert.Equal(t, "test user", c.ctx.GetString("user"), "incorrect user")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestAuthController_ProtectBearer_Form tests the bearer protection middleware of the AuthController with a token in the form.
+func TestAuthController_ProtectBearer_Form(t *testing.T) {
+	t.Parallel()
+	c := createAuthControllerContext(t)
+
+	mockFormPost(c.ctx, url.Values{"access_token": {"token"}, "h": {"entry"}})
+	c.mockJwtUtils.EXPECT().ParseJWT("token").Return("test user", nil)
+
+	c.sut.ProtectBearer(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "expected no errors")
+	assert.Equal(t, "test user", c.ctx.GetString("user"), "incorrect user")
+	assert.Equal(t, "entry", c.ctx.PostForm("h"), "form should remain readable")
+}
+
+// TestAuthController_ProtectBearer_Token_Missing tests the bearer protection middleware of the AuthController with missing token.
+func TestAuthController_ProtectBearer_Token_Missing(t *testing.T) {
+	t.Parallel()
+	c := createAuthControllerContext(t)
+
+	c.ctx.Request.Header.Add("Authorization", "Basic dXNlcjpwYXNz")
+
+	c.sut.ProtectBearer(c.ctx)
+
+	assert.Equal(t, errortypes.MissingAuthTokenError{}, c.ctx.Errors.Last().Err, "incorrect error type")
+	assert.Equal(t, "Bearer", c.rec.Header().Get("WWW-Authenticate"), "authentication scheme should be announced")
+	assert.Equal(t, 401, c.rec.Code, "incorrect response status")
+}
@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/types"
+	"net/http"
+)
+
+// CollectionController interface defining collection-related middleware methods to handle HTTP requests
+type CollectionController interface {
+	AddCollection(c *gin.Context)
+	DeleteCollection(c *gin.Context)
+	GetCollection(c *gin.Context)
+	GetCollections(c *gin.Context)
+	SetCollectionPosts(c *gin.Context)
+	UpdateCollection(c *gin.Context)
+}
+
+// collectionController is a concrete implementation of the CollectionController interface
+type collectionController struct {
+	cont              container.Container
+	collectionService services.CollectionService
+}
+
+// CreateCollectionController instantiates a collection controller using the application container.
+func CreateCollectionController(cont container.Container, collectionService services.CollectionService) CollectionController {
+	return &collectionController{cont, collectionService}
+}
+
+// AddCollection middleware. Top level handler of /collections POST requests.
+func (controller collectionController) AddCollection(c *gin.Context) {
+	collectionService := controller.collectionService
+
+	var body types.Collection
+	if err := c.BindJSON(&body); err != nil {
+		return
+	}
+
+	collection, err := collectionService.AddCollection(&body)
+
+	switch err.(type) {
+	case nil:
+		c.IndentedJSON(http.StatusCreated, collection)
+
+	case errortypes.DuplicateElementError:
+		_ = c.AbortWithError(http.StatusConflict, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedCollectionError{Collection: body})
+	}
+}
+
+// DeleteCollection middleware. Top level handler of /collections/:id DELETE requests.
+func (controller collectionController) DeleteCollection(c *gin.Context) {
+	collectionService := controller.collectionService
+
+	id, found := c.Params.Get("id")
+	if !found {
+		_ = c.AbortWithError(http.StatusBadRequest, errortypes.MissingUrlHandleError{})
+		return
+	}
+
+	err := collectionService.DeleteCollection(id)
+
+	switch err.(type) {
+	case nil:
+		c.Status(http.StatusNoContent)
+
+	case errortypes.CollectionNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedCollectionError{Collection: types.Collection{URLHandle: id}})
+	}
+}
+
+// GetCollection middleware. Top level handler of /collections/:id GET requests.
+func (controller collectionController) GetCollection(c *gin.Context) {
+	collectionService := controller.collectionService
+
+	id, found := c.Params.Get("id")
+	if !found {
+		_ = c.AbortWithError(http.StatusBadRequest, errortypes.MissingUrlHandleError{})
+		return
+	}
+
+	collection, err := collectionService.GetCollection(id)
+
+	switch err.(type) {
+	case nil:
+		c.IndentedJSON(http.StatusOK, collection)
+
+	case errortypes.CollectionNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedCollectionError{Collection: types.Collection{URLHandle: id}})
+	}
+}
+
+// GetCollections middleware. Top level handler of /collections GET requests.
+func (controller collectionController) GetCollections(c *gin.Context) {
+	collectionService := controller.collectionService
+
+	collections, err := collectionService.GetCollections()
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedCollectionError{})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, collections)
+}
+
+// SetCollectionPosts middleware. Top level handler of /collections/:id/posts PUT requests.
+// The request body contains the ordered list of post URL handles belonging to the collection.
+func (controller collectionController) SetCollectionPosts(c *gin.Context) {
+	collectionService := controller.collectionService
+
+	id, found := c.Params.Get("id")
+	if !found {
+		_ = c.AbortWithError(http.StatusBadRequest, errortypes.MissingUrlHandleError{})
+		return
+	}
+
+	var body types.CollectionPostsInput
+	if err := c.BindJSON(&body); err != nil {
+		return
+	}
+
+	collection, err := collectionService.SetCollectionPosts(id, body.Posts, c.GetString("user"))
+
+	switch err.(type) {
+	case nil:
+		c.IndentedJSON(http.StatusOK, collection)
+
+	case errortypes.CollectionNotFoundError, errortypes.PostNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedCollectionError{Collection: types.Collection{URLHandle: id}})
+	}
+}
+
+// UpdateCollection middleware. Top level handler of /collections/:id PUT requests.
+func (controller collectionController) UpdateCollection(c *gin.Context) {
+	collectionService := controller.collectionService
+
+	id, found := c.Params.Get("id")
+	if !found {
+		_ = c.AbortWithError(http.StatusBadRequest, errortypes.MissingUrlHandleError{})
+		return
+	}
+
+	var body types.Collection
+	if err := c.BindJSON(&body); err != nil {
+		return
+	}
+
+	body.URLHandle = id
+	collection, err := collectionService.UpdateCollection(&body)
+
+	switch err.(type) {
+	case nil:
+		c.IndentedJSON(http.StatusOK, collection)
+
+	case errortypes.CollectionNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedCollectionError{Collection: body})
+	}
+}
@@ -0,0 +1,190 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/controller"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/test"
+	"github.com/wlchs/blog/internal/types"
+	"net/http/httptest"
+	"testing"
+)
+
+// collectionTestContext contains commonly used services, controllers and other objects relevant for testing the CollectionController.
+type collectionTestContext struct {
+	mockCollectionService *mocks.MockCollectionService
+	sut                   controller.CollectionController
+	ctx                   *gin.Context
+	rec                   *httptest.ResponseRecorder
+}
+
+// createCollectionControllerContext creates the context for testing the CollectionController and reduces code duplication.
+func createCollectionControllerContext(t *testing.T) *collectionTestContext {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	mockCollectionService := mocks.NewMockCollectionService(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	sut := controller.CreateCollectionController(cont, mockCollectionService)
+	ctx, rec := test.CreateControllerContext()
+
+	return &collectionTestContext{mockCollectionService, sut, ctx, rec}
+}
+
+// TestCollectionController_AddCollection tests adding a new collection with valid input params.
+func TestCollectionController_AddCollection(t *testing.T) {
+	t.Parallel()
+	c := createCollectionControllerContext(t)
+
+	input := types.Collection{URLHandle: "start-here", Title: "Start here"}
+
+	test.MockJsonPost(c.ctx, input)
+	c.mockCollectionService.EXPECT().AddCollection(&input).Return(input, nil)
+
+	c.sut.AddCollection(c.ctx)
+
+	var output types.Collection
+	_ = json.Unmarshal(c.rec.Body.Bytes(), &output)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, input, output, "response body should match")
+	assert.Equal(t, 201, c.rec.Code, "incorrect response status")
+}
+
+// TestCollectionController_AddCollection_Duplicate tests adding a collection with an already existing URL handle.
+func TestCollectionController_AddCollection_Duplicate(t *testing.T) {
+	t.Parallel()
+	c := createCollectionControllerContext(t)
+
+	input := types.Collection{URLHandle: "start-here"}
+	expectedError := errortypes.DuplicateElementError{Key: input.URLHandle}
+
+	test.MockJsonPost(c.ctx, input)
+	c.mockCollectionService.EXPECT().AddCollection(&input).Return(types.Collection{}, expectedError)
+
+	c.sut.AddCollection(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 409, c.rec.Code, "incorrect response status")
+}
+
+// TestCollectionController_DeleteCollection tests removing a collection.
+func TestCollectionController_DeleteCollection(t *testing.T) {
+	t.Parallel()
+	c := createCollectionControllerContext(t)
+
+	c.ctx.AddParam("id", "start-here")
+	c.mockCollectionService.EXPECT().DeleteCollection("start-here").Return(nil)
+
+	c.sut.DeleteCollection(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "expected no errors")
+	assert.Equal(t, 204, c.ctx.Writer.Status(), "incorrect response status")
+}
+
+// TestCollectionController_GetCollection_Not_Found tests retrieving a non-existent collection.
+func TestCollectionController_GetCollection_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createCollectionControllerContext(t)
+
+	expectedError := errortypes.CollectionNotFoundError{Collection: types.Collection{URLHandle: "start-here"}}
+
+	c.ctx.AddParam("id", "start-here")
+	c.mockCollectionService.EXPECT().GetCollection("start-here").Return(types.Collection{}, expectedError)
+
+	c.sut.GetCollection(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+}
+
+// TestCollectionController_GetCollections_Unexpected_Error tests handling an unexpected error while retrieving every collection.
+func TestCollectionController_GetCollections_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createCollectionControllerContext(t)
+
+	c.mockCollectionService.EXPECT().GetCollections().Return(nil, fmt.Errorf("unexpected error"))
+
+	c.sut.GetCollections(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, errortypes.UnexpectedCollectionError{}.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 500, c.rec.Code, "incorrect response status")
+}
+
+// TestCollectionController_SetCollectionPosts tests replacing the posts of a collection.
+func TestCollectionController_SetCollectionPosts(t *testing.T) {
+	t.Parallel()
+	c := createCollectionControllerContext(t)
+
+	input := types.CollectionPostsInput{Posts: []string{"intro", "setup"}}
+	expectedOutput := types.Collection{URLHandle: "start-here", Posts: []types.Post{{URLHandle: "intro"}, {URLHandle: "setup"}}}
+
+	test.MockJsonPost(c.ctx, input)
+	c.ctx.AddParam("id", "start-here")
+	c.ctx.Set("user", "testEditor")
+	c.mockCollectionService.EXPECT().SetCollectionPosts("start-here", input.Posts, "testEditor").Return(expectedOutput, nil)
+
+	c.sut.SetCollectionPosts(c.ctx)
+
+	var output types.Collection
+	_ = json.Unmarshal(c.rec.Body.Bytes(), &output)
+
+	assert.Nil(t, c.ctx.Errors, "expected no errors")
+	assert.Equal(t, expectedOutput, output, "incorrect output body")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestCollectionController_SetCollectionPosts_Post_Not_Found tests adding a non-existent post to a collection.
+func TestCollectionController_SetCollectionPosts_Post_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createCollectionControllerContext(t)
+
+	input := types.CollectionPostsInput{Posts: []string{"missing"}}
+	expectedError := errortypes.PostNotFoundError{Post: types.Post{URLHandle: "missing"}}
+
+	test.MockJsonPost(c.ctx, input)
+	c.ctx.AddParam("id", "start-here")
+	c.mockCollectionService.EXPECT().SetCollectionPosts("start-here", input.Posts, "").Return(types.Collection{}, expectedError)
+
+	c.sut.SetCollectionPosts(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+}
+
+// TestCollectionController_UpdateCollection tests updating the fields of a collection.
+func TestCollectionController_UpdateCollection(t *testing.T) {
+	t.Parallel()
+	c := createCollectionControllerContext(t)
+
+	input := types.Collection{Title: "Start here"}
+	expected := types.Collection{URLHandle: "start-here", Title: "Start here"}
+
+	test.MockJsonPost(c.ctx, input)
+	c.ctx.AddParam("id", "start-here")
+	c.mockCollectionService.EXPECT().UpdateCollection(&expected).Return(expected, nil)
+
+	c.sut.UpdateCollection(c.ctx)
+
+	var output types.Collection
+	_ = json.Unmarshal(c.rec.Body.Bytes(), &output)
+
+	assert.Nil(t, c.ctx.Errors, "expected no errors")
+	assert.Equal(t, expected, output, "incorrect output body")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/types"
+	"io"
+	"net/http"
+)
+
+// maxActivitySize limits the size of the activities posted to the inboxes.
+const maxActivitySize = 1 << 20
+
+// FederationController interface defining ActivityPub-related middleware methods to handle HTTP requests
+type FederationController interface {
+	GetActor(c *gin.Context)
+	GetFollowers(c *gin.Context)
+	GetOutbox(c *gin.Context)
+	GetWebFinger(c *gin.Context)
+	PostInbox(c *gin.Context)
+}
+
+// federationController is a concrete implementation of the FederationController interface
+type federationController struct {
+	cont              container.Container
+	federationService services.FederationService
+}
+
+// CreateFederationController instantiates a federation controller using the application container.
+func CreateFederationController(cont container.Container, federationService services.FederationService) FederationController {
+	return &federationController{cont, federationService}
+}
+
+// GetActor middleware. Top level handler of /actors/:userName GET requests.
+func (controller federationController) GetActor(c *gin.Context) {
+	federationService := controller.federationService
+
+	actor, err := federationService.GetActor(c.Param("userName"))
+	if err != nil {
+		abortWithFederationError(c, err)
+		return
+	}
+
+	writeActivityJSON(c, types.ActivityContentType, actor)
+}
+
+// GetFollowers middleware. Top level handler of /actors/:userName/followers GET requests.
+func (controller federationController) GetFollowers(c *gin.Context) {
+	federationService := controller.federationService
+
+	followers, err := federationService.GetFollowers(c.Param("userName"))
+	if err != nil {
+		abortWithFederationError(c, err)
+		return
+	}
+
+	writeActivityJSON(c, types.ActivityContentType, followers)
+}
+
+// GetOutbox middleware. Top level handler of /actors/:userName/outbox GET requests.
+func (controller federationController) GetOutbox(c *gin.Context) {
+	federationService := controller.federationService
+
+	outbox, err := federationService.GetOutbox(c.Param("userName"))
+	if err != nil {
+		abortWithFederationError(c, err)
+		return
+	}
+
+	writeActivityJSON(c, types.ActivityContentType, outbox)
+}
+
+// GetWebFinger middleware. Top level handler of /.well-known/webfinger GET requests.
+func (controller federationController) GetWebFinger(c *gin.Context) {
+	federationService := controller.federationService
+
+	resource := c.Query("resource")
+	if resource == "" {
+		_ = c.AbortWithError(http.StatusBadRequest, errortypes.InvalidActivityError{Reason: "missing resource"})
+		return
+	}
+
+	webFinger, err := federationService.GetWebFinger(resource)
+	if err != nil {
+		abortWithFederationError(c, err)
+		return
+	}
+
+	writeActivityJSON(c, types.WebFingerContentType, webFinger)
+}
+
+// PostInbox middleware. Top level handler of /actors/:userName/inbox POST requests.
+// The signature of the request is verified using the raw body, so the body isn't bound.
+func (controller federationController) PostInbox(c *gin.Context) {
+	federationService := controller.federationService
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxActivitySize+1))
+	if err != nil || len(body) > maxActivitySize {
+		_ = c.AbortWithError(http.StatusBadRequest, errortypes.InvalidActivityError{Reason: "the activity can't be read"})
+		return
+	}
+
+	if err := federationService.ReceiveActivity(c.Param("userName"), c.Request, body); err != nil {
+		abortWithFederationError(c, err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// abortWithFederationError aborts the request with the status matching the error of the federation service.
+func abortWithFederationError(c *gin.Context, err error) {
+	switch err.(type) {
+	case errortypes.FederationDisabledError, errortypes.UserNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	case errortypes.InvalidActivityError:
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+
+	case errortypes.InvalidSignatureError:
+		_ = c.AbortWithError(http.StatusUnauthorized, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedFederationError{})
+	}
+}
+
+// writeActivityJSON writes an ActivityPub or WebFinger document using its own media type.
+func writeActivityJSON(c *gin.Context, contentType string, document interface{}) {
+	body, err := json.Marshal(document)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedFederationError{})
+		return
+	}
+
+	c.Data(http.StatusOK, contentType+"; charset=utf-8", body)
+}
@@ -0,0 +1,157 @@
+package controller_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/controller"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/test"
+	"github.com/wlchs/blog/internal/types"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// federationTestContext contains commonly used services, controllers and other objects relevant for testing the FederationController.
+type federationTestContext struct {
+	mockFederationService *mocks.MockFederationService
+	sut                   controller.FederationController
+	ctx                   *gin.Context
+	rec                   *httptest.ResponseRecorder
+}
+
+// createFederationControllerContext creates the context for testing the FederationController and reduces code duplication.
+func createFederationControllerContext(t *testing.T) *federationTestContext {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	mockFederationService := mocks.NewMockFederationService(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	sut := controller.CreateFederationController(cont, mockFederationService)
+	ctx, rec := test.CreateControllerContext()
+
+	return &federationTestContext{mockFederationService, sut, ctx, rec}
+}
+
+// TestFederationController_GetActor tests retrieving the actor of an author.
+func TestFederationController_GetActor(t *testing.T) {
+	t.Parallel()
+	c := createFederationControllerContext(t)
+
+	actor := types.Actor{ID: "https://blog.example.com/actors/testAuthor", Type: "Person", PreferredUsername: "testAuthor"}
+
+	c.ctx.AddParam("userName", "testAuthor")
+	c.mockFederationService.EXPECT().GetActor("testAuthor").Return(actor, nil)
+
+	c.sut.GetActor(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+	assert.Equal(t, types.ActivityContentType+"; charset=utf-8", c.rec.Header().Get("Content-Type"), "incorrect content type")
+	assert.Contains(t, c.rec.Body.String(), `"preferredUsername":"testAuthor"`, "actor should be returned")
+}
+
+// TestFederationController_GetActor_Disabled tests retrieving an actor while the federation is disabled.
+func TestFederationController_GetActor_Disabled(t *testing.T) {
+	t.Parallel()
+	c := createFederationControllerContext(t)
+
+	expectedError := errortypes.FederationDisabledError{}
+
+	c.ctx.AddParam("userName", "testAuthor")
+	c.mockFederationService.EXPECT().GetActor("testAuthor").Return(types.Actor{}, expectedError)
+
+	c.sut.GetActor(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+}
+
+// TestFederationController_GetWebFinger tests resolving an account.
+func TestFederationController_GetWebFinger(t *testing.T) {
+	t.Parallel()
+	c := createFederationControllerContext(t)
+
+	webFinger := types.WebFinger{Subject: "acct:testAuthor@blog.example.com"}
+
+	c.ctx.Request.URL.RawQuery = "resource=acct:testAuthor@blog.example.com"
+	c.mockFederationService.EXPECT().GetWebFinger("acct:testAuthor@blog.example.com").Return(webFinger, nil)
+
+	c.sut.GetWebFinger(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+	assert.Equal(t, types.WebFingerContentType+"; charset=utf-8", c.rec.Header().Get("Content-Type"), "incorrect content type")
+}
+
+// TestFederationController_GetWebFinger_Missing_Resource tests resolving an account without specifying it.
+func TestFederationController_GetWebFinger_Missing_Resource(t *testing.T) {
+	t.Parallel()
+	c := createFederationControllerContext(t)
+
+	c.sut.GetWebFinger(c.ctx)
+
+	assert.Equal(t, 1, len(c.ctx.Errors), "expected exactly 1 error")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
+
+// TestFederationController_PostInbox tests accepting an activity.
+func TestFederationController_PostInbox(t *testing.T) {
+	t.Parallel()
+	c := createFederationControllerContext(t)
+
+	body := `{"type":"Follow"}`
+
+	c.ctx.AddParam("userName", "testAuthor")
+	c.ctx.Request.Body = io.NopCloser(strings.NewReader(body))
+	c.mockFederationService.EXPECT().ReceiveActivity("testAuthor", c.ctx.Request, []byte(body)).Return(nil)
+
+	c.sut.PostInbox(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 202, c.ctx.Writer.Status(), "incorrect response status")
+}
+
+// TestFederationController_PostInbox_Invalid_Signature tests rejecting an activity with an invalid signature.
+func TestFederationController_PostInbox_Invalid_Signature(t *testing.T) {
+	t.Parallel()
+	c := createFederationControllerContext(t)
+
+	body := `{"type":"Follow"}`
+	expectedError := errortypes.InvalidSignatureError{Reason: "signature mismatch"}
+
+	c.ctx.AddParam("userName", "testAuthor")
+	c.ctx.Request.Body = io.NopCloser(strings.NewReader(body))
+	c.mockFederationService.EXPECT().ReceiveActivity("testAuthor", c.ctx.Request, []byte(body)).Return(expectedError)
+
+	c.sut.PostInbox(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 401, c.rec.Code, "incorrect response status")
+}
+
+// TestFederationController_PostInbox_Unexpected_Error tests handling an unexpected error while receiving an activity.
+func TestFederationController_PostInbox_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createFederationControllerContext(t)
+
+	c.ctx.AddParam("userName", "testAuthor")
+	c.ctx.Request.Body = io.NopCloser(strings.NewReader("{}"))
+	c.mockFederationService.EXPECT().ReceiveActivity("testAuthor", gomock.Any(), gomock.Any()).Return(errortypes.UnexpectedFederationError{})
+
+	c.sut.PostInbox(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, errortypes.UnexpectedFederationError{}.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 500, c.rec.Code, "incorrect response status")
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/types"
+	"net/http"
+)
+
+// FieldController interface defining custom field-related middleware methods to handle HTTP requests
+type FieldController interface {
+	AddField(c *gin.Context)
+	DeleteField(c *gin.Context)
+	GetFields(c *gin.Context)
+}
+
+// fieldController is a concrete implementation of the FieldController interface
+type fieldController struct {
+	cont         container.Container
+	fieldService services.FieldService
+}
+
+// CreateFieldController instantiates a custom field controller using the application container.
+func CreateFieldController(cont container.Container, fieldService services.FieldService) FieldController {
+	return &fieldController{cont, fieldService}
+}
+
+// AddField middleware. Top level handler of /fields POST requests.
+func (controller fieldController) AddField(c *gin.Context) {
+	fieldService := controller.fieldService
+
+	var body types.FieldDefinition
+	if err := c.BindJSON(&body); err != nil {
+		return
+	}
+
+	field, err := fieldService.AddField(&body)
+
+	switch err.(type) {
+	case nil:
+		c.IndentedJSON(http.StatusCreated, field)
+
+	case errortypes.InvalidFieldDefinitionError:
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+
+	case errortypes.DuplicateElementError:
+		_ = c.AbortWithError(http.StatusConflict, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedFieldError{Field: body})
+	}
+}
+
+// DeleteField middleware. Top level handler of /fields/:name DELETE requests.
+func (controller fieldController) DeleteField(c *gin.Context) {
+	fieldService := controller.fieldService
+
+	name := c.Param("name")
+	err := fieldService.DeleteField(name)
+
+	switch err.(type) {
+	case nil:
+		c.Status(http.StatusNoContent)
+
+	case errortypes.FieldNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedFieldError{Field: types.FieldDefinition{Name: name}})
+	}
+}
+
+// GetFields middleware. Top level handler of /fields GET requests.
+func (controller fieldController) GetFields(c *gin.Context) {
+	fieldService := controller.fieldService
+
+	fields, err := fieldService.GetFields()
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedFieldError{})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, fields)
+}
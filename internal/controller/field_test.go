@@ -0,0 +1,189 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/controller"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/test"
+	"github.com/wlchs/blog/internal/types"
+	"net/http/httptest"
+	"testing"
+)
+
+// fieldTestContext contains commonly used services, controllers and other objects relevant for testing the FieldController.
+type fieldTestContext struct {
+	mockFieldService *mocks.MockFieldService
+	sut              controller.FieldController
+	ctx              *gin.Context
+	rec              *httptest.ResponseRecorder
+}
+
+// createFieldControllerContext creates the context for testing the FieldController and reduces code duplication.
+func createFieldControllerContext(t *testing.T) *fieldTestContext {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	mockFieldService := mocks.NewMockFieldService(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	sut := controller.CreateFieldController(cont, mockFieldService)
+	ctx, rec := test.CreateControllerContext()
+
+	return &fieldTestContext{mockFieldService, sut, ctx, rec}
+}
+
+// TestFieldController_AddField tests adding a new custom field definition with valid input params.
+func TestFieldController_AddField(t *testing.T) {
+	t.Parallel()
+	c := createFieldControllerContext(t)
+
+	input := types.FieldDefinition{Name: "category", Type: types.FieldTypeEnum, Options: []string{"news", "howto"}}
+
+	test.MockJsonPost(c.ctx, input)
+	c.mockFieldService.EXPECT().AddField(&input).Return(input, nil)
+
+	c.sut.AddField(c.ctx)
+
+	var output types.FieldDefinition
+	_ = json.Unmarshal(c.rec.Body.Bytes(), &output)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, input, output, "response body should match")
+	assert.Equal(t, 201, c.rec.Code, "incorrect response status")
+}
+
+// TestFieldController_AddField_Invalid tests adding an invalid custom field definition.
+func TestFieldController_AddField_Invalid(t *testing.T) {
+	t.Parallel()
+	c := createFieldControllerContext(t)
+
+	input := types.FieldDefinition{Name: "category", Type: types.FieldTypeEnum}
+	expectedError := errortypes.InvalidFieldDefinitionError{Field: input, Reason: "enum fields require at least one option"}
+
+	test.MockJsonPost(c.ctx, input)
+	c.mockFieldService.EXPECT().AddField(&input).Return(types.FieldDefinition{}, expectedError)
+
+	c.sut.AddField(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
+
+// TestFieldController_AddField_Duplicate tests adding a custom field definition with an already existing name.
+func TestFieldController_AddField_Duplicate(t *testing.T) {
+	t.Parallel()
+	c := createFieldControllerContext(t)
+
+	input := types.FieldDefinition{Name: "cover", Type: types.FieldTypeURL}
+	expectedError := errortypes.DuplicateElementError{Key: input.Name}
+
+	test.MockJsonPost(c.ctx, input)
+	c.mockFieldService.EXPECT().AddField(&input).Return(types.FieldDefinition{}, expectedError)
+
+	c.sut.AddField(c.ctx)
+
+	assert.Equal(t, 409, c.rec.Code, "incorrect response status")
+}
+
+// TestFieldController_AddField_Unexpected_Error tests handling an unexpected error while adding a custom field definition.
+func TestFieldController_AddField_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createFieldControllerContext(t)
+
+	input := types.FieldDefinition{Name: "cover", Type: types.FieldTypeURL}
+	expectedError := errortypes.UnexpectedFieldError{Field: input}
+
+	test.MockJsonPost(c.ctx, input)
+	c.mockFieldService.EXPECT().AddField(&input).Return(types.FieldDefinition{}, fmt.Errorf("unexpected error"))
+
+	c.sut.AddField(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 500, c.rec.Code, "incorrect response status")
+}
+
+// TestFieldController_DeleteField tests removing a custom field definition.
+func TestFieldController_DeleteField(t *testing.T) {
+	t.Parallel()
+	c := createFieldControllerContext(t)
+
+	c.ctx.AddParam("name", "cover")
+	c.mockFieldService.EXPECT().DeleteField("cover").Return(nil)
+
+	c.sut.DeleteField(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 204, c.ctx.Writer.Status(), "incorrect response status")
+}
+
+// TestFieldController_DeleteField_Not_Found tests removing a non-existent custom field definition.
+func TestFieldController_DeleteField_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createFieldControllerContext(t)
+
+	expectedError := errortypes.FieldNotFoundError{Field: types.FieldDefinition{Name: "cover"}}
+
+	c.ctx.AddParam("name", "cover")
+	c.mockFieldService.EXPECT().DeleteField("cover").Return(expectedError)
+
+	c.sut.DeleteField(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+}
+
+// TestFieldController_DeleteField_Unexpected_Error tests handling an unexpected error while removing a custom field definition.
+func TestFieldController_DeleteField_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createFieldControllerContext(t)
+
+	c.ctx.AddParam("name", "cover")
+	c.mockFieldService.EXPECT().DeleteField("cover").Return(fmt.Errorf("unexpected error"))
+
+	c.sut.DeleteField(c.ctx)
+
+	assert.Equal(t, 500, c.rec.Code, "incorrect response status")
+}
+
+// TestFieldController_GetFields tests retrieving every custom field definition.
+func TestFieldController_GetFields(t *testing.T) {
+	t.Parallel()
+	c := createFieldControllerContext(t)
+
+	expectedOutput := []types.FieldDefinition{{Name: "cover", Type: types.FieldTypeURL}}
+
+	c.mockFieldService.EXPECT().GetFields().Return(expectedOutput, nil)
+
+	c.sut.GetFields(c.ctx)
+
+	var output []types.FieldDefinition
+	_ = json.Unmarshal(c.rec.Body.Bytes(), &output)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, expectedOutput, output, "response body should match")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestFieldController_GetFields_Unexpected_Error tests handling an unexpected error while retrieving every custom field definition.
+func TestFieldController_GetFields_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createFieldControllerContext(t)
+
+	c.mockFieldService.EXPECT().GetFields().Return(nil, fmt.Errorf("unexpected error"))
+
+	c.sut.GetFields(c.ctx)
+
+	assert.Equal(t, 500, c.rec.Code, "incorrect response status")
+}
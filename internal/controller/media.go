@@ -0,0 +1,232 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/types"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MediaController interface defining media-related middleware methods to handle HTTP requests
+type MediaController interface {
+	DeleteMedia(c *gin.Context)
+	GetMedia(c *gin.Context)
+	GetMediaList(c *gin.Context)
+	GetMediaVariant(c *gin.Context)
+	UploadMedia(c *gin.Context)
+}
+
+// mediaController is a concrete implementation of the MediaController interface
+type mediaController struct {
+	cont         container.Container
+	mediaService services.MediaService
+}
+
+// CreateMediaController instantiates a media controller using the application container.
+func CreateMediaController(cont container.Container, mediaService services.MediaService) MediaController {
+	return &mediaController{cont, mediaService}
+}
+
+// DeleteMedia middleware. Top level handler of /media/:id DELETE requests.
+func (controller mediaController) DeleteMedia(c *gin.Context) {
+	mediaService := controller.mediaService
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusNotFound, errortypes.MediaNotFoundError{})
+		return
+	}
+
+	err = mediaService.DeleteMedia(uint(id), c.GetString("user"))
+
+	switch err.(type) {
+	case nil:
+		c.Status(http.StatusNoContent)
+
+	case errortypes.MediaNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	case errortypes.MediaDeleteForbiddenError:
+		_ = c.AbortWithError(http.StatusForbidden, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedMediaError{Media: types.Media{ID: uint(id)}})
+	}
+}
+
+// GetMedia middleware. Top level handler of /media/:id GET requests.
+func (controller mediaController) GetMedia(c *gin.Context) {
+	mediaService := controller.mediaService
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusNotFound, errortypes.MediaNotFoundError{})
+		return
+	}
+
+	media, err := mediaService.GetMedia(uint(id))
+
+	switch err.(type) {
+	case nil:
+	case errortypes.MediaNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+		return
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedMediaError{Media: types.Media{ID: uint(id)}})
+		return
+	}
+
+	serveStoredFile(c, mediaService, media.StorageKey, media.Checksum, media.CreationTime, media.Size, media.ContentType)
+}
+
+// GetMediaList middleware. Top level handler of /media GET requests.
+func (controller mediaController) GetMediaList(c *gin.Context) {
+	mediaService := controller.mediaService
+
+	media, err := mediaService.GetMediaList()
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedMediaError{})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, media)
+}
+
+// GetMediaVariant middleware. Top level handler of /media/:id/variants/:name GET requests.
+// Serves a responsive variant of an uploaded image, generating it on the first request if necessary.
+func (controller mediaController) GetMediaVariant(c *gin.Context) {
+	mediaService := controller.mediaService
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusNotFound, errortypes.MediaNotFoundError{})
+		return
+	}
+
+	variant, err := mediaService.GetMediaVariant(uint(id), c.Param("name"))
+
+	switch err.(type) {
+	case nil:
+	case errortypes.MediaNotFoundError, errortypes.MediaVariantNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+		return
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedMediaError{Media: types.Media{ID: uint(id)}})
+		return
+	}
+
+	serveStoredFile(c, mediaService, variant.StorageKey, variant.Checksum, variant.CreationTime, variant.Size, variant.ContentType)
+}
+
+// UploadMedia middleware. Top level handler of /media POST requests.
+// The file is expected in the "file" field of a multipart form.
+func (controller mediaController) UploadMedia(c *gin.Context) {
+	mediaService := controller.mediaService
+	limit := mediaService.MaxUploadSize()
+
+	// Leave some room for the multipart framing around the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit+1<<20)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			_ = c.AbortWithError(http.StatusRequestEntityTooLarge, errortypes.MediaTooLargeError{Limit: limit})
+			return
+		}
+		_ = c.AbortWithError(http.StatusBadRequest, errortypes.MissingMediaFileError{})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedMediaError{})
+		return
+	}
+	defer file.Close()
+
+	media, err := mediaService.UploadMedia(file, header.Filename, c.GetString("user"))
+
+	switch err.(type) {
+	case nil:
+		c.Header("Location", media.URL)
+		c.IndentedJSON(http.StatusCreated, media)
+
+	case errortypes.MissingMediaFileError:
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+
+	case errortypes.MediaTooLargeError, errortypes.ImageTooLargeError:
+		_ = c.AbortWithError(http.StatusRequestEntityTooLarge, err)
+
+	case errortypes.UnsupportedMediaTypeError:
+		_ = c.AbortWithError(http.StatusUnsupportedMediaType, err)
+
+	case errortypes.InvalidImageError:
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedMediaError{})
+	}
+}
+
+// serveStoredFile writes the content of a stored file to the response.
+// Stored files never change, so they are served with long-lived caching headers and conditional requests are answered using their checksum.
+// If the file storage supports direct downloads, the client is redirected to a temporary URL of the file instead.
+func serveStoredFile(c *gin.Context, mediaService services.MediaService, key string, checksum string, modified time.Time, size int64, contentType string) {
+	etag := fmt.Sprintf("\"%s\"", checksum)
+	notModified := matchesETag(c.GetHeader("If-None-Match"), etag)
+
+	if !notModified {
+		location, err := mediaService.FileURL(key)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedMediaError{})
+			return
+		}
+
+		if location != "" {
+			// The temporary URL expires, so the redirect itself must not be cached
+			c.Header("Cache-Control", "no-store")
+			c.Redirect(http.StatusFound, location)
+			return
+		}
+	}
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	c.Header("X-Content-Type-Options", "nosniff")
+
+	if notModified {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	content, err := mediaService.OpenFile(key)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedMediaError{})
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, size, contentType, content, nil)
+}
+
+// matchesETag checks whether the value of an If-None-Match header matches the given entity tag.
+func matchesETag(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
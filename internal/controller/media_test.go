@@ -0,0 +1,331 @@
+package controller_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/controller"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/test"
+	"github.com/wlchs/blog/internal/types"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// mediaTestContext contains commonly used services, controllers and other objects relevant for testing the MediaController.
+type mediaTestContext struct {
+	mockMediaService *mocks.MockMediaService
+	sut              controller.MediaController
+	ctx              *gin.Context
+	rec              *httptest.ResponseRecorder
+}
+
+// createMediaControllerContext creates the context for testing the MediaController and reduces code duplication.
+func createMediaControllerContext(t *testing.T) *mediaTestContext {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	mockMediaService := mocks.NewMockMediaService(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	sut := controller.CreateMediaController(cont, mockMediaService)
+	ctx, rec := test.CreateControllerContext()
+
+	return &mediaTestContext{mockMediaService, sut, ctx, rec}
+}
+
+// mockFileUpload sets a multipart request body containing a single file in the "file" field.
+func mockFileUpload(t *testing.T, c *gin.Context, fileName string, content []byte) {
+	t.Helper()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", fileName)
+	_, _ = part.Write(content)
+	_ = writer.Close()
+
+	c.Request.Method = "POST"
+	c.Request.Header.Set("Content-Type", writer.FormDataContentType())
+	c.Request.Body = io.NopCloser(body)
+}
+
+// TestMediaController_UploadMedia tests uploading a new file.
+func TestMediaController_UploadMedia(t *testing.T) {
+	t.Parallel()
+	c := createMediaControllerContext(t)
+
+	expected := types.Media{ID: 1, FileName: "test.png", ContentType: "image/png", Uploader: "testAuthor", URL: "/media/1"}
+
+	mockFileUpload(t, c.ctx, "test.png", []byte("content"))
+	c.ctx.Set("user", "testAuthor")
+	c.mockMediaService.EXPECT().MaxUploadSize().Return(int64(1024))
+	c.mockMediaService.EXPECT().UploadMedia(gomock.Any(), "test.png", "testAuthor").Return(expected, nil)
+
+	c.sut.UploadMedia(c.ctx)
+
+	var output types.Media
+	_ = json.Unmarshal(c.rec.Body.Bytes(), &output)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, expected, output, "response body should match")
+	assert.Equal(t, "/media/1", c.rec.Header().Get("Location"), "location header should point to the file")
+	assert.Equal(t, 201, c.rec.Code, "incorrect response status")
+}
+
+// TestMediaController_UploadMedia_Missing_File tests uploading without providing a file.
+func TestMediaController_UploadMedia_Missing_File(t *testing.T) {
+	t.Parallel()
+	c := createMediaControllerContext(t)
+
+	test.MockJsonPost(c.ctx, types.Media{})
+	c.mockMediaService.EXPECT().MaxUploadSize().Return(int64(1024))
+
+	c.sut.UploadMedia(c.ctx)
+
+	assert.Equal(t, errortypes.MissingMediaFileError{}, c.ctx.Errors.Last().Err, "response should contain the expected error")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
+
+// TestMediaController_UploadMedia_Too_Large tests uploading a file exceeding the size limit.
+func TestMediaController_UploadMedia_Too_Large(t *testing.T) {
+	t.Parallel()
+	c := createMediaControllerContext(t)
+
+	mockFileUpload(t, c.ctx, "test.png", make([]byte, 2<<20))
+	c.mockMediaService.EXPECT().MaxUploadSize().Return(int64(1024))
+
+	c.sut.UploadMedia(c.ctx)
+
+	assert.Equal(t, errortypes.MediaTooLargeError{Limit: 1024}, c.ctx.Errors.Last().Err, "response should contain the expected error")
+	assert.Equal(t, 413, c.rec.Code, "incorrect response status")
+}
+
+// TestMediaController_UploadMedia_Too_Many_Pixels tests uploading an image exceeding the pixel limit.
+func TestMediaController_UploadMedia_Too_Many_Pixels(t *testing.T) {
+	t.Parallel()
+	c := createMediaControllerContext(t)
+
+	expectedError := errortypes.ImageTooLargeError{Limit: 100}
+
+	mockFileUpload(t, c.ctx, "test.png", []byte("\x89PNG\r\n\x1a\n"))
+	c.mockMediaService.EXPECT().MaxUploadSize().Return(int64(1024))
+	c.mockMediaService.EXPECT().UploadMedia(gomock.Any(), "test.png", "").Return(types.Media{}, expectedError)
+
+	c.sut.UploadMedia(c.ctx)
+
+	assert.Equal(t, expectedError, c.ctx.Errors.Last().Err, "response should contain the expected error")
+	assert.Equal(t, 413, c.rec.Code, "incorrect response status")
+}
+
+// TestMediaController_UploadMedia_Unsupported_Type tests uploading a file of an unsupported media type.
+func TestMediaController_UploadMedia_Unsupported_Type(t *testing.T) {
+	t.Parallel()
+	c := createMediaControllerContext(t)
+
+	expectedError := errortypes.UnsupportedMediaTypeError{ContentType: "text/html"}
+
+	mockFileUpload(t, c.ctx, "test.html", []byte("<html></html>"))
+	c.mockMediaService.EXPECT().MaxUploadSize().Return(int64(1024))
+	c.mockMediaService.EXPECT().UploadMedia(gomock.Any(), "test.html", "").Return(types.Media{}, expectedError)
+
+	c.sut.UploadMedia(c.ctx)
+
+	assert.Equal(t, expectedError, c.ctx.Errors.Last().Err, "response should contain the expected error")
+	assert.Equal(t, 415, c.rec.Code, "incorrect response status")
+}
+
+// TestMediaController_GetMedia tests serving the content of an uploaded file.
+func TestMediaController_GetMedia(t *testing.T) {
+	t.Parallel()
+	c := createMediaControllerContext(t)
+
+	media := types.Media{ID: 1, ContentType: "image/png", Size: 7, Checksum: "checksum", CreationTime: time.Now(), StorageKey: "media/test.png"}
+
+	c.ctx.AddParam("id", "1")
+	c.mockMediaService.EXPECT().GetMedia(uint(1)).Return(media, nil)
+	c.mockMediaService.EXPECT().FileURL(media.StorageKey).Return("", nil)
+	c.mockMediaService.EXPECT().OpenFile(media.StorageKey).Return(io.NopCloser(bytes.NewReader([]byte("content"))), nil)
+
+	c.sut.GetMedia(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, "content", c.rec.Body.String(), "response body should contain the file")
+	assert.Equal(t, "image/png", c.rec.Header().Get("Content-Type"), "content type should match the file")
+	assert.Equal(t, "\"checksum\"", c.rec.Header().Get("ETag"), "entity tag should be the checksum")
+	assert.Contains(t, c.rec.Header().Get("Cache-Control"), "immutable", "file should be cached")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestMediaController_GetMedia_Redirect tests redirecting to a temporary URL of the file storage.
+func TestMediaController_GetMedia_Redirect(t *testing.T) {
+	t.Parallel()
+	c := createMediaControllerContext(t)
+
+	media := types.Media{ID: 1, ContentType: "image/png", Size: 7, Checksum: "checksum", StorageKey: "media/test.png"}
+	location := "https://storage.example.com/blog/media/test.png?X-Amz-Signature=signature"
+
+	c.ctx.AddParam("id", "1")
+	c.mockMediaService.EXPECT().GetMedia(uint(1)).Return(media, nil)
+	c.mockMediaService.EXPECT().FileURL(media.StorageKey).Return(location, nil)
+
+	c.sut.GetMedia(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, location, c.rec.Header().Get("Location"), "client should be redirected to the file storage")
+	assert.Equal(t, "no-store", c.rec.Header().Get("Cache-Control"), "redirect shouldn't be cached")
+	assert.Equal(t, 302, c.ctx.Writer.Status(), "incorrect response status")
+}
+
+// TestMediaController_GetMedia_Not_Modified tests revalidating a cached file.
+func TestMediaController_GetMedia_Not_Modified(t *testing.T) {
+	t.Parallel()
+	c := createMediaControllerContext(t)
+
+	media := types.Media{ID: 1, ContentType: "image/png", Size: 7, Checksum: "checksum"}
+
+	c.ctx.AddParam("id", "1")
+	c.ctx.Request.Header.Set("If-None-Match", "W/\"other\", \"checksum\"")
+	c.mockMediaService.EXPECT().GetMedia(uint(1)).Return(media, nil)
+
+	c.sut.GetMedia(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 304, c.ctx.Writer.Status(), "incorrect response status")
+}
+
+// TestMediaController_GetMedia_Not_Found tests getting a non-existent file.
+func TestMediaController_GetMedia_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createMediaControllerContext(t)
+
+	expectedError := errortypes.MediaNotFoundError{Media: types.Media{ID: 1}}
+
+	c.ctx.AddParam("id", "1")
+	c.mockMediaService.EXPECT().GetMedia(uint(1)).Return(types.Media{}, expectedError)
+
+	c.sut.GetMedia(c.ctx)
+
+	assert.Equal(t, expectedError, c.ctx.Errors.Last().Err, "response should contain the expected error")
+	assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+}
+
+// TestMediaController_GetMedia_Invalid_ID tests getting a file with a malformed ID.
+func TestMediaController_GetMedia_Invalid_ID(t *testing.T) {
+	t.Parallel()
+	c := createMediaControllerContext(t)
+
+	c.ctx.AddParam("id", "abc")
+
+	c.sut.GetMedia(c.ctx)
+
+	assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+}
+
+// TestMediaController_GetMediaList tests getting the metadata of every uploaded file.
+func TestMediaController_GetMediaList(t *testing.T) {
+	t.Parallel()
+	c := createMediaControllerContext(t)
+
+	expected := []types.Media{{ID: 1, FileName: "test.png", URL: "/media/1"}}
+	c.mockMediaService.EXPECT().GetMediaList().Return(expected, nil)
+
+	c.sut.GetMediaList(c.ctx)
+
+	var output []types.Media
+	_ = json.Unmarshal(c.rec.Body.Bytes(), &output)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, expected, output, "response body should match")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestMediaController_GetMediaList_Unexpected_Error tests handling an unexpected error while getting every uploaded file.
+func TestMediaController_GetMediaList_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createMediaControllerContext(t)
+
+	c.mockMediaService.EXPECT().GetMediaList().Return(nil, fmt.Errorf("error"))
+
+	c.sut.GetMediaList(c.ctx)
+
+	assert.Equal(t, errortypes.UnexpectedMediaError{}, c.ctx.Errors.Last().Err, "response should contain the expected error")
+	assert.Equal(t, 500, c.rec.Code, "incorrect response status")
+}
+
+// TestMediaController_DeleteMedia tests removing an uploaded file.
+func TestMediaController_DeleteMedia(t *testing.T) {
+	t.Parallel()
+	c := createMediaControllerContext(t)
+
+	c.ctx.AddParam("id", "1")
+	c.ctx.Set("user", "testAuthor")
+	c.mockMediaService.EXPECT().DeleteMedia(uint(1), "testAuthor").Return(nil)
+
+	c.sut.DeleteMedia(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 204, c.ctx.Writer.Status(), "incorrect response status")
+}
+
+// TestMediaController_DeleteMedia_Forbidden tests removing a file uploaded by another user.
+func TestMediaController_DeleteMedia_Forbidden(t *testing.T) {
+	t.Parallel()
+	c := createMediaControllerContext(t)
+
+	expectedError := errortypes.MediaDeleteForbiddenError{Media: types.Media{ID: 1}, UserName: "otherUser"}
+
+	c.ctx.AddParam("id", "1")
+	c.ctx.Set("user", "otherUser")
+	c.mockMediaService.EXPECT().DeleteMedia(uint(1), "otherUser").Return(expectedError)
+
+	c.sut.DeleteMedia(c.ctx)
+
+	assert.Equal(t, expectedError, c.ctx.Errors.Last().Err, "response should contain the expected error")
+	assert.Equal(t, 403, c.rec.Code, "incorrect response status")
+}
+
+// TestMediaController_GetMediaVariant tests serving a responsive variant of an image.
+func TestMediaController_GetMediaVariant(t *testing.T) {
+	t.Parallel()
+	c := createMediaControllerContext(t)
+
+	variant := types.MediaVariant{Name: "thumbnail", ContentType: "image/png", Size: 7, Checksum: "variant", StorageKey: "media/test_thumbnail.png"}
+
+	c.ctx.AddParam("id", "1")
+	c.ctx.AddParam("name", "thumbnail")
+	c.mockMediaService.EXPECT().GetMediaVariant(uint(1), "thumbnail").Return(variant, nil)
+	c.mockMediaService.EXPECT().FileURL(variant.StorageKey).Return("", nil)
+	c.mockMediaService.EXPECT().OpenFile(variant.StorageKey).Return(io.NopCloser(bytes.NewReader([]byte("content"))), nil)
+
+	c.sut.GetMediaVariant(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, "content", c.rec.Body.String(), "response body should contain the variant")
+	assert.Equal(t, "\"variant\"", c.rec.Header().Get("ETag"), "entity tag should be the checksum of the variant")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestMediaController_GetMediaVariant_Not_Found tests getting a variant which can't be generated.
+func TestMediaController_GetMediaVariant_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createMediaControllerContext(t)
+
+	expectedError := errortypes.MediaVariantNotFoundError{Media: types.Media{ID: 1}, Variant: "large"}
+
+	c.ctx.AddParam("id", "1")
+	c.ctx.AddParam("name", "large")
+	c.mockMediaService.EXPECT().GetMediaVariant(uint(1), "large").Return(types.MediaVariant{}, expectedError)
+
+	c.sut.GetMediaVariant(c.ctx)
+
+	assert.Equal(t, expectedError, c.ctx.Errors.Last().Err, "response should contain the expected error")
+	assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+}
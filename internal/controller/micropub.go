@@ -0,0 +1,213 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/types"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// MicropubController interface defining the middleware methods of the Micropub endpoint and its media endpoint
+type MicropubController interface {
+	GetMicropub(c *gin.Context)
+	PostMicropub(c *gin.Context)
+	UploadMicropubMedia(c *gin.Context)
+}
+
+// micropubController is a concrete implementation of the MicropubController interface
+type micropubController struct {
+	cont            container.Container
+	micropubService services.MicropubService
+	mediaService    services.MediaService
+}
+
+// CreateMicropubController instantiates a Micropub controller using the application container.
+func CreateMicropubController(cont container.Container, micropubService services.MicropubService, mediaService services.MediaService) MicropubController {
+	return &micropubController{cont, micropubService, mediaService}
+}
+
+// GetMicropub middleware. Top level handler of /micropub GET requests, answering the config, source and syndicate-to queries.
+func (controller micropubController) GetMicropub(c *gin.Context) {
+	micropubService := controller.micropubService
+
+	switch query := c.Query("q"); query {
+	case "config":
+		c.IndentedJSON(http.StatusOK, micropubService.GetConfig())
+
+	case "syndicate-to":
+		c.IndentedJSON(http.StatusOK, types.MicropubSyndicationTargets{SyndicateTo: micropubService.GetConfig().SyndicateTo})
+
+	case "source":
+		properties := append(c.QueryArray("properties[]"), c.QueryArray("properties")...)
+		entry, err := micropubService.GetSource(c.Query("url"), properties, c.GetString("user"))
+		if err != nil {
+			abortMicropub(c, err)
+			return
+		}
+		c.IndentedJSON(http.StatusOK, entry)
+
+	default:
+		abortMicropub(c, errortypes.InvalidMicropubRequestError{Reason: "unsupported query \"" + query + "\""})
+	}
+}
+
+// PostMicropub middleware. Top level handler of /micropub POST requests.
+// Posts are created from JSON or form-encoded requests, updates are only accepted as JSON.
+func (controller micropubController) PostMicropub(c *gin.Context) {
+	micropubService := controller.micropubService
+
+	var request types.MicropubRequest
+	if c.ContentType() == binding.MIMEJSON {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			abortMicropub(c, errortypes.InvalidMicropubRequestError{Reason: err.Error()})
+			return
+		}
+	} else {
+		form, err := micropubForm(c)
+		if err != nil {
+			abortMicropub(c, errortypes.InvalidMicropubRequestError{Reason: "failed to parse the form"})
+			return
+		}
+		request = parseMicropubForm(form)
+	}
+
+	userName := c.GetString("user")
+
+	switch request.Action {
+	case "":
+		location, err := micropubService.CreatePost(request.MicropubEntry, userName)
+		if err != nil {
+			abortMicropub(c, err)
+			return
+		}
+		c.Header("Location", location)
+		c.Status(http.StatusCreated)
+
+	case types.MicropubActionUpdate:
+		if err := micropubService.UpdatePost(request.MicropubUpdate, userName); err != nil {
+			abortMicropub(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+
+	case types.MicropubActionDelete:
+		if err := micropubService.DeletePost(request.URL, userName); err != nil {
+			abortMicropub(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+
+	default:
+		abortMicropub(c, errortypes.InvalidMicropubRequestError{Reason: "unsupported action \"" + request.Action + "\""})
+	}
+}
+
+// UploadMicropubMedia middleware. Top level handler of /micropub/media POST requests.
+// The file is expected in the "file" field of a multipart form, its absolute URL is returned in the Location header.
+func (controller micropubController) UploadMicropubMedia(c *gin.Context) {
+	micropubService := controller.micropubService
+	limit := controller.mediaService.MaxUploadSize()
+
+	// Leave some room for the multipart framing around the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit+1<<20)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			abortMicropub(c, errortypes.MediaTooLargeError{Limit: limit})
+			return
+		}
+		abortMicropub(c, errortypes.MissingMediaFileError{})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		abortMicropub(c, err)
+		return
+	}
+	defer file.Close()
+
+	media, err := micropubService.UploadMedia(file, header.Filename, c.GetString("user"))
+	if err != nil {
+		abortMicropub(c, err)
+		return
+	}
+
+	c.Header("Location", media.URL)
+	c.IndentedJSON(http.StatusCreated, media)
+}
+
+// micropubForm parses the form-encoded or multipart body of a Micropub request.
+func micropubForm(c *gin.Context) (url.Values, error) {
+	if c.ContentType() == binding.MIMEMultipartPOSTForm {
+		if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
+			return nil, err
+		}
+	} else if err := c.Request.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	return c.Request.PostForm, nil
+}
+
+// parseMicropubForm maps the fields of a form-encoded request to the properties of an h-entry.
+// The type is given by the h field, array properties are sent with the [] suffix.
+func parseMicropubForm(form url.Values) types.MicropubRequest {
+	request := types.MicropubRequest{Action: form.Get("action")}
+	request.URL = form.Get("url")
+	request.Properties = map[string][]interface{}{}
+
+	if h := form.Get("h"); h != "" {
+		request.Type = []string{"h-" + h}
+	}
+
+	for key, values := range form {
+		switch key {
+		case "h", "action", "url", "access_token":
+			continue
+		}
+
+		name := strings.TrimSuffix(key, "[]")
+		for _, value := range values {
+			request.Properties[name] = append(request.Properties[name], value)
+		}
+	}
+
+	return request
+}
+
+// abortMicropub aborts a Micropub request, describing the error in the JSON format of the Micropub specification.
+func abortMicropub(c *gin.Context, err error) {
+	status, code := http.StatusInternalServerError, "server_error"
+
+	switch err.(type) {
+	case errortypes.InvalidMicropubRequestError, errortypes.PostNotFoundError, errortypes.DuplicateElementError,
+		errortypes.InvalidPostVisibilityError, errortypes.MissingPostPasswordError, errortypes.InvalidLanguageError,
+		errortypes.InvalidCustomFieldError, errortypes.MissingCustomFieldError, errortypes.InvalidCanonicalURLError,
+		errortypes.InvalidCoverImageError, errortypes.MissingMediaFileError, errortypes.InvalidImageError:
+		status, code = http.StatusBadRequest, "invalid_request"
+
+	case errortypes.PostEditForbiddenError, errortypes.PostDeleteForbiddenError:
+		status, code = http.StatusForbidden, "forbidden"
+
+	case errortypes.MediaTooLargeError:
+		status, code = http.StatusRequestEntityTooLarge, "invalid_request"
+
+	case errortypes.UnsupportedMediaTypeError:
+		status, code = http.StatusUnsupportedMediaType, "invalid_request"
+
+	default:
+		err = errortypes.UnexpectedMicropubError{}
+	}
+
+	_ = c.Error(err)
+	c.AbortWithStatusJSON(status, types.MicropubError{Error: code, Description: err.Error()})
+}
@@ -0,0 +1,246 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/controller"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/test"
+	"github.com/wlchs/blog/internal/types"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// micropubTestContext contains commonly used services, controllers and other objects relevant for testing the MicropubController.
+type micropubTestContext struct {
+	mockMicropubService *mocks.MockMicropubService
+	mockMediaService    *mocks.MockMediaService
+	sut                 controller.MicropubController
+	ctx                 *gin.Context
+	rec                 *httptest.ResponseRecorder
+}
+
+// createMicropubControllerContext creates the context for testing the MicropubController and reduces code duplication.
+func createMicropubControllerContext(t *testing.T) *micropubTestContext {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	mockMicropubService := mocks.NewMockMicropubService(mockCtrl)
+	mockMediaService := mocks.NewMockMediaService(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	sut := controller.CreateMicropubController(cont, mockMicropubService, mockMediaService)
+	ctx, rec := test.CreateControllerContext()
+
+	return &micropubTestContext{mockMicropubService, mockMediaService, sut, ctx, rec}
+}
+
+// TestMicropubController_GetMicropub_Config tests retrieving the configuration of the endpoint.
+func TestMicropubController_GetMicropub_Config(t *testing.T) {
+	t.Parallel()
+	c := createMicropubControllerContext(t)
+
+	expected := types.MicropubConfig{MediaEndpoint: "https://blog.example.com/micropub/media", SyndicateTo: []string{}, Q: []string{"config"}}
+
+	c.ctx.Request.URL.RawQuery = "q=config"
+	c.mockMicropubService.EXPECT().GetConfig().Return(expected)
+
+	c.sut.GetMicropub(c.ctx)
+
+	var output types.MicropubConfig
+	_ = json.Unmarshal(c.rec.Body.Bytes(), &output)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, expected, output, "response body should match")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestMicropubController_GetMicropub_Source tests retrieving selected properties of a post.
+func TestMicropubController_GetMicropub_Source(t *testing.T) {
+	t.Parallel()
+	c := createMicropubControllerContext(t)
+
+	expected := types.MicropubEntry{Properties: map[string][]interface{}{"name": {"Hello"}}}
+
+	c.ctx.Request.URL.RawQuery = "q=source&url=https%3A%2F%2Fblog.example.com%2Fposts%2Fhello&properties[]=name"
+	c.ctx.Set("user", "testAuthor")
+	c.mockMicropubService.EXPECT().GetSource("https://blog.example.com/posts/hello", []string{"name"}, "testAuthor").Return(expected, nil)
+
+	c.sut.GetMicropub(c.ctx)
+
+	var output types.MicropubEntry
+	_ = json.Unmarshal(c.rec.Body.Bytes(), &output)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, expected, output, "response body should match")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestMicropubController_GetMicropub_Unsupported tests an unsupported query.
+func TestMicropubController_GetMicropub_Unsupported(t *testing.T) {
+	t.Parallel()
+	c := createMicropubControllerContext(t)
+
+	c.ctx.Request.URL.RawQuery = "q=channels"
+
+	c.sut.GetMicropub(c.ctx)
+
+	var output types.MicropubError
+	_ = json.Unmarshal(c.rec.Body.Bytes(), &output)
+
+	assert.Equal(t, 1, len(c.ctx.Errors), "expected exactly 1 error")
+	assert.Equal(t, "invalid_request", output.Error, "incorrect error code")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
+
+// TestMicropubController_PostMicropub_Form tests creating a post from a form-encoded request.
+func TestMicropubController_PostMicropub_Form(t *testing.T) {
+	t.Parallel()
+	c := createMicropubControllerContext(t)
+
+	expectedEntry := types.MicropubEntry{
+		Type:       []string{"h-entry"},
+		Properties: map[string][]interface{}{"content": {"Hello world"}, "category": {"go", "indieweb"}},
+	}
+
+	mockFormPost(c.ctx, url.Values{"h": {"entry"}, "content": {"Hello world"}, "category[]": {"go", "indieweb"}, "access_token": {"token"}})
+	c.ctx.Set("user", "testAuthor")
+	c.mockMicropubService.EXPECT().CreatePost(expectedEntry, "testAuthor").Return("https://blog.example.com/posts/hello-world", nil)
+
+	c.sut.PostMicropub(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, "https://blog.example.com/posts/hello-world", c.rec.Header().Get("Location"), "location header should point to the post")
+	assert.Equal(t, 201, c.ctx.Writer.Status(), "incorrect response status")
+}
+
+// TestMicropubController_PostMicropub_JSON tests creating a post from a JSON request.
+func TestMicropubController_PostMicropub_JSON(t *testing.T) {
+	t.Parallel()
+	c := createMicropubControllerContext(t)
+
+	entry := types.MicropubEntry{
+		Type:       []string{"h-entry"},
+		Properties: map[string][]interface{}{"name": {"Hello"}, "content": {map[string]interface{}{"html": "<p>Hello</p>"}}},
+	}
+
+	test.MockJsonPost(c.ctx, entry)
+	c.ctx.Set("user", "testAuthor")
+	c.mockMicropubService.EXPECT().CreatePost(entry, "testAuthor").Return("https://blog.example.com/posts/hello", nil)
+
+	c.sut.PostMicropub(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 201, c.ctx.Writer.Status(), "incorrect response status")
+}
+
+// TestMicropubController_PostMicropub_Update tests updating a post.
+func TestMicropubController_PostMicropub_Update(t *testing.T) {
+	t.Parallel()
+	c := createMicropubControllerContext(t)
+
+	request := types.MicropubRequest{
+		Action: types.MicropubActionUpdate,
+		MicropubUpdate: types.MicropubUpdate{
+			URL:     "https://blog.example.com/posts/hello",
+			Replace: map[string][]interface{}{"name": {"Updated"}},
+			Delete:  []interface{}{"category"},
+		},
+	}
+
+	test.MockJsonPost(c.ctx, request)
+	c.ctx.Set("user", "testAuthor")
+	c.mockMicropubService.EXPECT().UpdatePost(request.MicropubUpdate, "testAuthor").Return(nil)
+
+	c.sut.PostMicropub(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 204, c.ctx.Writer.Status(), "incorrect response status")
+}
+
+// TestMicropubController_PostMicropub_Update_Forbidden tests updating the post of another user.
+func TestMicropubController_PostMicropub_Update_Forbidden(t *testing.T) {
+	t.Parallel()
+	c := createMicropubControllerContext(t)
+
+	expectedError := errortypes.PostEditForbiddenError{Post: types.Post{URLHandle: "hello"}, UserName: "otherUser"}
+
+	test.MockJsonPost(c.ctx, types.MicropubRequest{Action: types.MicropubActionUpdate, MicropubUpdate: types.MicropubUpdate{URL: "/posts/hello"}})
+	c.ctx.Set("user", "otherUser")
+	c.mockMicropubService.EXPECT().UpdatePost(types.MicropubUpdate{URL: "/posts/hello"}, "otherUser").Return(expectedError)
+
+	c.sut.PostMicropub(c.ctx)
+
+	var output types.MicropubError
+	_ = json.Unmarshal(c.rec.Body.Bytes(), &output)
+
+	assert.Equal(t, expectedError, c.ctx.Errors.Last().Err, "response should contain the expected error")
+	assert.Equal(t, types.MicropubError{Error: "forbidden", Description: expectedError.Error()}, output, "response body should describe the error")
+	assert.Equal(t, 403, c.rec.Code, "incorrect response status")
+}
+
+// TestMicropubController_PostMicropub_Delete tests deleting a post from a form-encoded request.
+func TestMicropubController_PostMicropub_Delete(t *testing.T) {
+	t.Parallel()
+	c := createMicropubControllerContext(t)
+
+	mockFormPost(c.ctx, url.Values{"action": {"delete"}, "url": {"https://blog.example.com/posts/hello"}})
+	c.ctx.Set("user", "testAuthor")
+	c.mockMicropubService.EXPECT().DeletePost("https://blog.example.com/posts/hello", "testAuthor").Return(nil)
+
+	c.sut.PostMicropub(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 204, c.ctx.Writer.Status(), "incorrect response status")
+}
+
+// TestMicropubController_PostMicropub_Unsupported_Action tests an unsupported action.
+func TestMicropubController_PostMicropub_Unsupported_Action(t *testing.T) {
+	t.Parallel()
+	c := createMicropubControllerContext(t)
+
+	mockFormPost(c.ctx, url.Values{"action": {"undelete"}, "url": {"https://blog.example.com/posts/hello"}})
+
+	c.sut.PostMicropub(c.ctx)
+
+	assert.IsType(t, errortypes.InvalidMicropubRequestError{}, c.ctx.Errors.Last().Err, "incorrect error type")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
+
+// TestMicropubController_UploadMicropubMedia tests uploading a file to the media endpoint.
+func TestMicropubController_UploadMicropubMedia(t *testing.T) {
+	t.Parallel()
+	c := createMicropubControllerContext(t)
+
+	expected := types.Media{ID: 1, FileName: "test.png", URL: "https://blog.example.com/media/1"}
+
+	mockFileUpload(t, c.ctx, "test.png", []byte("content"))
+	c.ctx.Set("user", "testAuthor")
+	c.mockMediaService.EXPECT().MaxUploadSize().Return(int64(1024))
+	c.mockMicropubService.EXPECT().UploadMedia(gomock.Any(), "test.png", "testAuthor").Return(expected, nil)
+
+	c.sut.UploadMicropubMedia(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, "https://blog.example.com/media/1", c.rec.Header().Get("Location"), "location header should point to the file")
+	assert.Equal(t, 201, c.rec.Code, "incorrect response status")
+}
+
+// TestMicropubController_UploadMicropubMedia_Missing_File tests uploading without providing a file.
+func TestMicropubController_UploadMicropubMedia_Missing_File(t *testing.T) {
+	t.Parallel()
+	c := createMicropubControllerContext(t)
+
+	mockFormPost(c.ctx, url.Values{})
+	c.mockMediaService.EXPECT().MaxUploadSize().Return(int64(1024))
+
+	c.sut.UploadMicropubMedia(c.ctx)
+
+	assert.Equal(t, errortypes.MissingMediaFileError{}, c.ctx.Errors.Last().Err, "response should contain the expected error")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
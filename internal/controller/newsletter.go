@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/types"
+	"net/http"
+)
+
+// NewsletterController interface defining newsletter-related middleware methods to handle HTTP requests
+type NewsletterController interface {
+	ConfirmSubscription(c *gin.Context)
+	Subscribe(c *gin.Context)
+	Unsubscribe(c *gin.Context)
+}
+
+// newsletterController is a concrete implementation of the NewsletterController interface
+type newsletterController struct {
+	cont              container.Container
+	newsletterService services.NewsletterService
+}
+
+// CreateNewsletterController instantiates a newsletter controller using the application container.
+func CreateNewsletterController(cont container.Container, newsletterService services.NewsletterService) NewsletterController {
+	return &newsletterController{cont, newsletterService}
+}
+
+// ConfirmSubscription middleware. Top level handler of /subscribe/confirm GET requests.
+// The link of the confirmation email is opened in the browser, so the response is plain text.
+func (controller newsletterController) ConfirmSubscription(c *gin.Context) {
+	newsletterService := controller.newsletterService
+
+	err := newsletterService.ConfirmSubscription(c.Query("token"))
+
+	switch err.(type) {
+	case nil:
+		c.String(http.StatusOK, "Your subscription is confirmed.")
+
+	case errortypes.InvalidSubscriptionTokenError:
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+
+	case errortypes.SubscriberNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedNewsletterError{})
+	}
+}
+
+// Subscribe middleware. Top level handler of /subscribe POST requests.
+// The subscription is only active once the address is confirmed, so the request is accepted without further details.
+func (controller newsletterController) Subscribe(c *gin.Context) {
+	newsletterService := controller.newsletterService
+
+	var body types.SubscriptionInput
+	if err := c.BindJSON(&body); err != nil {
+		return
+	}
+
+	err := newsletterService.Subscribe(&body)
+
+	switch err.(type) {
+	case nil:
+		c.Status(http.StatusAccepted)
+
+	case errortypes.InvalidSubscriptionError:
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedNewsletterError{})
+	}
+}
+
+// Unsubscribe middleware. Top level handler of /unsubscribe GET and POST requests.
+// GET requests are sent by the link of the emails, POST requests by the one-click unsubscribe of the email clients.
+func (controller newsletterController) Unsubscribe(c *gin.Context) {
+	newsletterService := controller.newsletterService
+
+	err := newsletterService.Unsubscribe(c.Query("token"))
+
+	switch err.(type) {
+	case nil:
+		c.String(http.StatusOK, "You have been unsubscribed.")
+
+	case errortypes.InvalidSubscriptionTokenError:
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedNewsletterError{})
+	}
+}
@@ -0,0 +1,170 @@
+package controller_test
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/controller"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/test"
+	"github.com/wlchs/blog/internal/types"
+	"net/http/httptest"
+	"testing"
+)
+
+// newsletterTestContext contains commonly used services, controllers and other objects relevant for testing the NewsletterController.
+type newsletterTestContext struct {
+	mockNewsletterService *mocks.MockNewsletterService
+	sut                   controller.NewsletterController
+	ctx                   *gin.Context
+	rec                   *httptest.ResponseRecorder
+}
+
+// createNewsletterControllerContext creates the context for testing the NewsletterController and reduces code duplication.
+func createNewsletterControllerContext(t *testing.T) *newsletterTestContext {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	mockNewsletterService := mocks.NewMockNewsletterService(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	sut := controller.CreateNewsletterController(cont, mockNewsletterService)
+	ctx, rec := test.CreateControllerContext()
+
+	return &newsletterTestContext{mockNewsletterService, sut, ctx, rec}
+}
+
+// TestNewsletterController_Subscribe tests accepting a new subscription.
+func TestNewsletterController_Subscribe(t *testing.T) {
+	t.Parallel()
+	c := createNewsletterControllerContext(t)
+
+	input := types.SubscriptionInput{Email: "reader@example.com", Mode: types.NewsletterDigest}
+
+	test.MockJsonPost(c.ctx, input)
+	c.mockNewsletterService.EXPECT().Subscribe(&input).Return(nil)
+
+	c.sut.Subscribe(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 202, c.ctx.Writer.Status(), "incorrect response status")
+}
+
+// TestNewsletterController_Subscribe_Invalid tests subscribing with an invalid email address.
+func TestNewsletterController_Subscribe_Invalid(t *testing.T) {
+	t.Parallel()
+	c := createNewsletterControllerContext(t)
+
+	input := types.SubscriptionInput{Email: "reader"}
+	expectedError := errortypes.InvalidSubscriptionError{Reason: "invalid email address"}
+
+	test.MockJsonPost(c.ctx, input)
+	c.mockNewsletterService.EXPECT().Subscribe(&input).Return(expectedError)
+
+	c.sut.Subscribe(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
+
+// TestNewsletterController_Subscribe_Unexpected_Error tests handling an unexpected error while subscribing.
+func TestNewsletterController_Subscribe_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createNewsletterControllerContext(t)
+
+	input := types.SubscriptionInput{Email: "reader@example.com"}
+
+	test.MockJsonPost(c.ctx, input)
+	c.mockNewsletterService.EXPECT().Subscribe(&input).Return(fmt.Errorf("unexpected error"))
+
+	c.sut.Subscribe(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, errortypes.UnexpectedNewsletterError{}.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 500, c.rec.Code, "incorrect response status")
+}
+
+// TestNewsletterController_ConfirmSubscription tests confirming a subscription.
+func TestNewsletterController_ConfirmSubscription(t *testing.T) {
+	t.Parallel()
+	c := createNewsletterControllerContext(t)
+
+	c.ctx.Request.URL.RawQuery = "token=testToken"
+	c.mockNewsletterService.EXPECT().ConfirmSubscription("testToken").Return(nil)
+
+	c.sut.ConfirmSubscription(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestNewsletterController_ConfirmSubscription_Invalid_Token tests confirming a subscription with an invalid token.
+func TestNewsletterController_ConfirmSubscription_Invalid_Token(t *testing.T) {
+	t.Parallel()
+	c := createNewsletterControllerContext(t)
+
+	expectedError := errortypes.InvalidSubscriptionTokenError{}
+
+	c.ctx.Request.URL.RawQuery = "token=expired"
+	c.mockNewsletterService.EXPECT().ConfirmSubscription("expired").Return(expectedError)
+
+	c.sut.ConfirmSubscription(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
+
+// TestNewsletterController_ConfirmSubscription_Not_Found tests confirming the subscription of a removed subscriber.
+func TestNewsletterController_ConfirmSubscription_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createNewsletterControllerContext(t)
+
+	expectedError := errortypes.SubscriberNotFoundError{Email: "reader@example.com"}
+
+	c.ctx.Request.URL.RawQuery = "token=testToken"
+	c.mockNewsletterService.EXPECT().ConfirmSubscription("testToken").Return(expectedError)
+
+	c.sut.ConfirmSubscription(c.ctx)
+
+	assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+}
+
+// TestNewsletterController_Unsubscribe tests unsubscribing using the one-click unsubscribe link.
+func TestNewsletterController_Unsubscribe(t *testing.T) {
+	t.Parallel()
+	c := createNewsletterControllerContext(t)
+
+	c.ctx.Request.Method = "POST"
+	c.ctx.Request.URL.RawQuery = "token=testToken"
+	c.mockNewsletterService.EXPECT().Unsubscribe("testToken").Return(nil)
+
+	c.sut.Unsubscribe(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestNewsletterController_Unsubscribe_Invalid_Token tests unsubscribing with an invalid token.
+func TestNewsletterController_Unsubscribe_Invalid_Token(t *testing.T) {
+	t.Parallel()
+	c := createNewsletterControllerContext(t)
+
+	expectedError := errortypes.InvalidSubscriptionTokenError{}
+
+	c.mockNewsletterService.EXPECT().Unsubscribe("").Return(expectedError)
+
+	c.sut.Unsubscribe(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
@@ -4,27 +4,38 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/wlchs/blog/internal/container"
 	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/i18n"
 	"github.com/wlchs/blog/internal/services"
 	"github.com/wlchs/blog/internal/types"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 // PostController interface defining post-related middleware methods to handle HTTP requests
 type PostController interface {
 	AddPost(c *gin.Context)
+	AuthorizePostAccess(c *gin.Context)
+	DeletePost(c *gin.Context)
+	DeletePostTranslation(c *gin.Context)
 	GetPost(c *gin.Context)
 	GetPosts(c *gin.Context)
+	SetPostContributors(c *gin.Context)
+	SetPostHighlight(c *gin.Context)
+	SetPostTranslation(c *gin.Context)
+	UpdatePost(c *gin.Context)
 }
 
 // postController is a concrete implementation of the PostController interface
 type postController struct {
-	cont        container.Container
-	postService services.PostService
+	cont           container.Container
+	postService    services.PostService
+	relatedService services.RelatedService
 }
 
 // CreatePostController instantiates a post controller using the application container.
-func CreatePostController(cont container.Container, postService services.PostService) PostController {
-	return &postController{cont, postService}
+func CreatePostController(cont container.Container, postService services.PostService, relatedService services.RelatedService) PostController {
+	return &postController{cont, postService, relatedService}
 }
 
 // AddPost middleware. Top level handler of /posts POST requests.
@@ -36,8 +47,9 @@ func (controller postController) AddPost(c *gin.Context) {
 		return
 	}
 
-	// Set author from context
+	// Set author from context, the creation time can only be preserved by the importers
 	body.Author = c.GetString("user")
+	body.CreationTime = time.Time{}
 	post, err := postService.AddPost(&body)
 
 	switch err.(type) {
@@ -47,12 +59,114 @@ func (controller postController) AddPost(c *gin.Context) {
 	case errortypes.DuplicateElementError:
 		_ = c.AbortWithError(http.StatusConflict, err)
 
+	case errortypes.InvalidContributorRoleError, errortypes.InvalidPostVisibilityError, errortypes.MissingPostPasswordError, errortypes.InvalidLanguageError,
+		errortypes.InvalidCustomFieldError, errortypes.MissingCustomFieldError, errortypes.InvalidCanonicalURLError, errortypes.InvalidCoverImageError:
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+
+	case errortypes.UserNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
 	default:
 		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedPostError{Post: body})
 	}
 }
 
+// AuthorizePostAccess middleware. Top level handler of /posts/:id/access POST requests.
+// If the password of a password-protected post is correct, a post access token is returned in the X-Post-Token header.
+func (controller postController) AuthorizePostAccess(c *gin.Context) {
+	postService := controller.postService
+
+	id, found := c.Params.Get("id")
+	if !found {
+		_ = c.AbortWithError(http.StatusBadRequest, errortypes.MissingUrlHandleError{})
+		return
+	}
+
+	var body types.PostAccessInput
+	if err := c.BindJSON(&body); err != nil {
+		return
+	}
+
+	token, err := postService.AuthorizePostAccess(id, body.Password)
+
+	switch err.(type) {
+	case nil:
+		c.Header("X-Post-Token", token)
+		c.Status(http.StatusOK)
+
+	case errortypes.IncorrectPostPasswordError:
+		_ = c.AbortWithError(http.StatusUnauthorized, err)
+
+	case errortypes.PostNotPasswordProtectedError:
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+
+	case errortypes.PostNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedPostError{Post: types.Post{URLHandle: id}})
+	}
+}
+
+// DeletePost middleware. Top level handler of /posts/:id DELETE requests.
+func (controller postController) DeletePost(c *gin.Context) {
+	postService := controller.postService
+
+	id, found := c.Params.Get("id")
+	if !found {
+		_ = c.AbortWithError(http.StatusBadRequest, errortypes.MissingUrlHandleError{})
+		return
+	}
+
+	err := postService.DeletePost(id, c.GetString("user"))
+
+	switch err.(type) {
+	case nil:
+		c.Status(http.StatusNoContent)
+
+	case errortypes.PostDeleteForbiddenError:
+		_ = c.AbortWithError(http.StatusForbidden, err)
+
+	case errortypes.PostNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedPostError{Post: types.Post{URLHandle: id}})
+	}
+}
+
+// DeletePostTranslation middleware. Top level handler of /posts/:id/translations/:lang DELETE requests.
+func (controller postController) DeletePostTranslation(c *gin.Context) {
+	postService := controller.postService
+
+	id, found := c.Params.Get("id")
+	if !found {
+		_ = c.AbortWithError(http.StatusBadRequest, errortypes.MissingUrlHandleError{})
+		return
+	}
+
+	err := postService.DeletePostTranslation(id, c.Param("lang"), c.GetString("user"))
+
+	switch err.(type) {
+	case nil:
+		c.Status(http.StatusNoContent)
+
+	case errortypes.InvalidLanguageError:
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+
+	case errortypes.PostEditForbiddenError:
+		_ = c.AbortWithError(http.StatusForbidden, err)
+
+	case errortypes.PostNotFoundError, errortypes.TranslationNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedPostError{Post: types.Post{URLHandle: id}})
+	}
+}
+
 // GetPost middleware. Top level handler of /posts/:id GET requests.
+// The language of the post is negotiated using the lang query parameter and the Accept-Language header.
 func (controller postController) GetPost(c *gin.Context) {
 	postService := controller.postService
 
@@ -62,12 +176,33 @@ func (controller postController) GetPost(c *gin.Context) {
 		return
 	}
 
-	post, err := postService.GetPost(id)
+	access := types.PostAccess{
+		UserName:    c.GetString("user"),
+		AccessToken: c.Request.Header.Get("X-Post-Token"),
+	}
+
+	languages, ok := requestLanguages(c)
+	if !ok {
+		return
+	}
+
+	post, err := postService.GetPost(id, access, languages)
+	if err == nil {
+		post.Related, err = controller.relatedPosts(c, id)
+	}
 
 	switch err.(type) {
 	case nil:
+		c.Header("Content-Language", post.Language)
+		c.Header("Vary", "Accept-Language")
 		c.IndentedJSON(http.StatusOK, post)
 
+	case errortypes.InvalidRelatedPostsLimitError:
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+
+	case errortypes.PostPasswordRequiredError:
+		_ = c.AbortWithError(http.StatusUnauthorized, err)
+
 	case errortypes.PostNotFoundError:
 		_ = c.AbortWithError(http.StatusNotFound, err)
 
@@ -77,14 +212,215 @@ func (controller postController) GetPost(c *gin.Context) {
 }
 
 // GetPosts middleware. Top level handler of /posts GET requests.
+// The optional lang query parameter filters the posts by language,
+// the field[name]=value query parameters filter the posts by their custom fields.
+// Pinned posts are listed first. The featured query parameter only lists the featured posts, the collection query
+// parameter only the posts of the given collection in their curated order.
 func (controller postController) GetPosts(c *gin.Context) {
 	postService := controller.postService
 
-	posts, err := postService.GetPosts()
-	if err != nil {
+	filter := types.PostFilter{Fields: c.QueryMap("field"), Collection: c.Query("collection"), PinnedFirst: true}
+	if lang := c.Query("lang"); lang != "" {
+		tag, ok := i18n.NormalizeTag(lang)
+		if !ok {
+			_ = c.AbortWithError(http.StatusBadRequest, errortypes.InvalidLanguageError{Language: lang})
+			return
+		}
+		filter.Language = tag
+	}
+	if featured := c.Query("featured"); featured != "" {
+		value, err := strconv.ParseBool(featured)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusBadRequest, errortypes.InvalidPostFilterError{Parameter: "featured", Value: featured})
+			return
+		}
+		filter.Featured = value
+	}
+
+	posts, err := postService.GetPosts(filter)
+
+	switch err.(type) {
+	case nil:
+		c.IndentedJSON(http.StatusOK, posts)
+
+	case errortypes.InvalidCustomFieldError:
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+
+	case errortypes.CollectionNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	default:
 		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedPostError{})
+	}
+}
+
+// SetPostContributors middleware. Top level handler of /posts/:id/contributors PUT requests.
+func (controller postController) SetPostContributors(c *gin.Context) {
+	postService := controller.postService
+
+	id, found := c.Params.Get("id")
+	if !found {
+		_ = c.AbortWithError(http.StatusBadRequest, errortypes.MissingUrlHandleError{})
+		return
+	}
+
+	var body types.PostContributorsInput
+	if err := c.BindJSON(&body); err != nil {
 		return
 	}
 
-	c.IndentedJSON(http.StatusOK, posts)
+	post, err := postService.SetPostContributors(id, body.Contributors, c.GetString("user"))
+
+	switch err.(type) {
+	case nil:
+		c.IndentedJSON(http.StatusOK, post)
+
+	case errortypes.InvalidContributorRoleError:
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+
+	case errortypes.PostEditForbiddenError:
+		_ = c.AbortWithError(http.StatusForbidden, err)
+
+	case errortypes.PostNotFoundError, errortypes.UserNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedPostError{Post: types.Post{URLHandle: id}})
+	}
+}
+
+// SetPostHighlight middleware. Top level handler of /posts/:id/highlight PUT requests.
+// The request body defines whether the post is pinned to the top of the listings and whether it is featured.
+func (controller postController) SetPostHighlight(c *gin.Context) {
+	postService := controller.postService
+
+	id, found := c.Params.Get("id")
+	if !found {
+		_ = c.AbortWithError(http.StatusBadRequest, errortypes.MissingUrlHandleError{})
+		return
+	}
+
+	var body types.PostHighlight
+	if err := c.BindJSON(&body); err != nil {
+		return
+	}
+
+	post, err := postService.SetPostHighlight(id, body, c.GetString("user"))
+
+	switch err.(type) {
+	case nil:
+		c.IndentedJSON(http.StatusOK, post)
+
+	case errortypes.PostNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedPostError{Post: types.Post{URLHandle: id}})
+	}
+}
+
+// SetPostTranslation middleware. Top level handler of /posts/:id/translations/:lang PUT requests.
+func (controller postController) SetPostTranslation(c *gin.Context) {
+	postService := controller.postService
+
+	id, found := c.Params.Get("id")
+	if !found {
+		_ = c.AbortWithError(http.StatusBadRequest, errortypes.MissingUrlHandleError{})
+		return
+	}
+
+	var body types.PostTranslation
+	if err := c.BindJSON(&body); err != nil {
+		return
+	}
+
+	body.Language = c.Param("lang")
+	post, err := postService.SetPostTranslation(id, body, c.GetString("user"))
+
+	switch err.(type) {
+	case nil:
+		c.IndentedJSON(http.StatusOK, post)
+
+	case errortypes.InvalidLanguageError, errortypes.OriginalLanguageTranslationError:
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+
+	case errortypes.PostEditForbiddenError:
+		_ = c.AbortWithError(http.StatusForbidden, err)
+
+	case errortypes.PostNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedPostError{Post: types.Post{URLHandle: id}})
+	}
+}
+
+// UpdatePost middleware. Top level handler of /posts/:id PUT requests.
+func (controller postController) UpdatePost(c *gin.Context) {
+	postService := controller.postService
+
+	id, found := c.Params.Get("id")
+	if !found {
+		_ = c.AbortWithError(http.StatusBadRequest, errortypes.MissingUrlHandleError{})
+		return
+	}
+
+	var body types.Post
+	if err := c.BindJSON(&body); err != nil {
+		return
+	}
+
+	// The creation time can only be changed by the importers
+	body.URLHandle = id
+	body.CreationTime = time.Time{}
+	post, err := postService.UpdatePost(&body, c.GetString("user"))
+
+	switch err.(type) {
+	case nil:
+		c.IndentedJSON(http.StatusOK, post)
+
+	case errortypes.InvalidPostVisibilityError, errortypes.MissingPostPasswordError, errortypes.InvalidCustomFieldError, errortypes.MissingCustomFieldError,
+		errortypes.InvalidCanonicalURLError, errortypes.InvalidCoverImageError:
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+
+	case errortypes.PostEditForbiddenError:
+		_ = c.AbortWithError(http.StatusForbidden, err)
+
+	case errortypes.PostNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedPostError{Post: types.Post{URLHandle: id}})
+	}
+}
+
+// relatedPosts retrieves the related posts of a post if the related query parameter requests them.
+// The parameter is the maximum number of related posts, without it no related posts are included.
+func (controller postController) relatedPosts(c *gin.Context, id string) ([]types.RelatedPost, error) {
+	value := c.Query("related")
+	if value == "" {
+		return nil, nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 {
+		return nil, errortypes.InvalidRelatedPostsLimitError{Limit: value}
+	}
+
+	return controller.relatedService.GetRelatedPosts(id, limit)
+}
+
+// requestLanguages returns the ordered language preferences of a request.
+// The lang query parameter takes precedence over the Accept-Language header, an invalid one aborts the request.
+func requestLanguages(c *gin.Context) ([]string, bool) {
+	languages := i18n.ParseAcceptLanguage(c.Request.Header.Get("Accept-Language"))
+	if lang := c.Query("lang"); lang != "" {
+		tag, ok := i18n.NormalizeTag(lang)
+		if !ok {
+			_ = c.AbortWithError(http.StatusBadRequest, errortypes.InvalidLanguageError{Language: lang})
+			return nil, false
+		}
+		languages = append([]string{tag}, languages...)
+	}
+	return languages, true
 }
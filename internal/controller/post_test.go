@@ -15,14 +15,16 @@ import (
 	"github.com/wlchs/blog/internal/types"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 // postTestContext contains commonly used services, controllers and other objects relevant for testing the PostController.
 type postTestContext struct {
-	mockPostService *mocks.MockPostService
-	sut             controller.PostController
-	ctx             *gin.Context
-	rec             *httptest.ResponseRecorder
+	mockPostService    *mocks.MockPostService
+	mockRelatedService *mocks.MockRelatedService
+	sut                controller.PostController
+	ctx                *gin.Context
+	rec                *httptest.ResponseRecorder
 }
 
 // createPostControllerContext creates the context for testing the PostController and reduces code duplication.
@@ -31,11 +33,12 @@ func createPostControllerContext(t *testing.T) *postTestContext {
 
 	mockCtrl := gomock.NewController(t)
 	mockPostService := mocks.NewMockPostService(mockCtrl)
-	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil)
-	sut := controller.CreatePostController(cont, mockPostService)
+	mockRelatedService := mocks.NewMockRelatedService(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	sut := controller.CreatePostController(cont, mockPostService, mockRelatedService)
 	ctx, rec := test.CreateControllerContext()
 
-	return &postTestContext{mockPostService, sut, ctx, rec}
+	return &postTestContext{mockPostService, mockRelatedService, sut, ctx, rec}
 }
 
 // TestPostController_AddPost tests adding a new post to the system with valid input params.
@@ -66,6 +69,25 @@ func TestPostController_AddPost(t *testing.T) {
 	assert.Equal(t, 201, c.rec.Code, "incorrect response status")
 }
 
+// TestPostController_AddPost_Creation_Time tests that the creation time of new posts can't be set through the API.
+func TestPostController_AddPost_Creation_Time(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	input := types.Post{URLHandle: "testUrlHandle", CreationTime: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)}
+	expected := types.Post{URLHandle: "testUrlHandle", Author: "testAuthor"}
+
+	test.MockJsonPost(c.ctx, input)
+
+	c.ctx.Set("user", "testAuthor")
+	c.mockPostService.EXPECT().AddPost(&expected).Return(expected, nil)
+
+	c.sut.AddPost(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 201, c.rec.Code, "incorrect response status")
+}
+
 // TestPostController_AddPost_Invalid_Input tests adding a new post to the system with invalid input params.
 func TestPostController_AddPost_Invalid_Input(t *testing.T) {
 	t.Parallel()
@@ -107,6 +129,27 @@ func TestPostController_AddPost_Duplicate_Input(t *testing.T) {
 	assert.Equal(t, 409, c.rec.Code, "incorrect response status")
 }
 
+// TestPostController_AddPost_Invalid_Canonical_URL tests adding a new post with an invalid canonical URL.
+func TestPostController_AddPost_Invalid_Canonical_URL(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	input := types.Post{URLHandle: "testUrlHandle", Author: "testAuthor", SEO: &types.PostSEO{CanonicalURL: "/relative"}}
+
+	test.MockJsonPost(c.ctx, input)
+
+	c.ctx.Set("user", input.Author)
+	expectedError := errortypes.InvalidCanonicalURLError{URL: "/relative"}
+	c.mockPostService.EXPECT().AddPost(&input).Return(types.Post{}, expectedError)
+
+	c.sut.AddPost(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
+
 // TestPostController_AddPost_Unexpected_Error tests handling unexpected errors while adding a new post to the system.
 func TestPostController_AddPost_Unexpected_Error(t *testing.T) {
 	t.Parallel()
@@ -148,7 +191,7 @@ func TestPostController_GetPost(t *testing.T) {
 	}
 
 	c.ctx.AddParam("id", expectedOutput.URLHandle)
-	c.mockPostService.EXPECT().GetPost(expectedOutput.URLHandle).Return(expectedOutput, nil)
+	c.mockPostService.EXPECT().GetPost(expectedOutput.URLHandle, types.PostAccess{}, []string{}).Return(expectedOutput, nil)
 
 	c.sut.GetPost(c.ctx)
 
@@ -160,6 +203,46 @@ func TestPostController_GetPost(t *testing.T) {
 	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
 }
 
+// TestPostController_GetPost_Related tests retrieving a single post together with its related posts.
+func TestPostController_GetPost_Related(t *testing.T) {
+	t.Parallel()
+
+	c := createPostControllerContext(t)
+	post := types.Post{URLHandle: "testUrlHandle", Title: "testTitle", Author: "testAuthor"}
+	related := []types.RelatedPost{{URLHandle: "otherUrlHandle", Title: "otherTitle", Score: 0.42}}
+
+	c.ctx.AddParam("id", post.URLHandle)
+	c.ctx.Request.URL.RawQuery = "related=3"
+	c.mockPostService.EXPECT().GetPost(post.URLHandle, types.PostAccess{}, []string{}).Return(post, nil)
+	c.mockRelatedService.EXPECT().GetRelatedPosts(post.URLHandle, 3).Return(related, nil)
+
+	c.sut.GetPost(c.ctx)
+
+	var output types.Post
+	_ = json.Unmarshal(c.rec.Body.Bytes(), &output)
+
+	assert.Nil(t, c.ctx.Errors, "expected no errors")
+	assert.Equal(t, related, output.Related, "incorrect related posts")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_GetPost_Invalid_Related tests requesting an invalid number of related posts.
+func TestPostController_GetPost_Invalid_Related(t *testing.T) {
+	t.Parallel()
+
+	c := createPostControllerContext(t)
+	urlHandle := "testUrlHandle"
+
+	c.ctx.AddParam("id", urlHandle)
+	c.ctx.Request.URL.RawQuery = "related=0"
+	c.mockPostService.EXPECT().GetPost(urlHandle, types.PostAccess{}, []string{}).Return(types.Post{URLHandle: urlHandle}, nil)
+
+	c.sut.GetPost(c.ctx)
+
+	assert.Equal(t, errortypes.InvalidRelatedPostsLimitError{Limit: "0"}, c.ctx.Errors.Last().Err, "incorrect error type")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
+
 // TestPostController_GetPost_Missing_URL_Handle tests retrieving a single post without URL handle.
 func TestPostController_GetPost_Missing_URL_Handle(t *testing.T) {
 	t.Parallel()
@@ -184,7 +267,7 @@ func TestPostController_GetPost_Incorrect_URL_Handle(t *testing.T) {
 	expectedError := errortypes.PostNotFoundError{Post: types.Post{URLHandle: urlHandle}}
 
 	c.ctx.AddParam("id", urlHandle)
-	c.mockPostService.EXPECT().GetPost(urlHandle).Return(types.Post{}, expectedError)
+	c.mockPostService.EXPECT().GetPost(urlHandle, types.PostAccess{}, []string{}).Return(types.Post{}, expectedError)
 
 	c.sut.GetPost(c.ctx)
 
@@ -203,7 +286,7 @@ func TestPostController_GetPost_Unexpected_Error(t *testing.T) {
 	expectedError := errortypes.UnexpectedPostError{Post: types.Post{URLHandle: urlHandle}}
 
 	c.ctx.AddParam("id", urlHandle)
-	c.mockPostService.EXPECT().GetPost(urlHandle).Return(types.Post{}, fmt.Errorf("unexpected error"))
+	c.mockPostService.EXPECT().GetPost(urlHandle, types.PostAccess{}, []string{}).Return(types.Post{}, fmt.Errorf("unexpected error"))
 
 	c.sut.GetPost(c.ctx)
 
@@ -228,7 +311,7 @@ func TestPostController_GetPosts(t *testing.T) {
 		},
 	}
 
-	c.mockPostService.EXPECT().GetPosts().Return(expectedOutput, nil)
+	c.mockPostService.EXPECT().GetPosts(types.PostFilter{Fields: map[string]string{}, PinnedFirst: true}).Return(expectedOutput, nil)
 
 	c.sut.GetPosts(c.ctx)
 
@@ -247,7 +330,7 @@ func TestPostController_GetPosts_Unexpected_Error(t *testing.T) {
 	c := createPostControllerContext(t)
 	expectedError := errortypes.UnexpectedPostError{}
 
-	c.mockPostService.EXPECT().GetPosts().Return(nil, fmt.Errorf("unexpected error"))
+	c.mockPostService.EXPECT().GetPosts(types.PostFilter{Fields: map[string]string{}, PinnedFirst: true}).Return(nil, fmt.Errorf("unexpected error"))
 
 	c.sut.GetPosts(c.ctx)
 
@@ -256,3 +339,549 @@ func TestPostController_GetPosts_Unexpected_Error(t *testing.T) {
 	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
 	assert.Equal(t, 500, c.rec.Code, "incorrect response status")
 }
+
+// TestPostController_UpdatePost tests updating a post with valid input params.
+func TestPostController_UpdatePost(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	input := types.Post{URLHandle: "testUrlHandle", Title: "newTitle", Body: "newBody"}
+
+	test.MockJsonPost(c.ctx, types.Post{Title: input.Title, Body: input.Body})
+	c.ctx.AddParam("id", input.URLHandle)
+	c.ctx.Set("user", "testAuthor")
+	c.mockPostService.EXPECT().UpdatePost(&input, "testAuthor").Return(input, nil)
+
+	c.sut.UpdatePost(c.ctx)
+
+	var output types.Post
+	_ = json.Unmarshal(c.rec.Body.Bytes(), &output)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, input, output, "response body should match")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_UpdatePost_Forbidden tests updating a post by a user who didn't contribute to it.
+func TestPostController_UpdatePost_Forbidden(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	input := types.Post{URLHandle: "testUrlHandle", Title: "newTitle"}
+	expectedError := errortypes.PostEditForbiddenError{Post: types.Post{URLHandle: input.URLHandle}, UserName: "stranger"}
+
+	test.MockJsonPost(c.ctx, types.Post{Title: input.Title})
+	c.ctx.AddParam("id", input.URLHandle)
+	c.ctx.Set("user", "stranger")
+	c.mockPostService.EXPECT().UpdatePost(&input, "stranger").Return(types.Post{}, expectedError)
+
+	c.sut.UpdatePost(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 403, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_UpdatePost_Not_Found tests updating a non-existent post.
+func TestPostController_UpdatePost_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	input := types.Post{URLHandle: "testUrlHandle"}
+	expectedError := errortypes.PostNotFoundError{Post: input}
+
+	test.MockJsonPost(c.ctx, types.Post{})
+	c.ctx.AddParam("id", input.URLHandle)
+	c.ctx.Set("user", "testAuthor")
+	c.mockPostService.EXPECT().UpdatePost(&input, "testAuthor").Return(types.Post{}, expectedError)
+
+	c.sut.UpdatePost(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_UpdatePost_Missing_URL_Handle tests updating a post without URL handle.
+func TestPostController_UpdatePost_Missing_URL_Handle(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	c.sut.UpdatePost(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, errortypes.MissingUrlHandleError{}.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_SetPostContributors tests replacing the contributors of a post.
+func TestPostController_SetPostContributors(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	input := types.PostContributorsInput{Contributors: []types.Contributor{{UserName: "testEditor", Role: types.ContributorRoleEditor}}}
+	expectedOutput := types.Post{
+		URLHandle: "testUrlHandle",
+		Author:    "testAuthor",
+		Contributors: []types.Contributor{
+			{UserName: "testAuthor", Role: types.ContributorRoleAuthor},
+			{UserName: "testEditor", Role: types.ContributorRoleEditor},
+		},
+	}
+
+	test.MockJsonPost(c.ctx, input)
+	c.ctx.AddParam("id", expectedOutput.URLHandle)
+	c.ctx.Set("user", "testAuthor")
+	c.mockPostService.EXPECT().SetPostContributors(expectedOutput.URLHandle, input.Contributors, "testAuthor").Return(expectedOutput, nil)
+
+	c.sut.SetPostContributors(c.ctx)
+
+	var output types.Post
+	_ = json.Unmarshal(c.rec.Body.Bytes(), &output)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, expectedOutput, output, "response body should match")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_SetPostContributors_Invalid_Role tests replacing the contributors of a post with an unsupported role.
+func TestPostController_SetPostContributors_Invalid_Role(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	input := types.PostContributorsInput{Contributors: []types.Contributor{{UserName: "testEditor", Role: "translator"}}}
+	expectedError := errortypes.InvalidContributorRoleError{Role: "translator"}
+
+	test.MockJsonPost(c.ctx, input)
+	c.ctx.AddParam("id", "testUrlHandle")
+	c.ctx.Set("user", "testAuthor")
+	c.mockPostService.EXPECT().SetPostContributors("testUrlHandle", input.Contributors, "testAuthor").Return(types.Post{}, expectedError)
+
+	c.sut.SetPostContributors(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_SetPostContributors_Forbidden tests replacing the contributors of a post by a co-author.
+func TestPostController_SetPostContributors_Forbidden(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	input := types.PostContributorsInput{Contributors: []types.Contributor{}}
+	expectedError := errortypes.PostEditForbiddenError{Post: types.Post{URLHandle: "testUrlHandle"}, UserName: "testEditor"}
+
+	test.MockJsonPost(c.ctx, input)
+	c.ctx.AddParam("id", "testUrlHandle")
+	c.ctx.Set("user", "testEditor")
+	c.mockPostService.EXPECT().SetPostContributors("testUrlHandle", input.Contributors, "testEditor").Return(types.Post{}, expectedError)
+
+	c.sut.SetPostContributors(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 403, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_GetPost_Access tests passing the authenticated user and the post access token to the service.
+func TestPostController_GetPost_Access(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	expectedOutput := types.Post{URLHandle: "testUrlHandle", Visibility: types.VisibilityPassword}
+	access := types.PostAccess{UserName: "testAuthor", AccessToken: "token"}
+
+	c.ctx.AddParam("id", expectedOutput.URLHandle)
+	c.ctx.Set("user", access.UserName)
+	c.ctx.Request.Header.Set("X-Post-Token", access.AccessToken)
+	c.mockPostService.EXPECT().GetPost(expectedOutput.URLHandle, access, []string{}).Return(expectedOutput, nil)
+
+	c.sut.GetPost(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "expected no errors")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_GetPost_Password_Required tests retrieving a password-protected post without access token.
+func TestPostController_GetPost_Password_Required(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	urlHandle := "testUrlHandle"
+	expectedError := errortypes.PostPasswordRequiredError{Post: types.Post{URLHandle: urlHandle}}
+
+	c.ctx.AddParam("id", urlHandle)
+	c.mockPostService.EXPECT().GetPost(urlHandle, types.PostAccess{}, []string{}).Return(types.Post{}, expectedError)
+
+	c.sut.GetPost(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 401, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_AuthorizePostAccess tests requesting a post access token with the correct password.
+func TestPostController_AuthorizePostAccess(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	test.MockJsonPost(c.ctx, types.PostAccessInput{Password: "secret"})
+	c.ctx.AddParam("id", "testUrlHandle")
+	c.mockPostService.EXPECT().AuthorizePostAccess("testUrlHandle", "secret").Return("token", nil)
+
+	c.sut.AuthorizePostAccess(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "expected no errors")
+	assert.Equal(t, "token", c.rec.Header().Get("X-Post-Token"), "incorrect token")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_AuthorizePostAccess_Incorrect_Password tests requesting a post access token with an incorrect password.
+func TestPostController_AuthorizePostAccess_Incorrect_Password(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	expectedError := errortypes.IncorrectPostPasswordError{}
+
+	test.MockJsonPost(c.ctx, types.PostAccessInput{Password: "wrong"})
+	c.ctx.AddParam("id", "testUrlHandle")
+	c.mockPostService.EXPECT().AuthorizePostAccess("testUrlHandle", "wrong").Return("", expectedError)
+
+	c.sut.AuthorizePostAccess(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 401, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_AuthorizePostAccess_Not_Protected tests requesting a post access token for a public post.
+func TestPostController_AuthorizePostAccess_Not_Protected(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	expectedError := errortypes.PostNotPasswordProtectedError{Post: types.Post{URLHandle: "testUrlHandle"}}
+
+	test.MockJsonPost(c.ctx, types.PostAccessInput{Password: "secret"})
+	c.ctx.AddParam("id", "testUrlHandle")
+	c.mockPostService.EXPECT().AuthorizePostAccess("testUrlHandle", "secret").Return("", expectedError)
+
+	c.sut.AuthorizePostAccess(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_GetPost_Language tests retrieving a post using language negotiation.
+// The lang query parameter takes precedence over the Accept-Language header.
+func TestPostController_GetPost_Language(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	expectedOutput := types.Post{URLHandle: "testUrlHandle", Language: "de"}
+
+	c.ctx.AddParam("id", expectedOutput.URLHandle)
+	c.ctx.Request.URL.RawQuery = "lang=de"
+	c.ctx.Request.Header.Set("Accept-Language", "fr, en;q=0.5")
+	c.mockPostService.EXPECT().GetPost(expectedOutput.URLHandle, types.PostAccess{}, []string{"de", "fr", "en"}).Return(expectedOutput, nil)
+
+	c.sut.GetPost(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "expected no errors")
+	assert.Equal(t, "de", c.rec.Header().Get("Content-Language"), "incorrect content language")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_GetPost_Invalid_Language tests retrieving a post with an invalid lang query parameter.
+func TestPostController_GetPost_Invalid_Language(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	c.ctx.AddParam("id", "testUrlHandle")
+	c.ctx.Request.URL.RawQuery = "lang=*"
+
+	c.sut.GetPost(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, errortypes.InvalidLanguageError{Language: "*"}.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_GetPosts_Language tests retrieving every post available in a given language.
+func TestPostController_GetPosts_Language(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	c.ctx.Request.URL.RawQuery = "lang=de-at"
+	c.mockPostService.EXPECT().GetPosts(types.PostFilter{Language: "de-AT", Fields: map[string]string{}, PinnedFirst: true}).Return([]types.Post{}, nil)
+
+	c.sut.GetPosts(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "expected no errors")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_SetPostTranslation tests adding a translation to a post.
+func TestPostController_SetPostTranslation(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	input := types.PostTranslation{Title: "Titel", Summary: "Zusammenfassung", Body: "Text"}
+	expectedInput := input
+	expectedInput.Language = "de"
+
+	test.MockJsonPost(c.ctx, input)
+	c.ctx.AddParam("id", "testUrlHandle")
+	c.ctx.AddParam("lang", "de")
+	c.ctx.Set("user", "testAuthor")
+	c.mockPostService.EXPECT().SetPostTranslation("testUrlHandle", expectedInput, "testAuthor").Return(types.Post{Language: "de"}, nil)
+
+	c.sut.SetPostTranslation(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "expected no errors")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_SetPostTranslation_Original_Language tests translating a post to its original language.
+func TestPostController_SetPostTranslation_Original_Language(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	expectedError := errortypes.OriginalLanguageTranslationError{Post: types.Post{URLHandle: "testUrlHandle"}, Language: "en"}
+
+	test.MockJsonPost(c.ctx, types.PostTranslation{})
+	c.ctx.AddParam("id", "testUrlHandle")
+	c.ctx.AddParam("lang", "en")
+	c.mockPostService.EXPECT().SetPostTranslation("testUrlHandle", types.PostTranslation{Language: "en"}, "").Return(types.Post{}, expectedError)
+
+	c.sut.SetPostTranslation(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_DeletePost tests removing a post.
+func TestPostController_DeletePost(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	c.ctx.AddParam("id", "testUrlHandle")
+	c.ctx.Set("user", "testAuthor")
+	c.mockPostService.EXPECT().DeletePost("testUrlHandle", "testAuthor").Return(nil)
+
+	c.sut.DeletePost(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "expected no errors")
+	assert.Equal(t, 204, c.ctx.Writer.Status(), "incorrect response status")
+}
+
+// TestPostController_DeletePost_Forbidden tests removing a post by a user other than its primary author.
+func TestPostController_DeletePost_Forbidden(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	expectedError := errortypes.PostDeleteForbiddenError{Post: types.Post{URLHandle: "testUrlHandle"}, UserName: "testEditor"}
+
+	c.ctx.AddParam("id", "testUrlHandle")
+	c.ctx.Set("user", "testEditor")
+	c.mockPostService.EXPECT().DeletePost("testUrlHandle", "testEditor").Return(expectedError)
+
+	c.sut.DeletePost(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 403, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_DeletePostTranslation tests removing the translation of a post.
+func TestPostController_DeletePostTranslation(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	c.ctx.AddParam("id", "testUrlHandle")
+	c.ctx.AddParam("lang", "de")
+	c.ctx.Set("user", "testAuthor")
+	c.mockPostService.EXPECT().DeletePostTranslation("testUrlHandle", "de", "testAuthor").Return(nil)
+
+	c.sut.DeletePostTranslation(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "expected no errors")
+	assert.Equal(t, 204, c.ctx.Writer.Status(), "incorrect response status")
+}
+
+// TestPostController_DeletePostTranslation_Not_Found tests removing a non-existent translation of a post.
+func TestPostController_DeletePostTranslation_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	expectedError := errortypes.TranslationNotFoundError{Post: types.Post{URLHandle: "testUrlHandle"}, Language: "fr"}
+
+	c.ctx.AddParam("id", "testUrlHandle")
+	c.ctx.AddParam("lang", "fr")
+	c.mockPostService.EXPECT().DeletePostTranslation("testUrlHandle", "fr", "").Return(expectedError)
+
+	c.sut.DeletePostTranslation(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_GetPosts_Custom_Fields tests retrieving every post filtered by custom fields.
+func TestPostController_GetPosts_Custom_Fields(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	filter := types.PostFilter{Fields: map[string]string{"category": "news", "featured": "true"}, PinnedFirst: true}
+
+	c.ctx.Request.URL.RawQuery = "field[category]=news&field[featured]=true"
+	c.mockPostService.EXPECT().GetPosts(filter).Return([]types.Post{}, nil)
+
+	c.sut.GetPosts(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "expected no errors")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_GetPosts_Unknown_Custom_Field tests filtering posts by an undefined custom field.
+func TestPostController_GetPosts_Unknown_Custom_Field(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	filter := types.PostFilter{Fields: map[string]string{"unknown": "value"}, PinnedFirst: true}
+	expectedError := errortypes.InvalidCustomFieldError{Name: "unknown", Reason: "unknown custom field"}
+
+	c.ctx.Request.URL.RawQuery = "field[unknown]=value"
+	c.mockPostService.EXPECT().GetPosts(filter).Return(nil, expectedError)
+
+	c.sut.GetPosts(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_GetPosts_Featured_Collection tests retrieving the featured posts of a collection.
+func TestPostController_GetPosts_Featured_Collection(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	filter := types.PostFilter{Fields: map[string]string{}, Featured: true, Collection: "start-here", PinnedFirst: true}
+
+	c.ctx.Request.URL.RawQuery = "featured=true&collection=start-here"
+	c.mockPostService.EXPECT().GetPosts(filter).Return([]types.Post{}, nil)
+
+	c.sut.GetPosts(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "expected no errors")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_GetPosts_Unknown_Collection tests retrieving the posts of a non-existent collection.
+func TestPostController_GetPosts_Unknown_Collection(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	filter := types.PostFilter{Fields: map[string]string{}, Collection: "missing", PinnedFirst: true}
+	expectedError := errortypes.CollectionNotFoundError{Collection: types.Collection{URLHandle: "missing"}}
+
+	c.ctx.Request.URL.RawQuery = "collection=missing"
+	c.mockPostService.EXPECT().GetPosts(filter).Return(nil, expectedError)
+
+	c.sut.GetPosts(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_GetPosts_Invalid_Featured tests filtering posts by a malformed featured flag.
+func TestPostController_GetPosts_Invalid_Featured(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	c.ctx.Request.URL.RawQuery = "featured=maybe"
+
+	c.sut.GetPosts(c.ctx)
+
+	assert.IsType(t, errortypes.InvalidPostFilterError{}, c.ctx.Errors.Last().Err, "incorrect error type")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_SetPostHighlight tests pinning a post.
+func TestPostController_SetPostHighlight(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	input := types.PostHighlight{Pinned: true}
+	expected := types.Post{URLHandle: "testUrlHandle", Pinned: true}
+
+	test.MockJsonPost(c.ctx, input)
+	c.ctx.AddParam("id", "testUrlHandle")
+	c.ctx.Set("user", "testEditor")
+	c.mockPostService.EXPECT().SetPostHighlight("testUrlHandle", input, "testEditor").Return(expected, nil)
+
+	c.sut.SetPostHighlight(c.ctx)
+
+	var output types.Post
+	_ = json.Unmarshal(c.rec.Body.Bytes(), &output)
+
+	assert.Nil(t, c.ctx.Errors, "expected no errors")
+	assert.Equal(t, expected, output, "incorrect output body")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_SetPostHighlight_Not_Found tests highlighting a non-existent post.
+func TestPostController_SetPostHighlight_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	input := types.PostHighlight{Featured: true}
+	expectedError := errortypes.PostNotFoundError{Post: types.Post{URLHandle: "missing"}}
+
+	test.MockJsonPost(c.ctx, input)
+	c.ctx.AddParam("id", "missing")
+	c.mockPostService.EXPECT().SetPostHighlight("missing", input, "").Return(types.Post{}, expectedError)
+
+	c.sut.SetPostHighlight(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+}
+
+// TestPostController_AddPost_Missing_Custom_Field tests adding a post without a required custom field.
+func TestPostController_AddPost_Missing_Custom_Field(t *testing.T) {
+	t.Parallel()
+	c := createPostControllerContext(t)
+
+	input := types.Post{URLHandle: "testUrlHandle"}
+	expectedError := errortypes.MissingCustomFieldError{Name: "category"}
+
+	test.MockJsonPost(c.ctx, input)
+	c.mockPostService.EXPECT().AddPost(&input).Return(types.Post{}, expectedError)
+
+	c.sut.AddPost(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
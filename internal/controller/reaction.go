@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/types"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// ReactionController interface defining reaction-related middleware methods to handle HTTP requests
+type ReactionController interface {
+	AddReaction(c *gin.Context)
+	GetReactions(c *gin.Context)
+	RemoveReaction(c *gin.Context)
+}
+
+// reactionController is a concrete implementation of the ReactionController interface
+type reactionController struct {
+	cont            container.Container
+	reactionService services.ReactionService
+}
+
+// CreateReactionController instantiates a reaction controller using the application container.
+func CreateReactionController(cont container.Container, reactionService services.ReactionService) ReactionController {
+	return &reactionController{cont, reactionService}
+}
+
+// AddReaction middleware. Top level handler of /posts/:id/reactions/:emoji PUT requests.
+func (controller reactionController) AddReaction(c *gin.Context) {
+	reactionService := controller.reactionService
+
+	reactions, err := reactionService.AddReaction(c.Param("id"), c.Param("emoji"), reactionReader(c))
+	handleReactionResult(c, reactions, err)
+}
+
+// GetReactions middleware. Top level handler of /posts/:id/reactions GET requests.
+func (controller reactionController) GetReactions(c *gin.Context) {
+	reactionService := controller.reactionService
+
+	reactions, err := reactionService.GetReactions(c.Param("id"), reactionReader(c))
+	handleReactionResult(c, reactions, err)
+}
+
+// RemoveReaction middleware. Top level handler of /posts/:id/reactions/:emoji DELETE requests.
+func (controller reactionController) RemoveReaction(c *gin.Context) {
+	reactionService := controller.reactionService
+
+	reactions, err := reactionService.RemoveReaction(c.Param("id"), c.Param("emoji"), reactionReader(c))
+	handleReactionResult(c, reactions, err)
+}
+
+// reactionReader identifies the reader of the request. The user is only set for logged-in readers.
+func reactionReader(c *gin.Context) types.ReactionReader {
+	return types.ReactionReader{UserName: c.GetString("user"), Address: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
+
+// handleReactionResult responds with the reactions to the post or the status matching the error.
+// Throttled readers are told when they may react again in the Retry-After header.
+func handleReactionResult(c *gin.Context, reactions types.PostReactions, err error) {
+	switch err := err.(type) {
+	case nil:
+		c.IndentedJSON(http.StatusOK, reactions)
+
+	case errortypes.InvalidReactionError:
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+
+	case errortypes.PostNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	case errortypes.ReactionRateLimitError:
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(err.RetryAfter.Seconds()))))
+		_ = c.AbortWithError(http.StatusTooManyRequests, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedReactionError{})
+	}
+}
@@ -0,0 +1,150 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/controller"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/test"
+	"github.com/wlchs/blog/internal/types"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// reactionTestContext contains commonly used services, controllers and other objects relevant for testing the ReactionController.
+type reactionTestContext struct {
+	mockReactionService *mocks.MockReactionService
+	sut                 controller.ReactionController
+	ctx                 *gin.Context
+	rec                 *httptest.ResponseRecorder
+}
+
+// createReactionControllerContext creates the context for testing the ReactionController and reduces code duplication.
+// The request is sent by an anonymous reader with a known address and user agent.
+func createReactionControllerContext(t *testing.T) *reactionTestContext {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	mockReactionService := mocks.NewMockReactionService(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	sut := controller.CreateReactionController(cont, mockReactionService)
+	ctx, rec := test.CreateControllerContext()
+	ctx.Request.RemoteAddr = "192.0.2.1:1234"
+	ctx.Request.Header.Set("User-Agent", "test-agent")
+
+	return &reactionTestContext{mockReactionService, sut, ctx, rec}
+}
+
+// TestReactionController_GetReactions tests retrieving the reactions to a post.
+func TestReactionController_GetReactions(t *testing.T) {
+	t.Parallel()
+	c := createReactionControllerContext(t)
+
+	expected := types.PostReactions{Counts: map[string]int{"👍": 2, "❤️": 0}, Reacted: []string{"👍"}}
+	reader := types.ReactionReader{Address: "192.0.2.1", UserAgent: "test-agent"}
+
+	c.ctx.AddParam("id", "hello")
+	c.mockReactionService.EXPECT().GetReactions("hello", reader).Return(expected, nil)
+
+	c.sut.GetReactions(c.ctx)
+
+	var output types.PostReactions
+	_ = json.Unmarshal(c.rec.Body.Bytes(), &output)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, expected, output, "response body should match")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestReactionController_AddReaction tests reacting to a post as a logged-in user.
+func TestReactionController_AddReaction(t *testing.T) {
+	t.Parallel()
+	c := createReactionControllerContext(t)
+
+	expected := types.PostReactions{Counts: map[string]int{"👍": 1}, Reacted: []string{"👍"}}
+	reader := types.ReactionReader{UserName: "testUser", Address: "192.0.2.1", UserAgent: "test-agent"}
+
+	c.ctx.AddParam("id", "hello")
+	c.ctx.AddParam("emoji", "👍")
+	c.ctx.Set("user", "testUser")
+	c.mockReactionService.EXPECT().AddReaction("hello", "👍", reader).Return(expected, nil)
+
+	c.sut.AddReaction(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestReactionController_AddReaction_Invalid tests reacting with an unsupported emoji.
+func TestReactionController_AddReaction_Invalid(t *testing.T) {
+	t.Parallel()
+	c := createReactionControllerContext(t)
+
+	expectedError := errortypes.InvalidReactionError{Emoji: "💩"}
+
+	c.ctx.AddParam("id", "hello")
+	c.ctx.AddParam("emoji", "💩")
+	c.mockReactionService.EXPECT().AddReaction("hello", "💩", gomock.Any()).Return(types.PostReactions{}, expectedError)
+
+	c.sut.AddReaction(c.ctx)
+
+	assert.Equal(t, expectedError, c.ctx.Errors.Last().Err, "response should contain the expected error")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
+
+// TestReactionController_AddReaction_Throttled tests throttling a reader reacting too often.
+func TestReactionController_AddReaction_Throttled(t *testing.T) {
+	t.Parallel()
+	c := createReactionControllerContext(t)
+
+	expectedError := errortypes.ReactionRateLimitError{RetryAfter: 1500 * time.Millisecond}
+
+	c.ctx.AddParam("id", "hello")
+	c.ctx.AddParam("emoji", "👍")
+	c.mockReactionService.EXPECT().AddReaction("hello", "👍", gomock.Any()).Return(types.PostReactions{}, expectedError)
+
+	c.sut.AddReaction(c.ctx)
+
+	assert.Equal(t, expectedError, c.ctx.Errors.Last().Err, "response should contain the expected error")
+	assert.Equal(t, "2", c.rec.Header().Get("Retry-After"), "retry delay should be rounded up to seconds")
+	assert.Equal(t, 429, c.rec.Code, "incorrect response status")
+}
+
+// TestReactionController_RemoveReaction_Not_Found tests removing a reaction from a missing post.
+func TestReactionController_RemoveReaction_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createReactionControllerContext(t)
+
+	expectedError := errortypes.PostNotFoundError{Post: types.Post{URLHandle: "hello"}}
+
+	c.ctx.AddParam("id", "hello")
+	c.ctx.AddParam("emoji", "👍")
+	c.mockReactionService.EXPECT().RemoveReaction("hello", "👍", gomock.Any()).Return(types.PostReactions{}, expectedError)
+
+	c.sut.RemoveReaction(c.ctx)
+
+	assert.Equal(t, expectedError, c.ctx.Errors.Last().Err, "response should contain the expected error")
+	assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+}
+
+// TestReactionController_RemoveReaction_Unexpected_Error tests hiding unexpected errors from the reader.
+func TestReactionController_RemoveReaction_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createReactionControllerContext(t)
+
+	c.ctx.AddParam("id", "hello")
+	c.ctx.AddParam("emoji", "👍")
+	c.mockReactionService.EXPECT().RemoveReaction("hello", "👍", gomock.Any()).Return(types.PostReactions{}, fmt.Errorf("error"))
+
+	c.sut.RemoveReaction(c.ctx)
+
+	assert.Equal(t, errortypes.UnexpectedReactionError{}, c.ctx.Errors.Last().Err, "response should contain the expected error")
+	assert.Equal(t, 500, c.rec.Code, "incorrect response status")
+}
@@ -4,27 +4,136 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/wlchs/blog/internal/container"
 	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/site"
 	"os"
+	"strings"
 )
 
 // CreateRoutes initializes and serves the REST API
 func CreateRoutes(cont container.Container) {
 	log := cont.GetLogger()
-	router := gin.Default()
+	router, err := CreateEngine(loadTrustedProxies())
+	if err != nil {
+		log.Errorf("invalid trusted proxies: %v", err)
+		return
+	}
 
 	// Services
+	webhookService := services.CreateWebhookService(cont)
+	analyticsService := services.CreateAnalyticsService(cont)
+	collectionService := services.CreateCollectionService(cont)
+	fieldService := services.CreateFieldService(cont)
+	mediaService := services.CreateMediaService(cont)
+	newsletterService := services.CreateNewsletterService(cont)
 	postService := services.CreatePostService(cont)
+	reactionService := services.CreateReactionService(cont)
+	relatedService := services.CreateRelatedService(cont)
+	micropubService := services.CreateMicropubService(cont, postService, mediaService)
+	seriesService := services.CreateSeriesService(cont)
+	siteService := services.CreateSiteService(cont, postService)
+	federationService := services.CreateFederationService(cont, postService)
 	userService := services.CreateUserService(cont)
+	webmentionService := services.CreateWebmentionService(cont)
 
 	// Controllers
+	analyticsCtrl := CreateAnalyticsController(cont, analyticsService)
 	authCtrl := CreateAuthController(cont, userService)
-	postCtrl := CreatePostController(cont, postService)
+	collectionCtrl := CreateCollectionController(cont, collectionService)
+	federationCtrl := CreateFederationController(cont, federationService)
+	fieldCtrl := CreateFieldController(cont, fieldService)
+	mediaCtrl := CreateMediaController(cont, mediaService)
+	micropubCtrl := CreateMicropubController(cont, micropubService, mediaService)
+	newsletterCtrl := CreateNewsletterController(cont, newsletterService)
+	postCtrl := CreatePostController(cont, postService, relatedService)
+	reactionCtrl := CreateReactionController(cont, reactionService)
+	seriesCtrl := CreateSeriesController(cont, seriesService)
+	siteCtrl := CreateSiteController(cont, siteService)
 	userCtrl := CreateUserController(cont, userService)
+	webhookCtrl := CreateWebhookController(cont, webhookService)
+	webmentionCtrl := CreateWebmentionController(cont, webmentionService)
+
+	// Dispatch the events left in the outbox, deliver the queued webhook events and activities, send the newsletters,
+	// verify and send the webmentions, roll up the views and relate the posts in the background
+	go cont.GetEventBus().Run(nil)
+	go webhookService.RunDeliveries(nil)
+	go federationService.RunDeliveries(nil)
+	go newsletterService.RunNewsletters(nil)
+	go webmentionService.RunWebmentions(nil)
+	go analyticsService.RunRollups(nil)
+	go relatedService.RunRelatedPosts(nil)
 
 	// Posts
 	router.GET("/posts", postCtrl.GetPosts)
-	router.GET("/posts/:id", postCtrl.GetPost)
+	router.GET("/posts/:id", authCtrl.Identify, analyticsCtrl.RecordView, siteCtrl.RenderPost, postCtrl.GetPost)
+	router.GET("/posts/:id/meta", authCtrl.Identify, siteCtrl.GetPostMeta)
 	router.POST("/posts", authCtrl.Protect, postCtrl.AddPost)
+	router.POST("/posts/:id/access", postCtrl.AuthorizePostAccess)
+	router.PUT("/posts/:id", authCtrl.Protect, postCtrl.UpdatePost)
+	router.DELETE("/posts/:id", authCtrl.Protect, postCtrl.DeletePost)
+	router.PUT("/posts/:id/contributors", authCtrl.Protect, postCtrl.SetPostContributors)
+	router.PUT("/posts/:id/translations/:lang", authCtrl.Protect, postCtrl.SetPostTranslation)
+	router.DELETE("/posts/:id/translations/:lang", authCtrl.Protect, postCtrl.DeletePostTranslation)
+	router.GET("/posts/:id/webmentions", authCtrl.Identify, webmentionCtrl.GetWebmentions)
+	router.GET("/posts/:id/stats", authCtrl.Protect, analyticsCtrl.GetPostStats)
+	router.GET("/posts/:id/reactions", authCtrl.Identify, reactionCtrl.GetReactions)
+	router.PUT("/posts/:id/reactions/:emoji", authCtrl.Identify, reactionCtrl.AddReaction)
+	router.DELETE("/posts/:id/reactions/:emoji", authCtrl.Identify, reactionCtrl.RemoveReaction)
+
+	// Analytics
+	router.GET("/stats", authCtrl.Protect, analyticsCtrl.GetStats)
+
+	// Highlighted posts and collections
+	CreateCurationRoutes(router, authCtrl, postCtrl, collectionCtrl)
+
+	// Custom fields
+	router.GET("/fields", fieldCtrl.GetFields)
+	router.POST("/fields", authCtrl.Protect, authCtrl.ProtectAdmin, fieldCtrl.AddField)
+	router.DELETE("/fields/:name", authCtrl.Protect, authCtrl.ProtectAdmin, fieldCtrl.DeleteField)
+
+	// Media
+	router.GET("/media", mediaCtrl.GetMediaList)
+	router.GET("/media/:id", mediaCtrl.GetMedia)
+	router.GET("/media/:id/variants/:name", mediaCtrl.GetMediaVariant)
+	router.POST("/media", authCtrl.Protect, mediaCtrl.UploadMedia)
+	router.DELETE("/media/:id", authCtrl.Protect, mediaCtrl.DeleteMedia)
+
+	// ActivityPub federation
+	router.GET("/.well-known/webfinger", federationCtrl.GetWebFinger)
+	router.GET("/actors/:userName", federationCtrl.GetActor)
+	router.GET("/actors/:userName/outbox", federationCtrl.GetOutbox)
+	router.GET("/actors/:userName/followers", federationCtrl.GetFollowers)
+	router.POST("/actors/:userName/inbox", federationCtrl.PostInbox)
+
+	// Micropub
+	router.GET(site.MicropubPath, authCtrl.ProtectBearer, micropubCtrl.GetMicropub)
+	router.POST(site.MicropubPath, authCtrl.ProtectBearer, micropubCtrl.PostMicropub)
+	router.POST(site.MicropubMediaPath, authCtrl.ProtectBearer, micropubCtrl.UploadMicropubMedia)
+
+	// Newsletter
+	router.POST("/subscribe", newsletterCtrl.Subscribe)
+	router.GET("/subscribe/confirm", newsletterCtrl.ConfirmSubscription)
+	router.GET("/unsubscribe", newsletterCtrl.Unsubscribe)
+	router.POST("/unsubscribe", newsletterCtrl.Unsubscribe)
+
+	// Series
+	router.GET("/series", seriesCtrl.GetSeriesList)
+	router.GET("/series/:id", seriesCtrl.GetSeries)
+	router.POST("/series", authCtrl.Protect, seriesCtrl.AddSeries)
+	router.PUT("/series/:id", authCtrl.Protect, seriesCtrl.UpdateSeries)
+	router.PUT("/series/:id/posts", authCtrl.Protect, seriesCtrl.SetSeriesPosts)
+	router.DELETE("/series/:id", authCtrl.Protect, seriesCtrl.DeleteSeries)
+
+	// Rendered pages and feeds
+	router.GET("/", siteCtrl.GetIndexPage)
+	router.GET("/page/:number", siteCtrl.GetIndexPage)
+	router.GET("/authors/:userName", siteCtrl.GetAuthorPage)
+	router.GET(site.RSSPath, siteCtrl.GetRSSFeed)
+	router.GET(site.AtomPath, siteCtrl.GetAtomFeed)
+	router.GET(site.JSONFeedPath, siteCtrl.GetJSONFeed)
+	router.GET("/feed/:file", siteCtrl.GetJSONFeed)
+	router.GET(site.SitemapPath, siteCtrl.GetSitemap)
+	router.GET("/sitemaps/:file", siteCtrl.GetSitemapPart)
+	router.GET(site.RobotsPath, siteCtrl.GetRobots)
 
 	// Users
 	router.GET("/users", userCtrl.GetUsers)
@@ -32,10 +141,55 @@ func CreateRoutes(cont container.Container) {
 	router.PUT("/users/:userName", userCtrl.UpdateUser)
 	router.POST("/login", authCtrl.Login)
 
+	// Webmentions
+	router.POST(site.WebmentionPath, webmentionCtrl.ReceiveWebmention)
+	router.PUT("/webmentions/:id", authCtrl.Protect, webmentionCtrl.ModerateWebmention)
+
+	// Webhooks
+	router.GET("/webhooks", authCtrl.Protect, authCtrl.ProtectAdmin, webhookCtrl.GetWebhooks)
+	router.GET("/webhooks/:id/deliveries", authCtrl.Protect, authCtrl.ProtectAdmin, webhookCtrl.GetDeliveries)
+	router.POST("/webhooks", authCtrl.Protect, authCtrl.ProtectAdmin, webhookCtrl.AddWebhook)
+	router.DELETE("/webhooks/:id", authCtrl.Protect, authCtrl.ProtectAdmin, webhookCtrl.DeleteWebhook)
+
 	port := os.Getenv("PORT")
-	err := router.Run(":" + port)
+	err = router.Run(":" + port)
 
 	if err != nil {
 		log.Errorf("error encountered in router: %v", err)
 	}
 }
+
+// CreateEngine creates the engine serving the REST API. Only the given proxies are trusted to report the address of
+// the client in the X-Forwarded-For header, without any the address of the connection is used. Otherwise, clients could
+// pass the throttles keyed by their address by sending a different header with every request.
+func CreateEngine(trustedProxies []string) (*gin.Engine, error) {
+	router := gin.Default()
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, err
+	}
+	return router, nil
+}
+
+// loadTrustedProxies reads the comma-separated addresses and networks of the trusted proxies from the TRUSTED_PROXIES
+// environment variable. No proxy is trusted if it's unset.
+func loadTrustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// CreateCurationRoutes registers the endpoints highlighting posts and managing the collections.
+// The collections can be listed by anyone, but only the administrators are allowed to curate them.
+func CreateCurationRoutes(router gin.IRoutes, authCtrl AuthController, postCtrl PostController, collectionCtrl CollectionController) {
+	router.PUT("/posts/:id/highlight", authCtrl.Protect, authCtrl.ProtectAdmin, postCtrl.SetPostHighlight)
+	router.GET("/collections", collectionCtrl.GetCollections)
+	router.GET("/collections/:id", collectionCtrl.GetCollection)
+	router.POST("/collections", authCtrl.Protect, authCtrl.ProtectAdmin, collectionCtrl.AddCollection)
+	router.PUT("/collections/:id", authCtrl.Protect, authCtrl.ProtectAdmin, collectionCtrl.UpdateCollection)
+	router.PUT("/collections/:id/posts", authCtrl.Protect, authCtrl.ProtectAdmin, collectionCtrl.SetCollectionPosts)
+	router.DELETE("/collections/:id", authCtrl.Protect, authCtrl.ProtectAdmin, collectionCtrl.DeleteCollection)
+}
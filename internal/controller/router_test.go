@@ -0,0 +1,179 @@
+package controller_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/controller"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/site"
+	"github.com/wlchs/blog/internal/types"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// routerTestContext contains commonly used services and the router relevant for testing the registered routes.
+type routerTestContext struct {
+	mockJwtUtils          *mocks.MockTokenUtils
+	mockUserService       *mocks.MockUserService
+	mockPostService       *mocks.MockPostService
+	mockCollectionService *mocks.MockCollectionService
+	router                *gin.Engine
+}
+
+// createCurationRouterContext creates a router with the curation routes backed by mocked services.
+func createCurationRouterContext(t *testing.T) *routerTestContext {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	mockJwtUtils := mocks.NewMockTokenUtils(mockCtrl)
+	mockUserService := mocks.NewMockUserService(mockCtrl)
+	mockPostService := mocks.NewMockPostService(mockCtrl)
+	mockRelatedService := mocks.NewMockRelatedService(mockCtrl)
+	mockCollectionService := mocks.NewMockCollectionService(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, mockJwtUtils, nil, nil, nil)
+
+	router := gin.New()
+	controller.CreateCurationRoutes(
+		router,
+		controller.CreateAuthController(cont, mockUserService),
+		controller.CreatePostController(cont, mockPostService, mockRelatedService),
+		controller.CreateCollectionController(cont, mockCollectionService),
+	)
+
+	return &routerTestContext{mockJwtUtils, mockUserService, mockPostService, mockCollectionService, router}
+}
+
+// TestCreateCurationRoutes_Forbidden tests that regular users can neither highlight posts nor manage the collections.
+func TestCreateCurationRoutes_Forbidden(t *testing.T) {
+	t.Parallel()
+
+	requests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodPut, "/posts/intro/highlight", `{"pinned":true}`},
+		{http.MethodPost, "/collections", `{"urlHandle":"start-here","title":"Start here"}`},
+		{http.MethodPut, "/collections/start-here", `{"title":"Start here"}`},
+		{http.MethodPut, "/collections/start-here/posts", `{"posts":[]}`},
+		{http.MethodDelete, "/collections/start-here", ""},
+	}
+
+	for _, r := range requests {
+		r := r
+		t.Run(r.method+" "+r.path, func(t *testing.T) {
+			t.Parallel()
+			c := createCurationRouterContext(t)
+
+			c.mockJwtUtils.EXPECT().ParseJWT("token").Return("testAuthor", nil)
+			c.mockUserService.EXPECT().IsAdmin("testAuthor").Return(false)
+
+			req := httptest.NewRequest(r.method, r.path, strings.NewReader(r.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Auth-Token", "token")
+			rec := httptest.NewRecorder()
+
+			c.router.ServeHTTP(rec, req)
+
+			assert.Equal(t, 403, rec.Code, "incorrect response status")
+		})
+	}
+}
+
+// TestCreateCurationRoutes_Admin tests that administrators can curate the collections.
+func TestCreateCurationRoutes_Admin(t *testing.T) {
+	t.Parallel()
+	c := createCurationRouterContext(t)
+
+	c.mockJwtUtils.EXPECT().ParseJWT("token").Return("admin", nil)
+	c.mockUserService.EXPECT().IsAdmin("admin").Return(true)
+	c.mockCollectionService.EXPECT().DeleteCollection("start-here").Return(nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/collections/start-here", nil)
+	req.Header.Set("X-Auth-Token", "token")
+	rec := httptest.NewRecorder()
+
+	c.router.ServeHTTP(rec, req)
+
+	assert.Equal(t, 204, rec.Code, "incorrect response status")
+}
+
+// TestCreateEngine_Spoofed_Forwarded_For tests that readers can neither react again nor pass the throttle by sending a
+// different X-Forwarded-For header with every request, since no proxy is trusted by default.
+func TestCreateEngine_Spoofed_Forwarded_For(t *testing.T) {
+	t.Setenv("REACTION_SECRET", "secret")
+	t.Setenv("REACTION_RATE_LIMIT", "2")
+
+	mockCtrl := gomock.NewController(t)
+	mockPostRepository := mocks.NewMockPostRepository(mockCtrl)
+	mockReactionRepository := mocks.NewMockReactionRepository(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, mockPostRepository, mockReactionRepository, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	router, err := controller.CreateEngine(nil)
+	assert.Nil(t, err, "should complete without error")
+	router.PUT("/posts/:id/reactions/:emoji", controller.CreateReactionController(cont, services.CreateReactionService(cont)).AddReaction)
+
+	var fingerprints []string
+	mockPostRepository.EXPECT().GetPost("hello").Return(&repository.Post{ID: 1, URLHandle: "hello", Visibility: types.VisibilityPublic}, nil).Times(3)
+	mockReactionRepository.EXPECT().AddReaction(gomock.Any()).DoAndReturn(func(reaction *repository.Reaction) error {
+		fingerprints = append(fingerprints, reaction.Fingerprint)
+		return nil
+	}).Times(2)
+	mockReactionRepository.EXPECT().CountReactions(gomock.Any()).Return(map[uint]map[string]int{}, nil).Times(2)
+	mockReactionRepository.EXPECT().GetReactedEmojis(uint(1), gomock.Any()).Return([]string{"👍"}, nil).Times(2)
+
+	codes := make([]int, 0, 3)
+	for _, forwardedFor := range []string{"198.51.100.1", "198.51.100.2", "198.51.100.3"} {
+		req := httptest.NewRequest(http.MethodPut, "/posts/hello/reactions/👍", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rec := httptest.NewRecorder()
+
+		router.ServeHTTP(rec, req)
+		codes = append(codes, rec.Code)
+	}
+
+	assert.Equal(t, []int{200, 200, 429}, codes, "the reader should be throttled by the address of the connection")
+	assert.Equal(t, fingerprints[0], fingerprints[1], "the reader should be identified by the address of the connection")
+}
+
+// TestCreateEngine_Webmention_Sender tests throttling webmention senders by the address of the connection, unless the
+// request was forwarded by a trusted proxy.
+func TestCreateEngine_Webmention_Sender(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	mockWebmentionService := mocks.NewMockWebmentionService(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	router, err := controller.CreateEngine([]string{"10.0.0.1"})
+	assert.Nil(t, err, "should complete without error")
+	router.POST(site.WebmentionPath, controller.CreateWebmentionController(cont, mockWebmentionService).ReceiveWebmention)
+
+	source, target := "https://example.org/reply", "https://blog.example.com/posts/hello"
+	gomock.InOrder(
+		mockWebmentionService.EXPECT().ReceiveWebmention(source, target, "192.0.2.1").Return(nil),
+		mockWebmentionService.EXPECT().ReceiveWebmention(source, target, "198.51.100.1").Return(nil),
+	)
+
+	for _, remoteAddr := range []string{"192.0.2.1:1234", "10.0.0.1:1234"} {
+		form := url.Values{"source": {source}, "target": {target}}
+		req := httptest.NewRequest(http.MethodPost, site.WebmentionPath, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-Forwarded-For", "198.51.100.1")
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, 202, rec.Code, "incorrect response status")
+	}
+}
@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/types"
+	"net/http"
+)
+
+// SeriesController interface defining series-related middleware methods to handle HTTP requests
+type SeriesController interface {
+	AddSeries(c *gin.Context)
+	DeleteSeries(c *gin.Context)
+	GetSeries(c *gin.Context)
+	GetSeriesList(c *gin.Context)
+	SetSeriesPosts(c *gin.Context)
+	UpdateSeries(c *gin.Context)
+}
+
+// seriesController is a concrete implementation of the SeriesController interface
+type seriesController struct {
+	cont          container.Container
+	seriesService services.SeriesService
+}
+
+// CreateSeriesController instantiates a series controller using the application container.
+func CreateSeriesController(cont container.Container, seriesService services.SeriesService) SeriesController {
+	return &seriesController{cont, seriesService}
+}
+
+// AddSeries middleware. Top level handler of /series POST requests.
+func (controller seriesController) AddSeries(c *gin.Context) {
+	seriesService := controller.seriesService
+
+	var body types.Series
+	if err := c.BindJSON(&body); err != nil {
+		return
+	}
+
+	series, err := seriesService.AddSeries(&body, c.GetString("user"))
+
+	switch err.(type) {
+	case nil:
+		c.IndentedJSON(http.StatusCreated, series)
+
+	case errortypes.DuplicateElementError:
+		_ = c.AbortWithError(http.StatusConflict, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedSeriesError{Series: body})
+	}
+}
+
+// DeleteSeries middleware. Top level handler of /series/:id DELETE requests.
+func (controller seriesController) DeleteSeries(c *gin.Context) {
+	seriesService := controller.seriesService
+
+	id, found := c.Params.Get("id")
+	if !found {
+		_ = c.AbortWithError(http.StatusBadRequest, errortypes.MissingUrlHandleError{})
+		return
+	}
+
+	err := seriesService.DeleteSeries(id, c.GetString("user"))
+
+	switch err.(type) {
+	case nil:
+		c.Status(http.StatusNoContent)
+
+	case errortypes.SeriesEditForbiddenError:
+		_ = c.AbortWithError(http.StatusForbidden, err)
+
+	case errortypes.SeriesNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedSeriesError{Series: types.Series{URLHandle: id}})
+	}
+}
+
+// GetSeries middleware. Top level handler of /series/:id GET requests.
+func (controller seriesController) GetSeries(c *gin.Context) {
+	seriesService := controller.seriesService
+
+	id, found := c.Params.Get("id")
+	if !found {
+		_ = c.AbortWithError(http.StatusBadRequest, errortypes.MissingUrlHandleError{})
+		return
+	}
+
+	series, err := seriesService.GetSeries(id)
+
+	switch err.(type) {
+	case nil:
+		c.IndentedJSON(http.StatusOK, series)
+
+	case errortypes.SeriesNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedSeriesError{Series: types.Series{URLHandle: id}})
+	}
+}
+
+// GetSeriesList middleware. Top level handler of /series GET requests.
+func (controller seriesController) GetSeriesList(c *gin.Context) {
+	seriesService := controller.seriesService
+
+	series, err := seriesService.GetSeriesList()
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedSeriesError{})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, series)
+}
+
+// SetSeriesPosts middleware. Top level handler of /series/:id/posts PUT requests.
+// The request body contains the ordered list of post URL handles belonging to the series.
+func (controller seriesController) SetSeriesPosts(c *gin.Context) {
+	seriesService := controller.seriesService
+
+	id, found := c.Params.Get("id")
+	if !found {
+		_ = c.AbortWithError(http.StatusBadRequest, errortypes.MissingUrlHandleError{})
+		return
+	}
+
+	var body types.SeriesPostsInput
+	if err := c.BindJSON(&body); err != nil {
+		return
+	}
+
+	series, err := seriesService.SetSeriesPosts(id, body.Posts, c.GetString("user"))
+
+	switch err.(type) {
+	case nil:
+		c.IndentedJSON(http.StatusOK, series)
+
+	case errortypes.DuplicateSeriesPostError:
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+
+	case errortypes.SeriesEditForbiddenError, errortypes.PostEditForbiddenError:
+		_ = c.AbortWithError(http.StatusForbidden, err)
+
+	case errortypes.SeriesNotFoundError, errortypes.PostNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	case errortypes.PostAlreadyInSeriesError:
+		_ = c.AbortWithError(http.StatusConflict, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedSeriesError{Series: types.Series{URLHandle: id}})
+	}
+}
+
+// UpdateSeries middleware. Top level handler of /series/:id PUT requests.
+func (controller seriesController) UpdateSeries(c *gin.Context) {
+	seriesService := controller.seriesService
+
+	id, found := c.Params.Get("id")
+	if !found {
+		_ = c.AbortWithError(http.StatusBadRequest, errortypes.MissingUrlHandleError{})
+		return
+	}
+
+	var body types.Series
+	if err := c.BindJSON(&body); err != nil {
+		return
+	}
+
+	body.URLHandle = id
+	series, err := seriesService.UpdateSeries(&body, c.GetString("user"))
+
+	switch err.(type) {
+	case nil:
+		c.IndentedJSON(http.StatusOK, series)
+
+	case errortypes.SeriesEditForbiddenError:
+		_ = c.AbortWithError(http.StatusForbidden, err)
+
+	case errortypes.SeriesNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedSeriesError{Series: body})
+	}
+}
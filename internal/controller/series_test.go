@@ -0,0 +1,414 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/controller"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/test"
+	"github.com/wlchs/blog/internal/types"
+	"net/http/httptest"
+	"testing"
+)
+
+// seriesTestContext contains commonly used services, controllers and other objects relevant for testing the SeriesController.
+type seriesTestContext struct {
+	mockSeriesService *mocks.MockSeriesService
+	sut               controller.SeriesController
+	ctx               *gin.Context
+	rec               *httptest.ResponseRecorder
+}
+
+// createSeriesControllerContext creates the context for testing the SeriesController and reduces code duplication.
+func createSeriesControllerContext(t *testing.T) *seriesTestContext {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	mockSeriesService := mocks.NewMockSeriesService(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	sut := controller.CreateSeriesController(cont, mockSeriesService)
+	ctx, rec := test.CreateControllerContext()
+
+	return &seriesTestContext{mockSeriesService, sut, ctx, rec}
+}
+
+// TestSeriesController_AddSeries tests adding a new series with valid input params.
+func TestSeriesController_AddSeries(t *testing.T) {
+	t.Parallel()
+	c := createSeriesControllerContext(t)
+
+	input := types.Series{URLHandle: "testSeries", Title: "testTitle", Summary: "testSummary"}
+
+	test.MockJsonPost(c.ctx, input)
+	c.mockSeriesService.EXPECT().AddSeries(&input, "").Return(input, nil)
+
+	c.sut.AddSeries(c.ctx)
+
+	var output types.Series
+	_ = json.Unmarshal(c.rec.Body.Bytes(), &output)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, input, output, "response body should match")
+	assert.Equal(t, 201, c.rec.Code, "incorrect response status")
+}
+
+// TestSeriesController_AddSeries_Invalid_Input tests adding a new series with invalid input params.
+func TestSeriesController_AddSeries_Invalid_Input(t *testing.T) {
+	t.Parallel()
+	c := createSeriesControllerContext(t)
+
+	c.sut.AddSeries(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
+
+// TestSeriesController_AddSeries_Duplicate tests adding a new series with an already existing URL handle.
+func TestSeriesController_AddSeries_Duplicate(t *testing.T) {
+	t.Parallel()
+	c := createSeriesControllerContext(t)
+
+	input := types.Series{URLHandle: "testSeries"}
+	expectedError := errortypes.DuplicateElementError{Key: input.URLHandle}
+
+	test.MockJsonPost(c.ctx, input)
+	c.mockSeriesService.EXPECT().AddSeries(&input, "").Return(types.Series{}, expectedError)
+
+	c.sut.AddSeries(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 409, c.rec.Code, "incorrect response status")
+}
+
+// TestSeriesController_AddSeries_Unexpected_Error tests handling unexpected errors while adding a new series.
+func TestSeriesController_AddSeries_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createSeriesControllerContext(t)
+
+	input := types.Series{URLHandle: "testSeries"}
+	expectedError := errortypes.UnexpectedSeriesError{Series: input}
+
+	test.MockJsonPost(c.ctx, input)
+	c.mockSeriesService.EXPECT().AddSeries(&input, "").Return(types.Series{}, fmt.Errorf("unexpected error"))
+
+	c.sut.AddSeries(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 500, c.rec.Code, "incorrect response status")
+}
+
+// TestSeriesController_DeleteSeries tests removing a series.
+func TestSeriesController_DeleteSeries(t *testing.T) {
+	t.Parallel()
+	c := createSeriesControllerContext(t)
+
+	c.ctx.AddParam("id", "testSeries")
+	c.mockSeriesService.EXPECT().DeleteSeries("testSeries", "").Return(nil)
+
+	c.sut.DeleteSeries(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 204, c.ctx.Writer.Status(), "incorrect response status")
+}
+
+// TestSeriesController_DeleteSeries_Not_Found tests removing a non-existent series.
+func TestSeriesController_DeleteSeries_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createSeriesControllerContext(t)
+
+	expectedError := errortypes.SeriesNotFoundError{Series: types.Series{URLHandle: "testSeries"}}
+
+	c.ctx.AddParam("id", "testSeries")
+	c.mockSeriesService.EXPECT().DeleteSeries("testSeries", "").Return(expectedError)
+
+	c.sut.DeleteSeries(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+}
+
+// TestSeriesController_DeleteSeries_Forbidden tests removing a series by a user other than its owner.
+func TestSeriesController_DeleteSeries_Forbidden(t *testing.T) {
+	t.Parallel()
+	c := createSeriesControllerContext(t)
+
+	expectedError := errortypes.SeriesEditForbiddenError{Series: types.Series{URLHandle: "testSeries"}, UserName: "stranger"}
+
+	c.ctx.AddParam("id", "testSeries")
+	c.ctx.Set("user", "stranger")
+	c.mockSeriesService.EXPECT().DeleteSeries("testSeries", "stranger").Return(expectedError)
+
+	c.sut.DeleteSeries(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 403, c.rec.Code, "incorrect response status")
+}
+
+// TestSeriesController_DeleteSeries_Missing_URL_Handle tests removing a series without URL handle.
+func TestSeriesController_DeleteSeries_Missing_URL_Handle(t *testing.T) {
+	t.Parallel()
+	c := createSeriesControllerContext(t)
+
+	c.sut.DeleteSeries(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, errortypes.MissingUrlHandleError{}.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
+
+// TestSeriesController_GetSeries tests retrieving a single series.
+func TestSeriesController_GetSeries(t *testing.T) {
+	t.Parallel()
+	c := createSeriesControllerContext(t)
+
+	expectedOutput := types.Series{
+		URLHandle: "testSeries",
+		Title:     "testTitle",
+		Posts:     []types.Post{{URLHandle: "part1"}, {URLHandle: "part2"}},
+	}
+
+	c.ctx.AddParam("id", expectedOutput.URLHandle)
+	c.mockSeriesService.EXPECT().GetSeries(expectedOutput.URLHandle).Return(expectedOutput, nil)
+
+	c.sut.GetSeries(c.ctx)
+
+	var output types.Series
+	_ = json.Unmarshal(c.rec.Body.Bytes(), &output)
+
+	assert.Nil(t, c.ctx.Errors, "expected no errors")
+	assert.Equal(t, expectedOutput, output, "incorrect output body")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestSeriesController_GetSeries_Not_Found tests retrieving a non-existent series.
+func TestSeriesController_GetSeries_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createSeriesControllerContext(t)
+
+	expectedError := errortypes.SeriesNotFoundError{Series: types.Series{URLHandle: "testSeries"}}
+
+	c.ctx.AddParam("id", "testSeries")
+	c.mockSeriesService.EXPECT().GetSeries("testSeries").Return(types.Series{}, expectedError)
+
+	c.sut.GetSeries(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+}
+
+// TestSeriesController_GetSeriesList tests retrieving every series.
+func TestSeriesController_GetSeriesList(t *testing.T) {
+	t.Parallel()
+	c := createSeriesControllerContext(t)
+
+	expectedOutput := []types.Series{{URLHandle: "testSeries", Posts: []types.Post{}}}
+
+	c.mockSeriesService.EXPECT().GetSeriesList().Return(expectedOutput, nil)
+
+	c.sut.GetSeriesList(c.ctx)
+
+	var output []types.Series
+	_ = json.Unmarshal(c.rec.Body.Bytes(), &output)
+
+	assert.Nil(t, c.ctx.Errors, "expected no errors")
+	assert.Equal(t, expectedOutput, output, "incorrect output body")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestSeriesController_GetSeriesList_Unexpected_Error tests handling an unexpected error while retrieving every series.
+func TestSeriesController_GetSeriesList_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createSeriesControllerContext(t)
+
+	c.mockSeriesService.EXPECT().GetSeriesList().Return(nil, fmt.Errorf("unexpected error"))
+
+	c.sut.GetSeriesList(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, errortypes.UnexpectedSeriesError{}.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 500, c.rec.Code, "incorrect response status")
+}
+
+// TestSeriesController_SetSeriesPosts tests replacing the posts of a series.
+func TestSeriesController_SetSeriesPosts(t *testing.T) {
+	t.Parallel()
+	c := createSeriesControllerContext(t)
+
+	input := types.SeriesPostsInput{Posts: []string{"part2", "part1"}}
+	expectedOutput := types.Series{URLHandle: "testSeries", Posts: []types.Post{{URLHandle: "part2"}, {URLHandle: "part1"}}}
+
+	test.MockJsonPost(c.ctx, input)
+	c.ctx.AddParam("id", "testSeries")
+	c.mockSeriesService.EXPECT().SetSeriesPosts("testSeries", input.Posts, "").Return(expectedOutput, nil)
+
+	c.sut.SetSeriesPosts(c.ctx)
+
+	var output types.Series
+	_ = json.Unmarshal(c.rec.Body.Bytes(), &output)
+
+	assert.Nil(t, c.ctx.Errors, "expected no errors")
+	assert.Equal(t, expectedOutput, output, "incorrect output body")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestSeriesController_SetSeriesPosts_Post_Not_Found tests adding a non-existent post to a series.
+func TestSeriesController_SetSeriesPosts_Post_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createSeriesControllerContext(t)
+
+	input := types.SeriesPostsInput{Posts: []string{"missing"}}
+	expectedError := errortypes.PostNotFoundError{Post: types.Post{URLHandle: "missing"}}
+
+	test.MockJsonPost(c.ctx, input)
+	c.ctx.AddParam("id", "testSeries")
+	c.mockSeriesService.EXPECT().SetSeriesPosts("testSeries", input.Posts, "").Return(types.Series{}, expectedError)
+
+	c.sut.SetSeriesPosts(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+}
+
+// TestSeriesController_SetSeriesPosts_Already_In_Series tests adding a post which is part of another series.
+func TestSeriesController_SetSeriesPosts_Already_In_Series(t *testing.T) {
+	t.Parallel()
+	c := createSeriesControllerContext(t)
+
+	input := types.SeriesPostsInput{Posts: []string{"part1"}}
+	expectedError := errortypes.PostAlreadyInSeriesError{}
+
+	test.MockJsonPost(c.ctx, input)
+	c.ctx.AddParam("id", "testSeries")
+	c.mockSeriesService.EXPECT().SetSeriesPosts("testSeries", input.Posts, "").Return(types.Series{}, expectedError)
+
+	c.sut.SetSeriesPosts(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 409, c.rec.Code, "incorrect response status")
+}
+
+// TestSeriesController_SetSeriesPosts_Forbidden tests adding a post to a series by a user who may not edit the post.
+func TestSeriesController_SetSeriesPosts_Forbidden(t *testing.T) {
+	t.Parallel()
+	c := createSeriesControllerContext(t)
+
+	input := types.SeriesPostsInput{Posts: []string{"private"}}
+	expectedError := errortypes.PostEditForbiddenError{Post: types.Post{URLHandle: "private"}, UserName: "testAuthor"}
+
+	test.MockJsonPost(c.ctx, input)
+	c.ctx.AddParam("id", "testSeries")
+	c.ctx.Set("user", "testAuthor")
+	c.mockSeriesService.EXPECT().SetSeriesPosts("testSeries", input.Posts, "testAuthor").Return(types.Series{}, expectedError)
+
+	c.sut.SetSeriesPosts(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 403, c.rec.Code, "incorrect response status")
+}
+
+// TestSeriesController_SetSeriesPosts_Duplicate tests listing a post more than once.
+func TestSeriesController_SetSeriesPosts_Duplicate(t *testing.T) {
+	t.Parallel()
+	c := createSeriesControllerContext(t)
+
+	input := types.SeriesPostsInput{Posts: []string{"part1", "part1"}}
+	expectedError := errortypes.DuplicateSeriesPostError{Post: types.Post{URLHandle: "part1"}}
+
+	test.MockJsonPost(c.ctx, input)
+	c.ctx.AddParam("id", "testSeries")
+	c.mockSeriesService.EXPECT().SetSeriesPosts("testSeries", input.Posts, "").Return(types.Series{}, expectedError)
+
+	c.sut.SetSeriesPosts(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
+
+// TestSeriesController_UpdateSeries tests updating a series.
+func TestSeriesController_UpdateSeries(t *testing.T) {
+	t.Parallel()
+	c := createSeriesControllerContext(t)
+
+	input := types.Series{URLHandle: "testSeries", Title: "newTitle"}
+
+	test.MockJsonPost(c.ctx, types.Series{Title: input.Title})
+	c.ctx.AddParam("id", input.URLHandle)
+	c.mockSeriesService.EXPECT().UpdateSeries(&input, "").Return(input, nil)
+
+	c.sut.UpdateSeries(c.ctx)
+
+	var output types.Series
+	_ = json.Unmarshal(c.rec.Body.Bytes(), &output)
+
+	assert.Nil(t, c.ctx.Errors, "expected no errors")
+	assert.Equal(t, input, output, "incorrect output body")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestSeriesController_UpdateSeries_Unexpected_Error tests handling an unexpected error while updating a series.
+func TestSeriesController_UpdateSeries_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createSeriesControllerContext(t)
+
+	input := types.Series{URLHandle: "testSeries", Title: "newTitle"}
+	expectedError := errortypes.UnexpectedSeriesError{Series: input}
+
+	test.MockJsonPost(c.ctx, types.Series{Title: input.Title})
+	c.ctx.AddParam("id", input.URLHandle)
+	c.mockSeriesService.EXPECT().UpdateSeries(&input, "").Return(types.Series{}, fmt.Errorf("unexpected error"))
+
+	c.sut.UpdateSeries(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 500, c.rec.Code, "incorrect response status")
+}
+
+// TestSeriesController_UpdateSeries_Forbidden tests updating a series by a user other than its owner.
+func TestSeriesController_UpdateSeries_Forbidden(t *testing.T) {
+	t.Parallel()
+	c := createSeriesControllerContext(t)
+
+	input := types.Series{URLHandle: "testSeries", Title: "newTitle"}
+	expectedError := errortypes.SeriesEditForbiddenError{Series: types.Series{URLHandle: input.URLHandle}, UserName: "stranger"}
+
+	test.MockJsonPost(c.ctx, types.Series{Title: input.Title})
+	c.ctx.AddParam("id", input.URLHandle)
+	c.ctx.Set("user", "stranger")
+	c.mockSeriesService.EXPECT().UpdateSeries(&input, "stranger").Return(types.Series{}, expectedError)
+
+	c.sut.UpdateSeries(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 403, c.rec.Code, "incorrect response status")
+}
@@ -0,0 +1,316 @@
+package controller
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/site"
+	"github.com/wlchs/blog/internal/types"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Content types of the rendered pages and feeds.
+const (
+	contentTypeHTML     = "text/html; charset=utf-8"
+	contentTypeRSS      = "application/rss+xml; charset=utf-8"
+	contentTypeAtom     = "application/atom+xml; charset=utf-8"
+	contentTypeJSONFeed = "application/feed+json; charset=utf-8"
+	contentTypeXML      = "application/xml; charset=utf-8"
+	contentTypeText     = "text/plain; charset=utf-8"
+)
+
+// SiteController interface defining the middleware methods serving the rendered pages and feeds of the blog
+type SiteController interface {
+	GetAtomFeed(c *gin.Context)
+	GetAuthorPage(c *gin.Context)
+	GetIndexPage(c *gin.Context)
+	GetJSONFeed(c *gin.Context)
+	GetPostMeta(c *gin.Context)
+	GetRobots(c *gin.Context)
+	GetRSSFeed(c *gin.Context)
+	GetSitemap(c *gin.Context)
+	GetSitemapPart(c *gin.Context)
+	RenderPost(c *gin.Context)
+}
+
+// siteController is a concrete implementation of the SiteController interface
+type siteController struct {
+	cont        container.Container
+	siteService services.SiteService
+}
+
+// CreateSiteController instantiates a site controller using the application container.
+func CreateSiteController(cont container.Container, siteService services.SiteService) SiteController {
+	return &siteController{cont, siteService}
+}
+
+// GetAtomFeed middleware. Top level handler of /atom.xml GET requests.
+func (controller siteController) GetAtomFeed(c *gin.Context) {
+	controller.writeFeed(c, contentTypeAtom, site.WriteAtom)
+}
+
+// GetAuthorPage middleware. Top level handler of /authors/:userName GET requests.
+func (controller siteController) GetAuthorPage(c *gin.Context) {
+	siteService := controller.siteService
+
+	page, err := siteService.GetAuthorPage(c.Param("userName"))
+	if err != nil {
+		abortRendering(c, err)
+		return
+	}
+
+	if !requireBaseURL(c, page.Site) {
+		return
+	}
+	writePage(c, func(w *bytes.Buffer) error { return site.RenderAuthor(w, page) })
+}
+
+// GetIndexPage middleware. Top level handler of / and /page/:number GET requests.
+func (controller siteController) GetIndexPage(c *gin.Context) {
+	siteService := controller.siteService
+
+	number := 1
+	if param, found := c.Params.Get("number"); found {
+		n, err := strconv.Atoi(param)
+		// The first page is only served as the home page
+		if err != nil || n < 2 {
+			_ = c.AbortWithError(http.StatusNotFound, errortypes.PageNotFoundError{Page: n})
+			return
+		}
+		number = n
+	}
+
+	page, err := siteService.GetIndexPage(number)
+	if err != nil {
+		abortRendering(c, err)
+		return
+	}
+
+	if !requireBaseURL(c, page.Site) {
+		return
+	}
+	writePage(c, func(w *bytes.Buffer) error { return site.RenderIndex(w, page) })
+}
+
+// GetJSONFeed middleware. Top level handler of /feed.json and /feed/:file GET requests.
+// The first page is only served as /feed.json, further pages are linked from the previous one.
+func (controller siteController) GetJSONFeed(c *gin.Context) {
+	siteService := controller.siteService
+
+	number := 1
+	if file, found := c.Params.Get("file"); found {
+		n, err := strconv.Atoi(strings.TrimSuffix(file, ".json"))
+		if err != nil || n < 2 || !strings.HasSuffix(file, ".json") {
+			_ = c.AbortWithError(http.StatusNotFound, errortypes.PageNotFoundError{Page: n})
+			return
+		}
+		number = n
+	}
+
+	page, err := siteService.GetJSONFeed(number)
+	if err != nil {
+		abortRendering(c, err)
+		return
+	}
+
+	if !requireBaseURL(c, page.Site) {
+		return
+	}
+	writeDocument(c, contentTypeJSONFeed, func(w *bytes.Buffer) error { return site.WriteJSONFeed(w, page) })
+}
+
+// GetRobots middleware. Top level handler of /robots.txt GET requests.
+func (controller siteController) GetRobots(c *gin.Context) {
+	siteService := controller.siteService
+
+	robots, err := siteService.GetRobots()
+	if err != nil {
+		abortRendering(c, err)
+		return
+	}
+
+	if !requireBaseURL(c, robots.Site) {
+		return
+	}
+	writeDocument(c, contentTypeText, func(w *bytes.Buffer) error { return site.WriteRobots(w, robots) })
+}
+
+// GetRSSFeed middleware. Top level handler of /feed.xml GET requests.
+func (controller siteController) GetRSSFeed(c *gin.Context) {
+	controller.writeFeed(c, contentTypeRSS, site.WriteRSS)
+}
+
+// GetSitemap middleware. Top level handler of /sitemap.xml GET requests.
+// Sitemaps with too many pages are split, the sitemap index listing the parts is returned instead.
+func (controller siteController) GetSitemap(c *gin.Context) {
+	siteService := controller.siteService
+
+	sitemap, err := siteService.GetSitemap()
+	if err != nil {
+		abortRendering(c, err)
+		return
+	}
+
+	if !requireBaseURL(c, sitemap.Site) {
+		return
+	}
+	parts := sitemap.Split(site.MaxSitemapURLs)
+	if len(parts) == 1 {
+		writeDocument(c, contentTypeXML, func(w *bytes.Buffer) error { return site.WriteSitemap(w, parts[0]) })
+		return
+	}
+	writeDocument(c, contentTypeXML, func(w *bytes.Buffer) error { return site.WriteSitemapIndex(w, sitemap.Site, parts) })
+}
+
+// GetSitemapPart middleware. Top level handler of /sitemaps/:file GET requests, serving a part of a split sitemap.
+func (controller siteController) GetSitemapPart(c *gin.Context) {
+	siteService := controller.siteService
+
+	number, err := strconv.Atoi(strings.TrimSuffix(c.Param("file"), ".xml"))
+	if err != nil || !strings.HasSuffix(c.Param("file"), ".xml") {
+		_ = c.AbortWithError(http.StatusNotFound, errortypes.PageNotFoundError{Page: number})
+		return
+	}
+
+	sitemap, err := siteService.GetSitemap()
+	if err != nil {
+		abortRendering(c, err)
+		return
+	}
+
+	if !requireBaseURL(c, sitemap.Site) {
+		return
+	}
+	parts := sitemap.Split(site.MaxSitemapURLs)
+	if number < 1 || number > len(parts) {
+		_ = c.AbortWithError(http.StatusNotFound, errortypes.PageNotFoundError{Page: number})
+		return
+	}
+	writeDocument(c, contentTypeXML, func(w *bytes.Buffer) error { return site.WriteSitemap(w, parts[number-1]) })
+}
+
+// GetPostMeta middleware. Top level handler of /posts/:id/meta GET requests.
+// Returns the Open Graph, Twitter Card and JSON-LD metadata of a post for frontends rendering the pages themselves.
+func (controller siteController) GetPostMeta(c *gin.Context) {
+	page, ok := controller.getPostPage(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Language", page.Language)
+	c.Header("Vary", "Accept-Language")
+	c.IndentedJSON(http.StatusOK, page.Meta())
+}
+
+// RenderPost middleware. Renders the page of a post for /posts/:id GET requests preferring HTML, e.g. from browsers.
+// Other requests are passed on to the JSON API.
+func (controller siteController) RenderPost(c *gin.Context) {
+	if c.NegotiateFormat(binding.MIMEJSON, binding.MIMEHTML) != binding.MIMEHTML {
+		c.Next()
+		return
+	}
+	c.Abort()
+
+	page, ok := controller.getPostPage(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Language", page.Language)
+	c.Header("Vary", "Accept, Accept-Language")
+	writePage(c, func(w *bytes.Buffer) error { return site.RenderPost(w, page) })
+}
+
+// getPostPage retrieves the page of the requested post for the authenticated user in the negotiated language.
+// The request is aborted if the page can't be retrieved.
+func (controller siteController) getPostPage(c *gin.Context) (site.PostPage, bool) {
+	siteService := controller.siteService
+
+	id, found := c.Params.Get("id")
+	if !found {
+		_ = c.AbortWithError(http.StatusBadRequest, errortypes.MissingUrlHandleError{})
+		return site.PostPage{}, false
+	}
+
+	access := types.PostAccess{
+		UserName:    c.GetString("user"),
+		AccessToken: c.Request.Header.Get("X-Post-Token"),
+	}
+
+	languages, ok := requestLanguages(c)
+	if !ok {
+		return site.PostPage{}, false
+	}
+
+	page, err := siteService.GetPostPage(id, access, languages)
+	if err != nil {
+		abortRendering(c, err)
+		return site.PostPage{}, false
+	}
+
+	if !requireBaseURL(c, page.Site) {
+		return site.PostPage{}, false
+	}
+	return page, true
+}
+
+// writeFeed writes the latest posts in the format of the given feed writer.
+func (controller siteController) writeFeed(c *gin.Context, contentType string, write func(w io.Writer, feed site.Feed) error) {
+	siteService := controller.siteService
+
+	feed, err := siteService.GetFeed()
+	if err != nil {
+		abortRendering(c, err)
+		return
+	}
+
+	if !requireBaseURL(c, feed.Site) {
+		return
+	}
+	writeDocument(c, contentType, func(w *bytes.Buffer) error { return write(w, feed) })
+}
+
+// writePage writes a rendered HTML page.
+func writePage(c *gin.Context, render func(w *bytes.Buffer) error) {
+	writeDocument(c, contentTypeHTML, render)
+}
+
+// writeDocument writes a rendered page or document. Rendering errors abort the request before anything is written.
+func writeDocument(c *gin.Context, contentType string, render func(w *bytes.Buffer) error) {
+	var buf bytes.Buffer
+	if err := render(&buf); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedPostError{})
+		return
+	}
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// abortRendering aborts a request of a rendered page or feed with the status code matching the error.
+func abortRendering(c *gin.Context, err error) {
+	switch err.(type) {
+	case errortypes.PostPasswordRequiredError:
+		_ = c.AbortWithError(http.StatusUnauthorized, err)
+
+	case errortypes.PageNotFoundError, errortypes.PostNotFoundError, errortypes.UserNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedPostError{})
+	}
+}
+
+// requireBaseURL checks whether the base URL of the site is configured, aborting the request otherwise.
+// The absolute URLs of the pages and feeds are never derived from the Host header, since it's controlled by the client.
+func requireBaseURL(c *gin.Context, config site.Config) bool {
+	if config.BaseURL == "" {
+		_ = c.AbortWithError(http.StatusServiceUnavailable, errortypes.MissingSiteURLError{})
+		return false
+	}
+	return true
+}
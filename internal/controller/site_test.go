@@ -0,0 +1,353 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/controller"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/site"
+	"github.com/wlchs/blog/internal/test"
+	"github.com/wlchs/blog/internal/types"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// siteTestContext contains commonly used services, controllers and other objects relevant for testing the SiteController.
+type siteTestContext struct {
+	mockSiteService *mocks.MockSiteService
+	sut             controller.SiteController
+	ctx             *gin.Context
+	rec             *httptest.ResponseRecorder
+}
+
+// createSiteControllerContext creates the context for testing the SiteController and reduces code duplication.
+func createSiteControllerContext(t *testing.T) *siteTestContext {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	mockSiteService := mocks.NewMockSiteService(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	sut := controller.CreateSiteController(cont, mockSiteService)
+	ctx, rec := test.CreateControllerContext()
+
+	return &siteTestContext{mockSiteService, sut, ctx, rec}
+}
+
+// testSiteConfig is the site configuration used for testing purposes.
+var testSiteConfig = site.Config{BaseURL: "http://blog.test", Title: "Test blog", Language: "en", PageSize: 10}
+
+// TestSiteController_GetIndexPage tests rendering the home page using the configured base URL, ignoring the host of the request.
+func TestSiteController_GetIndexPage(t *testing.T) {
+	t.Parallel()
+	c := createSiteControllerContext(t)
+
+	page := site.IndexPage{Page: site.Page{Site: testSiteConfig, Path: "/", Language: "en"}, Number: 1, Total: 1}
+
+	c.ctx.Request.Host = "attacker.test"
+	c.mockSiteService.EXPECT().GetIndexPage(1).Return(page, nil)
+
+	c.sut.GetIndexPage(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+	assert.Equal(t, "text/html; charset=utf-8", c.rec.Header().Get("Content-Type"), "incorrect content type")
+	assert.Contains(t, c.rec.Body.String(), `<link rel="canonical" href="http://blog.test/">`, "configured base URL should be used")
+}
+
+// TestSiteController_GetIndexPage_Missing_Site_URL tests refusing to render pages if the base URL isn't configured.
+func TestSiteController_GetIndexPage_Missing_Site_URL(t *testing.T) {
+	t.Parallel()
+	c := createSiteControllerContext(t)
+
+	config := testSiteConfig
+	config.BaseURL = ""
+	page := site.IndexPage{Page: site.Page{Site: config, Path: "/", Language: "en"}, Number: 1, Total: 1}
+
+	c.ctx.Request.Host = "attacker.test"
+	c.mockSiteService.EXPECT().GetIndexPage(1).Return(page, nil)
+
+	c.sut.GetIndexPage(c.ctx)
+
+	assert.Equal(t, errortypes.MissingSiteURLError{}, c.ctx.Errors.Last().Err, "error doesn't match expected one")
+	assert.Equal(t, 503, c.rec.Code, "incorrect response status")
+	assert.NotContains(t, c.rec.Body.String(), "attacker.test", "host of the request shouldn't be used")
+}
+
+// TestSiteController_GetIndexPage_Not_Found tests rendering a missing page of the index.
+func TestSiteController_GetIndexPage_Not_Found(t *testing.T) {
+	t.Parallel()
+
+	for _, number := range []string{"1", "invalid", "5"} {
+		c := createSiteControllerContext(t)
+
+		c.ctx.AddParam("number", number)
+		if number == "5" {
+			c.mockSiteService.EXPECT().GetIndexPage(5).Return(site.IndexPage{}, errortypes.PageNotFoundError{Page: 5})
+		}
+
+		c.sut.GetIndexPage(c.ctx)
+
+		assert.Equal(t, 1, len(c.ctx.Errors), fmt.Sprintf("expected exactly 1 error for page %s", number))
+		assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+	}
+}
+
+// TestSiteController_GetAuthorPage tests rendering the page of an author.
+func TestSiteController_GetAuthorPage(t *testing.T) {
+	t.Parallel()
+	c := createSiteControllerContext(t)
+
+	page := site.AuthorPage{Page: site.Page{Site: testSiteConfig, Path: "/authors/jane", Language: "en"}, Author: "jane"}
+
+	c.ctx.AddParam("userName", "jane")
+	c.mockSiteService.EXPECT().GetAuthorPage("jane").Return(page, nil)
+
+	c.sut.GetAuthorPage(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+	assert.Contains(t, c.rec.Body.String(), "<h1>Posts by jane</h1>", "author page should be rendered")
+}
+
+// TestSiteController_GetAuthorPage_Not_Found tests rendering the page of a missing user.
+func TestSiteController_GetAuthorPage_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createSiteControllerContext(t)
+
+	expectedError := errortypes.UserNotFoundError{User: types.User{UserName: "john"}}
+
+	c.ctx.AddParam("userName", "john")
+	c.mockSiteService.EXPECT().GetAuthorPage("john").Return(site.AuthorPage{}, expectedError)
+
+	c.sut.GetAuthorPage(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+}
+
+// TestSiteController_GetRSSFeed tests serving the RSS feed.
+func TestSiteController_GetRSSFeed(t *testing.T) {
+	t.Parallel()
+	c := createSiteControllerContext(t)
+
+	feed := site.Feed{Site: testSiteConfig, Posts: []types.Post{{URLHandle: "hello", Title: "Hello", CreationTime: time.Now()}}}
+	feed.Site.BaseURL = "https://example.com"
+
+	c.mockSiteService.EXPECT().GetFeed().Return(feed, nil)
+
+	c.sut.GetRSSFeed(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+	assert.Equal(t, "application/rss+xml; charset=utf-8", c.rec.Header().Get("Content-Type"), "incorrect content type")
+	assert.Contains(t, c.rec.Body.String(), "<link>https://example.com/posts/hello</link>", "configured base URL should be used")
+}
+
+// TestSiteController_GetAtomFeed_Error tests serving the Atom feed if the posts can't be retrieved.
+func TestSiteController_GetAtomFeed_Error(t *testing.T) {
+	t.Parallel()
+	c := createSiteControllerContext(t)
+
+	c.mockSiteService.EXPECT().GetFeed().Return(site.Feed{}, fmt.Errorf("unexpected error"))
+
+	c.sut.GetAtomFeed(c.ctx)
+
+	assert.Equal(t, 1, len(c.ctx.Errors), "expected exactly 1 error")
+	assert.Equal(t, 500, c.rec.Code, "incorrect response status")
+}
+
+// TestSiteController_GetJSONFeed tests serving a page of the JSON Feed linking the next page.
+func TestSiteController_GetJSONFeed(t *testing.T) {
+	t.Parallel()
+	c := createSiteControllerContext(t)
+
+	page := site.JSONFeedPage{Feed: site.Feed{Site: testSiteConfig, Posts: []types.Post{{URLHandle: "hello", Title: "Hello"}}}, Number: 2, Total: 3}
+
+	c.ctx.AddParam("file", "2.json")
+	c.mockSiteService.EXPECT().GetJSONFeed(2).Return(page, nil)
+
+	c.sut.GetJSONFeed(c.ctx)
+
+	var feed map[string]any
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+	assert.Equal(t, "application/feed+json; charset=utf-8", c.rec.Header().Get("Content-Type"), "incorrect content type")
+	assert.Nil(t, json.Unmarshal(c.rec.Body.Bytes(), &feed), "feed should be valid JSON")
+	assert.Equal(t, "http://blog.test/feed/3.json", feed["next_url"], "next page should be linked")
+}
+
+// TestSiteController_GetJSONFeed_Not_Found tests serving missing pages of the JSON Feed.
+func TestSiteController_GetJSONFeed_Not_Found(t *testing.T) {
+	t.Parallel()
+
+	for _, file := range []string{"1.json", "2.xml", "invalid.json", "3.json"} {
+		c := createSiteControllerContext(t)
+
+		c.ctx.AddParam("file", file)
+		if file == "3.json" {
+			c.mockSiteService.EXPECT().GetJSONFeed(3).Return(site.JSONFeedPage{}, errortypes.PageNotFoundError{Page: 3})
+		}
+
+		c.sut.GetJSONFeed(c.ctx)
+
+		assert.Equal(t, 1, len(c.ctx.Errors), fmt.Sprintf("expected exactly 1 error for %s", file))
+		assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+	}
+}
+
+// TestSiteController_GetSitemap tests serving the sitemap.
+func TestSiteController_GetSitemap(t *testing.T) {
+	t.Parallel()
+	c := createSiteControllerContext(t)
+
+	sitemap := site.Sitemap{Site: testSiteConfig, URLs: []site.SitemapURL{{Loc: "http://blog.test/posts/hello"}}}
+
+	c.mockSiteService.EXPECT().GetSitemap().Return(sitemap, nil)
+
+	c.sut.GetSitemap(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+	assert.Equal(t, "application/xml; charset=utf-8", c.rec.Header().Get("Content-Type"), "incorrect content type")
+	assert.Contains(t, c.rec.Body.String(), "<loc>http://blog.test/posts/hello</loc>", "page should be listed")
+}
+
+// TestSiteController_GetSitemapPart_Not_Found tests serving missing parts of the sitemap.
+func TestSiteController_GetSitemapPart_Not_Found(t *testing.T) {
+	t.Parallel()
+
+	for _, file := range []string{"1.txt", "invalid.xml", "2.xml"} {
+		c := createSiteControllerContext(t)
+
+		c.ctx.AddParam("file", file)
+		if file == "2.xml" {
+			c.mockSiteService.EXPECT().GetSitemap().Return(site.Sitemap{Site: testSiteConfig}, nil)
+		}
+
+		c.sut.GetSitemapPart(c.ctx)
+
+		assert.Equal(t, 1, len(c.ctx.Errors), fmt.Sprintf("expected exactly 1 error for %s", file))
+		assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+	}
+}
+
+// TestSiteController_GetRobots tests serving the robots.txt referencing the sitemap.
+func TestSiteController_GetRobots(t *testing.T) {
+	t.Parallel()
+	c := createSiteControllerContext(t)
+
+	c.mockSiteService.EXPECT().GetRobots().Return(site.Robots{Site: testSiteConfig}, nil)
+
+	c.sut.GetRobots(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+	assert.Equal(t, "text/plain; charset=utf-8", c.rec.Header().Get("Content-Type"), "incorrect content type")
+	assert.Contains(t, c.rec.Body.String(), "Sitemap: http://blog.test/sitemap.xml", "sitemap should be referenced")
+}
+
+// TestSiteController_GetPostMeta tests retrieving the metadata of a post.
+func TestSiteController_GetPostMeta(t *testing.T) {
+	t.Parallel()
+	c := createSiteControllerContext(t)
+
+	image := "/media/1"
+	post := types.Post{URLHandle: "hello", Title: "Hello", Author: "jane", CoverImage: &image}
+	page := site.PostPage{Page: site.Page{Site: testSiteConfig, Path: "/posts/hello", Language: "en"}, Post: post}
+
+	c.ctx.AddParam("id", "hello")
+	c.mockSiteService.EXPECT().GetPostPage("hello", types.PostAccess{}, []string{}).Return(page, nil)
+
+	c.sut.GetPostMeta(c.ctx)
+
+	var output types.PostMeta
+	_ = json.Unmarshal(c.rec.Body.Bytes(), &output)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+	assert.Equal(t, "http://blog.test/posts/hello", output.URL, "URL should use the host of the request")
+	assert.Equal(t, "http://blog.test/media/1", output.JSONLD.Image, "cover image should be resolved")
+}
+
+// TestSiteController_GetPostMeta_Not_Found tests retrieving the metadata of a missing post.
+func TestSiteController_GetPostMeta_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createSiteControllerContext(t)
+
+	expectedError := errortypes.PostNotFoundError{Post: types.Post{URLHandle: "hello"}}
+
+	c.ctx.AddParam("id", "hello")
+	c.mockSiteService.EXPECT().GetPostPage("hello", types.PostAccess{}, []string{}).Return(site.PostPage{}, expectedError)
+
+	c.sut.GetPostMeta(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+}
+
+// TestSiteController_RenderPost tests rendering the page of a post for browsers.
+func TestSiteController_RenderPost(t *testing.T) {
+	t.Parallel()
+	c := createSiteControllerContext(t)
+
+	page := site.PostPage{Page: site.Page{Site: testSiteConfig, Title: "Hello", Path: "/posts/hello", Language: "de-DE"}, Post: types.Post{Title: "Hello"}, Body: "<p>Hallo</p>"}
+	access := types.PostAccess{UserName: "jane"}
+
+	c.ctx.AddParam("id", "hello")
+	c.ctx.Set("user", access.UserName)
+	c.ctx.Request.Header.Set("Accept", "text/html,application/xhtml+xml,*/*;q=0.8")
+	c.ctx.Request.Header.Set("Accept-Language", "de")
+	c.mockSiteService.EXPECT().GetPostPage("hello", access, []string{"de"}).Return(page, nil)
+
+	c.sut.RenderPost(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.True(t, c.ctx.IsAborted(), "JSON handler shouldn't be called")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+	assert.Equal(t, "de-DE", c.rec.Header().Get("Content-Language"), "incorrect content language")
+	assert.Contains(t, c.rec.Body.String(), "<p>Hallo</p>", "post should be rendered")
+}
+
+// TestSiteController_RenderPost_JSON tests passing API requests on to the JSON handler.
+func TestSiteController_RenderPost_JSON(t *testing.T) {
+	t.Parallel()
+	c := createSiteControllerContext(t)
+
+	c.ctx.AddParam("id", "hello")
+	c.ctx.Request.Header.Set("Accept", "application/json")
+
+	c.sut.RenderPost(c.ctx)
+
+	assert.False(t, c.ctx.IsAborted(), "request should be passed on")
+	assert.Equal(t, 0, c.rec.Body.Len(), "nothing should be written")
+}
+
+// TestSiteController_RenderPost_Password_Required tests rendering a password-protected post without access token.
+func TestSiteController_RenderPost_Password_Required(t *testing.T) {
+	t.Parallel()
+	c := createSiteControllerContext(t)
+
+	expectedError := errortypes.PostPasswordRequiredError{Post: types.Post{URLHandle: "hello"}}
+
+	c.ctx.AddParam("id", "hello")
+	c.ctx.Request.Header.Set("Accept", "text/html")
+	c.mockSiteService.EXPECT().GetPostPage("hello", types.PostAccess{}, []string{}).Return(site.PostPage{}, expectedError)
+
+	c.sut.RenderPost(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 401, c.rec.Code, "incorrect response status")
+}
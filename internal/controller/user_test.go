@@ -31,7 +31,7 @@ func createUserControllerContext(t *testing.T) *userTestContext {
 
 	mockCtrl := gomock.NewController(t)
 	mockUserService := mocks.NewMockUserService(mockCtrl)
-	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	sut := controller.CreateUserController(cont, mockUserService)
 	ctx, rec := test.CreateControllerContext()
 
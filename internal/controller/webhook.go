@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/types"
+	"net/http"
+	"strconv"
+)
+
+// WebhookController interface defining webhook-related middleware methods to handle HTTP requests
+type WebhookController interface {
+	AddWebhook(c *gin.Context)
+	DeleteWebhook(c *gin.Context)
+	GetDeliveries(c *gin.Context)
+	GetWebhooks(c *gin.Context)
+}
+
+// webhookController is a concrete implementation of the WebhookController interface
+type webhookController struct {
+	cont           container.Container
+	webhookService services.WebhookService
+}
+
+// CreateWebhookController instantiates a webhook controller using the application container.
+func CreateWebhookController(cont container.Container, webhookService services.WebhookService) WebhookController {
+	return &webhookController{cont, webhookService}
+}
+
+// AddWebhook middleware. Top level handler of /webhooks POST requests.
+// The response contains the secret used for signing the payloads, it can't be retrieved later.
+func (controller webhookController) AddWebhook(c *gin.Context) {
+	webhookService := controller.webhookService
+
+	var body types.Webhook
+	if err := c.BindJSON(&body); err != nil {
+		return
+	}
+
+	webhook, err := webhookService.AddWebhook(&body)
+
+	switch err.(type) {
+	case nil:
+		c.IndentedJSON(http.StatusCreated, webhook)
+
+	case errortypes.InvalidWebhookError:
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedWebhookError{})
+	}
+}
+
+// DeleteWebhook middleware. Top level handler of /webhooks/:id DELETE requests.
+func (controller webhookController) DeleteWebhook(c *gin.Context) {
+	webhookService := controller.webhookService
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusNotFound, errortypes.WebhookNotFoundError{})
+		return
+	}
+
+	err = webhookService.DeleteWebhook(uint(id))
+
+	switch err.(type) {
+	case nil:
+		c.Status(http.StatusNoContent)
+
+	case errortypes.WebhookNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedWebhookError{Webhook: types.Webhook{ID: uint(id)}})
+	}
+}
+
+// GetDeliveries middleware. Top level handler of /webhooks/:id/deliveries GET requests.
+// Returns the latest deliveries of the webhook, starting with the latest one.
+func (controller webhookController) GetDeliveries(c *gin.Context) {
+	webhookService := controller.webhookService
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusNotFound, errortypes.WebhookNotFoundError{})
+		return
+	}
+
+	deliveries, err := webhookService.GetDeliveries(uint(id))
+
+	switch err.(type) {
+	case nil:
+		c.IndentedJSON(http.StatusOK, deliveries)
+
+	case errortypes.WebhookNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedWebhookError{Webhook: types.Webhook{ID: uint(id)}})
+	}
+}
+
+// GetWebhooks middleware. Top level handler of /webhooks GET requests.
+func (controller webhookController) GetWebhooks(c *gin.Context) {
+	webhookService := controller.webhookService
+
+	webhooks, err := webhookService.GetWebhooks()
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedWebhookError{})
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, webhooks)
+}
@@ -0,0 +1,155 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/controller"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/test"
+	"github.com/wlchs/blog/internal/types"
+	"net/http/httptest"
+	"testing"
+)
+
+// webhookTestContext contains commonly used services, controllers and other objects relevant for testing the WebhookController.
+type webhookTestContext struct {
+	mockWebhookService *mocks.MockWebhookService
+	sut                controller.WebhookController
+	ctx                *gin.Context
+	rec                *httptest.ResponseRecorder
+}
+
+// createWebhookControllerContext creates the context for testing the WebhookController and reduces code duplication.
+func createWebhookControllerContext(t *testing.T) *webhookTestContext {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	mockWebhookService := mocks.NewMockWebhookService(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	sut := controller.CreateWebhookController(cont, mockWebhookService)
+	ctx, rec := test.CreateControllerContext()
+
+	return &webhookTestContext{mockWebhookService, sut, ctx, rec}
+}
+
+// TestWebhookController_AddWebhook tests adding a new webhook with valid input params.
+func TestWebhookController_AddWebhook(t *testing.T) {
+	t.Parallel()
+	c := createWebhookControllerContext(t)
+
+	input := types.Webhook{URL: "https://example.com/hook", Events: []string{types.EventPostCreated}}
+	expectedOutput := types.Webhook{ID: 1, URL: input.URL, Events: input.Events, Secret: "0123456789abcdef"}
+
+	test.MockJsonPost(c.ctx, input)
+	c.mockWebhookService.EXPECT().AddWebhook(&input).Return(expectedOutput, nil)
+
+	c.sut.AddWebhook(c.ctx)
+
+	var output types.Webhook
+	_ = json.Unmarshal(c.rec.Body.Bytes(), &output)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, expectedOutput, output, "response body should match")
+	assert.Equal(t, 201, c.rec.Code, "incorrect response status")
+}
+
+// TestWebhookController_AddWebhook_Invalid tests adding an invalid webhook.
+func TestWebhookController_AddWebhook_Invalid(t *testing.T) {
+	t.Parallel()
+	c := createWebhookControllerContext(t)
+
+	input := types.Webhook{URL: "https://example.com/hook"}
+	expectedError := errortypes.InvalidWebhookError{Reason: "at least one event is required"}
+
+	test.MockJsonPost(c.ctx, input)
+	c.mockWebhookService.EXPECT().AddWebhook(&input).Return(types.Webhook{}, expectedError)
+
+	c.sut.AddWebhook(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
+
+// TestWebhookController_DeleteWebhook tests removing a webhook.
+func TestWebhookController_DeleteWebhook(t *testing.T) {
+	t.Parallel()
+	c := createWebhookControllerContext(t)
+
+	c.ctx.AddParam("id", "1")
+	c.mockWebhookService.EXPECT().DeleteWebhook(uint(1)).Return(nil)
+
+	c.sut.DeleteWebhook(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 204, c.ctx.Writer.Status(), "incorrect response status")
+}
+
+// TestWebhookController_DeleteWebhook_Not_Found tests removing a non-existent webhook.
+func TestWebhookController_DeleteWebhook_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createWebhookControllerContext(t)
+
+	expectedError := errortypes.WebhookNotFoundError{Webhook: types.Webhook{ID: 2}}
+
+	c.ctx.AddParam("id", "2")
+	c.mockWebhookService.EXPECT().DeleteWebhook(uint(2)).Return(expectedError)
+
+	c.sut.DeleteWebhook(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+}
+
+// TestWebhookController_GetDeliveries tests retrieving the delivery log of a webhook.
+func TestWebhookController_GetDeliveries(t *testing.T) {
+	t.Parallel()
+	c := createWebhookControllerContext(t)
+
+	expectedOutput := []types.WebhookDelivery{{ID: 1, Event: types.EventPostDeleted, Status: types.DeliveryFailed, Attempts: 8, Payload: json.RawMessage(`{}`)}}
+
+	c.ctx.AddParam("id", "1")
+	c.mockWebhookService.EXPECT().GetDeliveries(uint(1)).Return(expectedOutput, nil)
+
+	c.sut.GetDeliveries(c.ctx)
+
+	var output []types.WebhookDelivery
+	_ = json.Unmarshal(c.rec.Body.Bytes(), &output)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, expectedOutput, output, "response body should match")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestWebhookController_GetDeliveries_Invalid_ID tests retrieving the delivery log with a malformed webhook ID.
+func TestWebhookController_GetDeliveries_Invalid_ID(t *testing.T) {
+	t.Parallel()
+	c := createWebhookControllerContext(t)
+
+	c.ctx.AddParam("id", "abc")
+
+	c.sut.GetDeliveries(c.ctx)
+
+	assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+}
+
+// TestWebhookController_GetWebhooks_Unexpected_Error tests handling an unexpected error while retrieving every webhook.
+func TestWebhookController_GetWebhooks_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createWebhookControllerContext(t)
+
+	c.mockWebhookService.EXPECT().GetWebhooks().Return(nil, fmt.Errorf("unexpected error"))
+
+	c.sut.GetWebhooks(c.ctx)
+
+	assert.Equal(t, 500, c.rec.Code, "incorrect response status")
+}
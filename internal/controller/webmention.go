@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/types"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// WebmentionController interface defining webmention-related middleware methods to handle HTTP requests
+type WebmentionController interface {
+	GetWebmentions(c *gin.Context)
+	ModerateWebmention(c *gin.Context)
+	ReceiveWebmention(c *gin.Context)
+}
+
+// webmentionController is a concrete implementation of the WebmentionController interface
+type webmentionController struct {
+	cont              container.Container
+	webmentionService services.WebmentionService
+}
+
+// CreateWebmentionController instantiates a webmention controller using the application container.
+func CreateWebmentionController(cont container.Container, webmentionService services.WebmentionService) WebmentionController {
+	return &webmentionController{cont, webmentionService}
+}
+
+// GetWebmentions middleware. Top level handler of /posts/:id/webmentions GET requests.
+// The author and the contributors of the post may filter the mentions using the status query parameter.
+func (controller webmentionController) GetWebmentions(c *gin.Context) {
+	webmentionService := controller.webmentionService
+
+	mentions, err := webmentionService.GetWebmentions(c.Param("id"), c.Query("status"), c.GetString("user"))
+
+	switch err.(type) {
+	case nil:
+		c.IndentedJSON(http.StatusOK, mentions)
+
+	case errortypes.InvalidWebmentionError:
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+
+	case errortypes.PostNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedWebmentionError{})
+	}
+}
+
+// ModerateWebmention middleware. Top level handler of /webmentions/:id PUT requests.
+func (controller webmentionController) ModerateWebmention(c *gin.Context) {
+	webmentionService := controller.webmentionService
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusNotFound, errortypes.WebmentionNotFoundError{})
+		return
+	}
+
+	var body types.WebmentionModerationInput
+	if err := c.BindJSON(&body); err != nil {
+		return
+	}
+
+	mention, err := webmentionService.ModerateWebmention(uint(id), body.Status, c.GetString("user"))
+
+	switch err.(type) {
+	case nil:
+		c.IndentedJSON(http.StatusOK, mention)
+
+	case errortypes.InvalidWebmentionError:
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+
+	case errortypes.PostEditForbiddenError:
+		_ = c.AbortWithError(http.StatusForbidden, err)
+
+	case errortypes.WebmentionNotFoundError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedWebmentionError{})
+	}
+}
+
+// ReceiveWebmention middleware. Top level handler of /webmention POST requests.
+// The source and the target are sent form-encoded, the source is verified asynchronously.
+// Throttled senders are told when they may send mentions again in the Retry-After header. Senders are throttled by the
+// address of the connection, unless the request was forwarded by one of the trusted proxies of the engine.
+func (controller webmentionController) ReceiveWebmention(c *gin.Context) {
+	webmentionService := controller.webmentionService
+
+	source, target := c.PostForm("source"), c.PostForm("target")
+	if source == "" || target == "" {
+		_ = c.AbortWithError(http.StatusBadRequest, errortypes.InvalidWebmentionError{Reason: "the source and the target are required"})
+		return
+	}
+
+	err := webmentionService.ReceiveWebmention(source, target, c.ClientIP())
+
+	switch err := err.(type) {
+	case nil:
+		c.Status(http.StatusAccepted)
+
+	case errortypes.InvalidWebmentionError:
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+
+	case errortypes.WebmentionRateLimitError:
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(err.RetryAfter.Seconds()))))
+		_ = c.AbortWithError(http.StatusTooManyRequests, err)
+
+	case errortypes.WebmentionDisabledError:
+		_ = c.AbortWithError(http.StatusNotFound, err)
+
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, errortypes.UnexpectedWebmentionError{})
+	}
+}
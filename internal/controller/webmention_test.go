@@ -0,0 +1,212 @@
+package controller_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/controller"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/test"
+	"github.com/wlchs/blog/internal/types"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// webmentionTestContext contains commonly used services, controllers and other objects relevant for testing the WebmentionController.
+type webmentionTestContext struct {
+	mockWebmentionService *mocks.MockWebmentionService
+	sut                   controller.WebmentionController
+	ctx                   *gin.Context
+	rec                   *httptest.ResponseRecorder
+}
+
+// createWebmentionControllerContext creates the context for testing the WebmentionController and reduces code duplication.
+func createWebmentionControllerContext(t *testing.T) *webmentionTestContext {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	mockWebmentionService := mocks.NewMockWebmentionService(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	sut := controller.CreateWebmentionController(cont, mockWebmentionService)
+	ctx, rec := test.CreateControllerContext()
+
+	return &webmentionTestContext{mockWebmentionService, sut, ctx, rec}
+}
+
+// mockFormPost sets the form-encoded body of the request.
+func mockFormPost(c *gin.Context, form url.Values) {
+	c.Request.Method = "POST"
+	c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.Request.Body = io.NopCloser(strings.NewReader(form.Encode()))
+}
+
+// TestWebmentionController_ReceiveWebmention tests accepting a mention for verification.
+func TestWebmentionController_ReceiveWebmention(t *testing.T) {
+	t.Parallel()
+	c := createWebmentionControllerContext(t)
+
+	mockFormPost(c.ctx, url.Values{"source": {"https://remote.example.com/reply"}, "target": {"https://blog.example.com/posts/hello"}})
+	c.mockWebmentionService.EXPECT().ReceiveWebmention("https://remote.example.com/reply", "https://blog.example.com/posts/hello", gomock.Any()).Return(nil)
+
+	c.sut.ReceiveWebmention(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 202, c.ctx.Writer.Status(), "incorrect response status")
+}
+
+// TestWebmentionController_ReceiveWebmention_Missing_Target tests receiving a mention without a target.
+func TestWebmentionController_ReceiveWebmention_Missing_Target(t *testing.T) {
+	t.Parallel()
+	c := createWebmentionControllerContext(t)
+
+	mockFormPost(c.ctx, url.Values{"source": {"https://remote.example.com/reply"}})
+
+	c.sut.ReceiveWebmention(c.ctx)
+
+	assert.Equal(t, 1, len(c.ctx.Errors), "expected exactly 1 error")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
+
+// TestWebmentionController_ReceiveWebmention_Invalid tests receiving a mention of an unknown post.
+func TestWebmentionController_ReceiveWebmention_Invalid(t *testing.T) {
+	t.Parallel()
+	c := createWebmentionControllerContext(t)
+
+	expectedError := errortypes.InvalidWebmentionError{Reason: "the target isn't a post of this blog"}
+
+	mockFormPost(c.ctx, url.Values{"source": {"https://remote.example.com/reply"}, "target": {"https://blog.example.com/posts/missing"}})
+	c.mockWebmentionService.EXPECT().ReceiveWebmention(gomock.Any(), gomock.Any(), gomock.Any()).Return(expectedError)
+
+	c.sut.ReceiveWebmention(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 400, c.rec.Code, "incorrect response status")
+}
+
+// TestWebmentionController_ReceiveWebmention_Throttled tests receiving a mention from a throttled sender.
+func TestWebmentionController_ReceiveWebmention_Throttled(t *testing.T) {
+	t.Parallel()
+	c := createWebmentionControllerContext(t)
+
+	expectedError := errortypes.WebmentionRateLimitError{RetryAfter: 90 * time.Second}
+
+	mockFormPost(c.ctx, url.Values{"source": {"https://remote.example.com/reply"}, "target": {"https://blog.example.com/posts/hello"}})
+	c.mockWebmentionService.EXPECT().ReceiveWebmention(gomock.Any(), gomock.Any(), gomock.Any()).Return(expectedError)
+
+	c.sut.ReceiveWebmention(c.ctx)
+
+	assert.Equal(t, 1, len(c.ctx.Errors), "expected exactly 1 error")
+	assert.Equal(t, "90", c.rec.Header().Get("Retry-After"), "the sender should be told when to retry")
+	assert.Equal(t, 429, c.rec.Code, "incorrect response status")
+}
+
+// TestWebmentionController_ReceiveWebmention_Disabled tests receiving a mention while the site URL isn't set.
+func TestWebmentionController_ReceiveWebmention_Disabled(t *testing.T) {
+	t.Parallel()
+	c := createWebmentionControllerContext(t)
+
+	mockFormPost(c.ctx, url.Values{"source": {"https://remote.example.com/reply"}, "target": {"https://blog.example.com/posts/hello"}})
+	c.mockWebmentionService.EXPECT().ReceiveWebmention(gomock.Any(), gomock.Any(), gomock.Any()).Return(errortypes.WebmentionDisabledError{})
+
+	c.sut.ReceiveWebmention(c.ctx)
+
+	assert.Equal(t, 1, len(c.ctx.Errors), "expected exactly 1 error")
+	assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+}
+
+// TestWebmentionController_GetWebmentions tests listing the mentions of a post.
+func TestWebmentionController_GetWebmentions(t *testing.T) {
+	t.Parallel()
+	c := createWebmentionControllerContext(t)
+
+	mentions := []types.Webmention{{ID: 1, Post: "hello", Status: types.WebmentionVerified}}
+
+	c.ctx.AddParam("id", "hello")
+	c.ctx.Set("user", "testAuthor")
+	c.ctx.Request.URL.RawQuery = "status=verified"
+	c.mockWebmentionService.EXPECT().GetWebmentions("hello", types.WebmentionVerified, "testAuthor").Return(mentions, nil)
+
+	c.sut.GetWebmentions(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+	assert.Contains(t, c.rec.Body.String(), `"status": "verified"`, "mentions should be returned")
+}
+
+// TestWebmentionController_GetWebmentions_Not_Found tests listing the mentions of an unknown post.
+func TestWebmentionController_GetWebmentions_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createWebmentionControllerContext(t)
+
+	expectedError := errortypes.PostNotFoundError{Post: types.Post{URLHandle: "missing"}}
+
+	c.ctx.AddParam("id", "missing")
+	c.mockWebmentionService.EXPECT().GetWebmentions("missing", "", "").Return([]types.Webmention{}, expectedError)
+
+	c.sut.GetWebmentions(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+}
+
+// TestWebmentionController_ModerateWebmention tests approving a mention.
+func TestWebmentionController_ModerateWebmention(t *testing.T) {
+	t.Parallel()
+	c := createWebmentionControllerContext(t)
+
+	mention := types.Webmention{ID: 1, Post: "hello", Status: types.WebmentionApproved}
+
+	c.ctx.AddParam("id", "1")
+	c.ctx.Set("user", "testAuthor")
+	test.MockJsonPost(c.ctx, types.WebmentionModerationInput{Status: types.WebmentionApproved})
+	c.mockWebmentionService.EXPECT().ModerateWebmention(uint(1), types.WebmentionApproved, "testAuthor").Return(mention, nil)
+
+	c.sut.ModerateWebmention(c.ctx)
+
+	assert.Nil(t, c.ctx.Errors, "should complete without error")
+	assert.Equal(t, 200, c.rec.Code, "incorrect response status")
+}
+
+// TestWebmentionController_ModerateWebmention_Forbidden tests moderating a mention of another author's post.
+func TestWebmentionController_ModerateWebmention_Forbidden(t *testing.T) {
+	t.Parallel()
+	c := createWebmentionControllerContext(t)
+
+	expectedError := errortypes.PostEditForbiddenError{Post: types.Post{URLHandle: "hello"}, UserName: "otherAuthor"}
+
+	c.ctx.AddParam("id", "1")
+	c.ctx.Set("user", "otherAuthor")
+	test.MockJsonPost(c.ctx, types.WebmentionModerationInput{Status: types.WebmentionRejected})
+	c.mockWebmentionService.EXPECT().ModerateWebmention(uint(1), types.WebmentionRejected, "otherAuthor").Return(types.Webmention{}, expectedError)
+
+	c.sut.ModerateWebmention(c.ctx)
+
+	errors := c.ctx.Errors.Errors()
+	assert.Equal(t, 1, len(errors), "expected exactly 1 error")
+	assert.Equal(t, expectedError.Error(), errors[0], "incorrect error type")
+	assert.Equal(t, 403, c.rec.Code, "incorrect response status")
+}
+
+// TestWebmentionController_ModerateWebmention_Invalid_ID tests moderating a mention with a malformed ID.
+func TestWebmentionController_ModerateWebmention_Invalid_ID(t *testing.T) {
+	t.Parallel()
+	c := createWebmentionControllerContext(t)
+
+	c.ctx.AddParam("id", "first")
+
+	c.sut.ModerateWebmention(c.ctx)
+
+	assert.Equal(t, 1, len(c.ctx.Errors), "expected exactly 1 error")
+	assert.Equal(t, 404, c.rec.Code, "incorrect response status")
+}
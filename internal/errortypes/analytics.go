@@ -0,0 +1,17 @@
+package errortypes
+
+import "fmt"
+
+type UnexpectedAnalyticsError struct{}
+
+func (e UnexpectedAnalyticsError) Error() string {
+	return "unexpected analytics error encountered"
+}
+
+type InvalidAnalyticsQueryError struct {
+	Reason string
+}
+
+func (e InvalidAnalyticsQueryError) Error() string {
+	return fmt.Sprintf("invalid statistics query: %s", e.Reason)
+}
@@ -1,5 +1,7 @@
 package errortypes
 
+import "fmt"
+
 type MissingAuthTokenError struct{}
 
 func (m MissingAuthTokenError) Error() string {
@@ -11,3 +13,11 @@ type InvalidAuthTokenError struct{}
 func (i InvalidAuthTokenError) Error() string {
 	return "auth token expired or invalid"
 }
+
+type AdminRightsRequiredError struct {
+	UserName string
+}
+
+func (e AdminRightsRequiredError) Error() string {
+	return fmt.Sprintf("user \"%s\" doesn't have admin rights", e.UserName)
+}
@@ -0,0 +1,25 @@
+package errortypes
+
+import (
+	"fmt"
+	"github.com/wlchs/blog/internal/types"
+)
+
+type UnexpectedCollectionError struct {
+	Collection types.Collection
+}
+
+func (e UnexpectedCollectionError) Error() string {
+	if e.Collection.URLHandle != "" {
+		return fmt.Sprintf("unexpected error encountered with collection \"%s\"", e.Collection.URLHandle)
+	}
+	return "unexpected collection error encountered"
+}
+
+type CollectionNotFoundError struct {
+	Collection types.Collection
+}
+
+func (e CollectionNotFoundError) Error() string {
+	return fmt.Sprintf("collection with URL handle \"%s\" not found", e.Collection.URLHandle)
+}
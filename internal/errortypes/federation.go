@@ -0,0 +1,39 @@
+package errortypes
+
+import "fmt"
+
+type UnexpectedFederationError struct{}
+
+func (e UnexpectedFederationError) Error() string {
+	return "unexpected federation error encountered"
+}
+
+type FederationDisabledError struct{}
+
+func (e FederationDisabledError) Error() string {
+	return "SITE_URL must be set to federate the blog"
+}
+
+type InvalidActivityError struct {
+	Reason string
+}
+
+func (e InvalidActivityError) Error() string {
+	return fmt.Sprintf("invalid activity: %s", e.Reason)
+}
+
+type InvalidSignatureError struct {
+	Reason string
+}
+
+func (e InvalidSignatureError) Error() string {
+	return fmt.Sprintf("invalid signature: %s", e.Reason)
+}
+
+type ActorKeyNotFoundError struct {
+	UserName string
+}
+
+func (e ActorKeyNotFoundError) Error() string {
+	return fmt.Sprintf("key of actor %s not found", e.UserName)
+}
@@ -0,0 +1,51 @@
+package errortypes
+
+import (
+	"fmt"
+	"github.com/wlchs/blog/internal/types"
+)
+
+type UnexpectedFieldError struct {
+	Field types.FieldDefinition
+}
+
+func (e UnexpectedFieldError) Error() string {
+	if e.Field.Name != "" {
+		return fmt.Sprintf("unexpected error encountered with custom field \"%s\"", e.Field.Name)
+	}
+	return "unexpected custom field error encountered"
+}
+
+type FieldNotFoundError struct {
+	Field types.FieldDefinition
+}
+
+func (e FieldNotFoundError) Error() string {
+	return fmt.Sprintf("custom field \"%s\" not found", e.Field.Name)
+}
+
+type InvalidFieldDefinitionError struct {
+	Field  types.FieldDefinition
+	Reason string
+}
+
+func (e InvalidFieldDefinitionError) Error() string {
+	return fmt.Sprintf("invalid definition of custom field \"%s\": %s", e.Field.Name, e.Reason)
+}
+
+type InvalidCustomFieldError struct {
+	Name   string
+	Reason string
+}
+
+func (e InvalidCustomFieldError) Error() string {
+	return fmt.Sprintf("invalid value of custom field \"%s\": %s", e.Name, e.Reason)
+}
+
+type MissingCustomFieldError struct {
+	Name string
+}
+
+func (e MissingCustomFieldError) Error() string {
+	return fmt.Sprintf("required custom field \"%s\" is missing", e.Name)
+}
@@ -0,0 +1,109 @@
+package errortypes
+
+import (
+	"fmt"
+	"github.com/wlchs/blog/internal/types"
+)
+
+type UnexpectedMediaError struct {
+	Media types.Media
+}
+
+func (e UnexpectedMediaError) Error() string {
+	if e.Media.ID != 0 {
+		return fmt.Sprintf("unexpected error encountered with media %d", e.Media.ID)
+	}
+	return "unexpected media error encountered"
+}
+
+type MediaNotFoundError struct {
+	Media types.Media
+}
+
+func (e MediaNotFoundError) Error() string {
+	return fmt.Sprintf("media %d not found", e.Media.ID)
+}
+
+type MissingMediaFileError struct{}
+
+func (e MissingMediaFileError) Error() string {
+	return "no file provided"
+}
+
+type MediaTooLargeError struct {
+	Limit int64
+}
+
+func (e MediaTooLargeError) Error() string {
+	return fmt.Sprintf("file exceeds the upload size limit of %d bytes", e.Limit)
+}
+
+type ImageTooLargeError struct {
+	Limit int64
+}
+
+func (e ImageTooLargeError) Error() string {
+	return fmt.Sprintf("image exceeds the limit of %d pixels", e.Limit)
+}
+
+type UnsupportedMediaTypeError struct {
+	ContentType string
+}
+
+func (e UnsupportedMediaTypeError) Error() string {
+	return fmt.Sprintf("unsupported media type \"%s\"", e.ContentType)
+}
+
+type MediaDeleteForbiddenError struct {
+	Media    types.Media
+	UserName string
+}
+
+func (e MediaDeleteForbiddenError) Error() string {
+	return fmt.Sprintf("user \"%s\" is not allowed to delete media %d", e.UserName, e.Media.ID)
+}
+
+type StorageObjectNotFoundError struct {
+	Key string
+}
+
+func (e StorageObjectNotFoundError) Error() string {
+	return fmt.Sprintf("stored file \"%s\" not found", e.Key)
+}
+
+type InvalidStorageKeyError struct {
+	Key string
+}
+
+func (e InvalidStorageKeyError) Error() string {
+	return fmt.Sprintf("invalid storage key \"%s\"", e.Key)
+}
+
+type InvalidImageError struct {
+	Reason string
+}
+
+func (e InvalidImageError) Error() string {
+	return fmt.Sprintf("invalid image: %s", e.Reason)
+}
+
+type MediaVariantNotFoundError struct {
+	Media   types.Media
+	Variant string
+}
+
+func (e MediaVariantNotFoundError) Error() string {
+	return fmt.Sprintf("variant \"%s\" of media %d not found", e.Variant, e.Media.ID)
+}
+
+type ObjectStorageError struct {
+	Operation  string
+	Key        string
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e ObjectStorageError) Error() string {
+	return fmt.Sprintf("object storage request \"%s\" of \"%s\" failed with status %d: %s %s", e.Operation, e.Key, e.StatusCode, e.Code, e.Message)
+}
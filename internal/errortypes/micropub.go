@@ -0,0 +1,17 @@
+package errortypes
+
+import "fmt"
+
+type UnexpectedMicropubError struct{}
+
+func (e UnexpectedMicropubError) Error() string {
+	return "unexpected Micropub error encountered"
+}
+
+type InvalidMicropubRequestError struct {
+	Reason string
+}
+
+func (e InvalidMicropubRequestError) Error() string {
+	return fmt.Sprintf("invalid Micropub request: %s", e.Reason)
+}
@@ -0,0 +1,31 @@
+package errortypes
+
+import "fmt"
+
+type UnexpectedNewsletterError struct{}
+
+func (e UnexpectedNewsletterError) Error() string {
+	return "unexpected newsletter error encountered"
+}
+
+type InvalidSubscriptionError struct {
+	Reason string
+}
+
+func (e InvalidSubscriptionError) Error() string {
+	return fmt.Sprintf("invalid subscription: %s", e.Reason)
+}
+
+type InvalidSubscriptionTokenError struct{}
+
+func (e InvalidSubscriptionTokenError) Error() string {
+	return "invalid or expired subscription token"
+}
+
+type SubscriberNotFoundError struct {
+	Email string
+}
+
+func (e SubscriberNotFoundError) Error() string {
+	return fmt.Sprintf("subscriber %s not found", e.Email)
+}
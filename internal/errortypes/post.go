@@ -29,3 +29,122 @@ type PostNotFoundError struct {
 func (e PostNotFoundError) Error() string {
 	return fmt.Sprintf("post with URL handle \"%s\" not found", e.Post.URLHandle)
 }
+
+type PostEditForbiddenError struct {
+	Post     types.Post
+	UserName string
+}
+
+func (e PostEditForbiddenError) Error() string {
+	return fmt.Sprintf("user \"%s\" is not allowed to edit post \"%s\"", e.UserName, e.Post.URLHandle)
+}
+
+type InvalidContributorRoleError struct {
+	Role string
+}
+
+func (e InvalidContributorRoleError) Error() string {
+	return fmt.Sprintf("invalid contributor role \"%s\"", e.Role)
+}
+
+type InvalidPostVisibilityError struct {
+	Visibility string
+}
+
+func (e InvalidPostVisibilityError) Error() string {
+	return fmt.Sprintf("invalid post visibility \"%s\"", e.Visibility)
+}
+
+type InvalidCanonicalURLError struct {
+	URL string
+}
+
+func (e InvalidCanonicalURLError) Error() string {
+	return fmt.Sprintf("invalid canonical URL \"%s\": only absolute http and https URLs are supported", e.URL)
+}
+
+type InvalidCoverImageError struct {
+	URL string
+}
+
+func (e InvalidCoverImageError) Error() string {
+	return fmt.Sprintf("invalid cover image \"%s\": only absolute http and https URLs and paths of the blog are supported", e.URL)
+}
+
+type MissingPostPasswordError struct{}
+
+func (e MissingPostPasswordError) Error() string {
+	return "password-protected posts require a password"
+}
+
+type PostPasswordRequiredError struct {
+	Post types.Post
+}
+
+func (e PostPasswordRequiredError) Error() string {
+	return fmt.Sprintf("post \"%s\" is password-protected, a valid access token is required", e.Post.URLHandle)
+}
+
+type IncorrectPostPasswordError struct{}
+
+func (e IncorrectPostPasswordError) Error() string {
+	return "incorrect post password"
+}
+
+type PostNotPasswordProtectedError struct {
+	Post types.Post
+}
+
+func (e PostNotPasswordProtectedError) Error() string {
+	return fmt.Sprintf("post \"%s\" is not password-protected", e.Post.URLHandle)
+}
+
+type InvalidLanguageError struct {
+	Language string
+}
+
+func (e InvalidLanguageError) Error() string {
+	return fmt.Sprintf("invalid language tag \"%s\"", e.Language)
+}
+
+type OriginalLanguageTranslationError struct {
+	Post     types.Post
+	Language string
+}
+
+func (e OriginalLanguageTranslationError) Error() string {
+	return fmt.Sprintf("post \"%s\" is originally written in \"%s\", it can't be translated to the same language", e.Post.URLHandle, e.Language)
+}
+
+type TranslationNotFoundError struct {
+	Post     types.Post
+	Language string
+}
+
+func (e TranslationNotFoundError) Error() string {
+	return fmt.Sprintf("translation \"%s\" of post \"%s\" not found", e.Language, e.Post.URLHandle)
+}
+
+type PostDeleteForbiddenError struct {
+	Post     types.Post
+	UserName string
+}
+
+func (e PostDeleteForbiddenError) Error() string {
+	return fmt.Sprintf("user \"%s\" is not allowed to delete post \"%s\"", e.UserName, e.Post.URLHandle)
+}
+
+type InvalidPostFilterError struct {
+	Parameter string
+	Value     string
+}
+
+func (e InvalidPostFilterError) Error() string {
+	return fmt.Sprintf("invalid value \"%s\" of post filter \"%s\"", e.Value, e.Parameter)
+}
+
+type InvalidPasswordHashError struct{}
+
+func (e InvalidPasswordHashError) Error() string {
+	return "invalid password hash"
+}
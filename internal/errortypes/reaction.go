@@ -0,0 +1,28 @@
+package errortypes
+
+import (
+	"fmt"
+	"time"
+)
+
+type UnexpectedReactionError struct{}
+
+func (e UnexpectedReactionError) Error() string {
+	return "unexpected reaction error encountered"
+}
+
+type InvalidReactionError struct {
+	Emoji string
+}
+
+func (e InvalidReactionError) Error() string {
+	return fmt.Sprintf("unsupported reaction %q", e.Emoji)
+}
+
+type ReactionRateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e ReactionRateLimitError) Error() string {
+	return fmt.Sprintf("too many reactions, try again in %s", e.RetryAfter.Round(time.Second))
+}
@@ -0,0 +1,11 @@
+package errortypes
+
+import "fmt"
+
+type InvalidRelatedPostsLimitError struct {
+	Limit string
+}
+
+func (e InvalidRelatedPostsLimitError) Error() string {
+	return fmt.Sprintf("invalid number of related posts %q", e.Limit)
+}
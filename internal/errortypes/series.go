@@ -0,0 +1,48 @@
+package errortypes
+
+import (
+	"fmt"
+	"github.com/wlchs/blog/internal/types"
+)
+
+type UnexpectedSeriesError struct {
+	Series types.Series
+}
+
+func (e UnexpectedSeriesError) Error() string {
+	if e.Series.URLHandle != "" {
+		return fmt.Sprintf("unexpected error encountered with series \"%s\"", e.Series.URLHandle)
+	}
+	return "unexpected series error encountered"
+}
+
+type SeriesNotFoundError struct {
+	Series types.Series
+}
+
+func (e SeriesNotFoundError) Error() string {
+	return fmt.Sprintf("series with URL handle \"%s\" not found", e.Series.URLHandle)
+}
+
+type PostAlreadyInSeriesError struct{}
+
+func (e PostAlreadyInSeriesError) Error() string {
+	return "post is already part of another series"
+}
+
+type SeriesEditForbiddenError struct {
+	Series   types.Series
+	UserName string
+}
+
+func (e SeriesEditForbiddenError) Error() string {
+	return fmt.Sprintf("user \"%s\" is not allowed to edit series \"%s\"", e.UserName, e.Series.URLHandle)
+}
+
+type DuplicateSeriesPostError struct {
+	Post types.Post
+}
+
+func (e DuplicateSeriesPostError) Error() string {
+	return fmt.Sprintf("post \"%s\" is listed more than once", e.Post.URLHandle)
+}
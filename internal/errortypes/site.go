@@ -0,0 +1,17 @@
+package errortypes
+
+import "fmt"
+
+type PageNotFoundError struct {
+	Page int
+}
+
+func (e PageNotFoundError) Error() string {
+	return fmt.Sprintf("page %d not found", e.Page)
+}
+
+type MissingSiteURLError struct{}
+
+func (e MissingSiteURLError) Error() string {
+	return "SITE_URL must be set to render the site"
+}
@@ -0,0 +1,33 @@
+package errortypes
+
+import (
+	"fmt"
+	"github.com/wlchs/blog/internal/types"
+)
+
+type UnexpectedWebhookError struct {
+	Webhook types.Webhook
+}
+
+func (e UnexpectedWebhookError) Error() string {
+	if e.Webhook.ID != 0 {
+		return fmt.Sprintf("unexpected error encountered with webhook %d", e.Webhook.ID)
+	}
+	return "unexpected webhook error encountered"
+}
+
+type WebhookNotFoundError struct {
+	Webhook types.Webhook
+}
+
+func (e WebhookNotFoundError) Error() string {
+	return fmt.Sprintf("webhook %d not found", e.Webhook.ID)
+}
+
+type InvalidWebhookError struct {
+	Reason string
+}
+
+func (e InvalidWebhookError) Error() string {
+	return fmt.Sprintf("invalid webhook: %s", e.Reason)
+}
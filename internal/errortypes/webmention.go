@@ -0,0 +1,42 @@
+package errortypes
+
+import (
+	"fmt"
+	"time"
+)
+
+type UnexpectedWebmentionError struct{}
+
+func (e UnexpectedWebmentionError) Error() string {
+	return "unexpected webmention error encountered"
+}
+
+type WebmentionDisabledError struct{}
+
+func (e WebmentionDisabledError) Error() string {
+	return "SITE_URL must be set to receive webmentions"
+}
+
+type InvalidWebmentionError struct {
+	Reason string
+}
+
+func (e InvalidWebmentionError) Error() string {
+	return fmt.Sprintf("invalid webmention: %s", e.Reason)
+}
+
+type WebmentionNotFoundError struct {
+	ID uint
+}
+
+func (e WebmentionNotFoundError) Error() string {
+	return fmt.Sprintf("webmention %d not found", e.ID)
+}
+
+type WebmentionRateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e WebmentionRateLimitError) Error() string {
+	return fmt.Sprintf("too many webmentions, try again in %s", e.RetryAfter.Round(time.Second))
+}
@@ -0,0 +1,219 @@
+package events
+
+import (
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/types"
+	"go.uber.org/zap"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// batchSize is the number of pending events read from the outbox at once.
+const batchSize = 100
+
+// maxAttempts is the number of attempts to dispatch an event to the synchronous subscribers before giving up.
+const maxAttempts = 10
+
+// claimLease is the time the pending events read from the outbox are claimed for, before another instance sharing the
+// database may dispatch them, e.g. after a crash.
+const claimLease = 5 * time.Minute
+
+// relayInterval is the interval of dispatching the events left in the outbox, e.g. after a crash.
+const relayInterval = 30 * time.Second
+
+// retention is the time dispatched events are kept in the outbox before they're removed.
+const retention = 24 * time.Hour
+
+// Handler processes a domain event. Returning an error from a synchronous handler causes the event to be dispatched again.
+type Handler func(event types.DomainEvent) error
+
+// Bus interface. Dispatches the domain events stored in the outbox to the in-process subscribers.
+type Bus interface {
+	Dispatch() (int, error)
+	Run(stop <-chan struct{})
+	Subscribe(event string, handler Handler)
+	SubscribeAsync(event string, handler Handler)
+}
+
+// bus is the concrete implementation of the Bus interface.
+type bus struct {
+	logger           *zap.SugaredLogger
+	outboxRepository repository.OutboxRepository
+
+	lock  sync.RWMutex
+	sync  map[string][]Handler
+	async map[string][]Handler
+
+	// dispatching ensures that the outbox is only dispatched by a single goroutine at once,
+	// rerun signals it that new events were stored in the meantime
+	dispatching sync.Mutex
+	rerun       atomic.Bool
+}
+
+// CreateBus instantiates the event bus using the outbox repository.
+func CreateBus(logger *zap.SugaredLogger, outboxRepository repository.OutboxRepository) Bus {
+	return &bus{
+		logger:           logger,
+		outboxRepository: outboxRepository,
+		sync:             map[string][]Handler{},
+		async:            map[string][]Handler{},
+	}
+}
+
+// Subscribe registers a synchronous handler of an event. Synchronous handlers are called by Dispatch, and the event is
+// only marked as dispatched once every one of them succeeded, so they must tolerate receiving an event more than once.
+func (b *bus) Subscribe(event string, handler Handler) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.sync[event] = append(b.sync[event], handler)
+}
+
+// SubscribeAsync registers an asynchronous handler of an event. Asynchronous handlers are started in the background
+// once the event is marked as dispatched, their failures are only logged.
+func (b *bus) SubscribeAsync(event string, handler Handler) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.async[event] = append(b.async[event], handler)
+}
+
+// Dispatch delivers the pending events of the outbox to the subscribers in the order they were stored,
+// and returns the number of dispatched events. If another goroutine is already dispatching, e.g. because a handler
+// triggered further events, the events are left to it and Dispatch returns immediately. The events are claimed in the
+// outbox, so other instances sharing the database don't dispatch them at the same time.
+func (b *bus) Dispatch() (int, error) {
+	dispatched := 0
+	b.rerun.Store(true)
+
+	for b.rerun.Load() {
+		if !b.dispatching.TryLock() {
+			return dispatched, nil
+		}
+
+		for b.rerun.Swap(false) {
+			n, err := b.dispatchPending()
+			dispatched += n
+			if err != nil {
+				b.dispatching.Unlock()
+				return dispatched, err
+			}
+		}
+
+		b.dispatching.Unlock()
+	}
+
+	return dispatched, nil
+}
+
+// Run dispatches the events left in the outbox periodically until the stop channel is closed.
+// Dispatched events older than the retention period are removed from the outbox on the way.
+func (b *bus) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(relayInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := b.Dispatch(); err != nil {
+			b.logger.Errorf("failed to dispatch events: %v", err)
+		}
+		if _, err := b.outboxRepository.DeleteDispatchedEvents(time.Now().Add(-retention)); err != nil {
+			b.logger.Errorf("failed to delete dispatched events: %v", err)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatchPending dispatches the pending events of the outbox in batches and returns the number of dispatched events.
+func (b *bus) dispatchPending() (int, error) {
+	log := b.logger
+	outboxRepository := b.outboxRepository
+
+	dispatched := 0
+	for {
+		pending, err := outboxRepository.ClaimPendingEvents(time.Now(), batchSize, claimLease)
+		if err != nil {
+			log.Errorf("failed to claim pending events: %v", err)
+			return dispatched, err
+		}
+
+		progress := false
+		for i := range pending {
+			row := &pending[i]
+			event, err := b.dispatchEvent(row)
+			if err := outboxRepository.UpdateEvent(row); err != nil {
+				log.Errorf("failed to update outbox event %d: %v", row.ID, err)
+				return dispatched, err
+			}
+			if row.Status != repository.OutboxPending {
+				progress = true
+			}
+			if err == nil {
+				dispatched++
+				b.startAsync(event)
+			}
+		}
+
+		// Events failing again are left for the next dispatch
+		if len(pending) < batchSize || !progress {
+			return dispatched, nil
+		}
+	}
+}
+
+// dispatchEvent decodes an outbox event and calls its synchronous handlers. The outcome is recorded in the outbox event.
+func (b *bus) dispatchEvent(row *repository.OutboxEvent) (types.DomainEvent, error) {
+	log := b.logger
+
+	row.Attempts++
+
+	event, err := types.DecodeEvent(row.Name, []byte(row.Payload))
+	if err != nil {
+		log.Errorf("failed to decode outbox event %d: %v", row.ID, err)
+		row.Status = repository.OutboxFailed
+		row.Error = err.Error()
+		return nil, err
+	}
+
+	b.lock.RLock()
+	handlers := b.sync[row.Name]
+	b.lock.RUnlock()
+
+	for _, handler := range handlers {
+		if err := handler(event); err != nil {
+			row.Error = err.Error()
+			if row.Attempts >= maxAttempts {
+				row.Status = repository.OutboxFailed
+				log.Errorf("giving up event %d (%s) after %d attempts: %v", row.ID, row.Name, row.Attempts, err)
+			} else {
+				log.Warnf("failed to handle event %d (%s), retrying later: %v", row.ID, row.Name, err)
+			}
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	row.Status = repository.OutboxDispatched
+	row.Error = ""
+	row.DispatchedAt = &now
+	log.Debugf("dispatched event %d (%s)", row.ID, row.Name)
+	return event, nil
+}
+
+// startAsync starts the asynchronous handlers of a dispatched event.
+func (b *bus) startAsync(event types.DomainEvent) {
+	b.lock.RLock()
+	handlers := b.async[event.EventName()]
+	b.lock.RUnlock()
+
+	for _, handler := range handlers {
+		go func(handler Handler) {
+			if err := handler(event); err != nil {
+				b.logger.Warnf("asynchronous handler of event %s failed: %v", event.EventName(), err)
+			}
+		}(handler)
+	}
+}
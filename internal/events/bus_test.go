@@ -0,0 +1,164 @@
+package events_test
+
+import (
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/events"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/types"
+	"testing"
+	"time"
+)
+
+// busTestContext contains objects relevant for testing the event Bus.
+type busTestContext struct {
+	mockOutboxRepository *mocks.MockOutboxRepository
+	sut                  events.Bus
+}
+
+// createBusContext creates the context for testing the event Bus and reduces code duplication.
+func createBusContext(t *testing.T) *busTestContext {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	mockOutboxRepository := mocks.NewMockOutboxRepository(mockCtrl)
+	sut := events.CreateBus(logger.CreateLogger(), mockOutboxRepository)
+
+	return &busTestContext{mockOutboxRepository, sut}
+}
+
+// createOutboxEvent creates a pending outbox event of a new post for testing purposes.
+func createOutboxEvent() repository.OutboxEvent {
+	return repository.OutboxEvent{
+		ID:      1,
+		Name:    types.EventPostCreated,
+		Payload: `{"post":{"urlHandle":"testUrlHandle"}}`,
+		Status:  repository.OutboxPending,
+	}
+}
+
+// TestBus_Dispatch tests dispatching a pending event to its synchronous and asynchronous subscribers.
+func TestBus_Dispatch(t *testing.T) {
+	t.Parallel()
+	c := createBusContext(t)
+
+	var received []types.DomainEvent
+	c.sut.Subscribe(types.EventPostCreated, func(event types.DomainEvent) error {
+		received = append(received, event)
+		return nil
+	})
+	c.sut.Subscribe(types.EventUserCreated, func(event types.DomainEvent) error {
+		t.Error("handlers of other events shouldn't be called")
+		return nil
+	})
+
+	async := make(chan types.DomainEvent, 1)
+	c.sut.SubscribeAsync(types.EventPostCreated, func(event types.DomainEvent) error {
+		async <- event
+		return nil
+	})
+
+	expectedEvent := types.PostCreatedEvent{Post: types.Post{URLHandle: "testUrlHandle"}}
+
+	c.mockOutboxRepository.EXPECT().ClaimPendingEvents(gomock.Any(), gomock.Any(), gomock.Any()).Return([]repository.OutboxEvent{createOutboxEvent()}, nil)
+	c.mockOutboxRepository.EXPECT().UpdateEvent(gomock.Any()).DoAndReturn(func(event *repository.OutboxEvent) error {
+		assert.Equal(t, repository.OutboxDispatched, event.Status, "event should be dispatched")
+		assert.Equal(t, 1, event.Attempts, "incorrect number of attempts")
+		assert.NotNil(t, event.DispatchedAt, "dispatch time should be set")
+		return nil
+	})
+
+	n, err := c.sut.Dispatch()
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 1, n, "incorrect number of dispatched events")
+	assert.Equal(t, []types.DomainEvent{expectedEvent}, received, "synchronous handler should receive the event")
+
+	select {
+	case event := <-async:
+		assert.Equal(t, expectedEvent, event, "asynchronous handler should receive the event")
+	case <-time.After(time.Second):
+		t.Error("asynchronous handler wasn't called")
+	}
+}
+
+// TestBus_Dispatch_Handler_Error tests leaving an event in the outbox if a synchronous subscriber fails.
+func TestBus_Dispatch_Handler_Error(t *testing.T) {
+	t.Parallel()
+	c := createBusContext(t)
+
+	c.sut.Subscribe(types.EventPostCreated, func(event types.DomainEvent) error {
+		return fmt.Errorf("unexpected error")
+	})
+	c.sut.SubscribeAsync(types.EventPostCreated, func(event types.DomainEvent) error {
+		t.Error("asynchronous handlers shouldn't be called before the event is dispatched")
+		return nil
+	})
+
+	c.mockOutboxRepository.EXPECT().ClaimPendingEvents(gomock.Any(), gomock.Any(), gomock.Any()).Return([]repository.OutboxEvent{createOutboxEvent()}, nil)
+	c.mockOutboxRepository.EXPECT().UpdateEvent(gomock.Any()).DoAndReturn(func(event *repository.OutboxEvent) error {
+		assert.Equal(t, repository.OutboxPending, event.Status, "event should stay pending")
+		assert.Equal(t, 1, event.Attempts, "incorrect number of attempts")
+		assert.Equal(t, "unexpected error", event.Error, "error should be recorded")
+		return nil
+	})
+
+	n, err := c.sut.Dispatch()
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 0, n, "no events should be dispatched")
+}
+
+// TestBus_Dispatch_Unknown_Event tests marking an event which can't be decoded as failed.
+func TestBus_Dispatch_Unknown_Event(t *testing.T) {
+	t.Parallel()
+	c := createBusContext(t)
+
+	event := createOutboxEvent()
+	event.Name = "post.published"
+
+	c.mockOutboxRepository.EXPECT().ClaimPendingEvents(gomock.Any(), gomock.Any(), gomock.Any()).Return([]repository.OutboxEvent{event}, nil)
+	c.mockOutboxRepository.EXPECT().UpdateEvent(gomock.Any()).DoAndReturn(func(event *repository.OutboxEvent) error {
+		assert.Equal(t, repository.OutboxFailed, event.Status, "event should fail")
+		return nil
+	})
+
+	n, err := c.sut.Dispatch()
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 0, n, "no events should be dispatched")
+}
+
+// TestBus_Dispatch_Unexpected_Error tests handling an error while reading the outbox.
+func TestBus_Dispatch_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createBusContext(t)
+
+	expectedError := fmt.Errorf("unexpected error")
+
+	c.mockOutboxRepository.EXPECT().ClaimPendingEvents(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, expectedError)
+
+	_, err := c.sut.Dispatch()
+
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+}
+
+// TestBus_Run tests dispatching the pending events and removing the old dispatched ones when the relay runs.
+func TestBus_Run(t *testing.T) {
+	t.Parallel()
+	c := createBusContext(t)
+
+	stop := make(chan struct{})
+	close(stop)
+
+	c.mockOutboxRepository.EXPECT().ClaimPendingEvents(gomock.Any(), gomock.Any(), gomock.Any()).Return([]repository.OutboxEvent{}, nil)
+	c.mockOutboxRepository.EXPECT().DeleteDispatchedEvents(gomock.Any()).DoAndReturn(func(before time.Time) (int64, error) {
+		assert.True(t, before.Before(time.Now().Add(-time.Hour)), "recently dispatched events should be kept")
+		return 1, nil
+	})
+
+	c.sut.Run(stop)
+}
@@ -0,0 +1,83 @@
+package frontmatter
+
+import (
+	"bytes"
+	"errors"
+	"gopkg.in/yaml.v3"
+	"strings"
+	"time"
+)
+
+// delimiter separates the front matter from the content of a document.
+const delimiter = "---"
+
+// ErrMissingFrontMatter is returned if a document doesn't start with a front matter block.
+var ErrMissingFrontMatter = errors.New("document doesn't start with a front matter block")
+
+// ErrUnterminatedFrontMatter is returned if the front matter block of a document isn't closed.
+var ErrUnterminatedFrontMatter = errors.New("front matter block isn't terminated")
+
+// Metadata is the YAML front matter of a Markdown post.
+// The language and the visibility are only written if they differ from the defaults, the password hash only for
+// password-protected posts. The cover image is only written if the post has one, documents without it keep the current one.
+type Metadata struct {
+	Title        string    `yaml:"title"`
+	Slug         string    `yaml:"slug"`
+	Summary      string    `yaml:"summary,omitempty"`
+	Date         time.Time `yaml:"date,omitempty"`
+	Author       string    `yaml:"author,omitempty"`
+	Tags         []string  `yaml:"tags,omitempty"`
+	CoverImage   *string   `yaml:"coverImage,omitempty"`
+	Language     string    `yaml:"language,omitempty"`
+	Visibility   string    `yaml:"visibility,omitempty"`
+	PasswordHash string    `yaml:"passwordHash,omitempty"`
+}
+
+// Parse splits a Markdown document into its front matter and its content.
+// The front matter must be the first block of the document, enclosed in lines consisting of three dashes.
+func Parse(data []byte) (Metadata, string, error) {
+	document := strings.ReplaceAll(string(data), "\r\n", "\n")
+	document = strings.TrimPrefix(document, "\ufeff")
+
+	rest, found := strings.CutPrefix(document, delimiter+"\n")
+	if !found {
+		return Metadata{}, "", ErrMissingFrontMatter
+	}
+
+	// The leading line break allows the block to be closed right away
+	header, body, found := strings.Cut("\n"+rest, "\n"+delimiter+"\n")
+	if !found {
+		if header, found = strings.CutSuffix("\n"+rest, "\n"+delimiter); !found {
+			return Metadata{}, "", ErrUnterminatedFrontMatter
+		}
+	}
+
+	var metadata Metadata
+	if err := yaml.Unmarshal([]byte(header), &metadata); err != nil {
+		return Metadata{}, "", err
+	}
+
+	return metadata, strings.Trim(body, "\n"), nil
+}
+
+// Format writes the metadata as a front matter block followed by the content.
+func Format(metadata Metadata, body string) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(delimiter + "\n")
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(metadata); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	buf.WriteString(delimiter + "\n")
+	if body = strings.Trim(body, "\n"); body != "" {
+		buf.WriteString("\n" + body + "\n")
+	}
+
+	return buf.Bytes(), nil
+}
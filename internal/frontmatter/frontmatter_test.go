@@ -0,0 +1,113 @@
+package frontmatter_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/frontmatter"
+	"testing"
+	"time"
+)
+
+// TestParse tests splitting a Markdown document into its front matter and content.
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	document := "---\r\n" +
+		"title: \"Hello: world\"\r\n" +
+		"slug: hello-world\r\n" +
+		"date: 2023-05-17\r\n" +
+		"author: TEST\r\n" +
+		"tags: [go, blog]\r\n" +
+		"---\r\n" +
+		"\r\n" +
+		"# Hello\r\n" +
+		"\r\n" +
+		"--- not a delimiter\r\n"
+
+	metadata, body, err := frontmatter.Parse([]byte(document))
+
+	expectedMetadata := frontmatter.Metadata{
+		Title:  "Hello: world",
+		Slug:   "hello-world",
+		Date:   time.Date(2023, 5, 17, 0, 0, 0, 0, time.UTC),
+		Author: "TEST",
+		Tags:   []string{"go", "blog"},
+	}
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, expectedMetadata, metadata, "metadata doesn't match")
+	assert.Equal(t, "# Hello\n\n--- not a delimiter", body, "body doesn't match")
+}
+
+// TestParse_Empty_Front_Matter tests parsing a document with an empty front matter block.
+func TestParse_Empty_Front_Matter(t *testing.T) {
+	t.Parallel()
+
+	metadata, body, err := frontmatter.Parse([]byte("---\n---\nbody"))
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, frontmatter.Metadata{}, metadata, "metadata should be empty")
+	assert.Equal(t, "body", body, "body doesn't match")
+}
+
+// TestParse_Missing_Front_Matter tests parsing a document without a front matter block.
+func TestParse_Missing_Front_Matter(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := frontmatter.Parse([]byte("# Hello"))
+
+	assert.Equal(t, frontmatter.ErrMissingFrontMatter, err, "error doesn't match expected one")
+}
+
+// TestParse_Unterminated_Front_Matter tests parsing a document whose front matter block isn't closed.
+func TestParse_Unterminated_Front_Matter(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := frontmatter.Parse([]byte("---\ntitle: test\n# Hello"))
+
+	assert.Equal(t, frontmatter.ErrUnterminatedFrontMatter, err, "error doesn't match expected one")
+}
+
+// TestParse_Invalid_YAML tests parsing a document with a malformed front matter block.
+func TestParse_Invalid_YAML(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := frontmatter.Parse([]byte("---\ntags: [go\n---\n"))
+
+	assert.NotNil(t, err, "should return an error")
+}
+
+// TestFormat tests writing a document and parsing it back.
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	metadata := frontmatter.Metadata{
+		Title:   "Hello: world",
+		Slug:    "hello-world",
+		Summary: "Multi\nline",
+		Date:    time.Date(2023, 5, 17, 8, 30, 0, 0, time.UTC),
+		Author:  "TEST",
+		Tags:    []string{"go"},
+	}
+
+	document, err := frontmatter.Format(metadata, "# Hello\n")
+	assert.Nil(t, err, "should complete without error")
+
+	expected := "---\n" +
+		"title: 'Hello: world'\n" +
+		"slug: hello-world\n" +
+		"summary: |-\n" +
+		"  Multi\n" +
+		"  line\n" +
+		"date: 2023-05-17T08:30:00Z\n" +
+		"author: TEST\n" +
+		"tags:\n" +
+		"  - go\n" +
+		"---\n" +
+		"\n" +
+		"# Hello\n"
+	assert.Equal(t, expected, string(document), "document doesn't match")
+
+	parsedMetadata, body, err := frontmatter.Parse(document)
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, metadata, parsedMetadata, "metadata should survive a round trip")
+	assert.Equal(t, "# Hello", body, "body should survive a round trip")
+}
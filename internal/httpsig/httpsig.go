@@ -0,0 +1,232 @@
+package httpsig
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// algorithm is the identifier of the signing algorithm.
+const algorithm = "rsa-sha256"
+
+// requestTarget is the pseudo-header combining the method and the path of the request.
+const requestTarget = "(request-target)"
+
+// keySize is the size of the generated RSA keys in bits.
+const keySize = 2048
+
+// Signature contains the parameters of the Signature header of a request.
+type Signature struct {
+	KeyID     string
+	Algorithm string
+	Headers   []string
+	Signature []byte
+}
+
+// GenerateKey generates a new RSA key for signing requests.
+func GenerateKey() (*rsa.PrivateKey, error) {
+	return rsa.GenerateKey(rand.Reader, keySize)
+}
+
+// Digest returns the value of the Digest header of a request body.
+func Digest(body []byte) string {
+	hash := sha256.Sum256(body)
+	return "SHA-256=" + base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// Sign adds the Date, Digest and Signature headers to the request, as described by the HTTP Signatures draft
+// used by ActivityPub servers. The signature covers the request target, the host and the date,
+// and the digest of the body if the request has one.
+func Sign(req *http.Request, keyID string, key *rsa.PrivateKey, body []byte, now time.Time) error {
+	req.Header.Set("Date", now.UTC().Format(http.TimeFormat))
+
+	headers := []string{requestTarget, "host", "date"}
+	if body != nil {
+		req.Header.Set("Digest", Digest(body))
+		headers = append(headers, "digest")
+	}
+
+	hash := sha256.Sum256([]byte(signingString(req, headers)))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Signature", fmt.Sprintf(`keyId="%s",algorithm="%s",headers="%s",signature="%s"`,
+		keyID, algorithm, strings.Join(headers, " "), base64.StdEncoding.EncodeToString(signature)))
+	return nil
+}
+
+// Parse extracts the parameters of the Signature header of a request.
+func Parse(req *http.Request) (Signature, error) {
+	header := req.Header.Get("Signature")
+	if header == "" {
+		return Signature{}, fmt.Errorf("missing signature")
+	}
+
+	params := map[string]string{}
+	for _, param := range splitParams(header) {
+		name, value, found := strings.Cut(param, "=")
+		if !found {
+			return Signature{}, fmt.Errorf("malformed signature parameter: %s", param)
+		}
+		params[strings.TrimSpace(name)] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(params["signature"])
+	if err != nil || len(signature) == 0 {
+		return Signature{}, fmt.Errorf("malformed signature")
+	}
+	if params["keyId"] == "" {
+		return Signature{}, fmt.Errorf("missing key ID")
+	}
+
+	// The date is the only header signed by default
+	headers := []string{"date"}
+	if params["headers"] != "" {
+		headers = strings.Fields(strings.ToLower(params["headers"]))
+	}
+
+	return Signature{
+		KeyID:     params["keyId"],
+		Algorithm: params["algorithm"],
+		Headers:   headers,
+		Signature: signature,
+	}, nil
+}
+
+// Verify checks the signature of a request using the public key of the sender.
+// The signature must cover the request target, the host and the date, which mustn't differ from the current time
+// by more than the given skew, and the digest of the body, which has to match the received body.
+func Verify(req *http.Request, signature Signature, key *rsa.PublicKey, body []byte, now time.Time, skew time.Duration) error {
+	if signature.Algorithm != "" && signature.Algorithm != algorithm && signature.Algorithm != "hs2019" {
+		return fmt.Errorf("unsupported algorithm: %s", signature.Algorithm)
+	}
+
+	required := []string{requestTarget, "host", "date"}
+	if body != nil {
+		required = append(required, "digest")
+	}
+	for _, header := range required {
+		if !contains(signature.Headers, header) {
+			return fmt.Errorf("%s isn't signed", header)
+		}
+	}
+
+	date, err := http.ParseTime(req.Header.Get("Date"))
+	if err != nil {
+		return fmt.Errorf("invalid date: %v", err)
+	}
+	if d := now.Sub(date); d > skew || d < -skew {
+		return fmt.Errorf("date is outside of the accepted range")
+	}
+
+	if body != nil && req.Header.Get("Digest") != Digest(body) {
+		return fmt.Errorf("digest doesn't match the body")
+	}
+
+	hash := sha256.Sum256([]byte(signingString(req, signature.Headers)))
+	return rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature.Signature)
+}
+
+// EncodePublicKey encodes a public key in the PEM format published in the ActivityPub actor documents.
+func EncodePublicKey(key *rsa.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+// ParsePublicKey parses a PEM encoded RSA public key.
+func ParsePublicKey(encoded string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(encoded))
+	if block == nil {
+		return nil, fmt.Errorf("invalid PEM block")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		// Some servers publish their keys in the PKCS #1 format
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported key type: %T", key)
+	}
+	return rsaKey, nil
+}
+
+// EncodePrivateKey encodes a private key in the PEM format.
+func EncodePrivateKey(key *rsa.PrivateKey) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+}
+
+// ParsePrivateKey parses a PEM encoded RSA private key.
+func ParsePrivateKey(encoded string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(encoded))
+	if block == nil {
+		return nil, fmt.Errorf("invalid PEM block")
+	}
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
+
+// signingString creates the string covered by the signature from the given headers of the request.
+func signingString(req *http.Request, headers []string) string {
+	lines := make([]string, 0, len(headers))
+	for _, header := range headers {
+		switch header {
+		case requestTarget:
+			lines = append(lines, fmt.Sprintf("%s: %s %s", requestTarget, strings.ToLower(req.Method), req.URL.RequestURI()))
+		case "host":
+			lines = append(lines, "host: "+host(req))
+		default:
+			lines = append(lines, fmt.Sprintf("%s: %s", header, strings.Join(req.Header.Values(header), ", ")))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// host returns the host of the request. The Host header isn't part of the header map of Go requests.
+func host(req *http.Request) string {
+	if req.Host != "" {
+		return req.Host
+	}
+	return req.URL.Host
+}
+
+// splitParams splits the parameters of a Signature header, ignoring the commas of quoted values.
+func splitParams(header string) []string {
+	var params []string
+	quoted := false
+	start := 0
+	for i, c := range header {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == ',' && !quoted:
+			params = append(params, header[start:i])
+			start = i + 1
+		}
+	}
+	return append(params, header[start:])
+}
+
+// contains checks whether the list contains the given value.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package httpsig_test
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/httpsig"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// key is shared by the tests, generating RSA keys is slow.
+var key, _ = httpsig.GenerateKey()
+
+// now is the time of the signed test requests.
+var now = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// createSignedRequest creates a POST request signed by the test key.
+func createSignedRequest(t *testing.T, body []byte) *http.Request {
+	t.Helper()
+
+	req, _ := http.NewRequest(http.MethodPost, "https://blog.example.com/actors/testAuthor/inbox", bytes.NewReader(body))
+	if err := httpsig.Sign(req, "https://remote.example.com/users/alice#main-key", key, body, now); err != nil {
+		t.Fatalf("failed to sign request: %v", err)
+	}
+	return req
+}
+
+// TestSign_Verify tests verifying the signature of a signed request.
+func TestSign_Verify(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`{"type":"Follow"}`)
+	req := createSignedRequest(t, body)
+
+	signature, err := httpsig.Parse(req)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "https://remote.example.com/users/alice#main-key", signature.KeyID, "incorrect key ID")
+	assert.Equal(t, []string{"(request-target)", "host", "date", "digest"}, signature.Headers, "incorrect signed headers")
+	assert.Nil(t, httpsig.Verify(req, signature, &key.PublicKey, body, now.Add(time.Minute), time.Hour), "signature should be valid")
+}
+
+// TestVerify_Invalid tests rejecting requests whose signature doesn't match.
+func TestVerify_Invalid(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`{"type":"Follow"}`)
+	otherKey, _ := httpsig.GenerateKey()
+
+	cases := map[string]struct {
+		body []byte
+		key  *rsa.PublicKey
+		time time.Time
+		edit func(req *http.Request)
+	}{
+		"tampered body":  {body: []byte(`{"type":"Undo"}`), key: &key.PublicKey, time: now},
+		"other key":      {body: body, key: &otherKey.PublicKey, time: now},
+		"stale date":     {body: body, key: &key.PublicKey, time: now.Add(2 * time.Hour)},
+		"other path":     {body: body, key: &key.PublicKey, time: now, edit: func(req *http.Request) { req.URL.Path = "/actors/other/inbox" }},
+		"replaced host":  {body: body, key: &key.PublicKey, time: now, edit: func(req *http.Request) { req.Host = "other.example.com" }},
+		"replaced date":  {body: body, key: &key.PublicKey, time: now, edit: func(req *http.Request) { req.Header.Set("Date", now.Add(time.Minute).Format(http.TimeFormat)) }},
+		"missing digest": {body: body, key: &key.PublicKey, time: now, edit: func(req *http.Request) { req.Header.Del("Digest") }},
+	}
+
+	for name, c := range cases {
+		req := createSignedRequest(t, body)
+		if c.edit != nil {
+			c.edit(req)
+		}
+
+		signature, _ := httpsig.Parse(req)
+		err := httpsig.Verify(req, signature, c.key, c.body, c.time, time.Hour)
+
+		assert.NotNil(t, err, "%s should lead to error", name)
+	}
+}
+
+// TestParse_Invalid tests parsing malformed Signature headers.
+func TestParse_Invalid(t *testing.T) {
+	t.Parallel()
+
+	headers := []string{
+		"",
+		`keyId="https://remote.example.com/users/alice#main-key"`,
+		`signature="c2lnbmF0dXJl"`,
+		`keyId="https://remote.example.com/users/alice#main-key",signature="not base64"`,
+	}
+
+	for _, header := range headers {
+		req, _ := http.NewRequest(http.MethodPost, "https://blog.example.com/inbox", nil)
+		req.Header.Set("Signature", header)
+
+		_, err := httpsig.Parse(req)
+
+		assert.NotNil(t, err, "%q should lead to error", header)
+	}
+}
+
+// TestEncodePublicKey tests encoding and parsing the public key published in actor documents.
+func TestEncodePublicKey(t *testing.T) {
+	t.Parallel()
+
+	encoded, err := httpsig.EncodePublicKey(&key.PublicKey)
+	assert.Nil(t, err, "should complete without error")
+
+	parsed, err := httpsig.ParsePublicKey(encoded)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.True(t, key.PublicKey.Equal(parsed), "parsed key should match the encoded one")
+}
+
+// TestEncodePrivateKey tests encoding and parsing the stored private keys.
+func TestEncodePrivateKey(t *testing.T) {
+	t.Parallel()
+
+	parsed, err := httpsig.ParsePrivateKey(httpsig.EncodePrivateKey(key))
+
+	assert.Nil(t, err, "should complete without error")
+	assert.True(t, key.Equal(parsed), "parsed key should match the encoded one")
+}
@@ -0,0 +1,105 @@
+package i18n
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLanguage is the language of posts created without an explicit language tag.
+const DefaultLanguage = "en"
+
+// tagPattern matches the simplified BCP 47 language tags supported by the blog, e.g. "en", "de" or "de-AT".
+var tagPattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// NormalizeTag validates a language tag and converts it to its canonical form.
+// The primary language subtag is lowercased, two-letter region subtags are uppercased.
+func NormalizeTag(tag string) (string, bool) {
+	tag = strings.TrimSpace(strings.ReplaceAll(tag, "_", "-"))
+	if !tagPattern.MatchString(tag) {
+		return "", false
+	}
+
+	parts := strings.Split(tag, "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		if len(parts[i]) == 2 {
+			parts[i] = strings.ToUpper(parts[i])
+		} else {
+			parts[i] = strings.ToLower(parts[i])
+		}
+	}
+
+	return strings.Join(parts, "-"), true
+}
+
+// ParseAcceptLanguage parses the value of an Accept-Language header.
+// The normalized language tags are returned in the order of preference, invalid tags and wildcards are skipped.
+func ParseAcceptLanguage(header string) []string {
+	type weightedTag struct {
+		tag    string
+		weight float64
+	}
+
+	var tags []weightedTag
+	for _, item := range strings.Split(header, ",") {
+		fields := strings.Split(item, ";")
+
+		tag, ok := NormalizeTag(fields[0])
+		if !ok {
+			continue
+		}
+
+		weight := 1.0
+		for _, param := range fields[1:] {
+			if q, found := strings.CutPrefix(strings.TrimSpace(param), "q="); found {
+				if w, err := strconv.ParseFloat(q, 64); err == nil {
+					weight = w
+				}
+			}
+		}
+
+		if weight > 0 {
+			tags = append(tags, weightedTag{tag, weight})
+		}
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].weight > tags[j].weight
+	})
+
+	result := make([]string, 0, len(tags))
+	for _, t := range tags {
+		result = append(result, t.tag)
+	}
+
+	return result
+}
+
+// Match selects the best available language for the given preferences.
+// Exact matches are preferred, otherwise a preference matches every available tag sharing its primary language.
+// The second return value is false if none of the preferences can be satisfied.
+func Match(preferences []string, available []string) (string, bool) {
+	for _, preference := range preferences {
+		for _, tag := range available {
+			if strings.EqualFold(preference, tag) {
+				return tag, true
+			}
+		}
+
+		for _, tag := range available {
+			if strings.EqualFold(primaryLanguage(preference), primaryLanguage(tag)) {
+				return tag, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// primaryLanguage returns the primary language subtag of a language tag.
+func primaryLanguage(tag string) string {
+	primary, _, _ := strings.Cut(tag, "-")
+	return primary
+}
@@ -0,0 +1,76 @@
+package i18n_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/i18n"
+	"testing"
+)
+
+// TestNormalizeTag tests the validation and normalization of language tags.
+func TestNormalizeTag(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"en":      "en",
+		"DE":      "de",
+		"de-at":   "de-AT",
+		"de_AT":   "de-AT",
+		"zh-HANT": "zh-hant",
+	}
+
+	for in, expected := range cases {
+		tag, ok := i18n.NormalizeTag(in)
+		assert.True(t, ok, "tag %s should be valid", in)
+		assert.Equal(t, expected, tag, "tag %s isn't normalized correctly", in)
+	}
+}
+
+// TestNormalizeTag_Invalid tests rejecting invalid language tags.
+func TestNormalizeTag_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, in := range []string{"", "*", "e", "english", "de-", "de-AT-toolongsubtag"} {
+		_, ok := i18n.NormalizeTag(in)
+		assert.False(t, ok, "tag %s should be invalid", in)
+	}
+}
+
+// TestParseAcceptLanguage tests ordering the languages of an Accept-Language header by their weights.
+func TestParseAcceptLanguage(t *testing.T) {
+	t.Parallel()
+
+	tags := i18n.ParseAcceptLanguage("en;q=0.5, de-AT, *;q=0.1, fr;q=0, de;q=0.8")
+
+	assert.Equal(t, []string{"de-AT", "de", "en"}, tags, "languages aren't ordered by preference")
+}
+
+// TestParseAcceptLanguage_Empty tests parsing an empty Accept-Language header.
+func TestParseAcceptLanguage_Empty(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{}, i18n.ParseAcceptLanguage(""), "no languages should be returned")
+}
+
+// TestMatch tests selecting the best available language.
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	available := []string{"en", "de"}
+
+	tag, ok := i18n.Match([]string{"fr", "de-AT", "en"}, available)
+	assert.True(t, ok, "a language should be matched")
+	assert.Equal(t, "de", tag, "region variants should match the primary language")
+
+	tag, ok = i18n.Match([]string{"de", "en"}, []string{"en", "de-DE", "de"})
+	assert.True(t, ok, "a language should be matched")
+	assert.Equal(t, "de", tag, "exact matches should be preferred")
+}
+
+// TestMatch_No_Match tests matching preferences which can't be satisfied.
+func TestMatch_No_Match(t *testing.T) {
+	t.Parallel()
+
+	_, ok := i18n.Match([]string{"fr"}, []string{"en", "de"})
+
+	assert.False(t, ok, "no language should be matched")
+}
@@ -0,0 +1,212 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/webp"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+)
+
+// ErrUnsupportedImage is returned for images which can be stored, but not decoded or re-encoded, e.g. animated GIF or WebP images.
+var ErrUnsupportedImage = errors.New("image format can't be processed")
+
+// ErrInvalidImage is returned for images with a malformed structure.
+var ErrInvalidImage = errors.New("malformed image")
+
+// jpegQuality is the quality of re-encoded JPEG images.
+const jpegQuality = 85
+
+// Variant describes a responsive image variant generated from the uploaded images.
+type Variant struct {
+	Name  string
+	Width int
+}
+
+// Variants lists the responsive image variants ordered by their width.
+var Variants = []Variant{
+	{Name: "thumbnail", Width: 320},
+	{Name: "medium", Width: 768},
+	{Name: "large", Width: 1600},
+}
+
+// FindVariant looks up a responsive image variant by its name.
+func FindVariant(name string) (Variant, bool) {
+	for _, v := range Variants {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return Variant{}, false
+}
+
+// CanResize checks whether responsive variants can be generated from images of the given media type.
+func CanResize(contentType string) bool {
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return true
+	default:
+		return false
+	}
+}
+
+// Decode decodes a JPEG, PNG, GIF or WebP image. The EXIF orientation of JPEG images is applied to the decoded image.
+// ErrUnsupportedImage is returned for other media types and for animated GIF and WebP images.
+func Decode(data []byte, contentType string) (image.Image, error) {
+	switch contentType {
+	case "image/jpeg":
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return applyOrientation(img, Orientation(data)), nil
+
+	case "image/png":
+		return png.Decode(bytes.NewReader(data))
+
+	case "image/gif":
+		g, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		if len(g.Image) != 1 {
+			return nil, ErrUnsupportedImage
+		}
+		return g.Image[0], nil
+
+	case "image/webp":
+		if webpAnimated(data) {
+			return nil, ErrUnsupportedImage
+		}
+		return webp.Decode(bytes.NewReader(data))
+
+	default:
+		return nil, ErrUnsupportedImage
+	}
+}
+
+// Encode encodes an image derived from a source image of the given media type.
+// JPEG images are encoded as JPEG, PNG and GIF images as PNG to keep their transparency without palette limitations.
+// WebP images are encoded as lossless WebP, since there is no lossy WebP encoder written in Go.
+// The media type of the encoded image is returned along with its content.
+func Encode(img image.Image, sourceType string) ([]byte, string, error) {
+	var buf bytes.Buffer
+
+	switch sourceType {
+	case "image/jpeg":
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/jpeg", nil
+
+	case "image/png", "image/gif":
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/png", nil
+
+	case "image/webp":
+		if err := nativewebp.Encode(&buf, img, nil); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/webp", nil
+
+	default:
+		return nil, "", ErrUnsupportedImage
+	}
+}
+
+// Dimensions returns the width and height of an image without decoding the pixel data.
+// WebP headers are parsed directly, including the extended header of animated images.
+func Dimensions(data []byte, contentType string) (int, int, error) {
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return 0, 0, err
+		}
+		if contentType == "image/jpeg" && Orientation(data) >= 5 {
+			return cfg.Height, cfg.Width, nil
+		}
+		return cfg.Width, cfg.Height, nil
+
+	case "image/webp":
+		return webpDimensions(data)
+
+	default:
+		return 0, 0, ErrUnsupportedImage
+	}
+}
+
+// Resize scales an image down to the given width, keeping its aspect ratio.
+// Every destination pixel is the average of the source pixels it covers, which avoids aliasing when shrinking large photos.
+// Images not wider than the given width are returned unchanged.
+func Resize(img image.Image, width int) image.Image {
+	b := img.Bounds()
+	srcW, srcH := b.Dx(), b.Dy()
+	if width <= 0 || srcW <= width {
+		return img
+	}
+
+	height := srcH * width / srcW
+	if height < 1 {
+		height = 1
+	}
+
+	src := toRGBA(img)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0, y1 := span(y, height, srcH)
+		for x := 0; x < width; x++ {
+			x0, x1 := span(x, width, srcW)
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += uint64(p[0])
+					g += uint64(p[1])
+					bl += uint64(p[2])
+					a += uint64(p[3])
+					n++
+				}
+			}
+
+			d := dst.Pix[y*dst.Stride+x*4:]
+			d[0] = uint8(r / n)
+			d[1] = uint8(g / n)
+			d[2] = uint8(bl / n)
+			d[3] = uint8(a / n)
+		}
+	}
+
+	return dst
+}
+
+// span returns the range of source pixels covered by the i-th of n destination pixels.
+func span(i int, n int, size int) (int, int) {
+	start := i * size / n
+	end := (i + 1) * size / n
+	if end <= start {
+		end = start + 1
+	}
+	return start, end
+}
+
+// toRGBA converts an image to a zero-based RGBA image with premultiplied alpha.
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+	return rgba
+}
@@ -0,0 +1,141 @@
+package imaging_test
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/imaging"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// createTestImage creates an image with a white left and a black right half for testing purposes.
+func createTestImage(width int, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x < width/2 {
+				img.Set(x, y, color.White)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+	return img
+}
+
+// encodePNG encodes an image as PNG for testing purposes.
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	assert.Nil(t, png.Encode(&buf, img), "should complete without error")
+	return buf.Bytes()
+}
+
+// TestFindVariant tests looking up the responsive image variants by their name.
+func TestFindVariant(t *testing.T) {
+	t.Parallel()
+
+	v, ok := imaging.FindVariant("medium")
+	assert.True(t, ok, "variant should be found")
+	assert.Equal(t, 768, v.Width, "variant width doesn't match")
+
+	_, ok = imaging.FindVariant("huge")
+	assert.False(t, ok, "unknown variants shouldn't be found")
+}
+
+// TestResize tests scaling down an image while keeping its aspect ratio.
+func TestResize(t *testing.T) {
+	t.Parallel()
+
+	resized := imaging.Resize(createTestImage(400, 200), 100)
+
+	assert.Equal(t, image.Rect(0, 0, 100, 50), resized.Bounds(), "image should be scaled proportionally")
+	r, _, _, _ := resized.At(10, 10).RGBA()
+	assert.Equal(t, uint32(0xffff), r, "left half should stay white")
+	r, _, _, _ = resized.At(90, 10).RGBA()
+	assert.Equal(t, uint32(0), r, "right half should stay black")
+}
+
+// TestResize_Small_Image tests that images are never scaled up.
+func TestResize_Small_Image(t *testing.T) {
+	t.Parallel()
+
+	img := createTestImage(50, 50)
+
+	assert.Equal(t, image.Image(img), imaging.Resize(img, 100), "small images should be returned unchanged")
+}
+
+// TestDecode_Encode tests decoding an image and encoding a variant of it.
+func TestDecode_Encode(t *testing.T) {
+	t.Parallel()
+
+	img, err := imaging.Decode(encodePNG(t, createTestImage(40, 20)), "image/png")
+	assert.Nil(t, err, "should complete without error")
+
+	data, contentType, err := imaging.Encode(img, "image/gif")
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "image/png", contentType, "GIF variants should be encoded as PNG")
+
+	w, h, err := imaging.Dimensions(data, contentType)
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, []int{40, 20}, []int{w, h}, "dimensions should be preserved")
+}
+
+// TestDecode_Unsupported tests decoding images which can't be processed.
+func TestDecode_Unsupported(t *testing.T) {
+	t.Parallel()
+
+	palette := []color.Color{color.White, color.Black}
+	frames := &gif.GIF{
+		Image: []*image.Paletted{image.NewPaletted(image.Rect(0, 0, 2, 2), palette), image.NewPaletted(image.Rect(0, 0, 2, 2), palette)},
+		Delay: []int{10, 10},
+	}
+	var buf bytes.Buffer
+	_ = gif.EncodeAll(&buf, frames)
+
+	_, err := imaging.Decode(buf.Bytes(), "image/gif")
+	assert.Equal(t, imaging.ErrUnsupportedImage, err, "animated images shouldn't be processed")
+
+	// Extended header with the animation flag set
+	animated := append([]byte("RIFF\x00\x00\x00\x00WEBPVP8X\x0a\x00\x00\x00\x02\x00\x00\x00"), 1, 0, 0, 1, 0, 0)
+	_, err = imaging.Decode(animated, "image/webp")
+	assert.Equal(t, imaging.ErrUnsupportedImage, err, "animated WebP images shouldn't be processed")
+}
+
+// TestDecode_Encode_WebP tests encoding a variant of a WebP image as WebP and decoding it again.
+func TestDecode_Encode_WebP(t *testing.T) {
+	t.Parallel()
+
+	data, contentType, err := imaging.Encode(imaging.Resize(createTestImage(400, 200), 100), "image/webp")
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "image/webp", contentType, "WebP variants should be encoded as WebP")
+	assert.True(t, imaging.CanResize(contentType), "WebP images should be resizable")
+
+	img, err := imaging.Decode(data, contentType)
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, image.Rect(0, 0, 100, 50), img.Bounds(), "dimensions should be preserved")
+	r, _, _, _ := img.At(90, 10).RGBA()
+	assert.Equal(t, uint32(0), r, "pixels should be preserved")
+
+	w, h, err := imaging.Dimensions(data, contentType)
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, []int{100, 50}, []int{w, h}, "header dimensions should match")
+}
+
+// TestDimensions_JPEG tests reading the dimensions of a JPEG image.
+func TestDimensions_JPEG(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	_ = jpeg.Encode(&buf, createTestImage(64, 32), nil)
+
+	w, h, err := imaging.Dimensions(buf.Bytes(), "image/jpeg")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, []int{64, 32}, []int{w, h}, "dimensions don't match")
+}
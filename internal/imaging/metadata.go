@@ -0,0 +1,319 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+)
+
+// pngSignature is the fixed header of every PNG file.
+const pngSignature = "\x89PNG\r\n\x1a\n"
+
+// strippedPNGChunks lists the PNG chunks carrying EXIF, textual metadata or timestamps.
+var strippedPNGChunks = map[string]bool{
+	"eXIf": true,
+	"iTXt": true,
+	"tEXt": true,
+	"tIME": true,
+	"zTXt": true,
+}
+
+// StripMetadata removes EXIF (including GPS), XMP, IPTC and textual metadata from an image.
+// Images are rewritten without re-encoding, except for JPEG images with a non-default EXIF orientation:
+// these are re-encoded with the rotation applied, since the orientation would be lost along with the metadata.
+// GIF images can't carry EXIF metadata and are returned unchanged.
+func StripMetadata(data []byte, contentType string) ([]byte, error) {
+	switch contentType {
+	case "image/jpeg":
+		if Orientation(data) > 1 {
+			img, err := Decode(data, contentType)
+			if err != nil {
+				return nil, err
+			}
+			stripped, _, err := Encode(img, contentType)
+			return stripped, err
+		}
+		return stripJPEG(data)
+
+	case "image/png":
+		return stripPNG(data)
+
+	case "image/webp":
+		return stripWebP(data)
+
+	default:
+		return data, nil
+	}
+}
+
+// Orientation returns the EXIF orientation of a JPEG image, ranging from 1 to 8.
+// Images without a valid orientation tag are reported with the default orientation 1.
+func Orientation(data []byte) int {
+	exif := findExif(data)
+	if len(exif) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(exif[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(exif[4:8]))
+	if offset+2 > len(exif) {
+		return 1
+	}
+
+	entries := int(order.Uint16(exif[offset:]))
+	for i := 0; i < entries; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(exif) {
+			return 1
+		}
+		if order.Uint16(exif[entry:]) == 0x0112 {
+			o := int(order.Uint16(exif[entry+8:]))
+			if o < 1 || o > 8 {
+				return 1
+			}
+			return o
+		}
+	}
+
+	return 1
+}
+
+// findExif returns the TIFF structure of the EXIF segment of a JPEG image, or nil if there is none.
+func findExif(data []byte) []byte {
+	var exif []byte
+	_ = walkJPEG(data, func(marker byte, segment []byte) bool {
+		if marker == 0xe1 && bytes.HasPrefix(segment[4:], []byte("Exif\x00\x00")) {
+			exif = segment[10:]
+			return false
+		}
+		return true
+	})
+	return exif
+}
+
+// stripJPEG removes the APP1 (EXIF, XMP), APP13 (IPTC) and comment segments of a JPEG image.
+// The JFIF header, ICC color profiles and the Adobe color transform segment are kept.
+func stripJPEG(data []byte) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xff, 0xd8)
+
+	err := walkJPEG(data, func(marker byte, segment []byte) bool {
+		if marker != 0xe1 && marker != 0xed && marker != 0xfe {
+			out = append(out, segment...)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// walkJPEG calls the visitor with every marker segment of a JPEG image, including the marker itself.
+// The segment starting the image scan is passed along with the remaining data of the file.
+// Walking stops early if the visitor returns false.
+func walkJPEG(data []byte, visit func(marker byte, segment []byte) bool) error {
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return ErrInvalidImage
+	}
+
+	i := 2
+	for i < len(data) {
+		if data[i] != 0xff || i+1 >= len(data) {
+			return ErrInvalidImage
+		}
+		marker := data[i+1]
+
+		switch {
+		case marker == 0xff:
+			// Fill byte before a marker
+			i++
+			continue
+
+		case marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7):
+			if !visit(marker, data[i:i+2]) {
+				return nil
+			}
+			i += 2
+			continue
+
+		case marker == 0xd9:
+			visit(marker, data[i:])
+			return nil
+		}
+
+		if i+4 > len(data) {
+			return ErrInvalidImage
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end > len(data) || end < i+4 {
+			return ErrInvalidImage
+		}
+
+		if marker == 0xda {
+			visit(marker, data[i:])
+			return nil
+		}
+
+		if !visit(marker, data[i:end]) {
+			return nil
+		}
+		i = end
+	}
+
+	return ErrInvalidImage
+}
+
+// stripPNG removes the chunks carrying EXIF, textual metadata or timestamps from a PNG image.
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte(pngSignature)) {
+		return nil, ErrInvalidImage
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+
+	for i := len(pngSignature); i < len(data); {
+		if i+12 > len(data) {
+			return nil, ErrInvalidImage
+		}
+		end := i + 12 + int(binary.BigEndian.Uint32(data[i:]))
+		if end > len(data) || end < i+12 {
+			return nil, ErrInvalidImage
+		}
+
+		if !strippedPNGChunks[string(data[i+4:i+8])] {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+
+	return out, nil
+}
+
+// stripWebP removes the EXIF and XMP chunks of a WebP image and clears the corresponding feature flags.
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, ErrInvalidImage
+	}
+
+	out := make([]byte, 12, len(data))
+	copy(out, data[:12])
+
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return nil, ErrInvalidImage
+		}
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + size + size%2
+		if end > len(data) || end < i+8 {
+			return nil, ErrInvalidImage
+		}
+
+		switch string(data[i : i+4]) {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			start := len(out)
+			out = append(out, data[i:end]...)
+			if size > 0 {
+				// Clear the EXIF and XMP metadata flags
+				out[start+8] &^= 0x0c
+			}
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, nil
+}
+
+// webpDimensions parses the width and height of a WebP image from the header of its first chunk.
+func webpDimensions(data []byte) (int, int, error) {
+	if len(data) < 30 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return 0, 0, ErrInvalidImage
+	}
+
+	chunk := data[20:]
+	switch string(data[12:16]) {
+	case "VP8X":
+		width := int(chunk[4]) | int(chunk[5])<<8 | int(chunk[6])<<16
+		height := int(chunk[7]) | int(chunk[8])<<8 | int(chunk[9])<<16
+		return width + 1, height + 1, nil
+
+	case "VP8 ":
+		if chunk[3] != 0x9d || chunk[4] != 0x01 || chunk[5] != 0x2a {
+			return 0, 0, ErrInvalidImage
+		}
+		width := int(binary.LittleEndian.Uint16(chunk[6:])) & 0x3fff
+		height := int(binary.LittleEndian.Uint16(chunk[8:])) & 0x3fff
+		return width, height, nil
+
+	case "VP8L":
+		if chunk[0] != 0x2f {
+			return 0, 0, ErrInvalidImage
+		}
+		bits := binary.LittleEndian.Uint32(chunk[1:])
+		return int(bits&0x3fff) + 1, int(bits>>14&0x3fff) + 1, nil
+
+	default:
+		return 0, 0, ErrInvalidImage
+	}
+}
+
+// webpAnimated checks whether the extended header of a WebP image has the animation flag set.
+func webpAnimated(data []byte) bool {
+	return len(data) > 20 && string(data[12:16]) == "VP8X" && data[20]&0x02 != 0
+}
+
+// applyOrientation transforms an image according to its EXIF orientation, so it is displayed upright without the metadata.
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	src := toRGBA(img)
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[y*dst.Stride+x*4:y*dst.Stride+x*4+4], src.Pix[sy*src.Stride+sx*4:])
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,146 @@
+package imaging_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/imaging"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"testing"
+)
+
+// createExifSegment creates a JPEG APP1 segment containing the given EXIF orientation and a GPS marker text.
+func createExifSegment(orientation uint16) []byte {
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	tiff = binary.LittleEndian.AppendUint16(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 3)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	tiff = append(tiff, "GPS 47.4979N 19.0402E"...)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xff, 0xe1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// createJPEGWithExif creates a JPEG image of the given size carrying an EXIF segment.
+func createJPEGWithExif(t *testing.T, width int, height int, orientation uint16) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	assert.Nil(t, jpeg.Encode(&buf, createTestImage(width, height), nil), "should complete without error")
+
+	data := buf.Bytes()
+	result := append([]byte{0xff, 0xd8}, createExifSegment(orientation)...)
+	return append(result, data[2:]...)
+}
+
+// createPNGChunk creates a PNG chunk with a valid checksum.
+func createPNGChunk(chunkType string, content []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(content)))
+	chunk = append(chunk, chunkType...)
+	chunk = append(chunk, content...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+// TestOrientation tests reading the EXIF orientation of JPEG images.
+func TestOrientation(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 6, imaging.Orientation(createJPEGWithExif(t, 8, 4, 6)), "orientation doesn't match")
+	assert.Equal(t, 1, imaging.Orientation([]byte("not an image")), "invalid images should have the default orientation")
+}
+
+// TestStripMetadata_JPEG tests removing the EXIF segment of a JPEG image without re-encoding it.
+func TestStripMetadata_JPEG(t *testing.T) {
+	t.Parallel()
+
+	data := createJPEGWithExif(t, 8, 4, 1)
+
+	stripped, err := imaging.StripMetadata(data, "image/jpeg")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.False(t, bytes.Contains(stripped, []byte("Exif")), "EXIF segment should be removed")
+	assert.False(t, bytes.Contains(stripped, []byte("GPS")), "location data should be removed")
+	assert.Equal(t, len(data)-len(createExifSegment(1)), len(stripped), "only the EXIF segment should be removed")
+
+	_, err = jpeg.Decode(bytes.NewReader(stripped))
+	assert.Nil(t, err, "stripped image should stay valid")
+}
+
+// TestStripMetadata_JPEG_Orientation tests applying the EXIF orientation of a rotated JPEG image before stripping its metadata.
+func TestStripMetadata_JPEG_Orientation(t *testing.T) {
+	t.Parallel()
+
+	data := createJPEGWithExif(t, 80, 40, 6)
+
+	w, h, _ := imaging.Dimensions(data, "image/jpeg")
+	assert.Equal(t, []int{40, 80}, []int{w, h}, "dimensions should reflect the orientation")
+
+	stripped, err := imaging.StripMetadata(data, "image/jpeg")
+	assert.Nil(t, err, "should complete without error")
+	assert.False(t, bytes.Contains(stripped, []byte("GPS")), "location data should be removed")
+
+	img, _ := jpeg.Decode(bytes.NewReader(stripped))
+	assert.Equal(t, image.Rect(0, 0, 40, 80), img.Bounds(), "image should be rotated")
+
+	// The white left half ends up on top after rotating clockwise
+	r, _, _, _ := img.At(20, 10).RGBA()
+	assert.Greater(t, r, uint32(0xf000), "top half should be white")
+	r, _, _, _ = img.At(20, 70).RGBA()
+	assert.Less(t, r, uint32(0x1000), "bottom half should be black")
+}
+
+// TestStripMetadata_JPEG_Invalid tests stripping the metadata of a malformed JPEG image.
+func TestStripMetadata_JPEG_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := imaging.StripMetadata([]byte{0xff, 0xd8, 0xff, 0xe1, 0xff, 0xff}, "image/jpeg")
+
+	assert.Equal(t, imaging.ErrInvalidImage, err, "error doesn't match expected one")
+}
+
+// TestStripMetadata_PNG tests removing the metadata chunks of a PNG image.
+func TestStripMetadata_PNG(t *testing.T) {
+	t.Parallel()
+
+	data := encodePNG(t, createTestImage(4, 4))
+	// Insert metadata chunks right after the IHDR chunk
+	ihdrEnd := 8 + 25
+	withMetadata := append([]byte{}, data[:ihdrEnd]...)
+	withMetadata = append(withMetadata, createPNGChunk("eXIf", []byte("GPS"))...)
+	withMetadata = append(withMetadata, createPNGChunk("tEXt", []byte("Comment\x00secret"))...)
+	withMetadata = append(withMetadata, data[ihdrEnd:]...)
+
+	stripped, err := imaging.StripMetadata(withMetadata, "image/png")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, data, stripped, "metadata chunks should be removed")
+}
+
+// TestStripMetadata_WebP tests removing the EXIF chunk of a WebP image and clearing its feature flag.
+func TestStripMetadata_WebP(t *testing.T) {
+	t.Parallel()
+
+	vp8x := []byte("VP8X\x0a\x00\x00\x00\x08\x00\x00\x00\x3f\x00\x00\x1f\x00\x00")
+	exif := []byte("EXIF\x03\x00\x00\x00GPS\x00")
+	data := append([]byte("RIFF\x00\x00\x00\x00WEBP"), vp8x...)
+	data = append(data, exif...)
+	binary.LittleEndian.PutUint32(data[4:], uint32(len(data)-8))
+
+	stripped, err := imaging.StripMetadata(data, "image/webp")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.False(t, bytes.Contains(stripped, []byte("GPS")), "EXIF chunk should be removed")
+	assert.Equal(t, byte(0), stripped[20], "EXIF flag should be cleared")
+	assert.Equal(t, uint32(len(stripped)-8), binary.LittleEndian.Uint32(stripped[4:]), "RIFF size should be updated")
+
+	w, h, err := imaging.Dimensions(stripped, "image/webp")
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, []int{64, 32}, []int{w, h}, "dimensions don't match")
+}
@@ -11,10 +11,22 @@ import (
 // signingKey is the JWT secret key stored as an environment variable
 var signingKey = []byte(os.Getenv("JWT_SIGNING_KEY"))
 
+// postAccessTokenTTL defines how long a post access token remains valid.
+const postAccessTokenTTL = time.Hour
+
+// subscriptionTokenTTL defines how long a newsletter subscription can be confirmed.
+const subscriptionTokenTTL = 48 * time.Hour
+
 // TokenUtils interface. JWT-related utility methods.
 type TokenUtils interface {
 	ParseJWT(t string) (string, error)
 	GenerateJWT(userName string) (string, error)
+	ParsePostAccessJWT(t string) (string, error)
+	GeneratePostAccessJWT(urlHandle string) (string, error)
+	ParseSubscriptionJWT(t string) (string, error)
+	GenerateSubscriptionJWT(email string) (string, error)
+	ParseUnsubscribeJWT(t string) (string, error)
+	GenerateUnsubscribeJWT(email string) (string, error)
 }
 
 // tokenUtils struct. Placeholder receiver struct for JWT utils.
@@ -31,6 +43,81 @@ func CreateTokenUtils(logger *zap.SugaredLogger) TokenUtils {
 
 // ParseJWT parses a token and extracts the user field if valid.
 func (j tokenUtils) ParseJWT(t string) (string, error) {
+	return parseClaim(t, "user")
+}
+
+// GenerateJWT creates a JWT containing the following fields:
+// - username
+// - authorized flag
+// - expiration date
+func (j tokenUtils) GenerateJWT(userName string) (string, error) {
+	token := jwt.New(jwt.SigningMethodHS256)
+	claims := token.Claims.(jwt.MapClaims)
+
+	claims["exp"] = time.Now().Add(24 * time.Hour).Unix()
+	claims["authorized"] = true
+	claims["user"] = userName
+
+	return token.SignedString(signingKey)
+}
+
+// ParsePostAccessJWT parses a post access token and extracts the URL handle of the post it grants access to.
+func (j tokenUtils) ParsePostAccessJWT(t string) (string, error) {
+	return parseClaim(t, "post")
+}
+
+// GeneratePostAccessJWT creates a short-lived JWT granting read access to a single password-protected post.
+// The token contains the following fields:
+// - URL handle of the post
+// - expiration date
+func (j tokenUtils) GeneratePostAccessJWT(urlHandle string) (string, error) {
+	token := jwt.New(jwt.SigningMethodHS256)
+	claims := token.Claims.(jwt.MapClaims)
+
+	claims["exp"] = time.Now().Add(postAccessTokenTTL).Unix()
+	claims["post"] = urlHandle
+
+	return token.SignedString(signingKey)
+}
+
+// ParseSubscriptionJWT parses a subscription confirmation token and extracts the email address to confirm.
+func (j tokenUtils) ParseSubscriptionJWT(t string) (string, error) {
+	return parseClaim(t, "subscribe")
+}
+
+// GenerateSubscriptionJWT creates a JWT confirming the newsletter subscription of an email address.
+// The token contains the following fields:
+// - email address
+// - expiration date
+func (j tokenUtils) GenerateSubscriptionJWT(email string) (string, error) {
+	token := jwt.New(jwt.SigningMethodHS256)
+	claims := token.Claims.(jwt.MapClaims)
+
+	claims["exp"] = time.Now().Add(subscriptionTokenTTL).Unix()
+	claims["subscribe"] = email
+
+	return token.SignedString(signingKey)
+}
+
+// ParseUnsubscribeJWT parses an unsubscribe token and extracts the email address to unsubscribe.
+func (j tokenUtils) ParseUnsubscribeJWT(t string) (string, error) {
+	return parseClaim(t, "unsubscribe")
+}
+
+// GenerateUnsubscribeJWT creates a JWT for the one-click unsubscribe link of the newsletter emails.
+// The token doesn't expire, so the links of older emails keep working.
+func (j tokenUtils) GenerateUnsubscribeJWT(email string) (string, error) {
+	token := jwt.New(jwt.SigningMethodHS256)
+	claims := token.Claims.(jwt.MapClaims)
+
+	claims["unsubscribe"] = email
+
+	return token.SignedString(signingKey)
+}
+
+// parseClaim validates a token and extracts the given string claim.
+// Tokens without the claim are rejected, so tokens issued for different purposes can't be used interchangeably.
+func parseClaim(t string, claim string) (string, error) {
 	token, err := jwt.Parse(t, func(token *jwt.Token) (interface{}, error) {
 		// Don't forget to validate the alg is what you expect:
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -45,24 +132,11 @@ func (j tokenUtils) ParseJWT(t string) (string, error) {
 		return "", err
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && claims["user"] != nil {
-		return claims["user"].(string), nil
-	} else {
-		return "", fmt.Errorf("failed to get jwt claims")
+	if claims, ok := token.Claims.(jwt.MapClaims); ok && claims[claim] != nil {
+		if value, ok := claims[claim].(string); ok {
+			return value, nil
+		}
 	}
-}
 
-// GenerateJWT creates a JWT containing the following fields:
-// - username
-// - authorized flag
-// - expiration date
-func (j tokenUtils) GenerateJWT(userName string) (string, error) {
-	token := jwt.New(jwt.SigningMethodHS256)
-	claims := token.Claims.(jwt.MapClaims)
-
-	claims["exp"] = time.Now().Add(24 * time.Hour).Unix()
-	claims["authorized"] = true
-	claims["user"] = userName
-
-	return token.SignedString(signingKey)
+	return "", fmt.Errorf("failed to get jwt claims")
 }
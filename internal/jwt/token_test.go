@@ -82,3 +82,87 @@ func TestTokenUtils_ParseJWT_Invalid_Claims(t *testing.T) {
 	assert.NotNil(t, err, "invalid token should lead to error")
 	assert.Equal(t, "failed to get jwt claims", err.Error(), "incorrect error type")
 }
+
+// TestTokenUtils_ParsePostAccessJWT tests parsing a valid post access token
+func TestTokenUtils_ParsePostAccessJWT(t *testing.T) {
+	t.Parallel()
+	c := createTokenUtilsContext(t)
+
+	expectedURLHandle := "testHandle"
+
+	token, err := c.sut.GeneratePostAccessJWT(expectedURLHandle)
+	assert.Nil(t, err, "expected to complete without error")
+
+	urlHandle, err := c.sut.ParsePostAccessJWT(token)
+
+	assert.Nil(t, err, "expected to complete without error")
+	assert.Equal(t, expectedURLHandle, urlHandle, "resolved URL handle doesn't match the expected value")
+}
+
+// TestTokenUtils_ParsePostAccessJWT_User_Token tests that an authentication token can't be used as post access token
+func TestTokenUtils_ParsePostAccessJWT_User_Token(t *testing.T) {
+	t.Parallel()
+	c := createTokenUtilsContext(t)
+
+	token, _ := c.sut.GenerateJWT("TestAuthor")
+	_, err := c.sut.ParsePostAccessJWT(token)
+
+	assert.NotNil(t, err, "authentication token should lead to error")
+	assert.Equal(t, "failed to get jwt claims", err.Error(), "incorrect error type")
+}
+
+// TestTokenUtils_ParseJWT_Post_Access_Token tests that a post access token can't be used for authentication
+func TestTokenUtils_ParseJWT_Post_Access_Token(t *testing.T) {
+	t.Parallel()
+	c := createTokenUtilsContext(t)
+
+	token, _ := c.sut.GeneratePostAccessJWT("testHandle")
+	_, err := c.sut.ParseJWT(token)
+
+	assert.NotNil(t, err, "post access token should lead to error")
+	assert.Equal(t, "failed to get jwt claims", err.Error(), "incorrect error type")
+}
+
+// TestTokenUtils_ParseSubscriptionJWT tests parsing a valid subscription confirmation token
+func TestTokenUtils_ParseSubscriptionJWT(t *testing.T) {
+	t.Parallel()
+	c := createTokenUtilsContext(t)
+
+	expectedEmail := "reader@example.com"
+
+	token, err := c.sut.GenerateSubscriptionJWT(expectedEmail)
+	assert.Nil(t, err, "expected to complete without error")
+
+	email, err := c.sut.ParseSubscriptionJWT(token)
+
+	assert.Nil(t, err, "expected to complete without error")
+	assert.Equal(t, expectedEmail, email, "resolved email doesn't match the expected value")
+}
+
+// TestTokenUtils_ParseUnsubscribeJWT tests parsing a valid unsubscribe token
+func TestTokenUtils_ParseUnsubscribeJWT(t *testing.T) {
+	t.Parallel()
+	c := createTokenUtilsContext(t)
+
+	expectedEmail := "reader@example.com"
+
+	token, err := c.sut.GenerateUnsubscribeJWT(expectedEmail)
+	assert.Nil(t, err, "expected to complete without error")
+
+	email, err := c.sut.ParseUnsubscribeJWT(token)
+
+	assert.Nil(t, err, "expected to complete without error")
+	assert.Equal(t, expectedEmail, email, "resolved email doesn't match the expected value")
+}
+
+// TestTokenUtils_ParseUnsubscribeJWT_Subscription_Token tests that a confirmation token can't be used to unsubscribe
+func TestTokenUtils_ParseUnsubscribeJWT_Subscription_Token(t *testing.T) {
+	t.Parallel()
+	c := createTokenUtilsContext(t)
+
+	token, _ := c.sut.GenerateSubscriptionJWT("reader@example.com")
+	_, err := c.sut.ParseUnsubscribeJWT(token)
+
+	assert.NotNil(t, err, "subscription token should lead to error")
+	assert.Equal(t, "failed to get jwt claims", err.Error(), "incorrect error type")
+}
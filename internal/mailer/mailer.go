@@ -0,0 +1,15 @@
+package mailer
+
+// Message is an email sent by the blog engine. The body is plain text.
+// Headers contains additional headers, e.g. List-Unsubscribe.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+	Headers map[string]string
+}
+
+// Mailer interface defining the delivery of emails.
+type Mailer interface {
+	Send(message Message) error
+}
@@ -0,0 +1,39 @@
+package mailer
+
+import (
+	"go.uber.org/zap"
+	"sync"
+)
+
+// Outbox is a Mailer implementation keeping the sent messages in memory instead of delivering them.
+// It is used for local development and testing, the messages are also logged.
+type Outbox struct {
+	logger   *zap.SugaredLogger
+	lock     sync.Mutex
+	messages []Message
+}
+
+// CreateOutbox instantiates the in-memory outbox.
+func CreateOutbox(logger *zap.SugaredLogger) *Outbox {
+	return &Outbox{logger: logger}
+}
+
+// Send stores the message in the outbox.
+func (o *Outbox) Send(message Message) error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	o.messages = append(o.messages, message)
+	o.logger.Infof("email to %s stored in the outbox: %s\n%s", message.To, message.Subject, message.Body)
+	return nil
+}
+
+// Messages returns the messages sent so far, starting with the oldest one.
+func (o *Outbox) Messages() []Message {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	messages := make([]Message, len(o.messages))
+	copy(messages, o.messages)
+	return messages
+}
@@ -0,0 +1,102 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	"go.uber.org/zap"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// SMTPConfig contains the connection settings of the SMTP server and the sender address.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// smtpMailer is a Mailer implementation delivering the messages through an SMTP server.
+// The connection is upgraded using STARTTLS if the server supports it.
+type smtpMailer struct {
+	logger *zap.SugaredLogger
+	config SMTPConfig
+}
+
+// CreateSMTPMailer instantiates the SMTP mailer using the given settings.
+func CreateSMTPMailer(logger *zap.SugaredLogger, config SMTPConfig) Mailer {
+	return &smtpMailer{
+		logger: logger,
+		config: config,
+	}
+}
+
+// Send delivers the message to the SMTP server.
+func (m smtpMailer) Send(message Message) error {
+	log := m.logger
+	config := m.config
+
+	var auth smtp.Auth
+	if config.Username != "" {
+		auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	}
+
+	addr := net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+	body, err := formatMessage(config.From, message, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if err := smtp.SendMail(addr, auth, config.From, []string{message.To}, body); err != nil {
+		log.Debugf("failed to send email to %s: %v", message.To, err)
+		return err
+	}
+
+	log.Debugf("sent email to %s: %s", message.To, message.Subject)
+	return nil
+}
+
+// formatMessage creates the RFC 5322 representation of a message with a quoted-printable UTF-8 body.
+func formatMessage(from string, message Message, date time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+
+	headers := map[string]string{
+		"From":                      from,
+		"To":                        message.To,
+		"Subject":                   mime.QEncoding.Encode("utf-8", message.Subject),
+		"Date":                      date.Format(time.RFC1123Z),
+		"MIME-Version":              "1.0",
+		"Content-Type":              "text/plain; charset=utf-8",
+		"Content-Transfer-Encoding": "quoted-printable",
+	}
+	for name, value := range message.Headers {
+		headers[name] = value
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, headers[name])
+	}
+	buf.WriteString("\r\n")
+
+	w := quotedprintable.NewWriter(&buf)
+	if _, err := w.Write(bytes.ReplaceAll([]byte(message.Body), []byte("\n"), []byte("\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
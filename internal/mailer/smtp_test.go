@@ -0,0 +1,137 @@
+package mailer_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mailer"
+	"io"
+	"mime/quotedprintable"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+// fakeSMTP is a minimal stand-in of an SMTP server without STARTTLS and authentication, recording the received message.
+type fakeSMTP struct {
+	listener net.Listener
+	from     string
+	to       []string
+	data     chan string
+}
+
+// createSMTPContext starts the SMTP server stand-in and creates a mailer connected to it.
+func createSMTPContext(t *testing.T) (*fakeSMTP, mailer.Mailer) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start SMTP stand-in: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	fake := &fakeSMTP{listener: listener, data: make(chan string, 1)}
+	go fake.serve()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	config := mailer.SMTPConfig{Host: "127.0.0.1", Port: addr.Port, From: "blog@example.com"}
+	return fake, mailer.CreateSMTPMailer(logger.CreateLogger(), config)
+}
+
+// serve handles a single SMTP session.
+func (f *fakeSMTP) serve() {
+	conn, err := f.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+	_ = text.PrintfLine("220 localhost ESMTP")
+
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch {
+		case command == "EHLO" || command == "HELO":
+			_ = text.PrintfLine("250 localhost")
+		case strings.HasPrefix(strings.ToUpper(line), "MAIL FROM:"):
+			f.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			_ = text.PrintfLine("250 OK")
+		case strings.HasPrefix(strings.ToUpper(line), "RCPT TO:"):
+			f.to = append(f.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			_ = text.PrintfLine("250 OK")
+		case command == "DATA":
+			_ = text.PrintfLine("354 Start mail input")
+			data, _ := io.ReadAll(text.DotReader())
+			f.data <- string(data)
+			_ = text.PrintfLine("250 OK")
+		case command == "QUIT":
+			_ = text.PrintfLine("221 Bye")
+			return
+		default:
+			_ = text.PrintfLine("250 OK")
+		}
+	}
+}
+
+// TestSMTPMailer_Send tests delivering a message to the SMTP server.
+func TestSMTPMailer_Send(t *testing.T) {
+	t.Parallel()
+	fake, sut := createSMTPContext(t)
+
+	message := mailer.Message{
+		To:      "reader@example.com",
+		Subject: "Neuer Beitrag: Grüße",
+		Body:    "Hello, reader!\nRead more at https://blog.example.com/posts/hello-world",
+		Headers: map[string]string{"List-Unsubscribe": "<https://blog.example.com/unsubscribe?token=abc>"},
+	}
+
+	err := sut.Send(message)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "blog@example.com", fake.from, "incorrect sender")
+	assert.Equal(t, []string{message.To}, fake.to, "incorrect recipients")
+
+	data := <-fake.data
+	header, body, _ := strings.Cut(data, "\n\n")
+	assert.Contains(t, header, "To: reader@example.com", "recipient header should be set")
+	assert.Contains(t, header, "Subject: =?utf-8?q?Neuer_Beitrag:_Gr=C3=BC=C3=9Fe?=", "subject should be encoded")
+	assert.Contains(t, header, "List-Unsubscribe: <https://blog.example.com/unsubscribe?token=abc>", "additional headers should be set")
+
+	decoded, _ := io.ReadAll(quotedprintable.NewReader(strings.NewReader(body)))
+	assert.Equal(t, message.Body, strings.TrimSpace(strings.ReplaceAll(string(decoded), "\r\n", "\n")), "incorrect body")
+}
+
+// TestSMTPMailer_Send_Unavailable tests sending a message while the SMTP server is unavailable.
+func TestSMTPMailer_Send_Unavailable(t *testing.T) {
+	t.Parallel()
+
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	port := listener.Addr().(*net.TCPAddr).Port
+	_ = listener.Close()
+
+	config := mailer.SMTPConfig{Host: "127.0.0.1", Port: port, From: "blog@example.com"}
+	sut := mailer.CreateSMTPMailer(logger.CreateLogger(), config)
+
+	err := sut.Send(mailer.Message{To: "reader@example.com", Subject: "Hello"})
+
+	assert.NotNil(t, err, "expected error")
+}
+
+// TestOutbox_Send tests keeping the sent messages in the outbox.
+func TestOutbox_Send(t *testing.T) {
+	t.Parallel()
+	sut := mailer.CreateOutbox(logger.CreateLogger())
+
+	message := mailer.Message{To: "reader@example.com", Subject: "Hello", Body: "Hello, reader!"}
+
+	err := sut.Send(message)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, []mailer.Message{message}, sut.Messages(), "message should be stored in the outbox")
+}
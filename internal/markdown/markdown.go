@@ -0,0 +1,120 @@
+package markdown
+
+import (
+	"golang.org/x/net/html"
+	"regexp"
+	"strings"
+)
+
+// Patterns of the Markdown block syntax at the start of a line.
+var (
+	fencePattern      = regexp.MustCompile("^ {0,3}(```|~~~)")
+	headingPattern    = regexp.MustCompile(`^ {0,3}#{1,6}(\s+|$)`)
+	closingPattern    = regexp.MustCompile(`\s+#+\s*$`)
+	quotePattern      = regexp.MustCompile(`^ {0,3}>\s?`)
+	listPattern       = regexp.MustCompile(`^\s*([-*+]|\d{1,9}[.)])\s+(\[[ xX]]\s+)?`)
+	rulePattern       = regexp.MustCompile(`^ {0,3}([-*_=|:]\s*)+$`)
+	definitionPattern = regexp.MustCompile(`^ {0,3}\[[^\]]+]:\s*\S+`)
+)
+
+// Patterns of the Markdown inline syntax. The content of the matched elements is kept.
+var (
+	imagePattern     = regexp.MustCompile(`!\[([^\]]*)]\([^)]*\)`)
+	linkPattern      = regexp.MustCompile(`\[([^\]]*)](\([^)]*\)|\[[^\]]*])`)
+	autolinkPattern  = regexp.MustCompile(`<((https?|mailto):[^>\s]+)>`)
+	codePattern      = regexp.MustCompile("`+([^`]*)`+")
+	strongPattern    = regexp.MustCompile(`(\*\*|__)(\S(?:.*?\S)?)(\*\*|__)`)
+	emphasisPattern  = regexp.MustCompile(`\*(\S(?:[^*]*\S)?)\*|\b_(\S(?:[^_]*\S)?)_\b`)
+	strikePattern    = regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`)
+	tableCellPattern = regexp.MustCompile(`\s*\|\s*`)
+)
+
+// PlainText converts a Markdown document to plain text by removing its syntax, HTML tags and code blocks.
+// The text of links, the alternative text of images and the content of inline code are kept.
+// Documents written in HTML are supported as well, every run of whitespace is collapsed to a single space.
+func PlainText(source string) string {
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+	text := make([]string, 0, len(lines))
+	fence := ""
+
+	for _, line := range lines {
+		if match := fencePattern.FindStringSubmatch(line); match != nil {
+			if fence == "" {
+				fence = match[1]
+			} else if fence == match[1] {
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" || definitionPattern.MatchString(line) || rulePattern.MatchString(line) {
+			continue
+		}
+
+		for quotePattern.MatchString(line) {
+			line = quotePattern.ReplaceAllString(line, "")
+		}
+		if headingPattern.MatchString(line) {
+			line = closingPattern.ReplaceAllString(headingPattern.ReplaceAllString(line, ""), "")
+		}
+		line = listPattern.ReplaceAllString(line, "")
+		if strings.HasPrefix(strings.TrimSpace(line), "|") {
+			line = tableCellPattern.ReplaceAllString(line, " ")
+		}
+
+		text = append(text, inlineText(line))
+	}
+
+	return htmlText(strings.Join(text, "\n"))
+}
+
+// inlineText removes the inline syntax of a line.
+func inlineText(line string) string {
+	line = imagePattern.ReplaceAllString(line, "$1")
+	line = linkPattern.ReplaceAllString(line, "$1")
+	line = autolinkPattern.ReplaceAllString(line, "$1")
+	line = codePattern.ReplaceAllString(line, "$1")
+	line = strongPattern.ReplaceAllString(line, "$2")
+	line = emphasisPattern.ReplaceAllString(line, "$1$2")
+	return strikePattern.ReplaceAllString(line, "$1")
+}
+
+// blockElements are the HTML elements separating the words before and after them.
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true, "dd": true, "div": true, "dl": true,
+	"dt": true, "figcaption": true, "figure": true, "footer": true, "h1": true, "h2": true, "h3": true, "h4": true,
+	"h5": true, "h6": true, "header": true, "hr": true, "li": true, "ol": true, "p": true, "pre": true, "section": true,
+	"table": true, "td": true, "th": true, "tr": true, "ul": true,
+}
+
+// htmlText removes the HTML tags of a text, together with the content of scripts and style sheets.
+// Character references are decoded and the whitespace is collapsed.
+func htmlText(fragment string) string {
+	var b strings.Builder
+	tokenizer := html.NewTokenizer(strings.NewReader(fragment))
+	skip := 0
+
+	for {
+		switch token := tokenizer.Next(); token {
+		case html.ErrorToken:
+			return strings.Join(strings.Fields(b.String()), " ")
+
+		case html.TextToken:
+			if skip == 0 {
+				b.Write(tokenizer.Text())
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken, html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch {
+			case string(name) == "script" || string(name) == "style":
+				if token == html.StartTagToken {
+					skip++
+				} else if token == html.EndTagToken && skip > 0 {
+					skip--
+				}
+			case blockElements[string(name)]:
+				b.WriteString(" ")
+			}
+		}
+	}
+}
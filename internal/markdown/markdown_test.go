@@ -0,0 +1,81 @@
+package markdown_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/markdown"
+	"testing"
+)
+
+// TestPlainText tests removing the block and inline syntax of a Markdown document.
+func TestPlainText(t *testing.T) {
+	t.Parallel()
+
+	document := "# Hello *world* #\r\n" +
+		"\r\n" +
+		"Read the [docs](https://example.com/docs \"Docs\") and **never** use `snake_case_names` ~~twice~~.\r\n" +
+		"\r\n" +
+		"> - [x] quoted _task_\r\n" +
+		"1. first ![a cat](cat.png)\r\n" +
+		"\r\n" +
+		"```go\r\n" +
+		"fmt.Println(\"hidden\")\r\n" +
+		"```\r\n" +
+		"\r\n" +
+		"| Name | Value |\r\n" +
+		"|------|-------|\r\n" +
+		"| a    | 1     |\r\n" +
+		"\r\n" +
+		"***\r\n" +
+		"Visit <https://example.com>.\r\n" +
+		"\r\n" +
+		"[docs]: https://example.com/docs\r\n"
+
+	expected := "Hello world Read the docs and never use snake_case_names twice. quoted task first a cat Name Value a 1 Visit https://example.com."
+
+	assert.Equal(t, expected, markdown.PlainText(document), "plain text doesn't match the expected output")
+}
+
+// TestPlainText_HTML tests removing the tags of a document written in HTML.
+func TestPlainText_HTML(t *testing.T) {
+	t.Parallel()
+
+	document := "<h2>Caf&eacute;</h2><p>Hello <strong>world</strong>,<br>again</p><script>alert(1)</script><ul><li>one</li><li>two</li></ul>"
+
+	assert.Equal(t, "Café Hello world, again one two", markdown.PlainText(document), "plain text doesn't match the expected output")
+}
+
+// TestRender tests rendering a Markdown document with embedded HTML to HTML.
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	document := "# Hello\n" +
+		"\n" +
+		"Read the [docs](https://example.com/docs) and **never** give up.\n" +
+		"\n" +
+		"- [x] done\n" +
+		"\n" +
+		"<p class=\"h-entry\">Written in <b>HTML</b></p>\n"
+
+	expected := "<h1>Hello</h1>\n" +
+		"<p>Read the <a href=\"https://example.com/docs\">docs</a> and <strong>never</strong> give up.</p>\n" +
+		"<ul>\n" +
+		"<li><input checked=\"\" type=\"checkbox\" disabled=\"\"/> done</li>\n" +
+		"</ul>\n" +
+		"<p class=\"h-entry\">Written in <b>HTML</b></p>\n"
+
+	assert.Equal(t, expected, markdown.Render(document), "rendered HTML doesn't match the expected output")
+}
+
+// TestSanitize tests removing the scripts, event handlers and unsafe URLs of an HTML fragment.
+func TestSanitize(t *testing.T) {
+	t.Parallel()
+
+	fragment := `<p onclick="steal()">Hi<script>alert(1)</script><iframe src="https://example.com"></iframe></p>` +
+		`<a href="java&#9;script:alert(1)">link</a><a href="https://example.com" target="_blank">safe</a>` +
+		`<img src="javascript:alert(1)" onerror="alert(1)" alt="cat"><font color="red">kept</font>` +
+		`<input type="text" value="x"><style>p{}</style><!-- comment -->`
+
+	expected := `<p>Hi</p><a>link</a><a href="https://example.com">safe</a><img alt="cat"/>kept`
+
+	assert.Equal(t, expected, markdown.Sanitize(fragment), "sanitized HTML doesn't match the expected output")
+}
@@ -0,0 +1,210 @@
+package markdown
+
+import (
+	"bytes"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"net/url"
+	"strings"
+)
+
+// renderer converts the Markdown bodies of the posts to HTML. GitHub Flavored Markdown is supported and HTML written in
+// the body is kept, so bodies imported from WordPress or posted through Micropub are rendered as they are.
+var renderer = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithRendererOptions(goldmarkhtml.WithUnsafe()),
+)
+
+// allowedAttributes are the attributes kept on the HTML elements of a rendered body, per element.
+// Elements missing from the map are removed, keeping their content.
+var allowedAttributes = map[string]map[string]bool{
+	"a":          {"href": true, "rel": true, "hreflang": true},
+	"abbr":       {},
+	"b":          {},
+	"blockquote": {"cite": true},
+	"br":         {},
+	"caption":    {},
+	"cite":       {},
+	"code":       {},
+	"dd":         {},
+	"del":        {"cite": true},
+	"details":    {"open": true},
+	"div":        {},
+	"dl":         {},
+	"dt":         {},
+	"em":         {},
+	"figcaption": {},
+	"figure":     {},
+	"h1":         {},
+	"h2":         {},
+	"h3":         {},
+	"h4":         {},
+	"h5":         {},
+	"h6":         {},
+	"hr":         {},
+	"i":          {},
+	"img":        {"src": true, "srcset": true, "sizes": true, "alt": true, "width": true, "height": true, "loading": true},
+	"input":      {"type": true, "checked": true},
+	"ins":        {"cite": true},
+	"kbd":        {},
+	"li":         {"value": true},
+	"mark":       {},
+	"ol":         {"start": true, "reversed": true},
+	"p":          {},
+	"pre":        {},
+	"q":          {"cite": true},
+	"s":          {},
+	"small":      {},
+	"span":       {},
+	"strong":     {},
+	"sub":        {},
+	"summary":    {},
+	"sup":        {},
+	"table":      {},
+	"tbody":      {},
+	"td":         {"align": true, "colspan": true, "rowspan": true},
+	"tfoot":      {},
+	"th":         {"align": true, "colspan": true, "rowspan": true, "scope": true},
+	"thead":      {},
+	"tr":         {},
+	"u":          {},
+	"ul":         {},
+}
+
+// globalAttributes are the attributes kept on every allowed element. Classes are kept for the microformats of the body.
+var globalAttributes = map[string]bool{"class": true, "dir": true, "id": true, "lang": true, "title": true}
+
+// urlAttributes are the attributes holding a URL, which must be relative or use a safe scheme.
+var urlAttributes = map[string]bool{"cite": true, "href": true, "src": true}
+
+// removedElements are the elements removed together with their content.
+var removedElements = map[string]bool{
+	"button": true, "embed": true, "form": true, "frame": true, "frameset": true, "iframe": true, "math": true,
+	"noembed": true, "noframes": true, "noscript": true, "object": true, "script": true, "select": true, "style": true,
+	"svg": true, "template": true, "textarea": true, "title": true,
+}
+
+// Render converts the Markdown body of a post to HTML. The result is sanitized: scripts, event handlers, unsafe URLs
+// and other markup which could run in the pages of the blog is removed, so it can be embedded in the templates as it is.
+func Render(source string) string {
+	var b bytes.Buffer
+	if err := renderer.Convert([]byte(source), &b); err != nil {
+		return html.EscapeString(source)
+	}
+	return Sanitize(b.String())
+}
+
+// Sanitize removes the elements and attributes of an HTML fragment which aren't allowed in the body of a post.
+// Disallowed elements are replaced by their content, unless the content is removed as well, e.g. the one of scripts.
+func Sanitize(fragment string) string {
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), context)
+	if err != nil {
+		return html.EscapeString(fragment)
+	}
+
+	var b strings.Builder
+	for _, node := range nodes {
+		for _, sanitized := range sanitizeNode(node) {
+			_ = html.Render(&b, sanitized)
+		}
+	}
+	return b.String()
+}
+
+// sanitizeNode returns the sanitized replacement of a node, which is empty for removed nodes and the sanitized children
+// of the elements which aren't allowed.
+func sanitizeNode(node *html.Node) []*html.Node {
+	switch node.Type {
+	case html.TextNode:
+		return []*html.Node{node}
+	case html.ElementNode:
+	default:
+		return nil
+	}
+	if removedElements[node.Data] {
+		return nil
+	}
+
+	var children []*html.Node
+	for child := node.FirstChild; child != nil; {
+		next := child.NextSibling
+		node.RemoveChild(child)
+		children = append(children, sanitizeNode(child)...)
+		child = next
+	}
+
+	allowed, ok := allowedAttributes[node.Data]
+	if !ok || node.Namespace != "" {
+		return children
+	}
+
+	attributes := make([]html.Attribute, 0, len(node.Attr))
+	for _, attribute := range node.Attr {
+		if attribute.Namespace != "" || !(allowed[attribute.Key] || globalAttributes[attribute.Key]) {
+			continue
+		}
+		if urlAttributes[attribute.Key] && !isSafeURL(attribute.Val) {
+			continue
+		}
+		if attribute.Key == "srcset" && !isSafeSrcset(attribute.Val) {
+			continue
+		}
+		attributes = append(attributes, attribute)
+	}
+	node.Attr = attributes
+
+	// Only the checkboxes of task lists are kept, and readers can't tick them
+	if node.Data == "input" {
+		if getAttribute(node, "type") != "checkbox" {
+			return nil
+		}
+		node.Attr = append(node.Attr, html.Attribute{Key: "disabled", Val: ""})
+	}
+
+	for _, child := range children {
+		node.AppendChild(child)
+	}
+	return []*html.Node{node}
+}
+
+// getAttribute returns the value of an attribute of a node, or an empty string if it isn't set.
+func getAttribute(node *html.Node, key string) string {
+	for _, attribute := range node.Attr {
+		if attribute.Key == key {
+			return attribute.Val
+		}
+	}
+	return ""
+}
+
+// isSafeURL checks whether a URL is relative or uses the HTTP(S) or mailto scheme. Browsers ignore the tabs and line
+// breaks of URLs and the control characters around them, so they're removed before reading the scheme.
+func isSafeURL(value string) bool {
+	value = strings.NewReplacer("\t", "", "\n", "", "\r", "").Replace(value)
+	value = strings.TrimFunc(value, func(r rune) bool { return r <= ' ' })
+	u, err := url.Parse(value)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	default:
+		return false
+	}
+}
+
+// isSafeSrcset checks whether every candidate of a srcset attribute has a safe URL.
+func isSafeSrcset(value string) bool {
+	for _, candidate := range strings.Split(value, ",") {
+		fields := strings.Fields(candidate)
+		if len(fields) > 0 && !isSafeURL(fields[0]) {
+			return false
+		}
+	}
+	return true
+}
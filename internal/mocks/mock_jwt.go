@@ -48,6 +48,51 @@ func (mr *MockTokenUtilsMockRecorder) GenerateJWT(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateJWT", reflect.TypeOf((*MockTokenUtils)(nil).GenerateJWT), arg0)
 }
 
+// GeneratePostAccessJWT mocks base method.
+func (m *MockTokenUtils) GeneratePostAccessJWT(arg0 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GeneratePostAccessJWT", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GeneratePostAccessJWT indicates an expected call of GeneratePostAccessJWT.
+func (mr *MockTokenUtilsMockRecorder) GeneratePostAccessJWT(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GeneratePostAccessJWT", reflect.TypeOf((*MockTokenUtils)(nil).GeneratePostAccessJWT), arg0)
+}
+
+// GenerateSubscriptionJWT mocks base method.
+func (m *MockTokenUtils) GenerateSubscriptionJWT(arg0 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateSubscriptionJWT", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateSubscriptionJWT indicates an expected call of GenerateSubscriptionJWT.
+func (mr *MockTokenUtilsMockRecorder) GenerateSubscriptionJWT(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateSubscriptionJWT", reflect.TypeOf((*MockTokenUtils)(nil).GenerateSubscriptionJWT), arg0)
+}
+
+// GenerateUnsubscribeJWT mocks base method.
+func (m *MockTokenUtils) GenerateUnsubscribeJWT(arg0 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateUnsubscribeJWT", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateUnsubscribeJWT indicates an expected call of GenerateUnsubscribeJWT.
+func (mr *MockTokenUtilsMockRecorder) GenerateUnsubscribeJWT(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateUnsubscribeJWT", reflect.TypeOf((*MockTokenUtils)(nil).GenerateUnsubscribeJWT), arg0)
+}
+
 // ParseJWT mocks base method.
 func (m *MockTokenUtils) ParseJWT(arg0 string) (string, error) {
 	m.ctrl.T.Helper()
@@ -62,3 +107,48 @@ func (mr *MockTokenUtilsMockRecorder) ParseJWT(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseJWT", reflect.TypeOf((*MockTokenUtils)(nil).ParseJWT), arg0)
 }
+
+// ParsePostAccessJWT mocks base method.
+func (m *MockTokenUtils) ParsePostAccessJWT(arg0 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParsePostAccessJWT", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ParsePostAccessJWT indicates an expected call of ParsePostAccessJWT.
+func (mr *MockTokenUtilsMockRecorder) ParsePostAccessJWT(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParsePostAccessJWT", reflect.TypeOf((*MockTokenUtils)(nil).ParsePostAccessJWT), arg0)
+}
+
+// ParseSubscriptionJWT mocks base method.
+func (m *MockTokenUtils) ParseSubscriptionJWT(arg0 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParseSubscriptionJWT", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ParseSubscriptionJWT indicates an expected call of ParseSubscriptionJWT.
+func (mr *MockTokenUtilsMockRecorder) ParseSubscriptionJWT(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseSubscriptionJWT", reflect.TypeOf((*MockTokenUtils)(nil).ParseSubscriptionJWT), arg0)
+}
+
+// ParseUnsubscribeJWT mocks base method.
+func (m *MockTokenUtils) ParseUnsubscribeJWT(arg0 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParseUnsubscribeJWT", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ParseUnsubscribeJWT indicates an expected call of ParseUnsubscribeJWT.
+func (mr *MockTokenUtilsMockRecorder) ParseUnsubscribeJWT(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseUnsubscribeJWT", reflect.TypeOf((*MockTokenUtils)(nil).ParseUnsubscribeJWT), arg0)
+}
@@ -1,83 +1,1275 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/wlchs/blog/internal/repository (interfaces: PostRepository,UserRepository)
+// Source: github.com/wlchs/blog/internal/repository (interfaces: AnalyticsRepository,CollectionRepository,FederationRepository,FieldRepository,MediaRepository,NewsletterRepository,OutboxRepository,PostRepository,ReactionRepository,RelatedRepository,SeriesRepository,UserRepository,WebhookRepository,WebmentionRepository)
 
 // Package mocks is a generated GoMock package.
 package mocks
 
 import (
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	repository "github.com/wlchs/blog/internal/repository"
 	types "github.com/wlchs/blog/internal/types"
 )
 
+// MockAnalyticsRepository is a mock of AnalyticsRepository interface.
+type MockAnalyticsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAnalyticsRepositoryMockRecorder
+}
+
+// MockAnalyticsRepositoryMockRecorder is the mock recorder for MockAnalyticsRepository.
+type MockAnalyticsRepositoryMockRecorder struct {
+	mock *MockAnalyticsRepository
+}
+
+// NewMockAnalyticsRepository creates a new mock instance.
+func NewMockAnalyticsRepository(ctrl *gomock.Controller) *MockAnalyticsRepository {
+	mock := &MockAnalyticsRepository{ctrl: ctrl}
+	mock.recorder = &MockAnalyticsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAnalyticsRepository) EXPECT() *MockAnalyticsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddPageView mocks base method.
+func (m *MockAnalyticsRepository) AddPageView(arg0 string, arg1 *repository.PageView) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPageView", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddPageView indicates an expected call of AddPageView.
+func (mr *MockAnalyticsRepositoryMockRecorder) AddPageView(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPageView", reflect.TypeOf((*MockAnalyticsRepository)(nil).AddPageView), arg0, arg1)
+}
+
+// AddVisitorSalt mocks base method.
+func (m *MockAnalyticsRepository) AddVisitorSalt(arg0 time.Time, arg1 []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddVisitorSalt", arg0, arg1)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddVisitorSalt indicates an expected call of AddVisitorSalt.
+func (mr *MockAnalyticsRepositoryMockRecorder) AddVisitorSalt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddVisitorSalt", reflect.TypeOf((*MockAnalyticsRepository)(nil).AddVisitorSalt), arg0, arg1)
+}
+
+// DeletePageViews mocks base method.
+func (m *MockAnalyticsRepository) DeletePageViews(arg0 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePageViews", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeletePageViews indicates an expected call of DeletePageViews.
+func (mr *MockAnalyticsRepositoryMockRecorder) DeletePageViews(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePageViews", reflect.TypeOf((*MockAnalyticsRepository)(nil).DeletePageViews), arg0)
+}
+
+// DeleteVisitorSalts mocks base method.
+func (m *MockAnalyticsRepository) DeleteVisitorSalts(arg0 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVisitorSalts", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteVisitorSalts indicates an expected call of DeleteVisitorSalts.
+func (mr *MockAnalyticsRepositoryMockRecorder) DeleteVisitorSalts(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVisitorSalts", reflect.TypeOf((*MockAnalyticsRepository)(nil).DeleteVisitorSalts), arg0)
+}
+
+// GetTopPosts mocks base method.
+func (m *MockAnalyticsRepository) GetTopPosts(arg0 []uint, arg1, arg2 time.Time, arg3 int) ([]repository.ViewRollup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTopPosts", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]repository.ViewRollup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTopPosts indicates an expected call of GetTopPosts.
+func (mr *MockAnalyticsRepositoryMockRecorder) GetTopPosts(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTopPosts", reflect.TypeOf((*MockAnalyticsRepository)(nil).GetTopPosts), arg0, arg1, arg2, arg3)
+}
+
+// GetTopReferrers mocks base method.
+func (m *MockAnalyticsRepository) GetTopReferrers(arg0 []uint, arg1, arg2 time.Time, arg3 int) ([]repository.ReferrerRollup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTopReferrers", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]repository.ReferrerRollup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTopReferrers indicates an expected call of GetTopReferrers.
+func (mr *MockAnalyticsRepositoryMockRecorder) GetTopReferrers(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTopReferrers", reflect.TypeOf((*MockAnalyticsRepository)(nil).GetTopReferrers), arg0, arg1, arg2, arg3)
+}
+
+// GetViewSeries mocks base method.
+func (m *MockAnalyticsRepository) GetViewSeries(arg0 []uint, arg1 string, arg2, arg3 time.Time) ([]repository.ViewRollup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetViewSeries", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]repository.ViewRollup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetViewSeries indicates an expected call of GetViewSeries.
+func (mr *MockAnalyticsRepositoryMockRecorder) GetViewSeries(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetViewSeries", reflect.TypeOf((*MockAnalyticsRepository)(nil).GetViewSeries), arg0, arg1, arg2, arg3)
+}
+
+// RollupPageViews mocks base method.
+func (m *MockAnalyticsRepository) RollupPageViews(arg0 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RollupPageViews", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RollupPageViews indicates an expected call of RollupPageViews.
+func (mr *MockAnalyticsRepositoryMockRecorder) RollupPageViews(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RollupPageViews", reflect.TypeOf((*MockAnalyticsRepository)(nil).RollupPageViews), arg0)
+}
+
+// MockCollectionRepository is a mock of CollectionRepository interface.
+type MockCollectionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCollectionRepositoryMockRecorder
+}
+
+// MockCollectionRepositoryMockRecorder is the mock recorder for MockCollectionRepository.
+type MockCollectionRepositoryMockRecorder struct {
+	mock *MockCollectionRepository
+}
+
+// NewMockCollectionRepository creates a new mock instance.
+func NewMockCollectionRepository(ctrl *gomock.Controller) *MockCollectionRepository {
+	mock := &MockCollectionRepository{ctrl: ctrl}
+	mock.recorder = &MockCollectionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCollectionRepository) EXPECT() *MockCollectionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddCollection mocks base method.
+func (m *MockCollectionRepository) AddCollection(arg0 *types.Collection) (*repository.Collection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCollection", arg0)
+	ret0, _ := ret[0].(*repository.Collection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddCollection indicates an expected call of AddCollection.
+func (mr *MockCollectionRepositoryMockRecorder) AddCollection(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCollection", reflect.TypeOf((*MockCollectionRepository)(nil).AddCollection), arg0)
+}
+
+// DeleteCollection mocks base method.
+func (m *MockCollectionRepository) DeleteCollection(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCollection", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCollection indicates an expected call of DeleteCollection.
+func (mr *MockCollectionRepositoryMockRecorder) DeleteCollection(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCollection", reflect.TypeOf((*MockCollectionRepository)(nil).DeleteCollection), arg0)
+}
+
+// GetCollection mocks base method.
+func (m *MockCollectionRepository) GetCollection(arg0 string) (*repository.Collection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollection", arg0)
+	ret0, _ := ret[0].(*repository.Collection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCollection indicates an expected call of GetCollection.
+func (mr *MockCollectionRepositoryMockRecorder) GetCollection(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollection", reflect.TypeOf((*MockCollectionRepository)(nil).GetCollection), arg0)
+}
+
+// GetCollections mocks base method.
+func (m *MockCollectionRepository) GetCollections() ([]repository.Collection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollections")
+	ret0, _ := ret[0].([]repository.Collection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCollections indicates an expected call of GetCollections.
+func (mr *MockCollectionRepositoryMockRecorder) GetCollections() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollections", reflect.TypeOf((*MockCollectionRepository)(nil).GetCollections))
+}
+
+// SetCollectionPosts mocks base method.
+func (m *MockCollectionRepository) SetCollectionPosts(arg0 uint, arg1 []uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCollectionPosts", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCollectionPosts indicates an expected call of SetCollectionPosts.
+func (mr *MockCollectionRepositoryMockRecorder) SetCollectionPosts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCollectionPosts", reflect.TypeOf((*MockCollectionRepository)(nil).SetCollectionPosts), arg0, arg1)
+}
+
+// UpdateCollection mocks base method.
+func (m *MockCollectionRepository) UpdateCollection(arg0 *types.Collection) (*repository.Collection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCollection", arg0)
+	ret0, _ := ret[0].(*repository.Collection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCollection indicates an expected call of UpdateCollection.
+func (mr *MockCollectionRepositoryMockRecorder) UpdateCollection(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCollection", reflect.TypeOf((*MockCollectionRepository)(nil).UpdateCollection), arg0)
+}
+
+// MockFederationRepository is a mock of FederationRepository interface.
+type MockFederationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFederationRepositoryMockRecorder
+}
+
+// MockFederationRepositoryMockRecorder is the mock recorder for MockFederationRepository.
+type MockFederationRepositoryMockRecorder struct {
+	mock *MockFederationRepository
+}
+
+// NewMockFederationRepository creates a new mock instance.
+func NewMockFederationRepository(ctrl *gomock.Controller) *MockFederationRepository {
+	mock := &MockFederationRepository{ctrl: ctrl}
+	mock.recorder = &MockFederationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFederationRepository) EXPECT() *MockFederationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddActorKey mocks base method.
+func (m *MockFederationRepository) AddActorKey(arg0 *repository.ActorKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddActorKey", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddActorKey indicates an expected call of AddActorKey.
+func (mr *MockFederationRepositoryMockRecorder) AddActorKey(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddActorKey", reflect.TypeOf((*MockFederationRepository)(nil).AddActorKey), arg0)
+}
+
+// AddDeliveries mocks base method.
+func (m *MockFederationRepository) AddDeliveries(arg0 []repository.FederationDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddDeliveries", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddDeliveries indicates an expected call of AddDeliveries.
+func (mr *MockFederationRepositoryMockRecorder) AddDeliveries(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddDeliveries", reflect.TypeOf((*MockFederationRepository)(nil).AddDeliveries), arg0)
+}
+
+// AddFollower mocks base method.
+func (m *MockFederationRepository) AddFollower(arg0 *repository.Follower) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddFollower", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddFollower indicates an expected call of AddFollower.
+func (mr *MockFederationRepositoryMockRecorder) AddFollower(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFollower", reflect.TypeOf((*MockFederationRepository)(nil).AddFollower), arg0)
+}
+
+// ClaimDueDeliveries mocks base method.
+func (m *MockFederationRepository) ClaimDueDeliveries(arg0 time.Time, arg1 int, arg2 time.Duration) ([]repository.FederationDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueDeliveries", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]repository.FederationDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueDeliveries indicates an expected call of ClaimDueDeliveries.
+func (mr *MockFederationRepositoryMockRecorder) ClaimDueDeliveries(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueDeliveries", reflect.TypeOf((*MockFederationRepository)(nil).ClaimDueDeliveries), arg0, arg1, arg2)
+}
+
+// CountFollowers mocks base method.
+func (m *MockFederationRepository) CountFollowers(arg0 string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountFollowers", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountFollowers indicates an expected call of CountFollowers.
+func (mr *MockFederationRepositoryMockRecorder) CountFollowers(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountFollowers", reflect.TypeOf((*MockFederationRepository)(nil).CountFollowers), arg0)
+}
+
+// DeleteFollower mocks base method.
+func (m *MockFederationRepository) DeleteFollower(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFollower", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFollower indicates an expected call of DeleteFollower.
+func (mr *MockFederationRepositoryMockRecorder) DeleteFollower(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFollower", reflect.TypeOf((*MockFederationRepository)(nil).DeleteFollower), arg0, arg1)
+}
+
+// GetActorKey mocks base method.
+func (m *MockFederationRepository) GetActorKey(arg0 string) (*repository.ActorKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActorKey", arg0)
+	ret0, _ := ret[0].(*repository.ActorKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActorKey indicates an expected call of GetActorKey.
+func (mr *MockFederationRepositoryMockRecorder) GetActorKey(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActorKey", reflect.TypeOf((*MockFederationRepository)(nil).GetActorKey), arg0)
+}
+
+// GetFollowers mocks base method.
+func (m *MockFederationRepository) GetFollowers(arg0 string) ([]repository.Follower, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFollowers", arg0)
+	ret0, _ := ret[0].([]repository.Follower)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFollowers indicates an expected call of GetFollowers.
+func (mr *MockFederationRepositoryMockRecorder) GetFollowers(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFollowers", reflect.TypeOf((*MockFederationRepository)(nil).GetFollowers), arg0)
+}
+
+// GetLastActivityType mocks base method.
+func (m *MockFederationRepository) GetLastActivityType(arg0 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastActivityType", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastActivityType indicates an expected call of GetLastActivityType.
+func (mr *MockFederationRepositoryMockRecorder) GetLastActivityType(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastActivityType", reflect.TypeOf((*MockFederationRepository)(nil).GetLastActivityType), arg0)
+}
+
+// UpdateDelivery mocks base method.
+func (m *MockFederationRepository) UpdateDelivery(arg0 *repository.FederationDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDelivery", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDelivery indicates an expected call of UpdateDelivery.
+func (mr *MockFederationRepositoryMockRecorder) UpdateDelivery(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDelivery", reflect.TypeOf((*MockFederationRepository)(nil).UpdateDelivery), arg0)
+}
+
+// MockFieldRepository is a mock of FieldRepository interface.
+type MockFieldRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFieldRepositoryMockRecorder
+}
+
+// MockFieldRepositoryMockRecorder is the mock recorder for MockFieldRepository.
+type MockFieldRepositoryMockRecorder struct {
+	mock *MockFieldRepository
+}
+
+// NewMockFieldRepository creates a new mock instance.
+func NewMockFieldRepository(ctrl *gomock.Controller) *MockFieldRepository {
+	mock := &MockFieldRepository{ctrl: ctrl}
+	mock.recorder = &MockFieldRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFieldRepository) EXPECT() *MockFieldRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddField mocks base method.
+func (m *MockFieldRepository) AddField(arg0 *types.FieldDefinition) (*repository.FieldDefinition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddField", arg0)
+	ret0, _ := ret[0].(*repository.FieldDefinition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddField indicates an expected call of AddField.
+func (mr *MockFieldRepositoryMockRecorder) AddField(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddField", reflect.TypeOf((*MockFieldRepository)(nil).AddField), arg0)
+}
+
+// DeleteField mocks base method.
+func (m *MockFieldRepository) DeleteField(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteField", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteField indicates an expected call of DeleteField.
+func (mr *MockFieldRepositoryMockRecorder) DeleteField(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteField", reflect.TypeOf((*MockFieldRepository)(nil).DeleteField), arg0)
+}
+
+// GetField mocks base method.
+func (m *MockFieldRepository) GetField(arg0 string) (*repository.FieldDefinition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetField", arg0)
+	ret0, _ := ret[0].(*repository.FieldDefinition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetField indicates an expected call of GetField.
+func (mr *MockFieldRepositoryMockRecorder) GetField(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetField", reflect.TypeOf((*MockFieldRepository)(nil).GetField), arg0)
+}
+
+// GetFields mocks base method.
+func (m *MockFieldRepository) GetFields() ([]repository.FieldDefinition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFields")
+	ret0, _ := ret[0].([]repository.FieldDefinition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFields indicates an expected call of GetFields.
+func (mr *MockFieldRepositoryMockRecorder) GetFields() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFields", reflect.TypeOf((*MockFieldRepository)(nil).GetFields))
+}
+
+// MockMediaRepository is a mock of MediaRepository interface.
+type MockMediaRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMediaRepositoryMockRecorder
+}
+
+// MockMediaRepositoryMockRecorder is the mock recorder for MockMediaRepository.
+type MockMediaRepositoryMockRecorder struct {
+	mock *MockMediaRepository
+}
+
+// NewMockMediaRepository creates a new mock instance.
+func NewMockMediaRepository(ctrl *gomock.Controller) *MockMediaRepository {
+	mock := &MockMediaRepository{ctrl: ctrl}
+	mock.recorder = &MockMediaRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMediaRepository) EXPECT() *MockMediaRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddMedia mocks base method.
+func (m *MockMediaRepository) AddMedia(arg0 *repository.Media) (*repository.Media, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddMedia", arg0)
+	ret0, _ := ret[0].(*repository.Media)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddMedia indicates an expected call of AddMedia.
+func (mr *MockMediaRepositoryMockRecorder) AddMedia(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMedia", reflect.TypeOf((*MockMediaRepository)(nil).AddMedia), arg0)
+}
+
+// AddMediaVariant mocks base method.
+func (m *MockMediaRepository) AddMediaVariant(arg0 *repository.MediaVariant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddMediaVariant", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddMediaVariant indicates an expected call of AddMediaVariant.
+func (mr *MockMediaRepositoryMockRecorder) AddMediaVariant(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMediaVariant", reflect.TypeOf((*MockMediaRepository)(nil).AddMediaVariant), arg0)
+}
+
+// DeleteMedia mocks base method.
+func (m *MockMediaRepository) DeleteMedia(arg0 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMedia", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMedia indicates an expected call of DeleteMedia.
+func (mr *MockMediaRepositoryMockRecorder) DeleteMedia(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMedia", reflect.TypeOf((*MockMediaRepository)(nil).DeleteMedia), arg0)
+}
+
+// GetMedia mocks base method.
+func (m *MockMediaRepository) GetMedia(arg0 uint) (*repository.Media, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMedia", arg0)
+	ret0, _ := ret[0].(*repository.Media)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMedia indicates an expected call of GetMedia.
+func (mr *MockMediaRepositoryMockRecorder) GetMedia(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMedia", reflect.TypeOf((*MockMediaRepository)(nil).GetMedia), arg0)
+}
+
+// GetMediaByIDs mocks base method.
+func (m *MockMediaRepository) GetMediaByIDs(arg0 []uint) ([]repository.Media, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMediaByIDs", arg0)
+	ret0, _ := ret[0].([]repository.Media)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMediaByIDs indicates an expected call of GetMediaByIDs.
+func (mr *MockMediaRepositoryMockRecorder) GetMediaByIDs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMediaByIDs", reflect.TypeOf((*MockMediaRepository)(nil).GetMediaByIDs), arg0)
+}
+
+// GetMediaList mocks base method.
+func (m *MockMediaRepository) GetMediaList() ([]repository.Media, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMediaList")
+	ret0, _ := ret[0].([]repository.Media)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMediaList indicates an expected call of GetMediaList.
+func (mr *MockMediaRepositoryMockRecorder) GetMediaList() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMediaList", reflect.TypeOf((*MockMediaRepository)(nil).GetMediaList))
+}
+
+// MockNewsletterRepository is a mock of NewsletterRepository interface.
+type MockNewsletterRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockNewsletterRepositoryMockRecorder
+}
+
+// MockNewsletterRepositoryMockRecorder is the mock recorder for MockNewsletterRepository.
+type MockNewsletterRepositoryMockRecorder struct {
+	mock *MockNewsletterRepository
+}
+
+// NewMockNewsletterRepository creates a new mock instance.
+func NewMockNewsletterRepository(ctrl *gomock.Controller) *MockNewsletterRepository {
+	mock := &MockNewsletterRepository{ctrl: ctrl}
+	mock.recorder = &MockNewsletterRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNewsletterRepository) EXPECT() *MockNewsletterRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddNewsletterPost mocks base method.
+func (m *MockNewsletterRepository) AddNewsletterPost(arg0 *repository.NewsletterPost) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddNewsletterPost", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddNewsletterPost indicates an expected call of AddNewsletterPost.
+func (mr *MockNewsletterRepositoryMockRecorder) AddNewsletterPost(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddNewsletterPost", reflect.TypeOf((*MockNewsletterRepository)(nil).AddNewsletterPost), arg0)
+}
+
+// AddSubscriber mocks base method.
+func (m *MockNewsletterRepository) AddSubscriber(arg0 *repository.Subscriber) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSubscriber", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddSubscriber indicates an expected call of AddSubscriber.
+func (mr *MockNewsletterRepositoryMockRecorder) AddSubscriber(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSubscriber", reflect.TypeOf((*MockNewsletterRepository)(nil).AddSubscriber), arg0)
+}
+
+// DeleteSubscriber mocks base method.
+func (m *MockNewsletterRepository) DeleteSubscriber(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSubscriber", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSubscriber indicates an expected call of DeleteSubscriber.
+func (mr *MockNewsletterRepositoryMockRecorder) DeleteSubscriber(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSubscriber", reflect.TypeOf((*MockNewsletterRepository)(nil).DeleteSubscriber), arg0)
+}
+
+// GetConfirmedSubscribers mocks base method.
+func (m *MockNewsletterRepository) GetConfirmedSubscribers(arg0 string) ([]repository.Subscriber, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConfirmedSubscribers", arg0)
+	ret0, _ := ret[0].([]repository.Subscriber)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConfirmedSubscribers indicates an expected call of GetConfirmedSubscribers.
+func (mr *MockNewsletterRepositoryMockRecorder) GetConfirmedSubscribers(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfirmedSubscribers", reflect.TypeOf((*MockNewsletterRepository)(nil).GetConfirmedSubscribers), arg0)
+}
+
+// GetNewsletterPostsSince mocks base method.
+func (m *MockNewsletterRepository) GetNewsletterPostsSince(arg0 time.Time) ([]repository.NewsletterPost, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNewsletterPostsSince", arg0)
+	ret0, _ := ret[0].([]repository.NewsletterPost)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNewsletterPostsSince indicates an expected call of GetNewsletterPostsSince.
+func (mr *MockNewsletterRepositoryMockRecorder) GetNewsletterPostsSince(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNewsletterPostsSince", reflect.TypeOf((*MockNewsletterRepository)(nil).GetNewsletterPostsSince), arg0)
+}
+
+// GetPendingNewsletterPosts mocks base method.
+func (m *MockNewsletterRepository) GetPendingNewsletterPosts() ([]repository.NewsletterPost, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingNewsletterPosts")
+	ret0, _ := ret[0].([]repository.NewsletterPost)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingNewsletterPosts indicates an expected call of GetPendingNewsletterPosts.
+func (mr *MockNewsletterRepositoryMockRecorder) GetPendingNewsletterPosts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingNewsletterPosts", reflect.TypeOf((*MockNewsletterRepository)(nil).GetPendingNewsletterPosts))
+}
+
+// GetSubscriber mocks base method.
+func (m *MockNewsletterRepository) GetSubscriber(arg0 string) (*repository.Subscriber, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriber", arg0)
+	ret0, _ := ret[0].(*repository.Subscriber)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriber indicates an expected call of GetSubscriber.
+func (mr *MockNewsletterRepositoryMockRecorder) GetSubscriber(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriber", reflect.TypeOf((*MockNewsletterRepository)(nil).GetSubscriber), arg0)
+}
+
+// SetAnnounced mocks base method.
+func (m *MockNewsletterRepository) SetAnnounced(arg0 []uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAnnounced", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAnnounced indicates an expected call of SetAnnounced.
+func (mr *MockNewsletterRepositoryMockRecorder) SetAnnounced(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAnnounced", reflect.TypeOf((*MockNewsletterRepository)(nil).SetAnnounced), arg0)
+}
+
+// UpdateSubscriber mocks base method.
+func (m *MockNewsletterRepository) UpdateSubscriber(arg0 *repository.Subscriber) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSubscriber", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSubscriber indicates an expected call of UpdateSubscriber.
+func (mr *MockNewsletterRepositoryMockRecorder) UpdateSubscriber(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSubscriber", reflect.TypeOf((*MockNewsletterRepository)(nil).UpdateSubscriber), arg0)
+}
+
+// MockOutboxRepository is a mock of OutboxRepository interface.
+type MockOutboxRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRepositoryMockRecorder
+}
+
+// MockOutboxRepositoryMockRecorder is the mock recorder for MockOutboxRepository.
+type MockOutboxRepositoryMockRecorder struct {
+	mock *MockOutboxRepository
+}
+
+// NewMockOutboxRepository creates a new mock instance.
+func NewMockOutboxRepository(ctrl *gomock.Controller) *MockOutboxRepository {
+	mock := &MockOutboxRepository{ctrl: ctrl}
+	mock.recorder = &MockOutboxRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRepository) EXPECT() *MockOutboxRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimPendingEvents mocks base method.
+func (m *MockOutboxRepository) ClaimPendingEvents(arg0 time.Time, arg1 int, arg2 time.Duration) ([]repository.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimPendingEvents", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]repository.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimPendingEvents indicates an expected call of ClaimPendingEvents.
+func (mr *MockOutboxRepositoryMockRecorder) ClaimPendingEvents(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimPendingEvents", reflect.TypeOf((*MockOutboxRepository)(nil).ClaimPendingEvents), arg0, arg1, arg2)
+}
+
+// DeleteDispatchedEvents mocks base method.
+func (m *MockOutboxRepository) DeleteDispatchedEvents(arg0 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDispatchedEvents", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteDispatchedEvents indicates an expected call of DeleteDispatchedEvents.
+func (mr *MockOutboxRepositoryMockRecorder) DeleteDispatchedEvents(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDispatchedEvents", reflect.TypeOf((*MockOutboxRepository)(nil).DeleteDispatchedEvents), arg0)
+}
+
+// UpdateEvent mocks base method.
+func (m *MockOutboxRepository) UpdateEvent(arg0 *repository.OutboxEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEvent", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateEvent indicates an expected call of UpdateEvent.
+func (mr *MockOutboxRepositoryMockRecorder) UpdateEvent(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEvent", reflect.TypeOf((*MockOutboxRepository)(nil).UpdateEvent), arg0)
+}
+
 // MockPostRepository is a mock of PostRepository interface.
 type MockPostRepository struct {
 	ctrl     *gomock.Controller
-	recorder *MockPostRepositoryMockRecorder
+	recorder *MockPostRepositoryMockRecorder
+}
+
+// MockPostRepositoryMockRecorder is the mock recorder for MockPostRepository.
+type MockPostRepositoryMockRecorder struct {
+	mock *MockPostRepository
+}
+
+// NewMockPostRepository creates a new mock instance.
+func NewMockPostRepository(ctrl *gomock.Controller) *MockPostRepository {
+	mock := &MockPostRepository{ctrl: ctrl}
+	mock.recorder = &MockPostRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPostRepository) EXPECT() *MockPostRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddPost mocks base method.
+func (m *MockPostRepository) AddPost(arg0 *types.Post, arg1 uint, arg2 []repository.Contributor, arg3 repository.PostEvents) (*repository.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPost", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*repository.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPost indicates an expected call of AddPost.
+func (mr *MockPostRepositoryMockRecorder) AddPost(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPost", reflect.TypeOf((*MockPostRepository)(nil).AddPost), arg0, arg1, arg2, arg3)
+}
+
+// DeletePost mocks base method.
+func (m *MockPostRepository) DeletePost(arg0 uint, arg1 []types.DomainEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePost", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePost indicates an expected call of DeletePost.
+func (mr *MockPostRepositoryMockRecorder) DeletePost(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePost", reflect.TypeOf((*MockPostRepository)(nil).DeletePost), arg0, arg1)
+}
+
+// DeleteTranslation mocks base method.
+func (m *MockPostRepository) DeleteTranslation(arg0 uint, arg1 string, arg2 []types.DomainEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTranslation", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTranslation indicates an expected call of DeleteTranslation.
+func (mr *MockPostRepositoryMockRecorder) DeleteTranslation(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTranslation", reflect.TypeOf((*MockPostRepository)(nil).DeleteTranslation), arg0, arg1, arg2)
+}
+
+// GetAllPosts mocks base method.
+func (m *MockPostRepository) GetAllPosts() ([]repository.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllPosts")
+	ret0, _ := ret[0].([]repository.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllPosts indicates an expected call of GetAllPosts.
+func (mr *MockPostRepositoryMockRecorder) GetAllPosts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllPosts", reflect.TypeOf((*MockPostRepository)(nil).GetAllPosts))
+}
+
+// GetPost mocks base method.
+func (m *MockPostRepository) GetPost(arg0 string) (*repository.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPost", arg0)
+	ret0, _ := ret[0].(*repository.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPost indicates an expected call of GetPost.
+func (mr *MockPostRepositoryMockRecorder) GetPost(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPost", reflect.TypeOf((*MockPostRepository)(nil).GetPost), arg0)
+}
+
+// GetPosts mocks base method.
+func (m *MockPostRepository) GetPosts(arg0 types.PostFilter) ([]repository.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPosts", arg0)
+	ret0, _ := ret[0].([]repository.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPosts indicates an expected call of GetPosts.
+func (mr *MockPostRepositoryMockRecorder) GetPosts(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPosts", reflect.TypeOf((*MockPostRepository)(nil).GetPosts), arg0)
+}
+
+// SetContributors mocks base method.
+func (m *MockPostRepository) SetContributors(arg0 uint, arg1 []repository.Contributor, arg2 []types.DomainEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetContributors", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetContributors indicates an expected call of SetContributors.
+func (mr *MockPostRepositoryMockRecorder) SetContributors(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetContributors", reflect.TypeOf((*MockPostRepository)(nil).SetContributors), arg0, arg1, arg2)
+}
+
+// SetHighlight mocks base method.
+func (m *MockPostRepository) SetHighlight(arg0 *repository.Post, arg1 types.PostHighlight, arg2 repository.PostEvents) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHighlight", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHighlight indicates an expected call of SetHighlight.
+func (mr *MockPostRepositoryMockRecorder) SetHighlight(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHighlight", reflect.TypeOf((*MockPostRepository)(nil).SetHighlight), arg0, arg1, arg2)
+}
+
+// SetTranslation mocks base method.
+func (m *MockPostRepository) SetTranslation(arg0 *repository.PostTranslation, arg1 []types.DomainEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTranslation", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTranslation indicates an expected call of SetTranslation.
+func (mr *MockPostRepositoryMockRecorder) SetTranslation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTranslation", reflect.TypeOf((*MockPostRepository)(nil).SetTranslation), arg0, arg1)
+}
+
+// UpdatePost mocks base method.
+func (m *MockPostRepository) UpdatePost(arg0 *types.Post, arg1 repository.PostEvents) (*repository.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePost", arg0, arg1)
+	ret0, _ := ret[0].(*repository.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePost indicates an expected call of UpdatePost.
+func (mr *MockPostRepositoryMockRecorder) UpdatePost(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePost", reflect.TypeOf((*MockPostRepository)(nil).UpdatePost), arg0, arg1)
+}
+
+// MockReactionRepository is a mock of ReactionRepository interface.
+type MockReactionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReactionRepositoryMockRecorder
+}
+
+// MockReactionRepositoryMockRecorder is the mock recorder for MockReactionRepository.
+type MockReactionRepositoryMockRecorder struct {
+	mock *MockReactionRepository
+}
+
+// NewMockReactionRepository creates a new mock instance.
+func NewMockReactionRepository(ctrl *gomock.Controller) *MockReactionRepository {
+	mock := &MockReactionRepository{ctrl: ctrl}
+	mock.recorder = &MockReactionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReactionRepository) EXPECT() *MockReactionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddReaction mocks base method.
+func (m *MockReactionRepository) AddReaction(arg0 *repository.Reaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddReaction", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddReaction indicates an expected call of AddReaction.
+func (mr *MockReactionRepositoryMockRecorder) AddReaction(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddReaction", reflect.TypeOf((*MockReactionRepository)(nil).AddReaction), arg0)
+}
+
+// CountReactions mocks base method.
+func (m *MockReactionRepository) CountReactions(arg0 []uint) (map[uint]map[string]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountReactions", arg0)
+	ret0, _ := ret[0].(map[uint]map[string]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountReactions indicates an expected call of CountReactions.
+func (mr *MockReactionRepositoryMockRecorder) CountReactions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountReactions", reflect.TypeOf((*MockReactionRepository)(nil).CountReactions), arg0)
+}
+
+// GetReactedEmojis mocks base method.
+func (m *MockReactionRepository) GetReactedEmojis(arg0 uint, arg1 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReactedEmojis", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReactedEmojis indicates an expected call of GetReactedEmojis.
+func (mr *MockReactionRepositoryMockRecorder) GetReactedEmojis(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReactedEmojis", reflect.TypeOf((*MockReactionRepository)(nil).GetReactedEmojis), arg0, arg1)
+}
+
+// RemoveReaction mocks base method.
+func (m *MockReactionRepository) RemoveReaction(arg0 uint, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveReaction", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveReaction indicates an expected call of RemoveReaction.
+func (mr *MockReactionRepositoryMockRecorder) RemoveReaction(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveReaction", reflect.TypeOf((*MockReactionRepository)(nil).RemoveReaction), arg0, arg1, arg2)
+}
+
+// MockRelatedRepository is a mock of RelatedRepository interface.
+type MockRelatedRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRelatedRepositoryMockRecorder
 }
 
-// MockPostRepositoryMockRecorder is the mock recorder for MockPostRepository.
-type MockPostRepositoryMockRecorder struct {
-	mock *MockPostRepository
+// MockRelatedRepositoryMockRecorder is the mock recorder for MockRelatedRepository.
+type MockRelatedRepositoryMockRecorder struct {
+	mock *MockRelatedRepository
 }
 
-// NewMockPostRepository creates a new mock instance.
-func NewMockPostRepository(ctrl *gomock.Controller) *MockPostRepository {
-	mock := &MockPostRepository{ctrl: ctrl}
-	mock.recorder = &MockPostRepositoryMockRecorder{mock}
+// NewMockRelatedRepository creates a new mock instance.
+func NewMockRelatedRepository(ctrl *gomock.Controller) *MockRelatedRepository {
+	mock := &MockRelatedRepository{ctrl: ctrl}
+	mock.recorder = &MockRelatedRepositoryMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockPostRepository) EXPECT() *MockPostRepositoryMockRecorder {
+func (m *MockRelatedRepository) EXPECT() *MockRelatedRepositoryMockRecorder {
 	return m.recorder
 }
 
-// AddPost mocks base method.
-func (m *MockPostRepository) AddPost(arg0 *types.Post, arg1 uint) (*repository.Post, error) {
+// GetRelatedPosts mocks base method.
+func (m *MockRelatedRepository) GetRelatedPosts(arg0 string) ([]repository.RelatedPost, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddPost", arg0, arg1)
-	ret0, _ := ret[0].(*repository.Post)
+	ret := m.ctrl.Call(m, "GetRelatedPosts", arg0)
+	ret0, _ := ret[0].([]repository.RelatedPost)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// AddPost indicates an expected call of AddPost.
-func (mr *MockPostRepositoryMockRecorder) AddPost(arg0, arg1 interface{}) *gomock.Call {
+// GetRelatedPosts indicates an expected call of GetRelatedPosts.
+func (mr *MockRelatedRepositoryMockRecorder) GetRelatedPosts(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPost", reflect.TypeOf((*MockPostRepository)(nil).AddPost), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRelatedPosts", reflect.TypeOf((*MockRelatedRepository)(nil).GetRelatedPosts), arg0)
 }
 
-// GetPost mocks base method.
-func (m *MockPostRepository) GetPost(arg0 string) (*repository.Post, error) {
+// SetRelatedPosts mocks base method.
+func (m *MockRelatedRepository) SetRelatedPosts(arg0 []repository.RelatedPost) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPost", arg0)
-	ret0, _ := ret[0].(*repository.Post)
+	ret := m.ctrl.Call(m, "SetRelatedPosts", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRelatedPosts indicates an expected call of SetRelatedPosts.
+func (mr *MockRelatedRepositoryMockRecorder) SetRelatedPosts(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRelatedPosts", reflect.TypeOf((*MockRelatedRepository)(nil).SetRelatedPosts), arg0)
+}
+
+// MockSeriesRepository is a mock of SeriesRepository interface.
+type MockSeriesRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSeriesRepositoryMockRecorder
+}
+
+// MockSeriesRepositoryMockRecorder is the mock recorder for MockSeriesRepository.
+type MockSeriesRepositoryMockRecorder struct {
+	mock *MockSeriesRepository
+}
+
+// NewMockSeriesRepository creates a new mock instance.
+func NewMockSeriesRepository(ctrl *gomock.Controller) *MockSeriesRepository {
+	mock := &MockSeriesRepository{ctrl: ctrl}
+	mock.recorder = &MockSeriesRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSeriesRepository) EXPECT() *MockSeriesRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddSeries mocks base method.
+func (m *MockSeriesRepository) AddSeries(arg0 *types.Series, arg1 uint) (*repository.Series, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSeries", arg0, arg1)
+	ret0, _ := ret[0].(*repository.Series)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetPost indicates an expected call of GetPost.
-func (mr *MockPostRepositoryMockRecorder) GetPost(arg0 interface{}) *gomock.Call {
+// AddSeries indicates an expected call of AddSeries.
+func (mr *MockSeriesRepositoryMockRecorder) AddSeries(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPost", reflect.TypeOf((*MockPostRepository)(nil).GetPost), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSeries", reflect.TypeOf((*MockSeriesRepository)(nil).AddSeries), arg0, arg1)
 }
 
-// GetPosts mocks base method.
-func (m *MockPostRepository) GetPosts() ([]repository.Post, error) {
+// DeleteSeries mocks base method.
+func (m *MockSeriesRepository) DeleteSeries(arg0 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPosts")
-	ret0, _ := ret[0].([]repository.Post)
+	ret := m.ctrl.Call(m, "DeleteSeries", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSeries indicates an expected call of DeleteSeries.
+func (mr *MockSeriesRepositoryMockRecorder) DeleteSeries(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSeries", reflect.TypeOf((*MockSeriesRepository)(nil).DeleteSeries), arg0)
+}
+
+// GetPostSeries mocks base method.
+func (m *MockSeriesRepository) GetPostSeries(arg0 uint) (*repository.Series, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPostSeries", arg0)
+	ret0, _ := ret[0].(*repository.Series)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetPosts indicates an expected call of GetPosts.
-func (mr *MockPostRepositoryMockRecorder) GetPosts() *gomock.Call {
+// GetPostSeries indicates an expected call of GetPostSeries.
+func (mr *MockSeriesRepositoryMockRecorder) GetPostSeries(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostSeries", reflect.TypeOf((*MockSeriesRepository)(nil).GetPostSeries), arg0)
+}
+
+// GetSeries mocks base method.
+func (m *MockSeriesRepository) GetSeries(arg0 string) (*repository.Series, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSeries", arg0)
+	ret0, _ := ret[0].(*repository.Series)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSeries indicates an expected call of GetSeries.
+func (mr *MockSeriesRepositoryMockRecorder) GetSeries(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSeries", reflect.TypeOf((*MockSeriesRepository)(nil).GetSeries), arg0)
+}
+
+// GetSeriesList mocks base method.
+func (m *MockSeriesRepository) GetSeriesList() ([]repository.Series, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSeriesList")
+	ret0, _ := ret[0].([]repository.Series)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSeriesList indicates an expected call of GetSeriesList.
+func (mr *MockSeriesRepositoryMockRecorder) GetSeriesList() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPosts", reflect.TypeOf((*MockPostRepository)(nil).GetPosts))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSeriesList", reflect.TypeOf((*MockSeriesRepository)(nil).GetSeriesList))
+}
+
+// SetSeriesPosts mocks base method.
+func (m *MockSeriesRepository) SetSeriesPosts(arg0 uint, arg1 []uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSeriesPosts", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSeriesPosts indicates an expected call of SetSeriesPosts.
+func (mr *MockSeriesRepositoryMockRecorder) SetSeriesPosts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSeriesPosts", reflect.TypeOf((*MockSeriesRepository)(nil).SetSeriesPosts), arg0, arg1)
+}
+
+// UpdateSeries mocks base method.
+func (m *MockSeriesRepository) UpdateSeries(arg0 *types.Series) (*repository.Series, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSeries", arg0)
+	ret0, _ := ret[0].(*repository.Series)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSeries indicates an expected call of UpdateSeries.
+func (mr *MockSeriesRepositoryMockRecorder) UpdateSeries(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSeries", reflect.TypeOf((*MockSeriesRepository)(nil).UpdateSeries), arg0)
 }
 
 // MockUserRepository is a mock of UserRepository interface.
@@ -104,18 +1296,18 @@ func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
 }
 
 // AddUser mocks base method.
-func (m *MockUserRepository) AddUser(arg0 *types.User) (*repository.User, error) {
+func (m *MockUserRepository) AddUser(arg0 *types.User, arg1 repository.UserEvents) (*repository.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddUser", arg0)
+	ret := m.ctrl.Call(m, "AddUser", arg0, arg1)
 	ret0, _ := ret[0].(*repository.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AddUser indicates an expected call of AddUser.
-func (mr *MockUserRepositoryMockRecorder) AddUser(arg0 interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) AddUser(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUser", reflect.TypeOf((*MockUserRepository)(nil).AddUser), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUser", reflect.TypeOf((*MockUserRepository)(nil).AddUser), arg0, arg1)
 }
 
 // GetUser mocks base method.
@@ -162,3 +1354,297 @@ func (mr *MockUserRepositoryMockRecorder) UpdateUser(arg0 interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockUserRepository)(nil).UpdateUser), arg0)
 }
+
+// MockWebhookRepository is a mock of WebhookRepository interface.
+type MockWebhookRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookRepositoryMockRecorder
+}
+
+// MockWebhookRepositoryMockRecorder is the mock recorder for MockWebhookRepository.
+type MockWebhookRepositoryMockRecorder struct {
+	mock *MockWebhookRepository
+}
+
+// NewMockWebhookRepository creates a new mock instance.
+func NewMockWebhookRepository(ctrl *gomock.Controller) *MockWebhookRepository {
+	mock := &MockWebhookRepository{ctrl: ctrl}
+	mock.recorder = &MockWebhookRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookRepository) EXPECT() *MockWebhookRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddDeliveries mocks base method.
+func (m *MockWebhookRepository) AddDeliveries(arg0 []repository.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddDeliveries", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddDeliveries indicates an expected call of AddDeliveries.
+func (mr *MockWebhookRepositoryMockRecorder) AddDeliveries(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddDeliveries", reflect.TypeOf((*MockWebhookRepository)(nil).AddDeliveries), arg0)
+}
+
+// AddWebhook mocks base method.
+func (m *MockWebhookRepository) AddWebhook(arg0 *types.Webhook) (*repository.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddWebhook", arg0)
+	ret0, _ := ret[0].(*repository.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddWebhook indicates an expected call of AddWebhook.
+func (mr *MockWebhookRepositoryMockRecorder) AddWebhook(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWebhook", reflect.TypeOf((*MockWebhookRepository)(nil).AddWebhook), arg0)
+}
+
+// ClaimDueDeliveries mocks base method.
+func (m *MockWebhookRepository) ClaimDueDeliveries(arg0 time.Time, arg1 int, arg2 time.Duration) ([]repository.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueDeliveries", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]repository.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueDeliveries indicates an expected call of ClaimDueDeliveries.
+func (mr *MockWebhookRepositoryMockRecorder) ClaimDueDeliveries(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueDeliveries", reflect.TypeOf((*MockWebhookRepository)(nil).ClaimDueDeliveries), arg0, arg1, arg2)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockWebhookRepository) DeleteWebhook(arg0 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockWebhookRepositoryMockRecorder) DeleteWebhook(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockWebhookRepository)(nil).DeleteWebhook), arg0)
+}
+
+// GetDeliveries mocks base method.
+func (m *MockWebhookRepository) GetDeliveries(arg0 uint, arg1 int) ([]repository.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveries", arg0, arg1)
+	ret0, _ := ret[0].([]repository.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveries indicates an expected call of GetDeliveries.
+func (mr *MockWebhookRepositoryMockRecorder) GetDeliveries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveries", reflect.TypeOf((*MockWebhookRepository)(nil).GetDeliveries), arg0, arg1)
+}
+
+// GetWebhook mocks base method.
+func (m *MockWebhookRepository) GetWebhook(arg0 uint) (*repository.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhook", arg0)
+	ret0, _ := ret[0].(*repository.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhook indicates an expected call of GetWebhook.
+func (mr *MockWebhookRepositoryMockRecorder) GetWebhook(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhook", reflect.TypeOf((*MockWebhookRepository)(nil).GetWebhook), arg0)
+}
+
+// GetWebhooks mocks base method.
+func (m *MockWebhookRepository) GetWebhooks() ([]repository.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooks")
+	ret0, _ := ret[0].([]repository.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooks indicates an expected call of GetWebhooks.
+func (mr *MockWebhookRepositoryMockRecorder) GetWebhooks() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooks", reflect.TypeOf((*MockWebhookRepository)(nil).GetWebhooks))
+}
+
+// UpdateDelivery mocks base method.
+func (m *MockWebhookRepository) UpdateDelivery(arg0 *repository.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDelivery", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDelivery indicates an expected call of UpdateDelivery.
+func (mr *MockWebhookRepositoryMockRecorder) UpdateDelivery(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDelivery", reflect.TypeOf((*MockWebhookRepository)(nil).UpdateDelivery), arg0)
+}
+
+// MockWebmentionRepository is a mock of WebmentionRepository interface.
+type MockWebmentionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebmentionRepositoryMockRecorder
+}
+
+// MockWebmentionRepositoryMockRecorder is the mock recorder for MockWebmentionRepository.
+type MockWebmentionRepositoryMockRecorder struct {
+	mock *MockWebmentionRepository
+}
+
+// NewMockWebmentionRepository creates a new mock instance.
+func NewMockWebmentionRepository(ctrl *gomock.Controller) *MockWebmentionRepository {
+	mock := &MockWebmentionRepository{ctrl: ctrl}
+	mock.recorder = &MockWebmentionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebmentionRepository) EXPECT() *MockWebmentionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddWebmention mocks base method.
+func (m *MockWebmentionRepository) AddWebmention(arg0 *repository.Webmention) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddWebmention", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddWebmention indicates an expected call of AddWebmention.
+func (mr *MockWebmentionRepositoryMockRecorder) AddWebmention(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWebmention", reflect.TypeOf((*MockWebmentionRepository)(nil).AddWebmention), arg0)
+}
+
+// ClaimDueDeliveries mocks base method.
+func (m *MockWebmentionRepository) ClaimDueDeliveries(arg0 time.Time, arg1 int, arg2 time.Duration) ([]repository.WebmentionDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueDeliveries", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]repository.WebmentionDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueDeliveries indicates an expected call of ClaimDueDeliveries.
+func (mr *MockWebmentionRepositoryMockRecorder) ClaimDueDeliveries(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueDeliveries", reflect.TypeOf((*MockWebmentionRepository)(nil).ClaimDueDeliveries), arg0, arg1, arg2)
+}
+
+// ClaimDueWebmentions mocks base method.
+func (m *MockWebmentionRepository) ClaimDueWebmentions(arg0 time.Time, arg1 int, arg2 time.Duration) ([]repository.Webmention, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueWebmentions", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]repository.Webmention)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueWebmentions indicates an expected call of ClaimDueWebmentions.
+func (mr *MockWebmentionRepositoryMockRecorder) ClaimDueWebmentions(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueWebmentions", reflect.TypeOf((*MockWebmentionRepository)(nil).ClaimDueWebmentions), arg0, arg1, arg2)
+}
+
+// GetDeliveryTargets mocks base method.
+func (m *MockWebmentionRepository) GetDeliveryTargets(arg0 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveryTargets", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveryTargets indicates an expected call of GetDeliveryTargets.
+func (mr *MockWebmentionRepositoryMockRecorder) GetDeliveryTargets(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveryTargets", reflect.TypeOf((*MockWebmentionRepository)(nil).GetDeliveryTargets), arg0)
+}
+
+// GetWebmention mocks base method.
+func (m *MockWebmentionRepository) GetWebmention(arg0 uint) (*repository.Webmention, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebmention", arg0)
+	ret0, _ := ret[0].(*repository.Webmention)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebmention indicates an expected call of GetWebmention.
+func (mr *MockWebmentionRepositoryMockRecorder) GetWebmention(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebmention", reflect.TypeOf((*MockWebmentionRepository)(nil).GetWebmention), arg0)
+}
+
+// GetWebmentions mocks base method.
+func (m *MockWebmentionRepository) GetWebmentions(arg0 uint, arg1 string) ([]repository.Webmention, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebmentions", arg0, arg1)
+	ret0, _ := ret[0].([]repository.Webmention)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebmentions indicates an expected call of GetWebmentions.
+func (mr *MockWebmentionRepositoryMockRecorder) GetWebmentions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebmentions", reflect.TypeOf((*MockWebmentionRepository)(nil).GetWebmentions), arg0, arg1)
+}
+
+// QueueDeliveries mocks base method.
+func (m *MockWebmentionRepository) QueueDeliveries(arg0 string, arg1 []string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueueDeliveries", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// QueueDeliveries indicates an expected call of QueueDeliveries.
+func (mr *MockWebmentionRepositoryMockRecorder) QueueDeliveries(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueueDeliveries", reflect.TypeOf((*MockWebmentionRepository)(nil).QueueDeliveries), arg0, arg1, arg2)
+}
+
+// UpdateDelivery mocks base method.
+func (m *MockWebmentionRepository) UpdateDelivery(arg0 *repository.WebmentionDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDelivery", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDelivery indicates an expected call of UpdateDelivery.
+func (mr *MockWebmentionRepositoryMockRecorder) UpdateDelivery(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDelivery", reflect.TypeOf((*MockWebmentionRepository)(nil).UpdateDelivery), arg0)
+}
+
+// UpdateWebmention mocks base method.
+func (m *MockWebmentionRepository) UpdateWebmention(arg0 *repository.Webmention) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebmention", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebmention indicates an expected call of UpdateWebmention.
+func (mr *MockWebmentionRepositoryMockRecorder) UpdateWebmention(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebmention", reflect.TypeOf((*MockWebmentionRepository)(nil).UpdateWebmention), arg0)
+}
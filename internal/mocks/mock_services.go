@@ -1,82 +1,1330 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/wlchs/blog/internal/services (interfaces: PostService,UserService)
+// Source: github.com/wlchs/blog/internal/services (interfaces: AnalyticsService,CollectionService,FederationService,FieldService,MediaService,MicropubService,NewsletterService,PostService,ReactionService,RelatedService,SeriesService,SiteService,UserService,WebhookService,WebmentionService)
 
 // Package mocks is a generated GoMock package.
 package mocks
 
 import (
+	io "io"
+	http "net/http"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	site "github.com/wlchs/blog/internal/site"
+	storage "github.com/wlchs/blog/internal/storage"
 	types "github.com/wlchs/blog/internal/types"
 )
 
+// MockAnalyticsService is a mock of AnalyticsService interface.
+type MockAnalyticsService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAnalyticsServiceMockRecorder
+}
+
+// MockAnalyticsServiceMockRecorder is the mock recorder for MockAnalyticsService.
+type MockAnalyticsServiceMockRecorder struct {
+	mock *MockAnalyticsService
+}
+
+// NewMockAnalyticsService creates a new mock instance.
+func NewMockAnalyticsService(ctrl *gomock.Controller) *MockAnalyticsService {
+	mock := &MockAnalyticsService{ctrl: ctrl}
+	mock.recorder = &MockAnalyticsServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAnalyticsService) EXPECT() *MockAnalyticsServiceMockRecorder {
+	return m.recorder
+}
+
+// GetPostStats mocks base method.
+func (m *MockAnalyticsService) GetPostStats(arg0 string, arg1 types.AnalyticsQuery, arg2 string) (types.AnalyticsStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPostStats", arg0, arg1, arg2)
+	ret0, _ := ret[0].(types.AnalyticsStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPostStats indicates an expected call of GetPostStats.
+func (mr *MockAnalyticsServiceMockRecorder) GetPostStats(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostStats", reflect.TypeOf((*MockAnalyticsService)(nil).GetPostStats), arg0, arg1, arg2)
+}
+
+// GetStats mocks base method.
+func (m *MockAnalyticsService) GetStats(arg0 types.AnalyticsQuery, arg1 string) (types.AnalyticsStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStats", arg0, arg1)
+	ret0, _ := ret[0].(types.AnalyticsStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStats indicates an expected call of GetStats.
+func (mr *MockAnalyticsServiceMockRecorder) GetStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockAnalyticsService)(nil).GetStats), arg0, arg1)
+}
+
+// RecordView mocks base method.
+func (m *MockAnalyticsService) RecordView(arg0 string, arg1 types.PageVisit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordView", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordView indicates an expected call of RecordView.
+func (mr *MockAnalyticsServiceMockRecorder) RecordView(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordView", reflect.TypeOf((*MockAnalyticsService)(nil).RecordView), arg0, arg1)
+}
+
+// RollupViews mocks base method.
+func (m *MockAnalyticsService) RollupViews() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RollupViews")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RollupViews indicates an expected call of RollupViews.
+func (mr *MockAnalyticsServiceMockRecorder) RollupViews() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RollupViews", reflect.TypeOf((*MockAnalyticsService)(nil).RollupViews))
+}
+
+// RunRollups mocks base method.
+func (m *MockAnalyticsService) RunRollups(arg0 <-chan struct{}) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RunRollups", arg0)
+}
+
+// RunRollups indicates an expected call of RunRollups.
+func (mr *MockAnalyticsServiceMockRecorder) RunRollups(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunRollups", reflect.TypeOf((*MockAnalyticsService)(nil).RunRollups), arg0)
+}
+
+// MockCollectionService is a mock of CollectionService interface.
+type MockCollectionService struct {
+	ctrl     *gomock.Controller
+	recorder *MockCollectionServiceMockRecorder
+}
+
+// MockCollectionServiceMockRecorder is the mock recorder for MockCollectionService.
+type MockCollectionServiceMockRecorder struct {
+	mock *MockCollectionService
+}
+
+// NewMockCollectionService creates a new mock instance.
+func NewMockCollectionService(ctrl *gomock.Controller) *MockCollectionService {
+	mock := &MockCollectionService{ctrl: ctrl}
+	mock.recorder = &MockCollectionServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCollectionService) EXPECT() *MockCollectionServiceMockRecorder {
+	return m.recorder
+}
+
+// AddCollection mocks base method.
+func (m *MockCollectionService) AddCollection(arg0 *types.Collection) (types.Collection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCollection", arg0)
+	ret0, _ := ret[0].(types.Collection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddCollection indicates an expected call of AddCollection.
+func (mr *MockCollectionServiceMockRecorder) AddCollection(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCollection", reflect.TypeOf((*MockCollectionService)(nil).AddCollection), arg0)
+}
+
+// DeleteCollection mocks base method.
+func (m *MockCollectionService) DeleteCollection(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCollection", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCollection indicates an expected call of DeleteCollection.
+func (mr *MockCollectionServiceMockRecorder) DeleteCollection(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCollection", reflect.TypeOf((*MockCollectionService)(nil).DeleteCollection), arg0)
+}
+
+// GetCollection mocks base method.
+func (m *MockCollectionService) GetCollection(arg0 string) (types.Collection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollection", arg0)
+	ret0, _ := ret[0].(types.Collection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCollection indicates an expected call of GetCollection.
+func (mr *MockCollectionServiceMockRecorder) GetCollection(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollection", reflect.TypeOf((*MockCollectionService)(nil).GetCollection), arg0)
+}
+
+// GetCollections mocks base method.
+func (m *MockCollectionService) GetCollections() ([]types.Collection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollections")
+	ret0, _ := ret[0].([]types.Collection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCollections indicates an expected call of GetCollections.
+func (mr *MockCollectionServiceMockRecorder) GetCollections() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollections", reflect.TypeOf((*MockCollectionService)(nil).GetCollections))
+}
+
+// SetCollectionPosts mocks base method.
+func (m *MockCollectionService) SetCollectionPosts(arg0 string, arg1 []string, arg2 string) (types.Collection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCollectionPosts", arg0, arg1, arg2)
+	ret0, _ := ret[0].(types.Collection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetCollectionPosts indicates an expected call of SetCollectionPosts.
+func (mr *MockCollectionServiceMockRecorder) SetCollectionPosts(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCollectionPosts", reflect.TypeOf((*MockCollectionService)(nil).SetCollectionPosts), arg0, arg1, arg2)
+}
+
+// UpdateCollection mocks base method.
+func (m *MockCollectionService) UpdateCollection(arg0 *types.Collection) (types.Collection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCollection", arg0)
+	ret0, _ := ret[0].(types.Collection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCollection indicates an expected call of UpdateCollection.
+func (mr *MockCollectionServiceMockRecorder) UpdateCollection(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCollection", reflect.TypeOf((*MockCollectionService)(nil).UpdateCollection), arg0)
+}
+
+// MockFederationService is a mock of FederationService interface.
+type MockFederationService struct {
+	ctrl     *gomock.Controller
+	recorder *MockFederationServiceMockRecorder
+}
+
+// MockFederationServiceMockRecorder is the mock recorder for MockFederationService.
+type MockFederationServiceMockRecorder struct {
+	mock *MockFederationService
+}
+
+// NewMockFederationService creates a new mock instance.
+func NewMockFederationService(ctrl *gomock.Controller) *MockFederationService {
+	mock := &MockFederationService{ctrl: ctrl}
+	mock.recorder = &MockFederationServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFederationService) EXPECT() *MockFederationServiceMockRecorder {
+	return m.recorder
+}
+
+// DeliverActivities mocks base method.
+func (m *MockFederationService) DeliverActivities() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeliverActivities")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeliverActivities indicates an expected call of DeliverActivities.
+func (mr *MockFederationServiceMockRecorder) DeliverActivities() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeliverActivities", reflect.TypeOf((*MockFederationService)(nil).DeliverActivities))
+}
+
+// GetActor mocks base method.
+func (m *MockFederationService) GetActor(arg0 string) (types.Actor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActor", arg0)
+	ret0, _ := ret[0].(types.Actor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActor indicates an expected call of GetActor.
+func (mr *MockFederationServiceMockRecorder) GetActor(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActor", reflect.TypeOf((*MockFederationService)(nil).GetActor), arg0)
+}
+
+// GetFollowers mocks base method.
+func (m *MockFederationService) GetFollowers(arg0 string) (types.OrderedCollection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFollowers", arg0)
+	ret0, _ := ret[0].(types.OrderedCollection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFollowers indicates an expected call of GetFollowers.
+func (mr *MockFederationServiceMockRecorder) GetFollowers(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFollowers", reflect.TypeOf((*MockFederationService)(nil).GetFollowers), arg0)
+}
+
+// GetOutbox mocks base method.
+func (m *MockFederationService) GetOutbox(arg0 string) (types.OrderedCollection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutbox", arg0)
+	ret0, _ := ret[0].(types.OrderedCollection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutbox indicates an expected call of GetOutbox.
+func (mr *MockFederationServiceMockRecorder) GetOutbox(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutbox", reflect.TypeOf((*MockFederationService)(nil).GetOutbox), arg0)
+}
+
+// GetWebFinger mocks base method.
+func (m *MockFederationService) GetWebFinger(arg0 string) (types.WebFinger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebFinger", arg0)
+	ret0, _ := ret[0].(types.WebFinger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebFinger indicates an expected call of GetWebFinger.
+func (mr *MockFederationServiceMockRecorder) GetWebFinger(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebFinger", reflect.TypeOf((*MockFederationService)(nil).GetWebFinger), arg0)
+}
+
+// ReceiveActivity mocks base method.
+func (m *MockFederationService) ReceiveActivity(arg0 string, arg1 *http.Request, arg2 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceiveActivity", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReceiveActivity indicates an expected call of ReceiveActivity.
+func (mr *MockFederationServiceMockRecorder) ReceiveActivity(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveActivity", reflect.TypeOf((*MockFederationService)(nil).ReceiveActivity), arg0, arg1, arg2)
+}
+
+// RunDeliveries mocks base method.
+func (m *MockFederationService) RunDeliveries(arg0 <-chan struct{}) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RunDeliveries", arg0)
+}
+
+// RunDeliveries indicates an expected call of RunDeliveries.
+func (mr *MockFederationServiceMockRecorder) RunDeliveries(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunDeliveries", reflect.TypeOf((*MockFederationService)(nil).RunDeliveries), arg0)
+}
+
+// MockFieldService is a mock of FieldService interface.
+type MockFieldService struct {
+	ctrl     *gomock.Controller
+	recorder *MockFieldServiceMockRecorder
+}
+
+// MockFieldServiceMockRecorder is the mock recorder for MockFieldService.
+type MockFieldServiceMockRecorder struct {
+	mock *MockFieldService
+}
+
+// NewMockFieldService creates a new mock instance.
+func NewMockFieldService(ctrl *gomock.Controller) *MockFieldService {
+	mock := &MockFieldService{ctrl: ctrl}
+	mock.recorder = &MockFieldServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFieldService) EXPECT() *MockFieldServiceMockRecorder {
+	return m.recorder
+}
+
+// AddField mocks base method.
+func (m *MockFieldService) AddField(arg0 *types.FieldDefinition) (types.FieldDefinition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddField", arg0)
+	ret0, _ := ret[0].(types.FieldDefinition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddField indicates an expected call of AddField.
+func (mr *MockFieldServiceMockRecorder) AddField(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddField", reflect.TypeOf((*MockFieldService)(nil).AddField), arg0)
+}
+
+// DeleteField mocks base method.
+func (m *MockFieldService) DeleteField(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteField", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteField indicates an expected call of DeleteField.
+func (mr *MockFieldServiceMockRecorder) DeleteField(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteField", reflect.TypeOf((*MockFieldService)(nil).DeleteField), arg0)
+}
+
+// GetFields mocks base method.
+func (m *MockFieldService) GetFields() ([]types.FieldDefinition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFields")
+	ret0, _ := ret[0].([]types.FieldDefinition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFields indicates an expected call of GetFields.
+func (mr *MockFieldServiceMockRecorder) GetFields() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFields", reflect.TypeOf((*MockFieldService)(nil).GetFields))
+}
+
+// MockMediaService is a mock of MediaService interface.
+type MockMediaService struct {
+	ctrl     *gomock.Controller
+	recorder *MockMediaServiceMockRecorder
+}
+
+// MockMediaServiceMockRecorder is the mock recorder for MockMediaService.
+type MockMediaServiceMockRecorder struct {
+	mock *MockMediaService
+}
+
+// NewMockMediaService creates a new mock instance.
+func NewMockMediaService(ctrl *gomock.Controller) *MockMediaService {
+	mock := &MockMediaService{ctrl: ctrl}
+	mock.recorder = &MockMediaServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMediaService) EXPECT() *MockMediaServiceMockRecorder {
+	return m.recorder
+}
+
+// DeleteMedia mocks base method.
+func (m *MockMediaService) DeleteMedia(arg0 uint, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMedia", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMedia indicates an expected call of DeleteMedia.
+func (mr *MockMediaServiceMockRecorder) DeleteMedia(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMedia", reflect.TypeOf((*MockMediaService)(nil).DeleteMedia), arg0, arg1)
+}
+
+// FileURL mocks base method.
+func (m *MockMediaService) FileURL(arg0 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FileURL", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FileURL indicates an expected call of FileURL.
+func (mr *MockMediaServiceMockRecorder) FileURL(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FileURL", reflect.TypeOf((*MockMediaService)(nil).FileURL), arg0)
+}
+
+// GetMedia mocks base method.
+func (m *MockMediaService) GetMedia(arg0 uint) (types.Media, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMedia", arg0)
+	ret0, _ := ret[0].(types.Media)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMedia indicates an expected call of GetMedia.
+func (mr *MockMediaServiceMockRecorder) GetMedia(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMedia", reflect.TypeOf((*MockMediaService)(nil).GetMedia), arg0)
+}
+
+// GetMediaList mocks base method.
+func (m *MockMediaService) GetMediaList() ([]types.Media, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMediaList")
+	ret0, _ := ret[0].([]types.Media)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMediaList indicates an expected call of GetMediaList.
+func (mr *MockMediaServiceMockRecorder) GetMediaList() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMediaList", reflect.TypeOf((*MockMediaService)(nil).GetMediaList))
+}
+
+// GetMediaVariant mocks base method.
+func (m *MockMediaService) GetMediaVariant(arg0 uint, arg1 string) (types.MediaVariant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMediaVariant", arg0, arg1)
+	ret0, _ := ret[0].(types.MediaVariant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMediaVariant indicates an expected call of GetMediaVariant.
+func (mr *MockMediaServiceMockRecorder) GetMediaVariant(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMediaVariant", reflect.TypeOf((*MockMediaService)(nil).GetMediaVariant), arg0, arg1)
+}
+
+// MaxUploadSize mocks base method.
+func (m *MockMediaService) MaxUploadSize() int64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxUploadSize")
+	ret0, _ := ret[0].(int64)
+	return ret0
+}
+
+// MaxUploadSize indicates an expected call of MaxUploadSize.
+func (mr *MockMediaServiceMockRecorder) MaxUploadSize() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxUploadSize", reflect.TypeOf((*MockMediaService)(nil).MaxUploadSize))
+}
+
+// OpenFile mocks base method.
+func (m *MockMediaService) OpenFile(arg0 string) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenFile", arg0)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenFile indicates an expected call of OpenFile.
+func (mr *MockMediaServiceMockRecorder) OpenFile(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenFile", reflect.TypeOf((*MockMediaService)(nil).OpenFile), arg0)
+}
+
+// UploadMedia mocks base method.
+func (m *MockMediaService) UploadMedia(arg0 io.Reader, arg1, arg2 string) (types.Media, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadMedia", arg0, arg1, arg2)
+	ret0, _ := ret[0].(types.Media)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadMedia indicates an expected call of UploadMedia.
+func (mr *MockMediaServiceMockRecorder) UploadMedia(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadMedia", reflect.TypeOf((*MockMediaService)(nil).UploadMedia), arg0, arg1, arg2)
+}
+
+// MockMicropubService is a mock of MicropubService interface.
+type MockMicropubService struct {
+	ctrl     *gomock.Controller
+	recorder *MockMicropubServiceMockRecorder
+}
+
+// MockMicropubServiceMockRecorder is the mock recorder for MockMicropubService.
+type MockMicropubServiceMockRecorder struct {
+	mock *MockMicropubService
+}
+
+// NewMockMicropubService creates a new mock instance.
+func NewMockMicropubService(ctrl *gomock.Controller) *MockMicropubService {
+	mock := &MockMicropubService{ctrl: ctrl}
+	mock.recorder = &MockMicropubServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMicropubService) EXPECT() *MockMicropubServiceMockRecorder {
+	return m.recorder
+}
+
+// CreatePost mocks base method.
+func (m *MockMicropubService) CreatePost(arg0 types.MicropubEntry, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePost", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePost indicates an expected call of CreatePost.
+func (mr *MockMicropubServiceMockRecorder) CreatePost(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePost", reflect.TypeOf((*MockMicropubService)(nil).CreatePost), arg0, arg1)
+}
+
+// DeletePost mocks base method.
+func (m *MockMicropubService) DeletePost(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePost", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePost indicates an expected call of DeletePost.
+func (mr *MockMicropubServiceMockRecorder) DeletePost(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePost", reflect.TypeOf((*MockMicropubService)(nil).DeletePost), arg0, arg1)
+}
+
+// GetConfig mocks base method.
+func (m *MockMicropubService) GetConfig() types.MicropubConfig {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConfig")
+	ret0, _ := ret[0].(types.MicropubConfig)
+	return ret0
+}
+
+// GetConfig indicates an expected call of GetConfig.
+func (mr *MockMicropubServiceMockRecorder) GetConfig() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfig", reflect.TypeOf((*MockMicropubService)(nil).GetConfig))
+}
+
+// GetSource mocks base method.
+func (m *MockMicropubService) GetSource(arg0 string, arg1 []string, arg2 string) (types.MicropubEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSource", arg0, arg1, arg2)
+	ret0, _ := ret[0].(types.MicropubEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSource indicates an expected call of GetSource.
+func (mr *MockMicropubServiceMockRecorder) GetSource(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSource", reflect.TypeOf((*MockMicropubService)(nil).GetSource), arg0, arg1, arg2)
+}
+
+// UpdatePost mocks base method.
+func (m *MockMicropubService) UpdatePost(arg0 types.MicropubUpdate, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePost", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePost indicates an expected call of UpdatePost.
+func (mr *MockMicropubServiceMockRecorder) UpdatePost(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePost", reflect.TypeOf((*MockMicropubService)(nil).UpdatePost), arg0, arg1)
+}
+
+// UploadMedia mocks base method.
+func (m *MockMicropubService) UploadMedia(arg0 io.Reader, arg1, arg2 string) (types.Media, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadMedia", arg0, arg1, arg2)
+	ret0, _ := ret[0].(types.Media)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadMedia indicates an expected call of UploadMedia.
+func (mr *MockMicropubServiceMockRecorder) UploadMedia(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadMedia", reflect.TypeOf((*MockMicropubService)(nil).UploadMedia), arg0, arg1, arg2)
+}
+
+// MockNewsletterService is a mock of NewsletterService interface.
+type MockNewsletterService struct {
+	ctrl     *gomock.Controller
+	recorder *MockNewsletterServiceMockRecorder
+}
+
+// MockNewsletterServiceMockRecorder is the mock recorder for MockNewsletterService.
+type MockNewsletterServiceMockRecorder struct {
+	mock *MockNewsletterService
+}
+
+// NewMockNewsletterService creates a new mock instance.
+func NewMockNewsletterService(ctrl *gomock.Controller) *MockNewsletterService {
+	mock := &MockNewsletterService{ctrl: ctrl}
+	mock.recorder = &MockNewsletterServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNewsletterService) EXPECT() *MockNewsletterServiceMockRecorder {
+	return m.recorder
+}
+
+// ConfirmSubscription mocks base method.
+func (m *MockNewsletterService) ConfirmSubscription(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmSubscription", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmSubscription indicates an expected call of ConfirmSubscription.
+func (mr *MockNewsletterServiceMockRecorder) ConfirmSubscription(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmSubscription", reflect.TypeOf((*MockNewsletterService)(nil).ConfirmSubscription), arg0)
+}
+
+// RunNewsletters mocks base method.
+func (m *MockNewsletterService) RunNewsletters(arg0 <-chan struct{}) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RunNewsletters", arg0)
+}
+
+// RunNewsletters indicates an expected call of RunNewsletters.
+func (mr *MockNewsletterServiceMockRecorder) RunNewsletters(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunNewsletters", reflect.TypeOf((*MockNewsletterService)(nil).RunNewsletters), arg0)
+}
+
+// SendNewsletters mocks base method.
+func (m *MockNewsletterService) SendNewsletters() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendNewsletters")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendNewsletters indicates an expected call of SendNewsletters.
+func (mr *MockNewsletterServiceMockRecorder) SendNewsletters() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendNewsletters", reflect.TypeOf((*MockNewsletterService)(nil).SendNewsletters))
+}
+
+// Subscribe mocks base method.
+func (m *MockNewsletterService) Subscribe(arg0 *types.SubscriptionInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockNewsletterServiceMockRecorder) Subscribe(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockNewsletterService)(nil).Subscribe), arg0)
+}
+
+// Unsubscribe mocks base method.
+func (m *MockNewsletterService) Unsubscribe(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unsubscribe", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unsubscribe indicates an expected call of Unsubscribe.
+func (mr *MockNewsletterServiceMockRecorder) Unsubscribe(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsubscribe", reflect.TypeOf((*MockNewsletterService)(nil).Unsubscribe), arg0)
+}
+
 // MockPostService is a mock of PostService interface.
 type MockPostService struct {
 	ctrl     *gomock.Controller
-	recorder *MockPostServiceMockRecorder
+	recorder *MockPostServiceMockRecorder
+}
+
+// MockPostServiceMockRecorder is the mock recorder for MockPostService.
+type MockPostServiceMockRecorder struct {
+	mock *MockPostService
+}
+
+// NewMockPostService creates a new mock instance.
+func NewMockPostService(ctrl *gomock.Controller) *MockPostService {
+	mock := &MockPostService{ctrl: ctrl}
+	mock.recorder = &MockPostServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPostService) EXPECT() *MockPostServiceMockRecorder {
+	return m.recorder
+}
+
+// AddPost mocks base method.
+func (m *MockPostService) AddPost(arg0 *types.Post) (types.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPost", arg0)
+	ret0, _ := ret[0].(types.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPost indicates an expected call of AddPost.
+func (mr *MockPostServiceMockRecorder) AddPost(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPost", reflect.TypeOf((*MockPostService)(nil).AddPost), arg0)
+}
+
+// AuthorizePostAccess mocks base method.
+func (m *MockPostService) AuthorizePostAccess(arg0, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthorizePostAccess", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthorizePostAccess indicates an expected call of AuthorizePostAccess.
+func (mr *MockPostServiceMockRecorder) AuthorizePostAccess(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthorizePostAccess", reflect.TypeOf((*MockPostService)(nil).AuthorizePostAccess), arg0, arg1)
+}
+
+// DeletePost mocks base method.
+func (m *MockPostService) DeletePost(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePost", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePost indicates an expected call of DeletePost.
+func (mr *MockPostServiceMockRecorder) DeletePost(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePost", reflect.TypeOf((*MockPostService)(nil).DeletePost), arg0, arg1)
+}
+
+// DeletePostTranslation mocks base method.
+func (m *MockPostService) DeletePostTranslation(arg0, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePostTranslation", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePostTranslation indicates an expected call of DeletePostTranslation.
+func (mr *MockPostServiceMockRecorder) DeletePostTranslation(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePostTranslation", reflect.TypeOf((*MockPostService)(nil).DeletePostTranslation), arg0, arg1, arg2)
+}
+
+// GetAllPosts mocks base method.
+func (m *MockPostService) GetAllPosts() ([]types.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllPosts")
+	ret0, _ := ret[0].([]types.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllPosts indicates an expected call of GetAllPosts.
+func (mr *MockPostServiceMockRecorder) GetAllPosts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllPosts", reflect.TypeOf((*MockPostService)(nil).GetAllPosts))
+}
+
+// GetPost mocks base method.
+func (m *MockPostService) GetPost(arg0 string, arg1 types.PostAccess, arg2 []string) (types.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPost", arg0, arg1, arg2)
+	ret0, _ := ret[0].(types.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPost indicates an expected call of GetPost.
+func (mr *MockPostServiceMockRecorder) GetPost(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPost", reflect.TypeOf((*MockPostService)(nil).GetPost), arg0, arg1, arg2)
+}
+
+// GetPosts mocks base method.
+func (m *MockPostService) GetPosts(arg0 types.PostFilter) ([]types.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPosts", arg0)
+	ret0, _ := ret[0].([]types.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPosts indicates an expected call of GetPosts.
+func (mr *MockPostServiceMockRecorder) GetPosts(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPosts", reflect.TypeOf((*MockPostService)(nil).GetPosts), arg0)
+}
+
+// SetPostContributors mocks base method.
+func (m *MockPostService) SetPostContributors(arg0 string, arg1 []types.Contributor, arg2 string) (types.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPostContributors", arg0, arg1, arg2)
+	ret0, _ := ret[0].(types.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetPostContributors indicates an expected call of SetPostContributors.
+func (mr *MockPostServiceMockRecorder) SetPostContributors(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPostContributors", reflect.TypeOf((*MockPostService)(nil).SetPostContributors), arg0, arg1, arg2)
+}
+
+// SetPostHighlight mocks base method.
+func (m *MockPostService) SetPostHighlight(arg0 string, arg1 types.PostHighlight, arg2 string) (types.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPostHighlight", arg0, arg1, arg2)
+	ret0, _ := ret[0].(types.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetPostHighlight indicates an expected call of SetPostHighlight.
+func (mr *MockPostServiceMockRecorder) SetPostHighlight(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPostHighlight", reflect.TypeOf((*MockPostService)(nil).SetPostHighlight), arg0, arg1, arg2)
+}
+
+// SetPostTranslation mocks base method.
+func (m *MockPostService) SetPostTranslation(arg0 string, arg1 types.PostTranslation, arg2 string) (types.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPostTranslation", arg0, arg1, arg2)
+	ret0, _ := ret[0].(types.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetPostTranslation indicates an expected call of SetPostTranslation.
+func (mr *MockPostServiceMockRecorder) SetPostTranslation(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPostTranslation", reflect.TypeOf((*MockPostService)(nil).SetPostTranslation), arg0, arg1, arg2)
+}
+
+// UpdatePost mocks base method.
+func (m *MockPostService) UpdatePost(arg0 *types.Post, arg1 string) (types.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePost", arg0, arg1)
+	ret0, _ := ret[0].(types.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePost indicates an expected call of UpdatePost.
+func (mr *MockPostServiceMockRecorder) UpdatePost(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePost", reflect.TypeOf((*MockPostService)(nil).UpdatePost), arg0, arg1)
+}
+
+// MockReactionService is a mock of ReactionService interface.
+type MockReactionService struct {
+	ctrl     *gomock.Controller
+	recorder *MockReactionServiceMockRecorder
+}
+
+// MockReactionServiceMockRecorder is the mock recorder for MockReactionService.
+type MockReactionServiceMockRecorder struct {
+	mock *MockReactionService
+}
+
+// NewMockReactionService creates a new mock instance.
+func NewMockReactionService(ctrl *gomock.Controller) *MockReactionService {
+	mock := &MockReactionService{ctrl: ctrl}
+	mock.recorder = &MockReactionServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReactionService) EXPECT() *MockReactionServiceMockRecorder {
+	return m.recorder
+}
+
+// AddReaction mocks base method.
+func (m *MockReactionService) AddReaction(arg0, arg1 string, arg2 types.ReactionReader) (types.PostReactions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddReaction", arg0, arg1, arg2)
+	ret0, _ := ret[0].(types.PostReactions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddReaction indicates an expected call of AddReaction.
+func (mr *MockReactionServiceMockRecorder) AddReaction(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddReaction", reflect.TypeOf((*MockReactionService)(nil).AddReaction), arg0, arg1, arg2)
+}
+
+// GetReactions mocks base method.
+func (m *MockReactionService) GetReactions(arg0 string, arg1 types.ReactionReader) (types.PostReactions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReactions", arg0, arg1)
+	ret0, _ := ret[0].(types.PostReactions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReactions indicates an expected call of GetReactions.
+func (mr *MockReactionServiceMockRecorder) GetReactions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReactions", reflect.TypeOf((*MockReactionService)(nil).GetReactions), arg0, arg1)
+}
+
+// RemoveReaction mocks base method.
+func (m *MockReactionService) RemoveReaction(arg0, arg1 string, arg2 types.ReactionReader) (types.PostReactions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveReaction", arg0, arg1, arg2)
+	ret0, _ := ret[0].(types.PostReactions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveReaction indicates an expected call of RemoveReaction.
+func (mr *MockReactionServiceMockRecorder) RemoveReaction(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveReaction", reflect.TypeOf((*MockReactionService)(nil).RemoveReaction), arg0, arg1, arg2)
+}
+
+// MockRelatedService is a mock of RelatedService interface.
+type MockRelatedService struct {
+	ctrl     *gomock.Controller
+	recorder *MockRelatedServiceMockRecorder
+}
+
+// MockRelatedServiceMockRecorder is the mock recorder for MockRelatedService.
+type MockRelatedServiceMockRecorder struct {
+	mock *MockRelatedService
+}
+
+// NewMockRelatedService creates a new mock instance.
+func NewMockRelatedService(ctrl *gomock.Controller) *MockRelatedService {
+	mock := &MockRelatedService{ctrl: ctrl}
+	mock.recorder = &MockRelatedServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRelatedService) EXPECT() *MockRelatedServiceMockRecorder {
+	return m.recorder
+}
+
+// GetRelatedPosts mocks base method.
+func (m *MockRelatedService) GetRelatedPosts(arg0 string, arg1 int) ([]types.RelatedPost, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRelatedPosts", arg0, arg1)
+	ret0, _ := ret[0].([]types.RelatedPost)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRelatedPosts indicates an expected call of GetRelatedPosts.
+func (mr *MockRelatedServiceMockRecorder) GetRelatedPosts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRelatedPosts", reflect.TypeOf((*MockRelatedService)(nil).GetRelatedPosts), arg0, arg1)
+}
+
+// RefreshRelatedPosts mocks base method.
+func (m *MockRelatedService) RefreshRelatedPosts() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshRelatedPosts")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefreshRelatedPosts indicates an expected call of RefreshRelatedPosts.
+func (mr *MockRelatedServiceMockRecorder) RefreshRelatedPosts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshRelatedPosts", reflect.TypeOf((*MockRelatedService)(nil).RefreshRelatedPosts))
+}
+
+// RunRelatedPosts mocks base method.
+func (m *MockRelatedService) RunRelatedPosts(arg0 <-chan struct{}) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RunRelatedPosts", arg0)
+}
+
+// RunRelatedPosts indicates an expected call of RunRelatedPosts.
+func (mr *MockRelatedServiceMockRecorder) RunRelatedPosts(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunRelatedPosts", reflect.TypeOf((*MockRelatedService)(nil).RunRelatedPosts), arg0)
+}
+
+// MockSeriesService is a mock of SeriesService interface.
+type MockSeriesService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSeriesServiceMockRecorder
 }
 
-// MockPostServiceMockRecorder is the mock recorder for MockPostService.
-type MockPostServiceMockRecorder struct {
-	mock *MockPostService
+// MockSeriesServiceMockRecorder is the mock recorder for MockSeriesService.
+type MockSeriesServiceMockRecorder struct {
+	mock *MockSeriesService
 }
 
-// NewMockPostService creates a new mock instance.
-func NewMockPostService(ctrl *gomock.Controller) *MockPostService {
-	mock := &MockPostService{ctrl: ctrl}
-	mock.recorder = &MockPostServiceMockRecorder{mock}
+// NewMockSeriesService creates a new mock instance.
+func NewMockSeriesService(ctrl *gomock.Controller) *MockSeriesService {
+	mock := &MockSeriesService{ctrl: ctrl}
+	mock.recorder = &MockSeriesServiceMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockPostService) EXPECT() *MockPostServiceMockRecorder {
+func (m *MockSeriesService) EXPECT() *MockSeriesServiceMockRecorder {
 	return m.recorder
 }
 
-// AddPost mocks base method.
-func (m *MockPostService) AddPost(arg0 *types.Post) (types.Post, error) {
+// AddSeries mocks base method.
+func (m *MockSeriesService) AddSeries(arg0 *types.Series, arg1 string) (types.Series, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddPost", arg0)
-	ret0, _ := ret[0].(types.Post)
+	ret := m.ctrl.Call(m, "AddSeries", arg0, arg1)
+	ret0, _ := ret[0].(types.Series)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// AddPost indicates an expected call of AddPost.
-func (mr *MockPostServiceMockRecorder) AddPost(arg0 interface{}) *gomock.Call {
+// AddSeries indicates an expected call of AddSeries.
+func (mr *MockSeriesServiceMockRecorder) AddSeries(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPost", reflect.TypeOf((*MockPostService)(nil).AddPost), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSeries", reflect.TypeOf((*MockSeriesService)(nil).AddSeries), arg0, arg1)
 }
 
-// GetPost mocks base method.
-func (m *MockPostService) GetPost(arg0 string) (types.Post, error) {
+// DeleteSeries mocks base method.
+func (m *MockSeriesService) DeleteSeries(arg0, arg1 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPost", arg0)
-	ret0, _ := ret[0].(types.Post)
+	ret := m.ctrl.Call(m, "DeleteSeries", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSeries indicates an expected call of DeleteSeries.
+func (mr *MockSeriesServiceMockRecorder) DeleteSeries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSeries", reflect.TypeOf((*MockSeriesService)(nil).DeleteSeries), arg0, arg1)
+}
+
+// GetSeries mocks base method.
+func (m *MockSeriesService) GetSeries(arg0 string) (types.Series, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSeries", arg0)
+	ret0, _ := ret[0].(types.Series)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetPost indicates an expected call of GetPost.
-func (mr *MockPostServiceMockRecorder) GetPost(arg0 interface{}) *gomock.Call {
+// GetSeries indicates an expected call of GetSeries.
+func (mr *MockSeriesServiceMockRecorder) GetSeries(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPost", reflect.TypeOf((*MockPostService)(nil).GetPost), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSeries", reflect.TypeOf((*MockSeriesService)(nil).GetSeries), arg0)
 }
 
-// GetPosts mocks base method.
-func (m *MockPostService) GetPosts() ([]types.Post, error) {
+// GetSeriesList mocks base method.
+func (m *MockSeriesService) GetSeriesList() ([]types.Series, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPosts")
-	ret0, _ := ret[0].([]types.Post)
+	ret := m.ctrl.Call(m, "GetSeriesList")
+	ret0, _ := ret[0].([]types.Series)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetPosts indicates an expected call of GetPosts.
-func (mr *MockPostServiceMockRecorder) GetPosts() *gomock.Call {
+// GetSeriesList indicates an expected call of GetSeriesList.
+func (mr *MockSeriesServiceMockRecorder) GetSeriesList() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSeriesList", reflect.TypeOf((*MockSeriesService)(nil).GetSeriesList))
+}
+
+// SetSeriesPosts mocks base method.
+func (m *MockSeriesService) SetSeriesPosts(arg0 string, arg1 []string, arg2 string) (types.Series, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSeriesPosts", arg0, arg1, arg2)
+	ret0, _ := ret[0].(types.Series)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetSeriesPosts indicates an expected call of SetSeriesPosts.
+func (mr *MockSeriesServiceMockRecorder) SetSeriesPosts(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSeriesPosts", reflect.TypeOf((*MockSeriesService)(nil).SetSeriesPosts), arg0, arg1, arg2)
+}
+
+// UpdateSeries mocks base method.
+func (m *MockSeriesService) UpdateSeries(arg0 *types.Series, arg1 string) (types.Series, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSeries", arg0, arg1)
+	ret0, _ := ret[0].(types.Series)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSeries indicates an expected call of UpdateSeries.
+func (mr *MockSeriesServiceMockRecorder) UpdateSeries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSeries", reflect.TypeOf((*MockSeriesService)(nil).UpdateSeries), arg0, arg1)
+}
+
+// MockSiteService is a mock of SiteService interface.
+type MockSiteService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSiteServiceMockRecorder
+}
+
+// MockSiteServiceMockRecorder is the mock recorder for MockSiteService.
+type MockSiteServiceMockRecorder struct {
+	mock *MockSiteService
+}
+
+// NewMockSiteService creates a new mock instance.
+func NewMockSiteService(ctrl *gomock.Controller) *MockSiteService {
+	mock := &MockSiteService{ctrl: ctrl}
+	mock.recorder = &MockSiteServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSiteService) EXPECT() *MockSiteServiceMockRecorder {
+	return m.recorder
+}
+
+// Build mocks base method.
+func (m *MockSiteService) Build(arg0 storage.Storage, arg1 bool) (types.BuildReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Build", arg0, arg1)
+	ret0, _ := ret[0].(types.BuildReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Build indicates an expected call of Build.
+func (mr *MockSiteServiceMockRecorder) Build(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Build", reflect.TypeOf((*MockSiteService)(nil).Build), arg0, arg1)
+}
+
+// GetAuthorPage mocks base method.
+func (m *MockSiteService) GetAuthorPage(arg0 string) (site.AuthorPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuthorPage", arg0)
+	ret0, _ := ret[0].(site.AuthorPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuthorPage indicates an expected call of GetAuthorPage.
+func (mr *MockSiteServiceMockRecorder) GetAuthorPage(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthorPage", reflect.TypeOf((*MockSiteService)(nil).GetAuthorPage), arg0)
+}
+
+// GetFeed mocks base method.
+func (m *MockSiteService) GetFeed() (site.Feed, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeed")
+	ret0, _ := ret[0].(site.Feed)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeed indicates an expected call of GetFeed.
+func (mr *MockSiteServiceMockRecorder) GetFeed() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeed", reflect.TypeOf((*MockSiteService)(nil).GetFeed))
+}
+
+// GetIndexPage mocks base method.
+func (m *MockSiteService) GetIndexPage(arg0 int) (site.IndexPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIndexPage", arg0)
+	ret0, _ := ret[0].(site.IndexPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIndexPage indicates an expected call of GetIndexPage.
+func (mr *MockSiteServiceMockRecorder) GetIndexPage(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIndexPage", reflect.TypeOf((*MockSiteService)(nil).GetIndexPage), arg0)
+}
+
+// GetJSONFeed mocks base method.
+func (m *MockSiteService) GetJSONFeed(arg0 int) (site.JSONFeedPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJSONFeed", arg0)
+	ret0, _ := ret[0].(site.JSONFeedPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJSONFeed indicates an expected call of GetJSONFeed.
+func (mr *MockSiteServiceMockRecorder) GetJSONFeed(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJSONFeed", reflect.TypeOf((*MockSiteService)(nil).GetJSONFeed), arg0)
+}
+
+// GetPostPage mocks base method.
+func (m *MockSiteService) GetPostPage(arg0 string, arg1 types.PostAccess, arg2 []string) (site.PostPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPostPage", arg0, arg1, arg2)
+	ret0, _ := ret[0].(site.PostPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPostPage indicates an expected call of GetPostPage.
+func (mr *MockSiteServiceMockRecorder) GetPostPage(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostPage", reflect.TypeOf((*MockSiteService)(nil).GetPostPage), arg0, arg1, arg2)
+}
+
+// GetRobots mocks base method.
+func (m *MockSiteService) GetRobots() (site.Robots, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRobots")
+	ret0, _ := ret[0].(site.Robots)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRobots indicates an expected call of GetRobots.
+func (mr *MockSiteServiceMockRecorder) GetRobots() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRobots", reflect.TypeOf((*MockSiteService)(nil).GetRobots))
+}
+
+// GetSitemap mocks base method.
+func (m *MockSiteService) GetSitemap() (site.Sitemap, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSitemap")
+	ret0, _ := ret[0].(site.Sitemap)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSitemap indicates an expected call of GetSitemap.
+func (mr *MockSiteServiceMockRecorder) GetSitemap() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPosts", reflect.TypeOf((*MockPostService)(nil).GetPosts))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSitemap", reflect.TypeOf((*MockSiteService)(nil).GetSitemap))
 }
 
 // MockUserService is a mock of UserService interface.
@@ -161,6 +1409,20 @@ func (mr *MockUserServiceMockRecorder) GetUsers() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockUserService)(nil).GetUsers))
 }
 
+// IsAdmin mocks base method.
+func (m *MockUserService) IsAdmin(arg0 string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsAdmin", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsAdmin indicates an expected call of IsAdmin.
+func (mr *MockUserServiceMockRecorder) IsAdmin(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAdmin", reflect.TypeOf((*MockUserService)(nil).IsAdmin), arg0)
+}
+
 // RegisterFirstUser mocks base method.
 func (m *MockUserService) RegisterFirstUser() error {
 	m.ctrl.T.Helper()
@@ -204,3 +1466,221 @@ func (mr *MockUserServiceMockRecorder) UpdateUser(arg0, arg1 interface{}) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockUserService)(nil).UpdateUser), arg0, arg1)
 }
+
+// MockWebhookService is a mock of WebhookService interface.
+type MockWebhookService struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookServiceMockRecorder
+}
+
+// MockWebhookServiceMockRecorder is the mock recorder for MockWebhookService.
+type MockWebhookServiceMockRecorder struct {
+	mock *MockWebhookService
+}
+
+// NewMockWebhookService creates a new mock instance.
+func NewMockWebhookService(ctrl *gomock.Controller) *MockWebhookService {
+	mock := &MockWebhookService{ctrl: ctrl}
+	mock.recorder = &MockWebhookServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookService) EXPECT() *MockWebhookServiceMockRecorder {
+	return m.recorder
+}
+
+// AddWebhook mocks base method.
+func (m *MockWebhookService) AddWebhook(arg0 *types.Webhook) (types.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddWebhook", arg0)
+	ret0, _ := ret[0].(types.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddWebhook indicates an expected call of AddWebhook.
+func (mr *MockWebhookServiceMockRecorder) AddWebhook(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWebhook", reflect.TypeOf((*MockWebhookService)(nil).AddWebhook), arg0)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockWebhookService) DeleteWebhook(arg0 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockWebhookServiceMockRecorder) DeleteWebhook(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockWebhookService)(nil).DeleteWebhook), arg0)
+}
+
+// DeliverWebhooks mocks base method.
+func (m *MockWebhookService) DeliverWebhooks() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeliverWebhooks")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeliverWebhooks indicates an expected call of DeliverWebhooks.
+func (mr *MockWebhookServiceMockRecorder) DeliverWebhooks() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeliverWebhooks", reflect.TypeOf((*MockWebhookService)(nil).DeliverWebhooks))
+}
+
+// GetDeliveries mocks base method.
+func (m *MockWebhookService) GetDeliveries(arg0 uint) ([]types.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveries", arg0)
+	ret0, _ := ret[0].([]types.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveries indicates an expected call of GetDeliveries.
+func (mr *MockWebhookServiceMockRecorder) GetDeliveries(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveries", reflect.TypeOf((*MockWebhookService)(nil).GetDeliveries), arg0)
+}
+
+// GetWebhooks mocks base method.
+func (m *MockWebhookService) GetWebhooks() ([]types.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooks")
+	ret0, _ := ret[0].([]types.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooks indicates an expected call of GetWebhooks.
+func (mr *MockWebhookServiceMockRecorder) GetWebhooks() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooks", reflect.TypeOf((*MockWebhookService)(nil).GetWebhooks))
+}
+
+// RunDeliveries mocks base method.
+func (m *MockWebhookService) RunDeliveries(arg0 <-chan struct{}) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RunDeliveries", arg0)
+}
+
+// RunDeliveries indicates an expected call of RunDeliveries.
+func (mr *MockWebhookServiceMockRecorder) RunDeliveries(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunDeliveries", reflect.TypeOf((*MockWebhookService)(nil).RunDeliveries), arg0)
+}
+
+// MockWebmentionService is a mock of WebmentionService interface.
+type MockWebmentionService struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebmentionServiceMockRecorder
+}
+
+// MockWebmentionServiceMockRecorder is the mock recorder for MockWebmentionService.
+type MockWebmentionServiceMockRecorder struct {
+	mock *MockWebmentionService
+}
+
+// NewMockWebmentionService creates a new mock instance.
+func NewMockWebmentionService(ctrl *gomock.Controller) *MockWebmentionService {
+	mock := &MockWebmentionService{ctrl: ctrl}
+	mock.recorder = &MockWebmentionServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebmentionService) EXPECT() *MockWebmentionServiceMockRecorder {
+	return m.recorder
+}
+
+// GetWebmentions mocks base method.
+func (m *MockWebmentionService) GetWebmentions(arg0, arg1, arg2 string) ([]types.Webmention, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebmentions", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]types.Webmention)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebmentions indicates an expected call of GetWebmentions.
+func (mr *MockWebmentionServiceMockRecorder) GetWebmentions(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebmentions", reflect.TypeOf((*MockWebmentionService)(nil).GetWebmentions), arg0, arg1, arg2)
+}
+
+// ModerateWebmention mocks base method.
+func (m *MockWebmentionService) ModerateWebmention(arg0 uint, arg1, arg2 string) (types.Webmention, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ModerateWebmention", arg0, arg1, arg2)
+	ret0, _ := ret[0].(types.Webmention)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ModerateWebmention indicates an expected call of ModerateWebmention.
+func (mr *MockWebmentionServiceMockRecorder) ModerateWebmention(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModerateWebmention", reflect.TypeOf((*MockWebmentionService)(nil).ModerateWebmention), arg0, arg1, arg2)
+}
+
+// ReceiveWebmention mocks base method.
+func (m *MockWebmentionService) ReceiveWebmention(arg0, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceiveWebmention", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReceiveWebmention indicates an expected call of ReceiveWebmention.
+func (mr *MockWebmentionServiceMockRecorder) ReceiveWebmention(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveWebmention", reflect.TypeOf((*MockWebmentionService)(nil).ReceiveWebmention), arg0, arg1, arg2)
+}
+
+// RunWebmentions mocks base method.
+func (m *MockWebmentionService) RunWebmentions(arg0 <-chan struct{}) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RunWebmentions", arg0)
+}
+
+// RunWebmentions indicates an expected call of RunWebmentions.
+func (mr *MockWebmentionServiceMockRecorder) RunWebmentions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunWebmentions", reflect.TypeOf((*MockWebmentionService)(nil).RunWebmentions), arg0)
+}
+
+// SendWebmentions mocks base method.
+func (m *MockWebmentionService) SendWebmentions() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendWebmentions")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendWebmentions indicates an expected call of SendWebmentions.
+func (mr *MockWebmentionServiceMockRecorder) SendWebmentions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendWebmentions", reflect.TypeOf((*MockWebmentionService)(nil).SendWebmentions))
+}
+
+// VerifyWebmentions mocks base method.
+func (m *MockWebmentionService) VerifyWebmentions() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyWebmentions")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyWebmentions indicates an expected call of VerifyWebmentions.
+func (mr *MockWebmentionServiceMockRecorder) VerifyWebmentions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyWebmentions", reflect.TypeOf((*MockWebmentionService)(nil).VerifyWebmentions))
+}
@@ -0,0 +1,301 @@
+package repository
+
+import (
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/types"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// PageView DB schema. Stores the views of the posts until they are rolled up and expire.
+// Visitors are identified by a hash salted daily, neither their address nor a cookie is stored.
+// The referrer only contains the host of the referring page, direct visits have an empty referrer.
+type PageView struct {
+	ID       uint      `gorm:"primaryKey;autoIncrement"`
+	PostID   uint      `gorm:"not null;index"`
+	Visitor  string    `gorm:"not null;size:64"`
+	Referrer string    `gorm:"not null;size:255"`
+	Hour     time.Time `gorm:"not null;index"`
+	Day      time.Time `gorm:"not null;index"`
+}
+
+// ViewRollup DB schema. Stores the number of views and unique visitors of a post per hour or day.
+type ViewRollup struct {
+	PostID   uint      `gorm:"primaryKey;autoIncrement:false"`
+	Period   string    `gorm:"primaryKey;size:4"`
+	Start    time.Time `gorm:"primaryKey"`
+	Views    int       `gorm:"not null"`
+	Visitors int       `gorm:"not null"`
+}
+
+// ReferrerRollup DB schema. Stores the number of views and unique visitors of a post per referring host and day.
+type ReferrerRollup struct {
+	PostID   uint      `gorm:"primaryKey;autoIncrement:false"`
+	Day      time.Time `gorm:"primaryKey"`
+	Host     string    `gorm:"primaryKey;size:255"`
+	Views    int       `gorm:"not null"`
+	Visitors int       `gorm:"not null"`
+}
+
+// VisitorSalt DB schema. Stores the random salt of the visitor hashes of a day, so every instance of the blog engine
+// recognizes the same visitors. The salts of the past days are deleted, so their hashes can't be recomputed.
+type VisitorSalt struct {
+	Day  time.Time `gorm:"primaryKey"`
+	Salt []byte    `gorm:"not null;size:32"`
+}
+
+// AnalyticsRepository interface defining the database operations of the page-view analytics.
+type AnalyticsRepository interface {
+	AddPageView(urlHandle string, view *PageView) error
+	AddVisitorSalt(day time.Time, salt []byte) ([]byte, error)
+	DeletePageViews(before time.Time) (int64, error)
+	DeleteVisitorSalts(before time.Time) error
+	GetTopPosts(postIDs []uint, from time.Time, to time.Time, limit int) ([]ViewRollup, error)
+	GetTopReferrers(postIDs []uint, from time.Time, to time.Time, limit int) ([]ReferrerRollup, error)
+	GetViewSeries(postIDs []uint, period string, from time.Time, to time.Time) ([]ViewRollup, error)
+	RollupPageViews(since time.Time) error
+}
+
+// analyticsRepository is the concrete implementation of the AnalyticsRepository interface.
+type analyticsRepository struct {
+	logger     *zap.SugaredLogger
+	repository Repository
+}
+
+// CreateAnalyticsRepository instantiates the analyticsRepository
+func CreateAnalyticsRepository(logger *zap.SugaredLogger, repository Repository) AnalyticsRepository {
+	initAnalyticsModel(logger, repository)
+
+	return &analyticsRepository{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// initAnalyticsModel initializes the PageView, ViewRollup, ReferrerRollup and VisitorSalt schemas in the database
+func initAnalyticsModel(logger *zap.SugaredLogger, repository Repository) {
+	if err := repository.AutoMigrate(&PageView{}); err != nil {
+		logger.Errorf("failed to initialize page view model: %v", err)
+	}
+	if err := repository.AutoMigrate(&ViewRollup{}); err != nil {
+		logger.Errorf("failed to initialize view rollup model: %v", err)
+	}
+	if err := repository.AutoMigrate(&ReferrerRollup{}); err != nil {
+		logger.Errorf("failed to initialize referrer rollup model: %v", err)
+	}
+	if err := repository.AutoMigrate(&VisitorSalt{}); err != nil {
+		logger.Errorf("failed to initialize visitor salt model: %v", err)
+	}
+}
+
+// AddPageView stores a view of the post with the given URL-handle.
+func (a analyticsRepository) AddPageView(urlHandle string, view *PageView) error {
+	log := a.logger
+	repo := a.repository
+
+	var post Post
+	if result := repo.Where("url_handle = ?", urlHandle).Select("id").Take(&post); result.Error != nil {
+		log.Debugf("failed to retrieve viewed post %s, error: %v", urlHandle, result.Error)
+		if result.Error.Error() == "record not found" {
+			return errortypes.PostNotFoundError{Post: types.Post{URLHandle: urlHandle}}
+		}
+		return result.Error
+	}
+
+	view.PostID = post.ID
+	if result := repo.Create(view); result.Error != nil {
+		log.Debugf("failed to add view of post %d, error: %v", post.ID, result.Error)
+		return result.Error
+	}
+
+	return nil
+}
+
+// AddVisitorSalt stores the salt of the visitor hashes of the given day, unless another instance stored one first.
+// The stored salt of the day is returned.
+func (a analyticsRepository) AddVisitorSalt(day time.Time, salt []byte) ([]byte, error) {
+	log := a.logger
+	repo := a.repository
+
+	var stored VisitorSalt
+	err := repo.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&VisitorSalt{Day: day, Salt: salt}).Error; err != nil {
+			return err
+		}
+		return tx.Where("day = ?", day).Take(&stored).Error
+	})
+	if err != nil {
+		log.Debugf("failed to add visitor salt of %v, error: %v", day, err)
+		return nil, err
+	}
+
+	return stored.Salt, nil
+}
+
+// DeletePageViews removes the views recorded before the given hour and returns their number.
+func (a analyticsRepository) DeletePageViews(before time.Time) (int64, error) {
+	log := a.logger
+	repo := a.repository
+
+	result := repo.Where("hour < ?", before).Delete(&PageView{})
+	if result.Error != nil {
+		log.Debugf("failed to delete views before %v, error: %v", before, result.Error)
+		return 0, result.Error
+	}
+
+	log.Debugf("deleted %d views before %v", result.RowsAffected, before)
+	return result.RowsAffected, nil
+}
+
+// DeleteVisitorSalts removes the salts of the days before the given one.
+func (a analyticsRepository) DeleteVisitorSalts(before time.Time) error {
+	log := a.logger
+	repo := a.repository
+
+	result := repo.Where("day < ?", before).Delete(&VisitorSalt{})
+	if result.Error != nil {
+		log.Debugf("failed to delete visitor salts before %v, error: %v", before, result.Error)
+		return result.Error
+	}
+
+	log.Debugf("deleted %d visitor salts before %v", result.RowsAffected, before)
+	return nil
+}
+
+// GetTopPosts sums up the daily views of the given posts between from and to, excluding to.
+// The posts are ordered by their views, posts without views are omitted.
+func (a analyticsRepository) GetTopPosts(postIDs []uint, from time.Time, to time.Time, limit int) ([]ViewRollup, error) {
+	log := a.logger
+	repo := a.repository
+
+	var rows []ViewRollup
+	if len(postIDs) == 0 {
+		return rows, nil
+	}
+
+	result := repo.Where("post_id IN ? AND period = ? AND start >= ? AND start < ?", postIDs, types.AnalyticsPeriodDay, from, to).
+		Model(&ViewRollup{}).
+		Select("post_id, SUM(views) AS views, SUM(visitors) AS visitors").
+		Group("post_id").
+		Order("views DESC, post_id").
+		Limit(limit).
+		Scan(&rows)
+	if result.Error != nil {
+		log.Debugf("error fetching the top posts of %d posts: %v", len(postIDs), result.Error)
+		return []ViewRollup{}, result.Error
+	}
+
+	return rows, nil
+}
+
+// GetTopReferrers sums up the daily views of the given posts between from and to by referring host, excluding to.
+// The hosts are ordered by the views they referred.
+func (a analyticsRepository) GetTopReferrers(postIDs []uint, from time.Time, to time.Time, limit int) ([]ReferrerRollup, error) {
+	log := a.logger
+	repo := a.repository
+
+	var rows []ReferrerRollup
+	if len(postIDs) == 0 {
+		return rows, nil
+	}
+
+	result := repo.Where("post_id IN ? AND day >= ? AND day < ?", postIDs, from, to).
+		Model(&ReferrerRollup{}).
+		Select("host, SUM(views) AS views, SUM(visitors) AS visitors").
+		Group("host").
+		Order("views DESC, host").
+		Limit(limit).
+		Scan(&rows)
+	if result.Error != nil {
+		log.Debugf("error fetching the top referrers of %d posts: %v", len(postIDs), result.Error)
+		return []ReferrerRollup{}, result.Error
+	}
+
+	return rows, nil
+}
+
+// GetViewSeries sums up the views of the given posts per hour or day between from and to, excluding to.
+// Periods without views are omitted.
+func (a analyticsRepository) GetViewSeries(postIDs []uint, period string, from time.Time, to time.Time) ([]ViewRollup, error) {
+	log := a.logger
+	repo := a.repository
+
+	var rows []ViewRollup
+	if len(postIDs) == 0 {
+		return rows, nil
+	}
+
+	result := repo.Where("post_id IN ? AND period = ? AND start >= ? AND start < ?", postIDs, period, from, to).
+		Model(&ViewRollup{}).
+		Select("start, SUM(views) AS views, SUM(visitors) AS visitors").
+		Group("start").
+		Order("start").
+		Scan(&rows)
+	if result.Error != nil {
+		log.Debugf("error fetching the views of %d posts per %s: %v", len(postIDs), period, result.Error)
+		return []ViewRollup{}, result.Error
+	}
+
+	return rows, nil
+}
+
+// RollupPageViews recomputes the hourly, daily and referrer rollups from the views recorded since the given day.
+// The rollups of the recomputed periods are replaced in a single transaction, so running it again has no effect.
+func (a analyticsRepository) RollupPageViews(since time.Time) error {
+	log := a.logger
+	repo := a.repository
+
+	err := repo.Transaction(func(tx *gorm.DB) error {
+		var hours, days []ViewRollup
+		var referrers []ReferrerRollup
+
+		if err := tx.Where("hour >= ?", since).Model(&PageView{}).
+			Select("post_id, hour AS start, COUNT(*) AS views, COUNT(DISTINCT visitor) AS visitors").
+			Group("post_id, hour").Scan(&hours).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("day >= ?", since).Model(&PageView{}).
+			Select("post_id, day AS start, COUNT(*) AS views, COUNT(DISTINCT visitor) AS visitors").
+			Group("post_id, day").Scan(&days).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("day >= ?", since).Model(&PageView{}).
+			Select("post_id, day, referrer AS host, COUNT(*) AS views, COUNT(DISTINCT visitor) AS visitors").
+			Group("post_id, day, referrer").Scan(&referrers).Error; err != nil {
+			return err
+		}
+
+		for i := range hours {
+			hours[i].Period = types.AnalyticsPeriodHour
+		}
+		for i := range days {
+			days[i].Period = types.AnalyticsPeriodDay
+		}
+
+		if err := tx.Where("start >= ?", since).Delete(&ViewRollup{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("day >= ?", since).Delete(&ReferrerRollup{}).Error; err != nil {
+			return err
+		}
+		if rollups := append(hours, days...); len(rollups) > 0 {
+			if err := tx.Create(&rollups).Error; err != nil {
+				return err
+			}
+		}
+		if len(referrers) > 0 {
+			return tx.Create(&referrers).Error
+		}
+		return nil
+	})
+
+	if err != nil {
+		log.Debugf("failed to roll up the views since %v, error: %v", since, err)
+		return err
+	}
+
+	log.Debugf("rolled up the views since %v", since)
+	return nil
+}
@@ -0,0 +1,183 @@
+package repository_test
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/types"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// analyticsTestContext contains objects relevant for testing the AnalyticsRepository.
+type analyticsTestContext struct {
+	mockDb sqlmock.Sqlmock
+	sut    repository.AnalyticsRepository
+}
+
+// createAnalyticsRepositoryContext creates the context for testing the AnalyticsRepository and reduces code duplication.
+func createAnalyticsRepositoryContext(t *testing.T) *analyticsTestContext {
+	t.Helper()
+
+	db, mock, _ := sqlmock.New()
+	gormDb, _ := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}))
+
+	sut := repository.CreateAnalyticsRepository(logger.CreateLogger(), repository.CreateRepository(gormDb))
+	return &analyticsTestContext{mock, sut}
+}
+
+// TestAnalyticsRepository_AddPageView tests storing a view of a post identified by its URL-handle
+func TestAnalyticsRepository_AddPageView(t *testing.T) {
+	t.Parallel()
+	c := createAnalyticsRepositoryContext(t)
+
+	hour := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	view := repository.PageView{Visitor: "visitor", Referrer: "example.com", Hour: hour, Day: hour.Truncate(24 * time.Hour)}
+
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `posts` WHERE url_handle = ? LIMIT 1")).
+		WithArgs("hello").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(regexp.QuoteMeta("INSERT INTO `page_views` (`post_id`,`visitor`,`referrer`,`hour`,`day`) VALUES (?,?,?,?,?)")).
+		WithArgs(3, "visitor", "example.com", view.Hour, view.Day).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	c.mockDb.ExpectCommit()
+
+	err := c.sut.AddPageView("hello", &view)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, uint(3), view.PostID, "view should belong to the post")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expected query should be executed")
+}
+
+// TestAnalyticsRepository_AddPageView_Not_Found tests viewing a missing post
+func TestAnalyticsRepository_AddPageView_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createAnalyticsRepositoryContext(t)
+
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `posts` WHERE url_handle = ? LIMIT 1")).
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	err := c.sut.AddPageView("missing", &repository.PageView{})
+
+	assert.Equal(t, errortypes.PostNotFoundError{Post: types.Post{URLHandle: "missing"}}, err, "missing post should be reported")
+}
+
+// TestAnalyticsRepository_AddVisitorSalt tests keeping the salt stored by another instance first
+func TestAnalyticsRepository_AddVisitorSalt(t *testing.T) {
+	t.Parallel()
+	c := createAnalyticsRepositoryContext(t)
+
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(regexp.QuoteMeta("INSERT INTO `visitor_salts` (`day`,`salt`) VALUES (?,?) ON DUPLICATE KEY UPDATE")).
+		WithArgs(day, []byte("generated")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `visitor_salts` WHERE day = ? LIMIT 1")).
+		WithArgs(day).
+		WillReturnRows(sqlmock.NewRows([]string{"day", "salt"}).AddRow(day, []byte("stored")))
+	c.mockDb.ExpectCommit()
+
+	salt, err := c.sut.AddVisitorSalt(day, []byte("generated"))
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, []byte("stored"), salt, "stored salt should be returned")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expected query should be executed")
+}
+
+// TestAnalyticsRepository_DeleteVisitorSalts tests removing the salts of the past days
+func TestAnalyticsRepository_DeleteVisitorSalts(t *testing.T) {
+	t.Parallel()
+	c := createAnalyticsRepositoryContext(t)
+
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(regexp.QuoteMeta("DELETE FROM `visitor_salts` WHERE day < ?")).
+		WithArgs(day).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectCommit()
+
+	err := c.sut.DeleteVisitorSalts(day)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expected query should be executed")
+}
+
+// TestAnalyticsRepository_GetViewSeries tests summing up the views of posts per day
+func TestAnalyticsRepository_GetViewSeries(t *testing.T) {
+	t.Parallel()
+	c := createAnalyticsRepositoryContext(t)
+
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 2)
+	query := regexp.QuoteMeta("SELECT start, SUM(views) AS views, SUM(visitors) AS visitors FROM `view_rollups` WHERE post_id IN (?,?) AND period = ? AND start >= ? AND start < ? GROUP BY `start` ORDER BY start")
+	rows := sqlmock.NewRows([]string{"start", "views", "visitors"}).AddRow(from, 5, 3).AddRow(from.AddDate(0, 0, 1), 2, 2)
+
+	c.mockDb.ExpectQuery(query).WithArgs(1, 2, types.AnalyticsPeriodDay, from, to).WillReturnRows(rows)
+
+	series, err := c.sut.GetViewSeries([]uint{1, 2}, types.AnalyticsPeriodDay, from, to)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, []repository.ViewRollup{{Start: from, Views: 5, Visitors: 3}, {Start: from.AddDate(0, 0, 1), Views: 2, Visitors: 2}}, series, "series doesn't match the expected output")
+}
+
+// TestAnalyticsRepository_GetTopReferrers_No_Posts tests that no query is executed without posts
+func TestAnalyticsRepository_GetTopReferrers_No_Posts(t *testing.T) {
+	t.Parallel()
+	c := createAnalyticsRepositoryContext(t)
+
+	referrers, err := c.sut.GetTopReferrers([]uint{}, time.Time{}, time.Now(), 10)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Empty(t, referrers, "no referrers expected")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "no query should be executed")
+}
+
+// TestAnalyticsRepository_RollupPageViews tests replacing the rollups of the recent periods
+func TestAnalyticsRepository_RollupPageViews(t *testing.T) {
+	t.Parallel()
+	c := createAnalyticsRepositoryContext(t)
+
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	hour := since.Add(10 * time.Hour)
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT post_id, hour AS start, COUNT(*) AS views, COUNT(DISTINCT visitor) AS visitors FROM `page_views` WHERE hour >= ? GROUP BY post_id, hour")).
+		WithArgs(since).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "start", "views", "visitors"}).AddRow(1, hour, 3, 2))
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT post_id, day AS start, COUNT(*) AS views, COUNT(DISTINCT visitor) AS visitors FROM `page_views` WHERE day >= ? GROUP BY post_id, day")).
+		WithArgs(since).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "start", "views", "visitors"}).AddRow(1, since, 3, 2))
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT post_id, day, referrer AS host, COUNT(*) AS views, COUNT(DISTINCT visitor) AS visitors FROM `page_views` WHERE day >= ? GROUP BY post_id, day, referrer")).
+		WithArgs(since).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "day", "host", "views", "visitors"}).AddRow(1, since, "example.com", 3, 2))
+	c.mockDb.ExpectExec(regexp.QuoteMeta("DELETE FROM `view_rollups` WHERE start >= ?")).
+		WithArgs(since).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	c.mockDb.ExpectExec(regexp.QuoteMeta("DELETE FROM `referrer_rollups` WHERE day >= ?")).
+		WithArgs(since).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectExec(regexp.QuoteMeta("INSERT INTO `view_rollups` (`post_id`,`period`,`start`,`views`,`visitors`) VALUES (?,?,?,?,?),(?,?,?,?,?)")).
+		WithArgs(1, types.AnalyticsPeriodHour, hour, 3, 2, 1, types.AnalyticsPeriodDay, since, 3, 2).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	c.mockDb.ExpectExec(regexp.QuoteMeta("INSERT INTO `referrer_rollups` (`post_id`,`day`,`host`,`views`,`visitors`) VALUES (?,?,?,?,?)")).
+		WithArgs(1, since, "example.com", 3, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectCommit()
+
+	err := c.sut.RollupPageViews(since)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expected query should be executed")
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/types"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// Collection DB schema. Stores the editorial collections curated by the administrators, e.g. "Start here".
+type Collection struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	URLHandle string `gorm:"unique;not null"`
+	Title     string
+	Summary   string
+	Posts     []CollectionPost `gorm:"foreignKey:CollectionID"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// CollectionPost DB schema. Stores the ordered membership of posts in a collection.
+// Unlike series, a post can be part of any number of collections.
+type CollectionPost struct {
+	CollectionID uint `gorm:"primaryKey;autoIncrement:false"`
+	PostID       uint `gorm:"primaryKey;autoIncrement:false;index"`
+	Post         Post
+	Position     uint `gorm:"not null"`
+}
+
+// CollectionRepository interface defining collection-related database operations.
+type CollectionRepository interface {
+	AddCollection(collection *types.Collection) (*Collection, error)
+	DeleteCollection(urlHandle string) error
+	GetCollection(urlHandle string) (*Collection, error)
+	GetCollections() ([]Collection, error)
+	SetCollectionPosts(collectionID uint, postIDs []uint) error
+	UpdateCollection(collection *types.Collection) (*Collection, error)
+}
+
+// collectionRepository is the concrete implementation of the CollectionRepository interface.
+type collectionRepository struct {
+	logger     *zap.SugaredLogger
+	repository Repository
+}
+
+// CreateCollectionRepository instantiates the collectionRepository
+func CreateCollectionRepository(logger *zap.SugaredLogger, repository Repository) CollectionRepository {
+	initCollectionModel(logger, repository)
+
+	return &collectionRepository{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// initCollectionModel initializes the Collection and CollectionPost schemas in the database
+func initCollectionModel(logger *zap.SugaredLogger, repository Repository) {
+	if err := repository.AutoMigrate(&Collection{}); err != nil {
+		logger.Errorf("failed to initialize collection model: %v", err)
+	}
+	if err := repository.AutoMigrate(&CollectionPost{}); err != nil {
+		logger.Errorf("failed to initialize collection post model: %v", err)
+	}
+}
+
+// orderCollectionPosts orders preloaded collection members by their position.
+func orderCollectionPosts(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
+
+// collectionMembers builds the subquery selecting the IDs of the posts of a collection.
+func collectionMembers(tx *gorm.DB, collectionID uint) *gorm.DB {
+	return tx.Model(&CollectionPost{}).Select("post_id").Where("collection_id = ?", collectionID)
+}
+
+// AddCollection adds a new collection with the provided fields to the database.
+func (c collectionRepository) AddCollection(collection *types.Collection) (*Collection, error) {
+	log := c.logger
+	repo := c.repository
+
+	newCollection := Collection{
+		URLHandle: collection.URLHandle,
+		Title:     collection.Title,
+		Summary:   collection.Summary,
+	}
+
+	if result := repo.Create(&newCollection); result.Error == nil {
+		log.Debugf("created collection: %v", newCollection)
+		return &newCollection, nil
+	} else if strings.Contains(result.Error.Error(), "1062") {
+		log.Debugf("failed to create collection, duplicate key: %s, error: %v", collection.URLHandle, result.Error)
+		return nil, errortypes.DuplicateElementError{Key: collection.URLHandle}
+	} else {
+		log.Debugf("failed to create collection: %v, error: %s", collection, result.Error)
+		return nil, result.Error
+	}
+}
+
+// DeleteCollection removes the collection with the given URL handle and its memberships from the database.
+// The posts themselves are kept, only their update time is bumped.
+func (c collectionRepository) DeleteCollection(urlHandle string) error {
+	log := c.logger
+	repo := c.repository
+
+	collection, err := c.GetCollection(urlHandle)
+	if err != nil {
+		return err
+	}
+
+	err = repo.Transaction(func(tx *gorm.DB) error {
+		if err := touchPosts(tx, "id IN (?)", collectionMembers(tx, collection.ID)); err != nil {
+			return err
+		}
+		if err := tx.Where("collection_id = ?", collection.ID).Delete(&CollectionPost{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Collection{}, collection.ID).Error
+	})
+
+	if err != nil {
+		log.Debugf("failed to delete collection with handle: %s, error: %v", urlHandle, err)
+		return err
+	}
+
+	log.Debugf("deleted collection: %s", urlHandle)
+	return nil
+}
+
+// GetCollection retrieves the collection with the given URL-handle from the database.
+func (c collectionRepository) GetCollection(urlHandle string) (*Collection, error) {
+	log := c.logger
+	repo := c.repository
+
+	collection := Collection{
+		URLHandle: urlHandle,
+	}
+
+	result := repo.Preload("Posts", orderCollectionPosts).Preload("Posts.Post.Author").Where(&collection).Take(&collection)
+
+	if result.Error != nil {
+		log.Debugf("failed to retrieve collection with handle: %s, error: %v", urlHandle, result.Error)
+		if result.Error.Error() == "record not found" {
+			return nil, errortypes.CollectionNotFoundError{Collection: types.Collection{URLHandle: urlHandle}}
+		}
+		return nil, result.Error
+	}
+
+	log.Debugf("retrieved collection: %v", collection)
+	return &collection, nil
+}
+
+// GetCollections retrieves every collection from the database.
+func (c collectionRepository) GetCollections() ([]Collection, error) {
+	log := c.logger
+	repo := c.repository
+
+	var collections []Collection
+	if result := repo.Preload("Posts", orderCollectionPosts).Preload("Posts.Post.Author").Order("created_at DESC").Find(&collections); result.Error != nil {
+		log.Debugf("error fetching collections: %v", result.Error)
+		return []Collection{}, result.Error
+	}
+
+	log.Debugf("fetched collections: %v", collections)
+	return collections, nil
+}
+
+// SetCollectionPosts replaces the members of the collection with the given posts.
+// The order of the post IDs determines the position of the posts within the collection. The update time of the previous
+// and the new members is bumped.
+func (c collectionRepository) SetCollectionPosts(collectionID uint, postIDs []uint) error {
+	log := c.logger
+	repo := c.repository
+
+	memberships := make([]CollectionPost, 0, len(postIDs))
+	for i, postID := range postIDs {
+		memberships = append(memberships, CollectionPost{CollectionID: collectionID, PostID: postID, Position: uint(i + 1)})
+	}
+
+	err := repo.Transaction(func(tx *gorm.DB) error {
+		if err := touchPosts(tx, "id IN (?) OR id IN ?", collectionMembers(tx, collectionID), postIDs); err != nil {
+			return err
+		}
+		if err := tx.Where("collection_id = ?", collectionID).Delete(&CollectionPost{}).Error; err != nil {
+			return err
+		}
+		if len(memberships) == 0 {
+			return nil
+		}
+		return tx.Create(&memberships).Error
+	})
+
+	if err != nil {
+		log.Debugf("failed to update posts of collection %d: %v", collectionID, err)
+		return err
+	}
+
+	log.Debugf("updated posts of collection %d: %v", collectionID, postIDs)
+	return nil
+}
+
+// UpdateCollection updates the title and summary of an existing collection.
+func (c collectionRepository) UpdateCollection(collection *types.Collection) (*Collection, error) {
+	log := c.logger
+	repo := c.repository
+
+	existingCollection, err := c.GetCollection(collection.URLHandle)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := Collection{Title: collection.Title, Summary: collection.Summary}
+
+	if result := repo.Select("title", "summary").Where("id = ?", existingCollection.ID).Updates(&fields); result.Error != nil {
+		log.Debugf("failed to update collection %v, error: %v", existingCollection, result.Error)
+		return nil, result.Error
+	}
+
+	existingCollection.Title = collection.Title
+	existingCollection.Summary = collection.Summary
+
+	log.Debugf("updated collection: %v", existingCollection)
+	return existingCollection, nil
+}
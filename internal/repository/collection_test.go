@@ -0,0 +1,212 @@
+package repository_test
+
+import (
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/types"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+)
+
+// collectionTestContext contains objects relevant for testing the CollectionRepository.
+type collectionTestContext struct {
+	mockDb sqlmock.Sqlmock
+	sut    repository.CollectionRepository
+}
+
+// createCollectionRepositoryContext creates the context for testing the CollectionRepository and reduces code duplication.
+func createCollectionRepositoryContext(t *testing.T) *collectionTestContext {
+	t.Helper()
+
+	db, mock, _ := sqlmock.New()
+	gormDb, _ := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}))
+
+	sut := repository.CreateCollectionRepository(logger.CreateLogger(), repository.CreateRepository(gormDb))
+	return &collectionTestContext{mock, sut}
+}
+
+// TestCollectionRepository_AddCollection tests adding a new collection to the system
+func TestCollectionRepository_AddCollection(t *testing.T) {
+	t.Parallel()
+	c := createCollectionRepositoryContext(t)
+
+	input := &types.Collection{URLHandle: "start-here", Title: "Start here"}
+	query := regexp.QuoteMeta("INSERT INTO `collections` (`url_handle`,`title`,`summary`,`created_at`,`updated_at`) VALUES (?,?,?,?,?)")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(query).WillReturnResult(sqlmock.NewResult(1, 1))
+	c.mockDb.ExpectCommit()
+
+	collection, err := c.sut.AddCollection(input)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, input.URLHandle, collection.URLHandle, "received collection should match the expected one")
+	assert.Equal(t, input.Title, collection.Title, "received collection should match the expected one")
+}
+
+// TestCollectionRepository_AddCollection_Duplicate tests adding a new collection with an already existing URL handle
+func TestCollectionRepository_AddCollection_Duplicate(t *testing.T) {
+	t.Parallel()
+	c := createCollectionRepositoryContext(t)
+
+	input := &types.Collection{URLHandle: "start-here"}
+	query := regexp.QuoteMeta("INSERT INTO `collections` (`url_handle`,`title`,`summary`,`created_at`,`updated_at`) VALUES (?,?,?,?,?)")
+	expectedError := errortypes.DuplicateElementError{Key: input.URLHandle}
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(query).WillReturnError(fmt.Errorf("1062"))
+	c.mockDb.ExpectRollback()
+
+	collection, err := c.sut.AddCollection(input)
+
+	assert.Nil(t, collection, "should not return a collection")
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+}
+
+// TestCollectionRepository_GetCollection tests retrieving a single collection from the database
+func TestCollectionRepository_GetCollection(t *testing.T) {
+	t.Parallel()
+	c := createCollectionRepositoryContext(t)
+
+	query := regexp.QuoteMeta("SELECT * FROM `collections` WHERE `collections`.`url_handle` = ? LIMIT 1")
+	membersQuery := regexp.QuoteMeta("SELECT * FROM `collection_posts` WHERE `collection_posts`.`collection_id` = ? ORDER BY position")
+
+	c.mockDb.ExpectQuery(query).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url_handle"}).AddRow(1, "start-here"))
+	c.mockDb.ExpectQuery(membersQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"collection_id", "post_id", "position"}))
+
+	collection, err := c.sut.GetCollection("start-here")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "start-here", collection.URLHandle, "received collection should match the expected one")
+}
+
+// TestCollectionRepository_GetCollection_Record_Not_Found tests retrieving a non-existent collection from the database
+func TestCollectionRepository_GetCollection_Record_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createCollectionRepositoryContext(t)
+
+	query := regexp.QuoteMeta("SELECT * FROM `collections` WHERE `collections`.`url_handle` = ? LIMIT 1")
+	expectedError := errortypes.CollectionNotFoundError{Collection: types.Collection{URLHandle: "start-here"}}
+
+	c.mockDb.ExpectQuery(query).WillReturnError(fmt.Errorf("record not found"))
+
+	collection, err := c.sut.GetCollection("start-here")
+
+	assert.Nil(t, collection, "should not return a collection")
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+}
+
+// TestCollectionRepository_GetCollections tests retrieving every collection from the database
+func TestCollectionRepository_GetCollections(t *testing.T) {
+	t.Parallel()
+	c := createCollectionRepositoryContext(t)
+
+	query := regexp.QuoteMeta("SELECT * FROM `collections` ORDER BY created_at DESC")
+
+	c.mockDb.ExpectQuery(query).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url_handle"}))
+
+	collections, err := c.sut.GetCollections()
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 0, len(collections), "didn't receive the expected number of collections")
+}
+
+// TestCollectionRepository_SetCollectionPosts tests replacing the posts of a collection
+func TestCollectionRepository_SetCollectionPosts(t *testing.T) {
+	t.Parallel()
+	c := createCollectionRepositoryContext(t)
+
+	touchQuery := regexp.QuoteMeta("UPDATE `posts` SET `updated_at`=? WHERE id IN (SELECT `post_id` FROM `collection_posts` WHERE collection_id = ?) OR id IN (?,?)")
+	deleteQuery := regexp.QuoteMeta("DELETE FROM `collection_posts` WHERE collection_id = ?")
+	insertQuery := regexp.QuoteMeta("INSERT INTO `collection_posts` (`collection_id`,`post_id`,`position`) VALUES (?,?,?),(?,?,?)")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(touchQuery).WithArgs(sqlmock.AnyArg(), 1, 3, 2).WillReturnResult(sqlmock.NewResult(0, 2))
+	c.mockDb.ExpectExec(deleteQuery).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+	c.mockDb.ExpectExec(insertQuery).WithArgs(1, 3, 1, 1, 2, 2).WillReturnResult(sqlmock.NewResult(0, 2))
+	c.mockDb.ExpectCommit()
+
+	err := c.sut.SetCollectionPosts(1, []uint{3, 2})
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expected query should be executed")
+}
+
+// TestCollectionRepository_SetCollectionPosts_Unexpected_Error tests replacing the posts of a collection with an error
+func TestCollectionRepository_SetCollectionPosts_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createCollectionRepositoryContext(t)
+
+	touchQuery := regexp.QuoteMeta("UPDATE `posts` SET `updated_at`=? WHERE id IN (SELECT `post_id` FROM `collection_posts` WHERE collection_id = ?) OR id IN (?)")
+	expectedError := fmt.Errorf("unexpected error")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(touchQuery).WillReturnError(expectedError)
+	c.mockDb.ExpectRollback()
+
+	err := c.sut.SetCollectionPosts(1, []uint{3})
+
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+}
+
+// TestCollectionRepository_UpdateCollection tests updating the fields of a collection
+func TestCollectionRepository_UpdateCollection(t *testing.T) {
+	t.Parallel()
+	c := createCollectionRepositoryContext(t)
+
+	selectQuery := regexp.QuoteMeta("SELECT * FROM `collections` WHERE `collections`.`url_handle` = ? LIMIT 1")
+	membersQuery := regexp.QuoteMeta("SELECT * FROM `collection_posts` WHERE `collection_posts`.`collection_id` = ? ORDER BY position")
+	updateQuery := regexp.QuoteMeta("UPDATE `collections` SET `title`=?,`summary`=?,`updated_at`=? WHERE id = ?")
+
+	c.mockDb.ExpectQuery(selectQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url_handle"}).AddRow(1, "start-here"))
+	c.mockDb.ExpectQuery(membersQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"collection_id", "post_id", "position"}))
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(updateQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectCommit()
+
+	collection, err := c.sut.UpdateCollection(&types.Collection{URLHandle: "start-here", Title: "Start here"})
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "Start here", collection.Title, "title should be updated")
+}
+
+// TestCollectionRepository_DeleteCollection tests removing a collection from the database
+func TestCollectionRepository_DeleteCollection(t *testing.T) {
+	t.Parallel()
+	c := createCollectionRepositoryContext(t)
+
+	selectQuery := regexp.QuoteMeta("SELECT * FROM `collections` WHERE `collections`.`url_handle` = ? LIMIT 1")
+	membersQuery := regexp.QuoteMeta("SELECT * FROM `collection_posts` WHERE `collection_posts`.`collection_id` = ? ORDER BY position")
+	touchQuery := regexp.QuoteMeta("UPDATE `posts` SET `updated_at`=? WHERE id IN (SELECT `post_id` FROM `collection_posts` WHERE collection_id = ?)")
+	deleteMembersQuery := regexp.QuoteMeta("DELETE FROM `collection_posts` WHERE collection_id = ?")
+	deleteCollectionQuery := regexp.QuoteMeta("DELETE FROM `collections` WHERE `collections`.`id` = ?")
+
+	c.mockDb.ExpectQuery(selectQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url_handle"}).AddRow(1, "start-here"))
+	c.mockDb.ExpectQuery(membersQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"collection_id", "post_id", "position"}))
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(touchQuery).WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 0))
+	c.mockDb.ExpectExec(deleteMembersQuery).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+	c.mockDb.ExpectExec(deleteCollectionQuery).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectCommit()
+
+	err := c.sut.DeleteCollection("start-here")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expected query should be executed")
+}
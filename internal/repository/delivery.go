@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"github.com/wlchs/blog/internal/types"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// DeliveryState DB schema shared by the queued deliveries of the webhooks, the federation and the webmentions.
+// Stores the state of a delivery and the outcome of its attempts.
+type DeliveryState struct {
+	Status         string    `gorm:"not null;default:pending;index:,composite:queue,priority:1"`
+	Attempts       int       `gorm:"not null;default:0"`
+	NextAttemptAt  time.Time `gorm:"index:,composite:queue,priority:2"`
+	ResponseStatus int
+	Error          string
+	DeliveredAt    *time.Time
+}
+
+// deliveryStateColumns are the columns storing the outcome of a delivery attempt.
+var deliveryStateColumns = []string{"Status", "Attempts", "NextAttemptAt", "ResponseStatus", "Error", "DeliveredAt"}
+
+// addDeliveries queues new deliveries, without creating the given associations.
+func addDeliveries[T any](repo Repository, deliveries []T, omit ...string) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return repo.Omit(omit...).Create(&deliveries).Error
+}
+
+// claimDueDeliveries claims the pending deliveries whose next attempt is due, starting with the oldest one, together
+// with the given associations. The next attempt of the claimed deliveries is postponed by the lease, so other instances
+// skip them until the outcome of the attempts is stored, or attempt them again if the lease expires first.
+func claimDueDeliveries[T any](repo Repository, now time.Time, limit int, lease time.Duration, preload ...string) ([]T, error) {
+	condition := clause.Expr{SQL: "status = ? AND next_attempt_at <= ?", Vars: []interface{}{types.DeliveryPending, now}}
+	return claimRows[T](repo, condition, "next_attempt_at", limit, "next_attempt_at", now.Add(lease), preload...)
+}
+
+// updateDelivery stores the outcome of a delivery attempt together with the given columns.
+func updateDelivery[T any](repo Repository, delivery *T, columns ...string) error {
+	return repo.Select(append(columns, deliveryStateColumns...)).Updates(delivery).Error
+}
@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"github.com/wlchs/blog/internal/errortypes"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+// ActorKey DB schema. Stores the key pair an author signs the ActivityPub requests with.
+type ActorKey struct {
+	ID         uint   `gorm:"primaryKey;autoIncrement"`
+	UserName   string `gorm:"not null;unique;size:191"`
+	PublicKey  string `gorm:"type:text;not null"`
+	PrivateKey string `gorm:"type:text;not null"`
+	CreatedAt  time.Time
+}
+
+// Follower DB schema. Describes a remote ActivityPub actor following an author.
+type Follower struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	UserName  string `gorm:"not null;size:191;uniqueIndex:idx_follower,priority:1"`
+	ActorID   string `gorm:"not null;size:512;uniqueIndex:idx_follower,priority:2"`
+	Inbox     string `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+// FederationDelivery DB schema. Stores the queued deliveries of activities to remote inboxes and the outcome of the attempts.
+// The ID of the object and the type of the activity record which posts were federated.
+type FederationDelivery struct {
+	ID           uint   `gorm:"primaryKey;autoIncrement"`
+	UserName     string `gorm:"not null"`
+	Inbox        string `gorm:"not null"`
+	ObjectID     string `gorm:"size:512;index"`
+	ActivityType string
+	Activity     string `gorm:"type:text;not null"`
+	DeliveryState
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// FederationRepository interface defining the database operations of the ActivityPub federation.
+type FederationRepository interface {
+	AddActorKey(key *ActorKey) error
+	AddDeliveries(deliveries []FederationDelivery) error
+	AddFollower(follower *Follower) error
+	ClaimDueDeliveries(now time.Time, limit int, lease time.Duration) ([]FederationDelivery, error)
+	CountFollowers(userName string) (int, error)
+	DeleteFollower(userName string, actorID string) error
+	GetActorKey(userName string) (*ActorKey, error)
+	GetFollowers(userName string) ([]Follower, error)
+	GetLastActivityType(objectID string) (string, error)
+	UpdateDelivery(delivery *FederationDelivery) error
+}
+
+// federationRepository is the concrete implementation of the FederationRepository interface.
+type federationRepository struct {
+	logger     *zap.SugaredLogger
+	repository Repository
+}
+
+// CreateFederationRepository instantiates the federationRepository
+func CreateFederationRepository(logger *zap.SugaredLogger, repository Repository) FederationRepository {
+	initFederationModel(logger, repository)
+
+	return &federationRepository{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// initFederationModel initializes the ActorKey, Follower and FederationDelivery schemas in the database
+func initFederationModel(logger *zap.SugaredLogger, repository Repository) {
+	if err := repository.AutoMigrate(&ActorKey{}); err != nil {
+		logger.Errorf("failed to initialize actor key model: %v", err)
+	}
+	if err := repository.AutoMigrate(&Follower{}); err != nil {
+		logger.Errorf("failed to initialize follower model: %v", err)
+	}
+	if err := repository.AutoMigrate(&FederationDelivery{}); err != nil {
+		logger.Errorf("failed to initialize federation delivery model: %v", err)
+	}
+}
+
+// AddActorKey stores the key pair of an author.
+func (f federationRepository) AddActorKey(key *ActorKey) error {
+	log := f.logger
+	repo := f.repository
+
+	if result := repo.Create(key); result.Error != nil {
+		log.Debugf("failed to create key of actor %s, error: %v", key.UserName, result.Error)
+		if strings.Contains(result.Error.Error(), "1062") {
+			return errortypes.DuplicateElementError{Key: key.UserName}
+		}
+		return result.Error
+	}
+
+	log.Debugf("created key of actor %s", key.UserName)
+	return nil
+}
+
+// AddDeliveries queues the deliveries of an activity.
+func (f federationRepository) AddDeliveries(deliveries []FederationDelivery) error {
+	log := f.logger
+	repo := f.repository
+
+	if err := addDeliveries(repo, deliveries); err != nil {
+		log.Debugf("failed to queue %d federation deliveries, error: %v", len(deliveries), err)
+		return err
+	}
+
+	log.Debugf("queued %d federation deliveries", len(deliveries))
+	return nil
+}
+
+// AddFollower adds a follower of an author. Following an author again has no effect.
+func (f federationRepository) AddFollower(follower *Follower) error {
+	log := f.logger
+	repo := f.repository
+
+	if result := repo.Create(follower); result.Error != nil {
+		if strings.Contains(result.Error.Error(), "1062") {
+			log.Debugf("%s already follows %s", follower.ActorID, follower.UserName)
+			return nil
+		}
+		log.Debugf("failed to add follower %s of %s, error: %v", follower.ActorID, follower.UserName, result.Error)
+		return result.Error
+	}
+
+	log.Debugf("added follower %s of %s", follower.ActorID, follower.UserName)
+	return nil
+}
+
+// ClaimDueDeliveries claims the pending deliveries whose next attempt is due, starting with the oldest one.
+// The next attempt of the claimed deliveries is postponed by the lease, so other instances skip them in the meantime.
+func (f federationRepository) ClaimDueDeliveries(now time.Time, limit int, lease time.Duration) ([]FederationDelivery, error) {
+	log := f.logger
+	repo := f.repository
+
+	deliveries, err := claimDueDeliveries[FederationDelivery](repo, now, limit, lease)
+	if err != nil {
+		log.Debugf("error claiming due federation deliveries: %v", err)
+	}
+	return deliveries, err
+}
+
+// CountFollowers returns the number of followers of an author.
+func (f federationRepository) CountFollowers(userName string) (int, error) {
+	log := f.logger
+	repo := f.repository
+
+	var count int64
+	if result := repo.Where("user_name = ?", userName).Model(&Follower{}).Count(&count); result.Error != nil {
+		log.Debugf("error counting followers of %s: %v", userName, result.Error)
+		return 0, result.Error
+	}
+
+	return int(count), nil
+}
+
+// DeleteFollower removes a follower of an author.
+func (f federationRepository) DeleteFollower(userName string, actorID string) error {
+	log := f.logger
+	repo := f.repository
+
+	if result := repo.Where("user_name = ? AND actor_id = ?", userName, actorID).Delete(&Follower{}); result.Error != nil {
+		log.Debugf("failed to delete follower %s of %s, error: %v", actorID, userName, result.Error)
+		return result.Error
+	}
+
+	log.Debugf("deleted follower %s of %s", actorID, userName)
+	return nil
+}
+
+// GetActorKey retrieves the key pair of an author.
+func (f federationRepository) GetActorKey(userName string) (*ActorKey, error) {
+	log := f.logger
+	repo := f.repository
+
+	var key ActorKey
+	if result := repo.Where("user_name = ?", userName).Take(&key); result.Error != nil {
+		log.Debugf("failed to retrieve key of actor %s, error: %v", userName, result.Error)
+		if result.Error.Error() == "record not found" {
+			return nil, errortypes.ActorKeyNotFoundError{UserName: userName}
+		}
+		return nil, result.Error
+	}
+
+	return &key, nil
+}
+
+// GetFollowers retrieves the followers of an author.
+func (f federationRepository) GetFollowers(userName string) ([]Follower, error) {
+	log := f.logger
+	repo := f.repository
+
+	var followers []Follower
+	if result := repo.Where("user_name = ?", userName).Order("id").Find(&followers); result.Error != nil {
+		log.Debugf("error fetching followers of %s: %v", userName, result.Error)
+		return []Follower{}, result.Error
+	}
+
+	log.Debugf("fetched %d followers of %s", len(followers), userName)
+	return followers, nil
+}
+
+// GetLastActivityType retrieves the type of the last activity queued for an object, or an empty string if the object
+// was never federated.
+func (f federationRepository) GetLastActivityType(objectID string) (string, error) {
+	log := f.logger
+	repo := f.repository
+
+	var activityTypes []string
+	result := repo.Where("object_id = ?", objectID).
+		Model(&FederationDelivery{}).
+		Order("id DESC").
+		Limit(1).
+		Pluck("activity_type", &activityTypes)
+	if result.Error != nil {
+		log.Debugf("error fetching the last activity of %s: %v", objectID, result.Error)
+		return "", result.Error
+	}
+
+	if len(activityTypes) == 0 {
+		return "", nil
+	}
+	return activityTypes[0], nil
+}
+
+// UpdateDelivery stores the outcome of a delivery attempt.
+func (f federationRepository) UpdateDelivery(delivery *FederationDelivery) error {
+	log := f.logger
+	repo := f.repository
+
+	if err := updateDelivery(repo, delivery); err != nil {
+		log.Debugf("failed to update federation delivery %d, error: %v", delivery.ID, err)
+		return err
+	}
+
+	log.Debugf("updated federation delivery %d: %s after %d attempts", delivery.ID, delivery.Status, delivery.Attempts)
+	return nil
+}
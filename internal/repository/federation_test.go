@@ -0,0 +1,187 @@
+package repository_test
+
+import (
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/types"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// federationTestContext contains objects relevant for testing the FederationRepository.
+type federationTestContext struct {
+	mockDb sqlmock.Sqlmock
+	sut    repository.FederationRepository
+}
+
+// createFederationRepositoryContext creates the context for testing the FederationRepository and reduces code duplication.
+func createFederationRepositoryContext(t *testing.T) *federationTestContext {
+	t.Helper()
+
+	db, mock, _ := sqlmock.New()
+	gormDb, _ := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}))
+
+	sut := repository.CreateFederationRepository(logger.CreateLogger(), repository.CreateRepository(gormDb))
+	return &federationTestContext{mock, sut}
+}
+
+// TestFederationRepository_GetActorKey tests retrieving the key pair of an author
+func TestFederationRepository_GetActorKey(t *testing.T) {
+	t.Parallel()
+	c := createFederationRepositoryContext(t)
+
+	query := regexp.QuoteMeta("SELECT * FROM `actor_keys` WHERE user_name = ? LIMIT 1")
+	rows := sqlmock.NewRows([]string{"id", "user_name", "public_key", "private_key"}).AddRow(1, "testAuthor", "public", "private")
+
+	c.mockDb.ExpectQuery(query).WithArgs("testAuthor").WillReturnRows(rows)
+
+	key, err := c.sut.GetActorKey("testAuthor")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "public", key.PublicKey, "incorrect public key")
+	assert.Equal(t, "private", key.PrivateKey, "incorrect private key")
+}
+
+// TestFederationRepository_GetActorKey_Not_Found tests retrieving the key pair of an author who has none yet
+func TestFederationRepository_GetActorKey_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createFederationRepositoryContext(t)
+
+	query := regexp.QuoteMeta("SELECT * FROM `actor_keys` WHERE user_name = ? LIMIT 1")
+
+	c.mockDb.ExpectQuery(query).WithArgs("testAuthor").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	key, err := c.sut.GetActorKey("testAuthor")
+
+	assert.Nil(t, key, "no key should be returned")
+	assert.Equal(t, errortypes.ActorKeyNotFoundError{UserName: "testAuthor"}, err, "incorrect error type")
+}
+
+// TestFederationRepository_AddActorKey_Duplicate tests storing the key pair of an author concurrently
+func TestFederationRepository_AddActorKey_Duplicate(t *testing.T) {
+	t.Parallel()
+	c := createFederationRepositoryContext(t)
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(regexp.QuoteMeta("INSERT INTO `actor_keys`")).WillReturnError(fmt.Errorf("1062"))
+	c.mockDb.ExpectRollback()
+
+	err := c.sut.AddActorKey(&repository.ActorKey{UserName: "testAuthor", PublicKey: "public", PrivateKey: "private"})
+
+	assert.Equal(t, errortypes.DuplicateElementError{Key: "testAuthor"}, err, "incorrect error type")
+}
+
+// TestFederationRepository_AddFollower_Duplicate tests ignoring a follow request of an existing follower
+func TestFederationRepository_AddFollower_Duplicate(t *testing.T) {
+	t.Parallel()
+	c := createFederationRepositoryContext(t)
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(regexp.QuoteMeta("INSERT INTO `followers`")).WillReturnError(fmt.Errorf("1062"))
+	c.mockDb.ExpectRollback()
+
+	err := c.sut.AddFollower(&repository.Follower{UserName: "testAuthor", ActorID: "https://remote.example.com/users/alice", Inbox: "https://remote.example.com/inbox"})
+
+	assert.Nil(t, err, "duplicates should be ignored")
+}
+
+// TestFederationRepository_DeleteFollower tests removing a follower of an author
+func TestFederationRepository_DeleteFollower(t *testing.T) {
+	t.Parallel()
+	c := createFederationRepositoryContext(t)
+
+	actorID := "https://remote.example.com/users/alice"
+	query := regexp.QuoteMeta("DELETE FROM `followers` WHERE user_name = ? AND actor_id = ?")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(query).WithArgs("testAuthor", actorID).WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectCommit()
+
+	err := c.sut.DeleteFollower("testAuthor", actorID)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expectation should be met")
+}
+
+// TestFederationRepository_GetLastActivityType tests retrieving the type of the last activity queued for a post
+func TestFederationRepository_GetLastActivityType(t *testing.T) {
+	t.Parallel()
+	c := createFederationRepositoryContext(t)
+
+	objectID := "https://blog.example.com/posts/testUrlHandle"
+	query := regexp.QuoteMeta("SELECT `activity_type` FROM `federation_deliveries` WHERE object_id = ? ORDER BY id DESC LIMIT 1")
+
+	c.mockDb.ExpectQuery(query).WithArgs(objectID).WillReturnRows(sqlmock.NewRows([]string{"activity_type"}).AddRow("Update"))
+
+	activityType, err := c.sut.GetLastActivityType(objectID)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "Update", activityType, "incorrect activity type")
+}
+
+// TestFederationRepository_GetLastActivityType_Not_Federated tests retrieving the last activity of a post which was
+// never federated
+func TestFederationRepository_GetLastActivityType_Not_Federated(t *testing.T) {
+	t.Parallel()
+	c := createFederationRepositoryContext(t)
+
+	query := regexp.QuoteMeta("SELECT `activity_type` FROM `federation_deliveries` WHERE object_id = ? ORDER BY id DESC LIMIT 1")
+
+	c.mockDb.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"activity_type"}))
+
+	activityType, err := c.sut.GetLastActivityType("https://blog.example.com/posts/testUrlHandle")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "", activityType, "the post shouldn't be federated")
+}
+
+// TestFederationRepository_ClaimDueDeliveries tests claiming the pending deliveries whose next attempt is due
+func TestFederationRepository_ClaimDueDeliveries(t *testing.T) {
+	t.Parallel()
+	c := createFederationRepositoryContext(t)
+
+	now := time.Now()
+	query := regexp.QuoteMeta("SELECT * FROM `federation_deliveries` WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at LIMIT 10 FOR UPDATE SKIP LOCKED")
+	claimQuery := regexp.QuoteMeta("UPDATE `federation_deliveries` SET `next_attempt_at`=? WHERE `id` = ?")
+	rows := sqlmock.NewRows([]string{"id", "inbox", "status", "attempts"}).AddRow(1, "https://remote.example.com/inbox", types.DeliveryPending, 2)
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectQuery(query).WithArgs(types.DeliveryPending, now).WillReturnRows(rows)
+	c.mockDb.ExpectExec(claimQuery).WithArgs(now.Add(time.Minute), 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectCommit()
+
+	deliveries, err := c.sut.ClaimDueDeliveries(now, 10, time.Minute)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 1, len(deliveries), "incorrect number of deliveries")
+	assert.Equal(t, 2, deliveries[0].Attempts, "the state of the delivery should be retrieved")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "the delivery should be claimed")
+}
+
+// TestFederationRepository_UpdateDelivery tests storing the outcome of a delivery attempt
+func TestFederationRepository_UpdateDelivery(t *testing.T) {
+	t.Parallel()
+	c := createFederationRepositoryContext(t)
+
+	query := regexp.QuoteMeta("UPDATE `federation_deliveries` SET `status`=?,`attempts`=?,`next_attempt_at`=?,`response_status`=?,`error`=?,`delivered_at`=?,`updated_at`=? WHERE `id` = ?")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(query).WithArgs(types.DeliveryFailed, 8, sqlmock.AnyArg(), 410, "gone", nil, sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectCommit()
+
+	delivery := repository.FederationDelivery{ID: 1, DeliveryState: repository.DeliveryState{Status: types.DeliveryFailed, Attempts: 8, ResponseStatus: 410, Error: "gone"}}
+	err := c.sut.UpdateDelivery(&delivery)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expectation should be met")
+}
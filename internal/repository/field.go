@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/types"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// FieldDefinition DB schema. Describes a custom field which can be set on posts.
+type FieldDefinition struct {
+	ID          uint     `gorm:"primaryKey;autoIncrement"`
+	Name        string   `gorm:"size:64;unique;not null"`
+	Type        string   `gorm:"not null"`
+	Required    bool     `gorm:"not null;default:false"`
+	Options     []string `gorm:"type:json;serializer:json"`
+	Description string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// FieldRepository interface defining custom field-related database operations.
+type FieldRepository interface {
+	AddField(field *types.FieldDefinition) (*FieldDefinition, error)
+	DeleteField(name string) error
+	GetField(name string) (*FieldDefinition, error)
+	GetFields() ([]FieldDefinition, error)
+}
+
+// fieldRepository is the concrete implementation of the FieldRepository interface.
+type fieldRepository struct {
+	logger     *zap.SugaredLogger
+	repository Repository
+}
+
+// CreateFieldRepository instantiates the fieldRepository
+func CreateFieldRepository(logger *zap.SugaredLogger, repository Repository) FieldRepository {
+	initFieldModel(logger, repository)
+
+	return &fieldRepository{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// initFieldModel initializes the FieldDefinition schema in the database
+func initFieldModel(logger *zap.SugaredLogger, repository Repository) {
+	if err := repository.AutoMigrate(&FieldDefinition{}); err != nil {
+		logger.Errorf("failed to initialize field definition model: %v", err)
+	}
+}
+
+// customFieldPath creates the JSON path of a custom field in the custom_fields column of the posts table.
+func customFieldPath(name string) string {
+	return fmt.Sprintf("$.\"%s\"", name)
+}
+
+// AddField adds a new custom field definition to the database.
+func (f fieldRepository) AddField(field *types.FieldDefinition) (*FieldDefinition, error) {
+	log := f.logger
+	repo := f.repository
+
+	newField := FieldDefinition{
+		Name:        field.Name,
+		Type:        field.Type,
+		Required:    field.Required,
+		Options:     field.Options,
+		Description: field.Description,
+	}
+
+	if result := repo.Create(&newField); result.Error == nil {
+		log.Debugf("created custom field: %v", newField)
+		return &newField, nil
+	} else if strings.Contains(result.Error.Error(), "1062") {
+		log.Debugf("failed to create custom field, duplicate key: %s, error: %v", field.Name, result.Error)
+		return nil, errortypes.DuplicateElementError{Key: field.Name}
+	} else {
+		log.Debugf("failed to create custom field: %v, error: %s", field, result.Error)
+		return nil, result.Error
+	}
+}
+
+// DeleteField removes the custom field definition with the given name from the database.
+// The values of the field are removed from every post as well.
+func (f fieldRepository) DeleteField(name string) error {
+	log := f.logger
+	repo := f.repository
+
+	field, err := f.GetField(name)
+	if err != nil {
+		return err
+	}
+
+	err = repo.Transaction(func(tx *gorm.DB) error {
+		removeValue := gorm.Expr("JSON_REMOVE(custom_fields, ?)", customFieldPath(name))
+		if err := tx.Model(&Post{}).Where("JSON_CONTAINS_PATH(custom_fields, 'one', ?)", customFieldPath(name)).UpdateColumn("custom_fields", removeValue).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&FieldDefinition{}, field.ID).Error
+	})
+
+	if err != nil {
+		log.Debugf("failed to delete custom field %s, error: %v", name, err)
+		return err
+	}
+
+	log.Debugf("deleted custom field: %s", name)
+	return nil
+}
+
+// GetField retrieves the custom field definition with the given name from the database.
+func (f fieldRepository) GetField(name string) (*FieldDefinition, error) {
+	log := f.logger
+	repo := f.repository
+
+	field := FieldDefinition{
+		Name: name,
+	}
+
+	if result := repo.Where(&field).Take(&field); result.Error != nil {
+		log.Debugf("failed to retrieve custom field %s, error: %v", name, result.Error)
+		if result.Error.Error() == "record not found" {
+			return nil, errortypes.FieldNotFoundError{Field: types.FieldDefinition{Name: name}}
+		}
+		return nil, result.Error
+	}
+
+	log.Debugf("retrieved custom field: %v", field)
+	return &field, nil
+}
+
+// GetFields retrieves every custom field definition from the database.
+func (f fieldRepository) GetFields() ([]FieldDefinition, error) {
+	log := f.logger
+	repo := f.repository
+
+	var fields []FieldDefinition
+	if result := repo.Order("name").Find(&fields); result.Error != nil {
+		log.Debugf("error fetching custom fields: %v", result.Error)
+		return []FieldDefinition{}, result.Error
+	}
+
+	log.Debugf("fetched custom fields: %v", fields)
+	return fields, nil
+}
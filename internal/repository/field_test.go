@@ -0,0 +1,193 @@
+package repository_test
+
+import (
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/types"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+)
+
+// fieldTestContext contains objects relevant for testing the FieldRepository.
+type fieldTestContext struct {
+	mockDb sqlmock.Sqlmock
+	sut    repository.FieldRepository
+}
+
+// createFieldRepositoryContext creates the context for testing the FieldRepository and reduces code duplication.
+func createFieldRepositoryContext(t *testing.T) *fieldTestContext {
+	t.Helper()
+
+	db, mock, _ := sqlmock.New()
+	gormDb, _ := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}))
+
+	sut := repository.CreateFieldRepository(logger.CreateLogger(), repository.CreateRepository(gormDb))
+	return &fieldTestContext{mock, sut}
+}
+
+// TestFieldRepository_AddField tests adding a new custom field definition
+func TestFieldRepository_AddField(t *testing.T) {
+	t.Parallel()
+	c := createFieldRepositoryContext(t)
+
+	input := types.FieldDefinition{Name: "category", Type: types.FieldTypeEnum, Options: []string{"news", "howto"}}
+	query := regexp.QuoteMeta("INSERT INTO `field_definitions` (`name`,`type`,`required`,`options`,`description`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?)")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(query).
+		WithArgs(input.Name, input.Type, false, `["news","howto"]`, "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	c.mockDb.ExpectCommit()
+
+	field, err := c.sut.AddField(&input)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, input.Options, field.Options, "received field should match the input")
+}
+
+// TestFieldRepository_AddField_Duplicate tests adding a custom field definition with an already existing name
+func TestFieldRepository_AddField_Duplicate(t *testing.T) {
+	t.Parallel()
+	c := createFieldRepositoryContext(t)
+
+	input := types.FieldDefinition{Name: "category", Type: types.FieldTypeString}
+	expectedError := errortypes.DuplicateElementError{Key: input.Name}
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(regexp.QuoteMeta("INSERT INTO `field_definitions`")).WillReturnError(fmt.Errorf("1062"))
+	c.mockDb.ExpectRollback()
+
+	field, err := c.sut.AddField(&input)
+
+	assert.Nil(t, field, "should not return a field")
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+}
+
+// TestFieldRepository_AddField_Unexpected_Error tests adding a custom field definition with an unexpected error
+func TestFieldRepository_AddField_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createFieldRepositoryContext(t)
+
+	expectedError := fmt.Errorf("unexpected error")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(regexp.QuoteMeta("INSERT INTO `field_definitions`")).WillReturnError(expectedError)
+	c.mockDb.ExpectRollback()
+
+	field, err := c.sut.AddField(&types.FieldDefinition{Name: "category", Type: types.FieldTypeString})
+
+	assert.Nil(t, field, "should not return a field")
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+}
+
+// TestFieldRepository_DeleteField tests removing a custom field definition together with its values
+func TestFieldRepository_DeleteField(t *testing.T) {
+	t.Parallel()
+	c := createFieldRepositoryContext(t)
+
+	selectQuery := regexp.QuoteMeta("SELECT * FROM `field_definitions` WHERE `field_definitions`.`name` = ? LIMIT 1")
+	updateQuery := regexp.QuoteMeta("UPDATE `posts` SET `custom_fields`=JSON_REMOVE(custom_fields, ?) WHERE JSON_CONTAINS_PATH(custom_fields, 'one', ?)")
+	deleteQuery := regexp.QuoteMeta("DELETE FROM `field_definitions` WHERE `field_definitions`.`id` = ?")
+
+	c.mockDb.ExpectQuery(selectQuery).WithArgs("category").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "category"))
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(updateQuery).WithArgs(`$."category"`, `$."category"`).WillReturnResult(sqlmock.NewResult(0, 2))
+	c.mockDb.ExpectExec(deleteQuery).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectCommit()
+
+	err := c.sut.DeleteField("category")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expected query should be executed")
+}
+
+// TestFieldRepository_DeleteField_Not_Found tests removing a non-existent custom field definition
+func TestFieldRepository_DeleteField_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createFieldRepositoryContext(t)
+
+	expectedError := errortypes.FieldNotFoundError{Field: types.FieldDefinition{Name: "category"}}
+
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `field_definitions`")).WillReturnError(fmt.Errorf("record not found"))
+
+	err := c.sut.DeleteField("category")
+
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+}
+
+// TestFieldRepository_DeleteField_Unexpected_Error tests removing a custom field definition with an unexpected error
+func TestFieldRepository_DeleteField_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createFieldRepositoryContext(t)
+
+	expectedError := fmt.Errorf("unexpected error")
+
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `field_definitions`")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "category"))
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(regexp.QuoteMeta("UPDATE `posts`")).WillReturnError(expectedError)
+	c.mockDb.ExpectRollback()
+
+	err := c.sut.DeleteField("category")
+
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+}
+
+// TestFieldRepository_GetField_Unexpected_Error tests retrieving a custom field definition with an unexpected error
+func TestFieldRepository_GetField_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createFieldRepositoryContext(t)
+
+	expectedError := fmt.Errorf("unexpected error")
+
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `field_definitions`")).WillReturnError(expectedError)
+
+	field, err := c.sut.GetField("category")
+
+	assert.Nil(t, field, "should not return a field")
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+}
+
+// TestFieldRepository_GetFields tests retrieving every custom field definition
+func TestFieldRepository_GetFields(t *testing.T) {
+	t.Parallel()
+	c := createFieldRepositoryContext(t)
+
+	query := regexp.QuoteMeta("SELECT * FROM `field_definitions` ORDER BY name")
+
+	c.mockDb.ExpectQuery(query).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "type", "options"}).
+			AddRow(1, "category", types.FieldTypeEnum, `["news","howto"]`).
+			AddRow(2, "cover", types.FieldTypeURL, nil))
+
+	fields, err := c.sut.GetFields()
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 2, len(fields), "didn't receive the expected number of fields")
+	assert.Equal(t, []string{"news", "howto"}, fields[0].Options, "options should be deserialized")
+}
+
+// TestFieldRepository_GetFields_Unexpected_Error tests retrieving every custom field definition with an unexpected error
+func TestFieldRepository_GetFields_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createFieldRepositoryContext(t)
+
+	expectedError := fmt.Errorf("unexpected error")
+
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `field_definitions` ORDER BY name")).WillReturnError(expectedError)
+
+	fields, err := c.sut.GetFields()
+
+	assert.Equal(t, expectedError, err, "error should match expected value")
+	assert.Equal(t, 0, len(fields), "shouldn't receive any fields")
+}
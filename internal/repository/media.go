@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/types"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// Media DB schema. Stores the metadata of an uploaded file, the content itself is kept in the file storage.
+type Media struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	FileName    string `gorm:"not null"`
+	ContentType string `gorm:"not null"`
+	Size        int64  `gorm:"not null"`
+	Width       int
+	Height      int
+	Checksum    string `gorm:"size:64;not null"`
+	StorageKey  string `gorm:"unique;not null"`
+	UploaderID  uint   `gorm:"not null"`
+	Uploader    User
+	Variants    []MediaVariant
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// MediaVariant DB schema. Stores the metadata of a resized variant of an uploaded image.
+type MediaVariant struct {
+	MediaID     uint   `gorm:"primaryKey"`
+	Name        string `gorm:"primaryKey;size:32"`
+	ContentType string `gorm:"not null"`
+	Width       int    `gorm:"not null"`
+	Height      int    `gorm:"not null"`
+	Size        int64  `gorm:"not null"`
+	Checksum    string `gorm:"size:64;not null"`
+	StorageKey  string `gorm:"unique;not null"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// MediaRepository interface defining media-related database operations.
+type MediaRepository interface {
+	AddMedia(media *Media) (*Media, error)
+	AddMediaVariant(variant *MediaVariant) error
+	DeleteMedia(id uint) error
+	GetMedia(id uint) (*Media, error)
+	GetMediaByIDs(ids []uint) ([]Media, error)
+	GetMediaList() ([]Media, error)
+}
+
+// mediaRepository is the concrete implementation of the MediaRepository interface.
+type mediaRepository struct {
+	logger     *zap.SugaredLogger
+	repository Repository
+}
+
+// CreateMediaRepository instantiates the mediaRepository
+func CreateMediaRepository(logger *zap.SugaredLogger, repository Repository) MediaRepository {
+	initMediaModel(logger, repository)
+
+	return &mediaRepository{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// initMediaModel initializes the Media schema in the database
+func initMediaModel(logger *zap.SugaredLogger, repository Repository) {
+	if err := repository.AutoMigrate(&Media{}); err != nil {
+		logger.Errorf("failed to initialize media model: %v", err)
+	}
+	if err := repository.AutoMigrate(&MediaVariant{}); err != nil {
+		logger.Errorf("failed to initialize media variant model: %v", err)
+	}
+}
+
+// AddMedia adds the metadata of a new file and its variants to the database.
+func (m mediaRepository) AddMedia(media *Media) (*Media, error) {
+	log := m.logger
+	repo := m.repository
+
+	if result := repo.Omit("Uploader").Create(media); result.Error != nil {
+		log.Debugf("failed to create media: %v, error: %v", media, result.Error)
+		return nil, result.Error
+	}
+
+	log.Debugf("created media: %v", media)
+	return media, nil
+}
+
+// AddMediaVariant adds the metadata of a variant generated for an existing file to the database.
+func (m mediaRepository) AddMediaVariant(variant *MediaVariant) error {
+	log := m.logger
+	repo := m.repository
+
+	if result := repo.Create(variant); result.Error != nil {
+		log.Debugf("failed to create variant %s of media %d, error: %v", variant.Name, variant.MediaID, result.Error)
+		if strings.Contains(result.Error.Error(), "1062") {
+			return errortypes.DuplicateElementError{Key: variant.Name}
+		}
+		return result.Error
+	}
+
+	log.Debugf("created variant %s of media %d", variant.Name, variant.MediaID)
+	return nil
+}
+
+// DeleteMedia removes the metadata of the file with the given ID and its variants from the database.
+func (m mediaRepository) DeleteMedia(id uint) error {
+	log := m.logger
+	repo := m.repository
+
+	err := repo.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("media_id = ?", id).Delete(&MediaVariant{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Delete(&Media{ID: id})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errortypes.MediaNotFoundError{Media: types.Media{ID: id}}
+		}
+		return nil
+	})
+
+	if err != nil {
+		log.Debugf("failed to delete media %d, error: %v", id, err)
+		return err
+	}
+
+	log.Debugf("deleted media: %d", id)
+	return nil
+}
+
+// GetMedia retrieves the metadata of the file with the given ID from the database.
+func (m mediaRepository) GetMedia(id uint) (*Media, error) {
+	log := m.logger
+	repo := m.repository
+
+	var media Media
+	if result := repo.Preload("Uploader").Preload("Variants").Where("id = ?", id).Take(&media); result.Error != nil {
+		log.Debugf("failed to retrieve media %d, error: %v", id, result.Error)
+		if result.Error.Error() == "record not found" {
+			return nil, errortypes.MediaNotFoundError{Media: types.Media{ID: id}}
+		}
+		return nil, result.Error
+	}
+
+	log.Debugf("retrieved media: %v", media)
+	return &media, nil
+}
+
+// GetMediaByIDs retrieves the metadata of the files with the given IDs from the database.
+// IDs without a matching file are ignored.
+func (m mediaRepository) GetMediaByIDs(ids []uint) ([]Media, error) {
+	log := m.logger
+	repo := m.repository
+
+	var media []Media
+	if result := repo.Preload("Variants").Where("id IN ?", ids).Find(&media); result.Error != nil {
+		log.Debugf("error fetching media %v: %v", ids, result.Error)
+		return []Media{}, result.Error
+	}
+
+	log.Debugf("fetched media: %v", media)
+	return media, nil
+}
+
+// GetMediaList retrieves the metadata of every uploaded file from the database, starting with the latest one.
+func (m mediaRepository) GetMediaList() ([]Media, error) {
+	log := m.logger
+	repo := m.repository
+
+	var media []Media
+	if result := repo.Preload("Uploader").Preload("Variants").Order("created_at DESC").Find(&media); result.Error != nil {
+		log.Debugf("error fetching media: %v", result.Error)
+		return []Media{}, result.Error
+	}
+
+	log.Debugf("fetched media: %v", media)
+	return media, nil
+}
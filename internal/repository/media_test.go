@@ -0,0 +1,239 @@
+package repository_test
+
+import (
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/types"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// mediaTestContext contains objects relevant for testing the MediaRepository.
+type mediaTestContext struct {
+	mockDb sqlmock.Sqlmock
+	sut    repository.MediaRepository
+}
+
+// createMediaRepositoryContext creates the context for testing the MediaRepository and reduces code duplication.
+func createMediaRepositoryContext(t *testing.T) *mediaTestContext {
+	t.Helper()
+
+	db, mock, _ := sqlmock.New()
+	gormDb, _ := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}))
+
+	sut := repository.CreateMediaRepository(logger.CreateLogger(), repository.CreateRepository(gormDb))
+	return &mediaTestContext{mock, sut}
+}
+
+// TestMediaRepository_AddMedia tests adding the metadata of a new file
+func TestMediaRepository_AddMedia(t *testing.T) {
+	t.Parallel()
+	c := createMediaRepositoryContext(t)
+
+	input := repository.Media{
+		FileName:    "test.png",
+		ContentType: "image/png",
+		Size:        4,
+		Width:       800,
+		Height:      400,
+		Checksum:    "checksum",
+		StorageKey:  "media/2024/01/test.png",
+		UploaderID:  1,
+		Variants:    []repository.MediaVariant{{Name: "thumbnail", StorageKey: "media/2024/01/test_thumbnail.png"}},
+	}
+	query := regexp.QuoteMeta("INSERT INTO `media` (`file_name`,`content_type`,`size`,`width`,`height`,`checksum`,`storage_key`,`uploader_id`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?)")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(query).
+		WithArgs(input.FileName, input.ContentType, input.Size, input.Width, input.Height, input.Checksum, input.StorageKey, input.UploaderID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	c.mockDb.ExpectExec(regexp.QuoteMeta("INSERT INTO `media_variants`")).
+		WithArgs(1, "thumbnail", "", 0, 0, 0, "", "media/2024/01/test_thumbnail.png", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectCommit()
+
+	media, err := c.sut.AddMedia(&input)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, uint(1), media.ID, "media ID should be set")
+	assert.Equal(t, uint(1), media.Variants[0].MediaID, "variants should reference the media")
+}
+
+// TestMediaRepository_AddMedia_Unexpected_Error tests adding the metadata of a new file with an unexpected error
+func TestMediaRepository_AddMedia_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createMediaRepositoryContext(t)
+
+	expectedError := fmt.Errorf("unexpected error")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(regexp.QuoteMeta("INSERT INTO `media`")).WillReturnError(expectedError)
+	c.mockDb.ExpectRollback()
+
+	media, err := c.sut.AddMedia(&repository.Media{})
+
+	assert.Nil(t, media, "should not return media")
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+}
+
+// TestMediaRepository_AddMediaVariant tests adding the metadata of a variant generated for an existing file
+func TestMediaRepository_AddMediaVariant(t *testing.T) {
+	t.Parallel()
+	c := createMediaRepositoryContext(t)
+
+	input := repository.MediaVariant{MediaID: 1, Name: "thumbnail", ContentType: "image/png", Width: 320, Height: 160, Size: 4, Checksum: "checksum", StorageKey: "media/2024/01/test_thumbnail.png"}
+	query := regexp.QuoteMeta("INSERT INTO `media_variants` (`media_id`,`name`,`content_type`,`width`,`height`,`size`,`checksum`,`storage_key`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?)")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(query).
+		WithArgs(input.MediaID, input.Name, input.ContentType, input.Width, input.Height, input.Size, input.Checksum, input.StorageKey, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectCommit()
+
+	err := c.sut.AddMediaVariant(&input)
+
+	assert.Nil(t, err, "should complete without error")
+}
+
+// TestMediaRepository_AddMediaVariant_Duplicate tests adding the metadata of an already existing variant
+func TestMediaRepository_AddMediaVariant_Duplicate(t *testing.T) {
+	t.Parallel()
+	c := createMediaRepositoryContext(t)
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(regexp.QuoteMeta("INSERT INTO `media_variants`")).WillReturnError(fmt.Errorf("1062"))
+	c.mockDb.ExpectRollback()
+
+	err := c.sut.AddMediaVariant(&repository.MediaVariant{MediaID: 1, Name: "thumbnail"})
+
+	assert.Equal(t, errortypes.DuplicateElementError{Key: "thumbnail"}, err, "received error should match the expected one")
+}
+
+// TestMediaRepository_DeleteMedia tests removing the metadata of a file
+func TestMediaRepository_DeleteMedia(t *testing.T) {
+	t.Parallel()
+	c := createMediaRepositoryContext(t)
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(regexp.QuoteMeta("DELETE FROM `media_variants` WHERE media_id = ?")).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	c.mockDb.ExpectExec(regexp.QuoteMeta("DELETE FROM `media` WHERE `media`.`id` = ?")).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectCommit()
+
+	err := c.sut.DeleteMedia(1)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every query should be executed")
+}
+
+// TestMediaRepository_DeleteMedia_Not_Found tests removing the metadata of a non-existent file
+func TestMediaRepository_DeleteMedia_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createMediaRepositoryContext(t)
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(regexp.QuoteMeta("DELETE FROM `media_variants` WHERE media_id = ?")).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	c.mockDb.ExpectExec(regexp.QuoteMeta("DELETE FROM `media` WHERE `media`.`id` = ?")).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	c.mockDb.ExpectRollback()
+
+	err := c.sut.DeleteMedia(1)
+
+	assert.Equal(t, errortypes.MediaNotFoundError{Media: types.Media{ID: 1}}, err, "received error should match the expected one")
+}
+
+// TestMediaRepository_GetMedia tests retrieving the metadata of a file with its uploader
+func TestMediaRepository_GetMedia(t *testing.T) {
+	t.Parallel()
+	c := createMediaRepositoryContext(t)
+
+	mediaRows := sqlmock.
+		NewRows([]string{"id", "file_name", "content_type", "size", "checksum", "storage_key", "uploader_id", "created_at"}).
+		AddRow(1, "test.png", "image/png", 4, "checksum", "media/2024/01/test.png", 1, time.Now())
+	userRows := sqlmock.NewRows([]string{"id", "user_name"}).AddRow(1, "testAuthor")
+
+	variantRows := sqlmock.NewRows([]string{"media_id", "name", "width"}).AddRow(1, "thumbnail", 320)
+
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `media` WHERE id = ? LIMIT 1")).WithArgs(1).WillReturnRows(mediaRows)
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE `users`.`id` = ?")).WithArgs(1).WillReturnRows(userRows)
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `media_variants` WHERE `media_variants`.`media_id` = ?")).WithArgs(1).WillReturnRows(variantRows)
+
+	media, err := c.sut.GetMedia(1)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "media/2024/01/test.png", media.StorageKey, "storage key should match")
+	assert.Equal(t, "testAuthor", media.Uploader.UserName, "uploader should be preloaded")
+	assert.Equal(t, "thumbnail", media.Variants[0].Name, "variants should be preloaded")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every query should be executed")
+}
+
+// TestMediaRepository_GetMedia_Not_Found tests retrieving the metadata of a non-existent file
+func TestMediaRepository_GetMedia_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createMediaRepositoryContext(t)
+
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `media` WHERE id = ? LIMIT 1")).WithArgs(1).WillReturnError(gorm.ErrRecordNotFound)
+
+	media, err := c.sut.GetMedia(1)
+
+	assert.Nil(t, media, "should not return media")
+	assert.Equal(t, errortypes.MediaNotFoundError{Media: types.Media{ID: 1}}, err, "received error should match the expected one")
+}
+
+// TestMediaRepository_GetMediaList tests retrieving the metadata of every file, latest first
+func TestMediaRepository_GetMediaList(t *testing.T) {
+	t.Parallel()
+	c := createMediaRepositoryContext(t)
+
+	mediaRows := sqlmock.
+		NewRows([]string{"id", "file_name", "uploader_id"}).
+		AddRow(2, "second.png", 1).
+		AddRow(1, "first.png", 1)
+	userRows := sqlmock.NewRows([]string{"id", "user_name"}).AddRow(1, "testAuthor")
+
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `media` ORDER BY created_at DESC")).WillReturnRows(mediaRows)
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE `users`.`id` = ?")).WithArgs(1).WillReturnRows(userRows)
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `media_variants` WHERE `media_variants`.`media_id` IN (?,?)")).
+		WithArgs(2, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"media_id", "name"}))
+
+	media, err := c.sut.GetMediaList()
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 2, len(media), "should return both files")
+	assert.Equal(t, "second.png", media[0].FileName, "latest file should come first")
+}
+
+// TestMediaRepository_GetMediaByIDs tests retrieving the metadata of the files with the given IDs
+func TestMediaRepository_GetMediaByIDs(t *testing.T) {
+	t.Parallel()
+	c := createMediaRepositoryContext(t)
+
+	mediaRows := sqlmock.NewRows([]string{"id", "file_name"}).AddRow(1, "test.png")
+	variantRows := sqlmock.NewRows([]string{"media_id", "name"}).AddRow(1, "thumbnail")
+
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `media` WHERE id IN (?,?)")).WithArgs(1, 2).WillReturnRows(mediaRows)
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `media_variants` WHERE `media_variants`.`media_id` = ?")).WithArgs(1).WillReturnRows(variantRows)
+
+	media, err := c.sut.GetMediaByIDs([]uint{1, 2})
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 1, len(media), "only existing files should be returned")
+	assert.Equal(t, 1, len(media[0].Variants), "variants should be preloaded")
+}
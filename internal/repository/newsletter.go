@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"github.com/wlchs/blog/internal/errortypes"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+// Subscriber DB schema. Describes a reader receiving the newsletter by email.
+// Subscribers only receive emails once they confirmed their address.
+type Subscriber struct {
+	ID           uint   `gorm:"primaryKey;autoIncrement"`
+	Email        string `gorm:"not null;unique;size:320"`
+	Mode         string `gorm:"not null;default:post"`
+	Confirmed    bool   `gorm:"not null;default:false;index"`
+	ConfirmedAt  *time.Time
+	LastDigestAt *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// NewsletterPost DB schema. Records the published posts to be announced by the newsletter.
+type NewsletterPost struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	URLHandle string `gorm:"not null;unique"`
+	Title     string `gorm:"not null"`
+	Summary   string
+	Announced bool      `gorm:"not null;default:false;index"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+// NewsletterRepository interface defining the database operations of the newsletter.
+type NewsletterRepository interface {
+	AddNewsletterPost(post *NewsletterPost) error
+	AddSubscriber(subscriber *Subscriber) error
+	DeleteSubscriber(email string) error
+	GetConfirmedSubscribers(mode string) ([]Subscriber, error)
+	GetNewsletterPostsSince(since time.Time) ([]NewsletterPost, error)
+	GetPendingNewsletterPosts() ([]NewsletterPost, error)
+	GetSubscriber(email string) (*Subscriber, error)
+	SetAnnounced(ids []uint) error
+	UpdateSubscriber(subscriber *Subscriber) error
+}
+
+// newsletterRepository is the concrete implementation of the NewsletterRepository interface.
+type newsletterRepository struct {
+	logger     *zap.SugaredLogger
+	repository Repository
+}
+
+// CreateNewsletterRepository instantiates the newsletterRepository
+func CreateNewsletterRepository(logger *zap.SugaredLogger, repository Repository) NewsletterRepository {
+	initNewsletterModel(logger, repository)
+
+	return &newsletterRepository{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// initNewsletterModel initializes the Subscriber and NewsletterPost schemas in the database
+func initNewsletterModel(logger *zap.SugaredLogger, repository Repository) {
+	if err := repository.AutoMigrate(&Subscriber{}); err != nil {
+		logger.Errorf("failed to initialize subscriber model: %v", err)
+	}
+	if err := repository.AutoMigrate(&NewsletterPost{}); err != nil {
+		logger.Errorf("failed to initialize newsletter post model: %v", err)
+	}
+}
+
+// AddNewsletterPost records a post to be announced. Posts which were already recorded are ignored,
+// so every post is announced at most once.
+func (n newsletterRepository) AddNewsletterPost(post *NewsletterPost) error {
+	log := n.logger
+	repo := n.repository
+
+	if result := repo.Create(post); result.Error != nil {
+		if strings.Contains(result.Error.Error(), "1062") {
+			log.Debugf("post %s already recorded for the newsletter", post.URLHandle)
+			return nil
+		}
+		log.Debugf("failed to record post %s for the newsletter, error: %v", post.URLHandle, result.Error)
+		return result.Error
+	}
+
+	log.Debugf("recorded post %s for the newsletter", post.URLHandle)
+	return nil
+}
+
+// AddSubscriber adds a new subscriber to the database.
+func (n newsletterRepository) AddSubscriber(subscriber *Subscriber) error {
+	log := n.logger
+	repo := n.repository
+
+	if result := repo.Create(subscriber); result.Error != nil {
+		log.Debugf("failed to create subscriber %s, error: %v", subscriber.Email, result.Error)
+		if strings.Contains(result.Error.Error(), "1062") {
+			return errortypes.DuplicateElementError{Key: subscriber.Email}
+		}
+		return result.Error
+	}
+
+	log.Debugf("created subscriber: %d", subscriber.ID)
+	return nil
+}
+
+// DeleteSubscriber removes the subscriber with the given email address from the database.
+func (n newsletterRepository) DeleteSubscriber(email string) error {
+	log := n.logger
+	repo := n.repository
+
+	if result := repo.Where("email = ?", email).Delete(&Subscriber{}); result.Error != nil {
+		log.Debugf("failed to delete subscriber %s, error: %v", email, result.Error)
+		return result.Error
+	}
+
+	log.Debugf("deleted subscriber: %s", email)
+	return nil
+}
+
+// GetConfirmedSubscribers retrieves the confirmed subscribers of the given delivery mode.
+func (n newsletterRepository) GetConfirmedSubscribers(mode string) ([]Subscriber, error) {
+	log := n.logger
+	repo := n.repository
+
+	var subscribers []Subscriber
+	if result := repo.Where("confirmed = ? AND mode = ?", true, mode).Order("id").Find(&subscribers); result.Error != nil {
+		log.Debugf("error fetching confirmed subscribers: %v", result.Error)
+		return []Subscriber{}, result.Error
+	}
+
+	log.Debugf("fetched %d confirmed subscribers of mode %s", len(subscribers), mode)
+	return subscribers, nil
+}
+
+// GetNewsletterPostsSince retrieves the posts recorded after the given time, starting with the oldest one.
+func (n newsletterRepository) GetNewsletterPostsSince(since time.Time) ([]NewsletterPost, error) {
+	log := n.logger
+	repo := n.repository
+
+	var posts []NewsletterPost
+	if result := repo.Where("created_at > ?", since).Order("created_at").Find(&posts); result.Error != nil {
+		log.Debugf("error fetching newsletter posts since %v: %v", since, result.Error)
+		return []NewsletterPost{}, result.Error
+	}
+
+	return posts, nil
+}
+
+// GetPendingNewsletterPosts retrieves the recorded posts which haven't been announced yet, starting with the oldest one.
+func (n newsletterRepository) GetPendingNewsletterPosts() ([]NewsletterPost, error) {
+	log := n.logger
+	repo := n.repository
+
+	var posts []NewsletterPost
+	if result := repo.Where("announced = ?", false).Order("id").Find(&posts); result.Error != nil {
+		log.Debugf("error fetching pending newsletter posts: %v", result.Error)
+		return []NewsletterPost{}, result.Error
+	}
+
+	return posts, nil
+}
+
+// GetSubscriber retrieves the subscriber with the given email address from the database.
+func (n newsletterRepository) GetSubscriber(email string) (*Subscriber, error) {
+	log := n.logger
+	repo := n.repository
+
+	var subscriber Subscriber
+	if result := repo.Where("email = ?", email).Take(&subscriber); result.Error != nil {
+		log.Debugf("failed to retrieve subscriber %s, error: %v", email, result.Error)
+		if result.Error.Error() == "record not found" {
+			return nil, errortypes.SubscriberNotFoundError{Email: email}
+		}
+		return nil, result.Error
+	}
+
+	log.Debugf("retrieved subscriber: %d", subscriber.ID)
+	return &subscriber, nil
+}
+
+// SetAnnounced marks the recorded posts with the given IDs as announced.
+func (n newsletterRepository) SetAnnounced(ids []uint) error {
+	log := n.logger
+	repo := n.repository
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if result := repo.Where("id IN ?", ids).Model(&NewsletterPost{}).Update("announced", true); result.Error != nil {
+		log.Debugf("failed to mark newsletter posts %v as announced, error: %v", ids, result.Error)
+		return result.Error
+	}
+
+	log.Debugf("marked %d newsletter posts as announced", len(ids))
+	return nil
+}
+
+// UpdateSubscriber stores the delivery settings and the confirmation state of a subscriber.
+func (n newsletterRepository) UpdateSubscriber(subscriber *Subscriber) error {
+	log := n.logger
+	repo := n.repository
+
+	if result := repo.Select("Mode", "Confirmed", "ConfirmedAt", "LastDigestAt").Updates(subscriber); result.Error != nil {
+		log.Debugf("failed to update subscriber %d, error: %v", subscriber.ID, result.Error)
+		return result.Error
+	}
+
+	log.Debugf("updated subscriber: %d", subscriber.ID)
+	return nil
+}
@@ -0,0 +1,115 @@
+package repository_test
+
+import (
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/repository"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+)
+
+// newsletterTestContext contains objects relevant for testing the NewsletterRepository.
+type newsletterTestContext struct {
+	mockDb sqlmock.Sqlmock
+	sut    repository.NewsletterRepository
+}
+
+// createNewsletterRepositoryContext creates the context for testing the NewsletterRepository and reduces code duplication.
+func createNewsletterRepositoryContext(t *testing.T) *newsletterTestContext {
+	t.Helper()
+
+	db, mock, _ := sqlmock.New()
+	gormDb, _ := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}))
+
+	sut := repository.CreateNewsletterRepository(logger.CreateLogger(), repository.CreateRepository(gormDb))
+	return &newsletterTestContext{mock, sut}
+}
+
+// TestNewsletterRepository_GetSubscriber tests retrieving a subscriber by email address
+func TestNewsletterRepository_GetSubscriber(t *testing.T) {
+	t.Parallel()
+	c := createNewsletterRepositoryContext(t)
+
+	email := "reader@example.com"
+	query := regexp.QuoteMeta("SELECT * FROM `subscribers` WHERE email = ? LIMIT 1")
+	rows := sqlmock.NewRows([]string{"id", "email", "mode", "confirmed"}).AddRow(1, email, "post", true)
+
+	c.mockDb.ExpectQuery(query).WithArgs(email).WillReturnRows(rows)
+
+	subscriber, err := c.sut.GetSubscriber(email)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, uint(1), subscriber.ID, "incorrect subscriber")
+	assert.True(t, subscriber.Confirmed, "subscriber should be confirmed")
+}
+
+// TestNewsletterRepository_GetSubscriber_Not_Found tests retrieving an unknown subscriber
+func TestNewsletterRepository_GetSubscriber_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createNewsletterRepositoryContext(t)
+
+	email := "reader@example.com"
+	query := regexp.QuoteMeta("SELECT * FROM `subscribers` WHERE email = ? LIMIT 1")
+
+	c.mockDb.ExpectQuery(query).WithArgs(email).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	subscriber, err := c.sut.GetSubscriber(email)
+
+	assert.Nil(t, subscriber, "no subscriber should be returned")
+	assert.Equal(t, errortypes.SubscriberNotFoundError{Email: email}, err, "incorrect error type")
+}
+
+// TestNewsletterRepository_AddNewsletterPost_Duplicate tests ignoring a post which was already recorded
+func TestNewsletterRepository_AddNewsletterPost_Duplicate(t *testing.T) {
+	t.Parallel()
+	c := createNewsletterRepositoryContext(t)
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(regexp.QuoteMeta("INSERT INTO `newsletter_posts`")).WillReturnError(fmt.Errorf("1062"))
+	c.mockDb.ExpectRollback()
+
+	err := c.sut.AddNewsletterPost(&repository.NewsletterPost{URLHandle: "testUrlHandle", Title: "Test"})
+
+	assert.Nil(t, err, "duplicates should be ignored")
+}
+
+// TestNewsletterRepository_AddNewsletterPost_Unexpected_Error tests recording a post with an error
+func TestNewsletterRepository_AddNewsletterPost_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createNewsletterRepositoryContext(t)
+
+	expectedError := fmt.Errorf("unexpected error")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(regexp.QuoteMeta("INSERT INTO `newsletter_posts`")).WillReturnError(expectedError)
+	c.mockDb.ExpectRollback()
+
+	err := c.sut.AddNewsletterPost(&repository.NewsletterPost{URLHandle: "testUrlHandle", Title: "Test"})
+
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+}
+
+// TestNewsletterRepository_SetAnnounced tests marking recorded posts as announced
+func TestNewsletterRepository_SetAnnounced(t *testing.T) {
+	t.Parallel()
+	c := createNewsletterRepositoryContext(t)
+
+	query := regexp.QuoteMeta("UPDATE `newsletter_posts` SET `announced`=? WHERE id IN (?,?)")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(query).WithArgs(true, 1, 2).WillReturnResult(sqlmock.NewResult(0, 2))
+	c.mockDb.ExpectCommit()
+
+	err := c.sut.SetAnnounced([]uint{1, 2})
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expectation should be met")
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"encoding/json"
+	"github.com/wlchs/blog/internal/types"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// States of the events stored in the outbox.
+const (
+	OutboxPending    = "pending"
+	OutboxDispatched = "dispatched"
+	OutboxFailed     = "failed"
+)
+
+// OutboxEvent DB schema. Stores the domain events in the transaction of the change they describe until they're dispatched.
+// Pending events are claimed by the instance dispatching them until the claim expires.
+type OutboxEvent struct {
+	ID           uint   `gorm:"primaryKey;autoIncrement"`
+	Name         string `gorm:"not null"`
+	Payload      string `gorm:"not null"`
+	Status       string `gorm:"not null;default:pending;index"`
+	Attempts     int    `gorm:"not null;default:0"`
+	Error        string
+	ClaimedUntil *time.Time
+	DispatchedAt *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// PostEvents builds the domain events of a post mutation from the stored post.
+type PostEvents func(post *Post) []types.DomainEvent
+
+// UserEvents builds the domain events of a user mutation from the stored user.
+type UserEvents func(user *User) []types.DomainEvent
+
+// OutboxRepository interface defining the database operations of the event outbox.
+type OutboxRepository interface {
+	ClaimPendingEvents(now time.Time, limit int, lease time.Duration) ([]OutboxEvent, error)
+	DeleteDispatchedEvents(before time.Time) (int64, error)
+	UpdateEvent(event *OutboxEvent) error
+}
+
+// outboxRepository is the concrete implementation of the OutboxRepository interface.
+type outboxRepository struct {
+	logger     *zap.SugaredLogger
+	repository Repository
+}
+
+// CreateOutboxRepository instantiates the outboxRepository
+func CreateOutboxRepository(logger *zap.SugaredLogger, repository Repository) OutboxRepository {
+	initOutboxModel(logger, repository)
+
+	return &outboxRepository{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// initOutboxModel initializes the OutboxEvent schema in the database
+func initOutboxModel(logger *zap.SugaredLogger, repository Repository) {
+	if err := repository.AutoMigrate(&OutboxEvent{}); err != nil {
+		logger.Errorf("failed to initialize outbox model: %v", err)
+	}
+}
+
+// ClaimPendingEvents claims the events which haven't been dispatched yet and aren't claimed by another instance,
+// starting with the oldest one. The events stay claimed until their outcome is stored or the lease expires.
+func (o outboxRepository) ClaimPendingEvents(now time.Time, limit int, lease time.Duration) ([]OutboxEvent, error) {
+	log := o.logger
+	repo := o.repository
+
+	condition := clause.Expr{SQL: "status = ? AND (claimed_until IS NULL OR claimed_until <= ?)", Vars: []interface{}{OutboxPending, now}}
+	events, err := claimRows[OutboxEvent](repo, condition, "id", limit, "claimed_until", now.Add(lease))
+	if err != nil {
+		log.Debugf("error claiming pending outbox events: %v", err)
+	}
+	return events, err
+}
+
+// DeleteDispatchedEvents removes the events dispatched before the given time and returns the number of removed events.
+// Failed events are kept for inspection.
+func (o outboxRepository) DeleteDispatchedEvents(before time.Time) (int64, error) {
+	log := o.logger
+	repo := o.repository
+
+	result := repo.Where("status = ? AND dispatched_at < ?", OutboxDispatched, before).Delete(&OutboxEvent{})
+	if result.Error != nil {
+		log.Debugf("failed to delete dispatched outbox events, error: %v", result.Error)
+		return 0, result.Error
+	}
+
+	log.Debugf("deleted %d dispatched outbox events", result.RowsAffected)
+	return result.RowsAffected, nil
+}
+
+// UpdateEvent stores the outcome of dispatching an event and releases its claim.
+func (o outboxRepository) UpdateEvent(event *OutboxEvent) error {
+	log := o.logger
+	repo := o.repository
+
+	event.ClaimedUntil = nil
+	if result := repo.Select("Status", "Attempts", "Error", "ClaimedUntil", "DispatchedAt").Updates(event); result.Error != nil {
+		log.Debugf("failed to update outbox event %d, error: %v", event.ID, result.Error)
+		return result.Error
+	}
+
+	log.Debugf("updated outbox event %d: %s after %d attempts", event.ID, event.Status, event.Attempts)
+	return nil
+}
+
+// addOutboxEvents stores the domain events in the outbox using the transaction of the mutation they describe.
+func addOutboxEvents(tx *gorm.DB, events []types.DomainEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	rows := make([]OutboxEvent, 0, len(events))
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, OutboxEvent{Name: event.EventName(), Payload: string(payload), Status: OutboxPending})
+	}
+
+	return tx.Create(&rows).Error
+}
@@ -0,0 +1,152 @@
+package repository_test
+
+import (
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/types"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// outboxTestContext contains objects relevant for testing the OutboxRepository.
+type outboxTestContext struct {
+	mockDb sqlmock.Sqlmock
+	sut    repository.OutboxRepository
+}
+
+// createOutboxRepositoryContext creates the context for testing the OutboxRepository and reduces code duplication.
+func createOutboxRepositoryContext(t *testing.T) *outboxTestContext {
+	t.Helper()
+
+	db, mock, _ := sqlmock.New()
+	gormDb, _ := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}))
+
+	sut := repository.CreateOutboxRepository(logger.CreateLogger(), repository.CreateRepository(gormDb))
+	return &outboxTestContext{mock, sut}
+}
+
+// TestOutboxRepository_DeleteDispatchedEvents tests removing the events dispatched before a given time
+func TestOutboxRepository_DeleteDispatchedEvents(t *testing.T) {
+	t.Parallel()
+	c := createOutboxRepositoryContext(t)
+
+	before := time.Now()
+	query := regexp.QuoteMeta("DELETE FROM `outbox_events` WHERE status = ? AND dispatched_at < ?")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(query).WithArgs(repository.OutboxDispatched, before).WillReturnResult(sqlmock.NewResult(0, 3))
+	c.mockDb.ExpectCommit()
+
+	deleted, err := c.sut.DeleteDispatchedEvents(before)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, int64(3), deleted, "the number of removed events should be returned")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expected query should be executed")
+}
+
+// TestOutboxRepository_DeleteDispatchedEvents_Unexpected_Error tests removing the dispatched events with an error
+func TestOutboxRepository_DeleteDispatchedEvents_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createOutboxRepositoryContext(t)
+
+	query := regexp.QuoteMeta("DELETE FROM `outbox_events` WHERE status = ? AND dispatched_at < ?")
+	expectedError := fmt.Errorf("unexpected error")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(query).WillReturnError(expectedError)
+	c.mockDb.ExpectRollback()
+
+	deleted, err := c.sut.DeleteDispatchedEvents(time.Now())
+
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+	assert.Equal(t, int64(0), deleted, "no events should be counted")
+}
+
+// TestOutboxRepository_ClaimPendingEvents tests claiming the events which haven't been dispatched yet
+func TestOutboxRepository_ClaimPendingEvents(t *testing.T) {
+	t.Parallel()
+	c := createOutboxRepositoryContext(t)
+
+	now := time.Now()
+	query := regexp.QuoteMeta("SELECT * FROM `outbox_events` WHERE status = ? AND (claimed_until IS NULL OR claimed_until <= ?) ORDER BY id LIMIT 10 FOR UPDATE SKIP LOCKED")
+	claimQuery := regexp.QuoteMeta("UPDATE `outbox_events` SET `claimed_until`=? WHERE `id` IN (?,?)")
+	rows := sqlmock.NewRows([]string{"id", "name", "payload", "status"}).
+		AddRow(1, types.EventPostCreated, "{}", repository.OutboxPending).
+		AddRow(2, types.EventUserCreated, "{}", repository.OutboxPending)
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectQuery(query).WithArgs(repository.OutboxPending, now).WillReturnRows(rows)
+	c.mockDb.ExpectExec(claimQuery).WithArgs(now.Add(time.Minute), 1, 2).WillReturnResult(sqlmock.NewResult(0, 2))
+	c.mockDb.ExpectCommit()
+
+	events, err := c.sut.ClaimPendingEvents(now, 10, time.Minute)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 2, len(events), "incorrect number of events")
+	assert.Equal(t, types.EventPostCreated, events[0].Name, "events should be ordered by ID")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "the events should be claimed")
+}
+
+// TestOutboxRepository_ClaimPendingEvents_None tests claiming the pending events when there are none
+func TestOutboxRepository_ClaimPendingEvents_None(t *testing.T) {
+	t.Parallel()
+	c := createOutboxRepositoryContext(t)
+
+	query := regexp.QuoteMeta("SELECT * FROM `outbox_events` WHERE status = ? AND (claimed_until IS NULL OR claimed_until <= ?) ORDER BY id LIMIT 10 FOR UPDATE SKIP LOCKED")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	c.mockDb.ExpectCommit()
+
+	events, err := c.sut.ClaimPendingEvents(time.Now(), 10, time.Minute)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 0, len(events), "no events should be returned")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "nothing should be claimed")
+}
+
+// TestOutboxRepository_ClaimPendingEvents_Unexpected_Error tests claiming the pending events with an error
+func TestOutboxRepository_ClaimPendingEvents_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createOutboxRepositoryContext(t)
+
+	query := regexp.QuoteMeta("SELECT * FROM `outbox_events` WHERE status = ? AND (claimed_until IS NULL OR claimed_until <= ?) ORDER BY id LIMIT 10 FOR UPDATE SKIP LOCKED")
+	expectedError := fmt.Errorf("unexpected error")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectQuery(query).WillReturnError(expectedError)
+	c.mockDb.ExpectRollback()
+
+	events, err := c.sut.ClaimPendingEvents(time.Now(), 10, time.Minute)
+
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+	assert.Equal(t, []repository.OutboxEvent{}, events, "no events should be returned")
+}
+
+// TestOutboxRepository_UpdateEvent tests storing the outcome of dispatching an event
+func TestOutboxRepository_UpdateEvent(t *testing.T) {
+	t.Parallel()
+	c := createOutboxRepositoryContext(t)
+
+	now := time.Now()
+	event := repository.OutboxEvent{ID: 1, Status: repository.OutboxDispatched, Attempts: 1, DispatchedAt: &now}
+	query := regexp.QuoteMeta("UPDATE `outbox_events` SET `status`=?,`attempts`=?,`error`=?,`claimed_until`=?,`dispatched_at`=?,`updated_at`=? WHERE `id` = ?")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(query).WithArgs(repository.OutboxDispatched, 1, "", nil, &now, sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectCommit()
+
+	err := c.sut.UpdateEvent(&event)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expected query should be executed")
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"github.com/wlchs/blog/internal/types"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"sort"
 	"strings"
 	"time"
 
@@ -11,22 +13,69 @@ import (
 
 // Post DB schema
 type Post struct {
-	ID        uint   `gorm:"primaryKey;autoIncrement"`
-	URLHandle string `gorm:"unique;not null"`
-	AuthorID  uint   `gorm:"not null"`
-	Author    User
-	Title     string
-	Summary   string
-	Body      string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID              uint   `gorm:"primaryKey;autoIncrement"`
+	URLHandle       string `gorm:"unique;not null"`
+	AuthorID        uint   `gorm:"not null"`
+	Author          User
+	Contributors    []Contributor `gorm:"foreignKey:PostID"`
+	Title           string
+	Summary         string
+	Body            string
+	Excerpt         string
+	WordCount       int               `gorm:"not null;default:0"`
+	ReadingTime     int               `gorm:"not null;default:0"`
+	Language        string            `gorm:"size:35;not null;default:en"`
+	Translations    []PostTranslation `gorm:"foreignKey:PostID"`
+	Visibility      string            `gorm:"not null;default:public"`
+	PasswordHash    string
+	CustomFields    map[string]interface{} `gorm:"type:json;serializer:json"`
+	Tags            []string               `gorm:"type:json;serializer:json"`
+	CoverImage      string
+	MetaDescription string
+	CanonicalURL    string
+	NoIndex         bool `gorm:"not null;default:false"`
+	Pinned          bool `gorm:"not null;default:false"`
+	Featured        bool `gorm:"not null;default:false"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// Contributor DB schema. Stores the additional contributors of a post and their roles.
+// The primary author of the post is stored on the post itself.
+type Contributor struct {
+	PostID uint `gorm:"primaryKey;autoIncrement:false"`
+	UserID uint `gorm:"primaryKey;autoIncrement:false"`
+	User   User
+	Role   string `gorm:"not null"`
+}
+
+// PostTranslation DB schema. Stores the translated content of a post in a given language.
+// The content in the original language of the post is stored on the post itself.
+type PostTranslation struct {
+	PostID      uint   `gorm:"primaryKey;autoIncrement:false"`
+	Language    string `gorm:"primaryKey;size:35"`
+	Title       string
+	Summary     string
+	Body        string
+	Excerpt     string
+	WordCount   int `gorm:"not null;default:0"`
+	ReadingTime int `gorm:"not null;default:0"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
 }
 
 // PostRepository interface defining post-related database operations.
 type PostRepository interface {
-	AddPost(post *types.Post, authorID uint) (*Post, error)
+	AddPost(post *types.Post, authorID uint, contributors []Contributor, events PostEvents) (*Post, error)
+	DeletePost(postID uint, events []types.DomainEvent) error
+	DeleteTranslation(postID uint, language string, events []types.DomainEvent) error
+	GetAllPosts() ([]Post, error)
 	GetPost(urlHandle string) (*Post, error)
-	GetPosts() ([]Post, error)
+	GetPosts(filter types.PostFilter) ([]Post, error)
+	SetContributors(postID uint, contributors []Contributor, events []types.DomainEvent) error
+	SetHighlight(post *Post, highlight types.PostHighlight, events PostEvents) error
+	SetTranslation(translation *PostTranslation, events []types.DomainEvent) error
+	UpdatePost(post *types.Post, events PostEvents) (*Post, error)
 }
 
 // postRepository is the concrete implementation of the PostRepository interface.
@@ -45,37 +94,166 @@ func CreatePostRepository(logger *zap.SugaredLogger, repository Repository) Post
 	}
 }
 
-// initPostModel initializes the Post schema in the database
+// initPostModel initializes the Post, Contributor and PostTranslation schemas in the database
 func initPostModel(logger *zap.SugaredLogger, repository Repository) {
 	if err := repository.AutoMigrate(&Post{}); err != nil {
 		logger.Errorf("failed to initialize post model: %v", err)
 	}
+	if err := repository.AutoMigrate(&Contributor{}); err != nil {
+		logger.Errorf("failed to initialize contributor model: %v", err)
+	}
+	if err := repository.AutoMigrate(&PostTranslation{}); err != nil {
+		logger.Errorf("failed to initialize post translation model: %v", err)
+	}
 }
 
 // AddPost adds a new post with the provided fields to the database.
-// The second parameter holds information about the author.
-func (p postRepository) AddPost(post *types.Post, authorID uint) (*Post, error) {
+// The second parameter holds information about the author, the third one the additional contributors.
+// If the creation time of the post is provided, it is preserved, otherwise the current time is used.
+// The events built from the created post are stored in the outbox together with it.
+func (p postRepository) AddPost(post *types.Post, authorID uint, contributors []Contributor, events PostEvents) (*Post, error) {
 	log := p.logger
 	repo := p.repository
 
+	// Only reference the contributing users, they must not be upserted together with the post
+	newContributors := make([]Contributor, 0, len(contributors))
+	for _, contributor := range contributors {
+		newContributors = append(newContributors, Contributor{UserID: contributor.UserID, Role: contributor.Role})
+	}
+
+	seo := postSEO(post)
 	newPost := Post{
-		URLHandle: post.URLHandle,
-		Title:     post.Title,
-		Summary:   post.Summary,
-		Body:      post.Body,
-		AuthorID:  authorID,
+		URLHandle:       post.URLHandle,
+		Title:           post.Title,
+		Summary:         post.Summary,
+		Body:            post.Body,
+		Excerpt:         post.Excerpt,
+		WordCount:       post.WordCount,
+		ReadingTime:     post.ReadingTime,
+		Language:        post.Language,
+		Visibility:      post.Visibility,
+		PasswordHash:    post.PasswordHash,
+		CustomFields:    post.CustomFields,
+		Tags:            post.Tags,
+		CoverImage:      postCoverImage(post),
+		MetaDescription: seo.MetaDescription,
+		CanonicalURL:    seo.CanonicalURL,
+		NoIndex:         seo.NoIndex,
+		AuthorID:        authorID,
+		Contributors:    newContributors,
+		CreatedAt:       post.CreationTime,
 	}
 
-	if result := repo.Create(&newPost); result.Error == nil {
-		log.Debugf("created post: %v", newPost)
+	err := repo.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&newPost).Error; err != nil {
+			return err
+		}
+		return addOutboxEvents(tx, postEvents(events, &newPost))
+	})
+
+	if err == nil {
+		log.Debugf("created post: %s", newPost.URLHandle)
 		return &newPost, nil
-	} else if strings.Contains(result.Error.Error(), "1062") {
-		log.Debugf("failed to create post, duplicate key: %s, error: %v", post.URLHandle, result.Error)
+	} else if strings.Contains(err.Error(), "1062") {
+		log.Debugf("failed to create post, duplicate key: %s, error: %v", post.URLHandle, err)
 		return nil, errortypes.DuplicateElementError{Key: post.URLHandle}
 	} else {
-		log.Debugf("failed to create post: %s, error: %s", post, result.Error)
-		return nil, result.Error
+		log.Debugf("failed to create post: %s, error: %s", post.URLHandle, err)
+		return nil, err
+	}
+}
+
+// DeletePost removes the post with the given ID from the database together with its contributors, translations,
+// series and collection memberships, received webmentions, reactions, views and related posts.
+// The given events are stored in the outbox in the same transaction.
+func (p postRepository) DeletePost(postID uint, events []types.DomainEvent) error {
+	log := p.logger
+	repo := p.repository
+
+	err := repo.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("post_id = ?", postID).Delete(&Contributor{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id = ?", postID).Delete(&PostTranslation{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id = ?", postID).Delete(&SeriesPost{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id = ?", postID).Delete(&CollectionPost{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id = ?", postID).Delete(&Webmention{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id = ?", postID).Delete(&Reaction{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id = ?", postID).Delete(&PageView{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id = ?", postID).Delete(&ViewRollup{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id = ?", postID).Delete(&ReferrerRollup{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id = ? OR related_id = ?", postID, postID).Delete(&RelatedPost{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&Post{}, postID).Error; err != nil {
+			return err
+		}
+		return addOutboxEvents(tx, events)
+	})
+
+	if err != nil {
+		log.Debugf("failed to delete post %d, error: %v", postID, err)
+		return err
+	}
+
+	log.Debugf("deleted post: %d", postID)
+	return nil
+}
+
+// DeleteTranslation removes the translation of the post with the given ID in the given language.
+// The update time of the post is bumped and the given events are stored in the outbox in the same transaction.
+func (p postRepository) DeleteTranslation(postID uint, language string, events []types.DomainEvent) error {
+	log := p.logger
+	repo := p.repository
+
+	err := repo.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("post_id = ? AND language = ?", postID, language).Delete(&PostTranslation{}).Error; err != nil {
+			return err
+		}
+		if err := touchPosts(tx, "id = ?", postID); err != nil {
+			return err
+		}
+		return addOutboxEvents(tx, events)
+	})
+
+	if err != nil {
+		log.Debugf("failed to delete translation %s of post %d: %v", language, postID, err)
+		return err
 	}
+
+	log.Debugf("deleted translation %s of post %d", language, postID)
+	return nil
+}
+
+// GetAllPosts retrieves every post from the database regardless of its visibility, ordered by creation time.
+func (p postRepository) GetAllPosts() ([]Post, error) {
+	log := p.logger
+	repo := p.repository
+
+	var posts []Post
+	if result := repo.Preload("Author").Preload("Contributors.User").Preload("Translations").Order("created_at").Find(&posts); result.Error != nil {
+		log.Debugf("error fetching all posts: %v", result.Error)
+		return []Post{}, result.Error
+	}
+
+	log.Debugf("fetched all posts: %d", len(posts))
+	return posts, nil
 }
 
 // GetPost retrieves the post with the given URL-handle from the database.
@@ -87,7 +265,7 @@ func (p postRepository) GetPost(urlHandle string) (*Post, error) {
 		URLHandle: urlHandle,
 	}
 
-	result := repo.Preload("Author").Where(&post).Take(&post)
+	result := repo.Preload("Author").Preload("Contributors.User").Preload("Translations").Where(&post).Take(&post)
 
 	if result.Error != nil {
 		log.Debugf("failed to retrieve post with handle: %s, error: %v", urlHandle, result.Error)
@@ -97,21 +275,256 @@ func (p postRepository) GetPost(urlHandle string) (*Post, error) {
 		return nil, result.Error
 	}
 
-	log.Debugf("retrieved post: %v", post)
+	log.Debugf("retrieved post: %s", post.URLHandle)
 	return &post, nil
 }
 
-// GetPosts retrieves every post from the database.
-func (p postRepository) GetPosts() ([]Post, error) {
+// GetPosts retrieves every public post from the database.
+// Unlisted, private and password-protected posts are excluded from listings.
+// If a language is provided, only the posts written in or translated to the given language are retrieved.
+// Custom field filters only match posts whose field values are equal to the provided ones.
+// The posts are ordered by their creation time, newest first, optionally starting with the pinned ones. The posts of a
+// collection are ordered by their position within the collection instead.
+func (p postRepository) GetPosts(filter types.PostFilter) ([]Post, error) {
 	log := p.logger
 	repo := p.repository
 
+	query := repo.Preload("Author").Preload("Contributors.User").Preload("Translations").Where("visibility = ?", types.VisibilityPublic)
+	if filter.Language != "" {
+		query = query.Where("language = ? OR id IN (SELECT post_id FROM post_translations WHERE language = ?)", filter.Language, filter.Language)
+	}
+
+	names := make([]string, 0, len(filter.Fields))
+	for name := range filter.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		query = query.Where("JSON_UNQUOTE(JSON_EXTRACT(custom_fields, ?)) = ?", customFieldPath(name), filter.Fields[name])
+	}
+
+	if filter.Featured {
+		query = query.Where("featured = ?", true)
+	}
+
+	switch {
+	case filter.Collection != "":
+		collectionID := repo.Select("id").Where("url_handle = ?", filter.Collection).Table("collections")
+		query = query.Joins("JOIN collection_posts ON collection_posts.post_id = posts.id AND collection_posts.collection_id = (?)", collectionID).
+			Order("collection_posts.position")
+	case filter.PinnedFirst:
+		query = query.Order("pinned DESC").Order("created_at DESC")
+	default:
+		query = query.Order("created_at DESC")
+	}
+
 	var posts []Post
-	if result := repo.Preload("Author").Order("created_at DESC").Find(&posts); result.Error != nil {
+	if result := query.Find(&posts); result.Error != nil {
 		log.Debugf("error fetching posts: %v", result.Error)
 		return []Post{}, result.Error
 	}
 
-	log.Debugf("fetched posts: %v", posts)
+	log.Debugf("fetched posts: %d", len(posts))
 	return posts, nil
 }
+
+// SetContributors replaces the additional contributors of the post with the given ID.
+// The update time of the post is bumped and the given events are stored in the outbox in the same transaction.
+func (p postRepository) SetContributors(postID uint, contributors []Contributor, events []types.DomainEvent) error {
+	log := p.logger
+	repo := p.repository
+
+	for i := range contributors {
+		contributors[i].PostID = postID
+	}
+
+	err := repo.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("post_id = ?", postID).Delete(&Contributor{}).Error; err != nil {
+			return err
+		}
+		if len(contributors) > 0 {
+			if err := tx.Omit("User").Create(&contributors).Error; err != nil {
+				return err
+			}
+		}
+		if err := touchPosts(tx, "id = ?", postID); err != nil {
+			return err
+		}
+		return addOutboxEvents(tx, events)
+	})
+
+	if err != nil {
+		log.Debugf("failed to set contributors of post %d: %v", postID, err)
+		return err
+	}
+
+	log.Debugf("set contributors of post %d: %v", postID, contributors)
+	return nil
+}
+
+// SetHighlight sets whether the post is pinned to the top of the listings and whether it is featured.
+// The update time of the post is bumped and the events built from the updated post are stored in the outbox in the same transaction.
+func (p postRepository) SetHighlight(post *Post, highlight types.PostHighlight, events PostEvents) error {
+	log := p.logger
+	repo := p.repository
+
+	post.Pinned = highlight.Pinned
+	post.Featured = highlight.Featured
+	post.UpdatedAt = time.Now()
+
+	err := repo.Transaction(func(tx *gorm.DB) error {
+		columns := map[string]interface{}{"pinned": post.Pinned, "featured": post.Featured, "updated_at": post.UpdatedAt}
+		if err := tx.Model(&Post{}).Where("id = ?", post.ID).UpdateColumns(columns).Error; err != nil {
+			return err
+		}
+		return addOutboxEvents(tx, postEvents(events, post))
+	})
+
+	if err != nil {
+		log.Debugf("failed to highlight post %d, error: %v", post.ID, err)
+		return err
+	}
+
+	log.Debugf("highlighted post %d: %v", post.ID, highlight)
+	return nil
+}
+
+// SetTranslation creates or replaces the translation of a post in the language of the translation.
+// The update time of the post is bumped and the given events are stored in the outbox in the same transaction.
+func (p postRepository) SetTranslation(translation *PostTranslation, events []types.DomainEvent) error {
+	log := p.logger
+	repo := p.repository
+
+	err := repo.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("post_id = ? AND language = ?", translation.PostID, translation.Language).Delete(&PostTranslation{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(translation).Error; err != nil {
+			return err
+		}
+		if err := touchPosts(tx, "id = ?", translation.PostID); err != nil {
+			return err
+		}
+		return addOutboxEvents(tx, events)
+	})
+
+	if err != nil {
+		log.Debugf("failed to set translation %s of post %d: %v", translation.Language, translation.PostID, err)
+		return err
+	}
+
+	log.Debugf("set translation of post %d: %v", translation.PostID, translation)
+	return nil
+}
+
+// UpdatePost updates the title, summary, body, content statistics, visibility settings, custom fields and tags of an
+// existing post, and its creation time if given. The events built from the updated post are stored in the outbox together with the changes.
+// If the title or the visibility changes, the update time of the other posts of its series is bumped as well, since
+// their pages link to the post.
+func (p postRepository) UpdatePost(post *types.Post, events PostEvents) (*Post, error) {
+	log := p.logger
+	repo := p.repository
+
+	existingPost, err := p.GetPost(post.URLHandle)
+	if err != nil {
+		return nil, err
+	}
+
+	// The update time is set explicitly, so the events carry the same one as the stored post
+	now := time.Now()
+	seo := postSEO(post)
+	fields := Post{
+		Title:           post.Title,
+		Summary:         post.Summary,
+		Body:            post.Body,
+		Excerpt:         post.Excerpt,
+		WordCount:       post.WordCount,
+		ReadingTime:     post.ReadingTime,
+		Visibility:      post.Visibility,
+		PasswordHash:    post.PasswordHash,
+		CustomFields:    post.CustomFields,
+		Tags:            post.Tags,
+		CoverImage:      postCoverImage(post),
+		MetaDescription: seo.MetaDescription,
+		CanonicalURL:    seo.CanonicalURL,
+		NoIndex:         seo.NoIndex,
+		UpdatedAt:       now,
+	}
+
+	neighboursChanged := existingPost.Title != post.Title || existingPost.Visibility != post.Visibility
+
+	existingPost.Title = post.Title
+	existingPost.Summary = post.Summary
+	existingPost.Body = post.Body
+	existingPost.Excerpt = post.Excerpt
+	existingPost.WordCount = post.WordCount
+	existingPost.ReadingTime = post.ReadingTime
+	existingPost.Visibility = post.Visibility
+	existingPost.PasswordHash = post.PasswordHash
+	existingPost.CustomFields = post.CustomFields
+	existingPost.Tags = post.Tags
+	existingPost.CoverImage = fields.CoverImage
+	existingPost.MetaDescription = seo.MetaDescription
+	existingPost.CanonicalURL = seo.CanonicalURL
+	existingPost.NoIndex = seo.NoIndex
+	existingPost.UpdatedAt = now
+
+	columns := []string{"title", "summary", "body", "excerpt", "word_count", "reading_time", "visibility", "password_hash", "custom_fields", "tags", "cover_image", "meta_description", "canonical_url", "no_index", "updated_at"}
+	// The creation time is only changed if given, e.g. by the date of an imported file
+	if !post.CreationTime.IsZero() {
+		fields.CreatedAt = post.CreationTime
+		existingPost.CreatedAt = post.CreationTime
+		columns = append(columns, "created_at")
+	}
+
+	err = repo.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select(columns).Where("id = ?", existingPost.ID).UpdateColumns(&fields).Error; err != nil {
+			return err
+		}
+		if neighboursChanged {
+			if err := touchPosts(tx, "id IN (?)", seriesNeighbours(tx, existingPost.ID)); err != nil {
+				return err
+			}
+		}
+		return addOutboxEvents(tx, postEvents(events, existingPost))
+	})
+
+	if err != nil {
+		log.Debugf("failed to update post %s, error: %v", post.URLHandle, err)
+		return nil, err
+	}
+
+	log.Debugf("updated post: %s", existingPost.URLHandle)
+	return existingPost, nil
+}
+
+// touchPosts bumps the update time of the posts matching the condition, so the changes of the data shown on their pages,
+// e.g. their contributors, translations or series, are picked up by the incremental builds of the static site.
+func touchPosts(tx *gorm.DB, query interface{}, args ...interface{}) error {
+	return tx.Model(&Post{}).Where(query, args...).UpdateColumn("updated_at", time.Now()).Error
+}
+
+// postEvents builds the events of a post mutation, if any.
+func postEvents(events PostEvents, post *Post) []types.DomainEvent {
+	if events == nil {
+		return nil
+	}
+	return events(post)
+}
+
+// postSEO returns the search engine settings of a post, which are empty if none were provided.
+func postSEO(post *types.Post) types.PostSEO {
+	if post.SEO == nil {
+		return types.PostSEO{}
+	}
+	return *post.SEO
+}
+
+// postCoverImage returns the cover image of a post, which is empty if none was provided.
+func postCoverImage(post *types.Post) string {
+	if post.CoverImage == nil {
+		return ""
+	}
+	return *post.CoverImage
+}
@@ -12,6 +12,7 @@ import (
 	"gorm.io/gorm"
 	"regexp"
 	"testing"
+	"time"
 )
 
 // postTestContext contains objects relevant for testing the PostRepository.
@@ -47,13 +48,13 @@ func TestPostRepository_AddPost(t *testing.T) {
 		URLHandle: inputPost.URLHandle,
 	}
 
-	postQuery := regexp.QuoteMeta("INSERT INTO `posts` (`url_handle`,`author_id`,`title`,`summary`,`body`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?)")
+	postQuery := regexp.QuoteMeta("INSERT INTO `posts` (`url_handle`,`author_id`,`title`,`summary`,`body`,`excerpt`,`word_count`,`reading_time`,`language`,`visibility`,`password_hash`,`custom_fields`,`tags`,`cover_image`,`meta_description`,`canonical_url`,`no_index`,`pinned`,`featured`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)")
 
 	c.mockDb.ExpectBegin()
 	c.mockDb.ExpectExec(postQuery).WillReturnResult(sqlmock.NewResult(0, 1))
 	c.mockDb.ExpectCommit()
 
-	post, err := c.sut.AddPost(inputPost, 0)
+	post, err := c.sut.AddPost(inputPost, 0, nil, nil)
 
 	assert.Nil(t, err, "should complete without error")
 	assert.Equal(t, expectedPost.URLHandle, post.URLHandle, "received post should match the expected one")
@@ -71,13 +72,13 @@ func TestPostRepository_AddPost_Duplicate_Post(t *testing.T) {
 	dbErr := fmt.Errorf("1062")
 	expectedError := errortypes.DuplicateElementError{Key: inputPost.URLHandle}
 
-	postQuery := regexp.QuoteMeta("INSERT INTO `posts` (`url_handle`,`author_id`,`title`,`summary`,`body`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?)")
+	postQuery := regexp.QuoteMeta("INSERT INTO `posts` (`url_handle`,`author_id`,`title`,`summary`,`body`,`excerpt`,`word_count`,`reading_time`,`language`,`visibility`,`password_hash`,`custom_fields`,`tags`,`cover_image`,`meta_description`,`canonical_url`,`no_index`,`pinned`,`featured`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)")
 
 	c.mockDb.ExpectBegin()
 	c.mockDb.ExpectExec(postQuery).WillReturnError(dbErr)
 	c.mockDb.ExpectRollback()
 
-	post, err := c.sut.AddPost(inputPost, 0)
+	post, err := c.sut.AddPost(inputPost, 0, nil, nil)
 
 	assert.Nil(t, post, "should not return a post")
 	assert.Equal(t, expectedError, err, "received error should match the expected one")
@@ -94,13 +95,13 @@ func TestPostRepository_AddPost_Unexpected_Error(t *testing.T) {
 
 	expectedError := fmt.Errorf("unexpected error")
 
-	postQuery := regexp.QuoteMeta("INSERT INTO `posts` (`url_handle`,`author_id`,`title`,`summary`,`body`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?)")
+	postQuery := regexp.QuoteMeta("INSERT INTO `posts` (`url_handle`,`author_id`,`title`,`summary`,`body`,`excerpt`,`word_count`,`reading_time`,`language`,`visibility`,`password_hash`,`custom_fields`,`tags`,`cover_image`,`meta_description`,`canonical_url`,`no_index`,`pinned`,`featured`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)")
 
 	c.mockDb.ExpectBegin()
 	c.mockDb.ExpectExec(postQuery).WillReturnError(expectedError)
 	c.mockDb.ExpectRollback()
 
-	post, err := c.sut.AddPost(inputPost, 0)
+	post, err := c.sut.AddPost(inputPost, 0, nil, nil)
 
 	assert.Nil(t, post, "should not return a post")
 	assert.Equal(t, expectedError, err, "received error should match the expected one")
@@ -169,31 +170,473 @@ func TestPostRepository_GetPosts(t *testing.T) {
 	t.Parallel()
 	c := createPostRepositoryContext(t)
 
-	query := regexp.QuoteMeta("SELECT * FROM `posts` ORDER BY created_at DESC")
+	query := regexp.QuoteMeta("SELECT * FROM `posts` WHERE visibility = ? ORDER BY created_at DESC")
 
 	c.mockDb.ExpectQuery(query).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "url_handle"}).
 			AddRow(1, "test_1").
 			AddRow(2, "test_2"))
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `contributors` WHERE `contributors`.`post_id` IN (?,?)")).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "user_id", "role"}))
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `post_translations` WHERE `post_translations`.`post_id` IN (?,?)")).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "language"}))
 
-	posts, err := c.sut.GetPosts()
+	posts, err := c.sut.GetPosts(types.PostFilter{})
 
 	assert.Nil(t, err, "should complete without error")
 	assert.Equal(t, 2, len(posts), "didn't receive the expected number of posts")
 }
 
+// TestPostRepository_GetAllPosts tests retrieving every post regardless of its visibility from the database
+func TestPostRepository_GetAllPosts(t *testing.T) {
+	t.Parallel()
+	c := createPostRepositoryContext(t)
+
+	query := regexp.QuoteMeta("SELECT * FROM `posts` ORDER BY created_at")
+
+	c.mockDb.ExpectQuery(query).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url_handle", "visibility", "tags"}).
+			AddRow(1, "test_1", types.VisibilityPublic, `["go"]`).
+			AddRow(2, "test_2", types.VisibilityPrivate, nil))
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `contributors` WHERE `contributors`.`post_id` IN (?,?)")).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "user_id", "role"}))
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `post_translations` WHERE `post_translations`.`post_id` IN (?,?)")).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "language"}))
+
+	posts, err := c.sut.GetAllPosts()
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 2, len(posts), "didn't receive the expected number of posts")
+	assert.Equal(t, []string{"go"}, posts[0].Tags, "tags should be deserialized")
+}
+
+// TestPostRepository_GetPosts_Language tests retrieving every post available in a given language from the database
+func TestPostRepository_GetPosts_Language(t *testing.T) {
+	t.Parallel()
+	c := createPostRepositoryContext(t)
+
+	query := regexp.QuoteMeta("SELECT * FROM `posts` WHERE visibility = ? AND (language = ? OR id IN (SELECT post_id FROM post_translations WHERE language = ?)) ORDER BY created_at DESC")
+
+	c.mockDb.ExpectQuery(query).
+		WithArgs(types.VisibilityPublic, "de", "de").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url_handle", "language"}).
+			AddRow(1, "test_1", "en"))
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `contributors` WHERE `contributors`.`post_id` = ?")).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "user_id", "role"}))
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `post_translations` WHERE `post_translations`.`post_id` = ?")).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "language", "title"}).
+			AddRow(1, "de", "Titel"))
+
+	posts, err := c.sut.GetPosts(types.PostFilter{Language: "de"})
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 1, len(posts), "didn't receive the expected number of posts")
+	assert.Equal(t, "Titel", posts[0].Translations[0].Title, "translations should be preloaded")
+}
+
+// TestPostRepository_GetPosts_Custom_Fields tests retrieving every post with matching custom field values from the database
+func TestPostRepository_GetPosts_Custom_Fields(t *testing.T) {
+	t.Parallel()
+	c := createPostRepositoryContext(t)
+
+	filter := types.PostFilter{Fields: map[string]string{"featured": "true", "category": "news"}}
+	query := regexp.QuoteMeta("SELECT * FROM `posts` WHERE visibility = ? AND JSON_UNQUOTE(JSON_EXTRACT(custom_fields, ?)) = ? AND JSON_UNQUOTE(JSON_EXTRACT(custom_fields, ?)) = ? ORDER BY created_at DESC")
+
+	c.mockDb.ExpectQuery(query).
+		WithArgs(types.VisibilityPublic, `$."category"`, "news", `$."featured"`, "true").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url_handle", "custom_fields"}).
+			AddRow(1, "test_1", `{"category":"news","featured":"true"}`))
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `contributors`")).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "user_id", "role"}))
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `post_translations`")).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "language"}))
+
+	posts, err := c.sut.GetPosts(filter)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 1, len(posts), "didn't receive the expected number of posts")
+	assert.Equal(t, "news", posts[0].CustomFields["category"], "custom fields should be deserialized")
+}
+
+// TestPostRepository_GetPosts_Featured_Pinned_First tests retrieving the featured posts with the pinned ones first from the database
+func TestPostRepository_GetPosts_Featured_Pinned_First(t *testing.T) {
+	t.Parallel()
+	c := createPostRepositoryContext(t)
+
+	query := regexp.QuoteMeta("SELECT * FROM `posts` WHERE visibility = ? AND featured = ? ORDER BY pinned DESC,created_at DESC")
+
+	c.mockDb.ExpectQuery(query).
+		WithArgs(types.VisibilityPublic, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url_handle", "pinned", "featured"}).
+			AddRow(1, "test_1", true, true))
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `contributors`")).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "user_id", "role"}))
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `post_translations`")).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "language"}))
+
+	posts, err := c.sut.GetPosts(types.PostFilter{Featured: true, PinnedFirst: true})
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 1, len(posts), "didn't receive the expected number of posts")
+	assert.True(t, posts[0].Pinned, "post should be pinned")
+}
+
+// TestPostRepository_GetPosts_Collection tests retrieving the posts of a collection in their curated order from the database
+func TestPostRepository_GetPosts_Collection(t *testing.T) {
+	t.Parallel()
+	c := createPostRepositoryContext(t)
+
+	query := regexp.QuoteMeta("JOIN collection_posts ON collection_posts.post_id = posts.id AND collection_posts.collection_id = (SELECT id FROM `collections` WHERE url_handle = ?) WHERE visibility = ? ORDER BY collection_posts.position")
+
+	c.mockDb.ExpectQuery(query).
+		WithArgs("start-here", types.VisibilityPublic).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url_handle"}).
+			AddRow(2, "test_2").
+			AddRow(1, "test_1"))
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `contributors`")).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "user_id", "role"}))
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `post_translations`")).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "language"}))
+
+	posts, err := c.sut.GetPosts(types.PostFilter{Collection: "start-here", PinnedFirst: true})
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "test_2", posts[0].URLHandle, "posts should keep the order of the collection")
+}
+
 // TestPostRepository_GetPosts_Unexpected_Error tests retrieving every post from the database with an error
 func TestPostRepository_GetPosts_Unexpected_Error(t *testing.T) {
 	t.Parallel()
 	c := createPostRepositoryContext(t)
 
-	query := regexp.QuoteMeta("SELECT * FROM `posts` ORDER BY created_at DESC")
+	query := regexp.QuoteMeta("SELECT * FROM `posts` WHERE visibility = ? ORDER BY created_at DESC")
 	expectedError := fmt.Errorf("unexpected error")
 
 	c.mockDb.ExpectQuery(query).WillReturnError(expectedError)
 
-	posts, err := c.sut.GetPosts()
+	posts, err := c.sut.GetPosts(types.PostFilter{})
 
 	assert.Equal(t, expectedError, err, "error should match expected value")
 	assert.Equal(t, 0, len(posts), "shouldn't receive any posts")
 }
+
+// TestPostRepository_AddPost_Contributors tests adding a new post with additional contributors
+func TestPostRepository_AddPost_Contributors(t *testing.T) {
+	t.Parallel()
+	c := createPostRepositoryContext(t)
+
+	inputPost := &types.Post{URLHandle: "testHandle"}
+	contributors := []repository.Contributor{{UserID: 2, User: repository.User{ID: 2, UserName: "coAuthor"}, Role: types.ContributorRoleEditor}}
+
+	postQuery := regexp.QuoteMeta("INSERT INTO `posts` (`url_handle`,`author_id`,`title`,`summary`,`body`,`excerpt`,`word_count`,`reading_time`,`language`,`visibility`,`password_hash`,`custom_fields`,`tags`,`cover_image`,`meta_description`,`canonical_url`,`no_index`,`pinned`,`featured`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)")
+	contributorQuery := regexp.QuoteMeta("INSERT INTO `contributors` (`post_id`,`user_id`,`role`) VALUES (?,?,?) ON DUPLICATE KEY UPDATE `post_id`=VALUES(`post_id`)")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(postQuery).WillReturnResult(sqlmock.NewResult(1, 1))
+	c.mockDb.ExpectExec(contributorQuery).WithArgs(1, 2, types.ContributorRoleEditor).WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectCommit()
+
+	post, err := c.sut.AddPost(inputPost, 1, contributors, nil)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 1, len(post.Contributors), "contributor should be stored with the post")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expected query should be executed")
+}
+
+// TestPostRepository_AddPost_Events tests storing the events of a new post in the outbox in the same transaction
+func TestPostRepository_AddPost_Events(t *testing.T) {
+	t.Parallel()
+	c := createPostRepositoryContext(t)
+
+	inputPost := &types.Post{URLHandle: "testHandle"}
+	events := func(post *repository.Post) []types.DomainEvent {
+		return []types.DomainEvent{types.PostCreatedEvent{Post: types.Post{URLHandle: post.URLHandle}}}
+	}
+
+	postQuery := regexp.QuoteMeta("INSERT INTO `posts`")
+	outboxQuery := regexp.QuoteMeta("INSERT INTO `outbox_events` (`name`,`payload`,`status`,`attempts`,`error`,`claimed_until`,`dispatched_at`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?)")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(postQuery).WillReturnResult(sqlmock.NewResult(1, 1))
+	c.mockDb.ExpectExec(outboxQuery).WithArgs(types.EventPostCreated, sqlmock.AnyArg(), repository.OutboxPending, 0, "", nil, nil, sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+	c.mockDb.ExpectCommit()
+
+	_, err := c.sut.AddPost(inputPost, 1, nil, events)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expected query should be executed")
+}
+
+// TestPostRepository_AddPost_Events_Unexpected_Error tests rolling back a new post if its events can't be stored
+func TestPostRepository_AddPost_Events_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createPostRepositoryContext(t)
+
+	inputPost := &types.Post{URLHandle: "testHandle"}
+	events := func(post *repository.Post) []types.DomainEvent {
+		return []types.DomainEvent{types.PostCreatedEvent{Post: types.Post{URLHandle: post.URLHandle}}}
+	}
+	expectedError := fmt.Errorf("unexpected error")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(regexp.QuoteMeta("INSERT INTO `posts`")).WillReturnResult(sqlmock.NewResult(1, 1))
+	c.mockDb.ExpectExec(regexp.QuoteMeta("INSERT INTO `outbox_events`")).WillReturnError(expectedError)
+	c.mockDb.ExpectRollback()
+
+	post, err := c.sut.AddPost(inputPost, 1, nil, events)
+
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+	assert.Nil(t, post, "post shouldn't be returned")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expected query should be executed")
+}
+
+// TestPostRepository_SetContributors tests replacing the contributors of a post
+func TestPostRepository_SetContributors(t *testing.T) {
+	t.Parallel()
+	c := createPostRepositoryContext(t)
+
+	contributors := []repository.Contributor{
+		{UserID: 2, Role: types.ContributorRoleEditor},
+		{UserID: 3, Role: types.ContributorRoleReviewer},
+	}
+
+	touchQuery := regexp.QuoteMeta("UPDATE `posts` SET `updated_at`=? WHERE id = ?")
+	deleteQuery := regexp.QuoteMeta("DELETE FROM `contributors` WHERE post_id = ?")
+	insertQuery := regexp.QuoteMeta("INSERT INTO `contributors` (`post_id`,`user_id`,`role`) VALUES (?,?,?),(?,?,?)")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(deleteQuery).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectExec(insertQuery).WithArgs(1, 2, types.ContributorRoleEditor, 1, 3, types.ContributorRoleReviewer).WillReturnResult(sqlmock.NewResult(0, 2))
+	c.mockDb.ExpectExec(touchQuery).WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectCommit()
+
+	err := c.sut.SetContributors(1, contributors, nil)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expected query should be executed")
+}
+
+// TestPostRepository_SetContributors_Unexpected_Error tests replacing the contributors of a post with an error
+func TestPostRepository_SetContributors_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createPostRepositoryContext(t)
+
+	deleteQuery := regexp.QuoteMeta("DELETE FROM `contributors` WHERE post_id = ?")
+	expectedError := fmt.Errorf("unexpected error")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(deleteQuery).WillReturnError(expectedError)
+	c.mockDb.ExpectRollback()
+
+	err := c.sut.SetContributors(1, []repository.Contributor{}, nil)
+
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+}
+
+// TestPostRepository_SetHighlight tests pinning and featuring a post, bumping its update time
+func TestPostRepository_SetHighlight(t *testing.T) {
+	t.Parallel()
+	c := createPostRepositoryContext(t)
+
+	updateQuery := regexp.QuoteMeta("UPDATE `posts` SET `featured`=?,`pinned`=?,`updated_at`=? WHERE id = ?")
+	post := repository.Post{ID: 1}
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(updateQuery).WithArgs(false, true, sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectCommit()
+
+	err := c.sut.SetHighlight(&post, types.PostHighlight{Pinned: true}, nil)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.True(t, post.Pinned, "post should be pinned")
+	assert.False(t, post.UpdatedAt.IsZero(), "update time should be bumped")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expected query should be executed")
+}
+
+// TestPostRepository_UpdatePost tests updating the content of a post
+func TestPostRepository_UpdatePost(t *testing.T) {
+	t.Parallel()
+	c := createPostRepositoryContext(t)
+
+	selectQuery := regexp.QuoteMeta("SELECT * FROM `posts` WHERE `posts`.`url_handle` = ? LIMIT 1")
+	updateQuery := regexp.QuoteMeta("UPDATE `posts` SET `title`=?,`summary`=?,`body`=?,`excerpt`=?,`word_count`=?,`reading_time`=?,`visibility`=?,`password_hash`=?,`custom_fields`=?,`tags`=?,`cover_image`=?,`meta_description`=?,`canonical_url`=?,`no_index`=?,`updated_at`=? WHERE id = ?")
+
+	touchQuery := regexp.QuoteMeta("UPDATE `posts` SET `updated_at`=? WHERE id IN (SELECT `post_id` FROM `series_posts` WHERE series_id IN (SELECT `series_id` FROM `series_posts` WHERE post_id = ?) AND post_id <> ?)")
+
+	c.mockDb.ExpectQuery(selectQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url_handle"}).AddRow(0, "testHandle"))
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(updateQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+	// The new title is shown on the pages of the other posts of the series
+	c.mockDb.ExpectExec(touchQuery).WithArgs(sqlmock.AnyArg(), 0, 0).WillReturnResult(sqlmock.NewResult(0, 2))
+	c.mockDb.ExpectCommit()
+
+	var eventTime time.Time
+	events := func(post *repository.Post) []types.DomainEvent {
+		eventTime = post.UpdatedAt
+		return nil
+	}
+
+	post, err := c.sut.UpdatePost(&types.Post{URLHandle: "testHandle", Title: "newTitle", Body: "newBody"}, events)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "newTitle", post.Title, "title should be updated")
+	assert.Equal(t, "newBody", post.Body, "body should be updated")
+	assert.False(t, post.UpdatedAt.IsZero(), "update time should be refreshed")
+	assert.Equal(t, post.UpdatedAt, eventTime, "events should carry the new update time")
+}
+
+// TestPostRepository_UpdatePost_Same_Title tests updating the body of a post without touching the other posts of its series
+func TestPostRepository_UpdatePost_Same_Title(t *testing.T) {
+	t.Parallel()
+	c := createPostRepositoryContext(t)
+
+	selectQuery := regexp.QuoteMeta("SELECT * FROM `posts` WHERE `posts`.`url_handle` = ? LIMIT 1")
+	updateQuery := regexp.QuoteMeta("UPDATE `posts` SET")
+
+	c.mockDb.ExpectQuery(selectQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url_handle", "title", "visibility"}).AddRow(0, "testHandle", "title", types.VisibilityPublic))
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(updateQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectCommit()
+
+	_, err := c.sut.UpdatePost(&types.Post{URLHandle: "testHandle", Title: "title", Body: "newBody", Visibility: types.VisibilityPublic}, nil)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expected query should be executed")
+}
+
+// TestPostRepository_UpdatePost_Creation_Time tests changing the creation time of a post, e.g. by the date of an imported file
+func TestPostRepository_UpdatePost_Creation_Time(t *testing.T) {
+	t.Parallel()
+	c := createPostRepositoryContext(t)
+
+	created := time.Date(2022, 1, 2, 10, 0, 0, 0, time.UTC)
+	selectQuery := regexp.QuoteMeta("SELECT * FROM `posts` WHERE `posts`.`url_handle` = ? LIMIT 1")
+	updateQuery := "UPDATE `posts` SET .*`created_at`=\\?.* WHERE id = \\?"
+
+	c.mockDb.ExpectQuery(selectQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url_handle", "title", "visibility"}).AddRow(0, "testHandle", "title", types.VisibilityPublic))
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(updateQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectCommit()
+
+	post, err := c.sut.UpdatePost(&types.Post{URLHandle: "testHandle", Title: "title", CreationTime: created, Visibility: types.VisibilityPublic}, nil)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, created, post.CreatedAt, "creation time should be updated")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expected query should be executed")
+}
+
+// TestPostRepository_UpdatePost_Not_Found tests updating a non-existent post
+func TestPostRepository_UpdatePost_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createPostRepositoryContext(t)
+
+	selectQuery := regexp.QuoteMeta("SELECT * FROM `posts` WHERE `posts`.`url_handle` = ? LIMIT 1")
+	expectedError := errortypes.PostNotFoundError{Post: types.Post{URLHandle: "testHandle"}}
+
+	c.mockDb.ExpectQuery(selectQuery).WillReturnError(fmt.Errorf("record not found"))
+
+	post, err := c.sut.UpdatePost(&types.Post{URLHandle: "testHandle"}, nil)
+
+	assert.Nil(t, post, "should not return a post")
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+}
+
+// TestPostRepository_UpdatePost_Unexpected_Error tests updating a post with an error
+func TestPostRepository_UpdatePost_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createPostRepositoryContext(t)
+
+	selectQuery := regexp.QuoteMeta("SELECT * FROM `posts` WHERE `posts`.`url_handle` = ? LIMIT 1")
+	updateQuery := regexp.QuoteMeta("UPDATE `posts` SET `title`=?,`summary`=?,`body`=?,`excerpt`=?,`word_count`=?,`reading_time`=?,`visibility`=?,`password_hash`=?,`custom_fields`=?,`tags`=?,`cover_image`=?,`meta_description`=?,`canonical_url`=?,`no_index`=?,`updated_at`=? WHERE id = ?")
+	expectedError := fmt.Errorf("unexpected error")
+
+	c.mockDb.ExpectQuery(selectQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url_handle"}).AddRow(0, "testHandle"))
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(updateQuery).WillReturnError(expectedError)
+	c.mockDb.ExpectRollback()
+
+	post, err := c.sut.UpdatePost(&types.Post{URLHandle: "testHandle"}, nil)
+
+	assert.Nil(t, post, "should not return a post")
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+}
+
+// TestPostRepository_SetTranslation tests creating or replacing the translation of a post
+func TestPostRepository_SetTranslation(t *testing.T) {
+	t.Parallel()
+	c := createPostRepositoryContext(t)
+
+	translation := repository.PostTranslation{PostID: 1, Language: "de", Title: "Titel", Summary: "Zusammenfassung", Body: "Text"}
+
+	touchQuery := regexp.QuoteMeta("UPDATE `posts` SET `updated_at`=? WHERE id = ?")
+	deleteQuery := regexp.QuoteMeta("DELETE FROM `post_translations` WHERE post_id = ? AND language = ?")
+	insertQuery := regexp.QuoteMeta("INSERT INTO `post_translations` (`post_id`,`language`,`title`,`summary`,`body`,`excerpt`,`word_count`,`reading_time`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?)")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(deleteQuery).WithArgs(1, "de").WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectExec(insertQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectExec(touchQuery).WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectCommit()
+
+	err := c.sut.SetTranslation(&translation, nil)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expected query should be executed")
+}
+
+// TestPostRepository_SetTranslation_Unexpected_Error tests creating the translation of a post with an error
+func TestPostRepository_SetTranslation_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createPostRepositoryContext(t)
+
+	insertQuery := regexp.QuoteMeta("INSERT INTO `post_translations`")
+	expectedError := fmt.Errorf("unexpected error")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(regexp.QuoteMeta("DELETE FROM `post_translations`")).WillReturnResult(sqlmock.NewResult(0, 0))
+	c.mockDb.ExpectExec(insertQuery).WillReturnError(expectedError)
+	c.mockDb.ExpectRollback()
+
+	err := c.sut.SetTranslation(&repository.PostTranslation{PostID: 1, Language: "de"}, nil)
+
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+}
+
+// TestPostRepository_DeleteTranslation tests removing the translation of a post
+func TestPostRepository_DeleteTranslation(t *testing.T) {
+	t.Parallel()
+	c := createPostRepositoryContext(t)
+
+	touchQuery := regexp.QuoteMeta("UPDATE `posts` SET `updated_at`=? WHERE id = ?")
+	deleteQuery := regexp.QuoteMeta("DELETE FROM `post_translations` WHERE post_id = ? AND language = ?")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(deleteQuery).WithArgs(1, "de").WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectExec(touchQuery).WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectCommit()
+
+	err := c.sut.DeleteTranslation(1, "de", nil)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expected query should be executed")
+}
+
+// TestPostRepository_DeleteTranslation_Unexpected_Error tests removing the translation of a post with an error
+func TestPostRepository_DeleteTranslation_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createPostRepositoryContext(t)
+
+	expectedError := fmt.Errorf("unexpected error")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(regexp.QuoteMeta("DELETE FROM `post_translations`")).WillReturnError(expectedError)
+	c.mockDb.ExpectRollback()
+
+	err := c.sut.DeleteTranslation(1, "de", nil)
+
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+}
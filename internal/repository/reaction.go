@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+// Reaction DB schema. Every reader can react to a post once with each emoji.
+// Readers are identified by a fingerprint, which is derived from the address of anonymous readers and never stores it.
+// The emojis are compared byte by byte, since the default collations consider many of them equal.
+type Reaction struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	PostID      uint   `gorm:"not null;uniqueIndex:idx_reaction,priority:1"`
+	Emoji       string `gorm:"type:varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin;not null;uniqueIndex:idx_reaction,priority:2"`
+	Fingerprint string `gorm:"not null;size:64;uniqueIndex:idx_reaction,priority:3"`
+	CreatedAt   time.Time
+}
+
+// ReactionRepository interface defining the database operations of the reactions to the posts.
+type ReactionRepository interface {
+	AddReaction(reaction *Reaction) error
+	CountReactions(postIDs []uint) (map[uint]map[string]int, error)
+	GetReactedEmojis(postID uint, fingerprint string) ([]string, error)
+	RemoveReaction(postID uint, emoji string, fingerprint string) error
+}
+
+// reactionRepository is the concrete implementation of the ReactionRepository interface.
+type reactionRepository struct {
+	logger     *zap.SugaredLogger
+	repository Repository
+}
+
+// CreateReactionRepository instantiates the reactionRepository
+func CreateReactionRepository(logger *zap.SugaredLogger, repository Repository) ReactionRepository {
+	initReactionModel(logger, repository)
+
+	return &reactionRepository{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// initReactionModel initializes the Reaction schema in the database
+func initReactionModel(logger *zap.SugaredLogger, repository Repository) {
+	if err := repository.AutoMigrate(&Reaction{}); err != nil {
+		logger.Errorf("failed to initialize reaction model: %v", err)
+	}
+}
+
+// AddReaction stores the reaction of a reader. Reacting to a post again with the same emoji has no effect.
+func (r reactionRepository) AddReaction(reaction *Reaction) error {
+	log := r.logger
+	repo := r.repository
+
+	if result := repo.Create(reaction); result.Error != nil {
+		if strings.Contains(result.Error.Error(), "1062") {
+			log.Debugf("reader already reacted to post %d with %s", reaction.PostID, reaction.Emoji)
+			return nil
+		}
+		log.Debugf("failed to add reaction to post %d, error: %v", reaction.PostID, result.Error)
+		return result.Error
+	}
+
+	log.Debugf("added reaction %s to post %d", reaction.Emoji, reaction.PostID)
+	return nil
+}
+
+// CountReactions counts the reactions to the given posts by emoji. Posts without reactions are omitted.
+func (r reactionRepository) CountReactions(postIDs []uint) (map[uint]map[string]int, error) {
+	log := r.logger
+	repo := r.repository
+
+	counts := map[uint]map[string]int{}
+	if len(postIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		PostID uint
+		Emoji  string
+		Count  int
+	}
+	result := repo.Where("post_id IN ?", postIDs).
+		Model(&Reaction{}).
+		Select("post_id, emoji, COUNT(*) AS count").
+		Group("post_id, emoji").
+		Scan(&rows)
+	if result.Error != nil {
+		log.Debugf("error counting the reactions to %d posts: %v", len(postIDs), result.Error)
+		return counts, result.Error
+	}
+
+	for _, row := range rows {
+		if counts[row.PostID] == nil {
+			counts[row.PostID] = map[string]int{}
+		}
+		counts[row.PostID][row.Emoji] = row.Count
+	}
+
+	return counts, nil
+}
+
+// GetReactedEmojis retrieves the emojis a reader reacted to a post with.
+func (r reactionRepository) GetReactedEmojis(postID uint, fingerprint string) ([]string, error) {
+	log := r.logger
+	repo := r.repository
+
+	var emojis []string
+	if result := repo.Where("post_id = ? AND fingerprint = ?", postID, fingerprint).Model(&Reaction{}).Order("id").Pluck("emoji", &emojis); result.Error != nil {
+		log.Debugf("error fetching the reactions of a reader to post %d: %v", postID, result.Error)
+		return []string{}, result.Error
+	}
+
+	return emojis, nil
+}
+
+// RemoveReaction removes the reaction of a reader. Removing a missing reaction has no effect.
+func (r reactionRepository) RemoveReaction(postID uint, emoji string, fingerprint string) error {
+	log := r.logger
+	repo := r.repository
+
+	result := repo.Where("post_id = ? AND emoji = ? AND fingerprint = ?", postID, emoji, fingerprint).Delete(&Reaction{})
+	if result.Error != nil {
+		log.Debugf("failed to remove reaction %s from post %d, error: %v", emoji, postID, result.Error)
+		return result.Error
+	}
+
+	log.Debugf("removed %d reactions %s from post %d", result.RowsAffected, emoji, postID)
+	return nil
+}
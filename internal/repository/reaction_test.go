@@ -0,0 +1,94 @@
+package repository_test
+
+import (
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/repository"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+)
+
+// reactionTestContext contains objects relevant for testing the ReactionRepository.
+type reactionTestContext struct {
+	mockDb sqlmock.Sqlmock
+	sut    repository.ReactionRepository
+}
+
+// createReactionRepositoryContext creates the context for testing the ReactionRepository and reduces code duplication.
+func createReactionRepositoryContext(t *testing.T) *reactionTestContext {
+	t.Helper()
+
+	db, mock, _ := sqlmock.New()
+	gormDb, _ := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}))
+
+	sut := repository.CreateReactionRepository(logger.CreateLogger(), repository.CreateRepository(gormDb))
+	return &reactionTestContext{mock, sut}
+}
+
+// TestReactionRepository_AddReaction_Duplicate tests reacting to a post again with the same emoji
+func TestReactionRepository_AddReaction_Duplicate(t *testing.T) {
+	t.Parallel()
+	c := createReactionRepositoryContext(t)
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(regexp.QuoteMeta("INSERT INTO `reactions`")).
+		WillReturnError(fmt.Errorf("Error 1062 (23000): Duplicate entry"))
+	c.mockDb.ExpectRollback()
+
+	err := c.sut.AddReaction(&repository.Reaction{PostID: 1, Emoji: "👍", Fingerprint: "fingerprint"})
+
+	assert.Nil(t, err, "duplicate reactions should be ignored")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expected query should be executed")
+}
+
+// TestReactionRepository_CountReactions tests counting the reactions to posts by emoji
+func TestReactionRepository_CountReactions(t *testing.T) {
+	t.Parallel()
+	c := createReactionRepositoryContext(t)
+
+	query := regexp.QuoteMeta("SELECT post_id, emoji, COUNT(*) AS count FROM `reactions` WHERE post_id IN (?,?) GROUP BY post_id, emoji")
+	rows := sqlmock.NewRows([]string{"post_id", "emoji", "count"}).AddRow(1, "👍", 3).AddRow(1, "🎉", 1).AddRow(2, "👍", 2)
+
+	c.mockDb.ExpectQuery(query).WithArgs(1, 2).WillReturnRows(rows)
+
+	counts, err := c.sut.CountReactions([]uint{1, 2})
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, map[uint]map[string]int{1: {"👍": 3, "🎉": 1}, 2: {"👍": 2}}, counts, "incorrect counts")
+}
+
+// TestReactionRepository_CountReactions_No_Posts tests counting the reactions without posts
+func TestReactionRepository_CountReactions_No_Posts(t *testing.T) {
+	t.Parallel()
+	c := createReactionRepositoryContext(t)
+
+	counts, err := c.sut.CountReactions([]uint{})
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Empty(t, counts, "no reactions should be counted")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "no query should be executed")
+}
+
+// TestReactionRepository_RemoveReaction tests removing the reaction of a reader
+func TestReactionRepository_RemoveReaction(t *testing.T) {
+	t.Parallel()
+	c := createReactionRepositoryContext(t)
+
+	query := regexp.QuoteMeta("DELETE FROM `reactions` WHERE post_id = ? AND emoji = ? AND fingerprint = ?")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(query).WithArgs(1, "👍", "fingerprint").WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectCommit()
+
+	err := c.sut.RemoveReaction(1, "👍", "fingerprint")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expected query should be executed")
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// RelatedPost DB schema. Stores the most similar posts of every post, ordered by their position.
+// The related posts are precomputed in the background and replaced at once.
+type RelatedPost struct {
+	PostID    uint    `gorm:"primaryKey;autoIncrement:false"`
+	RelatedID uint    `gorm:"primaryKey;autoIncrement:false"`
+	Related   Post    `gorm:"foreignKey:RelatedID"`
+	Position  int     `gorm:"not null"`
+	Score     float64 `gorm:"not null"`
+}
+
+// RelatedRepository interface defining the database operations of the related posts.
+type RelatedRepository interface {
+	GetRelatedPosts(urlHandle string) ([]RelatedPost, error)
+	SetRelatedPosts(related []RelatedPost) error
+}
+
+// relatedRepository is the concrete implementation of the RelatedRepository interface.
+type relatedRepository struct {
+	logger     *zap.SugaredLogger
+	repository Repository
+}
+
+// CreateRelatedRepository instantiates the relatedRepository
+func CreateRelatedRepository(logger *zap.SugaredLogger, repository Repository) RelatedRepository {
+	initRelatedModel(logger, repository)
+
+	return &relatedRepository{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// initRelatedModel initializes the RelatedPost schema in the database
+func initRelatedModel(logger *zap.SugaredLogger, repository Repository) {
+	if err := repository.AutoMigrate(&RelatedPost{}); err != nil {
+		logger.Errorf("failed to initialize related post model: %v", err)
+	}
+}
+
+// GetRelatedPosts retrieves the related posts of the post with the given URL-handle, ordered by their position.
+func (r relatedRepository) GetRelatedPosts(urlHandle string) ([]RelatedPost, error) {
+	log := r.logger
+	repo := r.repository
+
+	var related []RelatedPost
+	postID := repo.Select("id").Where("url_handle = ?", urlHandle).Table("posts")
+	if result := repo.Where("post_id = (?)", postID).Preload("Related").Order("position").Find(&related); result.Error != nil {
+		log.Debugf("error fetching the related posts of %s: %v", urlHandle, result.Error)
+		return []RelatedPost{}, result.Error
+	}
+
+	return related, nil
+}
+
+// SetRelatedPosts replaces the related posts of every post in a single transaction.
+func (r relatedRepository) SetRelatedPosts(related []RelatedPost) error {
+	log := r.logger
+	repo := r.repository
+
+	err := repo.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&RelatedPost{}).Error; err != nil {
+			return err
+		}
+		if len(related) == 0 {
+			return nil
+		}
+		return tx.Omit("Related").CreateInBatches(related, 500).Error
+	})
+
+	if err != nil {
+		log.Debugf("failed to store %d related posts, error: %v", len(related), err)
+		return err
+	}
+
+	log.Debugf("stored %d related posts", len(related))
+	return nil
+}
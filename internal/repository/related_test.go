@@ -0,0 +1,72 @@
+package repository_test
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/repository"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+)
+
+// relatedTestContext contains objects relevant for testing the RelatedRepository.
+type relatedTestContext struct {
+	mockDb sqlmock.Sqlmock
+	sut    repository.RelatedRepository
+}
+
+// createRelatedRepositoryContext creates the context for testing the RelatedRepository and reduces code duplication.
+func createRelatedRepositoryContext(t *testing.T) *relatedTestContext {
+	t.Helper()
+
+	db, mock, _ := sqlmock.New()
+	gormDb, _ := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}))
+
+	sut := repository.CreateRelatedRepository(logger.CreateLogger(), repository.CreateRepository(gormDb))
+	return &relatedTestContext{mock, sut}
+}
+
+// TestRelatedRepository_GetRelatedPosts tests retrieving the related posts of a post identified by its URL-handle
+func TestRelatedRepository_GetRelatedPosts(t *testing.T) {
+	t.Parallel()
+	c := createRelatedRepositoryContext(t)
+
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `related_posts` WHERE post_id = (SELECT id FROM `posts` WHERE url_handle = ?) ORDER BY position")).
+		WithArgs("hello").
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "related_id", "position", "score"}).AddRow(1, 3, 0, 0.8).AddRow(1, 2, 1, 0.4))
+	c.mockDb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `posts` WHERE `posts`.`id` IN (?,?)")).
+		WithArgs(3, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url_handle"}).AddRow(2, "second").AddRow(3, "third"))
+
+	related, err := c.sut.GetRelatedPosts("hello")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Len(t, related, 2, "every related post should be returned")
+	assert.Equal(t, "third", related[0].Related.URLHandle, "related posts should be ordered by their position")
+	assert.Equal(t, "second", related[1].Related.URLHandle, "related posts should be ordered by their position")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expected query should be executed")
+}
+
+// TestRelatedRepository_SetRelatedPosts tests replacing the related posts of every post
+func TestRelatedRepository_SetRelatedPosts(t *testing.T) {
+	t.Parallel()
+	c := createRelatedRepositoryContext(t)
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(regexp.QuoteMeta("DELETE FROM `related_posts` WHERE 1 = 1")).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	c.mockDb.ExpectExec(regexp.QuoteMeta("INSERT INTO `related_posts` (`post_id`,`related_id`,`position`,`score`) VALUES (?,?,?,?),(?,?,?,?)")).
+		WithArgs(1, 2, 0, 0.5, 2, 1, 0, 0.5).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	c.mockDb.ExpectCommit()
+
+	err := c.sut.SetRelatedPosts([]repository.RelatedPost{{PostID: 1, RelatedID: 2, Score: 0.5}, {PostID: 2, RelatedID: 1, Score: 0.5}})
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expected query should be executed")
+}
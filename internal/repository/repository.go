@@ -2,17 +2,22 @@ package repository
 
 import (
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
 )
 
 // Repository defines the database access layer
 type Repository interface {
 	Select(query interface{}, args ...interface{}) *gorm.DB
+	Omit(columns ...string) *gorm.DB
 	Find(out interface{}, where ...interface{}) *gorm.DB
 	Create(value interface{}) *gorm.DB
 	Updates(value interface{}) *gorm.DB
 	Delete(value interface{}) *gorm.DB
 	Where(query interface{}, args ...interface{}) *gorm.DB
+	Order(value interface{}) *gorm.DB
 	Preload(column string, conditions ...interface{}) *gorm.DB
+	Transaction(fc func(tx *gorm.DB) error) error
 	Close() error
 	AutoMigrate(value interface{}) error
 }
@@ -33,6 +38,11 @@ func (rep *repository) Select(query interface{}, args ...interface{}) *gorm.DB {
 	return rep.db.Select(query, args...)
 }
 
+// Omit specify fields to be ignored when creating or updating rows
+func (rep *repository) Omit(columns ...string) *gorm.DB {
+	return rep.db.Omit(columns...)
+}
+
 // Find retrieves rows satisfying the given conditions
 func (rep *repository) Find(out interface{}, where ...interface{}) *gorm.DB {
 	return rep.db.Find(out, where...)
@@ -58,11 +68,22 @@ func (rep *repository) Where(query interface{}, args ...interface{}) *gorm.DB {
 	return rep.db.Where(query, args...)
 }
 
+// Order specifies the order of the retrieved rows
+func (rep *repository) Order(value interface{}) *gorm.DB {
+	return rep.db.Order(value)
+}
+
 // Preload loads a foreign table to run queries based on joined tables
 func (rep *repository) Preload(column string, conditions ...interface{}) *gorm.DB {
 	return rep.db.Preload(column, conditions...)
 }
 
+// Transaction executes the provided function in a database transaction.
+// If the function returns an error, the transaction is rolled back, otherwise it is committed.
+func (rep *repository) Transaction(fc func(tx *gorm.DB) error) error {
+	return rep.db.Transaction(fc)
+}
+
 // Close closes the database connection
 func (rep *repository) Close() error {
 	sqlDB, _ := rep.db.DB()
@@ -73,3 +94,25 @@ func (rep *repository) Close() error {
 func (rep *repository) AutoMigrate(value interface{}) error {
 	return rep.db.AutoMigrate(value)
 }
+
+// claimRows selects the rows of a queue matching the condition, together with the given associations, and claims them
+// by setting the column to the end of the claim, so other instances sharing the database skip them until it expires.
+// The selected rows are locked until they're claimed, rows locked by a concurrent claim are skipped.
+func claimRows[T any](repo Repository, condition clause.Expr, order string, limit int, column string, until time.Time, preload ...string) ([]T, error) {
+	var rows []T
+	err := repo.Transaction(func(tx *gorm.DB) error {
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).Where(condition)
+		for _, association := range preload {
+			query = query.Preload(association)
+		}
+		if err := query.Order(order).Limit(limit).Find(&rows).Error; err != nil || len(rows) == 0 {
+			return err
+		}
+		return tx.Model(&rows).Omit(clause.Associations).UpdateColumn(column, until).Error
+	})
+
+	if err != nil {
+		return []T{}, err
+	}
+	return rows, nil
+}
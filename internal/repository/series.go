@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/types"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// Series DB schema
+type Series struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	URLHandle string `gorm:"unique;not null"`
+	Title     string
+	Summary   string
+	OwnerID   uint `gorm:"not null"`
+	Owner     User
+	Posts     []SeriesPost `gorm:"foreignKey:SeriesID"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// SeriesPost DB schema. Stores the ordered membership of posts in a series.
+// A post can only be part of a single series.
+type SeriesPost struct {
+	SeriesID uint `gorm:"primaryKey;autoIncrement:false"`
+	PostID   uint `gorm:"primaryKey;autoIncrement:false;uniqueIndex"`
+	Post     Post
+	Position uint `gorm:"not null"`
+}
+
+// SeriesRepository interface defining series-related database operations.
+type SeriesRepository interface {
+	AddSeries(series *types.Series, ownerID uint) (*Series, error)
+	DeleteSeries(urlHandle string) error
+	GetPostSeries(postID uint) (*Series, error)
+	GetSeries(urlHandle string) (*Series, error)
+	GetSeriesList() ([]Series, error)
+	SetSeriesPosts(seriesID uint, postIDs []uint) error
+	UpdateSeries(series *types.Series) (*Series, error)
+}
+
+// seriesRepository is the concrete implementation of the SeriesRepository interface.
+type seriesRepository struct {
+	logger     *zap.SugaredLogger
+	repository Repository
+}
+
+// CreateSeriesRepository instantiates the seriesRepository
+func CreateSeriesRepository(logger *zap.SugaredLogger, repository Repository) SeriesRepository {
+	initSeriesModel(logger, repository)
+
+	return &seriesRepository{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// initSeriesModel initializes the Series and SeriesPost schemas in the database
+func initSeriesModel(logger *zap.SugaredLogger, repository Repository) {
+	if err := repository.AutoMigrate(&Series{}); err != nil {
+		logger.Errorf("failed to initialize series model: %v", err)
+	}
+	if err := repository.AutoMigrate(&SeriesPost{}); err != nil {
+		logger.Errorf("failed to initialize series post model: %v", err)
+	}
+}
+
+// publicSeriesPosts restricts preloaded series members to public posts and orders them by their position.
+// The series are listed publicly, so private, unlisted and password-protected posts must not be revealed.
+func publicSeriesPosts(db *gorm.DB) *gorm.DB {
+	return db.Where("post_id IN (SELECT id FROM posts WHERE visibility = ?)", types.VisibilityPublic).Order("position")
+}
+
+// seriesMembers builds the subquery selecting the IDs of the posts of a series.
+func seriesMembers(tx *gorm.DB, seriesID uint) *gorm.DB {
+	return tx.Model(&SeriesPost{}).Select("post_id").Where("series_id = ?", seriesID)
+}
+
+// seriesNeighbours builds the subquery selecting the IDs of the other posts of the series of a post.
+func seriesNeighbours(tx *gorm.DB, postID uint) *gorm.DB {
+	series := tx.Model(&SeriesPost{}).Select("series_id").Where("post_id = ?", postID)
+	return tx.Model(&SeriesPost{}).Select("post_id").Where("series_id IN (?) AND post_id <> ?", series, postID)
+}
+
+// AddSeries adds a new series with the provided fields and owner to the database.
+func (s seriesRepository) AddSeries(series *types.Series, ownerID uint) (*Series, error) {
+	log := s.logger
+	repo := s.repository
+
+	newSeries := Series{
+		URLHandle: series.URLHandle,
+		Title:     series.Title,
+		Summary:   series.Summary,
+		OwnerID:   ownerID,
+	}
+
+	if result := repo.Omit("Owner").Create(&newSeries); result.Error == nil {
+		log.Debugf("created series: %v", newSeries)
+		return &newSeries, nil
+	} else if strings.Contains(result.Error.Error(), "1062") {
+		log.Debugf("failed to create series, duplicate key: %s, error: %v", series.URLHandle, result.Error)
+		return nil, errortypes.DuplicateElementError{Key: series.URLHandle}
+	} else {
+		log.Debugf("failed to create series: %v, error: %s", series, result.Error)
+		return nil, result.Error
+	}
+}
+
+// DeleteSeries removes the series with the given URL handle and its memberships from the database.
+// The posts themselves are kept, only their update time is bumped.
+func (s seriesRepository) DeleteSeries(urlHandle string) error {
+	log := s.logger
+	repo := s.repository
+
+	series, err := s.GetSeries(urlHandle)
+	if err != nil {
+		return err
+	}
+
+	err = repo.Transaction(func(tx *gorm.DB) error {
+		if err := touchPosts(tx, "id IN (?)", seriesMembers(tx, series.ID)); err != nil {
+			return err
+		}
+		if err := tx.Where("series_id = ?", series.ID).Delete(&SeriesPost{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Series{}, series.ID).Error
+	})
+
+	if err != nil {
+		log.Debugf("failed to delete series with handle: %s, error: %v", urlHandle, err)
+		return err
+	}
+
+	log.Debugf("deleted series: %s", urlHandle)
+	return nil
+}
+
+// GetPostSeries retrieves the series the post with the given ID belongs to, together with the post and the other public
+// members of the series. If the post isn't part of any series, nil is returned without an error.
+func (s seriesRepository) GetPostSeries(postID uint) (*Series, error) {
+	log := s.logger
+	repo := s.repository
+
+	var memberships []SeriesPost
+	if result := repo.Where("post_id = ?", postID).Limit(1).Find(&memberships); result.Error != nil {
+		log.Debugf("failed to retrieve series membership of post: %d, error: %v", postID, result.Error)
+		return nil, result.Error
+	}
+
+	if len(memberships) == 0 {
+		return nil, nil
+	}
+
+	// The post itself is kept even if it isn't public, so that its authors see its position within the series
+	seriesPosts := func(db *gorm.DB) *gorm.DB {
+		return db.Where("post_id = ? OR post_id IN (SELECT id FROM posts WHERE visibility = ?)", postID, types.VisibilityPublic).Order("position")
+	}
+
+	var series Series
+	result := repo.Preload("Posts", seriesPosts).Preload("Posts.Post").Take(&series, memberships[0].SeriesID)
+	if result.Error != nil {
+		log.Debugf("failed to retrieve series of post: %d, error: %v", postID, result.Error)
+		return nil, result.Error
+	}
+
+	log.Debugf("retrieved series of post %d: %v", postID, series)
+	return &series, nil
+}
+
+// GetSeries retrieves the series with the given URL-handle and its public posts from the database.
+func (s seriesRepository) GetSeries(urlHandle string) (*Series, error) {
+	log := s.logger
+	repo := s.repository
+
+	series := Series{
+		URLHandle: urlHandle,
+	}
+
+	result := repo.Preload("Owner").Preload("Posts", publicSeriesPosts).Preload("Posts.Post.Author").Where(&series).Take(&series)
+
+	if result.Error != nil {
+		log.Debugf("failed to retrieve series with handle: %s, error: %v", urlHandle, result.Error)
+		if result.Error.Error() == "record not found" {
+			return nil, errortypes.SeriesNotFoundError{Series: types.Series{URLHandle: urlHandle}}
+		}
+		return nil, result.Error
+	}
+
+	log.Debugf("retrieved series: %v", series)
+	return &series, nil
+}
+
+// GetSeriesList retrieves every series and their public posts from the database.
+func (s seriesRepository) GetSeriesList() ([]Series, error) {
+	log := s.logger
+	repo := s.repository
+
+	var series []Series
+	if result := repo.Preload("Owner").Preload("Posts", publicSeriesPosts).Preload("Posts.Post.Author").Order("created_at DESC").Find(&series); result.Error != nil {
+		log.Debugf("error fetching series: %v", result.Error)
+		return []Series{}, result.Error
+	}
+
+	log.Debugf("fetched series: %v", series)
+	return series, nil
+}
+
+// SetSeriesPosts replaces the members of the series with the given posts.
+// The order of the post IDs determines the position of the posts within the series. The update time of the previous and
+// the new members is bumped, since the navigation of the series changes on their pages.
+func (s seriesRepository) SetSeriesPosts(seriesID uint, postIDs []uint) error {
+	log := s.logger
+	repo := s.repository
+
+	memberships := make([]SeriesPost, 0, len(postIDs))
+	for i, postID := range postIDs {
+		memberships = append(memberships, SeriesPost{SeriesID: seriesID, PostID: postID, Position: uint(i + 1)})
+	}
+
+	err := repo.Transaction(func(tx *gorm.DB) error {
+		// Both the removed and the added posts link to the other parts of the series
+		if err := touchPosts(tx, "id IN (?) OR id IN ?", seriesMembers(tx, seriesID), postIDs); err != nil {
+			return err
+		}
+		if err := tx.Where("series_id = ?", seriesID).Delete(&SeriesPost{}).Error; err != nil {
+			return err
+		}
+		if len(memberships) == 0 {
+			return nil
+		}
+		return tx.Create(&memberships).Error
+	})
+
+	if err == nil {
+		log.Debugf("updated posts of series %d: %v", seriesID, postIDs)
+		return nil
+	} else if strings.Contains(err.Error(), "1062") {
+		log.Debugf("failed to update posts of series %d, post already part of a series, error: %v", seriesID, err)
+		return errortypes.PostAlreadyInSeriesError{}
+	} else {
+		log.Debugf("failed to update posts of series %d: %v", seriesID, err)
+		return err
+	}
+}
+
+// UpdateSeries updates the title and summary of an existing series and bumps the update time of its posts.
+func (s seriesRepository) UpdateSeries(series *types.Series) (*Series, error) {
+	log := s.logger
+	repo := s.repository
+
+	existingSeries, err := s.GetSeries(series.URLHandle)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := Series{Title: series.Title, Summary: series.Summary}
+
+	err = repo.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("title", "summary").Where("id = ?", existingSeries.ID).Updates(&fields).Error; err != nil {
+			return err
+		}
+		// The title of the series is part of the navigation on the pages of its posts
+		return touchPosts(tx, "id IN (?)", seriesMembers(tx, existingSeries.ID))
+	})
+
+	if err != nil {
+		log.Debugf("failed to update series %s, error: %v", existingSeries.URLHandle, err)
+		return nil, err
+	}
+
+	existingSeries.Title = series.Title
+	existingSeries.Summary = series.Summary
+
+	log.Debugf("updated series: %v", existingSeries)
+	return existingSeries, nil
+}
@@ -0,0 +1,383 @@
+package repository_test
+
+import (
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/types"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+)
+
+// seriesTestContext contains objects relevant for testing the SeriesRepository.
+type seriesTestContext struct {
+	mockDb sqlmock.Sqlmock
+	sut    repository.SeriesRepository
+}
+
+// createSeriesRepositoryContext creates the context for testing the SeriesRepository and reduces code duplication.
+func createSeriesRepositoryContext(t *testing.T) *seriesTestContext {
+	t.Helper()
+
+	db, mock, _ := sqlmock.New()
+	gormDb, _ := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}))
+
+	sut := repository.CreateSeriesRepository(logger.CreateLogger(), repository.CreateRepository(gormDb))
+	return &seriesTestContext{mock, sut}
+}
+
+// TestSeriesRepository_AddSeries tests adding a new series to the system
+func TestSeriesRepository_AddSeries(t *testing.T) {
+	t.Parallel()
+	c := createSeriesRepositoryContext(t)
+
+	input := &types.Series{URLHandle: "testHandle", Title: "testTitle"}
+	query := regexp.QuoteMeta("INSERT INTO `series` (`url_handle`,`title`,`summary`,`owner_id`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?)")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(query).WillReturnResult(sqlmock.NewResult(1, 1))
+	c.mockDb.ExpectCommit()
+
+	series, err := c.sut.AddSeries(input, 1)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, input.URLHandle, series.URLHandle, "received series should match the expected one")
+	assert.Equal(t, input.Title, series.Title, "received series should match the expected one")
+}
+
+// TestSeriesRepository_AddSeries_Duplicate tests adding a new series with an already existing URL handle
+func TestSeriesRepository_AddSeries_Duplicate(t *testing.T) {
+	t.Parallel()
+	c := createSeriesRepositoryContext(t)
+
+	input := &types.Series{URLHandle: "testHandle"}
+	query := regexp.QuoteMeta("INSERT INTO `series` (`url_handle`,`title`,`summary`,`owner_id`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?)")
+	expectedError := errortypes.DuplicateElementError{Key: input.URLHandle}
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(query).WillReturnError(fmt.Errorf("1062"))
+	c.mockDb.ExpectRollback()
+
+	series, err := c.sut.AddSeries(input, 1)
+
+	assert.Nil(t, series, "should not return a series")
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+}
+
+// TestSeriesRepository_AddSeries_Unexpected_Error tests adding a new series while encountering an unexpected error
+func TestSeriesRepository_AddSeries_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createSeriesRepositoryContext(t)
+
+	query := regexp.QuoteMeta("INSERT INTO `series` (`url_handle`,`title`,`summary`,`owner_id`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?)")
+	expectedError := fmt.Errorf("unexpected error")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(query).WillReturnError(expectedError)
+	c.mockDb.ExpectRollback()
+
+	series, err := c.sut.AddSeries(&types.Series{URLHandle: "testHandle"}, 1)
+
+	assert.Nil(t, series, "should not return a series")
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+}
+
+// TestSeriesRepository_GetSeries tests retrieving a single series from the database
+func TestSeriesRepository_GetSeries(t *testing.T) {
+	t.Parallel()
+	c := createSeriesRepositoryContext(t)
+
+	query := regexp.QuoteMeta("SELECT * FROM `series` WHERE `series`.`url_handle` = ? LIMIT 1")
+	ownerQuery := regexp.QuoteMeta("SELECT * FROM `users` WHERE `users`.`id` = ?")
+	membersQuery := regexp.QuoteMeta("SELECT * FROM `series_posts` WHERE post_id IN (SELECT id FROM posts WHERE visibility = ?) AND `series_posts`.`series_id` = ? ORDER BY position")
+
+	c.mockDb.ExpectQuery(query).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url_handle", "owner_id"}).AddRow(1, "testHandle", 2))
+	c.mockDb.ExpectQuery(ownerQuery).WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_name"}).AddRow(2, "testAuthor"))
+	c.mockDb.ExpectQuery(membersQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"series_id", "post_id", "position"}))
+
+	series, err := c.sut.GetSeries("testHandle")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "testHandle", series.URLHandle, "received series should match the expected one")
+	assert.Equal(t, "testAuthor", series.Owner.UserName, "owner of the series should be loaded")
+}
+
+// TestSeriesRepository_GetSeries_Record_Not_Found tests retrieving a non-existent series from the database
+func TestSeriesRepository_GetSeries_Record_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createSeriesRepositoryContext(t)
+
+	query := regexp.QuoteMeta("SELECT * FROM `series` WHERE `series`.`url_handle` = ? LIMIT 1")
+	expectedError := errortypes.SeriesNotFoundError{Series: types.Series{URLHandle: "testHandle"}}
+
+	c.mockDb.ExpectQuery(query).WillReturnError(fmt.Errorf("record not found"))
+
+	series, err := c.sut.GetSeries("testHandle")
+
+	assert.Nil(t, series, "should not return a series")
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+}
+
+// TestSeriesRepository_GetSeries_Unexpected_Error tests retrieving a single series from the database with an error
+func TestSeriesRepository_GetSeries_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createSeriesRepositoryContext(t)
+
+	query := regexp.QuoteMeta("SELECT * FROM `series` WHERE `series`.`url_handle` = ? LIMIT 1")
+	expectedError := fmt.Errorf("unexpected error")
+
+	c.mockDb.ExpectQuery(query).WillReturnError(expectedError)
+
+	series, err := c.sut.GetSeries("testHandle")
+
+	assert.Nil(t, series, "should not return a series")
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+}
+
+// TestSeriesRepository_GetSeriesList tests retrieving every series from the database
+func TestSeriesRepository_GetSeriesList(t *testing.T) {
+	t.Parallel()
+	c := createSeriesRepositoryContext(t)
+
+	query := regexp.QuoteMeta("SELECT * FROM `series` ORDER BY created_at DESC")
+
+	c.mockDb.ExpectQuery(query).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url_handle"}))
+
+	series, err := c.sut.GetSeriesList()
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 0, len(series), "didn't receive the expected number of series")
+}
+
+// TestSeriesRepository_GetSeriesList_Unexpected_Error tests retrieving every series from the database with an error
+func TestSeriesRepository_GetSeriesList_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createSeriesRepositoryContext(t)
+
+	query := regexp.QuoteMeta("SELECT * FROM `series` ORDER BY created_at DESC")
+	expectedError := fmt.Errorf("unexpected error")
+
+	c.mockDb.ExpectQuery(query).WillReturnError(expectedError)
+
+	series, err := c.sut.GetSeriesList()
+
+	assert.Equal(t, expectedError, err, "error should match expected value")
+	assert.Equal(t, 0, len(series), "shouldn't receive any series")
+}
+
+// TestSeriesRepository_GetPostSeries_No_Series tests retrieving the series of a post which isn't part of any series
+func TestSeriesRepository_GetPostSeries_No_Series(t *testing.T) {
+	t.Parallel()
+	c := createSeriesRepositoryContext(t)
+
+	query := regexp.QuoteMeta("SELECT * FROM `series_posts` WHERE post_id = ? LIMIT 1")
+
+	c.mockDb.ExpectQuery(query).WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"series_id", "post_id", "position"}))
+
+	series, err := c.sut.GetPostSeries(1)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Nil(t, series, "should not return a series")
+}
+
+// TestSeriesRepository_GetPostSeries tests retrieving the series of a post
+func TestSeriesRepository_GetPostSeries(t *testing.T) {
+	t.Parallel()
+	c := createSeriesRepositoryContext(t)
+
+	membershipQuery := regexp.QuoteMeta("SELECT * FROM `series_posts` WHERE post_id = ? LIMIT 1")
+	seriesQuery := regexp.QuoteMeta("SELECT * FROM `series` WHERE `series`.`id` = ? LIMIT 1")
+	membersQuery := regexp.QuoteMeta("SELECT * FROM `series_posts` WHERE (post_id = ? OR post_id IN (SELECT id FROM posts WHERE visibility = ?)) AND `series_posts`.`series_id` = ? ORDER BY position")
+
+	c.mockDb.ExpectQuery(membershipQuery).WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"series_id", "post_id", "position"}).AddRow(2, 1, 1))
+	c.mockDb.ExpectQuery(seriesQuery).WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url_handle"}).AddRow(2, "testHandle"))
+	c.mockDb.ExpectQuery(membersQuery).WithArgs(1, types.VisibilityPublic, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"series_id", "post_id", "position"}))
+
+	series, err := c.sut.GetPostSeries(1)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "testHandle", series.URLHandle, "received series should match the expected one")
+}
+
+// TestSeriesRepository_GetPostSeries_Unexpected_Error tests retrieving the series of a post with an error
+func TestSeriesRepository_GetPostSeries_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createSeriesRepositoryContext(t)
+
+	query := regexp.QuoteMeta("SELECT * FROM `series_posts` WHERE post_id = ? LIMIT 1")
+	expectedError := fmt.Errorf("unexpected error")
+
+	c.mockDb.ExpectQuery(query).WillReturnError(expectedError)
+
+	series, err := c.sut.GetPostSeries(1)
+
+	assert.Nil(t, series, "should not return a series")
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+}
+
+// TestSeriesRepository_SetSeriesPosts tests replacing the posts of a series
+func TestSeriesRepository_SetSeriesPosts(t *testing.T) {
+	t.Parallel()
+	c := createSeriesRepositoryContext(t)
+
+	touchQuery := regexp.QuoteMeta("UPDATE `posts` SET `updated_at`=? WHERE id IN (SELECT `post_id` FROM `series_posts` WHERE series_id = ?) OR id IN (?,?)")
+	deleteQuery := regexp.QuoteMeta("DELETE FROM `series_posts` WHERE series_id = ?")
+	insertQuery := regexp.QuoteMeta("INSERT INTO `series_posts` (`series_id`,`post_id`,`position`) VALUES (?,?,?),(?,?,?)")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(touchQuery).WithArgs(sqlmock.AnyArg(), 1, 3, 2).WillReturnResult(sqlmock.NewResult(0, 2))
+	c.mockDb.ExpectExec(deleteQuery).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+	c.mockDb.ExpectExec(insertQuery).WithArgs(1, 3, 1, 1, 2, 2).WillReturnResult(sqlmock.NewResult(0, 2))
+	c.mockDb.ExpectCommit()
+
+	err := c.sut.SetSeriesPosts(1, []uint{3, 2})
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expected query should be executed")
+}
+
+// TestSeriesRepository_SetSeriesPosts_Already_In_Series tests adding a post to a series which is part of another one
+func TestSeriesRepository_SetSeriesPosts_Already_In_Series(t *testing.T) {
+	t.Parallel()
+	c := createSeriesRepositoryContext(t)
+
+	touchQuery := regexp.QuoteMeta("UPDATE `posts` SET `updated_at`=? WHERE id IN (SELECT `post_id` FROM `series_posts` WHERE series_id = ?) OR id IN (?)")
+	deleteQuery := regexp.QuoteMeta("DELETE FROM `series_posts` WHERE series_id = ?")
+	insertQuery := regexp.QuoteMeta("INSERT INTO `series_posts` (`series_id`,`post_id`,`position`) VALUES (?,?,?)")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(touchQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectExec(deleteQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+	c.mockDb.ExpectExec(insertQuery).WillReturnError(fmt.Errorf("1062"))
+	c.mockDb.ExpectRollback()
+
+	err := c.sut.SetSeriesPosts(1, []uint{3})
+
+	assert.Equal(t, errortypes.PostAlreadyInSeriesError{}, err, "received error should match the expected one")
+}
+
+// TestSeriesRepository_SetSeriesPosts_Unexpected_Error tests replacing the posts of a series with an error
+func TestSeriesRepository_SetSeriesPosts_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createSeriesRepositoryContext(t)
+
+	touchQuery := regexp.QuoteMeta("UPDATE `posts` SET `updated_at`=? WHERE id IN (SELECT `post_id` FROM `series_posts` WHERE series_id = ?) OR id IN (?)")
+	expectedError := fmt.Errorf("unexpected error")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(touchQuery).WillReturnError(expectedError)
+	c.mockDb.ExpectRollback()
+
+	err := c.sut.SetSeriesPosts(1, []uint{3})
+
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+}
+
+// TestSeriesRepository_UpdateSeries tests updating the fields of a series
+func TestSeriesRepository_UpdateSeries(t *testing.T) {
+	t.Parallel()
+	c := createSeriesRepositoryContext(t)
+
+	selectQuery := regexp.QuoteMeta("SELECT * FROM `series` WHERE `series`.`url_handle` = ? LIMIT 1")
+	membersQuery := regexp.QuoteMeta("SELECT * FROM `series_posts` WHERE post_id IN (SELECT id FROM posts WHERE visibility = ?) AND `series_posts`.`series_id` = ? ORDER BY position")
+	updateQuery := regexp.QuoteMeta("UPDATE `series` SET `title`=?,`summary`=?,`updated_at`=? WHERE id = ?")
+	touchQuery := regexp.QuoteMeta("UPDATE `posts` SET `updated_at`=? WHERE id IN (SELECT `post_id` FROM `series_posts` WHERE series_id = ?)")
+
+	c.mockDb.ExpectQuery(selectQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url_handle"}).AddRow(1, "testHandle"))
+	c.mockDb.ExpectQuery(membersQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"series_id", "post_id", "position"}))
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(updateQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectExec(touchQuery).WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 0))
+	c.mockDb.ExpectCommit()
+
+	series, err := c.sut.UpdateSeries(&types.Series{URLHandle: "testHandle", Title: "newTitle"})
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "newTitle", series.Title, "title should be updated")
+}
+
+// TestSeriesRepository_UpdateSeries_Not_Found tests updating a non-existent series
+func TestSeriesRepository_UpdateSeries_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createSeriesRepositoryContext(t)
+
+	selectQuery := regexp.QuoteMeta("SELECT * FROM `series` WHERE `series`.`url_handle` = ? LIMIT 1")
+	expectedError := errortypes.SeriesNotFoundError{Series: types.Series{URLHandle: "testHandle"}}
+
+	c.mockDb.ExpectQuery(selectQuery).WillReturnError(fmt.Errorf("record not found"))
+
+	series, err := c.sut.UpdateSeries(&types.Series{URLHandle: "testHandle"})
+
+	assert.Nil(t, series, "should not return a series")
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+}
+
+// TestSeriesRepository_DeleteSeries tests removing a series from the database
+func TestSeriesRepository_DeleteSeries(t *testing.T) {
+	t.Parallel()
+	c := createSeriesRepositoryContext(t)
+
+	selectQuery := regexp.QuoteMeta("SELECT * FROM `series` WHERE `series`.`url_handle` = ? LIMIT 1")
+	membersQuery := regexp.QuoteMeta("SELECT * FROM `series_posts` WHERE post_id IN (SELECT id FROM posts WHERE visibility = ?) AND `series_posts`.`series_id` = ? ORDER BY position")
+	touchQuery := regexp.QuoteMeta("UPDATE `posts` SET `updated_at`=? WHERE id IN (SELECT `post_id` FROM `series_posts` WHERE series_id = ?)")
+	deleteMembersQuery := regexp.QuoteMeta("DELETE FROM `series_posts` WHERE series_id = ?")
+	deleteSeriesQuery := regexp.QuoteMeta("DELETE FROM `series` WHERE `series`.`id` = ?")
+
+	c.mockDb.ExpectQuery(selectQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url_handle"}).AddRow(1, "testHandle"))
+	c.mockDb.ExpectQuery(membersQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"series_id", "post_id", "position"}))
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(touchQuery).WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 0))
+	c.mockDb.ExpectExec(deleteMembersQuery).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+	c.mockDb.ExpectExec(deleteSeriesQuery).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectCommit()
+
+	err := c.sut.DeleteSeries("testHandle")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expected query should be executed")
+}
+
+// TestSeriesRepository_DeleteSeries_Unexpected_Error tests removing a series from the database with an error
+func TestSeriesRepository_DeleteSeries_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createSeriesRepositoryContext(t)
+
+	selectQuery := regexp.QuoteMeta("SELECT * FROM `series` WHERE `series`.`url_handle` = ? LIMIT 1")
+	membersQuery := regexp.QuoteMeta("SELECT * FROM `series_posts` WHERE post_id IN (SELECT id FROM posts WHERE visibility = ?) AND `series_posts`.`series_id` = ? ORDER BY position")
+	touchQuery := regexp.QuoteMeta("UPDATE `posts` SET `updated_at`=? WHERE id IN (SELECT `post_id` FROM `series_posts` WHERE series_id = ?)")
+	deleteMembersQuery := regexp.QuoteMeta("DELETE FROM `series_posts` WHERE series_id = ?")
+	expectedError := fmt.Errorf("unexpected error")
+
+	c.mockDb.ExpectQuery(selectQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url_handle"}).AddRow(1, "testHandle"))
+	c.mockDb.ExpectQuery(membersQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"series_id", "post_id", "position"}))
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectExec(touchQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+	c.mockDb.ExpectExec(deleteMembersQuery).WillReturnError(expectedError)
+	c.mockDb.ExpectRollback()
+
+	err := c.sut.DeleteSeries("testHandle")
+
+	assert.Equal(t, expectedError, err, "received error should match the expected one")
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/wlchs/blog/internal/errortypes"
 	"github.com/wlchs/blog/internal/types"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 	"time"
 )
 
@@ -12,6 +13,7 @@ type User struct {
 	ID           uint   `gorm:"primaryKey;autoIncrement"`
 	UserName     string `gorm:"unique;not null"`
 	PasswordHash string `gorm:"not null"`
+	Disabled     bool   `gorm:"not null;default:false"`
 	Posts        []Post `gorm:"foreignKey:AuthorID"`
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
@@ -19,7 +21,7 @@ type User struct {
 
 // UserRepository interface defining user-related database operations.
 type UserRepository interface {
-	AddUser(user *types.User) (*User, error)
+	AddUser(user *types.User, events UserEvents) (*User, error)
 	GetUser(userName string) (*User, error)
 	GetUsers() ([]User, error)
 	UpdateUser(user *types.User) (*User, error)
@@ -49,18 +51,30 @@ func initUserModel(logger *zap.SugaredLogger, repository Repository) {
 }
 
 // AddUser adds a new user with the provided fields to the database.
-func (u userRepository) AddUser(user *types.User) (*User, error) {
+// The events built from the created user are stored in the outbox together with it.
+func (u userRepository) AddUser(user *types.User, events UserEvents) (*User, error) {
 	log := u.logger
 	repo := u.repository
 
 	newUser := User{
 		UserName:     user.UserName,
 		PasswordHash: user.PasswordHash,
+		Disabled:     user.Disabled,
 	}
 
-	if result := repo.Create(&newUser); result.Error != nil {
-		log.Debugf("failed to create new user: %v, error: %v", newUser, result.Error)
-		return nil, result.Error
+	err := repo.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&newUser).Error; err != nil {
+			return err
+		}
+		if events == nil {
+			return nil
+		}
+		return addOutboxEvents(tx, events(&newUser))
+	})
+
+	if err != nil {
+		log.Debugf("failed to create new user: %v, error: %v", newUser, err)
+		return nil, err
 	}
 
 	log.Debugf("created new user: %v", newUser)
@@ -43,13 +43,13 @@ func TestUserRepository_AddUser(t *testing.T) {
 		UserName: "testUser",
 	}
 
-	userQuery := regexp.QuoteMeta("INSERT INTO `users` (`user_name`,`password_hash`,`created_at`,`updated_at`) VALUES (?,?,?,?)")
+	userQuery := regexp.QuoteMeta("INSERT INTO `users` (`user_name`,`password_hash`,`disabled`,`created_at`,`updated_at`) VALUES (?,?,?,?,?)")
 
 	c.mockDb.ExpectBegin()
 	c.mockDb.ExpectExec(userQuery).WillReturnResult(sqlmock.NewResult(0, 1))
 	c.mockDb.ExpectCommit()
 
-	user, err := c.sut.AddUser(author)
+	user, err := c.sut.AddUser(author, nil)
 
 	assert.Nil(t, err, "should complete without error")
 	assert.Equal(t, author.UserName, user.UserName, "received post should match the expected one")
@@ -66,13 +66,13 @@ func TestUserRepository_AddUser_Unexpected_Error(t *testing.T) {
 
 	expectedError := fmt.Errorf("unexpected error")
 
-	userQuery := regexp.QuoteMeta("INSERT INTO `users` (`user_name`,`password_hash`,`created_at`,`updated_at`) VALUES (?,?,?,?)")
+	userQuery := regexp.QuoteMeta("INSERT INTO `users` (`user_name`,`password_hash`,`disabled`,`created_at`,`updated_at`) VALUES (?,?,?,?,?)")
 
 	c.mockDb.ExpectBegin()
 	c.mockDb.ExpectExec(userQuery).WillReturnError(expectedError)
 	c.mockDb.ExpectRollback()
 
-	user, err := c.sut.AddUser(author)
+	user, err := c.sut.AddUser(author, nil)
 
 	assert.Nil(t, user, "should not return a user")
 	assert.Equal(t, expectedError, err, "received error should match the expected one")
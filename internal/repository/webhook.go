@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/types"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Webhook DB schema. Describes an endpoint notified about the events of the blog.
+type Webhook struct {
+	ID        uint     `gorm:"primaryKey;autoIncrement"`
+	URL       string   `gorm:"not null"`
+	Secret    string   `gorm:"not null"`
+	Events    []string `gorm:"type:json;serializer:json"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// WebhookDelivery DB schema. Stores the queued deliveries of events to webhooks and the outcome of the attempts.
+type WebhookDelivery struct {
+	ID        uint `gorm:"primaryKey;autoIncrement"`
+	WebhookID uint `gorm:"not null;index"`
+	Webhook   Webhook
+	Event     string `gorm:"not null"`
+	Payload   string `gorm:"not null"`
+	DeliveryState
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// WebhookRepository interface defining webhook-related database operations.
+type WebhookRepository interface {
+	AddDeliveries(deliveries []WebhookDelivery) error
+	AddWebhook(webhook *types.Webhook) (*Webhook, error)
+	ClaimDueDeliveries(now time.Time, limit int, lease time.Duration) ([]WebhookDelivery, error)
+	DeleteWebhook(id uint) error
+	GetDeliveries(webhookID uint, limit int) ([]WebhookDelivery, error)
+	GetWebhook(id uint) (*Webhook, error)
+	GetWebhooks() ([]Webhook, error)
+	UpdateDelivery(delivery *WebhookDelivery) error
+}
+
+// webhookRepository is the concrete implementation of the WebhookRepository interface.
+type webhookRepository struct {
+	logger     *zap.SugaredLogger
+	repository Repository
+}
+
+// CreateWebhookRepository instantiates the webhookRepository
+func CreateWebhookRepository(logger *zap.SugaredLogger, repository Repository) WebhookRepository {
+	initWebhookModel(logger, repository)
+
+	return &webhookRepository{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// initWebhookModel initializes the Webhook and WebhookDelivery schemas in the database
+func initWebhookModel(logger *zap.SugaredLogger, repository Repository) {
+	if err := repository.AutoMigrate(&Webhook{}); err != nil {
+		logger.Errorf("failed to initialize webhook model: %v", err)
+	}
+	if err := repository.AutoMigrate(&WebhookDelivery{}); err != nil {
+		logger.Errorf("failed to initialize webhook delivery model: %v", err)
+	}
+}
+
+// AddDeliveries queues the deliveries of an event.
+func (w webhookRepository) AddDeliveries(deliveries []WebhookDelivery) error {
+	log := w.logger
+	repo := w.repository
+
+	if err := addDeliveries(repo, deliveries, "Webhook"); err != nil {
+		log.Debugf("failed to queue webhook deliveries: %v, error: %v", deliveries, err)
+		return err
+	}
+
+	log.Debugf("queued %d webhook deliveries", len(deliveries))
+	return nil
+}
+
+// AddWebhook adds a new webhook to the database.
+func (w webhookRepository) AddWebhook(webhook *types.Webhook) (*Webhook, error) {
+	log := w.logger
+	repo := w.repository
+
+	newWebhook := Webhook{
+		URL:    webhook.URL,
+		Secret: webhook.Secret,
+		Events: webhook.Events,
+	}
+
+	if result := repo.Create(&newWebhook); result.Error != nil {
+		log.Debugf("failed to create webhook for %s, error: %v", webhook.URL, result.Error)
+		return nil, result.Error
+	}
+
+	log.Debugf("created webhook %d for %s", newWebhook.ID, newWebhook.URL)
+	return &newWebhook, nil
+}
+
+// ClaimDueDeliveries claims the pending deliveries whose next attempt is due, starting with the oldest one.
+// The next attempt of the claimed deliveries is postponed by the lease, so other instances skip them in the meantime.
+func (w webhookRepository) ClaimDueDeliveries(now time.Time, limit int, lease time.Duration) ([]WebhookDelivery, error) {
+	log := w.logger
+	repo := w.repository
+
+	deliveries, err := claimDueDeliveries[WebhookDelivery](repo, now, limit, lease, "Webhook")
+	if err != nil {
+		log.Debugf("error claiming due webhook deliveries: %v", err)
+	}
+	return deliveries, err
+}
+
+// DeleteWebhook removes the webhook with the given ID from the database together with its deliveries.
+func (w webhookRepository) DeleteWebhook(id uint) error {
+	log := w.logger
+	repo := w.repository
+
+	if _, err := w.GetWebhook(id); err != nil {
+		return err
+	}
+
+	err := repo.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", id).Delete(&WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Webhook{}, id).Error
+	})
+
+	if err != nil {
+		log.Debugf("failed to delete webhook %d, error: %v", id, err)
+		return err
+	}
+
+	log.Debugf("deleted webhook: %d", id)
+	return nil
+}
+
+// GetDeliveries retrieves the latest deliveries of the webhook with the given ID, starting with the latest one.
+func (w webhookRepository) GetDeliveries(webhookID uint, limit int) ([]WebhookDelivery, error) {
+	log := w.logger
+	repo := w.repository
+
+	var deliveries []WebhookDelivery
+	if result := repo.Where("webhook_id = ?", webhookID).Order("id DESC").Limit(limit).Find(&deliveries); result.Error != nil {
+		log.Debugf("error fetching deliveries of webhook %d: %v", webhookID, result.Error)
+		return []WebhookDelivery{}, result.Error
+	}
+
+	log.Debugf("fetched %d deliveries of webhook %d", len(deliveries), webhookID)
+	return deliveries, nil
+}
+
+// GetWebhook retrieves the webhook with the given ID from the database.
+func (w webhookRepository) GetWebhook(id uint) (*Webhook, error) {
+	log := w.logger
+	repo := w.repository
+
+	var webhook Webhook
+	if result := repo.Where("id = ?", id).Take(&webhook); result.Error != nil {
+		log.Debugf("failed to retrieve webhook %d, error: %v", id, result.Error)
+		if result.Error.Error() == "record not found" {
+			return nil, errortypes.WebhookNotFoundError{Webhook: types.Webhook{ID: id}}
+		}
+		return nil, result.Error
+	}
+
+	log.Debugf("retrieved webhook: %d", webhook.ID)
+	return &webhook, nil
+}
+
+// GetWebhooks retrieves every webhook from the database.
+func (w webhookRepository) GetWebhooks() ([]Webhook, error) {
+	log := w.logger
+	repo := w.repository
+
+	var webhooks []Webhook
+	if result := repo.Order("id").Find(&webhooks); result.Error != nil {
+		log.Debugf("error fetching webhooks: %v", result.Error)
+		return []Webhook{}, result.Error
+	}
+
+	log.Debugf("fetched %d webhooks", len(webhooks))
+	return webhooks, nil
+}
+
+// UpdateDelivery stores the outcome of a delivery attempt.
+func (w webhookRepository) UpdateDelivery(delivery *WebhookDelivery) error {
+	log := w.logger
+	repo := w.repository
+
+	if err := updateDelivery(repo, delivery); err != nil {
+		log.Debugf("failed to update webhook delivery %d, error: %v", delivery.ID, err)
+		return err
+	}
+
+	log.Debugf("updated webhook delivery %d: %s after %d attempts", delivery.ID, delivery.Status, delivery.Attempts)
+	return nil
+}
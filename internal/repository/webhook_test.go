@@ -0,0 +1,59 @@
+package repository_test
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/types"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// webhookTestContext contains objects relevant for testing the WebhookRepository.
+type webhookTestContext struct {
+	mockDb sqlmock.Sqlmock
+	sut    repository.WebhookRepository
+}
+
+// createWebhookRepositoryContext creates the context for testing the WebhookRepository and reduces code duplication.
+func createWebhookRepositoryContext(t *testing.T) *webhookTestContext {
+	t.Helper()
+
+	db, mock, _ := sqlmock.New()
+	gormDb, _ := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}))
+
+	sut := repository.CreateWebhookRepository(logger.CreateLogger(), repository.CreateRepository(gormDb))
+	return &webhookTestContext{mock, sut}
+}
+
+// TestWebhookRepository_ClaimDueDeliveries tests claiming the due deliveries together with their webhooks, without
+// writing the webhooks
+func TestWebhookRepository_ClaimDueDeliveries(t *testing.T) {
+	t.Parallel()
+	c := createWebhookRepositoryContext(t)
+
+	now := time.Now()
+	query := regexp.QuoteMeta("SELECT * FROM `webhook_deliveries` WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at LIMIT 50 FOR UPDATE SKIP LOCKED")
+	webhookQuery := regexp.QuoteMeta("SELECT * FROM `webhooks` WHERE `webhooks`.`id` = ?")
+	claimQuery := regexp.QuoteMeta("UPDATE `webhook_deliveries` SET `next_attempt_at`=? WHERE `id` = ?")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectQuery(query).WithArgs(types.DeliveryPending, now).WillReturnRows(sqlmock.NewRows([]string{"id", "webhook_id"}).AddRow(1, 3))
+	c.mockDb.ExpectQuery(webhookQuery).WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"id", "url"}).AddRow(3, "https://example.com/hook"))
+	c.mockDb.ExpectExec(claimQuery).WithArgs(now.Add(time.Hour), 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectCommit()
+
+	deliveries, err := c.sut.ClaimDueDeliveries(now, 50, time.Hour)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 1, len(deliveries), "incorrect number of deliveries")
+	assert.Equal(t, "https://example.com/hook", deliveries[0].Webhook.URL, "the webhook should be loaded")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "the delivery should be claimed")
+}
@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/types"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// Webmention DB schema. Stores the received mentions of the posts and the state of their verification and moderation.
+// Queued mentions wait for the verification of their source, e.g. when they're received again after the source changed.
+type Webmention struct {
+	ID            uint `gorm:"primaryKey;autoIncrement"`
+	PostID        uint `gorm:"not null;index"`
+	Post          Post
+	Source        string    `gorm:"not null;size:255;uniqueIndex:idx_webmention,priority:1"`
+	Target        string    `gorm:"not null;size:255;uniqueIndex:idx_webmention,priority:2"`
+	Type          string    `gorm:"not null;default:mention"`
+	Status        string    `gorm:"not null;default:pending;index"`
+	AuthorName    string    `gorm:"size:255"`
+	AuthorURL     string    `gorm:"size:255"`
+	Title         string    `gorm:"size:255"`
+	Content       string    `gorm:"type:text"`
+	Queued        bool      `gorm:"not null;default:true;index:idx_webmention_queue,priority:1"`
+	Attempts      int       `gorm:"not null;default:0"`
+	NextAttemptAt time.Time `gorm:"index:idx_webmention_queue,priority:2"`
+	Error         string
+	VerifiedAt    *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// WebmentionDelivery DB schema. Stores the mentions sent to the pages linked from the posts and the outcome of the attempts.
+type WebmentionDelivery struct {
+	ID       uint   `gorm:"primaryKey;autoIncrement"`
+	Source   string `gorm:"not null;size:255;uniqueIndex:idx_webmention_delivery,priority:1"`
+	Target   string `gorm:"not null;size:255;uniqueIndex:idx_webmention_delivery,priority:2"`
+	Endpoint string
+	DeliveryState
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// WebmentionRepository interface defining the database operations of the received and sent webmentions.
+type WebmentionRepository interface {
+	AddWebmention(mention *Webmention) error
+	ClaimDueDeliveries(now time.Time, limit int, lease time.Duration) ([]WebmentionDelivery, error)
+	ClaimDueWebmentions(now time.Time, limit int, lease time.Duration) ([]Webmention, error)
+	GetDeliveryTargets(source string) ([]string, error)
+	GetWebmention(id uint) (*Webmention, error)
+	GetWebmentions(postID uint, status string) ([]Webmention, error)
+	QueueDeliveries(source string, targets []string, now time.Time) error
+	UpdateDelivery(delivery *WebmentionDelivery) error
+	UpdateWebmention(mention *Webmention) error
+}
+
+// webmentionRepository is the concrete implementation of the WebmentionRepository interface.
+type webmentionRepository struct {
+	logger     *zap.SugaredLogger
+	repository Repository
+}
+
+// CreateWebmentionRepository instantiates the webmentionRepository
+func CreateWebmentionRepository(logger *zap.SugaredLogger, repository Repository) WebmentionRepository {
+	initWebmentionModel(logger, repository)
+
+	return &webmentionRepository{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// initWebmentionModel initializes the Webmention and WebmentionDelivery schemas in the database
+func initWebmentionModel(logger *zap.SugaredLogger, repository Repository) {
+	if err := repository.AutoMigrate(&Webmention{}); err != nil {
+		logger.Errorf("failed to initialize webmention model: %v", err)
+	}
+	if err := repository.AutoMigrate(&WebmentionDelivery{}); err != nil {
+		logger.Errorf("failed to initialize webmention delivery model: %v", err)
+	}
+}
+
+// AddWebmention stores a received mention and queues it for verification.
+// A mention received again is queued for verification again, keeping its moderation state.
+func (w webmentionRepository) AddWebmention(mention *Webmention) error {
+	log := w.logger
+	repo := w.repository
+
+	err := repo.Transaction(func(tx *gorm.DB) error {
+		var existing Webmention
+		result := tx.Where("source = ? AND target = ?", mention.Source, mention.Target).Take(&existing)
+		if result.Error != nil {
+			if result.Error.Error() != "record not found" {
+				return result.Error
+			}
+			return tx.Create(mention).Error
+		}
+
+		existing.PostID = mention.PostID
+		existing.Queued = true
+		existing.Attempts = 0
+		existing.NextAttemptAt = mention.NextAttemptAt
+		existing.Error = ""
+		*mention = existing
+		return tx.Select("PostID", "Queued", "Attempts", "NextAttemptAt", "Error").Updates(mention).Error
+	})
+
+	if err != nil {
+		log.Debugf("failed to store webmention from %s to %s, error: %v", mention.Source, mention.Target, err)
+		return err
+	}
+
+	log.Debugf("queued webmention %d from %s for verification", mention.ID, mention.Source)
+	return nil
+}
+
+// ClaimDueDeliveries claims the pending deliveries whose next attempt is due, starting with the oldest one.
+// The next attempt of the claimed deliveries is postponed by the lease, so other instances skip them in the meantime.
+func (w webmentionRepository) ClaimDueDeliveries(now time.Time, limit int, lease time.Duration) ([]WebmentionDelivery, error) {
+	log := w.logger
+	repo := w.repository
+
+	deliveries, err := claimDueDeliveries[WebmentionDelivery](repo, now, limit, lease)
+	if err != nil {
+		log.Debugf("error claiming due webmention deliveries: %v", err)
+	}
+	return deliveries, err
+}
+
+// ClaimDueWebmentions claims the queued mentions whose next verification attempt is due, starting with the oldest one.
+// The next attempt of the claimed mentions is postponed by the lease, so other instances skip them in the meantime.
+func (w webmentionRepository) ClaimDueWebmentions(now time.Time, limit int, lease time.Duration) ([]Webmention, error) {
+	log := w.logger
+	repo := w.repository
+
+	condition := clause.Expr{SQL: "queued = ? AND next_attempt_at <= ?", Vars: []interface{}{true, now}}
+	mentions, err := claimRows[Webmention](repo, condition, "next_attempt_at", limit, "next_attempt_at", now.Add(lease))
+	if err != nil {
+		log.Debugf("error claiming due webmentions: %v", err)
+	}
+	return mentions, err
+}
+
+// GetDeliveryTargets retrieves the pages mentioned by a source before, so they can be notified if the links are removed.
+func (w webmentionRepository) GetDeliveryTargets(source string) ([]string, error) {
+	log := w.logger
+	repo := w.repository
+
+	var targets []string
+	if result := repo.Where("source = ?", source).Model(&WebmentionDelivery{}).Order("id").Pluck("target", &targets); result.Error != nil {
+		log.Debugf("error fetching webmention targets of %s: %v", source, result.Error)
+		return []string{}, result.Error
+	}
+
+	return targets, nil
+}
+
+// GetWebmention retrieves a received mention together with its post, its author and its contributors.
+func (w webmentionRepository) GetWebmention(id uint) (*Webmention, error) {
+	log := w.logger
+	repo := w.repository
+
+	var mention Webmention
+	if result := repo.Preload("Post.Author").Preload("Post.Contributors.User").Where("id = ?", id).Take(&mention); result.Error != nil {
+		log.Debugf("failed to retrieve webmention %d, error: %v", id, result.Error)
+		if result.Error.Error() == "record not found" {
+			return nil, errortypes.WebmentionNotFoundError{ID: id}
+		}
+		return nil, result.Error
+	}
+
+	return &mention, nil
+}
+
+// GetWebmentions retrieves the received mentions of a post, starting with the oldest one.
+// If a status is provided, only the mentions in the given state are retrieved.
+func (w webmentionRepository) GetWebmentions(postID uint, status string) ([]Webmention, error) {
+	log := w.logger
+	repo := w.repository
+
+	query := repo.Where("post_id = ?", postID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var mentions []Webmention
+	if result := query.Order("created_at").Order("id").Find(&mentions); result.Error != nil {
+		log.Debugf("error fetching webmentions of post %d: %v", postID, result.Error)
+		return []Webmention{}, result.Error
+	}
+
+	log.Debugf("fetched %d webmentions of post %d", len(mentions), postID)
+	return mentions, nil
+}
+
+// QueueDeliveries queues the mentions of the targets by a source. Targets mentioned before are queued again and their
+// endpoints are discovered again.
+func (w webmentionRepository) QueueDeliveries(source string, targets []string, now time.Time) error {
+	log := w.logger
+	repo := w.repository
+
+	if len(targets) == 0 {
+		return nil
+	}
+
+	err := repo.Transaction(func(tx *gorm.DB) error {
+		for _, target := range targets {
+			var delivery WebmentionDelivery
+			result := tx.Where("source = ? AND target = ?", source, target).Take(&delivery)
+			if result.Error != nil && result.Error.Error() != "record not found" {
+				return result.Error
+			}
+
+			delivery.Source = source
+			delivery.Target = target
+			delivery.Endpoint = ""
+			delivery.Status = types.DeliveryPending
+			delivery.Attempts = 0
+			delivery.NextAttemptAt = now
+			delivery.Error = ""
+
+			if delivery.ID == 0 {
+				if err := tx.Create(&delivery).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err := tx.Select("Endpoint", "Status", "Attempts", "NextAttemptAt", "Error").Updates(&delivery).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		log.Debugf("failed to queue %d webmentions from %s, error: %v", len(targets), source, err)
+		return err
+	}
+
+	log.Debugf("queued %d webmentions from %s", len(targets), source)
+	return nil
+}
+
+// UpdateDelivery stores the outcome of a delivery attempt.
+func (w webmentionRepository) UpdateDelivery(delivery *WebmentionDelivery) error {
+	log := w.logger
+	repo := w.repository
+
+	if err := updateDelivery(repo, delivery, "Endpoint"); err != nil {
+		log.Debugf("failed to update webmention delivery %d, error: %v", delivery.ID, err)
+		return err
+	}
+
+	log.Debugf("updated webmention delivery %d: %s after %d attempts", delivery.ID, delivery.Status, delivery.Attempts)
+	return nil
+}
+
+// UpdateWebmention stores the outcome of the verification or the moderation of a mention.
+func (w webmentionRepository) UpdateWebmention(mention *Webmention) error {
+	log := w.logger
+	repo := w.repository
+
+	result := repo.Select("Type", "Status", "AuthorName", "AuthorURL", "Title", "Content", "Queued", "Attempts", "NextAttemptAt", "Error", "VerifiedAt").
+		Updates(mention)
+	if result.Error != nil {
+		log.Debugf("failed to update webmention %d, error: %v", mention.ID, result.Error)
+		return result.Error
+	}
+
+	log.Debugf("updated webmention %d: %s", mention.ID, mention.Status)
+	return nil
+}
@@ -0,0 +1,129 @@
+package repository_test
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/repository"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// webmentionTestContext contains objects relevant for testing the WebmentionRepository.
+type webmentionTestContext struct {
+	mockDb sqlmock.Sqlmock
+	sut    repository.WebmentionRepository
+}
+
+// createWebmentionRepositoryContext creates the context for testing the WebmentionRepository and reduces code duplication.
+func createWebmentionRepositoryContext(t *testing.T) *webmentionTestContext {
+	t.Helper()
+
+	db, mock, _ := sqlmock.New()
+	gormDb, _ := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}))
+
+	sut := repository.CreateWebmentionRepository(logger.CreateLogger(), repository.CreateRepository(gormDb))
+	return &webmentionTestContext{mock, sut}
+}
+
+// TestWebmentionRepository_AddWebmention_Existing tests queueing a mention received again for verification
+func TestWebmentionRepository_AddWebmention_Existing(t *testing.T) {
+	t.Parallel()
+	c := createWebmentionRepositoryContext(t)
+
+	source, target := "https://remote.example.com/reply", "https://blog.example.com/posts/hello"
+	query := regexp.QuoteMeta("SELECT * FROM `webmentions` WHERE source = ? AND target = ? LIMIT 1")
+	rows := sqlmock.NewRows([]string{"id", "post_id", "source", "target", "status", "attempts"}).AddRow(3, 1, source, target, "approved", 5)
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectQuery(query).WithArgs(source, target).WillReturnRows(rows)
+	c.mockDb.ExpectExec(regexp.QuoteMeta("UPDATE `webmentions` SET `post_id`=?,`queued`=?,`attempts`=?,`next_attempt_at`=?,`error`=?,`updated_at`=? WHERE `id` = ?")).
+		WithArgs(1, true, 0, sqlmock.AnyArg(), "", sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	c.mockDb.ExpectCommit()
+
+	mention := repository.Webmention{PostID: 1, Source: source, Target: target, Status: "pending", Queued: true, NextAttemptAt: time.Now()}
+	err := c.sut.AddWebmention(&mention)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, uint(3), mention.ID, "the existing mention should be returned")
+	assert.Equal(t, "approved", mention.Status, "the moderation state should be kept")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "every expectation should be met")
+}
+
+// TestWebmentionRepository_GetWebmention_Not_Found tests retrieving an unknown mention
+func TestWebmentionRepository_GetWebmention_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createWebmentionRepositoryContext(t)
+
+	query := regexp.QuoteMeta("SELECT * FROM `webmentions` WHERE id = ? LIMIT 1")
+
+	c.mockDb.ExpectQuery(query).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	mention, err := c.sut.GetWebmention(1)
+
+	assert.Nil(t, mention, "no mention should be returned")
+	assert.Equal(t, errortypes.WebmentionNotFoundError{ID: 1}, err, "incorrect error type")
+}
+
+// TestWebmentionRepository_GetWebmentions tests retrieving the mentions of a post in a given state
+func TestWebmentionRepository_GetWebmentions(t *testing.T) {
+	t.Parallel()
+	c := createWebmentionRepositoryContext(t)
+
+	query := regexp.QuoteMeta("SELECT * FROM `webmentions` WHERE post_id = ? AND status = ? ORDER BY created_at,id")
+	rows := sqlmock.NewRows([]string{"id", "post_id", "status"}).AddRow(1, 1, "approved").AddRow(2, 1, "approved")
+
+	c.mockDb.ExpectQuery(query).WithArgs(1, "approved").WillReturnRows(rows)
+
+	mentions, err := c.sut.GetWebmentions(1, "approved")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 2, len(mentions), "incorrect number of mentions")
+}
+
+// TestWebmentionRepository_GetDeliveryTargets tests retrieving the pages mentioned by a source
+func TestWebmentionRepository_GetDeliveryTargets(t *testing.T) {
+	t.Parallel()
+	c := createWebmentionRepositoryContext(t)
+
+	source := "https://blog.example.com/posts/hello"
+	query := regexp.QuoteMeta("SELECT `target` FROM `webmention_deliveries` WHERE source = ? ORDER BY id")
+	rows := sqlmock.NewRows([]string{"target"}).AddRow("https://a.example.com/1").AddRow("https://b.example.com/2")
+
+	c.mockDb.ExpectQuery(query).WithArgs(source).WillReturnRows(rows)
+
+	targets, err := c.sut.GetDeliveryTargets(source)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, []string{"https://a.example.com/1", "https://b.example.com/2"}, targets, "incorrect targets")
+}
+
+// TestWebmentionRepository_ClaimDueWebmentions tests claiming the queued mentions whose verification is due
+func TestWebmentionRepository_ClaimDueWebmentions(t *testing.T) {
+	t.Parallel()
+	c := createWebmentionRepositoryContext(t)
+
+	now := time.Now()
+	query := regexp.QuoteMeta("SELECT * FROM `webmentions` WHERE queued = ? AND next_attempt_at <= ? ORDER BY next_attempt_at LIMIT 20 FOR UPDATE SKIP LOCKED")
+	claimQuery := regexp.QuoteMeta("UPDATE `webmentions` SET `next_attempt_at`=? WHERE `id` IN (?,?)")
+	rows := sqlmock.NewRows([]string{"id", "source"}).AddRow(1, "https://a.example.com").AddRow(2, "https://b.example.com")
+
+	c.mockDb.ExpectBegin()
+	c.mockDb.ExpectQuery(query).WithArgs(true, now).WillReturnRows(rows)
+	c.mockDb.ExpectExec(claimQuery).WithArgs(now.Add(time.Minute), 1, 2).WillReturnResult(sqlmock.NewResult(0, 2))
+	c.mockDb.ExpectCommit()
+
+	mentions, err := c.sut.ClaimDueWebmentions(now, 20, time.Minute)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 2, len(mentions), "incorrect number of mentions")
+	assert.Nil(t, c.mockDb.ExpectationsWereMet(), "the mentions should be claimed")
+}
@@ -0,0 +1,398 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/site"
+	"github.com/wlchs/blog/internal/types"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// analyticsDateFormat is the format of the dates selecting the range of the statistics.
+const analyticsDateFormat = "2006-01-02"
+
+// Limits of the statistics queries. Hourly statistics are restricted to shorter ranges to keep the series small.
+const (
+	analyticsDefaultDays  = 30
+	analyticsMaxDays      = 366
+	analyticsMaxHourDays  = 31
+	analyticsDefaultLimit = 10
+	analyticsMaxLimit     = 100
+)
+
+// analyticsBotPattern matches the user agents of crawlers and link previews, whose requests aren't counted as views.
+var analyticsBotPattern = regexp.MustCompile(`(?i)bot|crawl|spider|slurp|preview|facebookexternalhit|curl|wget|python-requests|go-http-client`)
+
+// AnalyticsService interface. Defines the cookieless page-view analytics of the posts.
+type AnalyticsService interface {
+	GetPostStats(urlHandle string, query types.AnalyticsQuery, userName string) (types.AnalyticsStats, error)
+	GetStats(query types.AnalyticsQuery, userName string) (types.AnalyticsStats, error)
+	RecordView(urlHandle string, visit types.PageVisit) error
+	RollupViews() error
+	RunRollups(stop <-chan struct{})
+}
+
+// analyticsService is the concrete implementation of the AnalyticsService interface.
+type analyticsService struct {
+	cont    container.Container
+	site    site.Config
+	config  analyticsConfig
+	rollups *analyticsRollups
+	salts   *visitorSalts
+}
+
+// analyticsConfig contains the settings of the rollups.
+type analyticsConfig struct {
+	RollupInterval time.Duration
+	Retention      time.Duration
+}
+
+// analyticsRollups remembers when the views were rolled up last, so only the recent periods are recomputed.
+type analyticsRollups struct {
+	mutex sync.Mutex
+	last  time.Time
+}
+
+// visitorSalts keeps the salt of the visitor hashes of the current day in memory.
+type visitorSalts struct {
+	mutex sync.Mutex
+	day   time.Time
+	salt  []byte
+}
+
+// CreateAnalyticsService instantiates the analyticsService using the application container.
+func CreateAnalyticsService(cont container.Container) AnalyticsService {
+	return &analyticsService{cont, site.LoadConfig(), loadAnalyticsConfig(), &analyticsRollups{}, &visitorSalts{}}
+}
+
+// loadAnalyticsConfig reads the settings of the analytics from the ANALYTICS_* environment variables.
+// The raw views are kept for at least two days, so the rollups of the previous day can always be recomputed.
+func loadAnalyticsConfig() analyticsConfig {
+	config := analyticsConfig{
+		RollupInterval: 5 * time.Minute,
+		Retention:      48 * time.Hour,
+	}
+
+	if interval, err := time.ParseDuration(os.Getenv("ANALYTICS_ROLLUP_INTERVAL")); err == nil && interval > 0 {
+		config.RollupInterval = interval
+	}
+	if retention, err := time.ParseDuration(os.Getenv("ANALYTICS_RETENTION")); err == nil && retention >= 48*time.Hour {
+		config.Retention = retention
+	}
+
+	return config
+}
+
+// GetPostStats retrieves the views of a post per hour or day and its top referrers.
+// Only the author and the contributors of the post may see its statistics.
+func (a analyticsService) GetPostStats(urlHandle string, query types.AnalyticsQuery, userName string) (types.AnalyticsStats, error) {
+	postRepository := a.cont.GetPostRepository()
+
+	stats, limit, err := parseAnalyticsQuery(query, time.Now())
+	if err != nil {
+		return types.AnalyticsStats{}, err
+	}
+
+	post, err := postRepository.GetPost(urlHandle)
+	if err != nil {
+		return types.AnalyticsStats{}, err
+	}
+
+	if !canReadPost(post, userName) {
+		return types.AnalyticsStats{}, errortypes.PostEditForbiddenError{Post: types.Post{URLHandle: urlHandle}, UserName: userName}
+	}
+
+	stats.Post = post.URLHandle
+	if err := a.collectStats(&stats, []uint{post.ID}, limit); err != nil {
+		return types.AnalyticsStats{}, err
+	}
+
+	return stats, nil
+}
+
+// GetStats retrieves the views of every post the user authored or contributed to, their top posts and top referrers.
+func (a analyticsService) GetStats(query types.AnalyticsQuery, userName string) (types.AnalyticsStats, error) {
+	log := a.cont.GetLogger()
+	postRepository := a.cont.GetPostRepository()
+	analyticsRepository := a.cont.GetAnalyticsRepository()
+
+	stats, limit, err := parseAnalyticsQuery(query, time.Now())
+	if err != nil {
+		return types.AnalyticsStats{}, err
+	}
+
+	posts, err := postRepository.GetAllPosts()
+	if err != nil {
+		return types.AnalyticsStats{}, err
+	}
+
+	ids := make([]uint, 0, len(posts))
+	postsByID := make(map[uint]*repository.Post, len(posts))
+	for i := range posts {
+		if canReadPost(&posts[i], userName) {
+			ids = append(ids, posts[i].ID)
+			postsByID[posts[i].ID] = &posts[i]
+		}
+	}
+
+	log.Debugf("collecting the statistics of %d posts of %s", len(ids), userName)
+
+	if err := a.collectStats(&stats, ids, limit); err != nil {
+		return types.AnalyticsStats{}, err
+	}
+
+	topPosts, err := analyticsRepository.GetTopPosts(ids, stats.From, stats.To.AddDate(0, 0, 1), limit)
+	if err != nil {
+		return types.AnalyticsStats{}, err
+	}
+
+	stats.TopPosts = make([]types.PostViews, 0, len(topPosts))
+	for _, row := range topPosts {
+		if post, ok := postsByID[row.PostID]; ok {
+			stats.TopPosts = append(stats.TopPosts, types.PostViews{URLHandle: post.URLHandle, Title: post.Title, Views: row.Views, Visitors: row.Visitors})
+		}
+	}
+
+	return stats, nil
+}
+
+// RecordView counts a view of a post. Crawlers aren't counted. The visitor is identified by a hash of their address and
+// their user agent, keyed with the random salt of the day, so the same visitor can't be recognized on the next day.
+func (a analyticsService) RecordView(urlHandle string, visit types.PageVisit) error {
+	log := a.cont.GetLogger()
+	analyticsRepository := a.cont.GetAnalyticsRepository()
+
+	if visit.UserAgent == "" || analyticsBotPattern.MatchString(visit.UserAgent) {
+		log.Debugf("ignoring view of post %s by a crawler", urlHandle)
+		return nil
+	}
+
+	now := time.Now().UTC()
+	day := startOfDay(now)
+
+	salt, err := a.visitorSalt(day)
+	if err != nil {
+		log.Errorf("failed to retrieve the visitor salt: %v", err)
+		return err
+	}
+
+	mac := hmac.New(sha256.New, salt)
+	mac.Write([]byte(visit.Address + "\x00" + visit.UserAgent))
+
+	view := repository.PageView{
+		Visitor:  hex.EncodeToString(mac.Sum(nil)),
+		Referrer: a.referrerHost(visit),
+		Hour:     now.Truncate(time.Hour),
+		Day:      day,
+	}
+
+	return analyticsRepository.AddPageView(urlHandle, &view)
+}
+
+// RollupViews recomputes the rollups of the periods whose views changed since the last run and drops the expired views.
+// On the first run, every day whose views are still complete is recomputed.
+func (a analyticsService) RollupViews() error {
+	log := a.cont.GetLogger()
+	analyticsRepository := a.cont.GetAnalyticsRepository()
+
+	a.rollups.mutex.Lock()
+	defer a.rollups.mutex.Unlock()
+
+	now := time.Now().UTC()
+	expiry := now.Add(-a.config.Retention)
+
+	// The first day whose views haven't expired partially
+	since := startOfDay(expiry).AddDate(0, 0, 1)
+	if last := startOfDay(a.rollups.last); last.After(since) {
+		since = last
+	}
+
+	if err := analyticsRepository.RollupPageViews(since); err != nil {
+		log.Errorf("failed to roll up the views: %v", err)
+		return err
+	}
+	a.rollups.last = now
+
+	if _, err := analyticsRepository.DeletePageViews(expiry); err != nil {
+		log.Errorf("failed to delete the expired views: %v", err)
+		return err
+	}
+
+	// Deletes the salts of the past days even if no view was recorded since the rollover
+	if err := analyticsRepository.DeleteVisitorSalts(startOfDay(now)); err != nil {
+		log.Errorf("failed to delete the visitor salts of the past days: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// RunRollups rolls up the recorded views periodically until the stop channel is closed.
+func (a analyticsService) RunRollups(stop <-chan struct{}) {
+	ticker := time.NewTicker(a.config.RollupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			_ = a.RollupViews()
+		}
+	}
+}
+
+// visitorSalt returns the salt of the visitor hashes of the given day. A random salt is generated for every day and
+// stored in the database, so it's shared by the instances of the blog engine. On rollover, the salts of the past days
+// are deleted from the memory and the database.
+func (a analyticsService) visitorSalt(day time.Time) ([]byte, error) {
+	analyticsRepository := a.cont.GetAnalyticsRepository()
+
+	a.salts.mutex.Lock()
+	defer a.salts.mutex.Unlock()
+
+	if a.salts.day.Equal(day) {
+		return a.salts.salt, nil
+	}
+
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	salt, err := analyticsRepository.AddVisitorSalt(day, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	a.salts.day = day
+	a.salts.salt = salt
+
+	if err := analyticsRepository.DeleteVisitorSalts(day); err != nil {
+		a.cont.GetLogger().Warnf("failed to delete the visitor salts of the past days: %v", err)
+	}
+
+	return salt, nil
+}
+
+// collectStats fills the series, the totals and the top referrers of the given posts.
+func (a analyticsService) collectStats(stats *types.AnalyticsStats, postIDs []uint, limit int) error {
+	analyticsRepository := a.cont.GetAnalyticsRepository()
+
+	to := stats.To.AddDate(0, 0, 1)
+
+	series, err := analyticsRepository.GetViewSeries(postIDs, stats.Period, stats.From, to)
+	if err != nil {
+		return err
+	}
+
+	stats.Series = make([]types.ViewCount, 0, len(series))
+	for _, row := range series {
+		stats.Series = append(stats.Series, types.ViewCount{Start: row.Start.UTC(), Views: row.Views, Visitors: row.Visitors})
+		stats.Views += row.Views
+		stats.Visitors += row.Visitors
+	}
+
+	referrers, err := analyticsRepository.GetTopReferrers(postIDs, stats.From, to, limit)
+	if err != nil {
+		return err
+	}
+
+	stats.TopReferrers = make([]types.ReferrerViews, 0, len(referrers))
+	for _, row := range referrers {
+		stats.TopReferrers = append(stats.TopReferrers, types.ReferrerViews{Host: row.Host, Views: row.Views, Visitors: row.Visitors})
+	}
+
+	return nil
+}
+
+// referrerHost extracts the host of the page referring a visit. Referrals from the blog itself count as direct visits.
+func (a analyticsService) referrerHost(visit types.PageVisit) string {
+	referrer, err := url.Parse(visit.Referrer)
+	if err != nil || referrer.Hostname() == "" {
+		return ""
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(referrer.Hostname()), "www.")
+	if own, err := url.Parse(a.site.BaseURL); err == nil && strings.TrimPrefix(strings.ToLower(own.Hostname()), "www.") == host {
+		return ""
+	}
+	if own, err := url.Parse("//" + visit.Host); err == nil && strings.TrimPrefix(strings.ToLower(own.Hostname()), "www.") == host {
+		return ""
+	}
+
+	if len(host) > 255 {
+		return host[:255]
+	}
+	return host
+}
+
+// parseAnalyticsQuery validates the range, the period and the limit of a statistics query.
+// The last 30 days are selected by default, daily.
+func parseAnalyticsQuery(query types.AnalyticsQuery, now time.Time) (types.AnalyticsStats, int, error) {
+	stats := types.AnalyticsStats{To: startOfDay(now.UTC()), Period: query.Period}
+
+	if query.To != "" {
+		to, err := time.Parse(analyticsDateFormat, query.To)
+		if err != nil {
+			return types.AnalyticsStats{}, 0, errortypes.InvalidAnalyticsQueryError{Reason: "to must be a date like 2006-01-02"}
+		}
+		stats.To = to
+	}
+
+	stats.From = stats.To.AddDate(0, 0, 1-analyticsDefaultDays)
+	if query.From != "" {
+		from, err := time.Parse(analyticsDateFormat, query.From)
+		if err != nil {
+			return types.AnalyticsStats{}, 0, errortypes.InvalidAnalyticsQueryError{Reason: "from must be a date like 2006-01-02"}
+		}
+		stats.From = from
+	}
+
+	days := int(stats.To.Sub(stats.From).Hours()/24) + 1
+	if days < 1 {
+		return types.AnalyticsStats{}, 0, errortypes.InvalidAnalyticsQueryError{Reason: "from must not be after to"}
+	}
+
+	switch stats.Period {
+	case "":
+		stats.Period = types.AnalyticsPeriodDay
+	case types.AnalyticsPeriodDay:
+	case types.AnalyticsPeriodHour:
+		if days > analyticsMaxHourDays {
+			return types.AnalyticsStats{}, 0, errortypes.InvalidAnalyticsQueryError{Reason: "hourly statistics are limited to 31 days"}
+		}
+	default:
+		return types.AnalyticsStats{}, 0, errortypes.InvalidAnalyticsQueryError{Reason: "period must be hour or day"}
+	}
+
+	if days > analyticsMaxDays {
+		return types.AnalyticsStats{}, 0, errortypes.InvalidAnalyticsQueryError{Reason: "the range is limited to 366 days"}
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = analyticsDefaultLimit
+	}
+	if limit > analyticsMaxLimit {
+		limit = analyticsMaxLimit
+	}
+
+	return stats, limit, nil
+}
+
+// startOfDay truncates the given time to the start of its day in its location.
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
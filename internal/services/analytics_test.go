@@ -0,0 +1,233 @@
+package services_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/types"
+	"testing"
+	"time"
+)
+
+// analyticsVisit is the request of a post page by a browser in the tests.
+var analyticsVisit = types.PageVisit{Address: "192.0.2.1", UserAgent: "Mozilla/5.0", Host: "blog.example.com"}
+
+// analyticsTestContext contains objects relevant for testing the AnalyticsService.
+type analyticsTestContext struct {
+	mockAnalyticsRepository *mocks.MockAnalyticsRepository
+	mockPostRepository      *mocks.MockPostRepository
+	sut                     services.AnalyticsService
+}
+
+// createAnalyticsServiceContext creates the context for testing the AnalyticsService and reduces code duplication.
+// The site URL is set in the environment, so the tests can't run in parallel.
+func createAnalyticsServiceContext(t *testing.T) *analyticsTestContext {
+	t.Helper()
+	t.Setenv("SITE_URL", "https://blog.example.com")
+
+	mockCtrl := gomock.NewController(t)
+	mockAnalyticsRepository := mocks.NewMockAnalyticsRepository(mockCtrl)
+	mockPostRepository := mocks.NewMockPostRepository(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), mockAnalyticsRepository, nil, nil, nil, nil, nil, mockPostRepository, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	sut := services.CreateAnalyticsService(cont)
+
+	return &analyticsTestContext{mockAnalyticsRepository, mockPostRepository, sut}
+}
+
+// expectVisitorSalt expects the salt of the current day to be stored once, returning the given salt as the stored one.
+func expectVisitorSalt(c *analyticsTestContext, salt []byte) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	c.mockAnalyticsRepository.EXPECT().AddVisitorSalt(today, gomock.Any()).Return(salt, nil)
+	c.mockAnalyticsRepository.EXPECT().DeleteVisitorSalts(today).Return(nil)
+}
+
+// recordView records a view of the test post and returns the stored view.
+func recordView(t *testing.T, c *analyticsTestContext, visit types.PageVisit) repository.PageView {
+	t.Helper()
+
+	var view repository.PageView
+	c.mockAnalyticsRepository.EXPECT().AddPageView("hello", gomock.Any()).DoAndReturn(func(_ string, v *repository.PageView) error {
+		view = *v
+		return nil
+	})
+
+	assert.Nil(t, c.sut.RecordView("hello", visit), "should complete without error")
+	return view
+}
+
+// TestAnalyticsService_RecordView tests recording a view referred by another site.
+func TestAnalyticsService_RecordView(t *testing.T) {
+	c := createAnalyticsServiceContext(t)
+
+	visit := analyticsVisit
+	visit.Referrer = "https://www.News.example.org/links?page=2"
+
+	expectVisitorSalt(c, []byte("salt"))
+	view := recordView(t, c, visit)
+	again := recordView(t, c, visit)
+
+	assert.Equal(t, "news.example.org", view.Referrer, "only the host of the referrer should be stored")
+	assert.Len(t, view.Visitor, 64, "visitor should be a hash")
+	assert.NotContains(t, view.Visitor, visit.Address, "visitor shouldn't contain the address")
+	assert.Equal(t, view.Visitor, again.Visitor, "visitor should be recognized on the same day")
+	assert.Equal(t, view.Hour.Truncate(time.Hour), view.Hour, "view should be assigned to its hour")
+	assert.Equal(t, view.Hour.Truncate(24*time.Hour), view.Day, "view should be assigned to its day")
+}
+
+// TestAnalyticsService_RecordView_Internal_Referrer tests recording a view referred by the blog itself.
+func TestAnalyticsService_RecordView_Internal_Referrer(t *testing.T) {
+	c := createAnalyticsServiceContext(t)
+
+	visit := analyticsVisit
+	visit.Referrer = "https://blog.example.com/posts"
+
+	expectVisitorSalt(c, []byte("salt"))
+	view := recordView(t, c, visit)
+
+	assert.Equal(t, "", view.Referrer, "internal referrals should count as direct visits")
+}
+
+// TestAnalyticsService_RecordView_Stored_Salt tests hashing the visitors with the salt stored in the database, e.g. by
+// another instance, instead of the generated one.
+func TestAnalyticsService_RecordView_Stored_Salt(t *testing.T) {
+	c := createAnalyticsServiceContext(t)
+
+	expectVisitorSalt(c, []byte("stored"))
+	view := recordView(t, c, analyticsVisit)
+
+	mac := hmac.New(sha256.New, []byte("stored"))
+	mac.Write([]byte(analyticsVisit.Address + "\x00" + analyticsVisit.UserAgent))
+	assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), view.Visitor, "stored salt should be used")
+}
+
+// TestAnalyticsService_RecordView_Crawler tests ignoring the requests of crawlers.
+func TestAnalyticsService_RecordView_Crawler(t *testing.T) {
+	c := createAnalyticsServiceContext(t)
+
+	visit := analyticsVisit
+	visit.UserAgent = "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"
+
+	err := c.sut.RecordView("hello", visit)
+
+	assert.Nil(t, err, "should complete without error")
+}
+
+// TestAnalyticsService_GetStats tests collecting the statistics of the posts of a user.
+func TestAnalyticsService_GetStats(t *testing.T) {
+	c := createAnalyticsServiceContext(t)
+
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	posts := []repository.Post{
+		{ID: 1, URLHandle: "first", Title: "First", Author: repository.User{UserName: "testAuthor"}},
+		{ID: 2, URLHandle: "foreign", Title: "Foreign", Author: repository.User{UserName: "otherAuthor"}},
+		{ID: 3, URLHandle: "shared", Title: "Shared", Author: repository.User{UserName: "otherAuthor"},
+			Contributors: []repository.Contributor{{User: repository.User{UserName: "testAuthor"}, Role: types.ContributorRoleEditor}}},
+	}
+
+	c.mockPostRepository.EXPECT().GetAllPosts().Return(posts, nil)
+	c.mockAnalyticsRepository.EXPECT().GetViewSeries([]uint{1, 3}, types.AnalyticsPeriodDay, from, to).
+		Return([]repository.ViewRollup{{Start: from, Views: 4, Visitors: 3}, {Start: from.AddDate(0, 0, 2), Views: 1, Visitors: 1}}, nil)
+	c.mockAnalyticsRepository.EXPECT().GetTopReferrers([]uint{1, 3}, from, to, 5).
+		Return([]repository.ReferrerRollup{{Host: "example.org", Views: 3, Visitors: 2}, {Views: 2, Visitors: 2}}, nil)
+	c.mockAnalyticsRepository.EXPECT().GetTopPosts([]uint{1, 3}, from, to, 5).
+		Return([]repository.ViewRollup{{PostID: 3, Views: 3, Visitors: 2}, {PostID: 1, Views: 2, Visitors: 2}}, nil)
+
+	stats, err := c.sut.GetStats(types.AnalyticsQuery{From: "2024-05-01", To: "2024-05-07", Limit: 5}, "testAuthor")
+
+	expected := types.AnalyticsStats{
+		From:     from,
+		To:       from.AddDate(0, 0, 6),
+		Period:   types.AnalyticsPeriodDay,
+		Views:    5,
+		Visitors: 4,
+		Series:   []types.ViewCount{{Start: from, Views: 4, Visitors: 3}, {Start: from.AddDate(0, 0, 2), Views: 1, Visitors: 1}},
+		TopPosts: []types.PostViews{
+			{URLHandle: "shared", Title: "Shared", Views: 3, Visitors: 2},
+			{URLHandle: "first", Title: "First", Views: 2, Visitors: 2},
+		},
+		TopReferrers: []types.ReferrerViews{{Host: "example.org", Views: 3, Visitors: 2}, {Views: 2, Visitors: 2}},
+	}
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, expected, stats, "statistics don't match the expected output")
+}
+
+// TestAnalyticsService_GetStats_Invalid_Query tests requesting statistics with invalid parameters.
+func TestAnalyticsService_GetStats_Invalid_Query(t *testing.T) {
+	c := createAnalyticsServiceContext(t)
+
+	queries := []types.AnalyticsQuery{
+		{From: "yesterday"},
+		{From: "2024-05-02", To: "2024-05-01"},
+		{From: "2023-01-01", To: "2024-05-01"},
+		{Period: "week"},
+		{From: "2024-03-01", To: "2024-05-01", Period: types.AnalyticsPeriodHour},
+	}
+
+	for _, query := range queries {
+		_, err := c.sut.GetStats(query, "testAuthor")
+		assert.IsType(t, errortypes.InvalidAnalyticsQueryError{}, err, "query %v should be rejected", query)
+	}
+}
+
+// TestAnalyticsService_GetPostStats tests collecting the hourly statistics of a post.
+func TestAnalyticsService_GetPostStats(t *testing.T) {
+	c := createAnalyticsServiceContext(t)
+
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	post := repository.Post{ID: 1, URLHandle: "hello", Author: repository.User{UserName: "testAuthor"}}
+
+	c.mockPostRepository.EXPECT().GetPost("hello").Return(&post, nil)
+	c.mockAnalyticsRepository.EXPECT().GetViewSeries([]uint{1}, types.AnalyticsPeriodHour, from, from.AddDate(0, 0, 1)).
+		Return([]repository.ViewRollup{{Start: from.Add(9 * time.Hour), Views: 2, Visitors: 1}}, nil)
+	c.mockAnalyticsRepository.EXPECT().GetTopReferrers([]uint{1}, from, from.AddDate(0, 0, 1), 10).Return([]repository.ReferrerRollup{}, nil)
+
+	stats, err := c.sut.GetPostStats("hello", types.AnalyticsQuery{From: "2024-05-01", To: "2024-05-01", Period: types.AnalyticsPeriodHour}, "testAuthor")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "hello", stats.Post, "statistics should belong to the post")
+	assert.Equal(t, []types.ViewCount{{Start: from.Add(9 * time.Hour), Views: 2, Visitors: 1}}, stats.Series, "series doesn't match the expected output")
+	assert.Nil(t, stats.TopPosts, "post statistics shouldn't contain top posts")
+}
+
+// TestAnalyticsService_GetPostStats_Forbidden tests requesting the statistics of the post of another user.
+func TestAnalyticsService_GetPostStats_Forbidden(t *testing.T) {
+	c := createAnalyticsServiceContext(t)
+
+	post := repository.Post{ID: 1, URLHandle: "hello", Author: repository.User{UserName: "testAuthor"}}
+
+	c.mockPostRepository.EXPECT().GetPost("hello").Return(&post, nil)
+
+	_, err := c.sut.GetPostStats("hello", types.AnalyticsQuery{}, "otherUser")
+
+	assert.Equal(t, errortypes.PostEditForbiddenError{Post: types.Post{URLHandle: "hello"}, UserName: "otherUser"}, err, "only contributors should see the statistics")
+}
+
+// TestAnalyticsService_RollupViews tests recomputing the complete days first and only the current day afterwards.
+func TestAnalyticsService_RollupViews(t *testing.T) {
+	c := createAnalyticsServiceContext(t)
+
+	var since []time.Time
+	c.mockAnalyticsRepository.EXPECT().RollupPageViews(gomock.Any()).Times(2).DoAndReturn(func(s time.Time) error {
+		since = append(since, s)
+		return nil
+	})
+	c.mockAnalyticsRepository.EXPECT().DeletePageViews(gomock.Any()).Return(int64(0), nil).Times(2)
+	c.mockAnalyticsRepository.EXPECT().DeleteVisitorSalts(time.Now().UTC().Truncate(24 * time.Hour)).Return(nil).Times(2)
+
+	assert.Nil(t, c.sut.RollupViews(), "should complete without error")
+	assert.Nil(t, c.sut.RollupViews(), "should complete without error")
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	assert.Equal(t, today.AddDate(0, 0, -1), since[0], "the previous day should be recomputed on the first run")
+	assert.Equal(t, today, since[1], "only the current day should be recomputed afterwards")
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/types"
+)
+
+// CollectionService interface. Defines the business logic of the editorial collections.
+type CollectionService interface {
+	AddCollection(newCollection *types.Collection) (types.Collection, error)
+	DeleteCollection(urlHandle string) error
+	GetCollection(urlHandle string) (types.Collection, error)
+	GetCollections() ([]types.Collection, error)
+	SetCollectionPosts(urlHandle string, postHandles []string, userName string) (types.Collection, error)
+	UpdateCollection(collection *types.Collection) (types.Collection, error)
+}
+
+// collectionService is the concrete implementation of the CollectionService interface.
+type collectionService struct {
+	cont container.Container
+}
+
+// CreateCollectionService instantiates the collectionService using the application container.
+func CreateCollectionService(cont container.Container) CollectionService {
+	return &collectionService{cont}
+}
+
+// AddCollection adds a new, empty collection to the blog.
+func (c collectionService) AddCollection(newCollection *types.Collection) (types.Collection, error) {
+	log := c.cont.GetLogger()
+	collectionRepository := c.cont.GetCollectionRepository()
+
+	log.Infof("adding new collection %v", newCollection)
+
+	collection, err := collectionRepository.AddCollection(newCollection)
+	return mapCollection(collection), err
+}
+
+// DeleteCollection removes the collection with the given URL handle. The posts of the collection are kept.
+func (c collectionService) DeleteCollection(urlHandle string) error {
+	log := c.cont.GetLogger()
+	collectionRepository := c.cont.GetCollectionRepository()
+
+	log.Infof("deleting collection %s", urlHandle)
+	return collectionRepository.DeleteCollection(urlHandle)
+}
+
+// GetCollection retrieves the collection with the given URL handle.
+func (c collectionService) GetCollection(urlHandle string) (types.Collection, error) {
+	collectionRepository := c.cont.GetCollectionRepository()
+	collection, err := collectionRepository.GetCollection(urlHandle)
+	return mapCollection(collection), err
+}
+
+// GetCollections retrieves every collection of the blog.
+func (c collectionService) GetCollections() ([]types.Collection, error) {
+	collectionRepository := c.cont.GetCollectionRepository()
+	collections, err := collectionRepository.GetCollections()
+	return mapCollections(collections), err
+}
+
+// SetCollectionPosts replaces the posts of a collection, as requested by an administrator.
+// The order of the URL handles defines the order of the posts.
+func (c collectionService) SetCollectionPosts(urlHandle string, postHandles []string, userName string) (types.Collection, error) {
+	log := c.cont.GetLogger()
+	postRepository := c.cont.GetPostRepository()
+	collectionRepository := c.cont.GetCollectionRepository()
+
+	collection, err := collectionRepository.GetCollection(urlHandle)
+	if err != nil {
+		log.Errorf("failed to get collection %s: %v", urlHandle, err)
+		return types.Collection{}, err
+	}
+
+	postIDs := make([]uint, 0, len(postHandles))
+	included := map[uint]bool{}
+	for _, handle := range postHandles {
+		post, err := postRepository.GetPost(handle)
+		if err != nil {
+			log.Errorf("failed to get post %s for collection %s: %v", handle, urlHandle, err)
+			return types.Collection{}, err
+		}
+		// A post listed twice keeps its first position
+		if !included[post.ID] {
+			included[post.ID] = true
+			postIDs = append(postIDs, post.ID)
+		}
+	}
+
+	log.Infof("user %s setting posts of collection %s to %v", userName, urlHandle, postHandles)
+
+	if err := collectionRepository.SetCollectionPosts(collection.ID, postIDs); err != nil {
+		return types.Collection{}, err
+	}
+
+	return c.GetCollection(urlHandle)
+}
+
+// UpdateCollection updates the title and summary of an existing collection.
+func (c collectionService) UpdateCollection(collection *types.Collection) (types.Collection, error) {
+	log := c.cont.GetLogger()
+	collectionRepository := c.cont.GetCollectionRepository()
+
+	log.Infof("updating collection %v", collection)
+
+	updatedCollection, err := collectionRepository.UpdateCollection(collection)
+	return mapCollection(updatedCollection), err
+}
+
+// mapCollection maps a Collection model to a collection data object.
+// Posts which aren't public are left out, since the collections are listed publicly.
+func mapCollection(c *repository.Collection) types.Collection {
+	if c == nil {
+		return types.Collection{}
+	}
+
+	posts := make([]types.Post, 0, len(c.Posts))
+	for _, member := range c.Posts {
+		if member.Post.Visibility == types.VisibilityPublic {
+			posts = append(posts, mapPostMetadata(&member.Post))
+		}
+	}
+
+	return types.Collection{
+		URLHandle: c.URLHandle,
+		Title:     c.Title,
+		Summary:   c.Summary,
+		Posts:     posts,
+	}
+}
+
+// mapCollections maps a slice of Collection models to a slice of collection data objects
+func mapCollections(c []repository.Collection) []types.Collection {
+	if c == nil {
+		return []types.Collection{}
+	}
+	collections := make([]types.Collection, 0, len(c))
+
+	for _, item := range c {
+		collections = append(collections, mapCollection(&item))
+	}
+
+	return collections
+}
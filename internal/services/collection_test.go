@@ -0,0 +1,148 @@
+package services_test
+
+import (
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/types"
+	"testing"
+)
+
+// collectionTestContext contains objects relevant for testing the CollectionService.
+type collectionTestContext struct {
+	mockCollectionRepository *mocks.MockCollectionRepository
+	mockPostRepository       *mocks.MockPostRepository
+	sut                      services.CollectionService
+}
+
+// createCollectionServiceContext creates the context for testing the CollectionService and reduces code duplication.
+func createCollectionServiceContext(t *testing.T) *collectionTestContext {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	mockCollectionRepository := mocks.NewMockCollectionRepository(mockCtrl)
+	mockPostRepository := mocks.NewMockPostRepository(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, mockCollectionRepository, nil, nil, nil, nil, mockPostRepository, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	sut := services.CreateCollectionService(cont)
+
+	return &collectionTestContext{mockCollectionRepository, mockPostRepository, sut}
+}
+
+// createCollectionModel creates a collection model with a public and a private post for testing purposes.
+func createCollectionModel() repository.Collection {
+	return repository.Collection{
+		ID:        1,
+		URLHandle: "start-here",
+		Title:     "Start here",
+		Posts: []repository.CollectionPost{
+			{CollectionID: 1, PostID: 2, Position: 1, Post: repository.Post{ID: 2, URLHandle: "intro", Visibility: types.VisibilityPublic, Author: repository.User{UserName: "testAuthor"}}},
+			{CollectionID: 1, PostID: 1, Position: 2, Post: repository.Post{ID: 1, URLHandle: "draft", Visibility: types.VisibilityPrivate}},
+		},
+	}
+}
+
+// TestCollectionService_AddCollection tests adding a new collection to the blog.
+func TestCollectionService_AddCollection(t *testing.T) {
+	t.Parallel()
+	c := createCollectionServiceContext(t)
+
+	input := types.Collection{URLHandle: "start-here", Title: "Start here"}
+	model := repository.Collection{ID: 1, URLHandle: input.URLHandle, Title: input.Title}
+	expected := types.Collection{URLHandle: input.URLHandle, Title: input.Title, Posts: []types.Post{}}
+
+	c.mockCollectionRepository.EXPECT().AddCollection(&input).Return(&model, nil)
+
+	collection, err := c.sut.AddCollection(&input)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, expected, collection, "added collection doesn't match the input")
+}
+
+// TestCollectionService_GetCollection tests getting a collection, leaving out the posts which aren't public.
+func TestCollectionService_GetCollection(t *testing.T) {
+	t.Parallel()
+	c := createCollectionServiceContext(t)
+
+	model := createCollectionModel()
+
+	c.mockCollectionRepository.EXPECT().GetCollection(model.URLHandle).Return(&model, nil)
+
+	collection, err := c.sut.GetCollection(model.URLHandle)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 1, len(collection.Posts), "private posts should be left out")
+	assert.Equal(t, "intro", collection.Posts[0].URLHandle, "posts should be ordered by position")
+	assert.Equal(t, "testAuthor", collection.Posts[0].Author, "post author should be mapped")
+}
+
+// TestCollectionService_GetCollections_Unexpected_Error tests handling an unexpected error while getting every collection.
+func TestCollectionService_GetCollections_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createCollectionServiceContext(t)
+
+	c.mockCollectionRepository.EXPECT().GetCollections().Return(nil, fmt.Errorf("error"))
+
+	collections, err := c.sut.GetCollections()
+
+	assert.NotNil(t, err, "expected error")
+	assert.Equal(t, []types.Collection{}, collections, "shouldn't return any collection")
+}
+
+// TestCollectionService_SetCollectionPosts tests replacing the posts of a collection, keeping the first position of repeated posts.
+func TestCollectionService_SetCollectionPosts(t *testing.T) {
+	t.Parallel()
+	c := createCollectionServiceContext(t)
+
+	model := createCollectionModel()
+	intro := model.Posts[0].Post
+	draft := model.Posts[1].Post
+
+	gomock.InOrder(
+		c.mockCollectionRepository.EXPECT().GetCollection(model.URLHandle).Return(&model, nil),
+		c.mockPostRepository.EXPECT().GetPost("intro").Return(&intro, nil),
+		c.mockPostRepository.EXPECT().GetPost("draft").Return(&draft, nil),
+		c.mockPostRepository.EXPECT().GetPost("intro").Return(&intro, nil),
+		c.mockCollectionRepository.EXPECT().SetCollectionPosts(model.ID, []uint{2, 1}).Return(nil),
+		c.mockCollectionRepository.EXPECT().GetCollection(model.URLHandle).Return(&model, nil),
+	)
+
+	collection, err := c.sut.SetCollectionPosts(model.URLHandle, []string{"intro", "draft", "intro"}, "testUser")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, model.URLHandle, collection.URLHandle, "collection doesn't match the expected one")
+}
+
+// TestCollectionService_SetCollectionPosts_Post_Not_Found tests adding a non-existent post to a collection.
+func TestCollectionService_SetCollectionPosts_Post_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createCollectionServiceContext(t)
+
+	model := createCollectionModel()
+	expectedError := errortypes.PostNotFoundError{Post: types.Post{URLHandle: "missing"}}
+
+	c.mockCollectionRepository.EXPECT().GetCollection(model.URLHandle).Return(&model, nil)
+	c.mockPostRepository.EXPECT().GetPost("missing").Return(nil, expectedError)
+
+	_, err := c.sut.SetCollectionPosts(model.URLHandle, []string{"missing"}, "testAuthor")
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
+
+// TestCollectionService_DeleteCollection_Not_Found tests removing a non-existent collection.
+func TestCollectionService_DeleteCollection_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createCollectionServiceContext(t)
+
+	expectedError := errortypes.CollectionNotFoundError{Collection: types.Collection{URLHandle: "start-here"}}
+	c.mockCollectionRepository.EXPECT().DeleteCollection("start-here").Return(expectedError)
+
+	err := c.sut.DeleteCollection("start-here")
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
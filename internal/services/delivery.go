@@ -0,0 +1,122 @@
+package services
+
+import (
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/types"
+	"go.uber.org/zap"
+	"os"
+	"strconv"
+	"time"
+)
+
+// maxRetryDelay limits the exponential backoff between the attempts of a delivery.
+const maxRetryDelay = 6 * time.Hour
+
+// deliveryConfig contains the settings of a delivery queue.
+type deliveryConfig struct {
+	MaxAttempts  int
+	RetryDelay   time.Duration
+	PollInterval time.Duration
+	Timeout      time.Duration
+}
+
+// loadDeliveryConfig reads the settings of a delivery queue from the environment variables with the given prefix,
+// e.g. WEBHOOK_MAX_ATTEMPTS. Missing or invalid settings keep their default value.
+func loadDeliveryConfig(prefix string, config deliveryConfig) deliveryConfig {
+	if attempts, err := strconv.Atoi(os.Getenv(prefix + "_MAX_ATTEMPTS")); err == nil && attempts > 0 {
+		config.MaxAttempts = attempts
+	}
+	if delay, err := time.ParseDuration(os.Getenv(prefix + "_RETRY_DELAY")); err == nil && delay > 0 {
+		config.RetryDelay = delay
+	}
+	if interval, err := time.ParseDuration(os.Getenv(prefix + "_POLL_INTERVAL")); err == nil && interval > 0 {
+		config.PollInterval = interval
+	}
+	if timeout, err := time.ParseDuration(os.Getenv(prefix + "_TIMEOUT")); err == nil && timeout > 0 {
+		config.Timeout = timeout
+	}
+
+	return config
+}
+
+// recordAttempt records the outcome of a delivery attempt. Failed deliveries are scheduled for another attempt with
+// exponential backoff, unless the maximum number of attempts is reached or the failure is permanent.
+func (c deliveryConfig) recordAttempt(delivery *repository.DeliveryState, status int, err error, permanent bool) {
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+
+	if err == nil {
+		now := time.Now()
+		delivery.Status = types.DeliverySucceeded
+		delivery.Error = ""
+		delivery.DeliveredAt = &now
+		return
+	}
+
+	delivery.Error = err.Error()
+	if permanent || delivery.Attempts >= c.MaxAttempts {
+		delivery.Status = types.DeliveryFailed
+		return
+	}
+
+	delivery.NextAttemptAt = time.Now().Add(retryDelay(c.RetryDelay, delivery.Attempts))
+}
+
+// claimLease returns the time a batch of deliveries is claimed for. It outlasts the attempts of the whole batch, each of
+// which may wait for a couple of requests to time out, so other instances sharing the database don't attempt them too.
+func (c deliveryConfig) claimLease(batchSize int) time.Duration {
+	return 2 * time.Duration(batchSize) * c.Timeout
+}
+
+// retryDelay calculates the delay before the next attempt of a delivery, doubling it after every failed attempt.
+func retryDelay(base time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// deliverDue claims a batch of the due deliveries of a queue, attempts them and stores the outcome of the attempts.
+// The number of attempted deliveries is returned.
+func deliverDue[T any](log *zap.SugaredLogger, queue string, config deliveryConfig, batchSize int, claim func(now time.Time, limit int, lease time.Duration) ([]T, error), attempt func(delivery *T), update func(delivery *T) error) (int, error) {
+	deliveries, err := claim(time.Now(), batchSize, config.claimLease(batchSize))
+	if err != nil {
+		log.Errorf("failed to claim due %s deliveries: %v", queue, err)
+		return 0, err
+	}
+
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		attempt(delivery)
+		if err := update(delivery); err != nil {
+			log.Errorf("failed to update %s delivery: %v", queue, err)
+			return i, err
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+// runQueue runs the given batches periodically until the stop channel is closed.
+// Batches are run again while they're full, so the queue doesn't fall behind.
+func runQueue(stop <-chan struct{}, interval time.Duration, batchSize int, batches ...func() (int, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			for _, batch := range batches {
+				for {
+					n, err := batch()
+					if err != nil || n < batchSize {
+						break
+					}
+				}
+			}
+		}
+	}
+}
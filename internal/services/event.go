@@ -0,0 +1,23 @@
+package services
+
+import (
+	"github.com/wlchs/blog/internal/container"
+)
+
+// dispatchEvents delivers the events stored in the outbox by a committed change to the subscribers of the event bus.
+// The change can't be rolled back anymore, so failures are only logged, the events are dispatched again later.
+func dispatchEvents(cont container.Container) {
+	if _, err := cont.GetEventBus().Dispatch(); err != nil {
+		cont.GetLogger().Errorf("failed to dispatch events: %v", err)
+	}
+}
+
+// CreateEventSubscribers instantiates the services handling the post and user events, which subscribe to the event bus.
+// Commands changing posts outside the blog engine use it, since the events are marked as dispatched once they
+// were delivered to the subscribers, even if the subscribers of the blog engine didn't receive them.
+func CreateEventSubscribers(cont container.Container, postService PostService) {
+	CreateWebhookService(cont)
+	CreateNewsletterService(cont)
+	CreateFederationService(cont, postService)
+	CreateWebmentionService(cont)
+}
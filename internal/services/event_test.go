@@ -0,0 +1,82 @@
+package services_test
+
+import (
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/events"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/types"
+	"testing"
+	"time"
+)
+
+// TestCreateEventSubscribers_Import tests announcing the posts imported by the command-line tools in the newsletter,
+// to the followers of their author and to the pages they link. The events of the imported posts are dispatched through the outbox to the
+// subscribers created for the command. The site URL is read from the environment, so the test can't run in parallel.
+func TestCreateEventSubscribers_Import(t *testing.T) {
+	t.Setenv("SITE_URL", "https://blog.example.com")
+
+	mockCtrl := gomock.NewController(t)
+	mockFederationRepository := mocks.NewMockFederationRepository(mockCtrl)
+	mockFieldRepository := mocks.NewMockFieldRepository(mockCtrl)
+	mockNewsletterRepository := mocks.NewMockNewsletterRepository(mockCtrl)
+	mockOutboxRepository := mocks.NewMockOutboxRepository(mockCtrl)
+	mockPostRepository := mocks.NewMockPostRepository(mockCtrl)
+	mockUserRepository := mocks.NewMockUserRepository(mockCtrl)
+	mockWebhookRepository := mocks.NewMockWebhookRepository(mockCtrl)
+	mockWebmentionRepository := mocks.NewMockWebmentionRepository(mockCtrl)
+	log := logger.CreateLogger()
+	cont := container.CreateContainer(log, nil, nil, mockFederationRepository, mockFieldRepository, nil, mockNewsletterRepository, mockPostRepository, nil, nil, nil, mockUserRepository, mockWebhookRepository, mockWebmentionRepository, nil, nil, events.CreateBus(log, mockOutboxRepository), nil)
+
+	postService := services.CreatePostService(cont)
+	services.CreateEventSubscribers(cont, postService)
+	sut := services.CreateMarkdownService(cont, postService)
+
+	dir := t.TempDir()
+	writeMarkdownFile(t, dir, "hello.md", "---\ntitle: Hello\nsummary: Summary\nauthor: testAuthor\n---\nSee [the docs](https://example.com/docs).")
+
+	author := repository.User{ID: 1, UserName: "testAuthor"}
+	var outbox []repository.OutboxEvent
+
+	mockPostRepository.EXPECT().GetPost("hello").Return(nil, errortypes.PostNotFoundError{})
+	mockUserRepository.EXPECT().GetUser("testAuthor").Return(&author, nil)
+	mockFieldRepository.EXPECT().GetFields().Return(nil, nil)
+	mockPostRepository.EXPECT().AddPost(gomock.Any(), author.ID, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(post *types.Post, authorID uint, contributors []repository.Contributor, events repository.PostEvents) (*repository.Post, error) {
+			model := &repository.Post{ID: 1, URLHandle: post.URLHandle, Title: post.Title, Summary: post.Summary, Body: post.Body, Visibility: post.Visibility, AuthorID: authorID, CreatedAt: time.Now()}
+			for _, event := range events(model) {
+				payload, _ := json.Marshal(event)
+				outbox = append(outbox, repository.OutboxEvent{ID: 1, Name: event.EventName(), Payload: string(payload), Status: repository.OutboxPending})
+			}
+			return model, nil
+		})
+	mockOutboxRepository.EXPECT().ClaimPendingEvents(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(time.Time, int, time.Duration) ([]repository.OutboxEvent, error) {
+		return outbox, nil
+	})
+	mockWebhookRepository.EXPECT().GetWebhooks().Return([]repository.Webhook{}, nil)
+	mockWebhookRepository.EXPECT().AddDeliveries(gomock.Any()).Return(nil)
+	mockFederationRepository.EXPECT().GetLastActivityType("https://blog.example.com/posts/hello").Return("", nil)
+	mockFederationRepository.EXPECT().GetFollowers("testAuthor").Return([]repository.Follower{{Inbox: "https://example.com/inbox"}}, nil)
+	mockFederationRepository.EXPECT().AddDeliveries(gomock.Any()).DoAndReturn(func(deliveries []repository.FederationDelivery) error {
+		assert.Equal(t, 1, len(deliveries), "activity should be delivered to the follower")
+		return nil
+	})
+	mockWebmentionRepository.EXPECT().GetDeliveryTargets("https://blog.example.com/posts/hello").Return([]string{}, nil)
+	mockWebmentionRepository.EXPECT().QueueDeliveries("https://blog.example.com/posts/hello", []string{"https://example.com/docs"}, gomock.Any()).Return(nil)
+	mockNewsletterRepository.EXPECT().AddNewsletterPost(&repository.NewsletterPost{URLHandle: "hello", Title: "Hello", Summary: "Summary"}).Return(nil)
+	mockOutboxRepository.EXPECT().UpdateEvent(gomock.Any()).DoAndReturn(func(event *repository.OutboxEvent) error {
+		assert.Equal(t, repository.OutboxDispatched, event.Status, "event should be dispatched to every subscriber")
+		return nil
+	})
+
+	report, err := sut.ImportPosts(dir)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, []string{"hello"}, report.Created, "post should be created")
+}
@@ -0,0 +1,661 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/httpsig"
+	"github.com/wlchs/blog/internal/markdown"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/site"
+	"github.com/wlchs/blog/internal/types"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// federationBatchSize is the number of due deliveries sent at once.
+const federationBatchSize = 50
+
+// maxActorSize limits the size of the fetched remote actor documents.
+const maxActorSize = 1 << 20
+
+// signatureSkew is the accepted difference between the date of a signed request and the current time.
+const signatureSkew = time.Hour
+
+// FederationService interface. Defines the ActivityPub actors of the authors and the delivery of their activities.
+type FederationService interface {
+	DeliverActivities() (int, error)
+	GetActor(userName string) (types.Actor, error)
+	GetFollowers(userName string) (types.OrderedCollection, error)
+	GetOutbox(userName string) (types.OrderedCollection, error)
+	GetWebFinger(resource string) (types.WebFinger, error)
+	ReceiveActivity(userName string, req *http.Request, body []byte) error
+	RunDeliveries(stop <-chan struct{})
+}
+
+// federationService is the concrete implementation of the FederationService interface.
+type federationService struct {
+	cont        container.Container
+	postService PostService
+	client      *http.Client
+	site        site.Config
+	config      deliveryConfig
+}
+
+// CreateFederationService instantiates the federationService using the application container and the post service.
+// The actors are identified by the site URL, federation is disabled if it isn't set.
+// The service subscribes to the post events of the event bus, which are delivered to the followers of the author.
+func CreateFederationService(cont container.Container, postService PostService) FederationService {
+	config := loadFederationConfig()
+	f := &federationService{cont, postService, createRemoteClient(config.Timeout), site.LoadConfig(), config}
+
+	eventBus := cont.GetEventBus()
+	for _, event := range []string{types.EventPostCreated, types.EventPostUpdated, types.EventPostDeleted} {
+		eventBus.Subscribe(event, f.queueActivity)
+	}
+
+	return f
+}
+
+// loadFederationConfig reads the settings of the delivery queue from the FEDERATION_* environment variables.
+func loadFederationConfig() deliveryConfig {
+	return loadDeliveryConfig("FEDERATION", deliveryConfig{
+		MaxAttempts:  8,
+		RetryDelay:   time.Minute,
+		PollInterval: 10 * time.Second,
+		Timeout:      10 * time.Second,
+	})
+}
+
+// DeliverActivities sends the deliveries whose next attempt is due and returns their number.
+// Failed deliveries are retried with exponential backoff until the maximum number of attempts is reached.
+func (f federationService) DeliverActivities() (int, error) {
+	federationRepository := f.cont.GetFederationRepository()
+
+	keys := map[string]*rsa.PrivateKey{}
+	attempt := func(delivery *repository.FederationDelivery) { f.attemptDelivery(delivery, keys) }
+	return deliverDue(f.cont.GetLogger(), "federation", f.config, federationBatchSize, federationRepository.ClaimDueDeliveries, attempt, federationRepository.UpdateDelivery)
+}
+
+// GetActor retrieves the ActivityPub actor of an author. The key pair of the actor is generated on first use.
+func (f federationService) GetActor(userName string) (types.Actor, error) {
+	if err := f.checkAuthor(userName); err != nil {
+		return types.Actor{}, err
+	}
+
+	key, err := f.actorKey(userName)
+	if err != nil {
+		return types.Actor{}, err
+	}
+
+	publicKey, err := httpsig.EncodePublicKey(&key.PublicKey)
+	if err != nil {
+		return types.Actor{}, err
+	}
+
+	id := f.actorID(userName)
+	return types.Actor{
+		Context:           []string{types.ActivityStreamsContext, types.SecurityContext},
+		ID:                id,
+		Type:              "Person",
+		PreferredUsername: userName,
+		Name:              userName,
+		URL:               f.site.BaseURL + site.AuthorPath(userName),
+		Inbox:             id + "/inbox",
+		Outbox:            id + "/outbox",
+		Followers:         id + "/followers",
+		PublicKey: types.ActorPublicKey{
+			ID:           f.keyID(userName),
+			Owner:        id,
+			PublicKeyPem: publicKey,
+		},
+	}, nil
+}
+
+// GetFollowers retrieves the collection of the followers of an author.
+// Only the number of followers is published, the followers themselves aren't listed.
+func (f federationService) GetFollowers(userName string) (types.OrderedCollection, error) {
+	federationRepository := f.cont.GetFederationRepository()
+
+	if err := f.checkAuthor(userName); err != nil {
+		return types.OrderedCollection{}, err
+	}
+
+	count, err := federationRepository.CountFollowers(userName)
+	if err != nil {
+		return types.OrderedCollection{}, err
+	}
+
+	return types.OrderedCollection{
+		Context:    types.ActivityStreamsContext,
+		ID:         f.actorID(userName) + "/followers",
+		Type:       "OrderedCollection",
+		TotalItems: count,
+	}, nil
+}
+
+// GetOutbox retrieves the outbox of an author containing the Create activities of the latest public posts.
+func (f federationService) GetOutbox(userName string) (types.OrderedCollection, error) {
+	if err := f.checkAuthor(userName); err != nil {
+		return types.OrderedCollection{}, err
+	}
+
+	posts, err := f.postService.GetPosts(types.PostFilter{})
+	if err != nil {
+		return types.OrderedCollection{}, err
+	}
+
+	total := 0
+	items := make([]interface{}, 0)
+	for _, post := range posts {
+		if post.Author != userName {
+			continue
+		}
+		total++
+		if len(items) < feedSize {
+			items = append(items, f.postActivity("Create", post))
+		}
+	}
+
+	return types.OrderedCollection{
+		Context:      types.ActivityStreamsContext,
+		ID:           f.actorID(userName) + "/outbox",
+		Type:         "OrderedCollection",
+		TotalItems:   total,
+		OrderedItems: items,
+	}, nil
+}
+
+// GetWebFinger resolves the actor of an author from an "acct:" URI or the actor ID.
+func (f federationService) GetWebFinger(resource string) (types.WebFinger, error) {
+	if err := f.checkEnabled(); err != nil {
+		return types.WebFinger{}, err
+	}
+
+	host := f.host()
+	userName := ""
+	switch {
+	case strings.HasPrefix(resource, "acct:"):
+		name, domain, _ := strings.Cut(strings.TrimPrefix(resource, "acct:"), "@")
+		if strings.EqualFold(domain, host) {
+			userName = name
+		}
+	case strings.HasPrefix(resource, f.actorID("")):
+		userName, _ = url.PathUnescape(strings.TrimPrefix(resource, f.actorID("")))
+	}
+
+	if userName == "" || strings.Contains(userName, "/") {
+		return types.WebFinger{}, errortypes.UserNotFoundError{User: types.User{UserName: userName}}
+	}
+	if err := f.checkAuthor(userName); err != nil {
+		return types.WebFinger{}, err
+	}
+
+	profile := f.site.BaseURL + site.AuthorPath(userName)
+	return types.WebFinger{
+		Subject: fmt.Sprintf("acct:%s@%s", userName, host),
+		Aliases: []string{f.actorID(userName), profile},
+		Links: []types.WebFingerLink{
+			{Rel: "self", Type: types.ActivityContentType, Href: f.actorID(userName)},
+			{Rel: "http://webfinger.net/rel/profile-page", Type: "text/html", Href: profile},
+		},
+	}, nil
+}
+
+// ReceiveActivity handles an activity posted to the inbox of an author. The request must be signed by the actor of the
+// activity. Follow requests are accepted automatically, undoing them removes the follower, other activities are ignored.
+func (f federationService) ReceiveActivity(userName string, req *http.Request, body []byte) error {
+	log := f.cont.GetLogger()
+
+	if err := f.checkAuthor(userName); err != nil {
+		return err
+	}
+
+	var activity types.IncomingActivity
+	if err := json.Unmarshal(body, &activity); err != nil || activity.Type == "" || activity.Actor == "" {
+		return errortypes.InvalidActivityError{Reason: "malformed activity"}
+	}
+
+	remote, err := f.verifySignature(req, body, activity.Actor)
+	if err != nil {
+		log.Debugf("rejected %s activity of %s: %v", activity.Type, activity.Actor, err)
+		return err
+	}
+
+	switch activity.Type {
+	case "Follow":
+		return f.acceptFollow(userName, activity, remote, body)
+	case "Undo":
+		return f.undo(userName, activity)
+	default:
+		log.Debugf("ignoring %s activity of %s", activity.Type, activity.Actor)
+		return nil
+	}
+}
+
+// RunDeliveries sends the due deliveries periodically until the stop channel is closed.
+func (f federationService) RunDeliveries(stop <-chan struct{}) {
+	runQueue(stop, f.config.PollInterval, federationBatchSize, f.DeliverActivities)
+}
+
+// acceptFollow adds the sender of a Follow activity to the followers of the author and queues the Accept activity.
+func (f federationService) acceptFollow(userName string, activity types.IncomingActivity, remote types.Actor, body []byte) error {
+	log := f.cont.GetLogger()
+	federationRepository := f.cont.GetFederationRepository()
+
+	if objectID(activity.Object) != f.actorID(userName) {
+		return errortypes.InvalidActivityError{Reason: "the follow request doesn't target the actor of the inbox"}
+	}
+
+	log.Infof("%s follows %s", remote.ID, userName)
+	if err := federationRepository.AddFollower(&repository.Follower{UserName: userName, ActorID: remote.ID, Inbox: remote.Inbox}); err != nil {
+		return err
+	}
+
+	accept := types.Activity{
+		Context: types.ActivityStreamsContext,
+		ID:      fmt.Sprintf("%s#accepts/%d", f.actorID(userName), time.Now().UnixNano()),
+		Type:    "Accept",
+		Actor:   f.actorID(userName),
+		Object:  json.RawMessage(body),
+	}
+	return f.queueDeliveries(userName, activity.ID, accept, []string{remote.Inbox})
+}
+
+// undo removes the sender of an undone Follow activity from the followers of the author.
+func (f federationService) undo(userName string, activity types.IncomingActivity) error {
+	log := f.cont.GetLogger()
+	federationRepository := f.cont.GetFederationRepository()
+
+	var undone types.IncomingActivity
+	if err := json.Unmarshal(activity.Object, &undone); err != nil || undone.Type != "Follow" {
+		log.Debugf("ignoring undo of %s", activity.Object)
+		return nil
+	}
+	if undone.Actor != activity.Actor {
+		return errortypes.InvalidActivityError{Reason: "only the sender of an activity can undo it"}
+	}
+
+	log.Infof("%s unfollows %s", activity.Actor, userName)
+	return federationRepository.DeleteFollower(userName, activity.Actor)
+}
+
+// verifySignature verifies the signature of a received activity with the key of its actor. The actor is only fetched
+// once the signature header parses and names a key owned by the actor of the activity.
+func (f federationService) verifySignature(req *http.Request, body []byte, actorID string) (types.Actor, error) {
+	signature, err := httpsig.Parse(req)
+	if err != nil {
+		return types.Actor{}, errortypes.InvalidSignatureError{Reason: err.Error()}
+	}
+
+	owner, err := keyOwner(signature.KeyID)
+	if err != nil {
+		return types.Actor{}, errortypes.InvalidSignatureError{Reason: err.Error()}
+	}
+	if owner != actorID {
+		return types.Actor{}, errortypes.InvalidSignatureError{Reason: "the request isn't signed by the actor of the activity"}
+	}
+
+	remote, err := f.fetchActor(owner)
+	if err != nil {
+		return types.Actor{}, errortypes.InvalidSignatureError{Reason: fmt.Sprintf("failed to fetch actor %s: %v", owner, err)}
+	}
+	if remote.PublicKey.ID != signature.KeyID || remote.PublicKey.Owner != remote.ID {
+		return types.Actor{}, errortypes.InvalidSignatureError{Reason: "the key doesn't belong to the actor"}
+	}
+
+	key, err := httpsig.ParsePublicKey(remote.PublicKey.PublicKeyPem)
+	if err != nil {
+		return types.Actor{}, errortypes.InvalidSignatureError{Reason: err.Error()}
+	}
+	if err := httpsig.Verify(req, signature, key, body, time.Now(), signatureSkew); err != nil {
+		return types.Actor{}, errortypes.InvalidSignatureError{Reason: err.Error()}
+	}
+
+	return remote, nil
+}
+
+// fetchActor retrieves the document of a remote actor. The ID of the document must match the requested one.
+func (f federationService) fetchActor(id string) (types.Actor, error) {
+	if !isAbsoluteURL(id) {
+		return types.Actor{}, fmt.Errorf("the actor ID must be an absolute HTTP(S) URL")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, id, nil)
+	if err != nil {
+		return types.Actor{}, err
+	}
+	req.Header.Set("Accept", types.ActivityContentType)
+
+	res, err := f.client.Do(req)
+	if err != nil {
+		return types.Actor{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return types.Actor{}, fmt.Errorf("unexpected response status %d", res.StatusCode)
+	}
+
+	var actor types.Actor
+	if err := json.NewDecoder(io.LimitReader(res.Body, maxActorSize)).Decode(&actor); err != nil {
+		return types.Actor{}, err
+	}
+	if actor.ID != id || !isAbsoluteURL(actor.Inbox) {
+		return types.Actor{}, fmt.Errorf("invalid actor document")
+	}
+
+	return actor, nil
+}
+
+// queueActivity queues the delivery of the activity describing a post event to the followers of the author.
+// Public posts are created when they weren't federated yet, and updated otherwise. Posts which were federated but
+// aren't public anymore are deleted. Events which can't be queued are dispatched again by the event bus.
+func (f federationService) queueActivity(event types.DomainEvent) error {
+	log := f.cont.GetLogger()
+	federationRepository := f.cont.GetFederationRepository()
+
+	if f.site.BaseURL == "" {
+		return nil
+	}
+
+	var post types.Post
+	deleted := false
+	switch e := event.(type) {
+	case types.PostCreatedEvent:
+		post = e.Post
+	case types.PostUpdatedEvent:
+		post = e.Post
+	case types.PostDeletedEvent:
+		post, deleted = e.Post, true
+	default:
+		return nil
+	}
+
+	postURL := f.site.BaseURL + site.PostPath(post.URLHandle)
+	lastActivity, err := federationRepository.GetLastActivityType(postURL)
+	if err != nil {
+		log.Errorf("failed to get the last activity of %s: %v", postURL, err)
+		return err
+	}
+
+	federated := lastActivity == "Create" || lastActivity == "Update"
+	public := !deleted && post.Visibility == types.VisibilityPublic
+
+	var activity types.Activity
+	switch {
+	case public && federated:
+		activity = f.postActivity("Update", post)
+	case public:
+		activity = f.postActivity("Create", post)
+	case federated:
+		activity = f.deleteActivity(post)
+	default:
+		return nil
+	}
+
+	userName := post.Author
+	followers, err := federationRepository.GetFollowers(userName)
+	if err != nil {
+		log.Errorf("failed to get followers of %s: %v", userName, err)
+		return err
+	}
+
+	inboxes := make([]string, 0, len(followers))
+	for _, follower := range followers {
+		if !containsString(inboxes, follower.Inbox) {
+			inboxes = append(inboxes, follower.Inbox)
+		}
+	}
+
+	return f.queueDeliveries(userName, postURL, activity, inboxes)
+}
+
+// queueDeliveries queues the delivery of an activity of an author about an object to the given inboxes.
+func (f federationService) queueDeliveries(userName string, object string, activity types.Activity, inboxes []string) error {
+	log := f.cont.GetLogger()
+	federationRepository := f.cont.GetFederationRepository()
+
+	payload, err := json.Marshal(activity)
+	if err != nil {
+		log.Errorf("failed to encode activity %s: %v", activity.ID, err)
+		return err
+	}
+
+	now := time.Now()
+	deliveries := make([]repository.FederationDelivery, 0, len(inboxes))
+	for _, inbox := range inboxes {
+		deliveries = append(deliveries, repository.FederationDelivery{
+			UserName:      userName,
+			Inbox:         inbox,
+			ObjectID:      object,
+			ActivityType:  activity.Type,
+			Activity:      string(payload),
+			DeliveryState: repository.DeliveryState{Status: types.DeliveryPending, NextAttemptAt: now},
+		})
+	}
+
+	if err := federationRepository.AddDeliveries(deliveries); err != nil {
+		log.Errorf("failed to queue deliveries of activity %s: %v", activity.ID, err)
+		return err
+	}
+	return nil
+}
+
+// attemptDelivery sends a delivery and records the outcome of the attempt.
+// Failed deliveries are scheduled for another attempt unless the maximum number of attempts is reached.
+func (f federationService) attemptDelivery(delivery *repository.FederationDelivery, keys map[string]*rsa.PrivateKey) {
+	log := f.cont.GetLogger()
+
+	status, err := f.send(delivery, keys)
+	// Gone inboxes won't accept the activity later either
+	f.config.recordAttempt(&delivery.DeliveryState, status, err, status == http.StatusGone)
+
+	switch delivery.Status {
+	case types.DeliverySucceeded:
+		log.Debugf("delivered activity of %s to %s", delivery.UserName, delivery.Inbox)
+	case types.DeliveryFailed:
+		log.Warnf("giving up delivery %d to %s after %d attempts: %v", delivery.ID, delivery.Inbox, delivery.Attempts, err)
+	default:
+		log.Debugf("delivery %d to %s failed, retrying at %v: %v", delivery.ID, delivery.Inbox, delivery.NextAttemptAt, err)
+	}
+}
+
+// send posts the activity of a delivery to the remote inbox, signed with the key of the author,
+// and returns the response status. Responses with a status other than 2xx are considered failures.
+func (f federationService) send(delivery *repository.FederationDelivery, keys map[string]*rsa.PrivateKey) (int, error) {
+	key, found := keys[delivery.UserName]
+	if !found {
+		var err error
+		if key, err = f.actorKey(delivery.UserName); err != nil {
+			return 0, err
+		}
+		keys[delivery.UserName] = key
+	}
+
+	body := []byte(delivery.Activity)
+	req, err := http.NewRequest(http.MethodPost, delivery.Inbox, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", types.ActivityContentType)
+	if err := httpsig.Sign(req, f.keyID(delivery.UserName), key, body, time.Now()); err != nil {
+		return 0, err
+	}
+
+	res, err := f.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected response status %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+// actorKey retrieves the private key of an author, generating a new key pair if the author doesn't have one yet.
+func (f federationService) actorKey(userName string) (*rsa.PrivateKey, error) {
+	log := f.cont.GetLogger()
+	federationRepository := f.cont.GetFederationRepository()
+
+	stored, err := federationRepository.GetActorKey(userName)
+	switch err.(type) {
+	case nil:
+		return httpsig.ParsePrivateKey(stored.PrivateKey)
+	case errortypes.ActorKeyNotFoundError:
+	default:
+		return nil, err
+	}
+
+	log.Infof("generating key of actor %s", userName)
+	key, err := httpsig.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := httpsig.EncodePublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	err = federationRepository.AddActorKey(&repository.ActorKey{UserName: userName, PublicKey: publicKey, PrivateKey: httpsig.EncodePrivateKey(key)})
+	if _, ok := err.(errortypes.DuplicateElementError); ok {
+		// The key was generated by another request in the meantime
+		return f.actorKey(userName)
+	}
+	return key, err
+}
+
+// postActivity creates an activity of the author of a post with the post as Article.
+func (f federationService) postActivity(activityType string, post types.Post) types.Activity {
+	postURL := f.site.BaseURL + site.PostPath(post.URLHandle)
+	actor := f.actorID(post.Author)
+	audience := []string{types.PublicAudience}
+	followers := []string{actor + "/followers"}
+
+	article := types.Article{
+		ID:           postURL,
+		Type:         "Article",
+		AttributedTo: actor,
+		Name:         post.Title,
+		Summary:      post.Summary,
+		Content:      markdown.Render(post.Body),
+		URL:          postURL,
+		Published:    post.CreationTime.UTC(),
+		To:           audience,
+		Cc:           followers,
+	}
+	if post.UpdateTime.After(post.CreationTime) {
+		updated := post.UpdateTime.UTC()
+		article.Updated = &updated
+	}
+	for _, tag := range post.Tags {
+		article.Tag = append(article.Tag, types.Hashtag{Type: "Hashtag", Name: "#" + strings.ReplaceAll(tag, " ", "")})
+	}
+
+	published := article.Published
+	if article.Updated != nil {
+		published = *article.Updated
+	}
+
+	return types.Activity{
+		Context:   types.ActivityStreamsContext,
+		ID:        fmt.Sprintf("%s#%s-%d", postURL, strings.ToLower(activityType), published.Unix()),
+		Type:      activityType,
+		Actor:     actor,
+		Object:    article,
+		To:        audience,
+		Cc:        followers,
+		Published: &published,
+	}
+}
+
+// deleteActivity creates the activity deleting a post from the servers of the followers of its author.
+func (f federationService) deleteActivity(post types.Post) types.Activity {
+	postURL := f.site.BaseURL + site.PostPath(post.URLHandle)
+
+	return types.Activity{
+		Context: types.ActivityStreamsContext,
+		ID:      fmt.Sprintf("%s#delete-%d", postURL, time.Now().Unix()),
+		Type:    "Delete",
+		Actor:   f.actorID(post.Author),
+		Object:  types.Tombstone{ID: postURL, Type: "Tombstone"},
+		To:      []string{types.PublicAudience},
+	}
+}
+
+// checkEnabled checks that the site URL identifying the actors is set.
+func (f federationService) checkEnabled() error {
+	if f.site.BaseURL == "" {
+		return errortypes.FederationDisabledError{}
+	}
+	return nil
+}
+
+// checkAuthor checks that federation is enabled and the author exists and isn't disabled.
+func (f federationService) checkAuthor(userName string) error {
+	if err := f.checkEnabled(); err != nil {
+		return err
+	}
+
+	user, err := f.cont.GetUserRepository().GetUser(userName)
+	if err != nil {
+		return err
+	}
+	if user.Disabled {
+		return errortypes.UserNotFoundError{User: types.User{UserName: userName}}
+	}
+	return nil
+}
+
+// actorID returns the ID of the actor of an author.
+func (f federationService) actorID(userName string) string {
+	return f.site.BaseURL + "/actors/" + url.PathEscape(userName)
+}
+
+// keyID returns the ID of the public key of an author.
+func (f federationService) keyID(userName string) string {
+	return f.actorID(userName) + "#main-key"
+}
+
+// host returns the host of the site URL, which is the domain of the "acct:" URIs of the authors.
+func (f federationService) host() string {
+	u, err := url.Parse(f.site.BaseURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// keyOwner returns the actor owning a signing key, the ID of the key without its fragment.
+func keyOwner(keyID string) (string, error) {
+	u, err := url.Parse(keyID)
+	if err != nil || !isAbsoluteURL(keyID) {
+		return "", fmt.Errorf("the key ID must be an absolute HTTP(S) URL")
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u.String(), nil
+}
+
+// objectID returns the ID of the object of an activity, which is either embedded or referenced by its ID.
+func objectID(object json.RawMessage) string {
+	var id string
+	if err := json.Unmarshal(object, &id); err == nil {
+		return id
+	}
+
+	var embedded struct {
+		ID string `json:"id"`
+	}
+	_ = json.Unmarshal(object, &embedded)
+	return embedded.ID
+}
@@ -0,0 +1,426 @@
+package services_test
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/events"
+	"github.com/wlchs/blog/internal/httpsig"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/types"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// localActorKey and remoteActorKey are shared by the tests, generating RSA keys is slow.
+var (
+	localActorKey, _  = httpsig.GenerateKey()
+	remoteActorKey, _ = httpsig.GenerateKey()
+)
+
+// federationTestContext contains objects relevant for testing the FederationService.
+type federationTestContext struct {
+	mockFederationRepository *mocks.MockFederationRepository
+	mockUserRepository       *mocks.MockUserRepository
+	mockPostService          *mocks.MockPostService
+	remote                   *remoteServer
+	handlers                 map[string]events.Handler
+	sut                      services.FederationService
+}
+
+// remoteServer is a local stand-in of a remote ActivityPub server hosting the actor alice.
+// The activities posted to the inbox of alice are recorded.
+type remoteServer struct {
+	*httptest.Server
+	received chan *http.Request
+	bodies   chan []byte
+	fetched  int32
+}
+
+// createRemoteServer starts the stand-in of the remote ActivityPub server.
+func createRemoteServer(t *testing.T) *remoteServer {
+	t.Helper()
+
+	remote := &remoteServer{received: make(chan *http.Request, 1), bodies: make(chan []byte, 1)}
+	remote.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/users/alice":
+			atomic.AddInt32(&remote.fetched, 1)
+			w.Header().Set("Content-Type", types.ActivityContentType)
+			_ = json.NewEncoder(w).Encode(remote.actor())
+		case r.Method == http.MethodPost && r.URL.Path == "/users/alice/inbox":
+			body, _ := io.ReadAll(r.Body)
+			remote.received <- r
+			remote.bodies <- body
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(remote.Close)
+
+	return remote
+}
+
+// actorID returns the ID of the remote actor.
+func (r *remoteServer) actorID() string {
+	return r.URL + "/users/alice"
+}
+
+// actor returns the document of the remote actor.
+func (r *remoteServer) actor() types.Actor {
+	publicKey, _ := httpsig.EncodePublicKey(&remoteActorKey.PublicKey)
+	return types.Actor{
+		ID:                r.actorID(),
+		Type:              "Person",
+		PreferredUsername: "alice",
+		Inbox:             r.actorID() + "/inbox",
+		PublicKey:         types.ActorPublicKey{ID: r.actorID() + "#main-key", Owner: r.actorID(), PublicKeyPem: publicKey},
+	}
+}
+
+// signedActivity creates a request posting an activity to the inbox of testAuthor, signed with the given key.
+func (r *remoteServer) signedActivity(t *testing.T, activity interface{}, key *rsa.PrivateKey) (*http.Request, []byte) {
+	t.Helper()
+
+	body, _ := json.Marshal(activity)
+	req := httptest.NewRequest(http.MethodPost, "https://blog.example.com/actors/testAuthor/inbox", bytes.NewReader(body))
+	if err := httpsig.Sign(req, r.actorID()+"#main-key", key, body, time.Now()); err != nil {
+		t.Fatalf("failed to sign activity: %v", err)
+	}
+	return req, body
+}
+
+// createFederationServiceContext creates the context for testing the FederationService and reduces code duplication.
+// The site URL identifying the actors is set in the environment, so the tests can't run in parallel. The remote server
+// listens on the loopback address, so private addresses are allowed.
+func createFederationServiceContext(t *testing.T, siteURL string) *federationTestContext {
+	t.Helper()
+	t.Setenv("ALLOW_PRIVATE_ADDRESSES", "true")
+	return createPublicFederationServiceContext(t, siteURL)
+}
+
+// createPublicFederationServiceContext creates the context for testing the FederationService connecting to publicly
+// routable addresses only.
+func createPublicFederationServiceContext(t *testing.T, siteURL string) *federationTestContext {
+	t.Helper()
+	t.Setenv("SITE_URL", siteURL)
+
+	mockCtrl := gomock.NewController(t)
+	mockFederationRepository := mocks.NewMockFederationRepository(mockCtrl)
+	mockUserRepository := mocks.NewMockUserRepository(mockCtrl)
+	mockPostService := mocks.NewMockPostService(mockCtrl)
+	mockEventBus := mocks.NewMockBus(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, mockFederationRepository, nil, nil, nil, nil, nil, nil, nil, mockUserRepository, nil, nil, nil, nil, mockEventBus, nil)
+
+	handlers := map[string]events.Handler{}
+	mockEventBus.EXPECT().Subscribe(gomock.Any(), gomock.Any()).Times(3).Do(func(event string, handler events.Handler) {
+		handlers[event] = handler
+	})
+	sut := services.CreateFederationService(cont, mockPostService)
+
+	return &federationTestContext{mockFederationRepository, mockUserRepository, mockPostService, createRemoteServer(t), handlers, sut}
+}
+
+// expectAuthor expects the author testAuthor to be looked up.
+func (c *federationTestContext) expectAuthor() {
+	c.mockUserRepository.EXPECT().GetUser("testAuthor").Return(&repository.User{ID: 1, UserName: "testAuthor"}, nil).AnyTimes()
+}
+
+// storedActorKey returns the stored key pair of testAuthor.
+func storedActorKey() *repository.ActorKey {
+	publicKey, _ := httpsig.EncodePublicKey(&localActorKey.PublicKey)
+	return &repository.ActorKey{UserName: "testAuthor", PublicKey: publicKey, PrivateKey: httpsig.EncodePrivateKey(localActorKey)}
+}
+
+// TestFederationService_GetWebFinger tests resolving the actor of an author from an "acct:" URI.
+func TestFederationService_GetWebFinger(t *testing.T) {
+	c := createFederationServiceContext(t, "https://blog.example.com")
+	c.expectAuthor()
+
+	webFinger, err := c.sut.GetWebFinger("acct:testAuthor@blog.example.com")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "acct:testAuthor@blog.example.com", webFinger.Subject, "incorrect subject")
+	assert.Contains(t, webFinger.Links, types.WebFingerLink{Rel: "self", Type: types.ActivityContentType, Href: "https://blog.example.com/actors/testAuthor"}, "actor should be linked")
+}
+
+// TestFederationService_GetWebFinger_Other_Domain tests resolving an account of another domain.
+func TestFederationService_GetWebFinger_Other_Domain(t *testing.T) {
+	c := createFederationServiceContext(t, "https://blog.example.com")
+
+	_, err := c.sut.GetWebFinger("acct:testAuthor@example.org")
+
+	assert.IsType(t, errortypes.UserNotFoundError{}, err, "incorrect error type")
+}
+
+// TestFederationService_GetActor tests generating the key pair of an actor on first use.
+func TestFederationService_GetActor(t *testing.T) {
+	c := createFederationServiceContext(t, "https://blog.example.com")
+	c.expectAuthor()
+
+	var stored *repository.ActorKey
+	c.mockFederationRepository.EXPECT().GetActorKey("testAuthor").Return(nil, errortypes.ActorKeyNotFoundError{UserName: "testAuthor"})
+	c.mockFederationRepository.EXPECT().AddActorKey(gomock.Any()).DoAndReturn(func(key *repository.ActorKey) error {
+		stored = key
+		return nil
+	})
+
+	actor, err := c.sut.GetActor("testAuthor")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "https://blog.example.com/actors/testAuthor", actor.ID, "incorrect actor ID")
+	assert.Equal(t, "https://blog.example.com/actors/testAuthor/inbox", actor.Inbox, "incorrect inbox")
+	assert.Equal(t, actor.ID+"#main-key", actor.PublicKey.ID, "incorrect key ID")
+	assert.Equal(t, stored.PublicKey, actor.PublicKey.PublicKeyPem, "published key should match the stored one")
+}
+
+// TestFederationService_GetActor_Disabled tests retrieving an actor while the site URL isn't set.
+func TestFederationService_GetActor_Disabled(t *testing.T) {
+	c := createFederationServiceContext(t, "")
+
+	_, err := c.sut.GetActor("testAuthor")
+
+	assert.Equal(t, errortypes.FederationDisabledError{}, err, "incorrect error type")
+}
+
+// TestFederationService_GetOutbox tests listing the public posts of an author as Create activities.
+func TestFederationService_GetOutbox(t *testing.T) {
+	c := createFederationServiceContext(t, "https://blog.example.com")
+	c.expectAuthor()
+
+	posts := []types.Post{
+		{URLHandle: "first", Title: "First", Author: "testAuthor", Visibility: types.VisibilityPublic},
+		{URLHandle: "other", Title: "Other", Author: "otherAuthor", Visibility: types.VisibilityPublic},
+	}
+	c.mockPostService.EXPECT().GetPosts(types.PostFilter{}).Return(posts, nil)
+
+	outbox, err := c.sut.GetOutbox("testAuthor")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 1, outbox.TotalItems, "only the posts of the author should be listed")
+	assert.Equal(t, "Create", outbox.OrderedItems[0].(types.Activity).Type, "posts should be listed as Create activities")
+}
+
+// TestFederationService_ReceiveActivity_Follow tests accepting a follow request signed by the remote actor.
+func TestFederationService_ReceiveActivity_Follow(t *testing.T) {
+	c := createFederationServiceContext(t, "https://blog.example.com")
+	c.expectAuthor()
+
+	follow := types.Activity{ID: c.remote.actorID() + "#follow", Type: "Follow", Actor: c.remote.actorID(), Object: "https://blog.example.com/actors/testAuthor"}
+	req, body := c.remote.signedActivity(t, follow, remoteActorKey)
+
+	c.mockFederationRepository.EXPECT().AddFollower(&repository.Follower{UserName: "testAuthor", ActorID: c.remote.actorID(), Inbox: c.remote.actorID() + "/inbox"}).Return(nil)
+	c.mockFederationRepository.EXPECT().AddDeliveries(gomock.Any()).DoAndReturn(func(deliveries []repository.FederationDelivery) error {
+		assert.Equal(t, 1, len(deliveries), "the follow request should be accepted")
+		assert.Equal(t, c.remote.actorID()+"/inbox", deliveries[0].Inbox, "the acceptance should be sent to the follower")
+
+		var accept types.IncomingActivity
+		_ = json.Unmarshal([]byte(deliveries[0].Activity), &accept)
+		assert.Equal(t, "Accept", accept.Type, "incorrect activity type")
+		return nil
+	})
+
+	err := c.sut.ReceiveActivity("testAuthor", req, body)
+
+	assert.Nil(t, err, "should complete without error")
+}
+
+// TestFederationService_ReceiveActivity_Invalid_Signature tests rejecting an activity which isn't signed by its actor.
+func TestFederationService_ReceiveActivity_Invalid_Signature(t *testing.T) {
+	c := createFederationServiceContext(t, "https://blog.example.com")
+	c.expectAuthor()
+
+	follow := types.Activity{Type: "Follow", Actor: c.remote.actorID(), Object: "https://blog.example.com/actors/testAuthor"}
+	req, body := c.remote.signedActivity(t, follow, localActorKey)
+
+	err := c.sut.ReceiveActivity("testAuthor", req, body)
+
+	assert.IsType(t, errortypes.InvalidSignatureError{}, err, "incorrect error type")
+}
+
+// TestFederationService_ReceiveActivity_Unsigned tests rejecting an unsigned activity without fetching its actor.
+func TestFederationService_ReceiveActivity_Unsigned(t *testing.T) {
+	c := createFederationServiceContext(t, "https://blog.example.com")
+	c.expectAuthor()
+
+	follow := types.Activity{Type: "Follow", Actor: c.remote.actorID(), Object: "https://blog.example.com/actors/testAuthor"}
+	body, _ := json.Marshal(follow)
+	req := httptest.NewRequest(http.MethodPost, "https://blog.example.com/actors/testAuthor/inbox", bytes.NewReader(body))
+
+	err := c.sut.ReceiveActivity("testAuthor", req, body)
+
+	assert.IsType(t, errortypes.InvalidSignatureError{}, err, "incorrect error type")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&c.remote.fetched), "the actor shouldn't be fetched")
+}
+
+// TestFederationService_ReceiveActivity_Other_Actor tests rejecting an activity signed with the key of another actor
+// without fetching either of them.
+func TestFederationService_ReceiveActivity_Other_Actor(t *testing.T) {
+	c := createFederationServiceContext(t, "https://blog.example.com")
+	c.expectAuthor()
+
+	follow := types.Activity{Type: "Follow", Actor: c.remote.URL + "/users/bob", Object: "https://blog.example.com/actors/testAuthor"}
+	req, body := c.remote.signedActivity(t, follow, remoteActorKey)
+
+	err := c.sut.ReceiveActivity("testAuthor", req, body)
+
+	assert.IsType(t, errortypes.InvalidSignatureError{}, err, "incorrect error type")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&c.remote.fetched), "the actor shouldn't be fetched")
+}
+
+// TestFederationService_ReceiveActivity_Private_Address tests refusing to fetch actors served from private addresses.
+func TestFederationService_ReceiveActivity_Private_Address(t *testing.T) {
+	c := createPublicFederationServiceContext(t, "https://blog.example.com")
+	c.expectAuthor()
+
+	follow := types.Activity{Type: "Follow", Actor: c.remote.actorID(), Object: "https://blog.example.com/actors/testAuthor"}
+	req, body := c.remote.signedActivity(t, follow, remoteActorKey)
+
+	err := c.sut.ReceiveActivity("testAuthor", req, body)
+
+	assert.IsType(t, errortypes.InvalidSignatureError{}, err, "incorrect error type")
+	assert.Contains(t, err.Error(), "the address isn't publicly routable", "the actor shouldn't be fetched")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&c.remote.fetched), "the actor shouldn't be fetched")
+}
+
+// TestFederationService_ReceiveActivity_Undo_Follow tests removing a follower who undid the follow request.
+func TestFederationService_ReceiveActivity_Undo_Follow(t *testing.T) {
+	c := createFederationServiceContext(t, "https://blog.example.com")
+	c.expectAuthor()
+
+	follow := types.Activity{Type: "Follow", Actor: c.remote.actorID(), Object: "https://blog.example.com/actors/testAuthor"}
+	undo := types.Activity{Type: "Undo", Actor: c.remote.actorID(), Object: follow}
+	req, body := c.remote.signedActivity(t, undo, remoteActorKey)
+
+	c.mockFederationRepository.EXPECT().DeleteFollower("testAuthor", c.remote.actorID()).Return(nil)
+
+	err := c.sut.ReceiveActivity("testAuthor", req, body)
+
+	assert.Nil(t, err, "should complete without error")
+}
+
+// TestFederationService_QueueActivity tests queueing the Create activity of a new public post for the followers.
+func TestFederationService_QueueActivity(t *testing.T) {
+	c := createFederationServiceContext(t, "https://blog.example.com")
+
+	post := types.Post{URLHandle: "testUrlHandle", Title: "Test", Body: "<p>Body</p>", Author: "testAuthor", Visibility: types.VisibilityPublic, Tags: []string{"go"}}
+	followers := []repository.Follower{
+		{UserName: "testAuthor", ActorID: "https://a.example.com/users/a", Inbox: "https://a.example.com/inbox"},
+		{UserName: "testAuthor", ActorID: "https://a.example.com/users/b", Inbox: "https://a.example.com/inbox"},
+	}
+
+	c.mockFederationRepository.EXPECT().GetLastActivityType("https://blog.example.com/posts/testUrlHandle").Return("", nil)
+	c.mockFederationRepository.EXPECT().GetFollowers("testAuthor").Return(followers, nil)
+	c.mockFederationRepository.EXPECT().AddDeliveries(gomock.Any()).DoAndReturn(func(deliveries []repository.FederationDelivery) error {
+		assert.Equal(t, 1, len(deliveries), "shared inboxes should only receive the activity once")
+		assert.Equal(t, "https://blog.example.com/posts/testUrlHandle", deliveries[0].ObjectID, "the post should be recorded")
+		assert.Equal(t, "Create", deliveries[0].ActivityType, "the activity type should be recorded")
+
+		var activity struct {
+			Type   string        `json:"type"`
+			Object types.Article `json:"object"`
+		}
+		_ = json.Unmarshal([]byte(deliveries[0].Activity), &activity)
+		assert.Equal(t, "Create", activity.Type, "incorrect activity type")
+		assert.Equal(t, "https://blog.example.com/posts/testUrlHandle", activity.Object.ID, "incorrect object ID")
+		assert.Equal(t, post.Body, activity.Object.Content, "incorrect content")
+		assert.Equal(t, []types.Hashtag{{Type: "Hashtag", Name: "#go"}}, activity.Object.Tag, "tags should be federated as hashtags")
+		return nil
+	})
+
+	err := c.handlers[types.EventPostCreated](types.PostCreatedEvent{Post: post})
+
+	assert.Nil(t, err, "should complete without error")
+}
+
+// TestFederationService_QueueActivity_Private tests deleting a federated post which isn't public anymore.
+func TestFederationService_QueueActivity_Private(t *testing.T) {
+	c := createFederationServiceContext(t, "https://blog.example.com")
+
+	post := types.Post{URLHandle: "testUrlHandle", Author: "testAuthor", Visibility: types.VisibilityPrivate}
+
+	c.mockFederationRepository.EXPECT().GetLastActivityType("https://blog.example.com/posts/testUrlHandle").Return("Update", nil)
+	c.mockFederationRepository.EXPECT().GetFollowers("testAuthor").Return([]repository.Follower{{Inbox: "https://a.example.com/inbox"}}, nil)
+	c.mockFederationRepository.EXPECT().AddDeliveries(gomock.Any()).DoAndReturn(func(deliveries []repository.FederationDelivery) error {
+		var activity types.IncomingActivity
+		_ = json.Unmarshal([]byte(deliveries[0].Activity), &activity)
+		assert.Equal(t, "Delete", activity.Type, "incorrect activity type")
+		return nil
+	})
+
+	err := c.handlers[types.EventPostUpdated](types.PostUpdatedEvent{Post: post})
+
+	assert.Nil(t, err, "should complete without error")
+}
+
+// TestFederationService_QueueActivity_Never_Federated tests ignoring posts which aren't public and were never federated.
+func TestFederationService_QueueActivity_Never_Federated(t *testing.T) {
+	c := createFederationServiceContext(t, "https://blog.example.com")
+
+	post := types.Post{URLHandle: "testUrlHandle", Author: "testAuthor", Visibility: types.VisibilityPrivate}
+
+	c.mockFederationRepository.EXPECT().GetLastActivityType("https://blog.example.com/posts/testUrlHandle").Return("", nil).Times(3)
+
+	assert.Nil(t, c.handlers[types.EventPostCreated](types.PostCreatedEvent{Post: post}), "private posts shouldn't be federated")
+	assert.Nil(t, c.handlers[types.EventPostUpdated](types.PostUpdatedEvent{Post: post}), "private posts shouldn't be deleted")
+	assert.Nil(t, c.handlers[types.EventPostDeleted](types.PostDeletedEvent{Post: post}), "private posts shouldn't be deleted")
+}
+
+// TestFederationService_QueueActivity_Published tests creating a post which is made public after it was deleted from
+// the servers of the followers.
+func TestFederationService_QueueActivity_Published(t *testing.T) {
+	c := createFederationServiceContext(t, "https://blog.example.com")
+
+	post := types.Post{URLHandle: "testUrlHandle", Author: "testAuthor", Visibility: types.VisibilityPublic}
+
+	c.mockFederationRepository.EXPECT().GetLastActivityType("https://blog.example.com/posts/testUrlHandle").Return("Delete", nil)
+	c.mockFederationRepository.EXPECT().GetFollowers("testAuthor").Return([]repository.Follower{{Inbox: "https://a.example.com/inbox"}}, nil)
+	c.mockFederationRepository.EXPECT().AddDeliveries(gomock.Any()).DoAndReturn(func(deliveries []repository.FederationDelivery) error {
+		assert.Equal(t, "Create", deliveries[0].ActivityType, "incorrect activity type")
+		return nil
+	})
+
+	err := c.handlers[types.EventPostUpdated](types.PostUpdatedEvent{Post: post})
+
+	assert.Nil(t, err, "should complete without error")
+}
+
+// TestFederationService_DeliverActivities tests delivering a signed activity to the inbox of the remote server.
+func TestFederationService_DeliverActivities(t *testing.T) {
+	c := createFederationServiceContext(t, "https://blog.example.com")
+
+	activity := `{"type":"Create","actor":"https://blog.example.com/actors/testAuthor"}`
+	delivery := repository.FederationDelivery{ID: 1, UserName: "testAuthor", Inbox: c.remote.actorID() + "/inbox", Activity: activity, DeliveryState: repository.DeliveryState{Status: types.DeliveryPending}}
+
+	c.mockFederationRepository.EXPECT().ClaimDueDeliveries(gomock.Any(), gomock.Any(), gomock.Any()).Return([]repository.FederationDelivery{delivery}, nil)
+	c.mockFederationRepository.EXPECT().GetActorKey("testAuthor").Return(storedActorKey(), nil)
+	c.mockFederationRepository.EXPECT().UpdateDelivery(gomock.Any()).DoAndReturn(func(d *repository.FederationDelivery) error {
+		assert.Equal(t, types.DeliverySucceeded, d.Status, "delivery should succeed")
+		assert.Equal(t, 202, d.ResponseStatus, "response status should be recorded")
+		return nil
+	})
+
+	n, err := c.sut.DeliverActivities()
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 1, n, "incorrect number of deliveries")
+
+	req, body := <-c.remote.received, <-c.remote.bodies
+	signature, err := httpsig.Parse(req)
+	assert.Nil(t, err, "request should be signed")
+	assert.Equal(t, "https://blog.example.com/actors/testAuthor#main-key", signature.KeyID, "incorrect key ID")
+	assert.Nil(t, httpsig.Verify(req, signature, &localActorKey.PublicKey, body, time.Now(), time.Minute), "signature should be valid")
+	assert.Equal(t, activity, string(body), "incorrect activity")
+}
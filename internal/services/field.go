@@ -0,0 +1,198 @@
+package services
+
+import (
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/types"
+	"net/url"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// fieldNamePattern restricts custom field names, so they can be safely used in query parameters and JSON paths.
+var fieldNamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,63}$`)
+
+// FieldService interface. Defines custom field-related business logic.
+type FieldService interface {
+	AddField(field *types.FieldDefinition) (types.FieldDefinition, error)
+	DeleteField(name string) error
+	GetFields() ([]types.FieldDefinition, error)
+}
+
+// fieldService is the concrete implementation of the FieldService interface.
+type fieldService struct {
+	cont container.Container
+}
+
+// CreateFieldService instantiates the fieldService using the application container.
+func CreateFieldService(cont container.Container) FieldService {
+	return &fieldService{cont}
+}
+
+// AddField validates and adds a new custom field definition.
+func (f fieldService) AddField(field *types.FieldDefinition) (types.FieldDefinition, error) {
+	log := f.cont.GetLogger()
+	fieldRepository := f.cont.GetFieldRepository()
+
+	if err := validateFieldDefinition(field); err != nil {
+		log.Debugf("invalid custom field definition %v: %v", field, err)
+		return types.FieldDefinition{}, err
+	}
+
+	log.Infof("adding new custom field %v", field)
+
+	newField, err := fieldRepository.AddField(field)
+	return mapField(newField), err
+}
+
+// DeleteField removes the custom field definition with the given name together with its values.
+func (f fieldService) DeleteField(name string) error {
+	log := f.cont.GetLogger()
+	fieldRepository := f.cont.GetFieldRepository()
+
+	log.Infof("deleting custom field %s", name)
+	return fieldRepository.DeleteField(name)
+}
+
+// GetFields retrieves every custom field definition.
+func (f fieldService) GetFields() ([]types.FieldDefinition, error) {
+	fieldRepository := f.cont.GetFieldRepository()
+	fields, err := fieldRepository.GetFields()
+	return mapFields(fields), err
+}
+
+// validateFieldDefinition checks the name, the type and the options of a custom field definition.
+// Options can only be provided for enum fields, which require at least one of them.
+func validateFieldDefinition(field *types.FieldDefinition) error {
+	if !fieldNamePattern.MatchString(field.Name) {
+		return errortypes.InvalidFieldDefinitionError{Field: *field, Reason: "the name must start with a letter and contain only letters, digits and underscores"}
+	}
+
+	if !types.IsValidFieldType(field.Type) {
+		return errortypes.InvalidFieldDefinitionError{Field: *field, Reason: "unsupported type \"" + field.Type + "\""}
+	}
+
+	if field.Type == types.FieldTypeEnum && len(field.Options) == 0 {
+		return errortypes.InvalidFieldDefinitionError{Field: *field, Reason: "enum fields require at least one option"}
+	}
+
+	if field.Type != types.FieldTypeEnum && len(field.Options) > 0 {
+		return errortypes.InvalidFieldDefinitionError{Field: *field, Reason: "only enum fields can have options"}
+	}
+
+	return nil
+}
+
+// validateCustomFields checks the custom field values of a post against the field definitions.
+// Every value must belong to a defined field and match its type, every required field must be set.
+func validateCustomFields(values map[string]interface{}, definitions []repository.FieldDefinition) error {
+	fields := make(map[string]repository.FieldDefinition, len(definitions))
+	for _, definition := range definitions {
+		fields[definition.Name] = definition
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		definition, found := fields[name]
+		if !found {
+			return errortypes.InvalidCustomFieldError{Name: name, Reason: "unknown custom field"}
+		}
+		if err := validateCustomFieldValue(values[name], definition); err != nil {
+			return err
+		}
+	}
+
+	for _, definition := range definitions {
+		if _, found := values[definition.Name]; definition.Required && !found {
+			return errortypes.MissingCustomFieldError{Name: definition.Name}
+		}
+	}
+
+	return nil
+}
+
+// validateCustomFieldValue checks whether a single value matches the type of its field definition.
+func validateCustomFieldValue(value interface{}, definition repository.FieldDefinition) error {
+	invalid := errortypes.InvalidCustomFieldError{Name: definition.Name, Reason: "expected a value of type " + definition.Type}
+
+	if definition.Type == types.FieldTypeNumber {
+		switch value.(type) {
+		case float64, float32, int, int64:
+			return nil
+		default:
+			return invalid
+		}
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return invalid
+	}
+
+	switch definition.Type {
+	case types.FieldTypeDate:
+		if _, err := time.Parse(time.DateOnly, s); err != nil {
+			return errortypes.InvalidCustomFieldError{Name: definition.Name, Reason: "expected a date in YYYY-MM-DD format"}
+		}
+
+	case types.FieldTypeURL:
+		if u, err := url.Parse(s); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errortypes.InvalidCustomFieldError{Name: definition.Name, Reason: "expected an absolute HTTP(S) URL"}
+		}
+
+	case types.FieldTypeEnum:
+		for _, option := range definition.Options {
+			if option == s {
+				return nil
+			}
+		}
+		return errortypes.InvalidCustomFieldError{Name: definition.Name, Reason: "expected one of the options of the field"}
+	}
+
+	return nil
+}
+
+// containsField checks whether a custom field with the given name is defined.
+func containsField(definitions []repository.FieldDefinition, name string) bool {
+	for _, definition := range definitions {
+		if definition.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// mapField maps a FieldDefinition model to a field definition data object
+func mapField(f *repository.FieldDefinition) types.FieldDefinition {
+	if f == nil {
+		return types.FieldDefinition{}
+	}
+	return types.FieldDefinition{
+		Name:        f.Name,
+		Type:        f.Type,
+		Required:    f.Required,
+		Options:     f.Options,
+		Description: f.Description,
+	}
+}
+
+// mapFields maps a slice of FieldDefinition models to a slice of field definition data objects
+func mapFields(f []repository.FieldDefinition) []types.FieldDefinition {
+	if f == nil {
+		return []types.FieldDefinition{}
+	}
+	fields := make([]types.FieldDefinition, 0, len(f))
+
+	for _, field := range f {
+		fields = append(fields, mapField(&field))
+	}
+
+	return fields
+}
@@ -0,0 +1,109 @@
+package services_test
+
+import (
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/types"
+	"testing"
+)
+
+// fieldTestContext contains objects relevant for testing the FieldService.
+type fieldTestContext struct {
+	mockFieldRepository *mocks.MockFieldRepository
+	sut                 services.FieldService
+}
+
+// createFieldServiceContext creates the context for testing the FieldService and reduces code duplication.
+func createFieldServiceContext(t *testing.T) *fieldTestContext {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	mockFieldRepository := mocks.NewMockFieldRepository(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, mockFieldRepository, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	sut := services.CreateFieldService(cont)
+
+	return &fieldTestContext{mockFieldRepository, sut}
+}
+
+// TestFieldService_AddField tests adding a new custom field definition.
+func TestFieldService_AddField(t *testing.T) {
+	t.Parallel()
+	c := createFieldServiceContext(t)
+
+	input := types.FieldDefinition{Name: "category", Type: types.FieldTypeEnum, Required: true, Options: []string{"news", "howto"}}
+	model := repository.FieldDefinition{ID: 1, Name: input.Name, Type: input.Type, Required: input.Required, Options: input.Options}
+
+	c.mockFieldRepository.EXPECT().AddField(&input).Return(&model, nil)
+
+	f, err := c.sut.AddField(&input)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, input, f, "added field doesn't match the input")
+}
+
+// TestFieldService_AddField_Invalid tests adding invalid custom field definitions.
+func TestFieldService_AddField_Invalid(t *testing.T) {
+	t.Parallel()
+	c := createFieldServiceContext(t)
+
+	inputs := []types.FieldDefinition{
+		{Name: "1st", Type: types.FieldTypeString},
+		{Name: "cover image", Type: types.FieldTypeURL},
+		{Name: "cover", Type: "image"},
+		{Name: "category", Type: types.FieldTypeEnum},
+		{Name: "caption", Type: types.FieldTypeString, Options: []string{"a"}},
+	}
+
+	for _, input := range inputs {
+		_, err := c.sut.AddField(&input)
+		assert.IsType(t, errortypes.InvalidFieldDefinitionError{}, err, "field %v should be rejected", input)
+	}
+}
+
+// TestFieldService_DeleteField tests removing a custom field definition.
+func TestFieldService_DeleteField(t *testing.T) {
+	t.Parallel()
+	c := createFieldServiceContext(t)
+
+	c.mockFieldRepository.EXPECT().DeleteField("category").Return(nil)
+
+	err := c.sut.DeleteField("category")
+
+	assert.Nil(t, err, "should complete without error")
+}
+
+// TestFieldService_GetFields tests getting every custom field definition.
+func TestFieldService_GetFields(t *testing.T) {
+	t.Parallel()
+	c := createFieldServiceContext(t)
+
+	models := []repository.FieldDefinition{{ID: 1, Name: "cover", Type: types.FieldTypeURL}}
+	expected := []types.FieldDefinition{{Name: "cover", Type: types.FieldTypeURL}}
+
+	c.mockFieldRepository.EXPECT().GetFields().Return(models, nil)
+
+	f, err := c.sut.GetFields()
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, expected, f, "fields don't match the expected output")
+}
+
+// TestFieldService_GetFields_Unexpected_Error tests handling an unexpected error while getting every custom field definition.
+func TestFieldService_GetFields_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createFieldServiceContext(t)
+
+	c.mockFieldRepository.EXPECT().GetFields().Return(nil, fmt.Errorf("error"))
+
+	f, err := c.sut.GetFields()
+
+	assert.NotNil(t, err, "expected error")
+	assert.Equal(t, []types.FieldDefinition{}, f, "shouldn't return any fields")
+}
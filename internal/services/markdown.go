@@ -0,0 +1,218 @@
+package services
+
+import (
+	"bytes"
+	"github.com/wlchs/blog/internal/auth"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/frontmatter"
+	"github.com/wlchs/blog/internal/i18n"
+	"github.com/wlchs/blog/internal/storage"
+	"github.com/wlchs/blog/internal/types"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// markdownExtension is the file extension of the imported and exported posts.
+const markdownExtension = ".md"
+
+// MarkdownService interface. Defines the import and export of posts as Markdown files with YAML front matter.
+type MarkdownService interface {
+	ExportPosts(target storage.Storage) (int, error)
+	ImportPosts(dir string) (types.ImportReport, error)
+}
+
+// markdownService is the concrete implementation of the MarkdownService interface.
+type markdownService struct {
+	cont        container.Container
+	postService PostService
+}
+
+// CreateMarkdownService instantiates the markdownService using the application container and the post service.
+func CreateMarkdownService(cont container.Container, postService PostService) MarkdownService {
+	return &markdownService{cont, postService}
+}
+
+// ExportPosts writes every post to the target storage as a Markdown file named after its URL handle.
+// Existing files are overwritten. Translations and contributors aren't part of the exported files.
+// Password-protected posts are exported with the hash of their password, so they can be imported again.
+// Since the files contain private drafts and password hashes, the target must not be publicly readable.
+// The number of exported posts is returned.
+func (m markdownService) ExportPosts(target storage.Storage) (int, error) {
+	log := m.cont.GetLogger()
+
+	posts, err := m.postService.GetAllPosts()
+	if err != nil {
+		return 0, err
+	}
+
+	exported := 0
+	for _, post := range posts {
+		if post.URLHandle == "" || post.URLHandle != filepath.Base(post.URLHandle) {
+			log.Warnf("skipping post with URL handle %q, it can't be used as a file name", post.URLHandle)
+			continue
+		}
+
+		metadata := mapPostToMetadata(post)
+
+		document, err := frontmatter.Format(metadata, post.Body)
+		if err != nil {
+			return exported, err
+		}
+
+		if err := target.Put(post.URLHandle+markdownExtension, bytes.NewReader(document), int64(len(document)), "text/markdown"); err != nil {
+			log.Errorf("failed to export post %s: %v", post.URLHandle, err)
+			return exported, err
+		}
+		exported++
+	}
+
+	log.Infof("exported %d posts", exported)
+	return exported, nil
+}
+
+// ImportPosts imports every Markdown file of the directory and its subdirectories.
+// Posts are identified by the slug of the front matter, falling back to the file name.
+// New posts are added, existing ones are updated by the author of the file, so importing the same files again doesn't create duplicates.
+// The author defaults to the main user. Files which can't be imported are skipped and listed in the report.
+func (m markdownService) ImportPosts(dir string) (types.ImportReport, error) {
+	log := m.cont.GetLogger()
+	report := types.ImportReport{Created: []string{}, Updated: []string{}, Skipped: []types.ImportIssue{}}
+
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && strings.EqualFold(filepath.Ext(path), markdownExtension) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Errorf("failed to list Markdown files of %s: %v", dir, err)
+		return report, err
+	}
+
+	for _, file := range files {
+		name, _ := filepath.Rel(dir, file)
+
+		post, err := readMarkdownPost(file)
+		if err != nil {
+			log.Warnf("skipping %s: %v", name, err)
+			report.Skipped = append(report.Skipped, types.ImportIssue{Item: name, Reason: err.Error()})
+			continue
+		}
+
+		created, err := m.importPost(post)
+		switch {
+		case err != nil:
+			log.Warnf("skipping %s: %v", name, err)
+			report.Skipped = append(report.Skipped, types.ImportIssue{Item: name, Reason: err.Error()})
+		case created:
+			report.Created = append(report.Created, post.URLHandle)
+		default:
+			report.Updated = append(report.Updated, post.URLHandle)
+		}
+	}
+
+	log.Infof("imported Markdown files of %s: %d created, %d updated, %d skipped", dir, len(report.Created), len(report.Updated), len(report.Skipped))
+	return report, nil
+}
+
+// importPost adds the post if there is no post with the same URL handle yet, otherwise the existing post is updated.
+// The existing post is looked up without access checks, since the author of the file may not be allowed to read it.
+// The first return value reports whether the post was created.
+func (m markdownService) importPost(post types.Post) (bool, error) {
+	postRepository := m.cont.GetPostRepository()
+
+	switch _, err := postRepository.GetPost(post.URLHandle); err.(type) {
+	case nil:
+		_, err = m.postService.UpdatePost(&post, post.Author)
+		return false, err
+	case errortypes.PostNotFoundError:
+		_, err = m.postService.AddPost(&post)
+		return true, err
+	default:
+		return false, err
+	}
+}
+
+// readMarkdownPost reads a Markdown file and maps it to a post data object.
+func readMarkdownPost(file string) (types.Post, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return types.Post{}, err
+	}
+
+	metadata, body, err := frontmatter.Parse(data)
+	if err != nil {
+		return types.Post{}, err
+	}
+
+	post := types.Post{
+		URLHandle:    metadata.Slug,
+		Title:        metadata.Title,
+		Author:       metadata.Author,
+		Summary:      metadata.Summary,
+		Body:         body,
+		CreationTime: metadata.Date,
+		Language:     metadata.Language,
+		Visibility:   metadata.Visibility,
+		PasswordHash: metadata.PasswordHash,
+		Tags:         metadata.Tags,
+		CoverImage:   metadata.CoverImage,
+	}
+
+	if post.PasswordHash != "" && !auth.IsHash(post.PasswordHash) {
+		return types.Post{}, errortypes.InvalidPasswordHashError{}
+	}
+
+	if post.URLHandle == "" {
+		post.URLHandle = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	}
+	if post.Author == "" {
+		post.Author = os.Getenv("DEFAULT_USER")
+	}
+	// Tags removed from the file must be removed from the post as well
+	if post.Tags == nil {
+		post.Tags = []string{}
+	}
+	// The export omits the public visibility, so files without one make the post public again
+	if post.Visibility == "" {
+		post.Visibility = types.VisibilityPublic
+	}
+	// Files without a cover image, e.g. earlier exports, keep the current one
+
+	return post, nil
+}
+
+// mapPostToMetadata maps a post data object to the front matter of its exported file.
+// The default language and visibility are omitted.
+func mapPostToMetadata(post types.Post) frontmatter.Metadata {
+	metadata := frontmatter.Metadata{
+		Title:      post.Title,
+		Slug:       post.URLHandle,
+		Summary:    post.Summary,
+		Date:       post.CreationTime.UTC(),
+		Author:     post.Author,
+		Tags:       post.Tags,
+		CoverImage: post.CoverImage,
+		Language:   post.Language,
+		Visibility: post.Visibility,
+	}
+
+	if post.Visibility == types.VisibilityPassword {
+		metadata.PasswordHash = post.PasswordHash
+	}
+	if metadata.Language == i18n.DefaultLanguage {
+		metadata.Language = ""
+	}
+	if metadata.Visibility == types.VisibilityPublic {
+		metadata.Visibility = ""
+	}
+
+	return metadata
+}
@@ -0,0 +1,258 @@
+package services_test
+
+import (
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/auth"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/storage"
+	"github.com/wlchs/blog/internal/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// markdownTestContext contains objects relevant for testing the MarkdownService.
+type markdownTestContext struct {
+	mockPostRepository *mocks.MockPostRepository
+	mockPostService    *mocks.MockPostService
+	sut                services.MarkdownService
+}
+
+// createMarkdownServiceContext creates the context for testing the MarkdownService and reduces code duplication.
+func createMarkdownServiceContext(t *testing.T) *markdownTestContext {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	mockPostRepository := mocks.NewMockPostRepository(mockCtrl)
+	mockPostService := mocks.NewMockPostService(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, mockPostRepository, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	sut := services.CreateMarkdownService(cont, mockPostService)
+
+	return &markdownTestContext{mockPostRepository, mockPostService, sut}
+}
+
+// writeMarkdownFile writes a Markdown file for testing purposes.
+func writeMarkdownFile(t *testing.T, dir string, name string, content string) {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755), "should complete without error")
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0644), "should complete without error")
+}
+
+// TestMarkdownService_ImportPosts tests importing new and existing posts from a directory of Markdown files.
+func TestMarkdownService_ImportPosts(t *testing.T) {
+	t.Setenv("DEFAULT_USER", "admin")
+	c := createMarkdownServiceContext(t)
+
+	dir := t.TempDir()
+	writeMarkdownFile(t, dir, "new-post.md", "---\ntitle: New post\nsummary: Summary\ndate: 2023-05-17\nauthor: testAuthor\ntags: [go]\ncoverImage: /media/1\n---\n\n# New post\n")
+	writeMarkdownFile(t, dir, "drafts/existing.md", "---\ntitle: Existing post\nslug: existing-post\n---\nUpdated body\n")
+	writeMarkdownFile(t, dir, "notes.txt", "not a post")
+
+	image := "/media/1"
+	newPost := types.Post{
+		URLHandle:    "new-post",
+		Title:        "New post",
+		Author:       "testAuthor",
+		Summary:      "Summary",
+		Body:         "# New post",
+		CreationTime: time.Date(2023, 5, 17, 0, 0, 0, 0, time.UTC),
+		Visibility:   types.VisibilityPublic,
+		Tags:         []string{"go"},
+		CoverImage:   &image,
+	}
+	// The file has no cover image, so the current one is kept, and no visibility, so the private post becomes public
+	existingPost := types.Post{
+		URLHandle:  "existing-post",
+		Title:      "Existing post",
+		Author:     "admin",
+		Body:       "Updated body",
+		Visibility: types.VisibilityPublic,
+		Tags:       []string{},
+	}
+
+	c.mockPostRepository.EXPECT().GetPost("existing-post").Return(&repository.Post{URLHandle: "existing-post", Visibility: types.VisibilityPrivate}, nil)
+	c.mockPostService.EXPECT().UpdatePost(&existingPost, "admin").Return(existingPost, nil)
+	c.mockPostRepository.EXPECT().GetPost("new-post").Return(nil, errortypes.PostNotFoundError{Post: newPost})
+	c.mockPostService.EXPECT().AddPost(&newPost).Return(newPost, nil)
+
+	report, err := c.sut.ImportPosts(dir)
+
+	expectedReport := types.ImportReport{
+		Created: []string{"new-post"},
+		Updated: []string{"existing-post"},
+		Skipped: []types.ImportIssue{},
+	}
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, expectedReport, report, "report doesn't match")
+}
+
+// TestMarkdownService_ImportPosts_Skipped tests reporting the files which can't be imported.
+func TestMarkdownService_ImportPosts_Skipped(t *testing.T) {
+	t.Parallel()
+	c := createMarkdownServiceContext(t)
+
+	dir := t.TempDir()
+	writeMarkdownFile(t, dir, "conflict.md", "---\ntitle: Conflict\nauthor: testAuthor\n---\nbody")
+	writeMarkdownFile(t, dir, "invalid.md", "# No front matter")
+	writeMarkdownFile(t, dir, "secret.md", "---\ntitle: Secret\nvisibility: password\npasswordHash: plain\n---\nbody")
+
+	duplicateError := errortypes.DuplicateElementError{Key: "conflict"}
+	c.mockPostRepository.EXPECT().GetPost("conflict").Return(nil, errortypes.PostNotFoundError{})
+	c.mockPostService.EXPECT().AddPost(gomock.Any()).Return(types.Post{}, duplicateError)
+
+	report, err := c.sut.ImportPosts(dir)
+
+	expectedIssues := []types.ImportIssue{
+		{Item: "conflict.md", Reason: duplicateError.Error()},
+		{Item: "invalid.md", Reason: "document doesn't start with a front matter block"},
+		{Item: "secret.md", Reason: errortypes.InvalidPasswordHashError{}.Error()},
+	}
+	assert.Nil(t, err, "should complete without error")
+	assert.Empty(t, report.Created, "no post should be created")
+	assert.Equal(t, expectedIssues, report.Skipped, "skipped files don't match")
+}
+
+// TestMarkdownService_ImportPosts_Missing_Directory tests importing from a non-existent directory.
+func TestMarkdownService_ImportPosts_Missing_Directory(t *testing.T) {
+	t.Parallel()
+	c := createMarkdownServiceContext(t)
+
+	_, err := c.sut.ImportPosts(filepath.Join(t.TempDir(), "missing"))
+
+	assert.True(t, os.IsNotExist(err), "error doesn't match expected one")
+}
+
+// TestMarkdownService_ExportPosts tests writing every post to a directory as Markdown files.
+func TestMarkdownService_ExportPosts(t *testing.T) {
+	t.Parallel()
+	c := createMarkdownServiceContext(t)
+
+	image := "/media/1"
+	posts := []types.Post{
+		{
+			URLHandle:    "hello-world",
+			Title:        "Hello world",
+			Author:       "testAuthor",
+			Body:         "# Hello",
+			CreationTime: time.Date(2023, 5, 17, 8, 30, 0, 0, time.UTC),
+			Language:     "en",
+			Visibility:   types.VisibilityPublic,
+			Tags:         []string{"go"},
+		},
+		{URLHandle: "draft", Title: "Draft", Author: "testAuthor", Language: "de", Visibility: types.VisibilityPrivate, CoverImage: &image},
+		{URLHandle: "secret", Title: "Secret", Author: "testAuthor", Visibility: types.VisibilityPassword, PasswordHash: "$2a$10$hash"},
+		{URLHandle: "../escape", Title: "Escape"},
+	}
+
+	c.mockPostService.EXPECT().GetAllPosts().Return(posts, nil)
+
+	dir := filepath.Join(t.TempDir(), "export")
+	exported, err := c.sut.ExportPosts(storage.CreateLocalStorage(logger.CreateLogger(), dir))
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 3, exported, "posts with invalid file names should be skipped")
+
+	content, _ := os.ReadFile(filepath.Join(dir, "hello-world.md"))
+	expected := "---\ntitle: Hello world\nslug: hello-world\ndate: 2023-05-17T08:30:00Z\nauthor: testAuthor\ntags:\n  - go\n---\n\n# Hello\n"
+	assert.Equal(t, expected, string(content), "exported file doesn't match")
+
+	content, _ = os.ReadFile(filepath.Join(dir, "draft.md"))
+	assert.Contains(t, string(content), "coverImage: /media/1\nlanguage: de\nvisibility: private\n", "non-default settings should be exported")
+
+	content, _ = os.ReadFile(filepath.Join(dir, "secret.md"))
+	assert.Contains(t, string(content), "visibility: password\npasswordHash: $2a$10$hash\n", "password hash should be exported")
+
+	info, _ := os.Stat(filepath.Join(dir, "secret.md"))
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "exported files should only be readable by the owner")
+}
+
+// TestMarkdownService_ExportPosts_Import tests importing an exported and edited file, updating the original post.
+func TestMarkdownService_ExportPosts_Import(t *testing.T) {
+	t.Parallel()
+	c := createMarkdownServiceContext(t)
+
+	posts := []types.Post{{
+		URLHandle:    "hello-world",
+		Title:        "Hello world",
+		Author:       "testAuthor",
+		Body:         "# Hello",
+		CreationTime: time.Date(2023, 5, 17, 8, 30, 0, 0, time.UTC),
+		Visibility:   types.VisibilityPublic,
+		Tags:         []string{"go"},
+	}}
+	dir := t.TempDir()
+
+	c.mockPostService.EXPECT().GetAllPosts().Return(posts, nil)
+	_, err := c.sut.ExportPosts(storage.CreateLocalStorage(logger.CreateLogger(), dir))
+	assert.Nil(t, err, "should complete without error")
+
+	file := filepath.Join(dir, "hello-world.md")
+	content, _ := os.ReadFile(file)
+	edited := strings.NewReplacer("title: Hello world", "title: Hello again", "date: 2023-05-17T08:30:00Z", "date: 2022-01-02T10:00:00Z").Replace(string(content))
+	writeMarkdownFile(t, dir, "hello-world.md", edited)
+
+	// The post was made private since the export, the public visibility omitted from the file must be restored
+	expected := types.Post{
+		URLHandle:    "hello-world",
+		Title:        "Hello again",
+		Author:       "testAuthor",
+		Body:         "# Hello",
+		CreationTime: time.Date(2022, 1, 2, 10, 0, 0, 0, time.UTC),
+		Visibility:   types.VisibilityPublic,
+		Tags:         []string{"go"},
+	}
+	c.mockPostRepository.EXPECT().GetPost("hello-world").Return(&repository.Post{URLHandle: "hello-world", Visibility: types.VisibilityPrivate}, nil)
+	c.mockPostService.EXPECT().UpdatePost(&expected, "testAuthor").Return(expected, nil)
+
+	report, err := c.sut.ImportPosts(dir)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, []string{"hello-world"}, report.Updated, "post should be updated")
+	assert.Empty(t, report.Skipped, "no file should be skipped")
+}
+
+// TestMarkdownService_ImportPosts_Password_Hash tests importing a password-protected post along with its password hash.
+func TestMarkdownService_ImportPosts_Password_Hash(t *testing.T) {
+	t.Parallel()
+	c := createMarkdownServiceContext(t)
+
+	hash, _ := auth.HashString("secret")
+	dir := t.TempDir()
+	writeMarkdownFile(t, dir, "secret.md", "---\ntitle: Secret\nauthor: testAuthor\nvisibility: password\npasswordHash: "+hash+"\n---\nbody")
+
+	c.mockPostRepository.EXPECT().GetPost("secret").Return(nil, errortypes.PostNotFoundError{})
+	c.mockPostService.EXPECT().AddPost(gomock.Any()).DoAndReturn(func(post *types.Post) (types.Post, error) {
+		assert.Equal(t, hash, post.PasswordHash, "password hash should be imported")
+		return *post, nil
+	})
+
+	report, err := c.sut.ImportPosts(dir)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, []string{"secret"}, report.Created, "post should be created")
+}
+
+// TestMarkdownService_ExportPosts_Unexpected_Error tests handling an error while retrieving the posts to export.
+func TestMarkdownService_ExportPosts_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createMarkdownServiceContext(t)
+
+	expectedError := fmt.Errorf("unexpected error")
+	c.mockPostService.EXPECT().GetAllPosts().Return(nil, expectedError)
+
+	exported, err := c.sut.ExportPosts(storage.CreateLocalStorage(logger.CreateLogger(), t.TempDir()))
+
+	assert.Equal(t, 0, exported, "no post should be exported")
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
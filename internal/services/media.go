@@ -0,0 +1,570 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/imaging"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/storage"
+	"github.com/wlchs/blog/internal/types"
+	"go.uber.org/zap"
+	"image"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultMaxMediaSize is the default upload size limit of media files in bytes.
+const defaultMaxMediaSize = 10 << 20
+
+// defaultMaxImagePixels is the default limit of the pixels of processed images. Small, highly compressed files can
+// describe huge images, which would exhaust the memory when decoded.
+const defaultMaxImagePixels = 40_000_000
+
+// mediaExtensions maps the supported media types to the file extensions used in the storage keys.
+var mediaExtensions = map[string]string{
+	"image/gif":  ".gif",
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// imageTagPattern matches the img tags of rendered post bodies.
+var imageTagPattern = regexp.MustCompile(`(?i)<img\b[^>]*>`)
+
+// mediaSourcePattern matches the src attribute of img tags referencing an uploaded file.
+var mediaSourcePattern = regexp.MustCompile(`(?i)\ssrc\s*=\s*["']/media/(\d+)["']`)
+
+// MediaService interface. Defines media-related business logic.
+type MediaService interface {
+	DeleteMedia(id uint, userName string) error
+	GetMedia(id uint) (types.Media, error)
+	GetMediaList() ([]types.Media, error)
+	FileURL(key string) (string, error)
+	GetMediaVariant(id uint, name string) (types.MediaVariant, error)
+	MaxUploadSize() int64
+	OpenFile(key string) (io.ReadCloser, error)
+	UploadMedia(content io.Reader, fileName string, userName string) (types.Media, error)
+}
+
+// mediaService is the concrete implementation of the MediaService interface.
+type mediaService struct {
+	cont container.Container
+}
+
+// CreateMediaService instantiates the mediaService using the application container.
+func CreateMediaService(cont container.Container) MediaService {
+	return &mediaService{cont}
+}
+
+// DeleteMedia removes an uploaded file, its variants and their metadata. Only the uploader of the file is allowed to delete it.
+func (m mediaService) DeleteMedia(id uint, userName string) error {
+	log := m.cont.GetLogger()
+	mediaRepository := m.cont.GetMediaRepository()
+	fileStorage := m.cont.GetStorage()
+
+	media, err := mediaRepository.GetMedia(id)
+	if err != nil {
+		return err
+	}
+
+	if media.Uploader.UserName != userName {
+		log.Debugf("user %s is not allowed to delete media %d", userName, id)
+		return errortypes.MediaDeleteForbiddenError{Media: types.Media{ID: id}, UserName: userName}
+	}
+
+	log.Infof("deleting media %d", id)
+
+	if err := mediaRepository.DeleteMedia(id); err != nil {
+		return err
+	}
+
+	// The metadata is already gone, leftover files are only logged
+	keys := []string{media.StorageKey}
+	for _, variant := range media.Variants {
+		keys = append(keys, variant.StorageKey)
+	}
+	deleteStoredFiles(log, fileStorage, keys)
+
+	return nil
+}
+
+// FileURL returns a temporary URL to download a stored media file or variant directly from the file storage.
+// An empty string is returned if the file storage doesn't support direct downloads.
+func (m mediaService) FileURL(key string) (string, error) {
+	fileStorage := m.cont.GetStorage()
+	return fileStorage.URL(key)
+}
+
+// GetMedia retrieves the metadata of the uploaded file with the given ID.
+func (m mediaService) GetMedia(id uint) (types.Media, error) {
+	mediaRepository := m.cont.GetMediaRepository()
+	media, err := mediaRepository.GetMedia(id)
+	return mapMedia(media), err
+}
+
+// GetMediaList retrieves the metadata of every uploaded file.
+func (m mediaService) GetMediaList() ([]types.Media, error) {
+	mediaRepository := m.cont.GetMediaRepository()
+	media, err := mediaRepository.GetMediaList()
+	return mapMediaList(media), err
+}
+
+// GetMediaVariant retrieves the metadata of a responsive variant of an uploaded image.
+// Missing variants are generated on demand, e.g. for images uploaded before the variant was introduced.
+// The original image is served instead of the variant if it isn't wider than the variant or it can't be processed,
+// e.g. animated GIF and WebP images or images exceeding the pixel limit. Only unknown variants are reported as not found.
+func (m mediaService) GetMediaVariant(id uint, name string) (types.MediaVariant, error) {
+	log := m.cont.GetLogger()
+	mediaRepository := m.cont.GetMediaRepository()
+	fileStorage := m.cont.GetStorage()
+
+	spec, found := imaging.FindVariant(name)
+	if !found {
+		return types.MediaVariant{}, errortypes.MediaVariantNotFoundError{Media: types.Media{ID: id}, Variant: name}
+	}
+
+	media, err := mediaRepository.GetMedia(id)
+	if err != nil {
+		return types.MediaVariant{}, err
+	}
+
+	for _, variant := range media.Variants {
+		if variant.Name == name {
+			return mapMediaVariant(&variant), nil
+		}
+	}
+
+	// Compare the recorded dimensions first, so the original doesn't have to be downloaded and decoded
+	if media.Width > 0 && (media.Width <= spec.Width || !imaging.CanResize(media.ContentType) || m.exceedsPixelLimit(media.Width, media.Height)) {
+		return mapOriginalVariant(media, name), nil
+	}
+
+	content, err := fileStorage.Get(media.StorageKey)
+	if err != nil {
+		log.Errorf("failed to open stored file %s of media %d: %v", media.StorageKey, id, err)
+		return types.MediaVariant{}, err
+	}
+	data, err := io.ReadAll(content)
+	_ = content.Close()
+	if err != nil {
+		return types.MediaVariant{}, err
+	}
+
+	if width, height, err := imaging.Dimensions(data, media.ContentType); err != nil || m.exceedsPixelLimit(width, height) {
+		return mapOriginalVariant(media, name), nil
+	}
+
+	img, err := imaging.Decode(data, media.ContentType)
+	if errors.Is(err, imaging.ErrUnsupportedImage) {
+		return mapOriginalVariant(media, name), nil
+	} else if err != nil {
+		log.Errorf("failed to decode media %d: %v", id, err)
+		return types.MediaVariant{}, err
+	}
+
+	log.Infof("generating variant %s of media %d on demand", name, id)
+
+	variant, err := storeVariant(fileStorage, img, media.ContentType, media.StorageKey, spec)
+	if err != nil {
+		log.Errorf("failed to store variant %s of media %d: %v", name, id, err)
+		return types.MediaVariant{}, err
+	}
+	if variant == nil {
+		return mapOriginalVariant(media, name), nil
+	}
+
+	variant.MediaID = media.ID
+	switch err := mediaRepository.AddMediaVariant(variant); err.(type) {
+	case nil:
+	case errortypes.DuplicateElementError:
+		// A concurrent request generated the same variant under the same storage key
+		log.Debugf("variant %s of media %d was generated concurrently", name, id)
+	default:
+		return types.MediaVariant{}, err
+	}
+
+	return mapMediaVariant(variant), nil
+}
+
+// MaxUploadSize returns the upload size limit of media files in bytes.
+// The limit can be configured using the MEDIA_MAX_SIZE environment variable.
+func (m mediaService) MaxUploadSize() int64 {
+	if size, err := strconv.ParseInt(os.Getenv("MEDIA_MAX_SIZE"), 10, 64); err == nil && size > 0 {
+		return size
+	}
+	return defaultMaxMediaSize
+}
+
+// maxImagePixels returns the limit of the pixels of processed images.
+// The limit can be configured using the MEDIA_MAX_PIXELS environment variable.
+func (m mediaService) maxImagePixels() int64 {
+	if pixels, err := strconv.ParseInt(os.Getenv("MEDIA_MAX_PIXELS"), 10, 64); err == nil && pixels > 0 {
+		return pixels
+	}
+	return defaultMaxImagePixels
+}
+
+// exceedsPixelLimit checks whether an image of the given dimensions is too large to be decoded.
+func (m mediaService) exceedsPixelLimit(width int, height int) bool {
+	return int64(width)*int64(height) > m.maxImagePixels()
+}
+
+// OpenFile opens the content of a stored media file or variant for reading. The caller is responsible for closing it.
+func (m mediaService) OpenFile(key string) (io.ReadCloser, error) {
+	fileStorage := m.cont.GetStorage()
+	return fileStorage.Get(key)
+}
+
+// UploadMedia stores a new file uploaded by the given user.
+// The media type is detected from the content of the file, the provided file name is only kept for reference.
+// Location and other metadata is stripped from the images before storing them, then the responsive variants are generated.
+func (m mediaService) UploadMedia(content io.Reader, fileName string, userName string) (types.Media, error) {
+	log := m.cont.GetLogger()
+	mediaRepository := m.cont.GetMediaRepository()
+	userRepository := m.cont.GetUserRepository()
+	fileStorage := m.cont.GetStorage()
+
+	limit := m.MaxUploadSize()
+	data, err := io.ReadAll(io.LimitReader(content, limit+1))
+	if err != nil {
+		log.Errorf("failed to read uploaded file %s: %v", fileName, err)
+		return types.Media{}, err
+	}
+
+	if len(data) == 0 {
+		return types.Media{}, errortypes.MissingMediaFileError{}
+	}
+
+	if int64(len(data)) > limit {
+		log.Debugf("uploaded file %s exceeds the size limit of %d bytes", fileName, limit)
+		return types.Media{}, errortypes.MediaTooLargeError{Limit: limit}
+	}
+
+	contentType, _, _ := strings.Cut(http.DetectContentType(data), ";")
+	extension, supported := mediaExtensions[contentType]
+	if !supported {
+		log.Debugf("uploaded file %s has unsupported media type %s", fileName, contentType)
+		return types.Media{}, errortypes.UnsupportedMediaTypeError{ContentType: contentType}
+	}
+
+	// The dimensions are read from the headers before any pixel data is decoded, e.g. to rotate JPEG images
+	width, height, err := imaging.Dimensions(data, contentType)
+	if err != nil {
+		log.Debugf("failed to read the dimensions of uploaded file %s: %v", fileName, err)
+		return types.Media{}, errortypes.InvalidImageError{Reason: err.Error()}
+	}
+
+	if m.exceedsPixelLimit(width, height) {
+		log.Debugf("uploaded file %s exceeds the pixel limit with %dx%d pixels", fileName, width, height)
+		return types.Media{}, errortypes.ImageTooLargeError{Limit: m.maxImagePixels()}
+	}
+
+	data, err = imaging.StripMetadata(data, contentType)
+	if err != nil {
+		log.Debugf("failed to strip the metadata of uploaded file %s: %v", fileName, err)
+		return types.Media{}, errortypes.InvalidImageError{Reason: err.Error()}
+	}
+
+	uploader, err := userRepository.GetUser(userName)
+	if err != nil {
+		log.Errorf("failed to get uploader %s of file %s: %v", userName, fileName, err)
+		return types.Media{}, err
+	}
+
+	key, err := createStorageKey(extension)
+	if err != nil {
+		return types.Media{}, err
+	}
+
+	checksum := sha256.Sum256(data)
+	media := repository.Media{
+		FileName:    path.Base(strings.ReplaceAll(fileName, "\\", "/")),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Width:       width,
+		Height:      height,
+		Checksum:    hex.EncodeToString(checksum[:]),
+		StorageKey:  key,
+		UploaderID:  uploader.ID,
+	}
+
+	log.Infof("storing media %s uploaded by %s as %s", media.FileName, userName, key)
+
+	if err := fileStorage.Put(key, bytes.NewReader(data), media.Size, contentType); err != nil {
+		log.Errorf("failed to store file %s: %v", key, err)
+		return types.Media{}, err
+	}
+
+	variants, err := createVariants(fileStorage, data, contentType, key)
+	media.Variants = variants
+	if err == nil {
+		_, err = mediaRepository.AddMedia(&media)
+	}
+
+	if err != nil {
+		log.Errorf("failed to save media %s: %v", key, err)
+		keys := []string{key}
+		for _, variant := range variants {
+			keys = append(keys, variant.StorageKey)
+		}
+		deleteStoredFiles(log, fileStorage, keys)
+		return types.Media{}, err
+	}
+
+	media.Uploader = *uploader
+	return mapMedia(&media), nil
+}
+
+// createStorageKey generates a unique, unguessable storage key for a new media file.
+func createStorageKey(extension string) (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("media/%s/%s%s", time.Now().UTC().Format("2006/01"), hex.EncodeToString(id), extension), nil
+}
+
+// createVariants generates and stores every responsive variant narrower than the image.
+// Images which can't be processed, e.g. animated GIF and WebP images, are stored without variants.
+// The variants keep the format of the original image, except for GIF images, whose variants are encoded as PNG.
+// The variants stored before an error are returned along with the error, so they can be cleaned up.
+func createVariants(fileStorage storage.Storage, data []byte, contentType string, key string) ([]repository.MediaVariant, error) {
+	img, err := imaging.Decode(data, contentType)
+	if errors.Is(err, imaging.ErrUnsupportedImage) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errortypes.InvalidImageError{Reason: err.Error()}
+	}
+
+	var variants []repository.MediaVariant
+	for _, spec := range imaging.Variants {
+		variant, err := storeVariant(fileStorage, img, contentType, key, spec)
+		if err != nil {
+			return variants, err
+		}
+		if variant != nil {
+			variants = append(variants, *variant)
+		}
+	}
+
+	return variants, nil
+}
+
+// storeVariant resizes an image to the width of the variant and stores it next to the original file.
+// Nil is returned if the image isn't wider than the variant, since images are never scaled up.
+func storeVariant(fileStorage storage.Storage, img image.Image, contentType string, key string, spec imaging.Variant) (*repository.MediaVariant, error) {
+	if img.Bounds().Dx() <= spec.Width {
+		return nil, nil
+	}
+
+	resized := imaging.Resize(img, spec.Width)
+	data, variantType, err := imaging.Encode(resized, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	variantKey := fmt.Sprintf("%s_%s%s", strings.TrimSuffix(key, path.Ext(key)), spec.Name, mediaExtensions[variantType])
+	if err := fileStorage.Put(variantKey, bytes.NewReader(data), int64(len(data)), variantType); err != nil {
+		return nil, err
+	}
+
+	checksum := sha256.Sum256(data)
+	return &repository.MediaVariant{
+		Name:        spec.Name,
+		ContentType: variantType,
+		Width:       resized.Bounds().Dx(),
+		Height:      resized.Bounds().Dy(),
+		Size:        int64(len(data)),
+		Checksum:    hex.EncodeToString(checksum[:]),
+		StorageKey:  variantKey,
+	}, nil
+}
+
+// deleteStoredFiles removes stored files which are no longer referenced. Failures are only logged.
+func deleteStoredFiles(log *zap.SugaredLogger, fileStorage storage.Storage, keys []string) {
+	for _, key := range keys {
+		if err := fileStorage.Delete(key); err != nil {
+			log.Errorf("failed to delete stored file %s: %v", key, err)
+		}
+	}
+}
+
+// renderResponsiveImages adds srcset, sizes, width and height attributes to the img tags of a rendered post body referencing uploaded images.
+// Tags which already define a srcset are left untouched. If the media can't be retrieved, the body is returned unchanged.
+func renderResponsiveImages(log *zap.SugaredLogger, mediaRepository repository.MediaRepository, body string) string {
+	var ids []uint
+	for _, tag := range imageTagPattern.FindAllString(body, -1) {
+		if id, ok := mediaSourceID(tag); ok {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return body
+	}
+
+	media, err := mediaRepository.GetMediaByIDs(ids)
+	if err != nil {
+		log.Errorf("failed to get media %v referenced by post body: %v", ids, err)
+		return body
+	}
+
+	byID := make(map[uint]*repository.Media, len(media))
+	for i := range media {
+		byID[media[i].ID] = &media[i]
+	}
+
+	return imageTagPattern.ReplaceAllStringFunc(body, func(tag string) string {
+		id, ok := mediaSourceID(tag)
+		if !ok || byID[id] == nil {
+			return tag
+		}
+		return addImageAttributes(tag, byID[id])
+	})
+}
+
+// mediaSourceID returns the ID of the uploaded file referenced by an img tag without a srcset.
+func mediaSourceID(tag string) (uint, bool) {
+	if strings.Contains(strings.ToLower(tag), "srcset") {
+		return 0, false
+	}
+
+	match := mediaSourcePattern.FindStringSubmatch(tag)
+	if match == nil {
+		return 0, false
+	}
+
+	id, err := strconv.ParseUint(match[1], 10, 0)
+	return uint(id), err == nil
+}
+
+// addImageAttributes extends an img tag with the responsive attributes of the referenced media.
+func addImageAttributes(tag string, media *repository.Media) string {
+	mapped := mapMedia(media)
+
+	var attributes []string
+	if len(mapped.Variants) > 0 && mapped.Width > 0 {
+		candidates := make([]string, 0, len(mapped.Variants)+1)
+		for _, variant := range mapped.Variants {
+			candidates = append(candidates, fmt.Sprintf("%s %dw", variant.URL, variant.Width))
+		}
+		candidates = append(candidates, fmt.Sprintf("%s %dw", mapped.URL, mapped.Width))
+
+		attributes = append(attributes,
+			fmt.Sprintf(`srcset="%s"`, strings.Join(candidates, ", ")),
+			fmt.Sprintf(`sizes="(max-width: %dpx) 100vw, %dpx"`, mapped.Width, mapped.Width),
+		)
+	}
+
+	lower := strings.ToLower(tag)
+	if mapped.Width > 0 && mapped.Height > 0 && !strings.Contains(lower, " width=") && !strings.Contains(lower, " height=") {
+		attributes = append(attributes, fmt.Sprintf(`width="%d"`, mapped.Width), fmt.Sprintf(`height="%d"`, mapped.Height))
+	}
+
+	if len(attributes) == 0 {
+		return tag
+	}
+
+	end := len(tag) - 1
+	if strings.HasSuffix(tag, "/>") {
+		end = len(tag) - 2
+	}
+	prefix := strings.TrimRight(tag[:end], " ")
+	return prefix + " " + strings.Join(attributes, " ") + tag[len(prefix):]
+}
+
+// mapMedia maps a Media model to a media data object
+func mapMedia(m *repository.Media) types.Media {
+	if m == nil {
+		return types.Media{}
+	}
+	return types.Media{
+		ID:           m.ID,
+		FileName:     m.FileName,
+		ContentType:  m.ContentType,
+		Size:         m.Size,
+		Width:        m.Width,
+		Height:       m.Height,
+		Checksum:     m.Checksum,
+		Uploader:     m.Uploader.UserName,
+		URL:          fmt.Sprintf("/media/%d", m.ID),
+		Variants:     mapMediaVariants(m.Variants),
+		CreationTime: m.CreatedAt,
+		StorageKey:   m.StorageKey,
+	}
+}
+
+// mapMediaVariant maps a MediaVariant model to a media variant data object
+func mapMediaVariant(v *repository.MediaVariant) types.MediaVariant {
+	return types.MediaVariant{
+		Name:         v.Name,
+		ContentType:  v.ContentType,
+		Width:        v.Width,
+		Height:       v.Height,
+		Size:         v.Size,
+		Checksum:     v.Checksum,
+		URL:          fmt.Sprintf("/media/%d/variants/%s", v.MediaID, v.Name),
+		CreationTime: v.CreatedAt,
+		StorageKey:   v.StorageKey,
+	}
+}
+
+// mapOriginalVariant maps the original file of an image to a variant data object, which is served instead of variants the
+// image can't be resized to.
+func mapOriginalVariant(m *repository.Media, name string) types.MediaVariant {
+	return types.MediaVariant{
+		Name:         name,
+		ContentType:  m.ContentType,
+		Width:        m.Width,
+		Height:       m.Height,
+		Size:         m.Size,
+		Checksum:     m.Checksum,
+		URL:          fmt.Sprintf("/media/%d/variants/%s", m.ID, name),
+		CreationTime: m.CreatedAt,
+		StorageKey:   m.StorageKey,
+	}
+}
+
+// mapMediaVariants maps a slice of MediaVariant models to a slice of media variant data objects ordered by their width.
+// Nil is returned if there are no variants, so they are omitted from the responses.
+func mapMediaVariants(v []repository.MediaVariant) []types.MediaVariant {
+	if len(v) == 0 {
+		return nil
+	}
+
+	variants := make([]types.MediaVariant, 0, len(v))
+	for _, item := range v {
+		variants = append(variants, mapMediaVariant(&item))
+	}
+
+	sort.Slice(variants, func(i, j int) bool {
+		return variants[i].Width < variants[j].Width
+	})
+	return variants
+}
+
+// mapMediaList maps a slice of Media models to a slice of media data objects
+func mapMediaList(m []repository.Media) []types.Media {
+	if m == nil {
+		return []types.Media{}
+	}
+	media := make([]types.Media, 0, len(m))
+
+	for _, item := range m {
+		media = append(media, mapMedia(&item))
+	}
+
+	return media
+}
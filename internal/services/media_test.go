@@ -0,0 +1,373 @@
+package services_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/imaging"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/types"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+)
+
+// pngHeader is the signature of PNG files used to test media type detection.
+const pngHeader = "\x89PNG\r\n\x1a\n"
+
+// createPNG creates a PNG image of the given size for testing purposes.
+func createPNG(t *testing.T, width int, height int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	assert.Nil(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))), "should complete without error")
+	return buf.Bytes()
+}
+
+// mediaTestContext contains objects relevant for testing the MediaService.
+type mediaTestContext struct {
+	mockMediaRepository *mocks.MockMediaRepository
+	mockUserRepository  *mocks.MockUserRepository
+	mockStorage         *mocks.MockStorage
+	sut                 services.MediaService
+}
+
+// createMediaServiceContext creates the context for testing the MediaService and reduces code duplication.
+func createMediaServiceContext(t *testing.T) *mediaTestContext {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	mockMediaRepository := mocks.NewMockMediaRepository(mockCtrl)
+	mockUserRepository := mocks.NewMockUserRepository(mockCtrl)
+	mockStorage := mocks.NewMockStorage(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, mockMediaRepository, nil, nil, nil, nil, nil, mockUserRepository, nil, nil, nil, mockStorage, nil, nil)
+	sut := services.CreateMediaService(cont)
+
+	return &mediaTestContext{mockMediaRepository, mockUserRepository, mockStorage, sut}
+}
+
+// createMediaModel creates a media model for testing purposes.
+func createMediaModel() repository.Media {
+	return repository.Media{
+		ID:          1,
+		FileName:    "test.png",
+		ContentType: "image/png",
+		Size:        int64(len(pngHeader)),
+		Width:       800,
+		Height:      400,
+		Checksum:    "checksum",
+		StorageKey:  "media/2024/01/test.png",
+		UploaderID:  1,
+		Uploader:    repository.User{ID: 1, UserName: "testAuthor"},
+	}
+}
+
+// TestMediaService_UploadMedia tests uploading a new image and generating its responsive variants.
+func TestMediaService_UploadMedia(t *testing.T) {
+	t.Parallel()
+	c := createMediaServiceContext(t)
+
+	uploader := repository.User{ID: 1, UserName: "testAuthor"}
+	content := createPNG(t, 800, 400)
+	var storedKeys []string
+
+	c.mockUserRepository.EXPECT().GetUser(uploader.UserName).Return(&uploader, nil)
+	c.mockStorage.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), "image/png").
+		DoAndReturn(func(key string, _ io.Reader, _ int64, _ string) error {
+			storedKeys = append(storedKeys, key)
+			return nil
+		}).
+		Times(3)
+	c.mockMediaRepository.EXPECT().AddMedia(gomock.Any()).DoAndReturn(func(m *repository.Media) (*repository.Media, error) {
+		m.ID = 1
+		for i := range m.Variants {
+			m.Variants[i].MediaID = 1
+		}
+		return m, nil
+	})
+
+	media, err := c.sut.UploadMedia(bytes.NewReader(content), "C:\\images\\test.png", uploader.UserName)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "test.png", media.FileName, "file name should be stripped of its directory")
+	assert.Equal(t, "image/png", media.ContentType, "content type should be detected")
+	assert.Equal(t, []int{800, 400}, []int{media.Width, media.Height}, "dimensions should be recorded")
+	assert.Equal(t, "testAuthor", media.Uploader, "uploader should be set")
+	assert.Equal(t, "/media/1", media.URL, "media URL should be set")
+	assert.Equal(t, 64, len(media.Checksum), "checksum should be a hex encoded SHA-256 hash")
+
+	assert.Equal(t, 2, len(media.Variants), "only variants narrower than the image should be generated")
+	assert.Equal(t, "thumbnail", media.Variants[0].Name, "variants should be ordered by width")
+	assert.Equal(t, []int{320, 160}, []int{media.Variants[0].Width, media.Variants[0].Height}, "variant should keep the aspect ratio")
+	assert.Equal(t, "/media/1/variants/medium", media.Variants[1].URL, "variant URL should be set")
+
+	assert.True(t, strings.HasPrefix(storedKeys[0], "media/"), "storage key should be placed in the media directory")
+	assert.True(t, strings.HasSuffix(storedKeys[0], ".png"), "storage key should have the extension of the media type")
+	assert.Equal(t, strings.TrimSuffix(storedKeys[0], ".png")+"_thumbnail.png", storedKeys[1], "variants should be stored next to the original")
+}
+
+// TestMediaService_UploadMedia_Invalid_Image tests uploading a file which looks like an image, but is malformed.
+func TestMediaService_UploadMedia_Invalid_Image(t *testing.T) {
+	t.Parallel()
+	c := createMediaServiceContext(t)
+
+	_, err := c.sut.UploadMedia(strings.NewReader(pngHeader+"broken"), "test.png", "testAuthor")
+
+	assert.IsType(t, errortypes.InvalidImageError{}, err, "error doesn't match expected one")
+}
+
+// TestMediaService_UploadMedia_Empty tests uploading an empty file.
+func TestMediaService_UploadMedia_Empty(t *testing.T) {
+	t.Parallel()
+	c := createMediaServiceContext(t)
+
+	_, err := c.sut.UploadMedia(strings.NewReader(""), "test.png", "testAuthor")
+
+	assert.Equal(t, errortypes.MissingMediaFileError{}, err, "error doesn't match expected one")
+}
+
+// TestMediaService_UploadMedia_Unsupported_Type tests uploading a file which isn't a supported image.
+func TestMediaService_UploadMedia_Unsupported_Type(t *testing.T) {
+	t.Parallel()
+	c := createMediaServiceContext(t)
+
+	_, err := c.sut.UploadMedia(strings.NewReader("<html><script>alert(1)</script></html>"), "test.png", "testAuthor")
+
+	assert.Equal(t, errortypes.UnsupportedMediaTypeError{ContentType: "text/html"}, err, "error doesn't match expected one")
+}
+
+// TestMediaService_UploadMedia_Too_Large tests uploading a file exceeding the configured size limit.
+func TestMediaService_UploadMedia_Too_Large(t *testing.T) {
+	t.Setenv("MEDIA_MAX_SIZE", "16")
+	c := createMediaServiceContext(t)
+
+	_, err := c.sut.UploadMedia(bytes.NewReader(createPNG(t, 10, 10)), "test.png", "testAuthor")
+
+	assert.Equal(t, errortypes.MediaTooLargeError{Limit: 16}, err, "error doesn't match expected one")
+}
+
+// TestMediaService_UploadMedia_Too_Many_Pixels tests rejecting an image exceeding the configured pixel limit before decoding it.
+func TestMediaService_UploadMedia_Too_Many_Pixels(t *testing.T) {
+	t.Setenv("MEDIA_MAX_PIXELS", "100")
+	c := createMediaServiceContext(t)
+
+	_, err := c.sut.UploadMedia(bytes.NewReader(createPNG(t, 20, 20)), "test.png", "testAuthor")
+
+	assert.Equal(t, errortypes.ImageTooLargeError{Limit: 100}, err, "error doesn't match expected one")
+}
+
+// TestMediaService_UploadMedia_Cleanup tests removing the stored file if its metadata can't be saved.
+func TestMediaService_UploadMedia_Cleanup(t *testing.T) {
+	t.Parallel()
+	c := createMediaServiceContext(t)
+
+	uploader := repository.User{ID: 1, UserName: "testAuthor"}
+	expectedError := fmt.Errorf("error")
+	var storedKey string
+
+	c.mockUserRepository.EXPECT().GetUser(uploader.UserName).Return(&uploader, nil)
+	c.mockStorage.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(key string, _ io.Reader, _ int64, _ string) error {
+			storedKey = key
+			return nil
+		})
+	c.mockMediaRepository.EXPECT().AddMedia(gomock.Any()).Return(nil, expectedError)
+	c.mockStorage.EXPECT().Delete(gomock.Any()).DoAndReturn(func(key string) error {
+		assert.Equal(t, storedKey, key, "the stored file should be removed")
+		return nil
+	})
+
+	_, err := c.sut.UploadMedia(bytes.NewReader(createPNG(t, 100, 100)), "test.png", uploader.UserName)
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
+
+// TestMediaService_GetMedia tests getting the metadata of an uploaded file.
+func TestMediaService_GetMedia(t *testing.T) {
+	t.Parallel()
+	c := createMediaServiceContext(t)
+
+	model := createMediaModel()
+	c.mockMediaRepository.EXPECT().GetMedia(model.ID).Return(&model, nil)
+
+	media, err := c.sut.GetMedia(model.ID)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, model.StorageKey, media.StorageKey, "storage key should be mapped")
+	assert.Equal(t, "testAuthor", media.Uploader, "uploader should be mapped")
+}
+
+// TestMediaService_GetMediaList_Unexpected_Error tests handling an unexpected error while getting every uploaded file.
+func TestMediaService_GetMediaList_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createMediaServiceContext(t)
+
+	c.mockMediaRepository.EXPECT().GetMediaList().Return(nil, fmt.Errorf("error"))
+
+	media, err := c.sut.GetMediaList()
+
+	assert.NotNil(t, err, "expected error")
+	assert.Equal(t, []types.Media{}, media, "shouldn't return any media")
+}
+
+// TestMediaService_OpenFile tests opening the content of an uploaded file.
+func TestMediaService_OpenFile(t *testing.T) {
+	t.Parallel()
+	c := createMediaServiceContext(t)
+
+	content := io.NopCloser(bytes.NewReader([]byte(pngHeader)))
+	c.mockStorage.EXPECT().Get("media/2024/01/test.png").Return(content, nil)
+
+	f, err := c.sut.OpenFile("media/2024/01/test.png")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, content, f, "stored content should be returned")
+}
+
+// TestMediaService_FileURL tests getting a temporary download URL of an uploaded file.
+func TestMediaService_FileURL(t *testing.T) {
+	t.Parallel()
+	c := createMediaServiceContext(t)
+
+	c.mockStorage.EXPECT().URL("media/2024/01/test.png").Return("https://storage.example.com/test.png", nil)
+
+	u, err := c.sut.FileURL("media/2024/01/test.png")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "https://storage.example.com/test.png", u, "URL of the file storage should be returned")
+}
+
+// TestMediaService_DeleteMedia tests removing an uploaded file.
+func TestMediaService_DeleteMedia(t *testing.T) {
+	t.Parallel()
+	c := createMediaServiceContext(t)
+
+	model := createMediaModel()
+
+	model.Variants = []repository.MediaVariant{{MediaID: 1, Name: "thumbnail", StorageKey: "media/2024/01/test_thumbnail.png"}}
+
+	gomock.InOrder(
+		c.mockMediaRepository.EXPECT().GetMedia(model.ID).Return(&model, nil),
+		c.mockMediaRepository.EXPECT().DeleteMedia(model.ID).Return(nil),
+		c.mockStorage.EXPECT().Delete(model.StorageKey).Return(nil),
+		c.mockStorage.EXPECT().Delete("media/2024/01/test_thumbnail.png").Return(nil),
+	)
+
+	err := c.sut.DeleteMedia(model.ID, "testAuthor")
+
+	assert.Nil(t, err, "should complete without error")
+}
+
+// TestMediaService_DeleteMedia_Forbidden tests removing a file uploaded by another user.
+func TestMediaService_DeleteMedia_Forbidden(t *testing.T) {
+	t.Parallel()
+	c := createMediaServiceContext(t)
+
+	model := createMediaModel()
+	expectedError := errortypes.MediaDeleteForbiddenError{Media: types.Media{ID: model.ID}, UserName: "otherUser"}
+
+	c.mockMediaRepository.EXPECT().GetMedia(model.ID).Return(&model, nil)
+
+	err := c.sut.DeleteMedia(model.ID, "otherUser")
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
+
+// TestMediaService_GetMediaVariant tests getting an existing responsive variant of an image.
+func TestMediaService_GetMediaVariant(t *testing.T) {
+	t.Parallel()
+	c := createMediaServiceContext(t)
+
+	model := createMediaModel()
+	model.Variants = []repository.MediaVariant{{MediaID: 1, Name: "thumbnail", Width: 320, StorageKey: "media/2024/01/test_thumbnail.png"}}
+
+	c.mockMediaRepository.EXPECT().GetMedia(model.ID).Return(&model, nil)
+
+	variant, err := c.sut.GetMediaVariant(model.ID, "thumbnail")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "media/2024/01/test_thumbnail.png", variant.StorageKey, "variant doesn't match the expected one")
+}
+
+// TestMediaService_GetMediaVariant_On_Demand tests generating a missing responsive variant of an image.
+func TestMediaService_GetMediaVariant_On_Demand(t *testing.T) {
+	t.Parallel()
+	c := createMediaServiceContext(t)
+
+	model := createMediaModel()
+	content := io.NopCloser(bytes.NewReader(createPNG(t, 800, 400)))
+
+	gomock.InOrder(
+		c.mockMediaRepository.EXPECT().GetMedia(model.ID).Return(&model, nil),
+		c.mockStorage.EXPECT().Get(model.StorageKey).Return(content, nil),
+		c.mockStorage.EXPECT().Put("media/2024/01/test_medium.png", gomock.Any(), gomock.Any(), "image/png").Return(nil),
+		c.mockMediaRepository.EXPECT().AddMediaVariant(gomock.Any()).Return(nil),
+	)
+
+	variant, err := c.sut.GetMediaVariant(model.ID, "medium")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, []int{768, 384}, []int{variant.Width, variant.Height}, "variant should be resized")
+	assert.Equal(t, "/media/1/variants/medium", variant.URL, "variant URL should be set")
+}
+
+// TestMediaService_GetMediaVariant_Too_Narrow tests serving the original for a variant wider than the image itself without
+// downloading the original.
+func TestMediaService_GetMediaVariant_Too_Narrow(t *testing.T) {
+	t.Parallel()
+	c := createMediaServiceContext(t)
+
+	model := createMediaModel()
+
+	c.mockMediaRepository.EXPECT().GetMedia(model.ID).Return(&model, nil)
+
+	variant, err := c.sut.GetMediaVariant(model.ID, "large")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, model.StorageKey, variant.StorageKey, "the original should be served")
+	assert.Equal(t, []int{800, 400}, []int{variant.Width, variant.Height}, "the dimensions of the original should be kept")
+}
+
+// TestMediaService_GetMediaVariant_WebP tests generating a missing responsive variant of a WebP image in the same format.
+func TestMediaService_GetMediaVariant_WebP(t *testing.T) {
+	t.Parallel()
+	c := createMediaServiceContext(t)
+
+	model := createMediaModel()
+	model.ContentType = "image/webp"
+	model.StorageKey = "media/2024/01/test.webp"
+	data, _, _ := imaging.Encode(image.NewRGBA(image.Rect(0, 0, 800, 400)), "image/webp")
+
+	gomock.InOrder(
+		c.mockMediaRepository.EXPECT().GetMedia(model.ID).Return(&model, nil),
+		c.mockStorage.EXPECT().Get(model.StorageKey).Return(io.NopCloser(bytes.NewReader(data)), nil),
+		c.mockStorage.EXPECT().Put("media/2024/01/test_thumbnail.webp", gomock.Any(), gomock.Any(), "image/webp").Return(nil),
+		c.mockMediaRepository.EXPECT().AddMediaVariant(gomock.Any()).Return(nil),
+	)
+
+	variant, err := c.sut.GetMediaVariant(model.ID, "thumbnail")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "image/webp", variant.ContentType, "variant should keep the format of the original")
+	assert.Equal(t, []int{320, 160}, []int{variant.Width, variant.Height}, "variant should be resized")
+}
+
+// TestMediaService_GetMediaVariant_Unknown tests getting a variant which isn't defined.
+func TestMediaService_GetMediaVariant_Unknown(t *testing.T) {
+	t.Parallel()
+	c := createMediaServiceContext(t)
+
+	expectedError := errortypes.MediaVariantNotFoundError{Media: types.Media{ID: 1}, Variant: "huge"}
+
+	_, err := c.sut.GetMediaVariant(1, "huge")
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
@@ -0,0 +1,456 @@
+package services
+
+import (
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/markdown"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/site"
+	"github.com/wlchs/blog/internal/types"
+	"html"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// micropubQueries are the queries supported by the Micropub endpoint.
+var micropubQueries = []string{"config", "source", "syndicate-to"}
+
+// maxMicropubSlugLength limits the length of the URL handles derived from the names of the posts.
+const maxMicropubSlugLength = 80
+
+// maxMicropubTitleLength limits the length of the titles derived from the content of notes.
+const maxMicropubTitleLength = 70
+
+// MicropubService interface. Maps the requests of Micropub clients onto the posts and the media of the blog.
+type MicropubService interface {
+	CreatePost(entry types.MicropubEntry, userName string) (string, error)
+	DeletePost(postURL string, userName string) error
+	GetConfig() types.MicropubConfig
+	GetSource(postURL string, properties []string, userName string) (types.MicropubEntry, error)
+	UpdatePost(update types.MicropubUpdate, userName string) error
+	UploadMedia(content io.Reader, fileName string, userName string) (types.Media, error)
+}
+
+// micropubService is the concrete implementation of the MicropubService interface.
+type micropubService struct {
+	cont         container.Container
+	postService  PostService
+	mediaService MediaService
+	site         site.Config
+}
+
+// CreateMicropubService instantiates the micropubService using the application container.
+// Posts are created, updated and deleted through the post service, so the same rules apply as for the JSON API.
+// The URLs returned to the clients are absolute if the site URL is set.
+func CreateMicropubService(cont container.Container, postService PostService, mediaService MediaService) MicropubService {
+	return &micropubService{cont, postService, mediaService, site.LoadConfig()}
+}
+
+// CreatePost creates a post of the given user from an h-entry and returns its URL.
+// The URL handle is taken from the mp-slug property, falling back to the name of the post and to the creation time.
+func (m micropubService) CreatePost(entry types.MicropubEntry, userName string) (string, error) {
+	log := m.cont.GetLogger()
+
+	if len(entry.Type) > 0 && entry.Type[0] != "h-entry" {
+		return "", errortypes.InvalidMicropubRequestError{Reason: "only h-entry posts are supported"}
+	}
+
+	post := types.Post{Author: userName}
+	if err := applyMicropubProperties(&post, entry.Properties); err != nil {
+		return "", err
+	}
+
+	slug := firstMicropubString(entry.Properties["mp-slug"])
+	urlHandle := slugify(slug)
+	if slug != "" && urlHandle == "" {
+		return "", errortypes.InvalidMicropubRequestError{Reason: "the slug must contain letters or digits"}
+	}
+	if urlHandle == "" {
+		urlHandle = slugify(firstMicropubString(entry.Properties["name"]))
+	}
+	if urlHandle == "" {
+		urlHandle = time.Now().UTC().Format("2006-01-02-150405")
+	}
+	post.URLHandle = urlHandle
+
+	log.Infof("creating post %s of user %s via Micropub", urlHandle, userName)
+
+	created, err := m.postService.AddPost(&post)
+	if err != nil {
+		return "", err
+	}
+
+	return m.postURL(created.URLHandle), nil
+}
+
+// DeletePost deletes the post with the given URL. Only the primary author of the post is allowed to delete it.
+func (m micropubService) DeletePost(postURL string, userName string) error {
+	urlHandle, err := m.postHandle(postURL)
+	if err != nil {
+		return err
+	}
+
+	return m.postService.DeletePost(urlHandle, userName)
+}
+
+// GetConfig returns the configuration of the Micropub endpoint. Posts can't be syndicated to other sites.
+func (m micropubService) GetConfig() types.MicropubConfig {
+	return types.MicropubConfig{
+		MediaEndpoint: m.site.BaseURL + site.MicropubMediaPath,
+		SyndicateTo:   []string{},
+		Q:             micropubQueries,
+	}
+}
+
+// GetSource returns the properties of the post with the given URL, so clients can edit them.
+// If property names are provided, only the given properties are returned, without the type of the post.
+// Non-public posts are only visible to their contributors.
+func (m micropubService) GetSource(postURL string, properties []string, userName string) (types.MicropubEntry, error) {
+	postRepository := m.cont.GetPostRepository()
+
+	urlHandle, err := m.postHandle(postURL)
+	if err != nil {
+		return types.MicropubEntry{}, err
+	}
+
+	post, err := postRepository.GetPost(urlHandle)
+	if err != nil {
+		return types.MicropubEntry{}, err
+	}
+
+	if post.Visibility != types.VisibilityPublic && !canReadPost(post, userName) {
+		return types.MicropubEntry{}, errortypes.PostNotFoundError{Post: types.Post{URLHandle: urlHandle}}
+	}
+
+	values := m.mapMicropubProperties(post)
+	if len(properties) == 0 {
+		return types.MicropubEntry{Type: []string{"h-entry"}, Properties: values}, nil
+	}
+
+	selected := make(map[string][]interface{}, len(properties))
+	for _, name := range properties {
+		if value, ok := values[name]; ok {
+			selected[name] = value
+		}
+	}
+	return types.MicropubEntry{Properties: selected}, nil
+}
+
+// UpdatePost replaces, adds and deletes the properties of the post with the given URL, in this order.
+// The post can be edited by its primary author and by its co-authors and editors.
+func (m micropubService) UpdatePost(update types.MicropubUpdate, userName string) error {
+	log := m.cont.GetLogger()
+	postRepository := m.cont.GetPostRepository()
+
+	urlHandle, err := m.postHandle(update.URL)
+	if err != nil {
+		return err
+	}
+
+	post, err := postRepository.GetPost(urlHandle)
+	if err != nil {
+		return err
+	}
+
+	if !canEditPost(post, userName) {
+		log.Debugf("user %s is not allowed to edit post %s", userName, urlHandle)
+		return errortypes.PostEditForbiddenError{Post: types.Post{URLHandle: urlHandle}, UserName: userName}
+	}
+
+	properties := m.mapMicropubProperties(post)
+	for name, values := range update.Replace {
+		properties[name] = values
+	}
+	for name, values := range update.Add {
+		properties[name] = append(properties[name], values...)
+	}
+	if err := deleteMicropubProperties(properties, update.Delete); err != nil {
+		return err
+	}
+
+	result := mapPost(post)
+	if err := applyMicropubProperties(&result, properties); err != nil {
+		return err
+	}
+
+	log.Infof("updating post %s of user %s via Micropub", urlHandle, userName)
+
+	_, err = m.postService.UpdatePost(&result, userName)
+	return err
+}
+
+// UploadMedia stores a file uploaded to the media endpoint and returns it with its absolute URL.
+func (m micropubService) UploadMedia(content io.Reader, fileName string, userName string) (types.Media, error) {
+	media, err := m.mediaService.UploadMedia(content, fileName, userName)
+	if err != nil {
+		return types.Media{}, err
+	}
+
+	media.URL = m.site.BaseURL + media.URL
+	return media, nil
+}
+
+// mapMicropubProperties maps a post to the properties of an h-entry. Private posts are reported as drafts.
+func (m micropubService) mapMicropubProperties(post *repository.Post) map[string][]interface{} {
+	properties := map[string][]interface{}{
+		"url":       {m.postURL(post.URLHandle)},
+		"published": {post.CreatedAt.UTC().Format(time.RFC3339)},
+		"updated":   {post.UpdatedAt.UTC().Format(time.RFC3339)},
+	}
+
+	if post.Title != "" {
+		properties["name"] = []interface{}{post.Title}
+	}
+	if post.Summary != "" {
+		properties["summary"] = []interface{}{post.Summary}
+	}
+	if post.Body != "" {
+		properties["content"] = []interface{}{map[string]interface{}{"html": markdown.Render(post.Body)}}
+	}
+	if len(post.Tags) > 0 {
+		tags := make([]interface{}, 0, len(post.Tags))
+		for _, tag := range post.Tags {
+			tags = append(tags, tag)
+		}
+		properties["category"] = tags
+	}
+	if post.CoverImage != "" {
+		properties["photo"] = []interface{}{post.CoverImage}
+	}
+
+	if post.Visibility == types.VisibilityPrivate {
+		properties["post-status"] = []interface{}{types.MicropubStatusDraft}
+	} else {
+		properties["post-status"] = []interface{}{types.MicropubStatusPublished}
+		properties["visibility"] = []interface{}{post.Visibility}
+	}
+
+	return properties
+}
+
+// postHandle extracts the URL handle from the URL of a post of the blog. Paths of posts are accepted as well.
+func (m micropubService) postHandle(postURL string) (string, error) {
+	invalid := errortypes.InvalidMicropubRequestError{Reason: "the URL isn't a post of this blog"}
+
+	path := postURL
+	if isAbsoluteURL(postURL) {
+		if m.site.BaseURL == "" || !strings.HasPrefix(postURL, m.site.BaseURL+"/") {
+			return "", invalid
+		}
+		path = strings.TrimPrefix(postURL, m.site.BaseURL)
+	}
+
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+
+	escaped, found := strings.CutPrefix(path, site.PostPath(""))
+	if !found || escaped == "" || strings.Contains(escaped, "/") {
+		return "", invalid
+	}
+
+	urlHandle, err := url.PathUnescape(escaped)
+	if err != nil {
+		return "", invalid
+	}
+
+	return urlHandle, nil
+}
+
+// postURL returns the URL of a post, which is absolute if the site URL is set.
+func (m micropubService) postURL(urlHandle string) string {
+	return m.site.BaseURL + site.PostPath(urlHandle)
+}
+
+// applyMicropubProperties sets the title, summary, body, tags, cover image and visibility of a post from the properties
+// of an h-entry. Plain text content is converted to paragraphs, notes without a name are titled after their content.
+// Other properties are ignored.
+func applyMicropubProperties(post *types.Post, properties map[string][]interface{}) error {
+	body, err := micropubContent(properties["content"])
+	if err != nil {
+		return err
+	}
+
+	post.Title = firstMicropubString(properties["name"])
+	post.Summary = firstMicropubString(properties["summary"])
+	post.Body = body
+
+	if post.Title == "" && post.Body == "" {
+		return errortypes.InvalidMicropubRequestError{Reason: "the post needs a name or content"}
+	}
+	if post.Title == "" {
+		post.Title = truncateWords(markdown.PlainText(post.Body), maxMicropubTitleLength)
+	}
+
+	post.Tags = []string{}
+	for _, category := range properties["category"] {
+		// Person tags are nested h-cards, which can't be stored as tags
+		if tag, ok := category.(string); ok && tag != "" && !containsString(post.Tags, tag) {
+			post.Tags = append(post.Tags, tag)
+		}
+	}
+
+	// Entries without a photo remove the current cover image
+	image := ""
+	if photos := properties["photo"]; len(photos) > 0 {
+		switch photo := photos[0].(type) {
+		case string:
+			image = photo
+		case map[string]interface{}:
+			image, _ = photo["value"].(string)
+		}
+	}
+	post.CoverImage = &image
+
+	switch status := firstMicropubString(properties["post-status"]); status {
+	case "", types.MicropubStatusPublished:
+		post.Visibility = firstMicropubString(properties["visibility"])
+		if post.Visibility == "" {
+			post.Visibility = types.VisibilityPublic
+		}
+	case types.MicropubStatusDraft:
+		post.Visibility = types.VisibilityPrivate
+	default:
+		return errortypes.InvalidMicropubRequestError{Reason: "unsupported post status \"" + status + "\""}
+	}
+
+	return nil
+}
+
+// deleteMicropubProperties deletes properties listed by name, or the given values of the properties.
+func deleteMicropubProperties(properties map[string][]interface{}, deleted interface{}) error {
+	switch deleted := deleted.(type) {
+	case nil:
+	case []interface{}:
+		for _, name := range deleted {
+			name, ok := name.(string)
+			if !ok {
+				return errortypes.InvalidMicropubRequestError{Reason: "the deleted properties must be listed by name"}
+			}
+			delete(properties, name)
+		}
+
+	case map[string]interface{}:
+		for name, values := range deleted {
+			values, ok := values.([]interface{})
+			if !ok {
+				return errortypes.InvalidMicropubRequestError{Reason: "the deleted values must be arrays"}
+			}
+
+			kept := make([]interface{}, 0, len(properties[name]))
+			for _, value := range properties[name] {
+				if !containsMicropubValue(values, value) {
+					kept = append(kept, value)
+				}
+			}
+			properties[name] = kept
+		}
+
+	default:
+		return errortypes.InvalidMicropubRequestError{Reason: "the deleted properties must be an array or an object"}
+	}
+
+	return nil
+}
+
+// micropubContent converts the content property of an h-entry to the body of a post.
+// HTML content is stored as it is, plain text is escaped and split into paragraphs.
+func micropubContent(values []interface{}) (string, error) {
+	if len(values) == 0 {
+		return "", nil
+	}
+
+	switch content := values[0].(type) {
+	case string:
+		return textToHTML(content), nil
+	case map[string]interface{}:
+		if fragment, ok := content["html"].(string); ok {
+			return fragment, nil
+		}
+		if text, ok := content["value"].(string); ok {
+			return textToHTML(text), nil
+		}
+	}
+
+	return "", errortypes.InvalidMicropubRequestError{Reason: "the content must be text or an object with an html or value field"}
+}
+
+// firstMicropubString returns the first value of a property if it's a string.
+func firstMicropubString(values []interface{}) string {
+	if len(values) == 0 {
+		return ""
+	}
+	value, _ := values[0].(string)
+	return strings.TrimSpace(value)
+}
+
+// containsMicropubValue checks whether the values of a property contain the given value.
+func containsMicropubValue(values []interface{}, value interface{}) bool {
+	for _, candidate := range values {
+		if reflect.DeepEqual(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// textToHTML escapes plain text and converts it to HTML paragraphs. Paragraphs are separated by blank lines, the other
+// line breaks are kept.
+func textToHTML(text string) string {
+	text = strings.ReplaceAll(strings.TrimSpace(text), "\r\n", "\n")
+	if text == "" {
+		return ""
+	}
+
+	paragraphs := make([]string, 0)
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+			lines := strings.Split(html.EscapeString(paragraph), "\n")
+			paragraphs = append(paragraphs, "<p>"+strings.Join(lines, "<br>\n")+"</p>")
+		}
+	}
+
+	return strings.Join(paragraphs, "\n")
+}
+
+// slugify derives a URL handle from a name. Letters are lowercased, runs of other characters are replaced by hyphens.
+func slugify(name string) string {
+	var b strings.Builder
+	hyphen := false
+
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+		} else {
+			hyphen = true
+		}
+
+		if b.Len() >= maxMicropubSlugLength {
+			break
+		}
+	}
+
+	return b.String()
+}
+
+// truncateWords shortens a text to at most the given number of characters, cutting it at a word boundary if possible.
+func truncateWords(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+
+	cut := string(runes[:limit-1])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
@@ -0,0 +1,278 @@
+package services_test
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/types"
+	"strings"
+	"testing"
+	"time"
+)
+
+// micropubTestContext contains objects relevant for testing the MicropubService.
+type micropubTestContext struct {
+	mockPostRepository *mocks.MockPostRepository
+	mockPostService    *mocks.MockPostService
+	mockMediaService   *mocks.MockMediaService
+	sut                services.MicropubService
+}
+
+// createMicropubServiceContext creates the context for testing the MicropubService and reduces code duplication.
+// The site URL is set in the environment, so the tests can't run in parallel.
+func createMicropubServiceContext(t *testing.T) *micropubTestContext {
+	t.Helper()
+	t.Setenv("SITE_URL", "https://blog.example.com")
+
+	mockCtrl := gomock.NewController(t)
+	mockPostRepository := mocks.NewMockPostRepository(mockCtrl)
+	mockPostService := mocks.NewMockPostService(mockCtrl)
+	mockMediaService := mocks.NewMockMediaService(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, mockPostRepository, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	sut := services.CreateMicropubService(cont, mockPostService, mockMediaService)
+
+	return &micropubTestContext{mockPostRepository, mockPostService, mockMediaService, sut}
+}
+
+// createMicropubPost creates a post edited in the tests, written by testAuthor.
+func createMicropubPost() *repository.Post {
+	return &repository.Post{
+		ID:         1,
+		URLHandle:  "hello",
+		Title:      "Hello",
+		Body:       "<p>Hello world!</p>",
+		Tags:       []string{"go", "indieweb"},
+		Visibility: types.VisibilityPublic,
+		Author:     repository.User{UserName: "testAuthor"},
+		CreatedAt:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		UpdatedAt:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+// TestMicropubService_CreatePost tests creating an article with a name, HTML content and categories.
+func TestMicropubService_CreatePost(t *testing.T) {
+	c := createMicropubServiceContext(t)
+
+	entry := types.MicropubEntry{
+		Type: []string{"h-entry"},
+		Properties: map[string][]interface{}{
+			"name":     {"Hello World!"},
+			"content":  {map[string]interface{}{"html": "<p>Hello <b>world</b></p>"}},
+			"category": {"go", "go", map[string]interface{}{"type": []interface{}{"h-card"}}},
+			"photo":    {map[string]interface{}{"value": "https://blog.example.com/media/1", "alt": "A photo"}},
+		},
+	}
+	image := "https://blog.example.com/media/1"
+	expectedPost := types.Post{
+		URLHandle:  "hello-world",
+		Title:      "Hello World!",
+		Author:     "testAuthor",
+		Body:       "<p>Hello <b>world</b></p>",
+		Tags:       []string{"go"},
+		CoverImage: &image,
+		Visibility: types.VisibilityPublic,
+	}
+
+	c.mockPostService.EXPECT().AddPost(&expectedPost).Return(expectedPost, nil)
+
+	location, err := c.sut.CreatePost(entry, "testAuthor")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "https://blog.example.com/posts/hello-world", location, "incorrect location of the post")
+}
+
+// TestMicropubService_CreatePost_Note tests creating a draft note from plain text, titled after its content.
+func TestMicropubService_CreatePost_Note(t *testing.T) {
+	c := createMicropubServiceContext(t)
+
+	entry := types.MicropubEntry{Properties: map[string][]interface{}{
+		"content":     {"Just a <short> note\nwith two lines.\n\nAnd a second paragraph."},
+		"mp-slug":     {"My Note"},
+		"post-status": {"draft"},
+	}}
+
+	var created types.Post
+	c.mockPostService.EXPECT().AddPost(gomock.Any()).DoAndReturn(func(post *types.Post) (types.Post, error) {
+		created = *post
+		return *post, nil
+	})
+
+	location, err := c.sut.CreatePost(entry, "testAuthor")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "https://blog.example.com/posts/my-note", location, "slug should be used as URL handle")
+	assert.Equal(t, "<p>Just a &lt;short&gt; note<br>\nwith two lines.</p>\n<p>And a second paragraph.</p>", created.Body, "text should be converted to paragraphs")
+	assert.Equal(t, "Just a <short> note with two lines. And a second paragraph.", created.Title, "title should be derived from the content")
+	assert.Equal(t, types.VisibilityPrivate, created.Visibility, "drafts should be private")
+}
+
+// TestMicropubService_CreatePost_Invalid tests creating posts from invalid entries.
+func TestMicropubService_CreatePost_Invalid(t *testing.T) {
+	c := createMicropubServiceContext(t)
+
+	entries := map[string]types.MicropubEntry{
+		"unsupported type": {Type: []string{"h-event"}, Properties: map[string][]interface{}{"name": {"Party"}}},
+		"empty":            {Properties: map[string][]interface{}{}},
+		"invalid content":  {Properties: map[string][]interface{}{"content": {42.0}}},
+		"invalid status":   {Properties: map[string][]interface{}{"name": {"Hello"}, "post-status": {"scheduled"}}},
+		"invalid slug":     {Properties: map[string][]interface{}{"name": {"Hello"}, "mp-slug": {"!!!"}}},
+	}
+
+	for name, entry := range entries {
+		_, err := c.sut.CreatePost(entry, "testAuthor")
+		assert.IsType(t, errortypes.InvalidMicropubRequestError{}, err, "%s entry should be rejected", name)
+	}
+}
+
+// TestMicropubService_UpdatePost tests replacing, adding and deleting the properties of a post.
+func TestMicropubService_UpdatePost(t *testing.T) {
+	c := createMicropubServiceContext(t)
+
+	update := types.MicropubUpdate{
+		URL:     "https://blog.example.com/posts/hello",
+		Replace: map[string][]interface{}{"content": {"Updated"}},
+		Add:     map[string][]interface{}{"category": {"micropub"}},
+		Delete:  map[string]interface{}{"category": []interface{}{"go"}},
+	}
+
+	var updated types.Post
+	c.mockPostRepository.EXPECT().GetPost("hello").Return(createMicropubPost(), nil)
+	c.mockPostService.EXPECT().UpdatePost(gomock.Any(), "testAuthor").DoAndReturn(func(post *types.Post, userName string) (types.Post, error) {
+		updated = *post
+		return *post, nil
+	})
+
+	err := c.sut.UpdatePost(update, "testAuthor")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "hello", updated.URLHandle, "incorrect post updated")
+	assert.Equal(t, "Hello", updated.Title, "name should be kept")
+	assert.Equal(t, "<p>Updated</p>", updated.Body, "content should be replaced")
+	assert.Equal(t, []string{"indieweb", "micropub"}, updated.Tags, "categories should be added and deleted")
+	assert.Equal(t, types.VisibilityPublic, updated.Visibility, "visibility should be kept")
+}
+
+// TestMicropubService_UpdatePost_Delete_Properties tests deleting every value of properties.
+func TestMicropubService_UpdatePost_Delete_Properties(t *testing.T) {
+	c := createMicropubServiceContext(t)
+
+	update := types.MicropubUpdate{URL: "/posts/hello", Delete: []interface{}{"category"}}
+
+	var updated types.Post
+	c.mockPostRepository.EXPECT().GetPost("hello").Return(createMicropubPost(), nil)
+	c.mockPostService.EXPECT().UpdatePost(gomock.Any(), "testAuthor").DoAndReturn(func(post *types.Post, userName string) (types.Post, error) {
+		updated = *post
+		return *post, nil
+	})
+
+	err := c.sut.UpdatePost(update, "testAuthor")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, []string{}, updated.Tags, "categories should be deleted")
+}
+
+// TestMicropubService_UpdatePost_Forbidden tests updating the post of another user.
+func TestMicropubService_UpdatePost_Forbidden(t *testing.T) {
+	c := createMicropubServiceContext(t)
+
+	c.mockPostRepository.EXPECT().GetPost("hello").Return(createMicropubPost(), nil)
+
+	err := c.sut.UpdatePost(types.MicropubUpdate{URL: "https://blog.example.com/posts/hello"}, "otherUser")
+
+	assert.IsType(t, errortypes.PostEditForbiddenError{}, err, "incorrect error type")
+}
+
+// TestMicropubService_UpdatePost_Foreign_URL tests updating a post with the URL of another site.
+func TestMicropubService_UpdatePost_Foreign_URL(t *testing.T) {
+	c := createMicropubServiceContext(t)
+
+	for _, postURL := range []string{"https://example.org/posts/hello", "https://blog.example.com/authors/jane", "/posts/a/b", ""} {
+		err := c.sut.UpdatePost(types.MicropubUpdate{URL: postURL}, "testAuthor")
+		assert.IsType(t, errortypes.InvalidMicropubRequestError{}, err, "%q should be rejected", postURL)
+	}
+}
+
+// TestMicropubService_DeletePost tests deleting a post by its URL.
+func TestMicropubService_DeletePost(t *testing.T) {
+	c := createMicropubServiceContext(t)
+
+	c.mockPostService.EXPECT().DeletePost("hello world", "testAuthor").Return(nil)
+
+	err := c.sut.DeletePost("https://blog.example.com/posts/hello%20world?lang=en", "testAuthor")
+
+	assert.Nil(t, err, "should complete without error")
+}
+
+// TestMicropubService_GetSource tests retrieving the properties of a post.
+func TestMicropubService_GetSource(t *testing.T) {
+	c := createMicropubServiceContext(t)
+
+	c.mockPostRepository.EXPECT().GetPost("hello").Return(createMicropubPost(), nil)
+
+	entry, err := c.sut.GetSource("https://blog.example.com/posts/hello", nil, "")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, []string{"h-entry"}, entry.Type, "incorrect type")
+	assert.Equal(t, []interface{}{"Hello"}, entry.Properties["name"], "incorrect name")
+	assert.Equal(t, []interface{}{map[string]interface{}{"html": "<p>Hello world!</p>"}}, entry.Properties["content"], "incorrect content")
+	assert.Equal(t, []interface{}{"go", "indieweb"}, entry.Properties["category"], "incorrect categories")
+	assert.Equal(t, []interface{}{"2024-01-02T03:04:05Z"}, entry.Properties["published"], "incorrect publication time")
+	assert.Equal(t, []interface{}{"published"}, entry.Properties["post-status"], "incorrect post status")
+}
+
+// TestMicropubService_GetSource_Properties tests retrieving selected properties of a draft by its author.
+func TestMicropubService_GetSource_Properties(t *testing.T) {
+	c := createMicropubServiceContext(t)
+
+	post := createMicropubPost()
+	post.Visibility = types.VisibilityPrivate
+	c.mockPostRepository.EXPECT().GetPost("hello").Return(post, nil)
+
+	entry, err := c.sut.GetSource("https://blog.example.com/posts/hello", []string{"name", "post-status", "photo"}, "testAuthor")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Nil(t, entry.Type, "type should be omitted")
+	assert.Equal(t, map[string][]interface{}{"name": {"Hello"}, "post-status": {"draft"}}, entry.Properties, "incorrect properties")
+}
+
+// TestMicropubService_GetSource_Private tests retrieving the properties of a draft of another user.
+func TestMicropubService_GetSource_Private(t *testing.T) {
+	c := createMicropubServiceContext(t)
+
+	post := createMicropubPost()
+	post.Visibility = types.VisibilityPrivate
+	c.mockPostRepository.EXPECT().GetPost("hello").Return(post, nil)
+
+	_, err := c.sut.GetSource("https://blog.example.com/posts/hello", nil, "otherUser")
+
+	assert.IsType(t, errortypes.PostNotFoundError{}, err, "incorrect error type")
+}
+
+// TestMicropubService_GetConfig tests retrieving the configuration of the Micropub endpoint.
+func TestMicropubService_GetConfig(t *testing.T) {
+	c := createMicropubServiceContext(t)
+
+	config := c.sut.GetConfig()
+
+	assert.Equal(t, "https://blog.example.com/micropub/media", config.MediaEndpoint, "incorrect media endpoint")
+	assert.Equal(t, []string{}, config.SyndicateTo, "no syndication targets should be provided")
+	assert.Contains(t, config.Q, "source", "source query should be supported")
+}
+
+// TestMicropubService_UploadMedia tests uploading a file to the media endpoint.
+func TestMicropubService_UploadMedia(t *testing.T) {
+	c := createMicropubServiceContext(t)
+
+	content := strings.NewReader("image")
+	c.mockMediaService.EXPECT().UploadMedia(content, "photo.jpg", "testAuthor").Return(types.Media{ID: 1, URL: "/media/1"}, nil)
+
+	media, err := c.sut.UploadMedia(content, "photo.jpg", "testAuthor")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "https://blog.example.com/media/1", media.URL, "URL should be absolute")
+}
@@ -0,0 +1,369 @@
+package services
+
+import (
+	"fmt"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/mailer"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/site"
+	"github.com/wlchs/blog/internal/types"
+	"net/mail"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// newsletterPostMaxAge limits the age of the posts announced by the newsletter.
+// Older posts becoming public, e.g. after changing their visibility, aren't announced anymore.
+const newsletterPostMaxAge = 7 * 24 * time.Hour
+
+// NewsletterService interface. Defines the subscriptions to the newsletter and the delivery of its emails.
+type NewsletterService interface {
+	ConfirmSubscription(token string) error
+	RunNewsletters(stop <-chan struct{})
+	SendNewsletters() (int, error)
+	Subscribe(input *types.SubscriptionInput) error
+	Unsubscribe(token string) error
+}
+
+// newsletterService is the concrete implementation of the NewsletterService interface.
+type newsletterService struct {
+	cont   container.Container
+	site   site.Config
+	config newsletterConfig
+}
+
+// newsletterConfig contains the delivery settings of the newsletter.
+type newsletterConfig struct {
+	DigestInterval time.Duration
+	PollInterval   time.Duration
+}
+
+// CreateNewsletterService instantiates the newsletterService using the application container.
+// The delivery settings are read from the environment, the links of the emails point to the site URL.
+// The service subscribes to the post events of the event bus to record the published posts.
+func CreateNewsletterService(cont container.Container) NewsletterService {
+	n := &newsletterService{cont, site.LoadConfig(), loadNewsletterConfig()}
+
+	if n.site.BaseURL == "" {
+		cont.GetLogger().Warn("SITE_URL is not set, the links of the newsletter emails won't be absolute")
+	}
+
+	eventBus := cont.GetEventBus()
+	for _, event := range []string{types.EventPostCreated, types.EventPostUpdated} {
+		eventBus.Subscribe(event, n.recordPost)
+	}
+
+	return n
+}
+
+// loadNewsletterConfig reads the delivery settings of the newsletter from the NEWSLETTER_* environment variables.
+func loadNewsletterConfig() newsletterConfig {
+	config := newsletterConfig{
+		DigestInterval: 7 * 24 * time.Hour,
+		PollInterval:   time.Minute,
+	}
+
+	if interval, err := time.ParseDuration(os.Getenv("NEWSLETTER_DIGEST_INTERVAL")); err == nil && interval > 0 {
+		config.DigestInterval = interval
+	}
+	if interval, err := time.ParseDuration(os.Getenv("NEWSLETTER_POLL_INTERVAL")); err == nil && interval > 0 {
+		config.PollInterval = interval
+	}
+
+	return config
+}
+
+// ConfirmSubscription confirms the email address of a subscriber using the token of the confirmation email.
+// Confirming a subscription again has no effect.
+func (n newsletterService) ConfirmSubscription(token string) error {
+	log := n.cont.GetLogger()
+	newsletterRepository := n.cont.GetNewsletterRepository()
+
+	email, err := n.cont.GetJWTUtils().ParseSubscriptionJWT(token)
+	if err != nil {
+		log.Debugf("invalid subscription token: %v", err)
+		return errortypes.InvalidSubscriptionTokenError{}
+	}
+
+	subscriber, err := newsletterRepository.GetSubscriber(email)
+	if err != nil {
+		return err
+	}
+	if subscriber.Confirmed {
+		return nil
+	}
+
+	now := time.Now()
+	subscriber.Confirmed = true
+	subscriber.ConfirmedAt = &now
+
+	log.Infof("confirming newsletter subscription %d", subscriber.ID)
+	return newsletterRepository.UpdateSubscriber(subscriber)
+}
+
+// RunNewsletters sends the newsletter emails periodically until the stop channel is closed.
+func (n newsletterService) RunNewsletters(stop <-chan struct{}) {
+	ticker := time.NewTicker(n.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if _, err := n.SendNewsletters(); err != nil {
+				n.cont.GetLogger().Errorf("failed to send newsletters: %v", err)
+			}
+		}
+	}
+}
+
+// SendNewsletters announces the recorded posts to the per-post subscribers and sends the due digests,
+// and returns the number of sent emails. Emails which can't be sent are skipped, failed digests are retried later.
+func (n newsletterService) SendNewsletters() (int, error) {
+	sent, err := n.sendAnnouncements()
+	if err != nil {
+		return sent, err
+	}
+
+	digests, err := n.sendDigests(time.Now())
+	return sent + digests, err
+}
+
+// Subscribe adds a new subscriber and sends the email to confirm the address. Subscribing an unconfirmed address
+// again updates its delivery mode and resends the confirmation, subscribing a confirmed address has no effect,
+// so the response doesn't reveal whether an address is subscribed.
+func (n newsletterService) Subscribe(input *types.SubscriptionInput) error {
+	log := n.cont.GetLogger()
+	newsletterRepository := n.cont.GetNewsletterRepository()
+
+	email, err := normalizeEmail(input.Email)
+	if err != nil {
+		log.Debugf("invalid subscription of %q: %v", input.Email, err)
+		return err
+	}
+
+	mode := input.Mode
+	if mode == "" {
+		mode = types.NewsletterPerPost
+	}
+	if !types.IsValidNewsletterMode(mode) {
+		return errortypes.InvalidSubscriptionError{Reason: fmt.Sprintf("unknown mode %q", mode)}
+	}
+
+	subscriber, err := newsletterRepository.GetSubscriber(email)
+	switch err.(type) {
+	case nil:
+		if subscriber.Confirmed {
+			log.Debugf("subscriber %d is already confirmed", subscriber.ID)
+			return nil
+		}
+		subscriber.Mode = mode
+		if err := newsletterRepository.UpdateSubscriber(subscriber); err != nil {
+			return err
+		}
+	case errortypes.SubscriberNotFoundError:
+		subscriber = &repository.Subscriber{Email: email, Mode: mode}
+		if err := newsletterRepository.AddSubscriber(subscriber); err != nil {
+			return err
+		}
+	default:
+		return err
+	}
+
+	log.Infof("sending newsletter confirmation to subscriber %d", subscriber.ID)
+	return n.sendConfirmation(email)
+}
+
+// Unsubscribe removes the subscriber identified by the token of an unsubscribe link.
+// Unsubscribing an address which isn't subscribed anymore succeeds, so the links can be used more than once.
+func (n newsletterService) Unsubscribe(token string) error {
+	log := n.cont.GetLogger()
+	newsletterRepository := n.cont.GetNewsletterRepository()
+
+	email, err := n.cont.GetJWTUtils().ParseUnsubscribeJWT(token)
+	if err != nil {
+		log.Debugf("invalid unsubscribe token: %v", err)
+		return errortypes.InvalidSubscriptionTokenError{}
+	}
+
+	log.Infof("unsubscribing %s from the newsletter", email)
+	return newsletterRepository.DeleteSubscriber(email)
+}
+
+// recordPost records a public post to be announced by the newsletter.
+// Recording a post again has no effect, so the events of later updates don't announce it twice.
+func (n newsletterService) recordPost(event types.DomainEvent) error {
+	var post types.Post
+	switch e := event.(type) {
+	case types.PostCreatedEvent:
+		post = e.Post
+	case types.PostUpdatedEvent:
+		post = e.Post
+	default:
+		return nil
+	}
+
+	if post.Visibility != types.VisibilityPublic || time.Since(post.CreationTime) > newsletterPostMaxAge {
+		return nil
+	}
+
+	return n.cont.GetNewsletterRepository().AddNewsletterPost(&repository.NewsletterPost{
+		URLHandle: post.URLHandle,
+		Title:     post.Title,
+		Summary:   post.Summary,
+	})
+}
+
+// sendAnnouncements sends an email about every post which hasn't been announced yet to the per-post subscribers.
+func (n newsletterService) sendAnnouncements() (int, error) {
+	log := n.cont.GetLogger()
+	newsletterRepository := n.cont.GetNewsletterRepository()
+
+	posts, err := newsletterRepository.GetPendingNewsletterPosts()
+	if err != nil || len(posts) == 0 {
+		return 0, err
+	}
+
+	subscribers, err := newsletterRepository.GetConfirmedSubscribers(types.NewsletterPerPost)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	ids := make([]uint, 0, len(posts))
+	for _, post := range posts {
+		for _, subscriber := range subscribers {
+			body := fmt.Sprintf("%s\n\n%s\n\nRead the post: %s\n", post.Title, post.Summary, n.postURL(post.URLHandle))
+			if err := n.send(subscriber.Email, post.Title, body); err != nil {
+				log.Warnf("failed to announce post %s to subscriber %d: %v", post.URLHandle, subscriber.ID, err)
+				continue
+			}
+			sent++
+		}
+		ids = append(ids, post.ID)
+	}
+
+	log.Infof("announced %d posts in %d emails", len(posts), sent)
+	return sent, newsletterRepository.SetAnnounced(ids)
+}
+
+// sendDigests sends the posts recorded since the last digest to the digest subscribers whose digest is due.
+func (n newsletterService) sendDigests(now time.Time) (int, error) {
+	log := n.cont.GetLogger()
+	newsletterRepository := n.cont.GetNewsletterRepository()
+
+	subscribers, err := newsletterRepository.GetConfirmedSubscribers(types.NewsletterDigest)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range subscribers {
+		subscriber := &subscribers[i]
+
+		since := subscriber.CreatedAt
+		if subscriber.LastDigestAt != nil {
+			since = *subscriber.LastDigestAt
+		} else if subscriber.ConfirmedAt != nil {
+			since = *subscriber.ConfirmedAt
+		}
+		if now.Sub(since) < n.config.DigestInterval {
+			continue
+		}
+
+		posts, err := newsletterRepository.GetNewsletterPostsSince(since)
+		if err != nil {
+			return sent, err
+		}
+
+		// Digests without posts are skipped, the next one is due after another interval
+		if len(posts) > 0 {
+			subject := fmt.Sprintf("%s: %d new posts", n.site.Title, len(posts))
+			if err := n.send(subscriber.Email, subject, n.digestBody(posts)); err != nil {
+				log.Warnf("failed to send digest to subscriber %d: %v", subscriber.ID, err)
+				continue
+			}
+			sent++
+		}
+
+		subscriber.LastDigestAt = &now
+		if err := newsletterRepository.UpdateSubscriber(subscriber); err != nil {
+			return sent, err
+		}
+	}
+
+	return sent, nil
+}
+
+// sendConfirmation sends the email containing the link to confirm a subscription.
+func (n newsletterService) sendConfirmation(email string) error {
+	log := n.cont.GetLogger()
+
+	token, err := n.cont.GetJWTUtils().GenerateSubscriptionJWT(email)
+	if err != nil {
+		log.Errorf("failed to generate subscription token: %v", err)
+		return err
+	}
+
+	link := n.site.BaseURL + "/subscribe/confirm?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Please confirm your subscription to %s by opening the following link:\n\n%s\n\n"+
+		"If you didn't subscribe, you can ignore this email.\n", n.site.Title, link)
+
+	return n.cont.GetMailer().Send(mailer.Message{
+		To:      email,
+		Subject: fmt.Sprintf("Confirm your subscription to %s", n.site.Title),
+		Body:    body,
+	})
+}
+
+// send sends a newsletter email with the one-click unsubscribe link of the recipient.
+func (n newsletterService) send(email string, subject string, body string) error {
+	token, err := n.cont.GetJWTUtils().GenerateUnsubscribeJWT(email)
+	if err != nil {
+		return err
+	}
+
+	link := n.site.BaseURL + "/unsubscribe?token=" + url.QueryEscape(token)
+	footer := fmt.Sprintf("\n--\nYou receive this email because you subscribed to %s.\nUnsubscribe: %s\n", n.site.Title, link)
+
+	return n.cont.GetMailer().Send(mailer.Message{
+		To:      email,
+		Subject: subject,
+		Body:    body + footer,
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<" + link + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	})
+}
+
+// digestBody lists the posts of a digest with their summary and link.
+func (n newsletterService) digestBody(posts []repository.NewsletterPost) string {
+	var b strings.Builder
+	for _, post := range posts {
+		fmt.Fprintf(&b, "%s\n%s\n", post.Title, n.postURL(post.URLHandle))
+		if post.Summary != "" {
+			fmt.Fprintf(&b, "%s\n", post.Summary)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// postURL returns the absolute URL of a post.
+func (n newsletterService) postURL(urlHandle string) string {
+	return n.site.BaseURL + site.PostPath(urlHandle)
+}
+
+// normalizeEmail validates an email address and returns it in lowercase without a display name.
+func normalizeEmail(email string) (string, error) {
+	address, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil || address.Name != "" {
+		return "", errortypes.InvalidSubscriptionError{Reason: "invalid email address"}
+	}
+	return strings.ToLower(address.Address), nil
+}
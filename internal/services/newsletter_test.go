@@ -0,0 +1,259 @@
+package services_test
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/events"
+	"github.com/wlchs/blog/internal/jwt"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mailer"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/types"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newsletterTestContext contains objects relevant for testing the NewsletterService.
+type newsletterTestContext struct {
+	mockNewsletterRepository *mocks.MockNewsletterRepository
+	outbox                   *mailer.Outbox
+	handlers                 map[string]events.Handler
+	sut                      services.NewsletterService
+}
+
+// createNewsletterServiceContext creates the context for testing the NewsletterService and reduces code duplication.
+// The emails are stored in a local outbox, the tokens of their links are signed by the actual token utils.
+func createNewsletterServiceContext(t *testing.T) *newsletterTestContext {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	mockNewsletterRepository := mocks.NewMockNewsletterRepository(mockCtrl)
+	mockEventBus := mocks.NewMockBus(mockCtrl)
+	log := logger.CreateLogger()
+	outbox := mailer.CreateOutbox(log)
+	cont := container.CreateContainer(log, nil, nil, nil, nil, nil, mockNewsletterRepository, nil, nil, nil, nil, nil, nil, nil, jwt.CreateTokenUtils(log), nil, mockEventBus, outbox)
+
+	handlers := map[string]events.Handler{}
+	mockEventBus.EXPECT().Subscribe(gomock.Any(), gomock.Any()).Times(2).Do(func(event string, handler events.Handler) {
+		handlers[event] = handler
+	})
+	sut := services.CreateNewsletterService(cont)
+
+	return &newsletterTestContext{mockNewsletterRepository, outbox, handlers, sut}
+}
+
+// linkToken extracts the token of the link with the given path from an email body.
+func linkToken(t *testing.T, body string, path string) string {
+	t.Helper()
+
+	start := strings.Index(body, path+"?token=")
+	if start < 0 {
+		t.Fatalf("link %s not found in %q", path, body)
+	}
+	link, _, _ := strings.Cut(body[start:], "\n")
+	u, _ := url.Parse(link)
+	return u.Query().Get("token")
+}
+
+// TestNewsletterService_Subscribe tests subscribing a new email address.
+func TestNewsletterService_Subscribe(t *testing.T) {
+	t.Parallel()
+	c := createNewsletterServiceContext(t)
+
+	input := types.SubscriptionInput{Email: " Reader@Example.com "}
+
+	c.mockNewsletterRepository.EXPECT().GetSubscriber("reader@example.com").Return(nil, errortypes.SubscriberNotFoundError{Email: "reader@example.com"})
+	c.mockNewsletterRepository.EXPECT().AddSubscriber(gomock.Any()).DoAndReturn(func(s *repository.Subscriber) error {
+		assert.Equal(t, "reader@example.com", s.Email, "email address should be normalized")
+		assert.Equal(t, types.NewsletterPerPost, s.Mode, "per-post emails should be the default mode")
+		assert.False(t, s.Confirmed, "subscriber shouldn't be confirmed")
+		return nil
+	})
+
+	err := c.sut.Subscribe(&input)
+
+	assert.Nil(t, err, "should complete without error")
+
+	messages := c.outbox.Messages()
+	assert.Len(t, messages, 1, "a confirmation email should be sent")
+	assert.Equal(t, "reader@example.com", messages[0].To, "incorrect recipient")
+	assert.NotEmpty(t, linkToken(t, messages[0].Body, "/subscribe/confirm"), "confirmation link should contain a token")
+}
+
+// TestNewsletterService_Subscribe_Confirmed tests subscribing an address which is already confirmed.
+func TestNewsletterService_Subscribe_Confirmed(t *testing.T) {
+	t.Parallel()
+	c := createNewsletterServiceContext(t)
+
+	subscriber := repository.Subscriber{ID: 1, Email: "reader@example.com", Mode: types.NewsletterPerPost, Confirmed: true}
+
+	c.mockNewsletterRepository.EXPECT().GetSubscriber(subscriber.Email).Return(&subscriber, nil)
+
+	err := c.sut.Subscribe(&types.SubscriptionInput{Email: subscriber.Email, Mode: types.NewsletterDigest})
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Empty(t, c.outbox.Messages(), "no email should be sent")
+}
+
+// TestNewsletterService_Subscribe_Pending tests resending the confirmation of an unconfirmed subscription.
+func TestNewsletterService_Subscribe_Pending(t *testing.T) {
+	t.Parallel()
+	c := createNewsletterServiceContext(t)
+
+	subscriber := repository.Subscriber{ID: 1, Email: "reader@example.com", Mode: types.NewsletterPerPost}
+
+	c.mockNewsletterRepository.EXPECT().GetSubscriber(subscriber.Email).Return(&subscriber, nil)
+	c.mockNewsletterRepository.EXPECT().UpdateSubscriber(gomock.Any()).DoAndReturn(func(s *repository.Subscriber) error {
+		assert.Equal(t, types.NewsletterDigest, s.Mode, "mode should be updated")
+		return nil
+	})
+
+	err := c.sut.Subscribe(&types.SubscriptionInput{Email: subscriber.Email, Mode: types.NewsletterDigest})
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Len(t, c.outbox.Messages(), 1, "confirmation email should be sent again")
+}
+
+// TestNewsletterService_Subscribe_Invalid tests subscribing with invalid input.
+func TestNewsletterService_Subscribe_Invalid(t *testing.T) {
+	t.Parallel()
+	c := createNewsletterServiceContext(t)
+
+	inputs := []types.SubscriptionInput{
+		{Email: ""},
+		{Email: "reader"},
+		{Email: "Reader <reader@example.com>"},
+		{Email: "reader@example.com", Mode: "weekly"},
+	}
+
+	for _, input := range inputs {
+		err := c.sut.Subscribe(&input)
+		assert.IsType(t, errortypes.InvalidSubscriptionError{}, err, "incorrect error type for %v", input)
+	}
+	assert.Empty(t, c.outbox.Messages(), "no email should be sent")
+}
+
+// TestNewsletterService_ConfirmSubscription tests confirming a subscription using the link of the confirmation email.
+func TestNewsletterService_ConfirmSubscription(t *testing.T) {
+	t.Parallel()
+	c := createNewsletterServiceContext(t)
+
+	subscriber := repository.Subscriber{ID: 1, Email: "reader@example.com", Mode: types.NewsletterPerPost}
+
+	c.mockNewsletterRepository.EXPECT().GetSubscriber(subscriber.Email).Return(&subscriber, nil).Times(2)
+	c.mockNewsletterRepository.EXPECT().UpdateSubscriber(gomock.Any()).Return(nil).Times(2)
+
+	_ = c.sut.Subscribe(&types.SubscriptionInput{Email: subscriber.Email})
+	token := linkToken(t, c.outbox.Messages()[0].Body, "/subscribe/confirm")
+
+	err := c.sut.ConfirmSubscription(token)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.True(t, subscriber.Confirmed, "subscriber should be confirmed")
+	assert.NotNil(t, subscriber.ConfirmedAt, "confirmation time should be set")
+}
+
+// TestNewsletterService_ConfirmSubscription_Invalid_Token tests confirming a subscription with an invalid token.
+func TestNewsletterService_ConfirmSubscription_Invalid_Token(t *testing.T) {
+	t.Parallel()
+	c := createNewsletterServiceContext(t)
+
+	unsubscribeToken, _ := jwt.CreateTokenUtils(logger.CreateLogger()).GenerateUnsubscribeJWT("reader@example.com")
+
+	for _, token := range []string{"", "invalid", unsubscribeToken} {
+		err := c.sut.ConfirmSubscription(token)
+		assert.Equal(t, errortypes.InvalidSubscriptionTokenError{}, err, "incorrect error type")
+	}
+}
+
+// TestNewsletterService_Unsubscribe tests unsubscribing using the unsubscribe token of the newsletter emails.
+func TestNewsletterService_Unsubscribe(t *testing.T) {
+	t.Parallel()
+	c := createNewsletterServiceContext(t)
+
+	token, _ := jwt.CreateTokenUtils(logger.CreateLogger()).GenerateUnsubscribeJWT("reader@example.com")
+
+	c.mockNewsletterRepository.EXPECT().DeleteSubscriber("reader@example.com").Return(nil)
+
+	err := c.sut.Unsubscribe(token)
+
+	assert.Nil(t, err, "should complete without error")
+}
+
+// TestNewsletterService_RecordPost tests recording the recent public posts for the newsletter.
+func TestNewsletterService_RecordPost(t *testing.T) {
+	t.Parallel()
+	c := createNewsletterServiceContext(t)
+
+	recent := types.Post{URLHandle: "recent", Title: "Recent", Visibility: types.VisibilityPublic, CreationTime: time.Now()}
+	private := types.Post{URLHandle: "private", Visibility: types.VisibilityPrivate, CreationTime: time.Now()}
+	old := types.Post{URLHandle: "old", Visibility: types.VisibilityPublic, CreationTime: time.Now().AddDate(-1, 0, 0)}
+
+	c.mockNewsletterRepository.EXPECT().AddNewsletterPost(&repository.NewsletterPost{URLHandle: "recent", Title: "Recent"}).Return(nil)
+
+	assert.Nil(t, c.handlers[types.EventPostCreated](types.PostCreatedEvent{Post: recent}), "should complete without error")
+	assert.Nil(t, c.handlers[types.EventPostCreated](types.PostCreatedEvent{Post: private}), "should complete without error")
+	assert.Nil(t, c.handlers[types.EventPostUpdated](types.PostUpdatedEvent{Post: old}), "should complete without error")
+}
+
+// TestNewsletterService_SendNewsletters tests announcing a post and sending a due digest.
+func TestNewsletterService_SendNewsletters(t *testing.T) {
+	t.Parallel()
+	c := createNewsletterServiceContext(t)
+
+	lastDigest := time.Now().AddDate(0, 0, -8)
+	post := repository.NewsletterPost{ID: 1, URLHandle: "testUrlHandle", Title: "Test post", Summary: "Summary"}
+	perPost := repository.Subscriber{ID: 1, Email: "post@example.com", Mode: types.NewsletterPerPost, Confirmed: true}
+	digest := repository.Subscriber{ID: 2, Email: "digest@example.com", Mode: types.NewsletterDigest, Confirmed: true, LastDigestAt: &lastDigest}
+
+	c.mockNewsletterRepository.EXPECT().GetPendingNewsletterPosts().Return([]repository.NewsletterPost{post}, nil)
+	c.mockNewsletterRepository.EXPECT().GetConfirmedSubscribers(types.NewsletterPerPost).Return([]repository.Subscriber{perPost}, nil)
+	c.mockNewsletterRepository.EXPECT().SetAnnounced([]uint{1}).Return(nil)
+	c.mockNewsletterRepository.EXPECT().GetConfirmedSubscribers(types.NewsletterDigest).Return([]repository.Subscriber{digest}, nil)
+	c.mockNewsletterRepository.EXPECT().GetNewsletterPostsSince(lastDigest).Return([]repository.NewsletterPost{post}, nil)
+	c.mockNewsletterRepository.EXPECT().UpdateSubscriber(gomock.Any()).DoAndReturn(func(s *repository.Subscriber) error {
+		assert.True(t, s.LastDigestAt.After(lastDigest), "digest time should be updated")
+		return nil
+	})
+
+	sent, err := c.sut.SendNewsletters()
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 2, sent, "incorrect number of sent emails")
+
+	messages := c.outbox.Messages()
+	assert.Equal(t, perPost.Email, messages[0].To, "post should be announced first")
+	assert.Equal(t, post.Title, messages[0].Subject, "incorrect subject")
+	assert.Contains(t, messages[0].Body, "/posts/testUrlHandle", "email should link the post")
+	assert.Equal(t, digest.Email, messages[1].To, "digest should be sent")
+	assert.Contains(t, messages[1].Body, "/posts/testUrlHandle", "digest should link the post")
+
+	for _, message := range messages {
+		token := linkToken(t, message.Body, "/unsubscribe")
+		assert.Equal(t, "<"+"/unsubscribe?token="+url.QueryEscape(token)+">", message.Headers["List-Unsubscribe"], "incorrect unsubscribe header")
+		assert.Equal(t, "List-Unsubscribe=One-Click", message.Headers["List-Unsubscribe-Post"], "one-click unsubscribe should be supported")
+	}
+}
+
+// TestNewsletterService_SendNewsletters_Digest_Not_Due tests skipping digests whose interval hasn't passed yet.
+func TestNewsletterService_SendNewsletters_Digest_Not_Due(t *testing.T) {
+	t.Parallel()
+	c := createNewsletterServiceContext(t)
+
+	lastDigest := time.Now().Add(-time.Hour)
+	digest := repository.Subscriber{ID: 2, Email: "digest@example.com", Mode: types.NewsletterDigest, Confirmed: true, LastDigestAt: &lastDigest}
+
+	c.mockNewsletterRepository.EXPECT().GetPendingNewsletterPosts().Return([]repository.NewsletterPost{}, nil)
+	c.mockNewsletterRepository.EXPECT().GetConfirmedSubscribers(types.NewsletterDigest).Return([]repository.Subscriber{digest}, nil)
+
+	sent, err := c.sut.SendNewsletters()
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 0, sent, "no emails should be sent")
+}
@@ -1,29 +1,80 @@
 package services
 
 import (
+	"fmt"
+	"github.com/wlchs/blog/internal/auth"
 	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/i18n"
+	"github.com/wlchs/blog/internal/markdown"
 	"github.com/wlchs/blog/internal/repository"
 	"github.com/wlchs/blog/internal/types"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 // PostService interface. Defines post-related business logic.
 type PostService interface {
 	AddPost(newPost *types.Post) (types.Post, error)
-	GetPost(id string) (types.Post, error)
-	GetPosts() ([]types.Post, error)
+	AuthorizePostAccess(urlHandle string, password string) (string, error)
+	DeletePost(urlHandle string, userName string) error
+	DeletePostTranslation(urlHandle string, language string, userName string) error
+	GetAllPosts() ([]types.Post, error)
+	GetPost(id string, access types.PostAccess, languages []string) (types.Post, error)
+	GetPosts(filter types.PostFilter) ([]types.Post, error)
+	SetPostContributors(urlHandle string, contributors []types.Contributor, userName string) (types.Post, error)
+	SetPostHighlight(urlHandle string, highlight types.PostHighlight, userName string) (types.Post, error)
+	SetPostTranslation(urlHandle string, translation types.PostTranslation, userName string) (types.Post, error)
+	UpdatePost(post *types.Post, userName string) (types.Post, error)
 }
 
 // postService is the concrete implementation of the PostService interface.
 type postService struct {
-	cont container.Container
+	cont   container.Container
+	config postConfig
+}
+
+// postConfig contains the reading speed used to estimate the reading time of the posts and the length of the excerpts.
+type postConfig struct {
+	WordsPerMinute int
+	ExcerptLength  int
+}
+
+// contentStats contains the statistics computed from the body of a post or of a translation.
+type contentStats struct {
+	WordCount   int
+	ReadingTime int
+	Excerpt     string
 }
 
 // CreatePostService instantiates the postService using the application container.
 func CreatePostService(cont container.Container) PostService {
-	return &postService{cont}
+	return &postService{cont, loadPostConfig()}
+}
+
+// loadPostConfig reads the settings of the content statistics from the POST_* environment variables.
+func loadPostConfig() postConfig {
+	config := postConfig{
+		WordsPerMinute: 200,
+		ExcerptLength:  200,
+	}
+
+	if words, err := strconv.Atoi(os.Getenv("POST_WORDS_PER_MINUTE")); err == nil && words > 0 {
+		config.WordsPerMinute = words
+	}
+	if length, err := strconv.Atoi(os.Getenv("POST_EXCERPT_LENGTH")); err == nil && length > 0 {
+		config.ExcerptLength = length
+	}
+
+	return config
 }
 
 // AddPost adds a new post to the blog.
+// The creation time of the post is preserved if provided, so imported posts keep their original publication date.
 func (p postService) AddPost(newPost *types.Post) (types.Post, error) {
 	log := p.cont.GetLogger()
 	postRepository := p.cont.GetPostRepository()
@@ -32,28 +83,761 @@ func (p postService) AddPost(newPost *types.Post) (types.Post, error) {
 	// Get post author
 	author, err := userRepository.GetUser(newPost.Author)
 	if err != nil {
-		log.Errorf("failed to get author for post %v with username %s", newPost, newPost.Author)
+		log.Errorf("failed to get author for post %s with username %s", newPost.URLHandle, newPost.Author)
 		return types.Post{}, err
 	}
 
-	log.Infof("adding new post %v with author %s", newPost, newPost.Author)
+	contributors, err := p.resolveContributors(newPost.Contributors, author)
+	if err != nil {
+		log.Errorf("failed to resolve contributors of post %s: %v", newPost.URLHandle, err)
+		return types.Post{}, err
+	}
+
+	if err := preparePostVisibility(newPost, nil); err != nil {
+		log.Debugf("invalid visibility settings for post %s: %v", newPost.URLHandle, err)
+		return types.Post{}, err
+	}
+
+	if newPost.Language == "" {
+		newPost.Language = i18n.DefaultLanguage
+	}
+	language, ok := i18n.NormalizeTag(newPost.Language)
+	if !ok {
+		return types.Post{}, errortypes.InvalidLanguageError{Language: newPost.Language}
+	}
+	newPost.Language = language
+
+	if err := p.checkCustomFields(newPost.CustomFields); err != nil {
+		log.Debugf("invalid custom fields for post %s: %v", newPost.URLHandle, err)
+		return types.Post{}, err
+	}
+
+	if err := checkPostSEO(newPost.SEO); err != nil {
+		log.Debugf("invalid search engine settings for post %s: %v", newPost.URLHandle, err)
+		return types.Post{}, err
+	}
+
+	if err := checkCoverImage(newPost.CoverImage); err != nil {
+		log.Debugf("invalid cover image for post %s: %v", newPost.URLHandle, err)
+		return types.Post{}, err
+	}
+
+	p.prepareContentStats(newPost)
+
+	log.Infof("adding new post %s with author %s", newPost.URLHandle, newPost.Author)
+
+	// The stored post only references the author and the contributors by their IDs
+	withUsers := func(post *repository.Post) *repository.Post {
+		post.Author = *author
+		post.Contributors = contributors
+		return post
+	}
+
+	post, err := postRepository.AddPost(newPost, author.ID, contributors, func(post *repository.Post) []types.DomainEvent {
+		return []types.DomainEvent{types.PostCreatedEvent{Post: mapPost(withUsers(post))}}
+	})
+	if err != nil {
+		return types.Post{}, err
+	}
+
+	dispatchEvents(p.cont)
+	return mapPost(withUsers(post)), nil
+}
+
+// AuthorizePostAccess checks the password of a password-protected post.
+// If the password is correct, a short-lived access token scoped to the post is generated.
+func (p postService) AuthorizePostAccess(urlHandle string, password string) (string, error) {
+	log := p.cont.GetLogger()
+	postRepository := p.cont.GetPostRepository()
+	jwtUtils := p.cont.GetJWTUtils()
+
+	post, err := postRepository.GetPost(urlHandle)
+	if err != nil {
+		return "", err
+	}
+
+	switch post.Visibility {
+	case types.VisibilityPassword:
+	case types.VisibilityPrivate:
+		return "", errortypes.PostNotFoundError{Post: types.Post{URLHandle: urlHandle}}
+	default:
+		return "", errortypes.PostNotPasswordProtectedError{Post: types.Post{URLHandle: urlHandle}}
+	}
+
+	if !auth.CompareStringWithHash(password, post.PasswordHash) {
+		log.Debugf("incorrect password provided for post %s", urlHandle)
+		return "", errortypes.IncorrectPostPasswordError{}
+	}
+
+	log.Debugf("access granted to post %s", urlHandle)
+	return jwtUtils.GeneratePostAccessJWT(urlHandle)
+}
+
+// DeletePost removes a post together with its translations. Only the primary author of the post is allowed to delete it.
+func (p postService) DeletePost(urlHandle string, userName string) error {
+	log := p.cont.GetLogger()
+	postRepository := p.cont.GetPostRepository()
+
+	post, err := postRepository.GetPost(urlHandle)
+	if err != nil {
+		return err
+	}
+
+	if post.Author.UserName != userName {
+		log.Debugf("user %s is not the primary author of post %s", userName, urlHandle)
+		return errortypes.PostDeleteForbiddenError{Post: types.Post{URLHandle: urlHandle}, UserName: userName}
+	}
+
+	log.Infof("deleting post %s by user %s", urlHandle, userName)
+
+	deleted := types.PostDeletedEvent{Post: mapPostMetadata(post)}
+	if err := postRepository.DeletePost(post.ID, []types.DomainEvent{deleted}); err != nil {
+		return err
+	}
+
+	dispatchEvents(p.cont)
+	return nil
+}
+
+// DeletePostTranslation removes the translation of a post in the given language.
+// The translations can be managed by the primary author and by the co-authors and editors of the post.
+func (p postService) DeletePostTranslation(urlHandle string, language string, userName string) error {
+	log := p.cont.GetLogger()
+	postRepository := p.cont.GetPostRepository()
+
+	post, err := postRepository.GetPost(urlHandle)
+	if err != nil {
+		return err
+	}
+
+	if !canEditPost(post, userName) {
+		log.Debugf("user %s is not allowed to edit post %s", userName, urlHandle)
+		return errortypes.PostEditForbiddenError{Post: types.Post{URLHandle: urlHandle}, UserName: userName}
+	}
+
+	tag, ok := i18n.NormalizeTag(language)
+	if !ok {
+		return errortypes.InvalidLanguageError{Language: language}
+	}
+
+	if findTranslation(post, tag) == nil {
+		return errortypes.TranslationNotFoundError{Post: types.Post{URLHandle: urlHandle}, Language: tag}
+	}
+
+	log.Infof("deleting translation %s of post %s", tag, urlHandle)
+
+	translations := make([]repository.PostTranslation, 0, len(post.Translations))
+	for _, translation := range post.Translations {
+		if translation.Language != tag {
+			translations = append(translations, translation)
+		}
+	}
+	post.Translations = translations
+
+	updated := types.PostUpdatedEvent{Post: mapPost(post)}
+	if err := postRepository.DeleteTranslation(post.ID, tag, []types.DomainEvent{updated}); err != nil {
+		return err
+	}
+
+	dispatchEvents(p.cont)
+	return nil
+}
+
+// GetAllPosts retrieves the full content of every post regardless of its visibility, e.g. for exporting them.
+// The stored body is returned as it is, without rendering the responsive images, along with the password hash of
+// password-protected posts, so they keep their password when imported again.
+func (p postService) GetAllPosts() ([]types.Post, error) {
+	postRepository := p.cont.GetPostRepository()
+
+	posts, err := postRepository.GetAllPosts()
+	if err != nil {
+		return []types.Post{}, err
+	}
+
+	result := make([]types.Post, 0, len(posts))
+	for i := range posts {
+		post := mapPost(&posts[i])
+		post.PasswordHash = posts[i].PasswordHash
+		result = append(result, post)
+	}
 
-	post, err := postRepository.AddPost(newPost, author.ID)
-	return mapPost(post), err
+	return result, nil
 }
 
 // GetPost retrieves the post with the given URL handle.
-func (p postService) GetPost(urlHandle string) (types.Post, error) {
+// Private posts are only visible to their contributors, password-protected posts require a valid post access token.
+// The content is returned in the best matching language of the ordered language preferences, falling back to the original language.
+// If the post is part of a series, the series metadata and the links to the neighbouring posts are included.
+// The reactions of the readers are counted by emoji.
+func (p postService) GetPost(urlHandle string, access types.PostAccess, languages []string) (types.Post, error) {
+	log := p.cont.GetLogger()
+	postRepository := p.cont.GetPostRepository()
+	reactionRepository := p.cont.GetReactionRepository()
+	seriesRepository := p.cont.GetSeriesRepository()
+
+	post, err := postRepository.GetPost(urlHandle)
+	if err != nil {
+		return types.Post{}, err
+	}
+
+	if err := p.checkPostAccess(post, access); err != nil {
+		log.Debugf("access to post %s denied: %v", urlHandle, err)
+		return types.Post{}, err
+	}
+
+	series, err := seriesRepository.GetPostSeries(post.ID)
+	if err != nil {
+		log.Errorf("failed to get series of post %s: %v", urlHandle, err)
+		return types.Post{}, err
+	}
+
+	counts, err := reactionRepository.CountReactions([]uint{post.ID})
+	if err != nil {
+		log.Errorf("failed to count reactions to post %s: %v", urlHandle, err)
+		return types.Post{}, err
+	}
+
+	result := mapPost(post)
+	result.Series = mapSeriesNavigation(series, post.ID)
+	result.Reactions = counts[post.ID]
+	if translation := selectTranslation(post, languages); translation != nil {
+		translatePost(&result, translation)
+	}
+	return result, nil
+}
+
+// GetPosts retrieves every post of the blog matching the filter.
+// If a language is provided, only the posts available in the given language are retrieved, using the translated metadata.
+// Custom field filters can only reference defined custom fields. The reactions to the posts are counted by emoji.
+func (p postService) GetPosts(filter types.PostFilter) ([]types.Post, error) {
+	postRepository := p.cont.GetPostRepository()
+	fieldRepository := p.cont.GetFieldRepository()
+	reactionRepository := p.cont.GetReactionRepository()
+	collectionRepository := p.cont.GetCollectionRepository()
+
+	if filter.Collection != "" {
+		if _, err := collectionRepository.GetCollection(filter.Collection); err != nil {
+			return []types.Post{}, err
+		}
+	}
+
+	if len(filter.Fields) > 0 {
+		definitions, err := fieldRepository.GetFields()
+		if err != nil {
+			return []types.Post{}, err
+		}
+
+		for name := range filter.Fields {
+			if !containsField(definitions, name) {
+				return []types.Post{}, errortypes.InvalidCustomFieldError{Name: name, Reason: "unknown custom field"}
+			}
+		}
+	}
+
+	posts, err := postRepository.GetPosts(filter)
+	result := mapPosts(posts)
+	if err != nil {
+		return result, err
+	}
+
+	if filter.Language != "" {
+		for i := range posts {
+			if translation := findTranslation(&posts[i], filter.Language); translation != nil {
+				translatePostMetadata(&result[i], translation)
+			}
+		}
+	}
+
+	ids := make([]uint, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.ID)
+	}
+
+	counts, err := reactionRepository.CountReactions(ids)
+	if err != nil {
+		return []types.Post{}, err
+	}
+	for i := range posts {
+		result[i].Reactions = counts[posts[i].ID]
+	}
+
+	return result, nil
+}
+
+// SetPostContributors replaces the additional contributors of a post.
+// Only the primary author of the post is allowed to manage its contributors.
+func (p postService) SetPostContributors(urlHandle string, contributors []types.Contributor, userName string) (types.Post, error) {
+	log := p.cont.GetLogger()
+	postRepository := p.cont.GetPostRepository()
+
+	post, err := postRepository.GetPost(urlHandle)
+	if err != nil {
+		return types.Post{}, err
+	}
+
+	if post.Author.UserName != userName {
+		log.Debugf("user %s is not the primary author of post %s", userName, urlHandle)
+		return types.Post{}, errortypes.PostEditForbiddenError{Post: types.Post{URLHandle: urlHandle}, UserName: userName}
+	}
+
+	models, err := p.resolveContributors(contributors, &post.Author)
+	if err != nil {
+		log.Errorf("failed to resolve contributors of post %s: %v", urlHandle, err)
+		return types.Post{}, err
+	}
+
+	log.Infof("setting contributors of post %s to %v", urlHandle, contributors)
+
+	post.Contributors = models
+	result := mapPost(post)
+
+	if err := postRepository.SetContributors(post.ID, models, []types.DomainEvent{types.PostUpdatedEvent{Post: result}}); err != nil {
+		return types.Post{}, err
+	}
+
+	dispatchEvents(p.cont)
+	return result, nil
+}
+
+// SetPostHighlight pins a post to the top of the listings and marks it as featured, as requested by an administrator.
+func (p postService) SetPostHighlight(urlHandle string, highlight types.PostHighlight, userName string) (types.Post, error) {
+	log := p.cont.GetLogger()
+	postRepository := p.cont.GetPostRepository()
+
+	post, err := postRepository.GetPost(urlHandle)
+	if err != nil {
+		return types.Post{}, err
+	}
+
+	log.Infof("highlighting post %s by user %s: %v", urlHandle, userName, highlight)
+
+	err = postRepository.SetHighlight(post, highlight, func(post *repository.Post) []types.DomainEvent {
+		return []types.DomainEvent{types.PostUpdatedEvent{Post: mapPost(post)}}
+	})
+	if err != nil {
+		return types.Post{}, err
+	}
+
+	dispatchEvents(p.cont)
+	return mapPost(post), nil
+}
+
+// SetPostTranslation creates or replaces the translation of a post in the language of the translation.
+// The translations can be managed by the primary author and by the co-authors and editors of the post.
+func (p postService) SetPostTranslation(urlHandle string, translation types.PostTranslation, userName string) (types.Post, error) {
+	log := p.cont.GetLogger()
 	postRepository := p.cont.GetPostRepository()
+
 	post, err := postRepository.GetPost(urlHandle)
-	return mapPost(post), err
+	if err != nil {
+		return types.Post{}, err
+	}
+
+	if !canEditPost(post, userName) {
+		log.Debugf("user %s is not allowed to edit post %s", userName, urlHandle)
+		return types.Post{}, errortypes.PostEditForbiddenError{Post: types.Post{URLHandle: urlHandle}, UserName: userName}
+	}
+
+	language, ok := i18n.NormalizeTag(translation.Language)
+	if !ok {
+		return types.Post{}, errortypes.InvalidLanguageError{Language: translation.Language}
+	}
+
+	if language == post.Language {
+		return types.Post{}, errortypes.OriginalLanguageTranslationError{Post: types.Post{URLHandle: urlHandle}, Language: language}
+	}
+
+	stats := p.contentStats(translation.Summary, translation.Body)
+	model := repository.PostTranslation{
+		PostID:      post.ID,
+		Language:    language,
+		Title:       translation.Title,
+		Summary:     translation.Summary,
+		Body:        translation.Body,
+		Excerpt:     stats.Excerpt,
+		WordCount:   stats.WordCount,
+		ReadingTime: stats.ReadingTime,
+	}
+
+	log.Infof("setting translation %s of post %s", language, urlHandle)
+
+	if existing := findTranslation(post, language); existing != nil {
+		*existing = model
+	} else {
+		post.Translations = append(post.Translations, model)
+	}
+
+	updated := types.PostUpdatedEvent{Post: mapPost(post)}
+	if err := postRepository.SetTranslation(&model, []types.DomainEvent{updated}); err != nil {
+		return types.Post{}, err
+	}
+
+	dispatchEvents(p.cont)
+
+	result := mapPost(post)
+	translatePost(&result, &model)
+	return result, nil
 }
 
-// GetPosts retrieves every post of the blog.
-func (p postService) GetPosts() ([]types.Post, error) {
+// UpdatePost updates the title, summary, body, visibility, custom fields and tags of an existing post.
+// If no custom fields, tags, search engine settings, cover image or creation time are provided, the current ones are kept.
+// An empty cover image removes the current one.
+// The post can be edited by its primary author and by its co-authors and editors.
+func (p postService) UpdatePost(post *types.Post, userName string) (types.Post, error) {
+	log := p.cont.GetLogger()
 	postRepository := p.cont.GetPostRepository()
-	posts, err := postRepository.GetPosts()
-	return mapPosts(posts), err
+
+	existingPost, err := postRepository.GetPost(post.URLHandle)
+	if err != nil {
+		return types.Post{}, err
+	}
+
+	if !canEditPost(existingPost, userName) {
+		log.Debugf("user %s is not allowed to edit post %s", userName, post.URLHandle)
+		return types.Post{}, errortypes.PostEditForbiddenError{Post: types.Post{URLHandle: post.URLHandle}, UserName: userName}
+	}
+
+	if err := preparePostVisibility(post, existingPost); err != nil {
+		log.Debugf("invalid visibility settings for post %s: %v", post.URLHandle, err)
+		return types.Post{}, err
+	}
+
+	if post.CustomFields == nil {
+		post.CustomFields = existingPost.CustomFields
+	} else if err := p.checkCustomFields(post.CustomFields); err != nil {
+		log.Debugf("invalid custom fields for post %s: %v", post.URLHandle, err)
+		return types.Post{}, err
+	}
+
+	if post.Tags == nil {
+		post.Tags = existingPost.Tags
+	}
+
+	if post.SEO == nil {
+		post.SEO = mapPostSEO(existingPost)
+	} else if err := checkPostSEO(post.SEO); err != nil {
+		log.Debugf("invalid search engine settings for post %s: %v", post.URLHandle, err)
+		return types.Post{}, err
+	}
+
+	if post.CoverImage == nil {
+		post.CoverImage = mapCoverImage(existingPost)
+	} else if err := checkCoverImage(post.CoverImage); err != nil {
+		log.Debugf("invalid cover image for post %s: %v", post.URLHandle, err)
+		return types.Post{}, err
+	}
+
+	p.prepareContentStats(post)
+
+	log.Infof("updating post %s by user %s", post.URLHandle, userName)
+
+	updatedPost, err := postRepository.UpdatePost(post, func(post *repository.Post) []types.DomainEvent {
+		return []types.DomainEvent{types.PostUpdatedEvent{Post: mapPost(post)}}
+	})
+	if err != nil {
+		return types.Post{}, err
+	}
+
+	dispatchEvents(p.cont)
+	return mapPost(updatedPost), nil
+}
+
+// resolveContributors validates the contributor roles and maps the contributors to models referencing the users.
+// The primary author is skipped, since it is stored on the post itself.
+func (p postService) resolveContributors(contributors []types.Contributor, author *repository.User) ([]repository.Contributor, error) {
+	userRepository := p.cont.GetUserRepository()
+
+	models := make([]repository.Contributor, 0, len(contributors))
+	for _, contributor := range contributors {
+		if !types.IsValidContributorRole(contributor.Role) {
+			return nil, errortypes.InvalidContributorRoleError{Role: contributor.Role}
+		}
+
+		if contributor.UserName == author.UserName {
+			continue
+		}
+
+		user, err := userRepository.GetUser(contributor.UserName)
+		if err != nil {
+			return nil, err
+		}
+
+		models = append(models, repository.Contributor{UserID: user.ID, User: *user, Role: contributor.Role})
+	}
+
+	return models, nil
+}
+
+// checkCustomFields validates the custom field values of a post against the current field definitions.
+func (p postService) checkCustomFields(values map[string]interface{}) error {
+	fieldRepository := p.cont.GetFieldRepository()
+
+	definitions, err := fieldRepository.GetFields()
+	if err != nil {
+		return err
+	}
+
+	return validateCustomFields(values, definitions)
+}
+
+// checkPostAccess checks whether the post can be read with the provided access information.
+// The existence of private posts is not revealed to other users.
+func (p postService) checkPostAccess(post *repository.Post, access types.PostAccess) error {
+	jwtUtils := p.cont.GetJWTUtils()
+
+	switch post.Visibility {
+	case types.VisibilityPrivate:
+		if !canReadPost(post, access.UserName) {
+			return errortypes.PostNotFoundError{Post: types.Post{URLHandle: post.URLHandle}}
+		}
+
+	case types.VisibilityPassword:
+		if canReadPost(post, access.UserName) {
+			return nil
+		}
+		if access.AccessToken == "" {
+			return errortypes.PostPasswordRequiredError{Post: types.Post{URLHandle: post.URLHandle}}
+		}
+		if urlHandle, err := jwtUtils.ParsePostAccessJWT(access.AccessToken); err != nil || urlHandle != post.URLHandle {
+			return errortypes.PostPasswordRequiredError{Post: types.Post{URLHandle: post.URLHandle}}
+		}
+	}
+
+	return nil
+}
+
+// prepareContentStats computes the word count, the reading time and the excerpt of a post from its body.
+func (p postService) prepareContentStats(post *types.Post) {
+	stats := p.contentStats(post.Summary, post.Body)
+	post.Excerpt = stats.Excerpt
+	post.WordCount = stats.WordCount
+	post.ReadingTime = stats.ReadingTime
+}
+
+// contentStats computes the statistics of a Markdown or HTML body. The reading time is rounded up to whole minutes.
+// The excerpt is the summary, or the beginning of the plain-text body if there is no summary.
+func (p postService) contentStats(summary string, body string) contentStats {
+	text := markdown.PlainText(body)
+	words := len(strings.Fields(text))
+
+	stats := contentStats{
+		WordCount:   words,
+		ReadingTime: (words + p.config.WordsPerMinute - 1) / p.config.WordsPerMinute,
+		Excerpt:     strings.TrimSpace(summary),
+	}
+	if stats.Excerpt == "" {
+		stats.Excerpt = excerpt(text, p.config.ExcerptLength)
+	}
+
+	return stats
+}
+
+// preparePostVisibility validates the visibility settings of a post and hashes the password of password-protected posts.
+// When updating an existing post, the missing visibility and password fall back to the currently stored ones.
+func preparePostVisibility(post *types.Post, existingPost *repository.Post) error {
+	if post.Visibility == "" && existingPost != nil {
+		post.Visibility = existingPost.Visibility
+	}
+	if post.Visibility == "" {
+		post.Visibility = types.VisibilityPublic
+	}
+
+	if !types.IsValidVisibility(post.Visibility) {
+		return errortypes.InvalidPostVisibilityError{Visibility: post.Visibility}
+	}
+
+	if post.Visibility != types.VisibilityPassword {
+		post.PasswordHash = ""
+		return nil
+	}
+
+	if post.Password == "" {
+		// Imported posts carry the hash of their password instead of the password itself
+		if post.PasswordHash != "" {
+			return nil
+		}
+		if existingPost == nil || existingPost.PasswordHash == "" {
+			return errortypes.MissingPostPasswordError{}
+		}
+		post.PasswordHash = existingPost.PasswordHash
+		return nil
+	}
+
+	hash, err := auth.HashString(post.Password)
+	if err != nil {
+		return errortypes.PasswordHashingError{}
+	}
+
+	// Only the hash is kept, so the password doesn't end up in the logs or the stored events
+	post.PasswordHash = hash
+	post.Password = ""
+	return nil
+}
+
+// checkPostSEO validates the search engine settings of a post. The canonical URL must be an absolute http or https URL.
+func checkPostSEO(seo *types.PostSEO) error {
+	if seo == nil || seo.CanonicalURL == "" {
+		return nil
+	}
+
+	if !isAbsoluteURL(seo.CanonicalURL) {
+		return errortypes.InvalidCanonicalURLError{URL: seo.CanonicalURL}
+	}
+	return nil
+}
+
+// checkCoverImage validates the cover image of a post.
+// It must be an absolute http or https URL or a path of the blog, e.g. the URL of an uploaded media file.
+func checkCoverImage(image *string) error {
+	if image == nil || *image == "" || isAbsoluteURL(*image) {
+		return nil
+	}
+
+	u, err := url.Parse(*image)
+	if err != nil || u.IsAbs() || u.Host != "" || !strings.HasPrefix(u.Path, "/") || strings.HasPrefix(*image, "//") {
+		return errortypes.InvalidCoverImageError{URL: *image}
+	}
+	return nil
+}
+
+// isAbsoluteURL checks whether the value is an absolute http or https URL.
+func isAbsoluteURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// canEditPost checks whether the user is the primary author of the post or one of its co-authors or editors.
+// Reviewers have read-only access and anonymous users can't edit any post.
+func canEditPost(post *repository.Post, userName string) bool {
+	if userName == "" {
+		return false
+	}
+
+	if post.Author.UserName == userName {
+		return true
+	}
+
+	for _, contributor := range post.Contributors {
+		if contributor.User.UserName == userName && contributor.Role != types.ContributorRoleReviewer {
+			return true
+		}
+	}
+
+	return false
+}
+
+// canReadPost checks whether the user is the primary author or one of the contributors of the post, including reviewers.
+// Anonymous users can't read posts which aren't visible to everyone.
+func canReadPost(post *repository.Post, userName string) bool {
+	if userName == "" {
+		return false
+	}
+
+	if post.Author.UserName == userName {
+		return true
+	}
+
+	for _, contributor := range post.Contributors {
+		if contributor.User.UserName == userName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findTranslation returns the translation of the post in the given language or nil, if there is none.
+func findTranslation(post *repository.Post, language string) *repository.PostTranslation {
+	for i := range post.Translations {
+		if post.Translations[i].Language == language {
+			return &post.Translations[i]
+		}
+	}
+	return nil
+}
+
+// selectTranslation negotiates the language of the post based on the ordered language preferences.
+// If the original language is the best match or none of the preferences can be satisfied, nil is returned.
+func selectTranslation(post *repository.Post, languages []string) *repository.PostTranslation {
+	available := make([]string, 0, len(post.Translations)+1)
+	available = append(available, post.Language)
+	for _, translation := range post.Translations {
+		available = append(available, translation.Language)
+	}
+
+	language, ok := i18n.Match(languages, available)
+	if !ok || language == post.Language {
+		return nil
+	}
+
+	return findTranslation(post, language)
+}
+
+// translatePost replaces the content of a post data object with the translated one.
+func translatePost(post *types.Post, translation *repository.PostTranslation) {
+	translatePostMetadata(post, translation)
+	post.Body = translation.Body
+}
+
+// translatePostMetadata replaces the metadata of a post data object with the translated one.
+func translatePostMetadata(post *types.Post, translation *repository.PostTranslation) {
+	post.Language = translation.Language
+	post.Title = translation.Title
+	post.Summary = translation.Summary
+	post.Excerpt = translation.Excerpt
+	post.WordCount = translation.WordCount
+	post.ReadingTime = translation.ReadingTime
+}
+
+// excerpt shortens a plain text to at most the given number of characters without breaking words.
+// Shortened texts end with an ellipsis, which is part of the length.
+func excerpt(text string, length int) string {
+	if utf8.RuneCountInString(text) <= length {
+		return text
+	}
+
+	runes := []rune(text)[:length]
+	cut := len(runes) - 1
+	for cut > 0 && !unicode.IsSpace(runes[cut]) {
+		cut--
+	}
+	// Break words only if the first word is too long
+	if cut == 0 {
+		cut = len(runes) - 1
+	}
+
+	return strings.TrimRightFunc(string(runes[:cut]), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + "…"
+}
+
+// mapAlternateLinks creates the hreflang links of every language version of a post.
+// Posts without translations have no alternate versions, so nil is returned.
+func mapAlternateLinks(p *repository.Post) []types.AlternateLink {
+	if len(p.Translations) == 0 {
+		return nil
+	}
+
+	links := make([]types.AlternateLink, 0, len(p.Translations)+1)
+	links = append(links, types.AlternateLink{HrefLang: p.Language, Href: fmt.Sprintf("/posts/%s?lang=%s", p.URLHandle, p.Language)})
+	for _, translation := range p.Translations {
+		links = append(links, types.AlternateLink{HrefLang: translation.Language, Href: fmt.Sprintf("/posts/%s?lang=%s", p.URLHandle, translation.Language)})
+	}
+
+	return links
+}
+
+// mapContributors maps the primary author and the contributors of a Post model to contributor data objects.
+// The primary author is always listed first.
+func mapContributors(p *repository.Post) []types.Contributor {
+	contributors := make([]types.Contributor, 0, len(p.Contributors)+1)
+	contributors = append(contributors, types.Contributor{UserName: p.Author.UserName, Role: types.ContributorRoleAuthor})
+
+	for _, contributor := range p.Contributors {
+		contributors = append(contributors, types.Contributor{UserName: contributor.User.UserName, Role: contributor.Role})
+	}
+
+	return contributors
 }
 
 // mapPost maps a Post model to a post data object
@@ -65,9 +849,23 @@ func mapPost(p *repository.Post) types.Post {
 		URLHandle:    p.URLHandle,
 		Title:        p.Title,
 		Author:       p.Author.UserName,
+		Contributors: mapContributors(p),
 		Summary:      p.Summary,
 		Body:         p.Body,
+		Excerpt:      p.Excerpt,
+		WordCount:    p.WordCount,
+		ReadingTime:  p.ReadingTime,
 		CreationTime: p.CreatedAt,
+		UpdateTime:   p.UpdatedAt,
+		Language:     p.Language,
+		Translations: mapAlternateLinks(p),
+		Visibility:   p.Visibility,
+		CustomFields: p.CustomFields,
+		Tags:         p.Tags,
+		CoverImage:   mapCoverImage(p),
+		Pinned:       p.Pinned,
+		Featured:     p.Featured,
+		SEO:          mapPostSEO(p),
 	}
 }
 
@@ -77,9 +875,39 @@ func mapPostMetadata(p *repository.Post) types.Post {
 		URLHandle:    p.URLHandle,
 		Title:        p.Title,
 		Author:       p.Author.UserName,
+		Contributors: mapContributors(p),
 		Summary:      p.Summary,
+		Excerpt:      p.Excerpt,
+		WordCount:    p.WordCount,
+		ReadingTime:  p.ReadingTime,
 		CreationTime: p.CreatedAt,
+		UpdateTime:   p.UpdatedAt,
+		Language:     p.Language,
+		Visibility:   p.Visibility,
+		CustomFields: p.CustomFields,
+		Tags:         p.Tags,
+		CoverImage:   mapCoverImage(p),
+		Pinned:       p.Pinned,
+		Featured:     p.Featured,
+		SEO:          mapPostSEO(p),
+	}
+}
+
+// mapPostSEO maps the search engine settings of a post model, which are omitted if none were provided.
+func mapPostSEO(p *repository.Post) *types.PostSEO {
+	if p.MetaDescription == "" && p.CanonicalURL == "" && !p.NoIndex {
+		return nil
+	}
+	return &types.PostSEO{MetaDescription: p.MetaDescription, CanonicalURL: p.CanonicalURL, NoIndex: p.NoIndex}
+}
+
+// mapCoverImage maps the cover image of a post model, which is omitted if none was provided.
+func mapCoverImage(p *repository.Post) *string {
+	if p.CoverImage == "" {
+		return nil
 	}
+	image := p.CoverImage
+	return &image
 }
 
 // mapPosts maps a slice of Post models to a slice of post data objects
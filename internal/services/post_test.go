@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/auth"
 	"github.com/wlchs/blog/internal/container"
 	"github.com/wlchs/blog/internal/errortypes"
 	"github.com/wlchs/blog/internal/logger"
@@ -11,15 +12,23 @@ import (
 	"github.com/wlchs/blog/internal/repository"
 	"github.com/wlchs/blog/internal/services"
 	"github.com/wlchs/blog/internal/types"
+	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 )
 
 // postTestContext contains objects relevant for testing the PostService.
 type postTestContext struct {
-	mostPostRepository *mocks.MockPostRepository
-	mostUserRepository *mocks.MockUserRepository
-	sut                services.PostService
+	mockCollectionRepository *mocks.MockCollectionRepository
+	mockFieldRepository      *mocks.MockFieldRepository
+	mostPostRepository       *mocks.MockPostRepository
+	mockReactionRepository   *mocks.MockReactionRepository
+	mostSeriesRepository     *mocks.MockSeriesRepository
+	mostUserRepository       *mocks.MockUserRepository
+	mockJwtUtils             *mocks.MockTokenUtils
+	mockEventBus             *mocks.MockBus
+	sut                      services.PostService
 }
 
 // createPostServiceContext creates the context for testing the PostService and reduces code duplication.
@@ -27,18 +36,25 @@ func createPostServiceContext(t *testing.T) *postTestContext {
 	t.Helper()
 
 	mockCtrl := gomock.NewController(t)
+	mockCollectionRepository := mocks.NewMockCollectionRepository(mockCtrl)
+	mockFieldRepository := mocks.NewMockFieldRepository(mockCtrl)
 	mockPostRepository := mocks.NewMockPostRepository(mockCtrl)
+	mockReactionRepository := mocks.NewMockReactionRepository(mockCtrl)
+	mockSeriesRepository := mocks.NewMockSeriesRepository(mockCtrl)
 	mockUserRepository := mocks.NewMockUserRepository(mockCtrl)
-	cont := container.CreateContainer(logger.CreateLogger(), mockPostRepository, mockUserRepository, nil)
+	mockJwtUtils := mocks.NewMockTokenUtils(mockCtrl)
+	mockEventBus := mocks.NewMockBus(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, mockCollectionRepository, nil, mockFieldRepository, nil, nil, mockPostRepository, mockReactionRepository, nil, mockSeriesRepository, mockUserRepository, nil, nil, mockJwtUtils, nil, mockEventBus, nil)
 	sut := services.CreatePostService(cont)
 
-	return &postTestContext{mockPostRepository, mockUserRepository, sut}
+	return &postTestContext{mockCollectionRepository, mockFieldRepository, mockPostRepository, mockReactionRepository, mockSeriesRepository, mockUserRepository, mockJwtUtils, mockEventBus, sut}
 }
 
 // TestPostService_AddPost tests adding a new post to the blog.
 func TestPostService_AddPost(t *testing.T) {
 	t.Parallel()
 	c := createPostServiceContext(t)
+	c.mockEventBus.EXPECT().Dispatch()
 
 	userModel := repository.User{
 		ID:       0,
@@ -47,15 +63,20 @@ func TestPostService_AddPost(t *testing.T) {
 	}
 
 	postModel := repository.Post{
-		ID:        0,
-		URLHandle: "testUrlHandle",
-		AuthorID:  userModel.ID,
-		Author:    userModel,
-		Title:     "testTitle",
-		Summary:   "testSummary",
-		Body:      "testBody",
-		CreatedAt: time.Time{}.Local(),
-		UpdatedAt: time.Time{}.Local(),
+		ID:          0,
+		URLHandle:   "testUrlHandle",
+		AuthorID:    userModel.ID,
+		Author:      userModel,
+		Title:       "testTitle",
+		Summary:     "testSummary",
+		Body:        "testBody",
+		Excerpt:     "testSummary",
+		WordCount:   1,
+		ReadingTime: 1,
+		Language:    "en",
+		Visibility:  types.VisibilityPublic,
+		CreatedAt:   time.Time{}.Local(),
+		UpdatedAt:   time.Time{}.Local(),
 	}
 
 	newPost := types.Post{
@@ -65,15 +86,26 @@ func TestPostService_AddPost(t *testing.T) {
 		Summary:      postModel.Summary,
 		Body:         postModel.Body,
 		CreationTime: postModel.CreatedAt,
+		UpdateTime:   postModel.UpdatedAt,
 	}
 
 	c.mostUserRepository.EXPECT().GetUser(userModel.UserName).Return(&userModel, nil)
-	c.mostPostRepository.EXPECT().AddPost(&newPost, userModel.ID).Return(&postModel, nil)
+	c.mockFieldRepository.EXPECT().GetFields().Return(nil, nil)
+	var storedEvents []types.DomainEvent
+	c.mostPostRepository.EXPECT().AddPost(&newPost, userModel.ID, []repository.Contributor{}, gomock.Any()).
+		DoAndReturn(func(_ *types.Post, _ uint, _ []repository.Contributor, events repository.PostEvents) (*repository.Post, error) {
+			storedEvents = events(&postModel)
+			return &postModel, nil
+		})
 
 	p, err := c.sut.AddPost(&newPost)
 
+	expectedPost := newPost
+	expectedPost.Contributors = []types.Contributor{{UserName: userModel.UserName, Role: types.ContributorRoleAuthor}}
+
 	assert.Nil(t, err, "should complete without error")
-	assert.Equal(t, newPost, p, "added post doesn't match the input")
+	assert.Equal(t, expectedPost, p, "added post doesn't match the input")
+	assert.Equal(t, []types.DomainEvent{types.PostCreatedEvent{Post: expectedPost}}, storedEvents, "the created post should be stored in the outbox")
 }
 
 // TestPostService_AddPost_Invalid_User tests adding a new post to the blog with invalid username.
@@ -127,12 +159,14 @@ func TestPostService_Duplicate_Post(t *testing.T) {
 		Summary:      postModel.Summary,
 		Body:         postModel.Body,
 		CreationTime: postModel.CreatedAt,
+		UpdateTime:   postModel.UpdatedAt,
 	}
 
 	expectedError := errortypes.DuplicateElementError{Key: postModel.URLHandle}
 
 	c.mostUserRepository.EXPECT().GetUser(userModel.UserName).Return(&userModel, nil)
-	c.mostPostRepository.EXPECT().AddPost(&newPost, userModel.ID).Return(nil, expectedError)
+	c.mockFieldRepository.EXPECT().GetFields().Return(nil, nil)
+	c.mostPostRepository.EXPECT().AddPost(&newPost, userModel.ID, []repository.Contributor{}, gomock.Any()).Return(nil, expectedError)
 
 	p, err := c.sut.AddPost(&newPost)
 
@@ -167,26 +201,127 @@ func TestPostService_GetPost(t *testing.T) {
 		URLHandle:    postModel.URLHandle,
 		Title:        postModel.Title,
 		Author:       userModel.UserName,
+		Contributors: []types.Contributor{{UserName: userModel.UserName, Role: types.ContributorRoleAuthor}},
 		Summary:      postModel.Summary,
 		Body:         postModel.Body,
 		CreationTime: postModel.CreatedAt,
+		UpdateTime:   postModel.UpdatedAt,
+		Reactions:    map[string]int{"👍": 2},
 	}
 
 	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+	c.mostSeriesRepository.EXPECT().GetPostSeries(postModel.ID).Return(nil, nil)
+	c.mockReactionRepository.EXPECT().CountReactions([]uint{postModel.ID}).Return(map[uint]map[string]int{postModel.ID: {"👍": 2}}, nil)
 
-	p, err := c.sut.GetPost(postModel.URLHandle)
+	p, err := c.sut.GetPost(postModel.URLHandle, types.PostAccess{}, nil)
 
 	assert.Nil(t, err, "should complete without error")
 	assert.Equal(t, post, p, "post doesn't match the expected output")
 }
 
+// TestPostService_GetPost_Series tests getting a post which is part of a series.
+func TestPostService_GetPost_Series(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	postModels := []repository.Post{
+		{ID: 1, URLHandle: "part1", Title: "Part 1", Visibility: types.VisibilityPublic},
+		{ID: 2, URLHandle: "part2", Title: "Part 2", Visibility: types.VisibilityPublic},
+		{ID: 3, URLHandle: "part3", Title: "Part 3", Visibility: types.VisibilityPublic},
+	}
+
+	seriesModel := repository.Series{
+		ID:        1,
+		URLHandle: "testSeries",
+		Title:     "Test Series",
+		Posts: []repository.SeriesPost{
+			{SeriesID: 1, PostID: 1, Post: postModels[0], Position: 1},
+			{SeriesID: 1, PostID: 2, Post: postModels[1], Position: 2},
+			{SeriesID: 1, PostID: 3, Post: postModels[2], Position: 3},
+		},
+	}
+
+	expectedNavigation := &types.SeriesNavigation{
+		URLHandle: seriesModel.URLHandle,
+		Title:     seriesModel.Title,
+		Position:  2,
+		Total:     3,
+		Previous:  &types.SeriesLink{URLHandle: "part1", Title: "Part 1"},
+		Next:      &types.SeriesLink{URLHandle: "part3", Title: "Part 3"},
+	}
+
+	c.mostPostRepository.EXPECT().GetPost("part2").Return(&postModels[1], nil)
+	c.mostSeriesRepository.EXPECT().GetPostSeries(uint(2)).Return(&seriesModel, nil)
+	c.mockReactionRepository.EXPECT().CountReactions([]uint{2}).Return(map[uint]map[string]int{}, nil)
+
+	p, err := c.sut.GetPost("part2", types.PostAccess{}, nil)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, expectedNavigation, p.Series, "series navigation doesn't match the expected output")
+}
+
+// TestPostService_GetPost_Series_Private_Member tests skipping a private post in the middle of a series.
+func TestPostService_GetPost_Series_Private_Member(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	postModels := []repository.Post{
+		{ID: 1, URLHandle: "part1", Title: "Part 1", Visibility: types.VisibilityPublic},
+		{ID: 2, URLHandle: "part2", Title: "Part 2", Visibility: types.VisibilityPrivate},
+		{ID: 3, URLHandle: "part3", Title: "Part 3", Visibility: types.VisibilityPublic},
+	}
+
+	seriesModel := repository.Series{
+		ID:        1,
+		URLHandle: "testSeries",
+		Title:     "Test Series",
+		Posts: []repository.SeriesPost{
+			{SeriesID: 1, PostID: 1, Post: postModels[0], Position: 1},
+			{SeriesID: 1, PostID: 2, Post: postModels[1], Position: 2},
+			{SeriesID: 1, PostID: 3, Post: postModels[2], Position: 3},
+		},
+	}
+
+	expectedNavigation := &types.SeriesNavigation{
+		URLHandle: seriesModel.URLHandle,
+		Title:     seriesModel.Title,
+		Position:  1,
+		Total:     2,
+		Next:      &types.SeriesLink{URLHandle: "part3", Title: "Part 3"},
+	}
+
+	c.mostPostRepository.EXPECT().GetPost("part1").Return(&postModels[0], nil)
+	c.mostSeriesRepository.EXPECT().GetPostSeries(uint(1)).Return(&seriesModel, nil)
+	c.mockReactionRepository.EXPECT().CountReactions([]uint{1}).Return(map[uint]map[string]int{}, nil)
+
+	p, err := c.sut.GetPost("part1", types.PostAccess{}, nil)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, expectedNavigation, p.Series, "private posts shouldn't be linked from the series navigation")
+}
+
+// TestPostService_GetPost_Series_Error tests handling an error while getting the series of a post.
+func TestPostService_GetPost_Series_Error(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle"}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+	c.mostSeriesRepository.EXPECT().GetPostSeries(postModel.ID).Return(nil, fmt.Errorf("error"))
+
+	_, err := c.sut.GetPost(postModel.URLHandle, types.PostAccess{}, nil)
+
+	assert.NotNil(t, err, "expected error")
+}
+
 // TestPostService_GetPost_Unexpected_Error tests handling an unexpected error while getting a post.
 func TestPostService_GetPost_Unexpected_Error(t *testing.T) {
 	t.Parallel()
 	c := createPostServiceContext(t)
 
 	c.mostPostRepository.EXPECT().GetPost("testUrlHandle").Return(nil, fmt.Errorf("error"))
-	_, err := c.sut.GetPost("testUrlHandle")
+	_, err := c.sut.GetPost("testUrlHandle", types.PostAccess{}, nil)
 
 	assert.NotNil(t, err, "expected error")
 }
@@ -221,26 +356,1112 @@ func TestPostService_GetPosts(t *testing.T) {
 			URLHandle:    postModels[0].URLHandle,
 			Title:        postModels[0].Title,
 			Author:       userModel.UserName,
+			Contributors: []types.Contributor{{UserName: userModel.UserName, Role: types.ContributorRoleAuthor}},
 			Summary:      postModels[0].Summary,
 			CreationTime: postModels[0].CreatedAt,
+			UpdateTime:   postModels[0].UpdatedAt,
+			Reactions:    map[string]int{"❤️": 1},
 		},
 	}
 
-	c.mostPostRepository.EXPECT().GetPosts().Return(postModels, nil)
+	c.mostPostRepository.EXPECT().GetPosts(types.PostFilter{}).Return(postModels, nil)
+	c.mockReactionRepository.EXPECT().CountReactions([]uint{postModels[0].ID}).Return(map[uint]map[string]int{postModels[0].ID: {"❤️": 1}}, nil)
 
-	p, err := c.sut.GetPosts()
+	p, err := c.sut.GetPosts(types.PostFilter{})
 
 	assert.Nil(t, err, "should complete without error")
 	assert.Equal(t, posts, p, "post doesn't match the expected output")
 }
 
+// TestPostService_GetPosts_Reactions_Error tests handling an error while counting the reactions to the posts.
+func TestPostService_GetPosts_Reactions_Error(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	postModels := []repository.Post{{ID: 1, URLHandle: "testUrlHandle"}}
+
+	c.mostPostRepository.EXPECT().GetPosts(types.PostFilter{}).Return(postModels, nil)
+	c.mockReactionRepository.EXPECT().CountReactions([]uint{1}).Return(nil, fmt.Errorf("error"))
+
+	_, err := c.sut.GetPosts(types.PostFilter{})
+
+	assert.NotNil(t, err, "expected error")
+}
+
+// TestPostService_GetAllPosts tests retrieving the full content of every post regardless of its visibility.
+func TestPostService_GetAllPosts(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	author := repository.User{ID: 1, UserName: "testAuthor"}
+	postModels := []repository.Post{
+		{ID: 1, URLHandle: "public", Author: author, Body: "<img src=\"/media/1\">", Visibility: types.VisibilityPublic},
+		{ID: 2, URLHandle: "private", Author: author, Body: "private body", Visibility: types.VisibilityPrivate, Tags: []string{"draft"}},
+		{ID: 3, URLHandle: "secret", Author: author, Visibility: types.VisibilityPassword, PasswordHash: "$2a$10$hash"},
+	}
+
+	c.mostPostRepository.EXPECT().GetAllPosts().Return(postModels, nil)
+
+	posts, err := c.sut.GetAllPosts()
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 3, len(posts), "didn't receive the expected number of posts")
+	assert.Equal(t, "<img src=\"/media/1\">", posts[0].Body, "stored body should be returned as it is")
+	assert.Equal(t, []string{"draft"}, posts[1].Tags, "tags should be included")
+	assert.Equal(t, "$2a$10$hash", posts[2].PasswordHash, "password hash should be included")
+}
+
+// TestPostService_GetAllPosts_Unexpected_Error tests handling an unexpected error while retrieving every post.
+func TestPostService_GetAllPosts_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	expectedError := fmt.Errorf("unexpected error")
+	c.mostPostRepository.EXPECT().GetAllPosts().Return(nil, expectedError)
+
+	posts, err := c.sut.GetAllPosts()
+
+	assert.Empty(t, posts, "shouldn't return posts")
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
+
 // TestPostService_GetPosts_Unexpected_Error tests handling an unexpected error while getting posts
 func TestPostService_GetPosts_Unexpected_Error(t *testing.T) {
 	t.Parallel()
 	c := createPostServiceContext(t)
 
-	c.mostPostRepository.EXPECT().GetPosts().Return(nil, fmt.Errorf("error"))
-	_, err := c.sut.GetPosts()
+	c.mostPostRepository.EXPECT().GetPosts(types.PostFilter{}).Return(nil, fmt.Errorf("error"))
+	_, err := c.sut.GetPosts(types.PostFilter{})
 
 	assert.NotNil(t, err, "expected error")
 }
+
+// TestPostService_AddPost_Contributors tests adding a new post with additional contributors.
+func TestPostService_AddPost_Contributors(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+	c.mockEventBus.EXPECT().Dispatch()
+
+	author := repository.User{ID: 1, UserName: "testAuthor"}
+	editor := repository.User{ID: 2, UserName: "testEditor"}
+
+	newPost := types.Post{
+		URLHandle: "testUrlHandle",
+		Author:    author.UserName,
+		Contributors: []types.Contributor{
+			{UserName: author.UserName, Role: types.ContributorRoleAuthor},
+			{UserName: editor.UserName, Role: types.ContributorRoleEditor},
+		},
+	}
+
+	contributors := []repository.Contributor{{UserID: editor.ID, User: editor, Role: types.ContributorRoleEditor}}
+	postModel := repository.Post{ID: 1, URLHandle: newPost.URLHandle, AuthorID: author.ID}
+
+	c.mostUserRepository.EXPECT().GetUser(author.UserName).Return(&author, nil)
+	c.mostUserRepository.EXPECT().GetUser(editor.UserName).Return(&editor, nil)
+	c.mockFieldRepository.EXPECT().GetFields().Return(nil, nil)
+	c.mostPostRepository.EXPECT().AddPost(&newPost, author.ID, contributors, gomock.Any()).Return(&postModel, nil)
+
+	p, err := c.sut.AddPost(&newPost)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, newPost.Contributors, p.Contributors, "contributors don't match the input")
+}
+
+// TestPostService_AddPost_Invalid_Contributor_Role tests adding a new post with an unsupported contributor role.
+func TestPostService_AddPost_Invalid_Contributor_Role(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	author := repository.User{ID: 1, UserName: "testAuthor"}
+	newPost := types.Post{
+		URLHandle:    "testUrlHandle",
+		Author:       author.UserName,
+		Contributors: []types.Contributor{{UserName: "testEditor", Role: "translator"}},
+	}
+
+	c.mostUserRepository.EXPECT().GetUser(author.UserName).Return(&author, nil)
+
+	_, err := c.sut.AddPost(&newPost)
+
+	assert.Equal(t, errortypes.InvalidContributorRoleError{Role: "translator"}, err, "error doesn't match expected one")
+}
+
+// TestPostService_SetPostHighlight tests pinning and featuring a post by one of its editors.
+func TestPostService_SetPostHighlight(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+	c.mockEventBus.EXPECT().Dispatch()
+
+	editor := repository.Contributor{User: repository.User{UserName: "testEditor"}, Role: types.ContributorRoleEditor}
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Featured: true, Contributors: []repository.Contributor{editor}}
+	highlight := types.PostHighlight{Pinned: true}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+	c.mostPostRepository.EXPECT().SetHighlight(&postModel, highlight, gomock.Any()).
+		DoAndReturn(func(post *repository.Post, highlight types.PostHighlight, events repository.PostEvents) error {
+			post.Pinned = highlight.Pinned
+			post.Featured = highlight.Featured
+			evts := events(post)
+			assert.Equal(t, 1, len(evts), "expected exactly 1 event")
+			assert.Equal(t, "testUrlHandle", evts[0].(types.PostUpdatedEvent).Post.URLHandle, "post.updated event should be stored")
+			return nil
+		})
+
+	p, err := c.sut.SetPostHighlight(postModel.URLHandle, highlight, "testEditor")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.True(t, p.Pinned, "post should be pinned")
+	assert.False(t, p.Featured, "post should not be featured anymore")
+}
+
+// TestPostService_SetPostHighlight_Not_Found tests highlighting a non-existent post.
+func TestPostService_SetPostHighlight_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	expectedError := errortypes.PostNotFoundError{Post: types.Post{URLHandle: "missing"}}
+
+	c.mostPostRepository.EXPECT().GetPost("missing").Return(nil, expectedError)
+
+	_, err := c.sut.SetPostHighlight("missing", types.PostHighlight{Featured: true}, "testAuthor")
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
+
+// TestPostService_UpdatePost tests updating a post by its primary author.
+func TestPostService_UpdatePost(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+	c.mockEventBus.EXPECT().Dispatch()
+
+	author := repository.User{ID: 1, UserName: "testAuthor"}
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Author: author, Title: "oldTitle"}
+	updatedModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Author: author, Title: "newTitle"}
+	input := types.Post{URLHandle: postModel.URLHandle, Title: "newTitle"}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+	c.mostPostRepository.EXPECT().UpdatePost(&input, gomock.Any()).Return(&updatedModel, nil)
+
+	p, err := c.sut.UpdatePost(&input, author.UserName)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "newTitle", p.Title, "title should be updated")
+}
+
+// TestPostService_UpdatePost_Keep_Tags tests keeping the current tags of a post if none are provided.
+func TestPostService_UpdatePost_Keep_Tags(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+	c.mockEventBus.EXPECT().Dispatch()
+
+	author := repository.User{ID: 1, UserName: "testAuthor"}
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Author: author, Tags: []string{"go"}}
+	input := types.Post{URLHandle: postModel.URLHandle, Title: "newTitle"}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+	c.mostPostRepository.EXPECT().UpdatePost(&input, gomock.Any()).Return(&postModel, nil)
+
+	_, err := c.sut.UpdatePost(&input, author.UserName)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, []string{"go"}, input.Tags, "current tags should be kept")
+}
+
+// TestPostService_UpdatePost_Content_Stats tests computing the word count, the reading time and the excerpt of a post
+// without summary.
+func TestPostService_UpdatePost_Content_Stats(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+	c.mockEventBus.EXPECT().Dispatch()
+
+	author := repository.User{ID: 1, UserName: "testAuthor"}
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Author: author}
+	body := "## Introduction\n\nThe **first** paragraph links to [the docs](https://example.com).\n\n" + strings.Repeat("word ", 400)
+	input := types.Post{URLHandle: postModel.URLHandle, Title: "newTitle", Body: body}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+	c.mostPostRepository.EXPECT().UpdatePost(&input, gomock.Any()).Return(&postModel, nil)
+
+	_, err := c.sut.UpdatePost(&input, author.UserName)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 408, input.WordCount, "Markdown syntax shouldn't be counted as words")
+	assert.Equal(t, 3, input.ReadingTime, "reading time should be rounded up to whole minutes")
+	assert.True(t, strings.HasPrefix(input.Excerpt, "Introduction The first paragraph links to the docs. word word"), "excerpt should be plain text")
+	assert.True(t, strings.HasSuffix(input.Excerpt, "word…"), "excerpt should end with an ellipsis after a whole word")
+	assert.LessOrEqual(t, utf8.RuneCountInString(input.Excerpt), 200, "excerpt should be shortened to the default length")
+}
+
+// TestPostService_UpdatePost_Content_Stats_Config tests computing the content statistics with custom settings.
+// The settings are read from the environment, so the test can't run in parallel.
+func TestPostService_UpdatePost_Content_Stats_Config(t *testing.T) {
+	t.Setenv("POST_WORDS_PER_MINUTE", "2")
+	t.Setenv("POST_EXCERPT_LENGTH", "12")
+	c := createPostServiceContext(t)
+	c.mockEventBus.EXPECT().Dispatch()
+
+	author := repository.User{ID: 1, UserName: "testAuthor"}
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Author: author}
+	input := types.Post{URLHandle: postModel.URLHandle, Title: "newTitle", Body: "<p>Hello, wonderful world!</p>"}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+	c.mostPostRepository.EXPECT().UpdatePost(&input, gomock.Any()).Return(&postModel, nil)
+
+	_, err := c.sut.UpdatePost(&input, author.UserName)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 3, input.WordCount, "every word should be counted")
+	assert.Equal(t, 2, input.ReadingTime, "reading time should use the configured speed")
+	assert.Equal(t, "Hello…", input.Excerpt, "excerpt should use the configured length")
+}
+
+// TestPostService_UpdatePost_Keep_SEO tests keeping the current search engine settings of a post if none are provided.
+func TestPostService_UpdatePost_Keep_SEO(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+	c.mockEventBus.EXPECT().Dispatch()
+
+	author := repository.User{ID: 1, UserName: "testAuthor"}
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Author: author, MetaDescription: "testDescription", NoIndex: true}
+	input := types.Post{URLHandle: postModel.URLHandle, Title: "newTitle"}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+	c.mostPostRepository.EXPECT().UpdatePost(&input, gomock.Any()).Return(&postModel, nil)
+
+	p, err := c.sut.UpdatePost(&input, author.UserName)
+
+	expectedSEO := &types.PostSEO{MetaDescription: "testDescription", NoIndex: true}
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, expectedSEO, input.SEO, "current search engine settings should be kept")
+	assert.Equal(t, expectedSEO, p.SEO, "search engine settings should be returned")
+}
+
+// TestPostService_UpdatePost_Keep_Cover_Image tests keeping the current cover image of a post if none is provided.
+func TestPostService_UpdatePost_Keep_Cover_Image(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+	c.mockEventBus.EXPECT().Dispatch()
+
+	author := repository.User{ID: 1, UserName: "testAuthor"}
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Author: author, CoverImage: "/media/1"}
+	input := types.Post{URLHandle: postModel.URLHandle, Title: "newTitle"}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+	c.mostPostRepository.EXPECT().UpdatePost(&input, gomock.Any()).Return(&postModel, nil)
+
+	p, err := c.sut.UpdatePost(&input, author.UserName)
+
+	expectedImage := "/media/1"
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, &expectedImage, input.CoverImage, "current cover image should be kept")
+	assert.Equal(t, &expectedImage, p.CoverImage, "cover image should be returned")
+}
+
+// TestPostService_UpdatePost_Remove_Cover_Image tests removing the cover image of a post with an empty one.
+func TestPostService_UpdatePost_Remove_Cover_Image(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+	c.mockEventBus.EXPECT().Dispatch()
+
+	author := repository.User{ID: 1, UserName: "testAuthor"}
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Author: author, CoverImage: "/media/1"}
+	image := ""
+	input := types.Post{URLHandle: postModel.URLHandle, Title: "newTitle", CoverImage: &image}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+	c.mostPostRepository.EXPECT().UpdatePost(&input, gomock.Any()).Return(&postModel, nil)
+
+	_, err := c.sut.UpdatePost(&input, author.UserName)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "", *input.CoverImage, "cover image should be removed")
+}
+
+// TestPostService_UpdatePost_Invalid_Canonical_URL tests updating a post with a relative canonical URL.
+func TestPostService_UpdatePost_Invalid_Canonical_URL(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	author := repository.User{ID: 1, UserName: "testAuthor"}
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Author: author}
+	input := types.Post{URLHandle: postModel.URLHandle, SEO: &types.PostSEO{CanonicalURL: "/posts/other"}}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+
+	_, err := c.sut.UpdatePost(&input, author.UserName)
+
+	assert.Equal(t, errortypes.InvalidCanonicalURLError{URL: "/posts/other"}, err, "error doesn't match expected one")
+}
+
+// TestPostService_UpdatePost_Contributor tests updating a post by one of its editors.
+func TestPostService_UpdatePost_Contributor(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+	c.mockEventBus.EXPECT().Dispatch()
+
+	author := repository.User{ID: 1, UserName: "testAuthor"}
+	editor := repository.User{ID: 2, UserName: "testEditor"}
+	postModel := repository.Post{
+		ID:           1,
+		URLHandle:    "testUrlHandle",
+		Author:       author,
+		Contributors: []repository.Contributor{{PostID: 1, UserID: editor.ID, User: editor, Role: types.ContributorRoleEditor}},
+	}
+	input := types.Post{URLHandle: postModel.URLHandle, Title: "newTitle"}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+	c.mostPostRepository.EXPECT().UpdatePost(&input, gomock.Any()).Return(&postModel, nil)
+
+	_, err := c.sut.UpdatePost(&input, editor.UserName)
+
+	assert.Nil(t, err, "should complete without error")
+}
+
+// TestPostService_UpdatePost_Reviewer tests rejecting the update of a post by one of its reviewers.
+func TestPostService_UpdatePost_Reviewer(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	author := repository.User{ID: 1, UserName: "testAuthor"}
+	reviewer := repository.User{ID: 2, UserName: "testReviewer"}
+	postModel := repository.Post{
+		ID:           1,
+		URLHandle:    "testUrlHandle",
+		Author:       author,
+		Contributors: []repository.Contributor{{PostID: 1, UserID: reviewer.ID, User: reviewer, Role: types.ContributorRoleReviewer}},
+	}
+	input := types.Post{URLHandle: postModel.URLHandle, Title: "newTitle"}
+	expectedError := errortypes.PostEditForbiddenError{Post: types.Post{URLHandle: postModel.URLHandle}, UserName: reviewer.UserName}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+
+	_, err := c.sut.UpdatePost(&input, reviewer.UserName)
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
+
+// TestPostService_UpdatePost_Forbidden tests updating a post by a user who didn't contribute to it.
+func TestPostService_UpdatePost_Forbidden(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Author: repository.User{UserName: "testAuthor"}}
+	input := types.Post{URLHandle: postModel.URLHandle}
+	expectedError := errortypes.PostEditForbiddenError{Post: types.Post{URLHandle: postModel.URLHandle}, UserName: "stranger"}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+
+	_, err := c.sut.UpdatePost(&input, "stranger")
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
+
+// TestPostService_UpdatePost_Not_Found tests updating a non-existent post.
+func TestPostService_UpdatePost_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	expectedError := errortypes.PostNotFoundError{Post: types.Post{URLHandle: "testUrlHandle"}}
+	c.mostPostRepository.EXPECT().GetPost("testUrlHandle").Return(nil, expectedError)
+
+	_, err := c.sut.UpdatePost(&types.Post{URLHandle: "testUrlHandle"}, "testAuthor")
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
+
+// TestPostService_SetPostContributors tests replacing the contributors of a post.
+func TestPostService_SetPostContributors(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+	c.mockEventBus.EXPECT().Dispatch()
+
+	author := repository.User{ID: 1, UserName: "testAuthor"}
+	editor := repository.User{ID: 2, UserName: "testEditor"}
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Author: author}
+	input := []types.Contributor{{UserName: editor.UserName, Role: types.ContributorRoleEditor}}
+	models := []repository.Contributor{{UserID: editor.ID, User: editor, Role: types.ContributorRoleEditor}}
+	expected := []types.Contributor{
+		{UserName: author.UserName, Role: types.ContributorRoleAuthor},
+		{UserName: editor.UserName, Role: types.ContributorRoleEditor},
+	}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+	c.mostUserRepository.EXPECT().GetUser(editor.UserName).Return(&editor, nil)
+	c.mostPostRepository.EXPECT().SetContributors(postModel.ID, models, gomock.Any()).Return(nil)
+
+	p, err := c.sut.SetPostContributors(postModel.URLHandle, input, author.UserName)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, expected, p.Contributors, "contributors don't match the expected ones")
+}
+
+// TestPostService_SetPostContributors_Forbidden tests replacing the contributors of a post by a co-author.
+func TestPostService_SetPostContributors_Forbidden(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Author: repository.User{UserName: "testAuthor"}}
+	expectedError := errortypes.PostEditForbiddenError{Post: types.Post{URLHandle: postModel.URLHandle}, UserName: "testEditor"}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+
+	_, err := c.sut.SetPostContributors(postModel.URLHandle, []types.Contributor{}, "testEditor")
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
+
+// TestPostService_SetPostContributors_Unknown_User tests adding a non-existent user as contributor.
+func TestPostService_SetPostContributors_Unknown_User(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Author: repository.User{UserName: "testAuthor"}}
+	expectedError := errortypes.UserNotFoundError{User: types.User{UserName: "unknown"}}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+	c.mostUserRepository.EXPECT().GetUser("unknown").Return(nil, expectedError)
+
+	_, err := c.sut.SetPostContributors(postModel.URLHandle, []types.Contributor{{UserName: "unknown", Role: types.ContributorRoleReviewer}}, "testAuthor")
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
+
+// TestPostService_AddPost_Invalid_Visibility tests adding a new post with an unsupported visibility setting.
+func TestPostService_AddPost_Invalid_Visibility(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	author := repository.User{ID: 1, UserName: "testAuthor"}
+	newPost := types.Post{URLHandle: "testUrlHandle", Author: author.UserName, Visibility: "hidden"}
+
+	c.mostUserRepository.EXPECT().GetUser(author.UserName).Return(&author, nil)
+
+	_, err := c.sut.AddPost(&newPost)
+
+	assert.Equal(t, errortypes.InvalidPostVisibilityError{Visibility: "hidden"}, err, "error doesn't match expected one")
+}
+
+// TestPostService_AddPost_Invalid_Canonical_URL tests adding a post with a canonical URL of an unsupported scheme.
+func TestPostService_AddPost_Invalid_Canonical_URL(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	author := repository.User{ID: 1, UserName: "testAuthor"}
+	newPost := types.Post{URLHandle: "testUrlHandle", Author: author.UserName, SEO: &types.PostSEO{CanonicalURL: "ftp://example.com/post"}}
+
+	c.mostUserRepository.EXPECT().GetUser(author.UserName).Return(&author, nil)
+	c.mockFieldRepository.EXPECT().GetFields().Return(nil, nil)
+
+	_, err := c.sut.AddPost(&newPost)
+
+	assert.Equal(t, errortypes.InvalidCanonicalURLError{URL: "ftp://example.com/post"}, err, "error doesn't match expected one")
+}
+
+// TestPostService_AddPost_Invalid_Cover_Image tests adding posts with cover images which aren't URLs or paths of the blog.
+func TestPostService_AddPost_Invalid_Cover_Image(t *testing.T) {
+	t.Parallel()
+
+	for _, image := range []string{"cover.png", "//example.com/cover.png", "javascript:alert(1)"} {
+		c := createPostServiceContext(t)
+
+		author := repository.User{ID: 1, UserName: "testAuthor"}
+		image := image
+		newPost := types.Post{URLHandle: "testUrlHandle", Author: author.UserName, CoverImage: &image}
+
+		c.mostUserRepository.EXPECT().GetUser(author.UserName).Return(&author, nil)
+		c.mockFieldRepository.EXPECT().GetFields().Return(nil, nil)
+
+		_, err := c.sut.AddPost(&newPost)
+
+		assert.Equal(t, errortypes.InvalidCoverImageError{URL: image}, err, "error doesn't match expected one")
+	}
+}
+
+// TestPostService_AddPost_Missing_Password tests adding a password-protected post without password.
+func TestPostService_AddPost_Missing_Password(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	author := repository.User{ID: 1, UserName: "testAuthor"}
+	newPost := types.Post{URLHandle: "testUrlHandle", Author: author.UserName, Visibility: types.VisibilityPassword}
+
+	c.mostUserRepository.EXPECT().GetUser(author.UserName).Return(&author, nil)
+
+	_, err := c.sut.AddPost(&newPost)
+
+	assert.Equal(t, errortypes.MissingPostPasswordError{}, err, "error doesn't match expected one")
+}
+
+// TestPostService_AddPost_Password tests adding a password-protected post. Only the hash of the password should be stored.
+func TestPostService_AddPost_Password(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+	c.mockEventBus.EXPECT().Dispatch()
+
+	author := repository.User{ID: 1, UserName: "testAuthor"}
+	newPost := types.Post{URLHandle: "testUrlHandle", Author: author.UserName, Visibility: types.VisibilityPassword, Password: "secret"}
+	postModel := repository.Post{ID: 1, URLHandle: newPost.URLHandle, Visibility: types.VisibilityPassword}
+
+	c.mostUserRepository.EXPECT().GetUser(author.UserName).Return(&author, nil)
+	c.mockFieldRepository.EXPECT().GetFields().Return(nil, nil)
+	c.mostPostRepository.EXPECT().AddPost(&newPost, author.ID, []repository.Contributor{}, gomock.Any()).Return(&postModel, nil)
+
+	p, err := c.sut.AddPost(&newPost)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.True(t, auth.CompareStringWithHash("secret", newPost.PasswordHash), "password hash should be calculated")
+	assert.Equal(t, "", newPost.Password, "password should be cleared after hashing")
+	assert.Equal(t, "", p.Password, "password shouldn't be returned")
+	assert.Equal(t, types.VisibilityPassword, p.Visibility, "visibility should be returned")
+}
+
+// TestPostService_UpdatePost_Keep_Password tests updating a password-protected post without changing its password.
+func TestPostService_UpdatePost_Keep_Password(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+	c.mockEventBus.EXPECT().Dispatch()
+
+	author := repository.User{ID: 1, UserName: "testAuthor"}
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Author: author, Visibility: types.VisibilityPassword, PasswordHash: "hash"}
+	input := types.Post{URLHandle: postModel.URLHandle, Title: "newTitle"}
+	expectedInput := types.Post{URLHandle: postModel.URLHandle, Title: "newTitle", Visibility: types.VisibilityPassword, PasswordHash: "hash"}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+	c.mostPostRepository.EXPECT().UpdatePost(&expectedInput, gomock.Any()).Return(&postModel, nil)
+
+	_, err := c.sut.UpdatePost(&input, author.UserName)
+
+	assert.Nil(t, err, "should complete without error")
+}
+
+// TestPostService_GetPost_Private tests getting a private post as a user who didn't contribute to it.
+func TestPostService_GetPost_Private(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Author: repository.User{UserName: "testAuthor"}, Visibility: types.VisibilityPrivate}
+	expectedError := errortypes.PostNotFoundError{Post: types.Post{URLHandle: postModel.URLHandle}}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+
+	_, err := c.sut.GetPost(postModel.URLHandle, types.PostAccess{UserName: "stranger"}, nil)
+
+	assert.Equal(t, expectedError, err, "private posts should be hidden")
+}
+
+// TestPostService_GetPost_Private_Author tests getting a private post as its author.
+func TestPostService_GetPost_Private_Author(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Author: repository.User{UserName: "testAuthor"}, Visibility: types.VisibilityPrivate}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+	c.mostSeriesRepository.EXPECT().GetPostSeries(postModel.ID).Return(nil, nil)
+	c.mockReactionRepository.EXPECT().CountReactions([]uint{postModel.ID}).Return(map[uint]map[string]int{}, nil)
+
+	p, err := c.sut.GetPost(postModel.URLHandle, types.PostAccess{UserName: "testAuthor"}, nil)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, postModel.URLHandle, p.URLHandle, "post doesn't match the expected output")
+}
+
+// TestPostService_GetPost_Password_Missing_Token tests getting a password-protected post without access token.
+func TestPostService_GetPost_Password_Missing_Token(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Visibility: types.VisibilityPassword}
+	expectedError := errortypes.PostPasswordRequiredError{Post: types.Post{URLHandle: postModel.URLHandle}}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+
+	_, err := c.sut.GetPost(postModel.URLHandle, types.PostAccess{}, nil)
+
+	assert.Equal(t, expectedError, err, "access token should be required")
+}
+
+// TestPostService_GetPost_Password_Token tests getting a password-protected post with a valid access token.
+func TestPostService_GetPost_Password_Token(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Visibility: types.VisibilityPassword}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+	c.mockJwtUtils.EXPECT().ParsePostAccessJWT("token").Return(postModel.URLHandle, nil)
+	c.mostSeriesRepository.EXPECT().GetPostSeries(postModel.ID).Return(nil, nil)
+	c.mockReactionRepository.EXPECT().CountReactions([]uint{postModel.ID}).Return(map[uint]map[string]int{}, nil)
+
+	p, err := c.sut.GetPost(postModel.URLHandle, types.PostAccess{AccessToken: "token"}, nil)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, postModel.URLHandle, p.URLHandle, "post doesn't match the expected output")
+}
+
+// TestPostService_GetPost_Password_Foreign_Token tests getting a password-protected post with a token issued for another post.
+func TestPostService_GetPost_Password_Foreign_Token(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Visibility: types.VisibilityPassword}
+	expectedError := errortypes.PostPasswordRequiredError{Post: types.Post{URLHandle: postModel.URLHandle}}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+	c.mockJwtUtils.EXPECT().ParsePostAccessJWT("token").Return("otherPost", nil)
+
+	_, err := c.sut.GetPost(postModel.URLHandle, types.PostAccess{AccessToken: "token"}, nil)
+
+	assert.Equal(t, expectedError, err, "token of another post should be rejected")
+}
+
+// TestPostService_AuthorizePostAccess tests requesting an access token with the correct password.
+func TestPostService_AuthorizePostAccess(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	hash, _ := auth.HashString("secret")
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Visibility: types.VisibilityPassword, PasswordHash: hash}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+	c.mockJwtUtils.EXPECT().GeneratePostAccessJWT(postModel.URLHandle).Return("token", nil)
+
+	token, err := c.sut.AuthorizePostAccess(postModel.URLHandle, "secret")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "token", token, "token doesn't match the expected one")
+}
+
+// TestPostService_AuthorizePostAccess_Incorrect_Password tests requesting an access token with an incorrect password.
+func TestPostService_AuthorizePostAccess_Incorrect_Password(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	hash, _ := auth.HashString("secret")
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Visibility: types.VisibilityPassword, PasswordHash: hash}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+
+	_, err := c.sut.AuthorizePostAccess(postModel.URLHandle, "wrong")
+
+	assert.Equal(t, errortypes.IncorrectPostPasswordError{}, err, "error doesn't match expected one")
+}
+
+// TestPostService_AuthorizePostAccess_Not_Protected tests requesting an access token for a public post.
+func TestPostService_AuthorizePostAccess_Not_Protected(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Visibility: types.VisibilityPublic}
+	expectedError := errortypes.PostNotPasswordProtectedError{Post: types.Post{URLHandle: postModel.URLHandle}}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+
+	_, err := c.sut.AuthorizePostAccess(postModel.URLHandle, "secret")
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
+
+// TestPostService_AuthorizePostAccess_Private tests requesting an access token for a private post.
+func TestPostService_AuthorizePostAccess_Private(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Visibility: types.VisibilityPrivate}
+	expectedError := errortypes.PostNotFoundError{Post: types.Post{URLHandle: postModel.URLHandle}}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+
+	_, err := c.sut.AuthorizePostAccess(postModel.URLHandle, "secret")
+
+	assert.Equal(t, expectedError, err, "private posts should be hidden")
+}
+
+// createTranslatedPostModel creates an English post model with a German translation for testing purposes.
+func createTranslatedPostModel() repository.Post {
+	return repository.Post{
+		ID:        1,
+		URLHandle: "testUrlHandle",
+		Author:    repository.User{UserName: "testAuthor"},
+		Title:     "testTitle",
+		Summary:   "testSummary",
+		Body:      "testBody",
+		Language:  "en",
+		Translations: []repository.PostTranslation{
+			{PostID: 1, Language: "de", Title: "Titel", Summary: "Zusammenfassung", Body: "Text"},
+		},
+	}
+}
+
+// TestPostService_AddPost_Invalid_Language tests adding a new post with an invalid language tag.
+func TestPostService_AddPost_Invalid_Language(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	author := repository.User{ID: 1, UserName: "testAuthor"}
+	newPost := types.Post{URLHandle: "testUrlHandle", Author: author.UserName, Language: "english"}
+
+	c.mostUserRepository.EXPECT().GetUser(author.UserName).Return(&author, nil)
+
+	_, err := c.sut.AddPost(&newPost)
+
+	assert.Equal(t, errortypes.InvalidLanguageError{Language: "english"}, err, "error doesn't match expected one")
+}
+
+// TestPostService_GetPost_Translation tests getting a post in the preferred language.
+func TestPostService_GetPost_Translation(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	postModel := createTranslatedPostModel()
+	expectedLinks := []types.AlternateLink{
+		{HrefLang: "en", Href: "/posts/testUrlHandle?lang=en"},
+		{HrefLang: "de", Href: "/posts/testUrlHandle?lang=de"},
+	}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+	c.mostSeriesRepository.EXPECT().GetPostSeries(postModel.ID).Return(nil, nil)
+	c.mockReactionRepository.EXPECT().CountReactions([]uint{postModel.ID}).Return(map[uint]map[string]int{}, nil)
+
+	p, err := c.sut.GetPost(postModel.URLHandle, types.PostAccess{}, []string{"fr", "de-AT", "en"})
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "de", p.Language, "the best matching language should be selected")
+	assert.Equal(t, "Titel", p.Title, "title should be translated")
+	assert.Equal(t, "Zusammenfassung", p.Summary, "summary should be translated")
+	assert.Equal(t, "Text", p.Body, "body should be translated")
+	assert.Equal(t, expectedLinks, p.Translations, "every language version should be linked")
+}
+
+// TestPostService_GetPost_Original_Language tests falling back to the original language of a post.
+func TestPostService_GetPost_Original_Language(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	postModel := createTranslatedPostModel()
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+	c.mostSeriesRepository.EXPECT().GetPostSeries(postModel.ID).Return(nil, nil)
+	c.mockReactionRepository.EXPECT().CountReactions([]uint{postModel.ID}).Return(map[uint]map[string]int{}, nil)
+
+	p, err := c.sut.GetPost(postModel.URLHandle, types.PostAccess{}, []string{"fr"})
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "en", p.Language, "the original language should be used")
+	assert.Equal(t, "testTitle", p.Title, "title shouldn't be translated")
+}
+
+// TestPostService_GetPosts_Language tests getting every post available in a given language.
+func TestPostService_GetPosts_Language(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	postModels := []repository.Post{createTranslatedPostModel()}
+
+	c.mostPostRepository.EXPECT().GetPosts(types.PostFilter{Language: "de"}).Return(postModels, nil)
+	c.mockReactionRepository.EXPECT().CountReactions([]uint{postModels[0].ID}).Return(map[uint]map[string]int{}, nil)
+
+	p, err := c.sut.GetPosts(types.PostFilter{Language: "de"})
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 1, len(p), "should return exactly one post")
+	assert.Equal(t, "de", p[0].Language, "post language should be the requested one")
+	assert.Equal(t, "Titel", p[0].Title, "title should be translated")
+	assert.Equal(t, "", p[0].Body, "listings shouldn't contain the body")
+}
+
+// TestPostService_SetPostTranslation tests adding a new translation to a post.
+func TestPostService_SetPostTranslation(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+	c.mockEventBus.EXPECT().Dispatch()
+
+	postModel := createTranslatedPostModel()
+	translation := types.PostTranslation{Language: "FR", Title: "Titre", Summary: "Résumé", Body: "Texte"}
+	expectedModel := repository.PostTranslation{PostID: postModel.ID, Language: "fr", Title: "Titre", Summary: "Résumé", Body: "Texte", Excerpt: "Résumé", WordCount: 1, ReadingTime: 1}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+	c.mostPostRepository.EXPECT().SetTranslation(&expectedModel, gomock.Any()).Return(nil)
+
+	p, err := c.sut.SetPostTranslation(postModel.URLHandle, translation, "testAuthor")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "fr", p.Language, "the new translation should be returned")
+	assert.Equal(t, "Titre", p.Title, "title should be translated")
+	assert.Equal(t, 3, len(p.Translations), "every language version should be linked")
+}
+
+// TestPostService_SetPostTranslation_Forbidden tests translating a post by a user who didn't contribute to it.
+func TestPostService_SetPostTranslation_Forbidden(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	postModel := createTranslatedPostModel()
+	expectedError := errortypes.PostEditForbiddenError{Post: types.Post{URLHandle: postModel.URLHandle}, UserName: "stranger"}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+
+	_, err := c.sut.SetPostTranslation(postModel.URLHandle, types.PostTranslation{Language: "fr"}, "stranger")
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
+
+// TestPostService_SetPostTranslation_Original_Language tests translating a post to its original language.
+func TestPostService_SetPostTranslation_Original_Language(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	postModel := createTranslatedPostModel()
+	expectedError := errortypes.OriginalLanguageTranslationError{Post: types.Post{URLHandle: postModel.URLHandle}, Language: "en"}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+
+	_, err := c.sut.SetPostTranslation(postModel.URLHandle, types.PostTranslation{Language: "en"}, "testAuthor")
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
+
+// TestPostService_SetPostTranslation_Invalid_Language tests translating a post to an invalid language.
+func TestPostService_SetPostTranslation_Invalid_Language(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	postModel := createTranslatedPostModel()
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+
+	_, err := c.sut.SetPostTranslation(postModel.URLHandle, types.PostTranslation{Language: "*"}, "testAuthor")
+
+	assert.Equal(t, errortypes.InvalidLanguageError{Language: "*"}, err, "error doesn't match expected one")
+}
+
+// TestPostService_DeletePostTranslation tests removing the translation of a post.
+func TestPostService_DeletePostTranslation(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+	c.mockEventBus.EXPECT().Dispatch()
+
+	postModel := createTranslatedPostModel()
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+	c.mostPostRepository.EXPECT().DeleteTranslation(postModel.ID, "de", gomock.Any()).Return(nil)
+
+	err := c.sut.DeletePostTranslation(postModel.URLHandle, "de", "testAuthor")
+
+	assert.Nil(t, err, "should complete without error")
+}
+
+// TestPostService_DeletePostTranslation_Not_Found tests removing a non-existent translation of a post.
+func TestPostService_DeletePostTranslation_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	postModel := createTranslatedPostModel()
+	expectedError := errortypes.TranslationNotFoundError{Post: types.Post{URLHandle: postModel.URLHandle}, Language: "fr"}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+
+	err := c.sut.DeletePostTranslation(postModel.URLHandle, "fr", "testAuthor")
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
+
+// TestPostService_DeletePost tests removing a post.
+func TestPostService_DeletePost(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	postModel := createTranslatedPostModel()
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+	expectedEvent := types.PostDeletedEvent{Post: types.Post{URLHandle: postModel.URLHandle, Title: postModel.Title, Author: "testAuthor", Contributors: []types.Contributor{{UserName: "testAuthor", Role: types.ContributorRoleAuthor}}, Summary: postModel.Summary, Language: postModel.Language}}
+
+	c.mostPostRepository.EXPECT().DeletePost(postModel.ID, []types.DomainEvent{expectedEvent}).Return(nil)
+	c.mockEventBus.EXPECT().Dispatch()
+
+	err := c.sut.DeletePost(postModel.URLHandle, "testAuthor")
+
+	assert.Nil(t, err, "should complete without error")
+}
+
+// TestPostService_DeletePost_Forbidden tests removing a post by a user other than its primary author.
+func TestPostService_DeletePost_Forbidden(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	postModel := createTranslatedPostModel()
+	expectedError := errortypes.PostDeleteForbiddenError{Post: types.Post{URLHandle: postModel.URLHandle}, UserName: "testEditor"}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+
+	err := c.sut.DeletePost(postModel.URLHandle, "testEditor")
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
+
+// createFieldModels creates custom field definitions of every supported type for testing purposes.
+func createFieldModels() []repository.FieldDefinition {
+	return []repository.FieldDefinition{
+		{Name: "canonical", Type: types.FieldTypeURL},
+		{Name: "category", Type: types.FieldTypeEnum, Options: []string{"news", "howto"}, Required: true},
+		{Name: "caption", Type: types.FieldTypeString},
+		{Name: "published", Type: types.FieldTypeDate},
+		{Name: "rating", Type: types.FieldTypeNumber},
+	}
+}
+
+// TestPostService_AddPost_Custom_Fields tests adding a new post with valid custom field values.
+func TestPostService_AddPost_Custom_Fields(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+	c.mockEventBus.EXPECT().Dispatch()
+
+	author := repository.User{ID: 1, UserName: "testAuthor"}
+	fields := map[string]interface{}{
+		"canonical": "https://example.com/post",
+		"category":  "news",
+		"caption":   "A caption",
+		"published": "2023-05-01",
+		"rating":    4.5,
+	}
+	newPost := types.Post{URLHandle: "testUrlHandle", Author: author.UserName, CustomFields: fields}
+	postModel := repository.Post{ID: 1, URLHandle: newPost.URLHandle, CustomFields: fields}
+
+	c.mostUserRepository.EXPECT().GetUser(author.UserName).Return(&author, nil)
+	c.mockFieldRepository.EXPECT().GetFields().Return(createFieldModels(), nil)
+	c.mostPostRepository.EXPECT().AddPost(&newPost, author.ID, []repository.Contributor{}, gomock.Any()).Return(&postModel, nil)
+
+	p, err := c.sut.AddPost(&newPost)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, fields, p.CustomFields, "custom fields should be returned")
+}
+
+// TestPostService_AddPost_Invalid_Custom_Fields tests adding a new post with invalid custom field values.
+func TestPostService_AddPost_Invalid_Custom_Fields(t *testing.T) {
+	t.Parallel()
+
+	cases := []map[string]interface{}{
+		{"category": "news", "unknown": "value"},
+		{"category": "other"},
+		{"category": "news", "canonical": "/relative/path"},
+		{"category": "news", "caption": 42.0},
+		{"category": "news", "published": "01/05/2023"},
+		{"category": "news", "rating": "five"},
+	}
+
+	for _, fields := range cases {
+		c := createPostServiceContext(t)
+
+		author := repository.User{ID: 1, UserName: "testAuthor"}
+		newPost := types.Post{URLHandle: "testUrlHandle", Author: author.UserName, CustomFields: fields}
+
+		c.mostUserRepository.EXPECT().GetUser(author.UserName).Return(&author, nil)
+		c.mockFieldRepository.EXPECT().GetFields().Return(createFieldModels(), nil)
+
+		_, err := c.sut.AddPost(&newPost)
+
+		assert.IsType(t, errortypes.InvalidCustomFieldError{}, err, "custom fields %v should be rejected", fields)
+	}
+}
+
+// TestPostService_AddPost_Missing_Custom_Field tests adding a new post without a required custom field.
+func TestPostService_AddPost_Missing_Custom_Field(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	author := repository.User{ID: 1, UserName: "testAuthor"}
+	newPost := types.Post{URLHandle: "testUrlHandle", Author: author.UserName}
+
+	c.mostUserRepository.EXPECT().GetUser(author.UserName).Return(&author, nil)
+	c.mockFieldRepository.EXPECT().GetFields().Return(createFieldModels(), nil)
+
+	_, err := c.sut.AddPost(&newPost)
+
+	assert.Equal(t, errortypes.MissingCustomFieldError{Name: "category"}, err, "error doesn't match expected one")
+}
+
+// TestPostService_UpdatePost_Keep_Custom_Fields tests updating a post without changing its custom fields.
+func TestPostService_UpdatePost_Keep_Custom_Fields(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+	c.mockEventBus.EXPECT().Dispatch()
+
+	author := repository.User{ID: 1, UserName: "testAuthor"}
+	fields := map[string]interface{}{"category": "news"}
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Author: author, Visibility: types.VisibilityPublic, CustomFields: fields}
+	input := types.Post{URLHandle: postModel.URLHandle, Title: "newTitle"}
+	expectedInput := types.Post{URLHandle: postModel.URLHandle, Title: "newTitle", Visibility: types.VisibilityPublic, CustomFields: fields}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+	c.mostPostRepository.EXPECT().UpdatePost(&expectedInput, gomock.Any()).Return(&postModel, nil)
+
+	_, err := c.sut.UpdatePost(&input, author.UserName)
+
+	assert.Nil(t, err, "should complete without error")
+}
+
+// TestPostService_UpdatePost_Invalid_Custom_Fields tests updating a post with invalid custom field values.
+func TestPostService_UpdatePost_Invalid_Custom_Fields(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	author := repository.User{ID: 1, UserName: "testAuthor"}
+	postModel := repository.Post{ID: 1, URLHandle: "testUrlHandle", Author: author}
+	input := types.Post{URLHandle: postModel.URLHandle, CustomFields: map[string]interface{}{"category": "other"}}
+
+	c.mostPostRepository.EXPECT().GetPost(postModel.URLHandle).Return(&postModel, nil)
+	c.mockFieldRepository.EXPECT().GetFields().Return(createFieldModels(), nil)
+
+	_, err := c.sut.UpdatePost(&input, author.UserName)
+
+	assert.IsType(t, errortypes.InvalidCustomFieldError{}, err, "error doesn't match expected one")
+}
+
+// TestPostService_GetPosts_Custom_Fields tests getting every post filtered by custom fields.
+func TestPostService_GetPosts_Custom_Fields(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	filter := types.PostFilter{Fields: map[string]string{"category": "news"}}
+
+	c.mockFieldRepository.EXPECT().GetFields().Return(createFieldModels(), nil)
+	c.mostPostRepository.EXPECT().GetPosts(filter).Return([]repository.Post{}, nil)
+	c.mockReactionRepository.EXPECT().CountReactions([]uint{}).Return(map[uint]map[string]int{}, nil)
+
+	p, err := c.sut.GetPosts(filter)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, []types.Post{}, p, "should return no posts")
+}
+
+// TestPostService_GetPosts_Unknown_Custom_Field tests filtering posts by an undefined custom field.
+func TestPostService_GetPosts_Unknown_Custom_Field(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	filter := types.PostFilter{Fields: map[string]string{"unknown": "value"}}
+	expectedError := errortypes.InvalidCustomFieldError{Name: "unknown", Reason: "unknown custom field"}
+
+	c.mockFieldRepository.EXPECT().GetFields().Return(createFieldModels(), nil)
+
+	_, err := c.sut.GetPosts(filter)
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
+
+// TestPostService_GetPosts_Unknown_Collection tests filtering posts by a non-existent collection.
+func TestPostService_GetPosts_Unknown_Collection(t *testing.T) {
+	t.Parallel()
+	c := createPostServiceContext(t)
+
+	filter := types.PostFilter{Collection: "missing"}
+	expectedError := errortypes.CollectionNotFoundError{Collection: types.Collection{URLHandle: "missing"}}
+
+	c.mockCollectionRepository.EXPECT().GetCollection("missing").Return(nil, expectedError)
+
+	_, err := c.sut.GetPosts(filter)
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/types"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultReactionEmojis is the default set of emojis readers can react with.
+const defaultReactionEmojis = "👍,❤️,🎉,😄,🤔"
+
+// ReactionService interface. Defines the reactions of readers to the posts.
+type ReactionService interface {
+	AddReaction(urlHandle string, emoji string, reader types.ReactionReader) (types.PostReactions, error)
+	GetReactions(urlHandle string, reader types.ReactionReader) (types.PostReactions, error)
+	RemoveReaction(urlHandle string, emoji string, reader types.ReactionReader) (types.PostReactions, error)
+}
+
+// reactionService is the concrete implementation of the ReactionService interface.
+type reactionService struct {
+	cont     container.Container
+	config   reactionConfig
+	throttle *throttle
+}
+
+// reactionConfig contains the emoji set, the secret of the reader fingerprints and the throttling settings.
+type reactionConfig struct {
+	Emojis     []string
+	Secret     []byte
+	RateLimit  int
+	RateWindow time.Duration
+}
+
+// CreateReactionService instantiates the reactionService using the application container.
+func CreateReactionService(cont container.Container) ReactionService {
+	config := loadReactionConfig(cont)
+	return &reactionService{cont, config, createThrottle()}
+}
+
+// loadReactionConfig reads the settings of the reactions from the REACTION_* environment variables.
+// If no secret is set, a random one is generated, so the fingerprints of anonymous readers change on every restart.
+func loadReactionConfig(cont container.Container) reactionConfig {
+	config := reactionConfig{
+		Secret:     []byte(os.Getenv("REACTION_SECRET")),
+		RateLimit:  30,
+		RateWindow: time.Hour,
+	}
+
+	emojis := os.Getenv("REACTION_EMOJIS")
+	if strings.TrimSpace(emojis) == "" {
+		emojis = defaultReactionEmojis
+	}
+	for _, emoji := range strings.Split(emojis, ",") {
+		if emoji = strings.TrimSpace(emoji); emoji != "" && !containsString(config.Emojis, emoji) {
+			config.Emojis = append(config.Emojis, emoji)
+		}
+	}
+
+	if len(config.Secret) == 0 {
+		cont.GetLogger().Warn("REACTION_SECRET is not set, anonymous readers can react again after a restart")
+		config.Secret = make([]byte, 32)
+		_, _ = rand.Read(config.Secret)
+	}
+
+	if limit, err := strconv.Atoi(os.Getenv("REACTION_RATE_LIMIT")); err == nil && limit > 0 {
+		config.RateLimit = limit
+	}
+	if window, err := time.ParseDuration(os.Getenv("REACTION_RATE_WINDOW")); err == nil && window > 0 {
+		config.RateWindow = window
+	}
+
+	return config
+}
+
+// AddReaction adds the reaction of a reader to a public or unlisted post. Reacting again with the same emoji has no effect.
+func (r reactionService) AddReaction(urlHandle string, emoji string, reader types.ReactionReader) (types.PostReactions, error) {
+	log := r.cont.GetLogger()
+	reactionRepository := r.cont.GetReactionRepository()
+
+	if !containsString(r.config.Emojis, emoji) {
+		return types.PostReactions{}, errortypes.InvalidReactionError{Emoji: emoji}
+	}
+
+	post, fingerprint, err := r.prepareReaction(urlHandle, reader)
+	if err != nil {
+		return types.PostReactions{}, err
+	}
+
+	log.Debugf("adding reaction %s to post %s", emoji, urlHandle)
+
+	if err := reactionRepository.AddReaction(&repository.Reaction{PostID: post.ID, Emoji: emoji, Fingerprint: fingerprint}); err != nil {
+		return types.PostReactions{}, err
+	}
+
+	return r.postReactions(post.ID, fingerprint)
+}
+
+// GetReactions retrieves the reactions to a public or unlisted post and the emojis the reader reacted with.
+// Every emoji of the set is counted, including the ones nobody reacted with yet.
+func (r reactionService) GetReactions(urlHandle string, reader types.ReactionReader) (types.PostReactions, error) {
+	post, err := r.getPost(urlHandle)
+	if err != nil {
+		return types.PostReactions{}, err
+	}
+
+	fingerprint, err := r.fingerprint(post.ID, reader)
+	if err != nil {
+		return types.PostReactions{}, err
+	}
+
+	return r.postReactions(post.ID, fingerprint)
+}
+
+// RemoveReaction removes the reaction of a reader. Removing a missing reaction has no effect.
+func (r reactionService) RemoveReaction(urlHandle string, emoji string, reader types.ReactionReader) (types.PostReactions, error) {
+	log := r.cont.GetLogger()
+	reactionRepository := r.cont.GetReactionRepository()
+
+	post, fingerprint, err := r.prepareReaction(urlHandle, reader)
+	if err != nil {
+		return types.PostReactions{}, err
+	}
+
+	log.Debugf("removing reaction %s from post %s", emoji, urlHandle)
+
+	if err := reactionRepository.RemoveReaction(post.ID, emoji, fingerprint); err != nil {
+		return types.PostReactions{}, err
+	}
+
+	return r.postReactions(post.ID, fingerprint)
+}
+
+// prepareReaction retrieves the post a reader reacts to and the fingerprint of the reader.
+// Readers changing their reactions too often are throttled.
+func (r reactionService) prepareReaction(urlHandle string, reader types.ReactionReader) (*repository.Post, string, error) {
+	log := r.cont.GetLogger()
+
+	post, err := r.getPost(urlHandle)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Anonymous readers are throttled by their address, so changing the user agent doesn't help
+	key := "user:" + reader.UserName
+	if reader.UserName == "" {
+		key = r.hash("address", reader.Address)
+	}
+	if retryAfter, ok := r.throttle.allow(key, r.config.RateLimit, r.config.RateWindow, time.Now()); !ok {
+		log.Debugf("throttled reaction to post %s", urlHandle)
+		return nil, "", errortypes.ReactionRateLimitError{RetryAfter: retryAfter}
+	}
+
+	fingerprint, err := r.fingerprint(post.ID, reader)
+	return post, fingerprint, err
+}
+
+// getPost retrieves a post readers can react to. The existence of the other posts isn't revealed.
+func (r reactionService) getPost(urlHandle string) (*repository.Post, error) {
+	postRepository := r.cont.GetPostRepository()
+
+	post, err := postRepository.GetPost(urlHandle)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.Visibility != types.VisibilityPublic && post.Visibility != types.VisibilityUnlisted {
+		return nil, errortypes.PostNotFoundError{Post: types.Post{URLHandle: urlHandle}}
+	}
+
+	return post, nil
+}
+
+// fingerprint identifies a reader of a post. Logged-in users are identified by their ID, anonymous readers by a keyed
+// hash of the post, their address and their user agent, which can't be linked to the reader or across posts.
+func (r reactionService) fingerprint(postID uint, reader types.ReactionReader) (string, error) {
+	userRepository := r.cont.GetUserRepository()
+
+	if reader.UserName == "" {
+		return r.hash(strconv.FormatUint(uint64(postID), 10), reader.Address, reader.UserAgent), nil
+	}
+
+	user, err := userRepository.GetUser(reader.UserName)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("user:%d", user.ID), nil
+}
+
+// hash computes the keyed hash of the given values.
+func (r reactionService) hash(values ...string) string {
+	mac := hmac.New(sha256.New, r.config.Secret)
+	mac.Write([]byte(strings.Join(values, "\x00")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// postReactions counts the reactions to a post and retrieves the emojis the reader reacted with.
+func (r reactionService) postReactions(postID uint, fingerprint string) (types.PostReactions, error) {
+	reactionRepository := r.cont.GetReactionRepository()
+
+	counts, err := reactionRepository.CountReactions([]uint{postID})
+	if err != nil {
+		return types.PostReactions{}, err
+	}
+
+	reacted, err := reactionRepository.GetReactedEmojis(postID, fingerprint)
+	if err != nil {
+		return types.PostReactions{}, err
+	}
+
+	result := types.PostReactions{Counts: make(map[string]int, len(r.config.Emojis)), Reacted: reacted}
+	for _, emoji := range r.config.Emojis {
+		result.Counts[emoji] = 0
+	}
+	for emoji, count := range counts[postID] {
+		result.Counts[emoji] = count
+	}
+
+	return result, nil
+}
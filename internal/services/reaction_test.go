@@ -0,0 +1,166 @@
+package services_test
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/types"
+	"testing"
+)
+
+// reactionReader is the anonymous reader reacting in the tests.
+var reactionReader = types.ReactionReader{Address: "192.0.2.1", UserAgent: "test-agent"}
+
+// reactionTestContext contains objects relevant for testing the ReactionService.
+type reactionTestContext struct {
+	mockPostRepository     *mocks.MockPostRepository
+	mockReactionRepository *mocks.MockReactionRepository
+	mockUserRepository     *mocks.MockUserRepository
+	sut                    services.ReactionService
+}
+
+// createReactionServiceContext creates the context for testing the ReactionService and reduces code duplication.
+// The emoji set and the rate limit are set in the environment, so the tests can't run in parallel.
+func createReactionServiceContext(t *testing.T) *reactionTestContext {
+	t.Helper()
+	t.Setenv("REACTION_EMOJIS", "👍, ❤️")
+	t.Setenv("REACTION_SECRET", "secret")
+	t.Setenv("REACTION_RATE_LIMIT", "2")
+
+	mockCtrl := gomock.NewController(t)
+	mockPostRepository := mocks.NewMockPostRepository(mockCtrl)
+	mockReactionRepository := mocks.NewMockReactionRepository(mockCtrl)
+	mockUserRepository := mocks.NewMockUserRepository(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, mockPostRepository, mockReactionRepository, nil, nil, mockUserRepository, nil, nil, nil, nil, nil, nil)
+	sut := services.CreateReactionService(cont)
+
+	return &reactionTestContext{mockPostRepository, mockReactionRepository, mockUserRepository, sut}
+}
+
+// createReactionPost creates a post readers react to in the tests.
+func createReactionPost(visibility string) *repository.Post {
+	return &repository.Post{ID: 1, URLHandle: "hello", Visibility: visibility}
+}
+
+// TestReactionService_AddReaction tests adding the reaction of an anonymous reader.
+func TestReactionService_AddReaction(t *testing.T) {
+	c := createReactionServiceContext(t)
+
+	var fingerprint string
+	c.mockPostRepository.EXPECT().GetPost("hello").Return(createReactionPost(types.VisibilityPublic), nil)
+	c.mockReactionRepository.EXPECT().AddReaction(gomock.Any()).DoAndReturn(func(reaction *repository.Reaction) error {
+		fingerprint = reaction.Fingerprint
+		assert.Equal(t, uint(1), reaction.PostID, "reaction should belong to the post")
+		assert.Equal(t, "👍", reaction.Emoji, "incorrect emoji")
+		return nil
+	})
+	c.mockReactionRepository.EXPECT().CountReactions([]uint{1}).Return(map[uint]map[string]int{1: {"👍": 3}}, nil)
+	c.mockReactionRepository.EXPECT().GetReactedEmojis(uint(1), gomock.Any()).Return([]string{"👍"}, nil)
+
+	reactions, err := c.sut.AddReaction("hello", "👍", reactionReader)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, types.PostReactions{Counts: map[string]int{"👍": 3, "❤️": 0}, Reacted: []string{"👍"}}, reactions, "every emoji of the set should be counted")
+	assert.Len(t, fingerprint, 64, "fingerprint should be a hash")
+	assert.NotContains(t, fingerprint, reactionReader.Address, "fingerprint shouldn't contain the address")
+}
+
+// TestReactionService_AddReaction_User tests adding the reaction of a logged-in user.
+func TestReactionService_AddReaction_User(t *testing.T) {
+	c := createReactionServiceContext(t)
+
+	reader := reactionReader
+	reader.UserName = "testUser"
+
+	c.mockPostRepository.EXPECT().GetPost("hello").Return(createReactionPost(types.VisibilityUnlisted), nil)
+	c.mockUserRepository.EXPECT().GetUser("testUser").Return(&repository.User{ID: 5, UserName: "testUser"}, nil)
+	c.mockReactionRepository.EXPECT().AddReaction(&repository.Reaction{PostID: 1, Emoji: "❤️", Fingerprint: "user:5"}).Return(nil)
+	c.mockReactionRepository.EXPECT().CountReactions([]uint{1}).Return(map[uint]map[string]int{1: {"❤️": 1}}, nil)
+	c.mockReactionRepository.EXPECT().GetReactedEmojis(uint(1), "user:5").Return([]string{"❤️"}, nil)
+
+	reactions, err := c.sut.AddReaction("hello", "❤️", reader)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, []string{"❤️"}, reactions.Reacted, "the reaction of the user should be marked")
+}
+
+// TestReactionService_AddReaction_Invalid_Emoji tests reacting with an emoji outside the configured set.
+func TestReactionService_AddReaction_Invalid_Emoji(t *testing.T) {
+	c := createReactionServiceContext(t)
+
+	_, err := c.sut.AddReaction("hello", "💩", reactionReader)
+
+	assert.Equal(t, errortypes.InvalidReactionError{Emoji: "💩"}, err, "emoji should be rejected")
+}
+
+// TestReactionService_AddReaction_Private tests reacting to a private post.
+func TestReactionService_AddReaction_Private(t *testing.T) {
+	c := createReactionServiceContext(t)
+
+	c.mockPostRepository.EXPECT().GetPost("hello").Return(createReactionPost(types.VisibilityPrivate), nil)
+
+	_, err := c.sut.AddReaction("hello", "👍", reactionReader)
+
+	assert.Equal(t, errortypes.PostNotFoundError{Post: types.Post{URLHandle: "hello"}}, err, "private post shouldn't be revealed")
+}
+
+// TestReactionService_AddReaction_Throttled tests throttling a reader changing their reactions too often.
+func TestReactionService_AddReaction_Throttled(t *testing.T) {
+	c := createReactionServiceContext(t)
+
+	c.mockPostRepository.EXPECT().GetPost("hello").Return(createReactionPost(types.VisibilityPublic), nil).Times(3)
+	c.mockReactionRepository.EXPECT().AddReaction(gomock.Any()).Return(nil).Times(2)
+	c.mockReactionRepository.EXPECT().CountReactions([]uint{1}).Return(map[uint]map[string]int{}, nil).Times(2)
+	c.mockReactionRepository.EXPECT().GetReactedEmojis(uint(1), gomock.Any()).Return([]string{}, nil).Times(2)
+
+	_, _ = c.sut.AddReaction("hello", "👍", reactionReader)
+	_, _ = c.sut.AddReaction("hello", "❤️", types.ReactionReader{Address: reactionReader.Address, UserAgent: "other-agent"})
+	_, err := c.sut.AddReaction("hello", "👍", reactionReader)
+
+	assert.IsType(t, errortypes.ReactionRateLimitError{}, err, "reader should be throttled by their address")
+	assert.Positive(t, err.(errortypes.ReactionRateLimitError).RetryAfter, "retry delay should be set")
+}
+
+// TestReactionService_GetReactions tests deriving a stable fingerprint for every post an anonymous reader visits.
+func TestReactionService_GetReactions(t *testing.T) {
+	c := createReactionServiceContext(t)
+
+	var fingerprints []string
+	c.mockPostRepository.EXPECT().GetPost("hello").Return(createReactionPost(types.VisibilityPublic), nil).Times(2)
+	c.mockPostRepository.EXPECT().GetPost("other").Return(&repository.Post{ID: 2, URLHandle: "other", Visibility: types.VisibilityPublic}, nil)
+	c.mockReactionRepository.EXPECT().CountReactions(gomock.Any()).Return(map[uint]map[string]int{}, nil).Times(3)
+	c.mockReactionRepository.EXPECT().GetReactedEmojis(gomock.Any(), gomock.Any()).Times(3).
+		DoAndReturn(func(_ uint, fingerprint string) ([]string, error) {
+			fingerprints = append(fingerprints, fingerprint)
+			return []string{}, nil
+		})
+
+	_, _ = c.sut.GetReactions("hello", reactionReader)
+	_, _ = c.sut.GetReactions("hello", reactionReader)
+	reactions, err := c.sut.GetReactions("other", reactionReader)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, map[string]int{"👍": 0, "❤️": 0}, reactions.Counts, "every emoji of the set should be counted")
+	assert.Equal(t, fingerprints[0], fingerprints[1], "fingerprint should be stable")
+	assert.NotEqual(t, fingerprints[0], fingerprints[2], "fingerprints shouldn't be linkable across posts")
+}
+
+// TestReactionService_RemoveReaction tests removing the reaction of an anonymous reader.
+func TestReactionService_RemoveReaction(t *testing.T) {
+	c := createReactionServiceContext(t)
+
+	c.mockPostRepository.EXPECT().GetPost("hello").Return(createReactionPost(types.VisibilityPublic), nil)
+	c.mockReactionRepository.EXPECT().RemoveReaction(uint(1), "👍", gomock.Any()).Return(nil)
+	c.mockReactionRepository.EXPECT().CountReactions([]uint{1}).Return(map[uint]map[string]int{}, nil)
+	c.mockReactionRepository.EXPECT().GetReactedEmojis(uint(1), gomock.Any()).Return([]string{}, nil)
+
+	reactions, err := c.sut.RemoveReaction("hello", "👍", reactionReader)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Empty(t, reactions.Reacted, "reaction should be removed")
+}
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/markdown"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/types"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Weights of the parts of a post in its term vector. Words of the title and the summary describe a post better than the
+// ones of its body.
+const (
+	relatedTitleWeight   = 3
+	relatedSummaryWeight = 2
+	relatedBodyWeight    = 1
+)
+
+// relatedTagWeight is the share of the tag similarity in the score of a related post, the rest is the text similarity.
+const relatedTagWeight = 0.4
+
+// relatedMinScore is the score a post needs to be considered related.
+const relatedMinScore = 0.05
+
+// relatedMinTermLength is the length of the shortest words taken into account.
+const relatedMinTermLength = 3
+
+// RelatedService interface. Defines the recommendations of related posts.
+type RelatedService interface {
+	GetRelatedPosts(urlHandle string, limit int) ([]types.RelatedPost, error)
+	RefreshRelatedPosts() error
+	RunRelatedPosts(stop <-chan struct{})
+}
+
+// relatedService is the concrete implementation of the RelatedService interface.
+type relatedService struct {
+	cont   container.Container
+	config relatedConfig
+	stale  *atomic.Bool
+}
+
+// relatedConfig contains the number of related posts stored per post and the interval of refreshing them.
+type relatedConfig struct {
+	MaxPosts        int
+	RefreshInterval time.Duration
+}
+
+// relatedCandidate is a post scored by its similarity to another post.
+type relatedCandidate struct {
+	post  *repository.Post
+	score float64
+}
+
+// CreateRelatedService instantiates the relatedService using the application container.
+// The service subscribes to the post events of the event bus, since every change of a post can affect the related
+// posts of every other post.
+func CreateRelatedService(cont container.Container) RelatedService {
+	r := &relatedService{cont, loadRelatedConfig(), &atomic.Bool{}}
+	r.stale.Store(true)
+
+	eventBus := cont.GetEventBus()
+	for _, event := range []string{types.EventPostCreated, types.EventPostUpdated, types.EventPostDeleted} {
+		eventBus.SubscribeAsync(event, r.markStale)
+	}
+
+	return r
+}
+
+// loadRelatedConfig reads the settings of the related posts from the RELATED_* environment variables.
+func loadRelatedConfig() relatedConfig {
+	config := relatedConfig{
+		MaxPosts:        5,
+		RefreshInterval: time.Minute,
+	}
+
+	if posts, err := strconv.Atoi(os.Getenv("RELATED_POSTS_MAX")); err == nil && posts > 0 {
+		config.MaxPosts = posts
+	}
+	if interval, err := time.ParseDuration(os.Getenv("RELATED_REFRESH_INTERVAL")); err == nil && interval > 0 {
+		config.RefreshInterval = interval
+	}
+
+	return config
+}
+
+// GetRelatedPosts retrieves the precomputed related posts of a post, most similar first.
+// Posts which aren't public anymore are skipped.
+func (r relatedService) GetRelatedPosts(urlHandle string, limit int) ([]types.RelatedPost, error) {
+	relatedRepository := r.cont.GetRelatedRepository()
+
+	rows, err := relatedRepository.GetRelatedPosts(urlHandle)
+	if err != nil {
+		return []types.RelatedPost{}, err
+	}
+
+	result := make([]types.RelatedPost, 0, len(rows))
+	for _, row := range rows {
+		if len(result) == limit {
+			break
+		}
+		if row.Related.Visibility != types.VisibilityPublic {
+			continue
+		}
+		result = append(result, types.RelatedPost{
+			URLHandle:  row.Related.URLHandle,
+			Title:      row.Related.Title,
+			Summary:    row.Related.Summary,
+			CoverImage: row.Related.CoverImage,
+			Score:      math.Round(row.Score*1000) / 1000,
+		})
+	}
+
+	return result, nil
+}
+
+// RefreshRelatedPosts recomputes the related posts of every public post. Posts are scored by the cosine similarity of
+// the TF-IDF vectors of their title, summary and body, and by the Jaccard similarity of their tags.
+func (r relatedService) RefreshRelatedPosts() error {
+	log := r.cont.GetLogger()
+	postRepository := r.cont.GetPostRepository()
+	relatedRepository := r.cont.GetRelatedRepository()
+
+	posts, err := postRepository.GetPosts(types.PostFilter{})
+	if err != nil {
+		log.Errorf("failed to retrieve the posts to relate: %v", err)
+		return err
+	}
+
+	vectors := relatedVectors(posts)
+	tags := make([]map[string]bool, len(posts))
+	for i := range posts {
+		tags[i] = map[string]bool{}
+		for _, tag := range posts[i].Tags {
+			if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+				tags[i][tag] = true
+			}
+		}
+	}
+
+	related := make([]repository.RelatedPost, 0, len(posts)*r.config.MaxPosts)
+	for i := range posts {
+		candidates := make([]relatedCandidate, 0)
+		for j := range posts {
+			if i == j {
+				continue
+			}
+			score := (1-relatedTagWeight)*cosineSimilarity(vectors[i], vectors[j]) + relatedTagWeight*jaccardSimilarity(tags[i], tags[j])
+			if score >= relatedMinScore {
+				candidates = append(candidates, relatedCandidate{&posts[j], score})
+			}
+		}
+
+		// Prefer newer posts among equally similar ones
+		sort.SliceStable(candidates, func(a, b int) bool {
+			if candidates[a].score != candidates[b].score {
+				return candidates[a].score > candidates[b].score
+			}
+			return candidates[a].post.CreatedAt.After(candidates[b].post.CreatedAt)
+		})
+
+		for position, candidate := range candidates {
+			if position == r.config.MaxPosts {
+				break
+			}
+			related = append(related, repository.RelatedPost{PostID: posts[i].ID, RelatedID: candidate.post.ID, Position: position, Score: candidate.score})
+		}
+	}
+
+	if err := relatedRepository.SetRelatedPosts(related); err != nil {
+		log.Errorf("failed to store the related posts: %v", err)
+		return err
+	}
+
+	log.Infof("related %d posts", len(posts))
+	return nil
+}
+
+// RunRelatedPosts refreshes the related posts periodically after the posts changed until the stop channel is closed.
+// The related posts are computed once after the start as well. A failed refresh is retried in the next interval.
+func (r relatedService) RunRelatedPosts(stop <-chan struct{}) {
+	ticker := time.NewTicker(r.config.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if r.stale.Swap(false) {
+				if err := r.RefreshRelatedPosts(); err != nil {
+					r.stale.Store(true)
+				}
+			}
+		}
+	}
+}
+
+// markStale schedules the recomputation of the related posts after a post changed.
+func (r relatedService) markStale(types.DomainEvent) error {
+	r.stale.Store(true)
+	return nil
+}
+
+// relatedVectors computes the normalized TF-IDF vectors of the posts. Words used by every post get no weight.
+func relatedVectors(posts []repository.Post) []map[string]float64 {
+	vectors := make([]map[string]float64, len(posts))
+	frequencies := map[string]int{}
+
+	for i := range posts {
+		vectors[i] = map[string]float64{}
+		addRelatedTerms(vectors[i], posts[i].Title, relatedTitleWeight)
+		addRelatedTerms(vectors[i], posts[i].Summary, relatedSummaryWeight)
+		addRelatedTerms(vectors[i], markdown.PlainText(posts[i].Body), relatedBodyWeight)
+
+		for term := range vectors[i] {
+			frequencies[term]++
+		}
+	}
+
+	for _, vector := range vectors {
+		norm := 0.0
+		for term, count := range vector {
+			weight := count * math.Log(float64(len(posts))/float64(frequencies[term]))
+			vector[term] = weight
+			norm += weight * weight
+		}
+
+		norm = math.Sqrt(norm)
+		for term := range vector {
+			if norm == 0 {
+				delete(vector, term)
+			} else {
+				vector[term] /= norm
+			}
+		}
+	}
+
+	return vectors
+}
+
+// addRelatedTerms counts the words of a text in a term vector with the given weight.
+func addRelatedTerms(vector map[string]float64, text string, weight float64) {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	for _, word := range words {
+		if utf8.RuneCountInString(word) >= relatedMinTermLength {
+			vector[word] += weight
+		}
+	}
+}
+
+// cosineSimilarity computes the cosine similarity of two normalized vectors.
+func cosineSimilarity(a map[string]float64, b map[string]float64) float64 {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+
+	similarity := 0.0
+	for term, weight := range a {
+		similarity += weight * b[term]
+	}
+
+	return similarity
+}
+
+// jaccardSimilarity computes the share of the common elements of two sets. Empty sets aren't similar to any set.
+func jaccardSimilarity(a map[string]bool, b map[string]bool) float64 {
+	common := 0
+	for element := range a {
+		if b[element] {
+			common++
+		}
+	}
+
+	union := len(a) + len(b) - common
+	if union == 0 {
+		return 0
+	}
+
+	return float64(common) / float64(union)
+}
@@ -0,0 +1,109 @@
+package services_test
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/types"
+	"testing"
+)
+
+// relatedTestContext contains objects relevant for testing the RelatedService.
+type relatedTestContext struct {
+	mockPostRepository    *mocks.MockPostRepository
+	mockRelatedRepository *mocks.MockRelatedRepository
+	sut                   services.RelatedService
+}
+
+// createRelatedServiceContext creates the context for testing the RelatedService and reduces code duplication.
+func createRelatedServiceContext(t *testing.T) *relatedTestContext {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	mockPostRepository := mocks.NewMockPostRepository(mockCtrl)
+	mockRelatedRepository := mocks.NewMockRelatedRepository(mockCtrl)
+	mockEventBus := mocks.NewMockBus(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, mockPostRepository, nil, mockRelatedRepository, nil, nil, nil, nil, nil, nil, mockEventBus, nil)
+
+	mockEventBus.EXPECT().SubscribeAsync(gomock.Any(), gomock.Any()).Times(3)
+	sut := services.CreateRelatedService(cont)
+
+	return &relatedTestContext{mockPostRepository, mockRelatedRepository, sut}
+}
+
+// TestRelatedService_RefreshRelatedPosts tests relating posts by their tags and their texts.
+func TestRelatedService_RefreshRelatedPosts(t *testing.T) {
+	t.Parallel()
+	c := createRelatedServiceContext(t)
+
+	posts := []repository.Post{
+		{ID: 1, Title: "Concurrency patterns in Go", Summary: "Goroutines and channels", Body: "Pipelines fan out over **channels**.", Tags: []string{"go"}},
+		{ID: 2, Title: "Buffered channels", Summary: "When goroutines block", Body: "A buffered channel decouples the pipelines.", Tags: []string{"Go", "performance"}},
+		{ID: 3, Title: "Baking sourdough bread", Summary: "A starter for beginners", Body: "Feed the starter with flour and water.", Tags: []string{"baking"}},
+		{ID: 4, Title: "Profiling memory", Summary: "Finding allocations", Body: "The profiler shows the allocations.", Tags: []string{"performance"}},
+	}
+
+	var related []repository.RelatedPost
+	c.mockPostRepository.EXPECT().GetPosts(types.PostFilter{}).Return(posts, nil)
+	c.mockRelatedRepository.EXPECT().SetRelatedPosts(gomock.Any()).DoAndReturn(func(r []repository.RelatedPost) error {
+		related = r
+		return nil
+	})
+
+	err := c.sut.RefreshRelatedPosts()
+
+	assert.Nil(t, err, "should complete without error")
+	for _, r := range related {
+		assert.NotEqual(t, uint(3), r.PostID, "unrelated post shouldn't have related posts")
+		assert.NotEqual(t, uint(3), r.RelatedID, "unrelated post shouldn't be related to other posts")
+	}
+
+	var first []repository.RelatedPost
+	for _, r := range related {
+		if r.PostID == 1 {
+			first = append(first, r)
+		}
+	}
+	assert.Len(t, first, 1, "only the post sharing tags and words should be related")
+	assert.Equal(t, uint(2), first[0].RelatedID, "post sharing tags and words should be related")
+
+	var second []repository.RelatedPost
+	for _, r := range related {
+		if r.PostID == 2 {
+			second = append(second, r)
+		}
+	}
+	assert.Len(t, second, 2, "posts sharing any tag should be related")
+	assert.Equal(t, uint(1), second[0].RelatedID, "post sharing tags and words should come first")
+	assert.Equal(t, 0, second[0].Position, "most similar post should come first")
+	assert.Greater(t, second[0].Score, second[1].Score, "posts should be ordered by their score")
+}
+
+// TestRelatedService_GetRelatedPosts tests retrieving the public related posts of a post.
+func TestRelatedService_GetRelatedPosts(t *testing.T) {
+	t.Parallel()
+	c := createRelatedServiceContext(t)
+
+	rows := []repository.RelatedPost{
+		{Related: repository.Post{URLHandle: "private", Visibility: types.VisibilityPrivate}, Score: 0.9},
+		{Related: repository.Post{URLHandle: "first", Title: "First", Visibility: types.VisibilityPublic}, Score: 0.81234},
+		{Related: repository.Post{URLHandle: "second", Title: "Second", Visibility: types.VisibilityPublic}, Score: 0.5},
+		{Related: repository.Post{URLHandle: "third", Title: "Third", Visibility: types.VisibilityPublic}, Score: 0.2},
+	}
+
+	c.mockRelatedRepository.EXPECT().GetRelatedPosts("hello").Return(rows, nil)
+
+	related, err := c.sut.GetRelatedPosts("hello", 2)
+
+	expected := []types.RelatedPost{
+		{URLHandle: "first", Title: "First", Score: 0.812},
+		{URLHandle: "second", Title: "Second", Score: 0.5},
+	}
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, expected, related, "related posts don't match the expected output")
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"time"
+)
+
+// maxRemoteRedirects is the number of redirects followed by the requests to other sites.
+const maxRemoteRedirects = 5
+
+// errPrivateAddress is returned for connections to addresses which aren't publicly routable.
+var errPrivateAddress = errors.New("the address isn't publicly routable")
+
+// reservedNetworks are the address ranges which aren't publicly routable, besides the loopback, private, link-local,
+// multicast and unspecified addresses recognized by the net package.
+var reservedNetworks = parseNetworks(
+	"0.0.0.0/8",      // "this" network
+	"100.64.0.0/10",  // carrier-grade NAT
+	"192.0.0.0/24",   // IETF protocol assignments
+	"198.18.0.0/15",  // benchmarking
+	"240.0.0.0/4",    // reserved, including the broadcast address
+	"64:ff9b::/96",   // NAT64, which can reach any IPv4 address
+	"64:ff9b:1::/48", // local-use NAT64
+	"2001:db8::/32",  // documentation
+)
+
+// createRemoteClient creates the client of the requests to other sites, e.g. fetching the sources of webmentions or the
+// documents of remote actors.
+// The URLs are provided by third parties, so connections are only made to publicly routable addresses. The address is
+// checked after resolving the host name, for redirects as well, so host names resolving to internal services are refused
+// too. The proxy of the environment isn't used, since it would connect to the address instead.
+// Set ALLOW_PRIVATE_ADDRESSES to true to allow private addresses, e.g. when testing with other sites of a local network.
+func createRemoteClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if os.Getenv("ALLOW_PRIVATE_ADDRESSES") != "true" {
+		dialer.Control = checkPublicAddress
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			ForceAttemptHTTP2:   true,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: checkRemoteRedirect,
+	}
+}
+
+// checkPublicAddress refuses connections to addresses which aren't publicly routable.
+// It's called with the resolved address of every connection.
+func checkPublicAddress(_ string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(host); ip == nil || !isPublicAddress(ip) {
+		return fmt.Errorf("%w: %s", errPrivateAddress, host)
+	}
+	return nil
+}
+
+// checkRemoteRedirect limits the number of redirects and only follows redirects to HTTP(S) URLs.
+func checkRemoteRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRemoteRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRemoteRedirects)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("redirect to unsupported URL %s", req.URL)
+	}
+	return nil
+}
+
+// isPublicAddress checks whether an IP address is publicly routable.
+func isPublicAddress(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range reservedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseNetworks parses a list of address ranges in CIDR notation.
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/types"
+)
+
+// SeriesService interface. Defines series-related business logic.
+type SeriesService interface {
+	AddSeries(newSeries *types.Series, userName string) (types.Series, error)
+	DeleteSeries(urlHandle string, userName string) error
+	GetSeries(urlHandle string) (types.Series, error)
+	GetSeriesList() ([]types.Series, error)
+	SetSeriesPosts(urlHandle string, postHandles []string, userName string) (types.Series, error)
+	UpdateSeries(series *types.Series, userName string) (types.Series, error)
+}
+
+// seriesService is the concrete implementation of the SeriesService interface.
+type seriesService struct {
+	cont container.Container
+}
+
+// CreateSeriesService instantiates the seriesService using the application container.
+func CreateSeriesService(cont container.Container) SeriesService {
+	return &seriesService{cont}
+}
+
+// AddSeries adds a new, empty series to the blog. The user creating the series becomes its owner.
+func (s seriesService) AddSeries(newSeries *types.Series, userName string) (types.Series, error) {
+	log := s.cont.GetLogger()
+	seriesRepository := s.cont.GetSeriesRepository()
+	userRepository := s.cont.GetUserRepository()
+
+	owner, err := userRepository.GetUser(userName)
+	if err != nil {
+		log.Errorf("failed to get owner %s of series %s: %v", userName, newSeries.URLHandle, err)
+		return types.Series{}, err
+	}
+
+	log.Infof("adding new series %s owned by %s", newSeries.URLHandle, userName)
+
+	series, err := seriesRepository.AddSeries(newSeries, owner.ID)
+	if err != nil {
+		return types.Series{}, err
+	}
+
+	series.Owner = *owner
+	return mapSeries(series), nil
+}
+
+// DeleteSeries removes the series with the given URL handle. The posts of the series are kept.
+// Only the owner of the series and the administrators are allowed to delete it.
+func (s seriesService) DeleteSeries(urlHandle string, userName string) error {
+	log := s.cont.GetLogger()
+	seriesRepository := s.cont.GetSeriesRepository()
+
+	series, err := seriesRepository.GetSeries(urlHandle)
+	if err != nil {
+		return err
+	}
+
+	if !canEditSeries(series, userName) {
+		log.Debugf("user %s is not allowed to delete series %s", userName, urlHandle)
+		return errortypes.SeriesEditForbiddenError{Series: types.Series{URLHandle: urlHandle}, UserName: userName}
+	}
+
+	log.Infof("deleting series %s by user %s", urlHandle, userName)
+	return seriesRepository.DeleteSeries(urlHandle)
+}
+
+// GetSeries retrieves the series with the given URL handle.
+func (s seriesService) GetSeries(urlHandle string) (types.Series, error) {
+	seriesRepository := s.cont.GetSeriesRepository()
+	series, err := seriesRepository.GetSeries(urlHandle)
+	return mapSeries(series), err
+}
+
+// GetSeriesList retrieves every series of the blog.
+func (s seriesService) GetSeriesList() ([]types.Series, error) {
+	seriesRepository := s.cont.GetSeriesRepository()
+	series, err := seriesRepository.GetSeriesList()
+	return mapSeriesList(series), err
+}
+
+// SetSeriesPosts replaces the posts of a series. The order of the URL handles defines the reading order.
+// The posts can only be managed by the owner of the series and the administrators. Posts which aren't part of the
+// series yet can only be added by their primary authors, co-authors and editors, unless the user is an administrator.
+func (s seriesService) SetSeriesPosts(urlHandle string, postHandles []string, userName string) (types.Series, error) {
+	log := s.cont.GetLogger()
+	postRepository := s.cont.GetPostRepository()
+	seriesRepository := s.cont.GetSeriesRepository()
+
+	listed := map[string]bool{}
+	for _, handle := range postHandles {
+		if listed[handle] {
+			return types.Series{}, errortypes.DuplicateSeriesPostError{Post: types.Post{URLHandle: handle}}
+		}
+		listed[handle] = true
+	}
+
+	series, err := seriesRepository.GetSeries(urlHandle)
+	if err != nil {
+		log.Errorf("failed to get series %s: %v", urlHandle, err)
+		return types.Series{}, err
+	}
+
+	if !canEditSeries(series, userName) {
+		log.Debugf("user %s is not allowed to edit series %s", userName, urlHandle)
+		return types.Series{}, errortypes.SeriesEditForbiddenError{Series: types.Series{URLHandle: urlHandle}, UserName: userName}
+	}
+
+	// Only the public members are loaded, so the other posts are checked as if they were added to the series
+	members := map[uint]bool{}
+	for _, member := range series.Posts {
+		members[member.PostID] = true
+	}
+
+	postIDs := make([]uint, 0, len(postHandles))
+	for _, handle := range postHandles {
+		post, err := postRepository.GetPost(handle)
+		if err != nil {
+			log.Errorf("failed to get post %s for series %s: %v", handle, urlHandle, err)
+			return types.Series{}, err
+		}
+		if !members[post.ID] && !isAdmin(userName) && !canEditPost(post, userName) {
+			log.Debugf("user %s is not allowed to add post %s to series %s", userName, handle, urlHandle)
+			return types.Series{}, errortypes.PostEditForbiddenError{Post: types.Post{URLHandle: handle}, UserName: userName}
+		}
+		postIDs = append(postIDs, post.ID)
+	}
+
+	log.Infof("user %s setting posts of series %s to %v", userName, urlHandle, postHandles)
+
+	if err := seriesRepository.SetSeriesPosts(series.ID, postIDs); err != nil {
+		return types.Series{}, err
+	}
+
+	return s.GetSeries(urlHandle)
+}
+
+// UpdateSeries updates the title and summary of an existing series.
+// Only the owner of the series and the administrators are allowed to update it.
+func (s seriesService) UpdateSeries(series *types.Series, userName string) (types.Series, error) {
+	log := s.cont.GetLogger()
+	seriesRepository := s.cont.GetSeriesRepository()
+
+	existingSeries, err := seriesRepository.GetSeries(series.URLHandle)
+	if err != nil {
+		return types.Series{}, err
+	}
+
+	if !canEditSeries(existingSeries, userName) {
+		log.Debugf("user %s is not allowed to update series %s", userName, series.URLHandle)
+		return types.Series{}, errortypes.SeriesEditForbiddenError{Series: types.Series{URLHandle: series.URLHandle}, UserName: userName}
+	}
+
+	log.Infof("updating series %s by user %s", series.URLHandle, userName)
+
+	updatedSeries, err := seriesRepository.UpdateSeries(series)
+	return mapSeries(updatedSeries), err
+}
+
+// mapSeries maps a Series model to a series data object.
+// Posts which aren't public are left out, since the series are listed publicly.
+func mapSeries(s *repository.Series) types.Series {
+	if s == nil {
+		return types.Series{}
+	}
+
+	posts := make([]types.Post, 0, len(s.Posts))
+	for _, member := range s.Posts {
+		if member.Post.Visibility == types.VisibilityPublic {
+			posts = append(posts, mapPostMetadata(&member.Post))
+		}
+	}
+
+	return types.Series{
+		URLHandle: s.URLHandle,
+		Title:     s.Title,
+		Summary:   s.Summary,
+		Owner:     s.Owner.UserName,
+		Posts:     posts,
+	}
+}
+
+// canEditSeries checks whether the user is the owner of the series or an administrator.
+func canEditSeries(s *repository.Series, userName string) bool {
+	return (userName != "" && s.Owner.UserName == userName) || isAdmin(userName)
+}
+
+// mapSeriesList maps a slice of Series models to a slice of series data objects
+func mapSeriesList(s []repository.Series) []types.Series {
+	if s == nil {
+		return []types.Series{}
+	}
+	series := make([]types.Series, 0, len(s))
+
+	for _, item := range s {
+		series = append(series, mapSeries(&item))
+	}
+
+	return series
+}
+
+// mapSeriesNavigation creates the navigation data object of a post within its series.
+// Other posts which aren't public are skipped, so the previous and next links only point to posts every reader can
+// access. If the post isn't part of the series, nil is returned.
+func mapSeriesNavigation(s *repository.Series, postID uint) *types.SeriesNavigation {
+	if s == nil {
+		return nil
+	}
+
+	members := make([]repository.SeriesPost, 0, len(s.Posts))
+	for _, member := range s.Posts {
+		if member.PostID == postID || member.Post.Visibility == types.VisibilityPublic {
+			members = append(members, member)
+		}
+	}
+
+	for i, member := range members {
+		if member.PostID != postID {
+			continue
+		}
+
+		nav := types.SeriesNavigation{
+			URLHandle: s.URLHandle,
+			Title:     s.Title,
+			Position:  i + 1,
+			Total:     len(members),
+		}
+
+		if i > 0 {
+			prev := members[i-1].Post
+			nav.Previous = &types.SeriesLink{URLHandle: prev.URLHandle, Title: prev.Title}
+		}
+
+		if i < len(members)-1 {
+			next := members[i+1].Post
+			nav.Next = &types.SeriesLink{URLHandle: next.URLHandle, Title: next.Title}
+		}
+
+		return &nav
+	}
+
+	return nil
+}
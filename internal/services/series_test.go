@@ -0,0 +1,331 @@
+package services_test
+
+import (
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/types"
+	"testing"
+)
+
+// seriesTestContext contains objects relevant for testing the SeriesService.
+type seriesTestContext struct {
+	mockPostRepository   *mocks.MockPostRepository
+	mockSeriesRepository *mocks.MockSeriesRepository
+	mockUserRepository   *mocks.MockUserRepository
+	sut                  services.SeriesService
+}
+
+// createSeriesServiceContext creates the context for testing the SeriesService and reduces code duplication.
+func createSeriesServiceContext(t *testing.T) *seriesTestContext {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	mockPostRepository := mocks.NewMockPostRepository(mockCtrl)
+	mockSeriesRepository := mocks.NewMockSeriesRepository(mockCtrl)
+	mockUserRepository := mocks.NewMockUserRepository(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, mockPostRepository, nil, nil, mockSeriesRepository, mockUserRepository, nil, nil, nil, nil, nil, nil)
+	sut := services.CreateSeriesService(cont)
+
+	return &seriesTestContext{mockPostRepository, mockSeriesRepository, mockUserRepository, sut}
+}
+
+// createSeriesModel creates a series model owned by testAuthor with two public posts for testing purposes.
+func createSeriesModel() repository.Series {
+	return repository.Series{
+		ID:        1,
+		URLHandle: "testSeries",
+		Title:     "testTitle",
+		Summary:   "testSummary",
+		OwnerID:   1,
+		Owner:     repository.User{ID: 1, UserName: "testAuthor"},
+		Posts: []repository.SeriesPost{
+			{SeriesID: 1, PostID: 1, Position: 1, Post: repository.Post{ID: 1, URLHandle: "part1", Visibility: types.VisibilityPublic, Author: repository.User{UserName: "testAuthor"}}},
+			{SeriesID: 1, PostID: 2, Position: 2, Post: repository.Post{ID: 2, URLHandle: "part2", Visibility: types.VisibilityPublic, Author: repository.User{UserName: "testAuthor"}}},
+		},
+	}
+}
+
+// TestSeriesService_AddSeries tests adding a new series to the blog.
+func TestSeriesService_AddSeries(t *testing.T) {
+	t.Parallel()
+	c := createSeriesServiceContext(t)
+
+	owner := repository.User{ID: 1, UserName: "testAuthor"}
+	input := types.Series{URLHandle: "testSeries", Title: "testTitle", Summary: "testSummary"}
+	model := repository.Series{ID: 1, URLHandle: input.URLHandle, Title: input.Title, Summary: input.Summary, OwnerID: owner.ID}
+	expected := types.Series{URLHandle: input.URLHandle, Title: input.Title, Summary: input.Summary, Owner: owner.UserName, Posts: []types.Post{}}
+
+	c.mockUserRepository.EXPECT().GetUser(owner.UserName).Return(&owner, nil)
+	c.mockSeriesRepository.EXPECT().AddSeries(&input, owner.ID).Return(&model, nil)
+
+	s, err := c.sut.AddSeries(&input, owner.UserName)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, expected, s, "added series doesn't match the input")
+}
+
+// TestSeriesService_AddSeries_Duplicate tests adding a duplicate series to the blog.
+func TestSeriesService_AddSeries_Duplicate(t *testing.T) {
+	t.Parallel()
+	c := createSeriesServiceContext(t)
+
+	owner := repository.User{ID: 1, UserName: "testAuthor"}
+	input := types.Series{URLHandle: "testSeries"}
+	expectedError := errortypes.DuplicateElementError{Key: input.URLHandle}
+
+	c.mockUserRepository.EXPECT().GetUser(owner.UserName).Return(&owner, nil)
+	c.mockSeriesRepository.EXPECT().AddSeries(&input, owner.ID).Return(nil, expectedError)
+
+	s, err := c.sut.AddSeries(&input, owner.UserName)
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+	assert.Equal(t, types.Series{}, s, "shouldn't return a series")
+}
+
+// TestSeriesService_DeleteSeries tests removing a series from the blog.
+func TestSeriesService_DeleteSeries(t *testing.T) {
+	t.Parallel()
+	c := createSeriesServiceContext(t)
+
+	model := createSeriesModel()
+
+	c.mockSeriesRepository.EXPECT().GetSeries(model.URLHandle).Return(&model, nil)
+	c.mockSeriesRepository.EXPECT().DeleteSeries(model.URLHandle).Return(nil)
+
+	err := c.sut.DeleteSeries(model.URLHandle, "testAuthor")
+
+	assert.Nil(t, err, "should complete without error")
+}
+
+// TestSeriesService_DeleteSeries_Forbidden tests removing a series by a user other than its owner.
+func TestSeriesService_DeleteSeries_Forbidden(t *testing.T) {
+	t.Parallel()
+	c := createSeriesServiceContext(t)
+
+	model := createSeriesModel()
+	expectedError := errortypes.SeriesEditForbiddenError{Series: types.Series{URLHandle: model.URLHandle}, UserName: "stranger"}
+
+	c.mockSeriesRepository.EXPECT().GetSeries(model.URLHandle).Return(&model, nil)
+
+	err := c.sut.DeleteSeries(model.URLHandle, "stranger")
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
+
+// TestSeriesService_DeleteSeries_Admin tests removing the series of another user by the administrator.
+func TestSeriesService_DeleteSeries_Admin(t *testing.T) {
+	t.Setenv("DEFAULT_USER", "admin")
+	c := createSeriesServiceContext(t)
+
+	model := createSeriesModel()
+
+	c.mockSeriesRepository.EXPECT().GetSeries(model.URLHandle).Return(&model, nil)
+	c.mockSeriesRepository.EXPECT().DeleteSeries(model.URLHandle).Return(nil)
+
+	err := c.sut.DeleteSeries(model.URLHandle, "admin")
+
+	assert.Nil(t, err, "should complete without error")
+}
+
+// TestSeriesService_GetSeries tests getting a series with its posts.
+func TestSeriesService_GetSeries(t *testing.T) {
+	t.Parallel()
+	c := createSeriesServiceContext(t)
+
+	model := createSeriesModel()
+
+	c.mockSeriesRepository.EXPECT().GetSeries(model.URLHandle).Return(&model, nil)
+
+	s, err := c.sut.GetSeries(model.URLHandle)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, model.Title, s.Title, "series title doesn't match the expected one")
+	assert.Equal(t, 2, len(s.Posts), "series should contain two posts")
+	assert.Equal(t, "part1", s.Posts[0].URLHandle, "posts should be ordered by position")
+	assert.Equal(t, "testAuthor", s.Posts[0].Author, "post author should be mapped")
+}
+
+// TestSeriesService_GetSeriesList tests getting every series of the blog.
+func TestSeriesService_GetSeriesList(t *testing.T) {
+	t.Parallel()
+	c := createSeriesServiceContext(t)
+
+	models := []repository.Series{createSeriesModel()}
+
+	c.mockSeriesRepository.EXPECT().GetSeriesList().Return(models, nil)
+
+	s, err := c.sut.GetSeriesList()
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 1, len(s), "should return exactly one series")
+}
+
+// TestSeriesService_GetSeriesList_Unexpected_Error tests handling an unexpected error while getting every series.
+func TestSeriesService_GetSeriesList_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createSeriesServiceContext(t)
+
+	c.mockSeriesRepository.EXPECT().GetSeriesList().Return(nil, fmt.Errorf("error"))
+
+	s, err := c.sut.GetSeriesList()
+
+	assert.NotNil(t, err, "expected error")
+	assert.Equal(t, []types.Series{}, s, "shouldn't return any series")
+}
+
+// TestSeriesService_SetSeriesPosts tests replacing the posts of a series.
+func TestSeriesService_SetSeriesPosts(t *testing.T) {
+	t.Parallel()
+	c := createSeriesServiceContext(t)
+
+	model := createSeriesModel()
+	part1 := model.Posts[0].Post
+	part2 := model.Posts[1].Post
+
+	gomock.InOrder(
+		c.mockSeriesRepository.EXPECT().GetSeries(model.URLHandle).Return(&model, nil),
+		c.mockPostRepository.EXPECT().GetPost("part2").Return(&part2, nil),
+		c.mockPostRepository.EXPECT().GetPost("part1").Return(&part1, nil),
+		c.mockSeriesRepository.EXPECT().SetSeriesPosts(model.ID, []uint{2, 1}).Return(nil),
+		c.mockSeriesRepository.EXPECT().GetSeries(model.URLHandle).Return(&model, nil),
+	)
+
+	s, err := c.sut.SetSeriesPosts(model.URLHandle, []string{"part2", "part1"}, "testAuthor")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, model.URLHandle, s.URLHandle, "series doesn't match the expected one")
+}
+
+// TestSeriesService_SetSeriesPosts_Duplicate tests listing a post more than once.
+func TestSeriesService_SetSeriesPosts_Duplicate(t *testing.T) {
+	t.Parallel()
+	c := createSeriesServiceContext(t)
+
+	expectedError := errortypes.DuplicateSeriesPostError{Post: types.Post{URLHandle: "part1"}}
+
+	_, err := c.sut.SetSeriesPosts("testSeries", []string{"part1", "part2", "part1"}, "testAuthor")
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
+
+// TestSeriesService_SetSeriesPosts_Forbidden tests replacing the posts of a series by a user other than its owner.
+func TestSeriesService_SetSeriesPosts_Forbidden(t *testing.T) {
+	t.Parallel()
+	c := createSeriesServiceContext(t)
+
+	model := createSeriesModel()
+	expectedError := errortypes.SeriesEditForbiddenError{Series: types.Series{URLHandle: model.URLHandle}, UserName: "stranger"}
+
+	c.mockSeriesRepository.EXPECT().GetSeries(model.URLHandle).Return(&model, nil)
+
+	_, err := c.sut.SetSeriesPosts(model.URLHandle, []string{}, "stranger")
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
+
+// TestSeriesService_SetSeriesPosts_Post_Forbidden tests adding the private post of another user to a series.
+func TestSeriesService_SetSeriesPosts_Post_Forbidden(t *testing.T) {
+	t.Parallel()
+	c := createSeriesServiceContext(t)
+
+	model := createSeriesModel()
+	part1 := model.Posts[0].Post
+	private := repository.Post{ID: 3, URLHandle: "private", Visibility: types.VisibilityPrivate, Author: repository.User{UserName: "otherAuthor"}}
+	expectedError := errortypes.PostEditForbiddenError{Post: types.Post{URLHandle: "private"}, UserName: "testAuthor"}
+
+	c.mockSeriesRepository.EXPECT().GetSeries(model.URLHandle).Return(&model, nil)
+	c.mockPostRepository.EXPECT().GetPost("part1").Return(&part1, nil)
+	c.mockPostRepository.EXPECT().GetPost("private").Return(&private, nil)
+
+	_, err := c.sut.SetSeriesPosts(model.URLHandle, []string{"part1", "private"}, "testAuthor")
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
+
+// TestSeriesService_SetSeriesPosts_Series_Not_Found tests replacing the posts of a non-existent series.
+func TestSeriesService_SetSeriesPosts_Series_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createSeriesServiceContext(t)
+
+	expectedError := errortypes.SeriesNotFoundError{Series: types.Series{URLHandle: "testSeries"}}
+	c.mockSeriesRepository.EXPECT().GetSeries("testSeries").Return(nil, expectedError)
+
+	_, err := c.sut.SetSeriesPosts("testSeries", []string{"part1"}, "testAuthor")
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
+
+// TestSeriesService_SetSeriesPosts_Post_Not_Found tests adding a non-existent post to a series.
+func TestSeriesService_SetSeriesPosts_Post_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createSeriesServiceContext(t)
+
+	model := createSeriesModel()
+	expectedError := errortypes.PostNotFoundError{Post: types.Post{URLHandle: "missing"}}
+
+	c.mockSeriesRepository.EXPECT().GetSeries(model.URLHandle).Return(&model, nil)
+	c.mockPostRepository.EXPECT().GetPost("missing").Return(nil, expectedError)
+
+	_, err := c.sut.SetSeriesPosts(model.URLHandle, []string{"missing"}, "testAuthor")
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
+
+// TestSeriesService_SetSeriesPosts_Already_In_Series tests adding a post to a series which is part of another series.
+func TestSeriesService_SetSeriesPosts_Already_In_Series(t *testing.T) {
+	t.Parallel()
+	c := createSeriesServiceContext(t)
+
+	model := createSeriesModel()
+	part1 := model.Posts[0].Post
+	expectedError := errortypes.PostAlreadyInSeriesError{}
+
+	c.mockSeriesRepository.EXPECT().GetSeries(model.URLHandle).Return(&model, nil)
+	c.mockPostRepository.EXPECT().GetPost("part1").Return(&part1, nil)
+	c.mockSeriesRepository.EXPECT().SetSeriesPosts(model.ID, []uint{1}).Return(expectedError)
+
+	_, err := c.sut.SetSeriesPosts(model.URLHandle, []string{"part1"}, "testAuthor")
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
+
+// TestSeriesService_UpdateSeries tests updating the fields of a series.
+func TestSeriesService_UpdateSeries(t *testing.T) {
+	t.Parallel()
+	c := createSeriesServiceContext(t)
+
+	existing := createSeriesModel()
+	input := types.Series{URLHandle: existing.URLHandle, Title: "newTitle"}
+	model := repository.Series{ID: 1, URLHandle: input.URLHandle, Title: input.Title}
+
+	c.mockSeriesRepository.EXPECT().GetSeries(input.URLHandle).Return(&existing, nil)
+	c.mockSeriesRepository.EXPECT().UpdateSeries(&input).Return(&model, nil)
+
+	s, err := c.sut.UpdateSeries(&input, "testAuthor")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, input.Title, s.Title, "series title should be updated")
+}
+
+// TestSeriesService_UpdateSeries_Forbidden tests updating a series by a user other than its owner.
+func TestSeriesService_UpdateSeries_Forbidden(t *testing.T) {
+	t.Parallel()
+	c := createSeriesServiceContext(t)
+
+	existing := createSeriesModel()
+	input := types.Series{URLHandle: existing.URLHandle, Title: "newTitle"}
+	expectedError := errortypes.SeriesEditForbiddenError{Series: types.Series{URLHandle: input.URLHandle}, UserName: "stranger"}
+
+	c.mockSeriesRepository.EXPECT().GetSeries(input.URLHandle).Return(&existing, nil)
+
+	_, err := c.sut.UpdateSeries(&input, "stranger")
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
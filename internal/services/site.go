@@ -0,0 +1,562 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/markdown"
+	"github.com/wlchs/blog/internal/site"
+	"github.com/wlchs/blog/internal/storage"
+	"github.com/wlchs/blog/internal/types"
+	"html/template"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// feedSize is the number of the latest posts included in the feeds.
+const feedSize = 20
+
+// buildManifest is the key of the file recording the state of the last static build in the target storage.
+const buildManifest = ".build-manifest.json"
+
+// SiteService interface. Defines the rendered pages of the blog and the static site build.
+type SiteService interface {
+	Build(target storage.Storage, full bool) (types.BuildReport, error)
+	GetAuthorPage(userName string) (site.AuthorPage, error)
+	GetFeed() (site.Feed, error)
+	GetIndexPage(number int) (site.IndexPage, error)
+	GetJSONFeed(number int) (site.JSONFeedPage, error)
+	GetPostPage(urlHandle string, access types.PostAccess, languages []string) (site.PostPage, error)
+	GetRobots() (site.Robots, error)
+	GetSitemap() (site.Sitemap, error)
+}
+
+// siteService is the concrete implementation of the SiteService interface.
+type siteService struct {
+	cont        container.Container
+	postService PostService
+	config      site.Config
+}
+
+// manifest records the rendered posts and the written files of a static build.
+type manifest struct {
+	Version string               `json:"version"`
+	Posts   map[string]time.Time `json:"posts"`
+	Files   []string             `json:"files"`
+}
+
+// siteBuilder writes the files of a static build and keeps track of the changes.
+type siteBuilder struct {
+	target storage.Storage
+	files  map[string]bool
+	report types.BuildReport
+}
+
+// CreateSiteService instantiates the siteService using the application container and the post service.
+// The settings of the site are read from the environment.
+func CreateSiteService(cont container.Container, postService PostService) SiteService {
+	return &siteService{cont, postService, site.LoadConfig()}
+}
+
+// Build renders the public posts, the pages of the index, the author pages, the feeds and the sitemap into the target storage.
+// Post pages are only rendered again if the post was updated since the last build, unless a full build is requested.
+// Changes of the series, the contributors and the translations of a post bump its update time as well.
+// Files of the previous build which are no longer part of the site are removed.
+func (s siteService) Build(target storage.Storage, full bool) (types.BuildReport, error) {
+	log := s.cont.GetLogger()
+
+	if s.config.BaseURL == "" {
+		return types.BuildReport{}, errortypes.MissingSiteURLError{}
+	}
+
+	posts, err := s.postService.GetPosts(types.PostFilter{})
+	if err != nil {
+		return types.BuildReport{}, err
+	}
+
+	previous := readManifest(target)
+	current := manifest{Version: site.Version(s.config), Posts: map[string]time.Time{}}
+	if previous.Version != current.Version {
+		full = true
+	}
+
+	b := &siteBuilder{target: target, files: map[string]bool{}, report: types.BuildReport{Written: []string{}, Removed: []string{}}}
+	listed := make([]types.Post, 0, len(posts))
+	fetched := map[string]types.Post{}
+
+	for _, post := range posts {
+		if !isValidFileName(post.URLHandle) {
+			log.Warnf("skipping post with URL handle %q, it can't be used as a file name", post.URLHandle)
+			continue
+		}
+		listed = append(listed, post)
+		current.Posts[post.URLHandle] = post.UpdateTime
+
+		file := path.Join("posts", post.URLHandle, "index.html")
+		if updated, found := previous.Posts[post.URLHandle]; !full && found && updated.Equal(post.UpdateTime) && b.exists(file) {
+			b.keep(file)
+			continue
+		}
+
+		page, err := s.GetPostPage(post.URLHandle, types.PostAccess{}, nil)
+		if err != nil {
+			return b.report, err
+		}
+		fetched[post.URLHandle] = page.Post
+		if err := b.write(file, func(w *bytes.Buffer) error { return site.RenderPost(w, page) }); err != nil {
+			return b.report, err
+		}
+	}
+
+	// The index starts with the pinned posts like the served one, the feeds and author pages stay chronological
+	pages := s.indexPages(pinnedFirst(listed))
+	for _, page := range pages {
+		page := page
+		file := path.Join(page.Path, "index.html")
+		if err := b.write(file, func(w *bytes.Buffer) error { return site.RenderIndex(w, page) }); err != nil {
+			return b.report, err
+		}
+	}
+
+	for _, userName := range authors(listed) {
+		if !isValidFileName(userName) {
+			log.Warnf("skipping author page of %q, the user name can't be used as a file name", userName)
+			continue
+		}
+		page := s.authorPage(listed, userName)
+		file := path.Join("authors", userName, "index.html")
+		if err := b.write(file, func(w *bytes.Buffer) error { return site.RenderAuthor(w, page) }); err != nil {
+			return b.report, err
+		}
+	}
+
+	feed := s.feed(listed)
+	if err := b.write(site.RSSPath, func(w *bytes.Buffer) error { return site.WriteRSS(w, feed) }); err != nil {
+		return b.report, err
+	}
+	if err := b.write(site.AtomPath, func(w *bytes.Buffer) error { return site.WriteAtom(w, feed) }); err != nil {
+		return b.report, err
+	}
+	for _, page := range s.jsonFeedPages(listed) {
+		page, err := s.jsonFeedPage(page, fetched)
+		if err != nil {
+			return b.report, err
+		}
+		file := site.JSONFeedPagePath(page.Number)
+		if err := b.write(file, func(w *bytes.Buffer) error { return site.WriteJSONFeed(w, page) }); err != nil {
+			return b.report, err
+		}
+	}
+	if err := s.writeSitemap(b, site.Sitemap{Site: s.config, URLs: s.sitemapURLs(listed, pages)}); err != nil {
+		return b.report, err
+	}
+
+	robots, err := s.GetRobots()
+	if err != nil {
+		return b.report, err
+	}
+	if err := b.write(site.RobotsPath, func(w *bytes.Buffer) error { return site.WriteRobots(w, robots) }); err != nil {
+		return b.report, err
+	}
+
+	for _, file := range previous.Files {
+		if !b.files[file] {
+			b.remove(file)
+		}
+	}
+
+	for file := range b.files {
+		current.Files = append(current.Files, file)
+	}
+	sort.Strings(current.Files)
+
+	if err := writeManifest(target, current); err != nil {
+		log.Errorf("failed to write build manifest: %v", err)
+		return b.report, err
+	}
+
+	log.Infof("built site: %d written, %d removed, %d unchanged", len(b.report.Written), len(b.report.Removed), b.report.Unchanged)
+	return b.report, nil
+}
+
+// pinnedFirst orders the posts like listing them with the PinnedFirst filter: the pinned posts come before the other
+// ones, both keeping their chronological order.
+func pinnedFirst(posts []types.Post) []types.Post {
+	ordered := make([]types.Post, len(posts))
+	copy(ordered, posts)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Pinned && !ordered[j].Pinned })
+	return ordered
+}
+
+// GetAuthorPage retrieves the page listing the public posts of a user.
+func (s siteService) GetAuthorPage(userName string) (site.AuthorPage, error) {
+	userRepository := s.cont.GetUserRepository()
+
+	if _, err := userRepository.GetUser(userName); err != nil {
+		return site.AuthorPage{}, err
+	}
+
+	posts, err := s.postService.GetPosts(types.PostFilter{})
+	if err != nil {
+		return site.AuthorPage{}, err
+	}
+
+	return s.authorPage(posts, userName), nil
+}
+
+// GetFeed retrieves the latest public posts for the RSS and Atom feeds.
+func (s siteService) GetFeed() (site.Feed, error) {
+	posts, err := s.postService.GetPosts(types.PostFilter{})
+	if err != nil {
+		return site.Feed{}, err
+	}
+
+	return s.feed(posts), nil
+}
+
+// GetIndexPage retrieves the given page of the list of public posts, starting with the pinned and the latest ones.
+// The first page is always available, even if there are no posts yet.
+func (s siteService) GetIndexPage(number int) (site.IndexPage, error) {
+	posts, err := s.postService.GetPosts(types.PostFilter{PinnedFirst: true})
+	if err != nil {
+		return site.IndexPage{}, err
+	}
+
+	pages := s.indexPages(posts)
+	if number < 1 || number > len(pages) {
+		return site.IndexPage{}, errortypes.PageNotFoundError{Page: number}
+	}
+
+	return pages[number-1], nil
+}
+
+// GetJSONFeed retrieves the given page of the JSON Feed, starting with the latest public posts.
+// Unlike the RSS and Atom feeds, the JSON Feed is paginated and contains the full body of the posts.
+// The first page is always available, even if there are no posts yet.
+func (s siteService) GetJSONFeed(number int) (site.JSONFeedPage, error) {
+	posts, err := s.postService.GetPosts(types.PostFilter{})
+	if err != nil {
+		return site.JSONFeedPage{}, err
+	}
+
+	pages := s.jsonFeedPages(posts)
+	if number < 1 || number > len(pages) {
+		return site.JSONFeedPage{}, errortypes.PageNotFoundError{Page: number}
+	}
+
+	return s.jsonFeedPage(pages[number-1], nil)
+}
+
+// GetPostPage retrieves the page of a post using the same access rules and language negotiation as the post API.
+// The meta description, canonical URL and noindex settings of the post are applied to the page.
+// Posts which aren't public are never indexed, since they aren't listed anywhere either.
+// The Markdown body is rendered to HTML, images referencing uploaded media are extended with their responsive variants.
+func (s siteService) GetPostPage(urlHandle string, access types.PostAccess, languages []string) (site.PostPage, error) {
+	post, err := s.postService.GetPost(urlHandle, access, languages)
+	if err != nil {
+		return site.PostPage{}, err
+	}
+
+	page := site.PostPage{
+		Page: site.Page{
+			Site:        s.config,
+			Title:       post.Title,
+			Description: post.Summary,
+			Path:        site.PostPath(post.URLHandle),
+			Language:    post.Language,
+			NoIndex:     post.Visibility != types.VisibilityPublic,
+		},
+		Post: post,
+		// The Markdown body is rendered to sanitized HTML, so it's safe to embed
+		Body: template.HTML(renderResponsiveImages(s.cont.GetLogger(), s.cont.GetMediaRepository(), markdown.Render(post.Body))),
+	}
+	if page.Language == "" {
+		page.Language = s.config.Language
+	}
+	if seo := post.SEO; seo != nil {
+		if seo.MetaDescription != "" {
+			page.Description = seo.MetaDescription
+		}
+		page.Canonical = seo.CanonicalURL
+		page.NoIndex = page.NoIndex || seo.NoIndex
+	}
+
+	return page, nil
+}
+
+// GetRobots retrieves the robots.txt of the site. The custom robots.txt is read from the configured file, if any.
+func (s siteService) GetRobots() (site.Robots, error) {
+	log := s.cont.GetLogger()
+
+	robots := site.Robots{Site: s.config}
+	if s.config.RobotsFile == "" {
+		return robots, nil
+	}
+
+	content, err := os.ReadFile(s.config.RobotsFile)
+	if err != nil {
+		log.Errorf("failed to read robots.txt %s: %v", s.config.RobotsFile, err)
+		return site.Robots{}, err
+	}
+
+	robots.Content = string(content)
+	return robots, nil
+}
+
+// GetSitemap retrieves the pages of the sitemap: the pages of the index, the public posts and the author pages.
+// Posts marked as noindex are left out.
+func (s siteService) GetSitemap() (site.Sitemap, error) {
+	posts, err := s.postService.GetPosts(types.PostFilter{})
+	if err != nil {
+		return site.Sitemap{}, err
+	}
+
+	return site.Sitemap{Site: s.config, URLs: s.sitemapURLs(posts, s.indexPages(posts))}, nil
+}
+
+// authorPage creates the page of an author from the list of public posts.
+func (s siteService) authorPage(posts []types.Post, userName string) site.AuthorPage {
+	authored := make([]types.Post, 0)
+	for _, post := range posts {
+		if post.Author == userName {
+			authored = append(authored, post)
+		}
+	}
+
+	return site.AuthorPage{
+		Page: site.Page{
+			Site:     s.config,
+			Title:    userName,
+			Path:     site.AuthorPath(userName),
+			Language: s.config.Language,
+		},
+		Author: userName,
+		Posts:  authored,
+	}
+}
+
+// feed creates the feed from the latest posts of the list of public posts.
+func (s siteService) feed(posts []types.Post) site.Feed {
+	if len(posts) > feedSize {
+		posts = posts[:feedSize]
+	}
+	return site.Feed{Site: s.config, Posts: posts}
+}
+
+// jsonFeedPages splits the list of public posts into the pages of the JSON Feed. The posts don't contain their body yet.
+func (s siteService) jsonFeedPages(posts []types.Post) []site.JSONFeedPage {
+	total := (len(posts) + feedSize - 1) / feedSize
+	if total == 0 {
+		total = 1
+	}
+
+	pages := make([]site.JSONFeedPage, 0, total)
+	for i := 0; i < total; i++ {
+		end := min((i+1)*feedSize, len(posts))
+		pages = append(pages, site.JSONFeedPage{
+			Feed:   site.Feed{Site: s.config, Posts: posts[i*feedSize : end]},
+			Number: i + 1,
+			Total:  total,
+		})
+	}
+	return pages
+}
+
+// jsonFeedPage retrieves the full posts of a page of the JSON Feed. Posts which were already fetched aren't retrieved again.
+func (s siteService) jsonFeedPage(page site.JSONFeedPage, fetched map[string]types.Post) (site.JSONFeedPage, error) {
+	posts := make([]types.Post, 0, len(page.Posts))
+	for _, listed := range page.Posts {
+		if post, found := fetched[listed.URLHandle]; found {
+			posts = append(posts, post)
+			continue
+		}
+		post, err := s.postService.GetPost(listed.URLHandle, types.PostAccess{}, nil)
+		if err != nil {
+			return site.JSONFeedPage{}, err
+		}
+		posts = append(posts, post)
+	}
+
+	page.Posts = posts
+	return page, nil
+}
+
+// indexPages splits the list of public posts into the pages of the index.
+func (s siteService) indexPages(posts []types.Post) []site.IndexPage {
+	size := s.config.PageSize
+	total := (len(posts) + size - 1) / size
+	if total == 0 {
+		total = 1
+	}
+
+	pages := make([]site.IndexPage, 0, total)
+	for number := 1; number <= total; number++ {
+		start := (number - 1) * size
+		end := start + size
+		if end > len(posts) {
+			end = len(posts)
+		}
+
+		page := site.IndexPage{
+			Page: site.Page{
+				Site:        s.config,
+				Description: s.config.Description,
+				Path:        site.IndexPath(number),
+				Language:    s.config.Language,
+			},
+			Posts:  posts[start:end],
+			Number: number,
+			Total:  total,
+		}
+		if number > 1 {
+			page.Previous = site.IndexPath(number - 1)
+		}
+		if number < total {
+			page.Next = site.IndexPath(number + 1)
+		}
+		pages = append(pages, page)
+	}
+
+	return pages
+}
+
+// sitemapURLs lists the pages of the static site with the time of their latest change.
+func (s siteService) sitemapURLs(posts []types.Post, pages []site.IndexPage) []site.SitemapURL {
+	base := site.Page{Site: s.config}
+	urls := make([]site.SitemapURL, 0, len(pages)+len(posts))
+
+	for _, page := range pages {
+		urls = append(urls, site.SitemapURL{Loc: base.URL(page.Path), LastMod: site.Feed{Posts: page.Posts}.Updated()})
+	}
+	for _, post := range posts {
+		if post.SEO == nil || !post.SEO.NoIndex {
+			urls = append(urls, site.SitemapURL{Loc: base.URL(site.PostPath(post.URLHandle)), LastMod: site.LastModified(post)})
+		}
+	}
+	for _, userName := range authors(posts) {
+		if isValidFileName(userName) {
+			page := s.authorPage(posts, userName)
+			urls = append(urls, site.SitemapURL{Loc: base.URL(page.Path), LastMod: site.Feed{Posts: page.Posts}.Updated()})
+		}
+	}
+
+	return urls
+}
+
+// writeSitemap writes the sitemap of the static site, which is split into several sitemaps listed by a sitemap index
+// if it contains too many pages.
+func (s siteService) writeSitemap(b *siteBuilder, sitemap site.Sitemap) error {
+	parts := sitemap.Split(site.MaxSitemapURLs)
+	if len(parts) == 1 {
+		return b.write(site.SitemapPath, func(w *bytes.Buffer) error { return site.WriteSitemap(w, parts[0]) })
+	}
+
+	for i, part := range parts {
+		part := part
+		if err := b.write(site.SitemapPagePath(i+1), func(w *bytes.Buffer) error { return site.WriteSitemap(w, part) }); err != nil {
+			return err
+		}
+	}
+	return b.write(site.SitemapPath, func(w *bytes.Buffer) error { return site.WriteSitemapIndex(w, sitemap.Site, parts) })
+}
+
+// authors lists the primary authors of the posts in order of their first appearance.
+func authors(posts []types.Post) []string {
+	seen := map[string]bool{}
+	result := make([]string, 0)
+	for _, post := range posts {
+		if !seen[post.Author] {
+			seen[post.Author] = true
+			result = append(result, post.Author)
+		}
+	}
+	return result
+}
+
+// isValidFileName checks whether a URL handle or user name can be used as a single path element of the output directory.
+func isValidFileName(name string) bool {
+	return name != "" && name != "." && name != ".." && name == filepath.Base(name)
+}
+
+// readManifest reads the manifest of the previous build. A missing or unreadable manifest results in a full build.
+func readManifest(target storage.Storage) manifest {
+	var m manifest
+	content, err := readFile(target, buildManifest)
+	if err != nil || json.Unmarshal(content, &m) != nil {
+		return manifest{}
+	}
+	return m
+}
+
+// writeManifest stores the manifest of the current build.
+func writeManifest(target storage.Storage, m manifest) error {
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return target.Put(buildManifest, bytes.NewReader(content), int64(len(content)), "application/json")
+}
+
+// readFile reads the whole content of a file of the storage.
+func readFile(target storage.Storage, key string) ([]byte, error) {
+	r, err := target.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// write renders a file of the static site, given by its slash-separated path, and stores it if its content changed.
+func (b *siteBuilder) write(file string, render func(w *bytes.Buffer) error) error {
+	var buf bytes.Buffer
+	if err := render(&buf); err != nil {
+		return err
+	}
+
+	file = strings.TrimPrefix(path.Clean(file), "/")
+	b.files[file] = true
+
+	if existing, err := readFile(b.target, file); err == nil && bytes.Equal(existing, buf.Bytes()) {
+		b.report.Unchanged++
+		return nil
+	}
+
+	if err := b.target.Put(file, &buf, int64(buf.Len()), mime.TypeByExtension(path.Ext(file))); err != nil {
+		return err
+	}
+
+	b.report.Written = append(b.report.Written, file)
+	return nil
+}
+
+// exists checks whether a file of the previous build is still present in the target storage.
+func (b *siteBuilder) exists(file string) bool {
+	r, err := b.target.Get(file)
+	if err != nil {
+		return false
+	}
+	_ = r.Close()
+	return true
+}
+
+// keep marks an unchanged file of the previous build as part of the current build.
+func (b *siteBuilder) keep(file string) {
+	b.files[file] = true
+	b.report.Unchanged++
+}
+
+// remove deletes a file of the previous build.
+func (b *siteBuilder) remove(file string) {
+	if err := b.target.Delete(file); err != nil {
+		return
+	}
+	b.report.Removed = append(b.report.Removed, file)
+}
@@ -0,0 +1,443 @@
+package services_test
+
+import (
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/storage"
+	"github.com/wlchs/blog/internal/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// siteTestContext contains objects relevant for testing the SiteService.
+type siteTestContext struct {
+	mockMediaRepository *mocks.MockMediaRepository
+	mockUserRepository  *mocks.MockUserRepository
+	mockPostService     *mocks.MockPostService
+	sut                 services.SiteService
+}
+
+// createSiteServiceContext creates the context for testing the SiteService and reduces code duplication.
+// The settings of the site have to be set in the environment before creating the context.
+func createSiteServiceContext(t *testing.T) *siteTestContext {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	mockMediaRepository := mocks.NewMockMediaRepository(mockCtrl)
+	mockUserRepository := mocks.NewMockUserRepository(mockCtrl)
+	mockPostService := mocks.NewMockPostService(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, mockMediaRepository, nil, nil, nil, nil, nil, mockUserRepository, nil, nil, nil, nil, nil, nil)
+	sut := services.CreateSiteService(cont, mockPostService)
+
+	return &siteTestContext{mockMediaRepository, mockUserRepository, mockPostService, sut}
+}
+
+// createSitePosts creates the given number of public posts, starting with the latest one, for testing purposes.
+func createSitePosts(count int) []types.Post {
+	posts := make([]types.Post, 0, count)
+	for i := count; i > 0; i-- {
+		posts = append(posts, types.Post{
+			URLHandle:    fmt.Sprintf("post-%d", i),
+			Title:        fmt.Sprintf("Post %d", i),
+			Author:       fmt.Sprintf("author-%d", i%2),
+			CreationTime: time.Date(2024, 1, i, 0, 0, 0, 0, time.UTC),
+			UpdateTime:   time.Date(2024, 1, i, 12, 0, 0, 0, time.UTC),
+			Visibility:   types.VisibilityPublic,
+		})
+	}
+	return posts
+}
+
+// TestSiteService_GetIndexPage tests retrieving a page of the index.
+func TestSiteService_GetIndexPage(t *testing.T) {
+	t.Setenv("SITE_PAGE_SIZE", "2")
+	c := createSiteServiceContext(t)
+
+	c.mockPostService.EXPECT().GetPosts(types.PostFilter{PinnedFirst: true}).Return(createSitePosts(5), nil)
+
+	page, err := c.sut.GetIndexPage(2)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, []string{"post-3", "post-2"}, []string{page.Posts[0].URLHandle, page.Posts[1].URLHandle}, "posts of the page don't match")
+	assert.Equal(t, 3, page.Total, "incorrect number of pages")
+	assert.Equal(t, "/", page.Previous, "previous page should be the home page")
+	assert.Equal(t, "/page/3", page.Next, "incorrect next page")
+}
+
+// TestSiteService_GetIndexPage_Not_Found tests retrieving a page after the last page of the index.
+func TestSiteService_GetIndexPage_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createSiteServiceContext(t)
+
+	c.mockPostService.EXPECT().GetPosts(types.PostFilter{PinnedFirst: true}).Return([]types.Post{}, nil)
+
+	_, err := c.sut.GetIndexPage(2)
+
+	assert.Equal(t, errortypes.PageNotFoundError{Page: 2}, err, "error doesn't match expected one")
+}
+
+// TestSiteService_GetAuthorPage tests retrieving the posts of an author.
+func TestSiteService_GetAuthorPage(t *testing.T) {
+	t.Parallel()
+	c := createSiteServiceContext(t)
+
+	c.mockUserRepository.EXPECT().GetUser("author-1").Return(&repository.User{UserName: "author-1"}, nil)
+	c.mockPostService.EXPECT().GetPosts(types.PostFilter{}).Return(createSitePosts(3), nil)
+
+	page, err := c.sut.GetAuthorPage("author-1")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 2, len(page.Posts), "only the posts of the author should be listed")
+	assert.Equal(t, "/authors/author-1", page.Path, "incorrect path")
+}
+
+// TestSiteService_GetAuthorPage_Not_Found tests retrieving the page of a missing user.
+func TestSiteService_GetAuthorPage_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createSiteServiceContext(t)
+
+	expectedError := errortypes.UserNotFoundError{User: types.User{UserName: "john"}}
+	c.mockUserRepository.EXPECT().GetUser("john").Return(nil, expectedError)
+
+	_, err := c.sut.GetAuthorPage("john")
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
+
+// TestSiteService_GetFeed tests limiting the feed to the latest posts.
+func TestSiteService_GetFeed(t *testing.T) {
+	t.Parallel()
+	c := createSiteServiceContext(t)
+
+	c.mockPostService.EXPECT().GetPosts(types.PostFilter{}).Return(createSitePosts(25), nil)
+
+	feed, err := c.sut.GetFeed()
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 20, len(feed.Posts), "feed should contain the latest 20 posts")
+	assert.Equal(t, "post-25", feed.Posts[0].URLHandle, "latest post should come first")
+}
+
+// TestSiteService_GetJSONFeed tests retrieving a page of the JSON Feed with the full posts.
+func TestSiteService_GetJSONFeed(t *testing.T) {
+	t.Parallel()
+	c := createSiteServiceContext(t)
+
+	posts := createSitePosts(25)
+	c.mockPostService.EXPECT().GetPosts(types.PostFilter{}).Return(posts, nil)
+	for _, post := range posts[20:] {
+		post := post
+		post.Body = "<p>" + post.Title + "</p>"
+		c.mockPostService.EXPECT().GetPost(post.URLHandle, types.PostAccess{}, nil).Return(post, nil)
+	}
+
+	page, err := c.sut.GetJSONFeed(2)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 2, page.Number, "incorrect page number")
+	assert.Equal(t, 2, page.Total, "incorrect number of pages")
+	assert.Equal(t, 5, len(page.Posts), "last page should contain the remaining posts")
+	assert.Equal(t, "<p>Post 5</p>", page.Posts[0].Body, "posts should contain their body")
+}
+
+// TestSiteService_GetJSONFeed_Not_Found tests retrieving a page of the JSON Feed which doesn't exist.
+func TestSiteService_GetJSONFeed_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createSiteServiceContext(t)
+
+	c.mockPostService.EXPECT().GetPosts(types.PostFilter{}).Return(createSitePosts(20), nil)
+
+	_, err := c.sut.GetJSONFeed(2)
+
+	assert.Equal(t, errortypes.PageNotFoundError{Page: 2}, err, "error doesn't match expected one")
+}
+
+// TestSiteService_GetPostPage tests retrieving the page of a post.
+func TestSiteService_GetPostPage(t *testing.T) {
+	t.Parallel()
+	c := createSiteServiceContext(t)
+
+	access := types.PostAccess{UserName: "jane"}
+	post := types.Post{URLHandle: "hello", Title: "Hello", Summary: "Summary", Body: "Hello *world*<script>alert(1)</script>", Language: "de-DE"}
+	c.mockPostService.EXPECT().GetPost("hello", access, []string{"de-DE"}).Return(post, nil)
+
+	page, err := c.sut.GetPostPage("hello", access, []string{"de-DE"})
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "Hello", page.Title, "incorrect title")
+	assert.Equal(t, "Summary", page.Description, "incorrect description")
+	assert.Equal(t, "de-DE", page.Language, "language of the post should be used")
+	assert.Equal(t, "<p>Hello <em>world</em></p>\n", string(page.Body), "body should be rendered to sanitized HTML")
+}
+
+// TestSiteService_GetPostPage_Responsive_Images tests adding the srcset of uploaded images to the rendered body.
+func TestSiteService_GetPostPage_Responsive_Images(t *testing.T) {
+	t.Parallel()
+	c := createSiteServiceContext(t)
+
+	post := types.Post{URLHandle: "hello", Body: `![test](/media/1)<img src="/media/2"><img src="/media/1" srcset="custom">`}
+	mediaModel := repository.Media{
+		ID:     1,
+		Width:  1000,
+		Height: 500,
+		Variants: []repository.MediaVariant{
+			{MediaID: 1, Name: "medium", Width: 768, Height: 384},
+			{MediaID: 1, Name: "thumbnail", Width: 320, Height: 160},
+		},
+	}
+	expectedBody := `<p><img src="/media/1" alt="test" srcset="/media/1/variants/thumbnail 320w, /media/1/variants/medium 768w, /media/1 1000w" sizes="(max-width: 1000px) 100vw, 1000px" width="1000" height="500"/>` +
+		`<img src="/media/2"/><img src="/media/1" srcset="custom"/></p>` + "\n"
+
+	c.mockPostService.EXPECT().GetPost("hello", types.PostAccess{}, nil).Return(post, nil)
+	c.mockMediaRepository.EXPECT().GetMediaByIDs([]uint{1, 2}).Return([]repository.Media{mediaModel}, nil)
+
+	page, err := c.sut.GetPostPage("hello", types.PostAccess{}, nil)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, expectedBody, string(page.Body), "images should be extended with their responsive variants")
+}
+
+// TestSiteService_GetPostPage_SEO tests applying the search engine settings of a post to its page.
+func TestSiteService_GetPostPage_SEO(t *testing.T) {
+	t.Parallel()
+	c := createSiteServiceContext(t)
+
+	seo := &types.PostSEO{MetaDescription: "Description", CanonicalURL: "https://example.org/hello"}
+	public := types.Post{URLHandle: "hello", Summary: "Summary", Visibility: types.VisibilityPublic, SEO: seo}
+	unlisted := types.Post{URLHandle: "unlisted", Summary: "Summary", Visibility: types.VisibilityUnlisted}
+	c.mockPostService.EXPECT().GetPost("hello", types.PostAccess{}, nil).Return(public, nil)
+	c.mockPostService.EXPECT().GetPost("unlisted", types.PostAccess{}, nil).Return(unlisted, nil)
+
+	page, err := c.sut.GetPostPage("hello", types.PostAccess{}, nil)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "Description", page.Description, "meta description should be preferred over the summary")
+	assert.Equal(t, "https://example.org/hello", page.CanonicalURL(), "canonical URL should be overridden")
+	assert.False(t, page.NoIndex, "public post should be indexed")
+
+	page, err = c.sut.GetPostPage("unlisted", types.PostAccess{}, nil)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "Summary", page.Description, "summary should be used as description")
+	assert.True(t, page.NoIndex, "unlisted post shouldn't be indexed")
+}
+
+// TestSiteService_GetSitemap tests listing the pages of the sitemap without the posts marked as noindex.
+func TestSiteService_GetSitemap(t *testing.T) {
+	t.Setenv("SITE_URL", "https://example.com")
+	c := createSiteServiceContext(t)
+
+	posts := createSitePosts(2)
+	posts[1].SEO = &types.PostSEO{NoIndex: true}
+	c.mockPostService.EXPECT().GetPosts(types.PostFilter{}).Return(posts, nil)
+
+	sitemap, err := c.sut.GetSitemap()
+
+	locs := make([]string, 0, len(sitemap.URLs))
+	for _, u := range sitemap.URLs {
+		locs = append(locs, u.Loc)
+	}
+	expectedLocs := []string{"https://example.com/", "https://example.com/posts/post-2", "https://example.com/authors/author-0", "https://example.com/authors/author-1"}
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, expectedLocs, locs, "sitemap URLs don't match")
+	assert.Equal(t, posts[0].UpdateTime, sitemap.URLs[1].LastMod, "last modification should be the update time")
+}
+
+// TestSiteService_GetRobots tests reading the custom robots.txt.
+func TestSiteService_GetRobots(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "robots.txt")
+	_ = os.WriteFile(file, []byte("User-agent: *\nDisallow: /\n"), 0644)
+	t.Setenv("SITE_ROBOTS_FILE", file)
+	c := createSiteServiceContext(t)
+
+	robots, err := c.sut.GetRobots()
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "User-agent: *\nDisallow: /\n", robots.Content, "content of the file should be used")
+}
+
+// TestSiteService_GetRobots_Missing_File tests reading a missing custom robots.txt.
+func TestSiteService_GetRobots_Missing_File(t *testing.T) {
+	t.Setenv("SITE_ROBOTS_FILE", filepath.Join(t.TempDir(), "robots.txt"))
+	c := createSiteServiceContext(t)
+
+	_, err := c.sut.GetRobots()
+
+	assert.NotNil(t, err, "should return an error")
+}
+
+// TestSiteService_Build tests building the static site and rebuilding it incrementally.
+func TestSiteService_Build(t *testing.T) {
+	t.Setenv("SITE_URL", "https://example.com/")
+	t.Setenv("SITE_PAGE_SIZE", "2")
+	c := createSiteServiceContext(t)
+	dir := t.TempDir()
+
+	posts := createSitePosts(3)
+	c.mockPostService.EXPECT().GetPosts(types.PostFilter{}).Return(posts, nil)
+	for _, post := range posts {
+		c.mockPostService.EXPECT().GetPost(post.URLHandle, types.PostAccess{}, nil).Return(post, nil)
+	}
+
+	report, err := c.sut.Build(storage.CreateLocalStorage(logger.CreateLogger(), dir), false)
+
+	expectedFiles := []string{
+		"posts/post-3/index.html", "posts/post-2/index.html", "posts/post-1/index.html",
+		"index.html", "page/2/index.html", "authors/author-1/index.html", "authors/author-0/index.html",
+		"feed.xml", "atom.xml", "feed.json", "sitemap.xml", "robots.txt",
+	}
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, expectedFiles, report.Written, "written files don't match")
+	for _, file := range expectedFiles {
+		assert.FileExists(t, filepath.Join(dir, file), "file should be written")
+	}
+	sitemap, _ := os.ReadFile(filepath.Join(dir, "sitemap.xml"))
+	assert.Contains(t, string(sitemap), "<loc>https://example.com/posts/post-1</loc>", "sitemap should use the site URL")
+
+	// Only the updated post is rendered again, the deleted one is removed
+	posts[0].Title = "Post 3 updated"
+	posts[0].UpdateTime = posts[0].UpdateTime.Add(time.Hour)
+	posts = posts[:2]
+	c.mockPostService.EXPECT().GetPosts(types.PostFilter{}).Return(posts, nil)
+	c.mockPostService.EXPECT().GetPost("post-3", types.PostAccess{}, nil).Return(posts[0], nil)
+	// The JSON Feed contains the body of the unchanged post as well
+	c.mockPostService.EXPECT().GetPost("post-2", types.PostAccess{}, nil).Return(posts[1], nil)
+
+	report, err = c.sut.Build(storage.CreateLocalStorage(logger.CreateLogger(), dir), false)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Contains(t, report.Written, "posts/post-3/index.html", "updated post should be written")
+	assert.NotContains(t, report.Written, "posts/post-2/index.html", "unchanged post shouldn't be written")
+	assert.Equal(t, []string{"page/2/index.html", "posts/post-1/index.html"}, report.Removed, "removed files don't match")
+	assert.NoDirExists(t, filepath.Join(dir, "posts", "post-1"), "directory of the removed post should be deleted")
+	assert.Greater(t, report.Unchanged, 0, "unchanged files should be counted")
+}
+
+// TestSiteService_Build_Pinned tests listing the pinned posts first on the index of the static site.
+func TestSiteService_Build_Pinned(t *testing.T) {
+	t.Setenv("SITE_URL", "https://example.com")
+	t.Setenv("SITE_PAGE_SIZE", "2")
+	c := createSiteServiceContext(t)
+	dir := t.TempDir()
+
+	posts := createSitePosts(3)
+	posts[2].Pinned = true
+	c.mockPostService.EXPECT().GetPosts(types.PostFilter{}).Return(posts, nil)
+	for _, post := range posts {
+		c.mockPostService.EXPECT().GetPost(post.URLHandle, types.PostAccess{}, nil).Return(post, nil)
+	}
+
+	_, err := c.sut.Build(storage.CreateLocalStorage(logger.CreateLogger(), dir), false)
+
+	assert.Nil(t, err, "should complete without error")
+	index, _ := os.ReadFile(filepath.Join(dir, "index.html"))
+	assert.Contains(t, string(index), "Post 1", "pinned post should be on the first page")
+	assert.Less(t, strings.Index(string(index), "Post 1"), strings.Index(string(index), "Post 3"), "pinned post should be listed first")
+	secondPage, _ := os.ReadFile(filepath.Join(dir, "page", "2", "index.html"))
+	assert.Contains(t, string(secondPage), "Post 2", "the oldest unpinned post should be on the second page")
+}
+
+// TestSiteService_Build_Series tests rendering a post again when a new part is added to its series, which bumps the
+// update time of the post.
+func TestSiteService_Build_Series(t *testing.T) {
+	t.Setenv("SITE_URL", "https://example.com")
+	c := createSiteServiceContext(t)
+	dir := t.TempDir()
+
+	posts := createSitePosts(1)
+	posts[0].Series = &types.SeriesNavigation{URLHandle: "go", Title: "Go", Position: 1, Total: 1}
+	c.mockPostService.EXPECT().GetPosts(types.PostFilter{}).Return(posts, nil)
+	c.mockPostService.EXPECT().GetPost("post-1", types.PostAccess{}, nil).Return(posts[0], nil)
+
+	_, err := c.sut.Build(storage.CreateLocalStorage(logger.CreateLogger(), dir), false)
+	assert.Nil(t, err, "should complete without error")
+
+	// A new part was added to the series
+	updated := createSitePosts(1)
+	updated[0].UpdateTime = updated[0].UpdateTime.Add(time.Hour)
+	updated[0].Series = &types.SeriesNavigation{URLHandle: "go", Title: "Go", Position: 1, Total: 2, Next: &types.SeriesLink{URLHandle: "post-2", Title: "Post 2"}}
+	c.mockPostService.EXPECT().GetPosts(types.PostFilter{}).Return(updated, nil)
+	c.mockPostService.EXPECT().GetPost("post-1", types.PostAccess{}, nil).Return(updated[0], nil)
+
+	report, err := c.sut.Build(storage.CreateLocalStorage(logger.CreateLogger(), dir), false)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Contains(t, report.Written, "posts/post-1/index.html", "the post page should be written")
+	page, _ := os.ReadFile(filepath.Join(dir, "posts", "post-1", "index.html"))
+	assert.Contains(t, string(page), `rel="next"`, "the next part of the series should be linked")
+}
+
+// TestSiteService_Build_Series_Neighbour tests rendering a post again when another part of its series is renamed, which
+// bumps the update time of the other parts.
+func TestSiteService_Build_Series_Neighbour(t *testing.T) {
+	t.Setenv("SITE_URL", "https://example.com")
+	c := createSiteServiceContext(t)
+	dir := t.TempDir()
+
+	posts := createSitePosts(2)
+	posts[1].Series = &types.SeriesNavigation{URLHandle: "go", Title: "Go", Position: 1, Total: 2, Next: &types.SeriesLink{URLHandle: "post-2", Title: "Post 2"}}
+	c.mockPostService.EXPECT().GetPosts(types.PostFilter{}).Return(posts, nil)
+	c.mockPostService.EXPECT().GetPost("post-2", types.PostAccess{}, nil).Return(posts[0], nil)
+	c.mockPostService.EXPECT().GetPost("post-1", types.PostAccess{}, nil).Return(posts[1], nil)
+
+	_, err := c.sut.Build(storage.CreateLocalStorage(logger.CreateLogger(), dir), false)
+	assert.Nil(t, err, "should complete without error")
+
+	// The second part was renamed
+	updated := createSitePosts(2)
+	updated[0].Title = "Renamed"
+	for i := range updated {
+		updated[i].UpdateTime = updated[i].UpdateTime.Add(time.Hour)
+	}
+	updated[1].Series = &types.SeriesNavigation{URLHandle: "go", Title: "Go", Position: 1, Total: 2, Next: &types.SeriesLink{URLHandle: "post-2", Title: "Renamed"}}
+	c.mockPostService.EXPECT().GetPosts(types.PostFilter{}).Return(updated, nil)
+	c.mockPostService.EXPECT().GetPost("post-2", types.PostAccess{}, nil).Return(updated[0], nil)
+	c.mockPostService.EXPECT().GetPost("post-1", types.PostAccess{}, nil).Return(updated[1], nil)
+
+	report, err := c.sut.Build(storage.CreateLocalStorage(logger.CreateLogger(), dir), false)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Contains(t, report.Written, "posts/post-1/index.html", "the page of the first part should be written")
+	page, _ := os.ReadFile(filepath.Join(dir, "posts", "post-1", "index.html"))
+	assert.Contains(t, string(page), `rel="next">Renamed</a>`, "the new title of the second part should be linked")
+}
+
+// TestSiteService_Build_Full tests rendering every post again on a full build.
+func TestSiteService_Build_Full(t *testing.T) {
+	t.Setenv("SITE_URL", "https://example.com")
+	c := createSiteServiceContext(t)
+	dir := t.TempDir()
+
+	posts := createSitePosts(1)
+	c.mockPostService.EXPECT().GetPosts(types.PostFilter{}).Return(posts, nil).Times(2)
+	c.mockPostService.EXPECT().GetPost("post-1", types.PostAccess{}, nil).Return(posts[0], nil).Times(2)
+
+	_, err := c.sut.Build(storage.CreateLocalStorage(logger.CreateLogger(), dir), false)
+	assert.Nil(t, err, "should complete without error")
+
+	report, err := c.sut.Build(storage.CreateLocalStorage(logger.CreateLogger(), dir), true)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Empty(t, report.Written, "unchanged files shouldn't be written")
+	assert.Equal(t, 8, report.Unchanged, "every file should be unchanged")
+}
+
+// TestSiteService_Build_Missing_URL tests building the static site without a site URL.
+func TestSiteService_Build_Missing_URL(t *testing.T) {
+	t.Setenv("SITE_URL", "")
+	c := createSiteServiceContext(t)
+
+	_, err := c.sut.Build(storage.CreateLocalStorage(logger.CreateLogger(), t.TempDir()), false)
+
+	assert.Equal(t, errortypes.MissingSiteURLError{}, err, "error doesn't match expected one")
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// throttle counts the requests of every client in fixed time windows, e.g. the reactions of a reader.
+// The counters are kept in memory, so every instance of the blog engine throttles the clients separately.
+type throttle struct {
+	mutex   sync.Mutex
+	windows map[string]*throttleWindow
+	cleaned time.Time
+}
+
+// throttleWindow is the current time window of a client.
+type throttleWindow struct {
+	start time.Time
+	count int
+}
+
+// createThrottle instantiates a throttle without any counted requests.
+func createThrottle() *throttle {
+	return &throttle{windows: map[string]*throttleWindow{}}
+}
+
+// allow counts a request of a client and checks whether the limit of the current window is exceeded.
+// If it is, the time until the next window is returned. The expired windows are dropped once per window.
+func (t *throttle) allow(key string, limit int, window time.Duration, now time.Time) (time.Duration, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if now.Sub(t.cleaned) >= window {
+		for k, w := range t.windows {
+			if now.Sub(w.start) >= window {
+				delete(t.windows, k)
+			}
+		}
+		t.cleaned = now
+	}
+
+	w, ok := t.windows[key]
+	if !ok || now.Sub(w.start) >= window {
+		w = &throttleWindow{start: now}
+		t.windows[key] = w
+	}
+
+	if w.count >= limit {
+		return w.start.Add(window).Sub(now), false
+	}
+
+	w.count++
+	return 0, true
+}
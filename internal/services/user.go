@@ -15,6 +15,7 @@ type UserService interface {
 	CheckUserPassword(user *types.UserLoginInput) bool
 	GetUser(userName string) (types.User, error)
 	GetUsers() ([]types.User, error)
+	IsAdmin(userName string) bool
 	RegisterFirstUser() error
 	RegisterUser(user *types.UserLoginInput) (types.User, error)
 	UpdateUser(oldUser *types.UserLoginInput, newUser *types.UserLoginInput) (types.User, error)
@@ -62,6 +63,7 @@ func (u userService) AuthenticateUser(user *types.UserLoginInput) (string, error
 }
 
 // CheckUserPassword fetches the user's password hash from the database and compares it to the input.
+// Disabled users, e.g. the authors of imported posts, can't sign in.
 func (u userService) CheckUserPassword(user *types.UserLoginInput) bool {
 	log := u.cont.GetLogger()
 	userRepository := u.cont.GetUserRepository()
@@ -72,6 +74,11 @@ func (u userService) CheckUserPassword(user *types.UserLoginInput) bool {
 		return false
 	}
 
+	if userModel.Disabled {
+		log.Debugf("user %s is disabled", user.UserName)
+		return false
+	}
+
 	return auth.CompareStringWithHash(user.Password, userModel.PasswordHash)
 }
 
@@ -89,6 +96,18 @@ func (u userService) GetUsers() ([]types.User, error) {
 	return mapUsers(users), err
 }
 
+// IsAdmin checks whether the user has admin rights.
+// The main user, defined by the DEFAULT_USER environment variable, is the administrator of the blog.
+func (u userService) IsAdmin(userName string) bool {
+	return isAdmin(userName)
+}
+
+// isAdmin checks whether the user is the main user of the blog, so the services can grant administrators the same
+// rights as the owners of the managed objects.
+func isAdmin(userName string) bool {
+	return userName != "" && userName == os.Getenv("DEFAULT_USER")
+}
+
 // RegisterFirstUser creates the main user if it doesn't exist yet.
 // The default username and password are read from environment variables.
 func (u userService) RegisterFirstUser() error {
@@ -133,8 +152,15 @@ func (u userService) RegisterUser(user *types.UserLoginInput) (types.User, error
 		PasswordHash: hash,
 	}
 
-	addedUser, err := userRepository.AddUser(&newUser)
-	return mapUser(addedUser), err
+	addedUser, err := userRepository.AddUser(&newUser, func(user *repository.User) []types.DomainEvent {
+		return []types.DomainEvent{types.UserCreatedEvent{User: mapUser(user)}}
+	})
+	if err != nil {
+		return types.User{}, err
+	}
+
+	dispatchEvents(u.cont)
+	return mapUser(addedUser), nil
 }
 
 // UpdateUser receives two user input objects, one with the user's current password, and one with the new attributes.
@@ -177,6 +203,7 @@ func mapUser(u *repository.User) types.User {
 	return types.User{
 		UserName:     u.UserName,
 		PasswordHash: u.PasswordHash,
+		Disabled:     u.Disabled,
 		Posts:        mapPostHandles(u.Posts),
 	}
 }
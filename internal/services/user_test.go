@@ -19,6 +19,7 @@ import (
 type userTestContext struct {
 	mockUserRepository *mocks.MockUserRepository
 	mockJwtUtils       *mocks.MockTokenUtils
+	mockEventBus       *mocks.MockBus
 	sut                services.UserService
 }
 
@@ -32,12 +33,13 @@ func createUserServiceContext(t *testing.T) *userTestContext {
 	mockCtrl := gomock.NewController(t)
 	mockUserRepository := mocks.NewMockUserRepository(mockCtrl)
 	mockJwtUtils := mocks.NewMockTokenUtils(mockCtrl)
-	cont := container.CreateContainer(logger.CreateLogger(), nil, mockUserRepository, mockJwtUtils)
+	mockEventBus := mocks.NewMockBus(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, mockUserRepository, nil, nil, mockJwtUtils, nil, mockEventBus, nil)
 
 	mockUserRepository.EXPECT().GetUser("TEST").Return(nil, nil)
 	sut := services.CreateUserService(cont)
 
-	return &userTestContext{mockUserRepository, mockJwtUtils, sut}
+	return &userTestContext{mockUserRepository, mockJwtUtils, mockEventBus, sut}
 }
 
 // createUserServiceContext creates the context for testing the UserService and reduces code duplication.
@@ -47,11 +49,12 @@ func createUserServiceContextWithoutDefaults(t *testing.T) *userTestContext {
 	mockCtrl := gomock.NewController(t)
 	mockUserRepository := mocks.NewMockUserRepository(mockCtrl)
 	mockJwtUtils := mocks.NewMockTokenUtils(mockCtrl)
-	cont := container.CreateContainer(logger.CreateLogger(), nil, mockUserRepository, mockJwtUtils)
+	mockEventBus := mocks.NewMockBus(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, mockUserRepository, nil, nil, mockJwtUtils, nil, mockEventBus, nil)
 
 	sut := services.CreateUserService(cont)
 
-	return &userTestContext{mockUserRepository, mockJwtUtils, sut}
+	return &userTestContext{mockUserRepository, mockJwtUtils, mockEventBus, sut}
 }
 
 // TestUserService_AuthenticateUser tests user authentication.
@@ -105,6 +108,29 @@ func TestUserService_AuthenticateUser_Invalid_Password(t *testing.T) {
 	assert.Equal(t, expectedError, err, "incorrect error type")
 }
 
+// TestUserService_AuthenticateUser_Disabled tests that disabled users can't sign in, even with a matching password.
+func TestUserService_AuthenticateUser_Disabled(t *testing.T) {
+	c := createUserServiceContext(t)
+
+	userModel := repository.User{
+		UserName:     "testAuthor",
+		PasswordHash: "$2y$10$Hb7smnjLlPtN.VMyNi5dYuMaCmEgCbus/Tapxf2u5jhxkKE1Pr50.",
+		Disabled:     true,
+	}
+
+	input := types.UserLoginInput{
+		UserName: userModel.UserName,
+		Password: "Test",
+	}
+
+	c.mockUserRepository.EXPECT().GetUser(input.UserName).Return(&userModel, nil)
+
+	token, err := c.sut.AuthenticateUser(&input)
+
+	assert.Equal(t, "", token, "no token should be generated")
+	assert.Equal(t, errortypes.IncorrectUsernameOrPasswordError{}, err, "incorrect error type")
+}
+
 // TestUserService_CheckUserPassword tests checking the user's password upon login.
 func TestUserService_CheckUserPassword(t *testing.T) {
 	c := createUserServiceContext(t)
@@ -265,6 +291,7 @@ func TestUserService_GetUsers_Unexpected_Error(t *testing.T) {
 // TestUserService_RegisterFirstUser tests registering the first user.
 func TestUserService_RegisterFirstUser(t *testing.T) {
 	c := createUserServiceContextWithoutDefaults(t)
+	c.mockEventBus.EXPECT().Dispatch()
 
 	userModel := repository.User{
 		ID:           0,
@@ -277,7 +304,7 @@ func TestUserService_RegisterFirstUser(t *testing.T) {
 	t.Setenv("DEFAULT_PASSWORD", "Test")
 
 	c.mockUserRepository.EXPECT().GetUser(userModel.UserName).Return(nil, fmt.Errorf("internal error"))
-	c.mockUserRepository.EXPECT().AddUser(gomock.Any()).Return(&userModel, nil)
+	c.mockUserRepository.EXPECT().AddUser(gomock.Any(), gomock.Any()).Return(&userModel, nil)
 
 	err := c.sut.RegisterFirstUser()
 
@@ -299,6 +326,7 @@ func TestUserService_RegisterFirstUser_Invalid_Defaults(t *testing.T) {
 // TestUserService_RegisterUser tests adding a new user to the system.
 func TestUserService_RegisterUser(t *testing.T) {
 	c := createUserServiceContext(t)
+	c.mockEventBus.EXPECT().Dispatch()
 
 	userModel := repository.User{
 		ID:           0,
@@ -318,7 +346,7 @@ func TestUserService_RegisterUser(t *testing.T) {
 		Posts:        []string{},
 	}
 
-	c.mockUserRepository.EXPECT().AddUser(gomock.Any()).Return(&userModel, nil)
+	c.mockUserRepository.EXPECT().AddUser(gomock.Any(), gomock.Any()).Return(&userModel, nil)
 
 	user, err := c.sut.RegisterUser(&input)
 
@@ -468,3 +496,12 @@ func TestUserService_UpdateUser_Unexpected_Error(t *testing.T) {
 
 	assert.NotNil(t, err, "expected to receive an error")
 }
+
+// TestUserService_IsAdmin tests checking the admin rights of users.
+func TestUserService_IsAdmin(t *testing.T) {
+	c := createUserServiceContext(t)
+
+	assert.True(t, c.sut.IsAdmin("TEST"), "the default user should be an administrator")
+	assert.False(t, c.sut.IsAdmin("other"), "other users shouldn't be administrators")
+	assert.False(t, c.sut.IsAdmin(""), "anonymous users shouldn't be administrators")
+}
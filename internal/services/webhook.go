@@ -0,0 +1,352 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/types"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers of the requests delivering events to webhooks.
+const (
+	webhookEventHeader     = "X-Webhook-Event"
+	webhookDeliveryHeader  = "X-Webhook-Delivery"
+	webhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// webhookBatchSize is the number of due deliveries sent at once.
+const webhookBatchSize = 50
+
+// webhookLogSize is the number of the latest deliveries returned in the delivery log of a webhook.
+const webhookLogSize = 100
+
+// minWebhookSecretLength is the minimum length of secrets provided for signing the payloads.
+const minWebhookSecretLength = 16
+
+// WebhookService interface. Defines the management of webhooks and the delivery of events to them.
+type WebhookService interface {
+	AddWebhook(webhook *types.Webhook) (types.Webhook, error)
+	DeleteWebhook(id uint) error
+	DeliverWebhooks() (int, error)
+	GetDeliveries(id uint) ([]types.WebhookDelivery, error)
+	GetWebhooks() ([]types.Webhook, error)
+	RunDeliveries(stop <-chan struct{})
+}
+
+// webhookService is the concrete implementation of the WebhookService interface.
+type webhookService struct {
+	cont   container.Container
+	client *http.Client
+	config deliveryConfig
+}
+
+// CreateWebhookService instantiates the webhookService using the application container.
+// The settings of the delivery queue are read from the environment.
+// The service subscribes to the events of the event bus, which are queued for delivery to the subscribed webhooks.
+func CreateWebhookService(cont container.Container) WebhookService {
+	config := loadWebhookConfig()
+	w := &webhookService{cont, &http.Client{Timeout: config.Timeout}, config}
+
+	eventBus := cont.GetEventBus()
+	for _, event := range []string{types.EventPostCreated, types.EventPostUpdated, types.EventPostDeleted, types.EventUserCreated} {
+		eventBus.Subscribe(event, w.queueEvent)
+	}
+
+	return w
+}
+
+// loadWebhookConfig reads the settings of the delivery queue from the WEBHOOK_* environment variables.
+func loadWebhookConfig() deliveryConfig {
+	return loadDeliveryConfig("WEBHOOK", deliveryConfig{
+		MaxAttempts:  8,
+		RetryDelay:   30 * time.Second,
+		PollInterval: 5 * time.Second,
+		Timeout:      10 * time.Second,
+	})
+}
+
+// AddWebhook validates and adds a new webhook. If no secret is provided, a random one is generated.
+// The secret is only returned when the webhook is created.
+func (w webhookService) AddWebhook(webhook *types.Webhook) (types.Webhook, error) {
+	log := w.cont.GetLogger()
+	webhookRepository := w.cont.GetWebhookRepository()
+
+	if err := validateWebhook(webhook); err != nil {
+		log.Debugf("invalid webhook %s: %v", webhook.URL, err)
+		return types.Webhook{}, err
+	}
+
+	if webhook.Secret == "" {
+		secret, err := createWebhookSecret()
+		if err != nil {
+			log.Errorf("failed to generate webhook secret: %v", err)
+			return types.Webhook{}, err
+		}
+		webhook.Secret = secret
+	}
+
+	log.Infof("adding new webhook for %s with events %v", webhook.URL, webhook.Events)
+
+	newWebhook, err := webhookRepository.AddWebhook(webhook)
+	if err != nil {
+		return types.Webhook{}, err
+	}
+
+	result := mapWebhook(newWebhook)
+	result.Secret = newWebhook.Secret
+	return result, nil
+}
+
+// DeleteWebhook removes the webhook with the given ID together with its pending and past deliveries.
+func (w webhookService) DeleteWebhook(id uint) error {
+	log := w.cont.GetLogger()
+	webhookRepository := w.cont.GetWebhookRepository()
+
+	log.Infof("deleting webhook %d", id)
+	return webhookRepository.DeleteWebhook(id)
+}
+
+// DeliverWebhooks sends the deliveries whose next attempt is due and returns their number.
+// Failed deliveries are retried with exponential backoff until the maximum number of attempts is reached.
+func (w webhookService) DeliverWebhooks() (int, error) {
+	webhookRepository := w.cont.GetWebhookRepository()
+	return deliverDue(w.cont.GetLogger(), "webhook", w.config, webhookBatchSize, webhookRepository.ClaimDueDeliveries, w.attemptDelivery, webhookRepository.UpdateDelivery)
+}
+
+// GetDeliveries retrieves the delivery log of the webhook with the given ID, starting with the latest delivery.
+func (w webhookService) GetDeliveries(id uint) ([]types.WebhookDelivery, error) {
+	webhookRepository := w.cont.GetWebhookRepository()
+
+	if _, err := webhookRepository.GetWebhook(id); err != nil {
+		return []types.WebhookDelivery{}, err
+	}
+
+	deliveries, err := webhookRepository.GetDeliveries(id, webhookLogSize)
+	return mapWebhookDeliveries(deliveries), err
+}
+
+// GetWebhooks retrieves every webhook without its secret.
+func (w webhookService) GetWebhooks() ([]types.Webhook, error) {
+	webhookRepository := w.cont.GetWebhookRepository()
+	webhooks, err := webhookRepository.GetWebhooks()
+	return mapWebhooks(webhooks), err
+}
+
+// RunDeliveries sends the due deliveries periodically until the stop channel is closed.
+func (w webhookService) RunDeliveries(stop <-chan struct{}) {
+	runQueue(stop, w.config.PollInterval, webhookBatchSize, w.DeliverWebhooks)
+}
+
+// queueEvent queues the delivery of a domain event to every webhook subscribed to it.
+// Events which can't be queued are dispatched again by the event bus.
+func (w webhookService) queueEvent(event types.DomainEvent) error {
+	log := w.cont.GetLogger()
+	webhookRepository := w.cont.GetWebhookRepository()
+
+	name := event.EventName()
+
+	webhooks, err := webhookRepository.GetWebhooks()
+	if err != nil {
+		log.Errorf("failed to get webhooks for event %s: %v", name, err)
+		return err
+	}
+
+	now := time.Now().UTC()
+	payload, err := json.Marshal(types.WebhookPayload{Event: name, Timestamp: now, Data: webhookPayloadData(event)})
+	if err != nil {
+		log.Errorf("failed to encode payload of event %s: %v", name, err)
+		return err
+	}
+
+	deliveries := make([]repository.WebhookDelivery, 0)
+	for _, webhook := range webhooks {
+		if !containsString(webhook.Events, name) {
+			continue
+		}
+		deliveries = append(deliveries, repository.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			Event:         name,
+			Payload:       string(payload),
+			DeliveryState: repository.DeliveryState{Status: types.DeliveryPending, NextAttemptAt: now},
+		})
+	}
+
+	if err := webhookRepository.AddDeliveries(deliveries); err != nil {
+		log.Errorf("failed to queue deliveries of event %s: %v", name, err)
+		return err
+	}
+	return nil
+}
+
+// webhookPayloadData returns the post or the user affected by an event, which is sent as the data of the payload.
+func webhookPayloadData(event types.DomainEvent) interface{} {
+	switch e := event.(type) {
+	case types.PostCreatedEvent:
+		return e.Post
+	case types.PostUpdatedEvent:
+		return e.Post
+	case types.PostDeletedEvent:
+		return e.Post
+	case types.UserCreatedEvent:
+		return e.User
+	default:
+		return event
+	}
+}
+
+// attemptDelivery sends a delivery and records the outcome of the attempt.
+// Failed deliveries are scheduled for another attempt unless the maximum number of attempts is reached.
+func (w webhookService) attemptDelivery(delivery *repository.WebhookDelivery) {
+	log := w.cont.GetLogger()
+
+	status, err := w.send(delivery)
+	w.config.recordAttempt(&delivery.DeliveryState, status, err, false)
+
+	switch delivery.Status {
+	case types.DeliverySucceeded:
+		log.Debugf("delivered event %s to webhook %d", delivery.Event, delivery.WebhookID)
+	case types.DeliveryFailed:
+		log.Warnf("giving up delivery %d of event %s to webhook %d after %d attempts: %v", delivery.ID, delivery.Event, delivery.WebhookID, delivery.Attempts, err)
+	default:
+		log.Debugf("delivery %d of event %s to webhook %d failed, retrying at %v: %v", delivery.ID, delivery.Event, delivery.WebhookID, delivery.NextAttemptAt, err)
+	}
+}
+
+// send posts the signed payload of a delivery to the URL of its webhook and returns the response status.
+// Responses with a status other than 2xx are considered failures.
+func (w webhookService) send(delivery *repository.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+
+	req, err := http.NewRequest(http.MethodPost, delivery.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookEventHeader, delivery.Event)
+	req.Header.Set(webhookDeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(webhookSignatureHeader, SignWebhookPayload(delivery.Webhook.Secret, body))
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected response status %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+// SignWebhookPayload calculates the signature of a payload sent in the X-Webhook-Signature header:
+// the hex-encoded HMAC-SHA256 of the request body using the secret of the webhook, prefixed with "sha256=".
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// validateWebhook checks the URL, the events and the secret of a webhook.
+func validateWebhook(webhook *types.Webhook) error {
+	if !isAbsoluteURL(webhook.URL) {
+		return errortypes.InvalidWebhookError{Reason: "the URL must be an absolute HTTP(S) URL"}
+	}
+
+	if len(webhook.Events) == 0 {
+		return errortypes.InvalidWebhookError{Reason: "at least one event is required"}
+	}
+	for _, event := range webhook.Events {
+		if !types.IsValidWebhookEvent(event) {
+			return errortypes.InvalidWebhookError{Reason: "unsupported event \"" + event + "\""}
+		}
+	}
+
+	if webhook.Secret != "" && len(webhook.Secret) < minWebhookSecretLength {
+		return errortypes.InvalidWebhookError{Reason: fmt.Sprintf("the secret must be at least %d characters long", minWebhookSecretLength)}
+	}
+
+	return nil
+}
+
+// createWebhookSecret generates a random secret for signing the payloads of a webhook.
+func createWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// containsString checks whether the slice contains the given value.
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// mapWebhook maps a Webhook model to a webhook data object without its secret
+func mapWebhook(w *repository.Webhook) types.Webhook {
+	if w == nil {
+		return types.Webhook{}
+	}
+	return types.Webhook{
+		ID:           w.ID,
+		URL:          w.URL,
+		Events:       w.Events,
+		CreationTime: w.CreatedAt,
+	}
+}
+
+// mapWebhooks maps a slice of Webhook models to a slice of webhook data objects
+func mapWebhooks(w []repository.Webhook) []types.Webhook {
+	webhooks := make([]types.Webhook, 0, len(w))
+	for _, webhook := range w {
+		webhooks = append(webhooks, mapWebhook(&webhook))
+	}
+	return webhooks
+}
+
+// mapWebhookDelivery maps a WebhookDelivery model to a delivery data object.
+// The time of the next attempt is only set for pending deliveries.
+func mapWebhookDelivery(d *repository.WebhookDelivery) types.WebhookDelivery {
+	delivery := types.WebhookDelivery{
+		ID:             d.ID,
+		Event:          d.Event,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		Error:          d.Error,
+		Payload:        json.RawMessage(d.Payload),
+		CreationTime:   d.CreatedAt,
+		DeliveryTime:   d.DeliveredAt,
+	}
+	if d.Status == types.DeliveryPending {
+		next := d.NextAttemptAt
+		delivery.NextAttempt = &next
+	}
+	return delivery
+}
+
+// mapWebhookDeliveries maps a slice of WebhookDelivery models to a slice of delivery data objects
+func mapWebhookDeliveries(d []repository.WebhookDelivery) []types.WebhookDelivery {
+	deliveries := make([]types.WebhookDelivery, 0, len(d))
+	for _, delivery := range d {
+		deliveries = append(deliveries, mapWebhookDelivery(&delivery))
+	}
+	return deliveries
+}
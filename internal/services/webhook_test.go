@@ -0,0 +1,236 @@
+package services_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/events"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/types"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// webhookTestContext contains objects relevant for testing the WebhookService.
+type webhookTestContext struct {
+	mockWebhookRepository *mocks.MockWebhookRepository
+	handlers              map[string]events.Handler
+	sut                   services.WebhookService
+}
+
+// createWebhookServiceContext creates the context for testing the WebhookService and reduces code duplication.
+func createWebhookServiceContext(t *testing.T) *webhookTestContext {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	mockWebhookRepository := mocks.NewMockWebhookRepository(mockCtrl)
+	mockEventBus := mocks.NewMockBus(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, mockWebhookRepository, nil, nil, nil, mockEventBus, nil)
+
+	handlers := map[string]events.Handler{}
+	mockEventBus.EXPECT().Subscribe(gomock.Any(), gomock.Any()).Times(4).Do(func(event string, handler events.Handler) {
+		handlers[event] = handler
+	})
+	sut := services.CreateWebhookService(cont)
+
+	return &webhookTestContext{mockWebhookRepository, handlers, sut}
+}
+
+// TestWebhookService_AddWebhook tests adding a new webhook with a generated secret.
+func TestWebhookService_AddWebhook(t *testing.T) {
+	t.Parallel()
+	c := createWebhookServiceContext(t)
+
+	input := types.Webhook{URL: "https://example.com/hook", Events: []string{types.EventPostCreated}}
+
+	c.mockWebhookRepository.EXPECT().AddWebhook(gomock.Any()).DoAndReturn(func(w *types.Webhook) (*repository.Webhook, error) {
+		return &repository.Webhook{ID: 1, URL: w.URL, Secret: w.Secret, Events: w.Events}, nil
+	})
+
+	w, err := c.sut.AddWebhook(&input)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, uint(1), w.ID, "incorrect webhook ID")
+	assert.Equal(t, input.URL, w.URL, "incorrect webhook URL")
+	assert.Len(t, w.Secret, 64, "a secret should be generated")
+}
+
+// TestWebhookService_AddWebhook_Invalid tests adding invalid webhooks.
+func TestWebhookService_AddWebhook_Invalid(t *testing.T) {
+	t.Parallel()
+	c := createWebhookServiceContext(t)
+
+	inputs := []types.Webhook{
+		{URL: "/hook", Events: []string{types.EventPostCreated}},
+		{URL: "ftp://example.com/hook", Events: []string{types.EventPostCreated}},
+		{URL: "https://example.com/hook"},
+		{URL: "https://example.com/hook", Events: []string{"post.published"}},
+		{URL: "https://example.com/hook", Events: []string{types.EventPostCreated}, Secret: "short"},
+	}
+
+	for _, input := range inputs {
+		_, err := c.sut.AddWebhook(&input)
+		assert.IsType(t, errortypes.InvalidWebhookError{}, err, "webhook %v should be rejected", input)
+	}
+}
+
+// TestWebhookService_GetWebhooks tests getting every webhook without its secret.
+func TestWebhookService_GetWebhooks(t *testing.T) {
+	t.Parallel()
+	c := createWebhookServiceContext(t)
+
+	models := []repository.Webhook{{ID: 1, URL: "https://example.com/hook", Secret: "secret", Events: []string{types.EventUserCreated}}}
+	expected := []types.Webhook{{ID: 1, URL: "https://example.com/hook", Events: []string{types.EventUserCreated}}}
+
+	c.mockWebhookRepository.EXPECT().GetWebhooks().Return(models, nil)
+
+	w, err := c.sut.GetWebhooks()
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, expected, w, "webhooks don't match the expected output")
+}
+
+// TestWebhookService_GetDeliveries_Not_Found tests getting the delivery log of a non-existent webhook.
+func TestWebhookService_GetDeliveries_Not_Found(t *testing.T) {
+	t.Parallel()
+	c := createWebhookServiceContext(t)
+
+	expectedError := errortypes.WebhookNotFoundError{Webhook: types.Webhook{ID: 2}}
+
+	c.mockWebhookRepository.EXPECT().GetWebhook(uint(2)).Return(nil, expectedError)
+
+	d, err := c.sut.GetDeliveries(2)
+
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+	assert.Equal(t, []types.WebhookDelivery{}, d, "shouldn't return any deliveries")
+}
+
+// TestWebhookService_Queue_Event tests queueing the deliveries of an event to the subscribed webhooks only.
+func TestWebhookService_Queue_Event(t *testing.T) {
+	t.Parallel()
+	c := createWebhookServiceContext(t)
+
+	models := []repository.Webhook{
+		{ID: 1, URL: "https://example.com/posts", Events: []string{types.EventPostCreated, types.EventPostUpdated}},
+		{ID: 2, URL: "https://example.com/users", Events: []string{types.EventUserCreated}},
+	}
+
+	c.mockWebhookRepository.EXPECT().GetWebhooks().Return(models, nil)
+	c.mockWebhookRepository.EXPECT().AddDeliveries(gomock.Any()).DoAndReturn(func(d []repository.WebhookDelivery) error {
+		assert.Len(t, d, 1, "expected exactly 1 delivery")
+		assert.Equal(t, uint(1), d[0].WebhookID, "incorrect webhook")
+		assert.Equal(t, types.DeliveryPending, d[0].Status, "delivery should be pending")
+
+		var payload map[string]interface{}
+		_ = json.Unmarshal([]byte(d[0].Payload), &payload)
+		assert.Equal(t, types.EventPostCreated, payload["event"], "incorrect event in the payload")
+		assert.Equal(t, "testUrlHandle", payload["data"].(map[string]interface{})["urlHandle"], "the payload should contain the post")
+		return nil
+	})
+
+	err := c.handlers[types.EventPostCreated](types.PostCreatedEvent{Post: types.Post{URLHandle: "testUrlHandle"}})
+
+	assert.Nil(t, err, "should complete without error")
+}
+
+// TestWebhookService_Queue_Event_Unexpected_Error tests failing to queue an event, which is dispatched again by the event bus.
+func TestWebhookService_Queue_Event_Unexpected_Error(t *testing.T) {
+	t.Parallel()
+	c := createWebhookServiceContext(t)
+
+	c.mockWebhookRepository.EXPECT().GetWebhooks().Return(nil, fmt.Errorf("unexpected error"))
+
+	err := c.handlers[types.EventUserCreated](types.UserCreatedEvent{User: types.User{UserName: "testUser"}})
+
+	assert.NotNil(t, err, "expected error")
+}
+
+// TestWebhookService_DeliverWebhooks tests sending a signed delivery to a webhook.
+func TestWebhookService_DeliverWebhooks(t *testing.T) {
+	t.Parallel()
+	c := createWebhookServiceContext(t)
+
+	payload := `{"event":"post.created"}`
+	secret := "0123456789abcdef"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, payload, string(body), "incorrect request body")
+		assert.Equal(t, types.EventPostCreated, r.Header.Get("X-Webhook-Event"), "incorrect event header")
+		assert.Equal(t, services.SignWebhookPayload(secret, body), r.Header.Get("X-Webhook-Signature"), "incorrect signature")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	delivery := repository.WebhookDelivery{
+		ID:        1,
+		WebhookID: 1,
+		Webhook:   repository.Webhook{ID: 1, URL: server.URL, Secret: secret},
+		Event:     types.EventPostCreated,
+		Payload:   payload,
+		DeliveryState: repository.DeliveryState{
+			Status: types.DeliveryPending,
+		},
+	}
+
+	c.mockWebhookRepository.EXPECT().ClaimDueDeliveries(gomock.Any(), gomock.Any(), gomock.Any()).Return([]repository.WebhookDelivery{delivery}, nil)
+	c.mockWebhookRepository.EXPECT().UpdateDelivery(gomock.Any()).DoAndReturn(func(d *repository.WebhookDelivery) error {
+		assert.Equal(t, types.DeliverySucceeded, d.Status, "delivery should succeed")
+		assert.Equal(t, 1, d.Attempts, "incorrect number of attempts")
+		assert.Equal(t, http.StatusNoContent, d.ResponseStatus, "incorrect response status")
+		assert.NotNil(t, d.DeliveredAt, "delivery time should be set")
+		return nil
+	})
+
+	n, err := c.sut.DeliverWebhooks()
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 1, n, "incorrect number of deliveries")
+}
+
+// TestWebhookService_DeliverWebhooks_Retry tests scheduling another attempt with exponential backoff after a failed delivery.
+func TestWebhookService_DeliverWebhooks_Retry(t *testing.T) {
+	t.Parallel()
+	c := createWebhookServiceContext(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	delivery := repository.WebhookDelivery{
+		ID:        1,
+		WebhookID: 1,
+		Webhook:   repository.Webhook{ID: 1, URL: server.URL, Secret: "0123456789abcdef"},
+		Event:     types.EventPostUpdated,
+		Payload:   "{}",
+		DeliveryState: repository.DeliveryState{
+			Status:   types.DeliveryPending,
+			Attempts: 2,
+		},
+	}
+	start := time.Now()
+
+	c.mockWebhookRepository.EXPECT().ClaimDueDeliveries(gomock.Any(), gomock.Any(), gomock.Any()).Return([]repository.WebhookDelivery{delivery}, nil)
+	c.mockWebhookRepository.EXPECT().UpdateDelivery(gomock.Any()).DoAndReturn(func(d *repository.WebhookDelivery) error {
+		assert.Equal(t, types.DeliveryPending, d.Status, "delivery should stay pending")
+		assert.Equal(t, 3, d.Attempts, "incorrect number of attempts")
+		assert.Equal(t, http.StatusBadGateway, d.ResponseStatus, "incorrect response status")
+		assert.Equal(t, fmt.Sprintf("unexpected response status %d", http.StatusBadGateway), d.Error, "incorrect error")
+		assert.False(t, d.NextAttemptAt.Before(start.Add(2*time.Minute)), "the third attempt should be delayed by 4 times the base delay")
+		return nil
+	})
+
+	_, err := c.sut.DeliverWebhooks()
+
+	assert.Nil(t, err, "should complete without error")
+}
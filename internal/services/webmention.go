@@ -0,0 +1,503 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/markdown"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/site"
+	"github.com/wlchs/blog/internal/types"
+	"github.com/wlchs/blog/internal/webmention"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// webmentionBatchSize is the number of due verifications and deliveries processed at once.
+const webmentionBatchSize = 50
+
+// maxWebmentionDocumentSize limits the size of the fetched sources and targets.
+const maxWebmentionDocumentSize = 1 << 20
+
+// maxWebmentionURLLength limits the length of the stored source and target URLs.
+const maxWebmentionURLLength = 255
+
+// errNoWebmentionEndpoint is the error of deliveries to targets which don't accept webmentions.
+var errNoWebmentionEndpoint = errors.New("the target has no Webmention endpoint")
+
+// WebmentionService interface. Defines the verification and moderation of received webmentions and sending webmentions
+// to the pages linked from the posts.
+type WebmentionService interface {
+	GetWebmentions(urlHandle string, status string, userName string) ([]types.Webmention, error)
+	ModerateWebmention(id uint, status string, userName string) (types.Webmention, error)
+	ReceiveWebmention(source string, target string, address string) error
+	RunWebmentions(stop <-chan struct{})
+	SendWebmentions() (int, error)
+	VerifyWebmentions() (int, error)
+}
+
+// webmentionService is the concrete implementation of the WebmentionService interface.
+type webmentionService struct {
+	cont     container.Container
+	client   *http.Client
+	site     site.Config
+	config   webmentionConfig
+	throttle *throttle
+}
+
+// webmentionConfig contains the settings of the verification and delivery queues.
+type webmentionConfig struct {
+	deliveryConfig
+	RateLimit  int
+	RateWindow time.Duration
+}
+
+// CreateWebmentionService instantiates the webmentionService using the application container.
+// The posts are identified by the site URL, webmentions are neither received nor sent if it isn't set.
+// The service subscribes to the post events of the event bus, which are sent to the pages linked from the posts.
+// The sources and the endpoints are provided by third parties, so they're only requested from public addresses.
+func CreateWebmentionService(cont container.Container) WebmentionService {
+	config := loadWebmentionConfig()
+	w := &webmentionService{cont, createRemoteClient(config.Timeout), site.LoadConfig(), config, createThrottle()}
+
+	eventBus := cont.GetEventBus()
+	for _, event := range []string{types.EventPostCreated, types.EventPostUpdated, types.EventPostDeleted} {
+		eventBus.Subscribe(event, w.queueWebmentions)
+	}
+
+	return w
+}
+
+// loadWebmentionConfig reads the settings of the verification and delivery queues from the WEBMENTION_* environment variables.
+func loadWebmentionConfig() webmentionConfig {
+	config := webmentionConfig{
+		deliveryConfig: loadDeliveryConfig("WEBMENTION", deliveryConfig{
+			MaxAttempts:  5,
+			RetryDelay:   time.Minute,
+			PollInterval: 10 * time.Second,
+			Timeout:      10 * time.Second,
+		}),
+		RateLimit:  20,
+		RateWindow: time.Hour,
+	}
+
+	if limit, err := strconv.Atoi(os.Getenv("WEBMENTION_RATE_LIMIT")); err == nil && limit > 0 {
+		config.RateLimit = limit
+	}
+	if window, err := time.ParseDuration(os.Getenv("WEBMENTION_RATE_WINDOW")); err == nil && window > 0 {
+		config.RateWindow = window
+	}
+
+	return config
+}
+
+// GetWebmentions retrieves the received mentions of a post. The author and the contributors of the post see every
+// mention, optionally filtered by status, other users only see the approved mentions of public posts.
+func (w webmentionService) GetWebmentions(urlHandle string, status string, userName string) ([]types.Webmention, error) {
+	postRepository := w.cont.GetPostRepository()
+	webmentionRepository := w.cont.GetWebmentionRepository()
+
+	if status != "" && !types.IsValidWebmentionStatus(status) {
+		return []types.Webmention{}, errortypes.InvalidWebmentionError{Reason: "unsupported status \"" + status + "\""}
+	}
+
+	post, err := postRepository.GetPost(urlHandle)
+	if err != nil {
+		return []types.Webmention{}, err
+	}
+
+	if !canReadPost(post, userName) {
+		if post.Visibility != types.VisibilityPublic {
+			return []types.Webmention{}, errortypes.PostNotFoundError{Post: types.Post{URLHandle: urlHandle}}
+		}
+		status = types.WebmentionApproved
+	}
+
+	mentions, err := webmentionRepository.GetWebmentions(post.ID, status)
+	return mapWebmentions(mentions, urlHandle), err
+}
+
+// ModerateWebmention approves or rejects a verified mention. Only the author, the co-authors and the editors of the
+// mentioned post may moderate its mentions.
+func (w webmentionService) ModerateWebmention(id uint, status string, userName string) (types.Webmention, error) {
+	log := w.cont.GetLogger()
+	webmentionRepository := w.cont.GetWebmentionRepository()
+
+	if status != types.WebmentionApproved && status != types.WebmentionRejected {
+		return types.Webmention{}, errortypes.InvalidWebmentionError{Reason: "the status must be approved or rejected"}
+	}
+
+	mention, err := webmentionRepository.GetWebmention(id)
+	if err != nil {
+		return types.Webmention{}, err
+	}
+
+	if !canEditPost(&mention.Post, userName) {
+		return types.Webmention{}, errortypes.PostEditForbiddenError{Post: types.Post{URLHandle: mention.Post.URLHandle}, UserName: userName}
+	}
+
+	if mention.Status == types.WebmentionPending || mention.Status == types.WebmentionInvalid {
+		return types.Webmention{}, errortypes.InvalidWebmentionError{Reason: "only verified webmentions can be moderated"}
+	}
+
+	log.Infof("user %s set the status of webmention %d to %s", userName, id, status)
+
+	mention.Status = status
+	if err := webmentionRepository.UpdateWebmention(mention); err != nil {
+		return types.Webmention{}, err
+	}
+
+	return mapWebmention(mention), nil
+}
+
+// ReceiveWebmention validates a received mention of a post and queues it for the verification of its source.
+// Only public posts can be mentioned. Senders are throttled by their address, since every mention causes a request.
+func (w webmentionService) ReceiveWebmention(source string, target string, address string) error {
+	log := w.cont.GetLogger()
+	postRepository := w.cont.GetPostRepository()
+	webmentionRepository := w.cont.GetWebmentionRepository()
+
+	if w.site.BaseURL == "" {
+		return errortypes.WebmentionDisabledError{}
+	}
+
+	if retryAfter, ok := w.throttle.allow(address, w.config.RateLimit, w.config.RateWindow, time.Now()); !ok {
+		log.Debugf("throttled webmention from %s", address)
+		return errortypes.WebmentionRateLimitError{RetryAfter: retryAfter}
+	}
+
+	if !isAbsoluteURL(source) || !isAbsoluteURL(target) {
+		return errortypes.InvalidWebmentionError{Reason: "the source and the target must be absolute HTTP(S) URLs"}
+	}
+	if len(source) > maxWebmentionURLLength || len(target) > maxWebmentionURLLength {
+		return errortypes.InvalidWebmentionError{Reason: fmt.Sprintf("the source and the target must be at most %d characters long", maxWebmentionURLLength)}
+	}
+	if source == target {
+		return errortypes.InvalidWebmentionError{Reason: "the source must differ from the target"}
+	}
+
+	urlHandle, ok := w.postHandle(target)
+	if !ok {
+		return errortypes.InvalidWebmentionError{Reason: "the target isn't a post of this blog"}
+	}
+
+	post, err := postRepository.GetPost(urlHandle)
+	switch err.(type) {
+	case nil:
+	case errortypes.PostNotFoundError:
+		return errortypes.InvalidWebmentionError{Reason: "the target isn't a post of this blog"}
+	default:
+		return err
+	}
+	if post.Visibility != types.VisibilityPublic {
+		return errortypes.InvalidWebmentionError{Reason: "the target isn't a post of this blog"}
+	}
+
+	log.Infof("received webmention of post %s from %s", urlHandle, source)
+
+	return webmentionRepository.AddWebmention(&repository.Webmention{
+		PostID:        post.ID,
+		Source:        source,
+		Target:        target,
+		Type:          types.ReactionMention,
+		Status:        types.WebmentionPending,
+		Queued:        true,
+		NextAttemptAt: time.Now().UTC(),
+	})
+}
+
+// RunWebmentions verifies the received mentions and sends the due deliveries periodically until the stop channel is closed.
+func (w webmentionService) RunWebmentions(stop <-chan struct{}) {
+	runQueue(stop, w.config.PollInterval, webmentionBatchSize, w.VerifyWebmentions, w.SendWebmentions)
+}
+
+// SendWebmentions sends the deliveries whose next attempt is due and returns their number.
+// Failed deliveries are retried with exponential backoff until the maximum number of attempts is reached.
+func (w webmentionService) SendWebmentions() (int, error) {
+	webmentionRepository := w.cont.GetWebmentionRepository()
+	return deliverDue(w.cont.GetLogger(), "webmention", w.config.deliveryConfig, webmentionBatchSize, webmentionRepository.ClaimDueDeliveries, w.attemptDelivery, webmentionRepository.UpdateDelivery)
+}
+
+// VerifyWebmentions verifies the queued mentions whose next attempt is due and returns their number.
+// Sources which can't be fetched are retried with exponential backoff until the maximum number of attempts is reached.
+func (w webmentionService) VerifyWebmentions() (int, error) {
+	log := w.cont.GetLogger()
+	webmentionRepository := w.cont.GetWebmentionRepository()
+
+	mentions, err := webmentionRepository.ClaimDueWebmentions(time.Now(), webmentionBatchSize, w.config.claimLease(webmentionBatchSize))
+	if err != nil {
+		log.Errorf("failed to claim due webmentions: %v", err)
+		return 0, err
+	}
+
+	for i := range mentions {
+		mention := &mentions[i]
+		w.verify(mention)
+		if err := webmentionRepository.UpdateWebmention(mention); err != nil {
+			log.Errorf("failed to update webmention %d: %v", mention.ID, err)
+			return i, err
+		}
+	}
+
+	return len(mentions), nil
+}
+
+// verify checks whether the source of a mention links to its target and records the outcome of the attempt.
+// Verified mentions wait for moderation, mentions verified again keep their moderation state.
+// Mentions whose source is gone or doesn't link to the target anymore become invalid.
+func (w webmentionService) verify(mention *repository.Webmention) {
+	log := w.cont.GetLogger()
+
+	mention.Attempts++
+	source, linked, err := w.fetchSource(mention.Source, mention.Target)
+
+	if err != nil {
+		mention.Error = err.Error()
+		if mention.Attempts < w.config.MaxAttempts {
+			mention.NextAttemptAt = time.Now().Add(retryDelay(w.config.RetryDelay, mention.Attempts))
+			log.Debugf("verification of webmention %d from %s failed, retrying at %v: %v", mention.ID, mention.Source, mention.NextAttemptAt, err)
+			return
+		}
+		mention.Queued = false
+		if mention.Status == types.WebmentionPending {
+			mention.Status = types.WebmentionInvalid
+		}
+		log.Warnf("giving up verification of webmention %d from %s after %d attempts: %v", mention.ID, mention.Source, mention.Attempts, err)
+		return
+	}
+
+	mention.Queued = false
+	if !linked {
+		mention.Status = types.WebmentionInvalid
+		mention.Error = "the source doesn't link to the target"
+		log.Debugf("webmention %d from %s is invalid: %s", mention.ID, mention.Source, mention.Error)
+		return
+	}
+
+	now := time.Now()
+	mention.Type = source.Type
+	mention.AuthorName = source.AuthorName
+	mention.AuthorURL = source.AuthorURL
+	mention.Title = source.Title
+	mention.Content = source.Content
+	mention.Error = ""
+	mention.VerifiedAt = &now
+	if mention.Status == types.WebmentionPending || mention.Status == types.WebmentionInvalid {
+		mention.Status = types.WebmentionVerified
+	}
+	log.Debugf("verified webmention %d from %s", mention.ID, mention.Source)
+}
+
+// fetchSource retrieves the source of a mention and checks whether it links to the target.
+// Sources which are gone don't link to the target, other responses with a status other than 2xx are considered failures.
+func (w webmentionService) fetchSource(source string, target string) (webmention.Source, bool, error) {
+	req, err := http.NewRequest(http.MethodGet, source, nil)
+	if err != nil {
+		return webmention.Source{}, false, err
+	}
+	req.Header.Set("Accept", "text/html, text/plain;q=0.9")
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		return webmention.Source{}, false, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusGone || res.StatusCode == http.StatusNotFound {
+		return webmention.Source{}, false, nil
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return webmention.Source{}, false, fmt.Errorf("unexpected response status %d", res.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxWebmentionDocumentSize))
+	if err != nil {
+		return webmention.Source{}, false, err
+	}
+
+	if !strings.HasPrefix(res.Header.Get("Content-Type"), "text/html") {
+		return webmention.Source{Type: types.ReactionMention}, bytes.Contains(body, []byte(target)), nil
+	}
+
+	mention, linked := webmention.ParseSource(res.Request.URL, bytes.NewReader(body), target)
+	return mention, linked, nil
+}
+
+// queueWebmentions queues the mentions of the pages linked from a post. The pages mentioned by earlier versions of
+// the post are notified as well, so they can remove the mentions whose links were removed. Posts which aren't public
+// anymore don't link to any page. Events which can't be queued are dispatched again by the event bus.
+func (w webmentionService) queueWebmentions(event types.DomainEvent) error {
+	log := w.cont.GetLogger()
+	webmentionRepository := w.cont.GetWebmentionRepository()
+
+	if w.site.BaseURL == "" {
+		return nil
+	}
+
+	var post types.Post
+	links := make([]string, 0)
+	switch e := event.(type) {
+	case types.PostCreatedEvent:
+		if e.Post.Visibility != types.VisibilityPublic {
+			return nil
+		}
+		post, links = e.Post, webmention.Links(markdown.Render(e.Post.Body))
+	case types.PostUpdatedEvent:
+		post = e.Post
+		if e.Post.Visibility == types.VisibilityPublic {
+			links = webmention.Links(markdown.Render(e.Post.Body))
+		}
+	case types.PostDeletedEvent:
+		post = e.Post
+	default:
+		return nil
+	}
+
+	source := w.site.BaseURL + site.PostPath(post.URLHandle)
+	previous, err := webmentionRepository.GetDeliveryTargets(source)
+	if err != nil {
+		log.Errorf("failed to get webmention targets of %s: %v", source, err)
+		return err
+	}
+
+	targets := make([]string, 0, len(links)+len(previous))
+	for _, target := range append(links, previous...) {
+		if w.isOwnURL(target) || len(target) > maxWebmentionURLLength || containsString(targets, target) {
+			continue
+		}
+		targets = append(targets, target)
+	}
+
+	if err := webmentionRepository.QueueDeliveries(source, targets, time.Now().UTC()); err != nil {
+		log.Errorf("failed to queue webmentions from %s: %v", source, err)
+		return err
+	}
+	return nil
+}
+
+// attemptDelivery sends a delivery and records the outcome of the attempt.
+// Failed deliveries are scheduled for another attempt unless the maximum number of attempts is reached, the target
+// has no endpoint or the endpoint rejected the mention.
+func (w webmentionService) attemptDelivery(delivery *repository.WebmentionDelivery) {
+	log := w.cont.GetLogger()
+
+	status, err := w.send(delivery)
+	rejected := status >= 400 && status < 500 && status != http.StatusTooManyRequests
+	w.config.recordAttempt(&delivery.DeliveryState, status, err, errors.Is(err, errNoWebmentionEndpoint) || rejected)
+
+	switch delivery.Status {
+	case types.DeliverySucceeded:
+		log.Debugf("sent webmention from %s to %s", delivery.Source, delivery.Endpoint)
+	case types.DeliveryFailed:
+		log.Debugf("giving up webmention %d from %s to %s after %d attempts: %v", delivery.ID, delivery.Source, delivery.Target, delivery.Attempts, err)
+	default:
+		log.Debugf("webmention %d from %s to %s failed, retrying at %v: %v", delivery.ID, delivery.Source, delivery.Target, delivery.NextAttemptAt, err)
+	}
+}
+
+// send discovers the endpoint of the target of a delivery, unless it's already known, and posts the source and the
+// target to it. The response status is returned, responses with a status other than 2xx are considered failures.
+func (w webmentionService) send(delivery *repository.WebmentionDelivery) (int, error) {
+	if delivery.Endpoint == "" {
+		endpoint, status, err := w.discoverEndpoint(delivery.Target)
+		if err != nil {
+			return status, err
+		}
+		delivery.Endpoint = endpoint
+	}
+
+	form := url.Values{"source": {delivery.Source}, "target": {delivery.Target}}
+	res, err := w.client.PostForm(delivery.Endpoint, form)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected response status %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+// discoverEndpoint retrieves a target and returns the absolute URL of its Webmention endpoint.
+func (w webmentionService) discoverEndpoint(target string) (string, int, error) {
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Accept", "text/html")
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return "", res.StatusCode, fmt.Errorf("unexpected response status %d of the target", res.StatusCode)
+	}
+
+	// The endpoint is resolved against the final URL of the target, which may have been redirected
+	endpoint := webmention.DiscoverEndpoint(res.Request.URL, res.Header, io.LimitReader(res.Body, maxWebmentionDocumentSize))
+	if !isAbsoluteURL(endpoint) {
+		return "", res.StatusCode, errNoWebmentionEndpoint
+	}
+	return endpoint, res.StatusCode, nil
+}
+
+// postHandle returns the URL handle of the post with the given URL if it's a post of this blog.
+func (w webmentionService) postHandle(target string) (string, bool) {
+	prefix := w.site.BaseURL + site.PostPath("")
+	if !strings.HasPrefix(target, prefix) {
+		return "", false
+	}
+
+	escaped, _, _ := strings.Cut(strings.TrimPrefix(target, prefix), "#")
+	urlHandle, err := url.PathUnescape(escaped)
+	if err != nil || urlHandle == "" || strings.ContainsAny(escaped, "/?") {
+		return "", false
+	}
+	return urlHandle, true
+}
+
+// isOwnURL checks whether the URL points to this blog, which doesn't need to be notified about its own links.
+func (w webmentionService) isOwnURL(value string) bool {
+	return value == w.site.BaseURL || strings.HasPrefix(value, w.site.BaseURL+"/")
+}
+
+// mapWebmention maps a Webmention model to a webmention data object.
+func mapWebmention(m *repository.Webmention) types.Webmention {
+	return types.Webmention{
+		ID:               m.ID,
+		Post:             m.Post.URLHandle,
+		Source:           m.Source,
+		Target:           m.Target,
+		Type:             m.Type,
+		Status:           m.Status,
+		AuthorName:       m.AuthorName,
+		AuthorURL:        m.AuthorURL,
+		Title:            m.Title,
+		Content:          m.Content,
+		CreationTime:     m.CreatedAt,
+		VerificationTime: m.VerifiedAt,
+	}
+}
+
+// mapWebmentions maps a slice of Webmention models of the post with the given URL handle to a slice of webmention data objects
+func mapWebmentions(m []repository.Webmention, urlHandle string) []types.Webmention {
+	mentions := make([]types.Webmention, 0, len(m))
+	for _, mention := range m {
+		result := mapWebmention(&mention)
+		result.Post = urlHandle
+		mentions = append(mentions, result)
+	}
+	return mentions
+}
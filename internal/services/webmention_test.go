@@ -0,0 +1,389 @@
+package services_test
+
+import (
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/events"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/types"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// webmentionTarget is the URL of the post mentioned in the tests.
+const webmentionTarget = "https://blog.example.com/posts/hello"
+
+// webmentionTestContext contains objects relevant for testing the WebmentionService.
+type webmentionTestContext struct {
+	mockPostRepository       *mocks.MockPostRepository
+	mockWebmentionRepository *mocks.MockWebmentionRepository
+	handlers                 map[string]events.Handler
+	sut                      services.WebmentionService
+}
+
+// createWebmentionServiceContext creates the context for testing the WebmentionService and reduces code duplication.
+// The remote sites of the tests are served locally, so requests to private addresses are allowed.
+// The settings are read from the environment, so the tests can't run in parallel.
+func createWebmentionServiceContext(t *testing.T, siteURL string) *webmentionTestContext {
+	t.Helper()
+	t.Setenv("ALLOW_PRIVATE_ADDRESSES", "true")
+
+	return createPublicWebmentionServiceContext(t, siteURL)
+}
+
+// createPublicWebmentionServiceContext creates the context for testing the WebmentionService with the default settings,
+// which only allow requests to public addresses.
+func createPublicWebmentionServiceContext(t *testing.T, siteURL string) *webmentionTestContext {
+	t.Helper()
+	t.Setenv("SITE_URL", siteURL)
+
+	mockCtrl := gomock.NewController(t)
+	mockPostRepository := mocks.NewMockPostRepository(mockCtrl)
+	mockWebmentionRepository := mocks.NewMockWebmentionRepository(mockCtrl)
+	mockEventBus := mocks.NewMockBus(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, mockPostRepository, nil, nil, nil, nil, nil, mockWebmentionRepository, nil, nil, mockEventBus, nil)
+
+	handlers := map[string]events.Handler{}
+	mockEventBus.EXPECT().Subscribe(gomock.Any(), gomock.Any()).Times(3).Do(func(event string, handler events.Handler) {
+		handlers[event] = handler
+	})
+	sut := services.CreateWebmentionService(cont)
+
+	return &webmentionTestContext{mockPostRepository, mockWebmentionRepository, handlers, sut}
+}
+
+// createWebmentionPost creates a post mentioned in the tests, written by testAuthor.
+func createWebmentionPost(visibility string) *repository.Post {
+	return &repository.Post{ID: 1, URLHandle: "hello", Visibility: visibility, Author: repository.User{UserName: "testAuthor"}}
+}
+
+// createRemoteSite starts a local stand-in of a remote site, which mentions the test post and accepts webmentions.
+// The forms posted to the Webmention endpoint of the site are recorded.
+func createRemoteSite(t *testing.T) (*httptest.Server, chan url.Values) {
+	t.Helper()
+
+	received := make(chan url.Values, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/reply":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = fmt.Fprintf(w, `<article class="h-entry"><a class="p-author h-card" href="/">Alice</a>
+<a class="u-in-reply-to" href="%s">Hello</a><p class="e-content">Nice post!</p></article>`, webmentionTarget)
+		case "/unrelated":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = fmt.Fprint(w, `<p><a href="https://blog.example.com/posts/other">Other</a></p>`)
+		case "/gone":
+			w.WriteHeader(http.StatusGone)
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/article":
+			w.Header().Set("Link", `</endpoint>; rel="webmention"`)
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = fmt.Fprint(w, `<p>Article</p>`)
+		case "/static":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = fmt.Fprint(w, `<p>No endpoint</p>`)
+		case "/endpoint":
+			_ = r.ParseForm()
+			received <- r.PostForm
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, received
+}
+
+// TestWebmentionService_ReceiveWebmention tests queueing a received mention of a public post for verification.
+func TestWebmentionService_ReceiveWebmention(t *testing.T) {
+	c := createWebmentionServiceContext(t, "https://blog.example.com")
+
+	source := "https://remote.example.com/reply"
+
+	c.mockPostRepository.EXPECT().GetPost("hello").Return(createWebmentionPost(types.VisibilityPublic), nil)
+	c.mockWebmentionRepository.EXPECT().AddWebmention(gomock.Any()).DoAndReturn(func(mention *repository.Webmention) error {
+		assert.Equal(t, uint(1), mention.PostID, "incorrect post")
+		assert.Equal(t, source, mention.Source, "incorrect source")
+		assert.Equal(t, webmentionTarget, mention.Target, "incorrect target")
+		assert.Equal(t, types.WebmentionPending, mention.Status, "the mention should wait for verification")
+		assert.True(t, mention.Queued, "the mention should be queued for verification")
+		return nil
+	})
+
+	err := c.sut.ReceiveWebmention(source, webmentionTarget, "192.0.2.1")
+
+	assert.Nil(t, err, "should complete without error")
+}
+
+// TestWebmentionService_ReceiveWebmention_Invalid tests rejecting mentions which don't target a public post of the blog.
+func TestWebmentionService_ReceiveWebmention_Invalid(t *testing.T) {
+	c := createWebmentionServiceContext(t, "https://blog.example.com")
+
+	c.mockPostRepository.EXPECT().GetPost("missing").Return(nil, errortypes.PostNotFoundError{Post: types.Post{URLHandle: "missing"}})
+	c.mockPostRepository.EXPECT().GetPost("private").Return(createWebmentionPost(types.VisibilityPrivate), nil)
+
+	cases := map[string][2]string{
+		"relative source":  {"/reply", webmentionTarget},
+		"same URLs":        {webmentionTarget, webmentionTarget},
+		"other site":       {"https://remote.example.com/reply", "https://other.example.com/posts/hello"},
+		"not a post":       {"https://remote.example.com/reply", "https://blog.example.com/authors/testAuthor"},
+		"nested path":      {"https://remote.example.com/reply", "https://blog.example.com/posts/hello/meta"},
+		"missing post":     {"https://remote.example.com/reply", "https://blog.example.com/posts/missing"},
+		"private post":     {"https://remote.example.com/reply", "https://blog.example.com/posts/private"},
+		"too long a value": {"https://remote.example.com/" + strings.Repeat("a", 255), webmentionTarget},
+	}
+
+	for name, urls := range cases {
+		err := c.sut.ReceiveWebmention(urls[0], urls[1], "192.0.2.1")
+
+		assert.IsType(t, errortypes.InvalidWebmentionError{}, err, "%s should be rejected", name)
+	}
+}
+
+// TestWebmentionService_ReceiveWebmention_Disabled tests receiving a mention while the site URL isn't set.
+func TestWebmentionService_ReceiveWebmention_Disabled(t *testing.T) {
+	c := createWebmentionServiceContext(t, "")
+
+	err := c.sut.ReceiveWebmention("https://remote.example.com/reply", webmentionTarget, "192.0.2.1")
+
+	assert.Equal(t, errortypes.WebmentionDisabledError{}, err, "incorrect error type")
+}
+
+// TestWebmentionService_ReceiveWebmention_Throttled tests throttling the senders of too many mentions by their address.
+func TestWebmentionService_ReceiveWebmention_Throttled(t *testing.T) {
+	t.Setenv("WEBMENTION_RATE_LIMIT", "1")
+	c := createWebmentionServiceContext(t, "https://blog.example.com")
+
+	source := "https://remote.example.com/reply"
+
+	c.mockPostRepository.EXPECT().GetPost("hello").Times(2).Return(createWebmentionPost(types.VisibilityPublic), nil)
+	c.mockWebmentionRepository.EXPECT().AddWebmention(gomock.Any()).Times(2).Return(nil)
+
+	assert.Nil(t, c.sut.ReceiveWebmention(source, webmentionTarget, "192.0.2.1"), "first mention should be accepted")
+	assert.Nil(t, c.sut.ReceiveWebmention(source, webmentionTarget, "192.0.2.2"), "other senders shouldn't be throttled")
+
+	err := c.sut.ReceiveWebmention(source, webmentionTarget, "192.0.2.1")
+
+	assert.IsType(t, errortypes.WebmentionRateLimitError{}, err, "sender should be throttled by their address")
+}
+
+// TestWebmentionService_VerifyWebmentions tests verifying the sources of the queued mentions on the remote site.
+func TestWebmentionService_VerifyWebmentions(t *testing.T) {
+	c := createWebmentionServiceContext(t, "https://blog.example.com")
+	remote, _ := createRemoteSite(t)
+
+	mentions := []repository.Webmention{
+		{ID: 1, Source: remote.URL + "/reply", Target: webmentionTarget, Status: types.WebmentionPending, Queued: true},
+		{ID: 2, Source: remote.URL + "/unrelated", Target: webmentionTarget, Status: types.WebmentionPending, Queued: true},
+		{ID: 3, Source: remote.URL + "/gone", Target: webmentionTarget, Status: types.WebmentionApproved, Queued: true},
+		{ID: 4, Source: remote.URL + "/reply", Target: webmentionTarget, Status: types.WebmentionApproved, Queued: true},
+	}
+	updated := map[uint]repository.Webmention{}
+
+	c.mockWebmentionRepository.EXPECT().ClaimDueWebmentions(gomock.Any(), gomock.Any(), gomock.Any()).Return(mentions, nil)
+	c.mockWebmentionRepository.EXPECT().UpdateWebmention(gomock.Any()).Times(4).DoAndReturn(func(mention *repository.Webmention) error {
+		updated[mention.ID] = *mention
+		return nil
+	})
+
+	n, err := c.sut.VerifyWebmentions()
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 4, n, "incorrect number of verified mentions")
+
+	reply := updated[1]
+	assert.Equal(t, types.WebmentionVerified, reply.Status, "the mention should wait for moderation")
+	assert.Equal(t, types.ReactionReply, reply.Type, "incorrect reaction type")
+	assert.Equal(t, "Alice", reply.AuthorName, "incorrect author")
+	assert.Equal(t, remote.URL+"/", reply.AuthorURL, "incorrect author URL")
+	assert.Equal(t, "Nice post!", reply.Content, "incorrect content")
+	assert.False(t, reply.Queued, "the mention should be dequeued")
+	assert.NotNil(t, reply.VerifiedAt, "the verification time should be recorded")
+
+	assert.Equal(t, types.WebmentionInvalid, updated[2].Status, "sources without a link to the target should be invalid")
+	assert.Equal(t, types.WebmentionInvalid, updated[3].Status, "gone sources should be invalid")
+	assert.Equal(t, types.WebmentionApproved, updated[4].Status, "mentions verified again should keep their moderation state")
+}
+
+// TestWebmentionService_VerifyWebmentions_Retry tests retrying the verification of a source which can't be fetched.
+func TestWebmentionService_VerifyWebmentions_Retry(t *testing.T) {
+	c := createWebmentionServiceContext(t, "https://blog.example.com")
+	remote, _ := createRemoteSite(t)
+
+	mention := repository.Webmention{ID: 1, Source: remote.URL + "/broken", Target: webmentionTarget, Status: types.WebmentionPending, Queued: true}
+
+	c.mockWebmentionRepository.EXPECT().ClaimDueWebmentions(gomock.Any(), gomock.Any(), gomock.Any()).Return([]repository.Webmention{mention}, nil)
+	c.mockWebmentionRepository.EXPECT().UpdateWebmention(gomock.Any()).DoAndReturn(func(m *repository.Webmention) error {
+		assert.Equal(t, types.WebmentionPending, m.Status, "the mention should still wait for verification")
+		assert.True(t, m.Queued, "the mention should stay queued")
+		assert.Equal(t, 1, m.Attempts, "the attempt should be recorded")
+		assert.True(t, m.NextAttemptAt.After(time.Now()), "the next attempt should be scheduled")
+		assert.Equal(t, "unexpected response status 500", m.Error, "the error should be recorded")
+		return nil
+	})
+
+	_, err := c.sut.VerifyWebmentions()
+
+	assert.Nil(t, err, "should complete without error")
+}
+
+// TestWebmentionService_VerifyWebmentions_Private_Address tests refusing to fetch sources served from private addresses,
+// given directly or by a host name resolving to one.
+func TestWebmentionService_VerifyWebmentions_Private_Address(t *testing.T) {
+	c := createPublicWebmentionServiceContext(t, "https://blog.example.com")
+	remote, _ := createRemoteSite(t)
+
+	mentions := []repository.Webmention{
+		{ID: 1, Source: remote.URL + "/reply", Target: webmentionTarget, Status: types.WebmentionPending, Queued: true},
+		{ID: 2, Source: strings.Replace(remote.URL, "127.0.0.1", "localhost", 1) + "/reply", Target: webmentionTarget, Status: types.WebmentionPending, Queued: true},
+	}
+
+	c.mockWebmentionRepository.EXPECT().ClaimDueWebmentions(gomock.Any(), gomock.Any(), gomock.Any()).Return(mentions, nil)
+	c.mockWebmentionRepository.EXPECT().UpdateWebmention(gomock.Any()).Times(2).DoAndReturn(func(m *repository.Webmention) error {
+		assert.Equal(t, types.WebmentionPending, m.Status, "the mention shouldn't be verified")
+		assert.Contains(t, m.Error, "the address isn't publicly routable", "the refused address should be recorded")
+		return nil
+	})
+
+	_, err := c.sut.VerifyWebmentions()
+
+	assert.Nil(t, err, "should complete without error")
+}
+
+// TestWebmentionService_SendWebmentions tests sending mentions to the endpoint discovered on the remote site.
+func TestWebmentionService_SendWebmentions(t *testing.T) {
+	c := createWebmentionServiceContext(t, "https://blog.example.com")
+	remote, received := createRemoteSite(t)
+
+	deliveries := []repository.WebmentionDelivery{
+		{ID: 1, Source: webmentionTarget, Target: remote.URL + "/article", DeliveryState: repository.DeliveryState{Status: types.DeliveryPending}},
+		{ID: 2, Source: webmentionTarget, Target: remote.URL + "/static", DeliveryState: repository.DeliveryState{Status: types.DeliveryPending}},
+	}
+	updated := map[uint]repository.WebmentionDelivery{}
+
+	c.mockWebmentionRepository.EXPECT().ClaimDueDeliveries(gomock.Any(), gomock.Any(), gomock.Any()).Return(deliveries, nil)
+	c.mockWebmentionRepository.EXPECT().UpdateDelivery(gomock.Any()).Times(2).DoAndReturn(func(delivery *repository.WebmentionDelivery) error {
+		updated[delivery.ID] = *delivery
+		return nil
+	})
+
+	n, err := c.sut.SendWebmentions()
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 2, n, "incorrect number of deliveries")
+
+	assert.Equal(t, types.DeliverySucceeded, updated[1].Status, "delivery should succeed")
+	assert.Equal(t, remote.URL+"/endpoint", updated[1].Endpoint, "the discovered endpoint should be recorded")
+	assert.Equal(t, url.Values{"source": {webmentionTarget}, "target": {remote.URL + "/article"}}, <-received, "incorrect form")
+
+	assert.Equal(t, types.DeliveryFailed, updated[2].Status, "targets without endpoint shouldn't be retried")
+	assert.Equal(t, 1, updated[2].Attempts, "incorrect number of attempts")
+}
+
+// TestWebmentionService_QueueWebmentions tests queueing the mentions of the pages linked from an updated post.
+func TestWebmentionService_QueueWebmentions(t *testing.T) {
+	c := createWebmentionServiceContext(t, "https://blog.example.com")
+
+	post := types.Post{
+		URLHandle:  "hello",
+		Visibility: types.VisibilityPublic,
+		Body:       `<p><a href="https://a.example.com/1">A</a> <a href="https://blog.example.com/posts/other">Other</a> <a href="https://a.example.com/1">A</a></p>`,
+	}
+
+	c.mockWebmentionRepository.EXPECT().GetDeliveryTargets(webmentionTarget).Return([]string{"https://a.example.com/1", "https://b.example.com/removed"}, nil)
+	c.mockWebmentionRepository.EXPECT().QueueDeliveries(webmentionTarget, []string{"https://a.example.com/1", "https://b.example.com/removed"}, gomock.Any()).Return(nil)
+
+	err := c.handlers[types.EventPostUpdated](types.PostUpdatedEvent{Post: post})
+
+	assert.Nil(t, err, "should complete without error")
+}
+
+// TestWebmentionService_QueueWebmentions_Deleted tests notifying the pages mentioned by a deleted post.
+func TestWebmentionService_QueueWebmentions_Deleted(t *testing.T) {
+	c := createWebmentionServiceContext(t, "https://blog.example.com")
+
+	post := types.Post{URLHandle: "hello", Body: `<a href="https://a.example.com/1">A</a>`}
+
+	c.mockWebmentionRepository.EXPECT().GetDeliveryTargets(webmentionTarget).Return([]string{"https://b.example.com/2"}, nil)
+	c.mockWebmentionRepository.EXPECT().QueueDeliveries(webmentionTarget, []string{"https://b.example.com/2"}, gomock.Any()).Return(nil)
+
+	assert.Nil(t, c.handlers[types.EventPostDeleted](types.PostDeletedEvent{Post: post}), "should complete without error")
+	assert.Nil(t, c.handlers[types.EventPostCreated](types.PostCreatedEvent{Post: types.Post{Visibility: types.VisibilityPrivate}}), "private posts shouldn't send webmentions")
+}
+
+// TestWebmentionService_ModerateWebmention tests approving a verified mention by the author of the post.
+func TestWebmentionService_ModerateWebmention(t *testing.T) {
+	c := createWebmentionServiceContext(t, "https://blog.example.com")
+
+	mention := &repository.Webmention{ID: 1, Post: *createWebmentionPost(types.VisibilityPublic), Status: types.WebmentionVerified}
+
+	c.mockWebmentionRepository.EXPECT().GetWebmention(uint(1)).Return(mention, nil)
+	c.mockWebmentionRepository.EXPECT().UpdateWebmention(mention).Return(nil)
+
+	result, err := c.sut.ModerateWebmention(1, types.WebmentionApproved, "testAuthor")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, types.WebmentionApproved, result.Status, "the mention should be approved")
+	assert.Equal(t, "hello", result.Post, "incorrect post")
+}
+
+// TestWebmentionService_ModerateWebmention_Forbidden tests moderating a mention of another author's post.
+func TestWebmentionService_ModerateWebmention_Forbidden(t *testing.T) {
+	c := createWebmentionServiceContext(t, "https://blog.example.com")
+
+	mention := &repository.Webmention{ID: 1, Post: *createWebmentionPost(types.VisibilityPublic), Status: types.WebmentionVerified}
+
+	c.mockWebmentionRepository.EXPECT().GetWebmention(uint(1)).Return(mention, nil)
+
+	_, err := c.sut.ModerateWebmention(1, types.WebmentionApproved, "otherAuthor")
+
+	assert.IsType(t, errortypes.PostEditForbiddenError{}, err, "incorrect error type")
+}
+
+// TestWebmentionService_ModerateWebmention_Unverified tests moderating a mention which isn't verified yet.
+func TestWebmentionService_ModerateWebmention_Unverified(t *testing.T) {
+	c := createWebmentionServiceContext(t, "https://blog.example.com")
+
+	mention := &repository.Webmention{ID: 1, Post: *createWebmentionPost(types.VisibilityPublic), Status: types.WebmentionPending}
+
+	c.mockWebmentionRepository.EXPECT().GetWebmention(uint(1)).Return(mention, nil)
+
+	_, err := c.sut.ModerateWebmention(1, types.WebmentionApproved, "testAuthor")
+	assert.IsType(t, errortypes.InvalidWebmentionError{}, err, "unverified mentions shouldn't be moderated")
+
+	_, err = c.sut.ModerateWebmention(1, types.WebmentionVerified, "testAuthor")
+	assert.IsType(t, errortypes.InvalidWebmentionError{}, err, "mentions should only be approved or rejected")
+}
+
+// TestWebmentionService_GetWebmentions tests listing the mentions of a post for readers and for its author.
+func TestWebmentionService_GetWebmentions(t *testing.T) {
+	c := createWebmentionServiceContext(t, "https://blog.example.com")
+
+	post := createWebmentionPost(types.VisibilityPublic)
+	mentions := []repository.Webmention{{ID: 1, Source: "https://remote.example.com/reply", Status: types.WebmentionVerified}}
+
+	c.mockPostRepository.EXPECT().GetPost("hello").Return(post, nil).Times(2)
+	c.mockWebmentionRepository.EXPECT().GetWebmentions(uint(1), types.WebmentionApproved).Return([]repository.Webmention{}, nil)
+	c.mockWebmentionRepository.EXPECT().GetWebmentions(uint(1), types.WebmentionVerified).Return(mentions, nil)
+
+	approved, err := c.sut.GetWebmentions("hello", types.WebmentionVerified, "")
+	assert.Nil(t, err, "should complete without error")
+	assert.Empty(t, approved, "readers should only see approved mentions")
+
+	verified, err := c.sut.GetWebmentions("hello", types.WebmentionVerified, "testAuthor")
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "hello", verified[0].Post, "incorrect post")
+	assert.Equal(t, types.WebmentionVerified, verified[0].Status, "the author should see the mentions waiting for moderation")
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"fmt"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/i18n"
+	"github.com/wlchs/blog/internal/types"
+	"github.com/wlchs/blog/internal/wxr"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// WordPressService interface. Defines the migration of WordPress blogs.
+type WordPressService interface {
+	ImportWXR(r io.Reader) (types.ImportReport, error)
+}
+
+// wordPressService is the concrete implementation of the WordPressService interface.
+type wordPressService struct {
+	cont        container.Container
+	postService PostService
+}
+
+// CreateWordPressService instantiates the wordPressService using the application container and the post service.
+func CreateWordPressService(cont container.Container, postService PostService) WordPressService {
+	return &wordPressService{cont, postService}
+}
+
+// ImportWXR imports the posts of a WordPress eXtended RSS export file.
+// The authors of the export are mapped to users by their login names, missing users are created as disabled accounts.
+// Posts keep their publication date and their post_name as URL handle. Drafts, pending and scheduled posts are imported as private posts.
+// Posts whose URL handle is already taken are reported as conflicts and left untouched, pages, attachments and other items are skipped.
+func (w wordPressService) ImportWXR(r io.Reader) (types.ImportReport, error) {
+	log := w.cont.GetLogger()
+	report := types.ImportReport{
+		Created:      []string{},
+		Updated:      []string{},
+		Skipped:      []types.ImportIssue{},
+		Conflicts:    []types.ImportIssue{},
+		CreatedUsers: []string{},
+	}
+
+	export, err := wxr.Parse(r)
+	if err != nil {
+		log.Errorf("failed to parse WordPress export: %v", err)
+		return report, err
+	}
+
+	language, ok := i18n.NormalizeTag(export.Language)
+	if !ok {
+		language = i18n.DefaultLanguage
+	}
+
+	// Errors of the authors are only reported once, their posts are skipped
+	authors := map[string]error{}
+	for _, author := range export.Authors {
+		login := strings.TrimSpace(author.Login)
+		if _, found := authors[login]; found || login == "" {
+			continue
+		}
+		authors[login] = w.ensureUser(login, &report)
+		if err := authors[login]; err != nil {
+			report.Skipped = append(report.Skipped, types.ImportIssue{Item: "author " + login, Reason: err.Error()})
+		}
+	}
+
+	for _, item := range export.Items {
+		name := itemName(item)
+
+		post, err := mapWordPressItem(item, language)
+		if err != nil {
+			log.Debugf("skipping WordPress item %s: %v", name, err)
+			report.Skipped = append(report.Skipped, types.ImportIssue{Item: name, Reason: err.Error()})
+			continue
+		}
+
+		authorErr, found := authors[post.Author]
+		if !found {
+			authorErr = w.ensureUser(post.Author, &report)
+			authors[post.Author] = authorErr
+		}
+		if authorErr != nil {
+			report.Skipped = append(report.Skipped, types.ImportIssue{Item: name, Reason: fmt.Sprintf("author %s can't be imported", post.Author)})
+			continue
+		}
+
+		switch err := w.importWordPressPost(&post); err.(type) {
+		case nil:
+			report.Created = append(report.Created, post.URLHandle)
+		case errortypes.DuplicateElementError:
+			log.Debugf("WordPress item %s conflicts with an existing post", name)
+			report.Conflicts = append(report.Conflicts, types.ImportIssue{Item: name, Reason: fmt.Sprintf("a post with URL handle %q already exists", post.URLHandle)})
+		default:
+			log.Warnf("failed to import WordPress item %s: %v", name, err)
+			report.Skipped = append(report.Skipped, types.ImportIssue{Item: name, Reason: err.Error()})
+		}
+	}
+
+	log.Infof("imported WordPress export %q: %d posts created, %d users created, %d conflicts, %d skipped",
+		export.Title, len(report.Created), len(report.CreatedUsers), len(report.Conflicts), len(report.Skipped))
+	return report, nil
+}
+
+// ensureUser creates a disabled account for the login name unless a user with the same name exists.
+func (w wordPressService) ensureUser(login string, report *types.ImportReport) error {
+	log := w.cont.GetLogger()
+	userRepository := w.cont.GetUserRepository()
+
+	switch _, err := userRepository.GetUser(login); err.(type) {
+	case nil:
+		return nil
+	case errortypes.UserNotFoundError:
+	default:
+		return err
+	}
+
+	if _, err := userRepository.AddUser(&types.User{UserName: login, Disabled: true}, nil); err != nil {
+		log.Errorf("failed to create disabled user %s: %v", login, err)
+		return err
+	}
+
+	log.Infof("created disabled user %s", login)
+	report.CreatedUsers = append(report.CreatedUsers, login)
+	return nil
+}
+
+// importWordPressPost adds the post unless its URL handle is already taken by a post of any visibility.
+func (w wordPressService) importWordPressPost(post *types.Post) error {
+	postRepository := w.cont.GetPostRepository()
+
+	switch _, err := postRepository.GetPost(post.URLHandle); err.(type) {
+	case nil:
+		return errortypes.DuplicateElementError{Key: post.URLHandle}
+	case errortypes.PostNotFoundError:
+	default:
+		return err
+	}
+
+	_, err := w.postService.AddPost(post)
+	return err
+}
+
+// mapWordPressItem maps a post of the export to a post data object.
+// An error describing the reason is returned if the item can't be imported.
+func mapWordPressItem(item wxr.Item, language string) (types.Post, error) {
+	if item.Type != "post" {
+		return types.Post{}, fmt.Errorf("unsupported item type %q", item.Type)
+	}
+
+	var visibility string
+	switch item.Status {
+	case "publish":
+		visibility = types.VisibilityPublic
+		if item.Password != "" {
+			visibility = types.VisibilityPassword
+		}
+	case "private", "draft", "pending", "future":
+		visibility = types.VisibilityPrivate
+	default:
+		return types.Post{}, fmt.Errorf("unsupported status %q", item.Status)
+	}
+
+	if item.Name == "" {
+		return types.Post{}, fmt.Errorf("missing post_name")
+	}
+	// WordPress stores the percent-encoded form of non-ASCII post names
+	urlHandle, err := url.PathUnescape(item.Name)
+	if err != nil {
+		urlHandle = item.Name
+	}
+
+	if item.Creator == "" {
+		return types.Post{}, fmt.Errorf("missing author")
+	}
+
+	creationTime, ok := item.PublicationTime()
+	if !ok {
+		return types.Post{}, fmt.Errorf("invalid post_date %q", item.Date)
+	}
+
+	post := types.Post{
+		URLHandle:    urlHandle,
+		Title:        item.Title,
+		Author:       item.Creator,
+		Summary:      strings.TrimSpace(item.Excerpt),
+		Body:         item.Content,
+		CreationTime: creationTime,
+		Language:     language,
+		Visibility:   visibility,
+		Tags:         item.Tags,
+	}
+	if visibility == types.VisibilityPassword {
+		post.Password = item.Password
+	}
+
+	return post, nil
+}
+
+// itemName identifies an item of the export in the import report.
+func itemName(item wxr.Item) string {
+	if item.Name != "" {
+		return item.Name
+	}
+	return fmt.Sprintf("#%s %q", item.ID, item.Title)
+}
@@ -0,0 +1,175 @@
+package services_test
+
+import (
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/container"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/mocks"
+	"github.com/wlchs/blog/internal/repository"
+	"github.com/wlchs/blog/internal/services"
+	"github.com/wlchs/blog/internal/types"
+	"strings"
+	"testing"
+	"time"
+)
+
+// wordPressTestContext contains objects relevant for testing the WordPressService.
+type wordPressTestContext struct {
+	mockPostRepository *mocks.MockPostRepository
+	mockUserRepository *mocks.MockUserRepository
+	mockPostService    *mocks.MockPostService
+	sut                services.WordPressService
+}
+
+// createWordPressServiceContext creates the context for testing the WordPressService and reduces code duplication.
+func createWordPressServiceContext(t *testing.T) *wordPressTestContext {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	mockPostRepository := mocks.NewMockPostRepository(mockCtrl)
+	mockUserRepository := mocks.NewMockUserRepository(mockCtrl)
+	mockPostService := mocks.NewMockPostService(mockCtrl)
+	cont := container.CreateContainer(logger.CreateLogger(), nil, nil, nil, nil, nil, nil, mockPostRepository, nil, nil, nil, mockUserRepository, nil, nil, nil, nil, nil, nil)
+	sut := services.CreateWordPressService(cont, mockPostService)
+
+	return &wordPressTestContext{mockPostRepository, mockUserRepository, mockPostService, sut}
+}
+
+// createWXR creates a WordPress export with the given authors and items for testing purposes.
+func createWXR(authors []string, items ...string) *strings.Reader {
+	var b strings.Builder
+	b.WriteString(`<rss xmlns:excerpt="http://wordpress.org/export/1.2/excerpt/" xmlns:content="http://purl.org/rss/1.0/modules/content/"` +
+		` xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:wp="http://wordpress.org/export/1.2/"><channel><title>Test</title><language>de-DE</language>`)
+	for _, author := range authors {
+		fmt.Fprintf(&b, "<wp:author><wp:author_login>%s</wp:author_login></wp:author>", author)
+	}
+	for _, item := range items {
+		b.WriteString("<item>" + item + "</item>")
+	}
+	b.WriteString("</channel></rss>")
+	return strings.NewReader(b.String())
+}
+
+// createWXRItem creates an item of a WordPress export for testing purposes.
+func createWXRItem(name string, creator string, status string, postType string) string {
+	return fmt.Sprintf(`<title>Title of %s</title><dc:creator>%s</dc:creator><content:encoded><![CDATA[<p>%s</p>]]></content:encoded>`+
+		`<excerpt:encoded>Summary</excerpt:encoded><wp:post_id>1</wp:post_id><wp:post_date>2019-03-01 10:00:00</wp:post_date>`+
+		`<wp:post_date_gmt>2019-03-01 09:00:00</wp:post_date_gmt><wp:post_name>%s</wp:post_name><wp:status>%s</wp:status>`+
+		`<wp:post_type>%s</wp:post_type><category domain="post_tag" nicename="go">Go</category>`, name, creator, name, name, status, postType)
+}
+
+// TestWordPressService_ImportWXR tests importing the authors and posts of a WordPress export.
+func TestWordPressService_ImportWXR(t *testing.T) {
+	t.Parallel()
+	c := createWordPressServiceContext(t)
+
+	export := createWXR([]string{"jane", "admin"},
+		createWXRItem("hello-world", "jane", "publish", "post"),
+		createWXRItem("caf%C3%A9", "admin", "draft", "post"),
+	)
+
+	expectedPosts := []types.Post{
+		{
+			URLHandle:    "hello-world",
+			Title:        "Title of hello-world",
+			Author:       "jane",
+			Summary:      "Summary",
+			Body:         "<p>hello-world</p>",
+			CreationTime: time.Date(2019, 3, 1, 9, 0, 0, 0, time.UTC),
+			Language:     "de-DE",
+			Visibility:   types.VisibilityPublic,
+			Tags:         []string{"Go"},
+		},
+		{
+			URLHandle:    "café",
+			Title:        "Title of caf%C3%A9",
+			Author:       "admin",
+			Summary:      "Summary",
+			Body:         "<p>caf%C3%A9</p>",
+			CreationTime: time.Date(2019, 3, 1, 9, 0, 0, 0, time.UTC),
+			Language:     "de-DE",
+			Visibility:   types.VisibilityPrivate,
+			Tags:         []string{"Go"},
+		},
+	}
+
+	c.mockUserRepository.EXPECT().GetUser("jane").Return(nil, errortypes.UserNotFoundError{User: types.User{UserName: "jane"}})
+	c.mockUserRepository.EXPECT().AddUser(&types.User{UserName: "jane", Disabled: true}, nil).Return(&repository.User{UserName: "jane", Disabled: true}, nil)
+	c.mockUserRepository.EXPECT().GetUser("admin").Return(&repository.User{UserName: "admin"}, nil)
+	for i := range expectedPosts {
+		c.mockPostRepository.EXPECT().GetPost(expectedPosts[i].URLHandle).Return(nil, errortypes.PostNotFoundError{})
+		c.mockPostService.EXPECT().AddPost(&expectedPosts[i]).Return(expectedPosts[i], nil)
+	}
+
+	report, err := c.sut.ImportWXR(export)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, []string{"hello-world", "café"}, report.Created, "created posts don't match")
+	assert.Equal(t, []string{"jane"}, report.CreatedUsers, "only the missing author should be created")
+	assert.Empty(t, report.Conflicts, "there should be no conflicts")
+	assert.Empty(t, report.Skipped, "no item should be skipped")
+}
+
+// TestWordPressService_ImportWXR_Report tests reporting the skipped and conflicting items of a WordPress export.
+func TestWordPressService_ImportWXR_Report(t *testing.T) {
+	t.Parallel()
+	c := createWordPressServiceContext(t)
+
+	export := createWXR([]string{"jane"},
+		createWXRItem("existing", "jane", "publish", "post"),
+		createWXRItem("about", "jane", "publish", "page"),
+		createWXRItem("deleted", "jane", "trash", "post"),
+		createWXRItem("", "jane", "publish", "post"),
+		createWXRItem("orphan", "", "publish", "post"),
+		createWXRItem("failing", "john", "publish", "post"),
+	)
+
+	c.mockUserRepository.EXPECT().GetUser("jane").Return(&repository.User{UserName: "jane"}, nil)
+	c.mockUserRepository.EXPECT().GetUser("john").Return(nil, fmt.Errorf("unexpected error"))
+	c.mockPostRepository.EXPECT().GetPost("existing").Return(&repository.Post{URLHandle: "existing"}, nil)
+
+	report, err := c.sut.ImportWXR(export)
+
+	expectedConflicts := []types.ImportIssue{{Item: "existing", Reason: `a post with URL handle "existing" already exists`}}
+	expectedSkipped := []types.ImportIssue{
+		{Item: "about", Reason: `unsupported item type "page"`},
+		{Item: "deleted", Reason: `unsupported status "trash"`},
+		{Item: `#1 "Title of"`, Reason: "missing post_name"},
+		{Item: "orphan", Reason: "missing author"},
+		{Item: "failing", Reason: "author john can't be imported"},
+	}
+	assert.Nil(t, err, "should complete without error")
+	assert.Empty(t, report.Created, "no post should be created")
+	assert.Equal(t, expectedConflicts, report.Conflicts, "conflicts don't match")
+	assert.Equal(t, expectedSkipped, report.Skipped, "skipped items don't match")
+}
+
+// TestWordPressService_ImportWXR_Duplicate tests reporting posts conflicting with each other as conflicts.
+func TestWordPressService_ImportWXR_Duplicate(t *testing.T) {
+	t.Parallel()
+	c := createWordPressServiceContext(t)
+
+	export := createWXR(nil, createWXRItem("hello-world", "jane", "publish", "post"))
+
+	c.mockUserRepository.EXPECT().GetUser("jane").Return(&repository.User{UserName: "jane"}, nil)
+	c.mockPostRepository.EXPECT().GetPost("hello-world").Return(nil, errortypes.PostNotFoundError{})
+	c.mockPostService.EXPECT().AddPost(gomock.Any()).Return(types.Post{}, errortypes.DuplicateElementError{Key: "hello-world"})
+
+	report, err := c.sut.ImportWXR(export)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, 1, len(report.Conflicts), "post should be reported as a conflict")
+}
+
+// TestWordPressService_ImportWXR_Invalid tests importing a malformed export file.
+func TestWordPressService_ImportWXR_Invalid(t *testing.T) {
+	t.Parallel()
+	c := createWordPressServiceContext(t)
+
+	_, err := c.sut.ImportWXR(strings.NewReader("<rss><channel>"))
+
+	assert.NotNil(t, err, "should return an error")
+}
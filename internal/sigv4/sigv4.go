@@ -0,0 +1,192 @@
+package sigv4
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UnsignedPayload is used instead of the payload hash if the body of a request isn't signed.
+const UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+// algorithm is the identifier of the signing algorithm.
+const algorithm = "AWS4-HMAC-SHA256"
+
+// timeFormat is the format of the request timestamps.
+const timeFormat = "20060102T150405Z"
+
+// Signer signs HTTP requests with AWS Signature Version 4, as required by S3-compatible object storages.
+type Signer struct {
+	AccessKey string
+	SecretKey string
+	Region    string
+	Service   string
+}
+
+// HashPayload returns the hex encoded SHA-256 hash of a request payload.
+func HashPayload(payload []byte) string {
+	hash := sha256.Sum256(payload)
+	return hex.EncodeToString(hash[:])
+}
+
+// Sign adds the X-Amz-Date, X-Amz-Content-Sha256 and Authorization headers to the request.
+// Every header present at the time of signing is included in the signature.
+// The URL of the request must be encoded using EncodePath and EncodeQuery.
+func (s Signer) Sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format(timeFormat)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers, signedHeaders := canonicalHeaders(req)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		EncodePath(req.URL.Path),
+		EncodeQuery(req.URL.Query()),
+		headers,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := s.scope(now)
+	signature := s.signature(now, stringToSign(amzDate, scope, canonicalRequest))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s", algorithm, s.AccessKey, scope, signedHeaders, signature))
+}
+
+// Presign creates a URL granting temporary access to a resource without further credentials.
+// Only the host is signed, the payload of the request isn't.
+func (s Signer) Presign(method string, u *url.URL, expiry time.Duration, now time.Time) string {
+	amzDate := now.UTC().Format(timeFormat)
+	scope := s.scope(now)
+
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", algorithm)
+	query.Set("X-Amz-Credential", s.AccessKey+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiry.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		EncodePath(u.Path),
+		EncodeQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		UnsignedPayload,
+	}, "\n")
+
+	query.Set("X-Amz-Signature", s.signature(now, stringToSign(amzDate, scope, canonicalRequest)))
+
+	signed := *u
+	signed.RawPath = EncodePath(u.Path)
+	signed.RawQuery = EncodeQuery(query)
+	return signed.String()
+}
+
+// EncodePath encodes a URL path the way it is canonicalized for signing. The slashes separating the segments are kept.
+func EncodePath(path string) string {
+	if path == "" {
+		return "/"
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = encode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// EncodeQuery encodes query parameters sorted by their names, the way they are canonicalized for signing.
+func EncodeQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var params []string
+	for _, key := range keys {
+		values := append([]string{}, query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			params = append(params, encode(key)+"="+encode(value))
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// encode percent-encodes every character except the unreserved ones of RFC 3986.
+func encode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// canonicalHeaders returns the canonical form and the names of the signed headers of a request.
+func canonicalHeaders(req *http.Request) (string, string) {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	values := map[string]string{"host": host}
+	for name, v := range req.Header {
+		trimmed := make([]string, 0, len(v))
+		for _, value := range v {
+			trimmed = append(trimmed, strings.Join(strings.Fields(value), " "))
+		}
+		values[strings.ToLower(name)] = strings.Join(trimmed, ",")
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name + ":" + values[name] + "\n")
+	}
+	return b.String(), strings.Join(names, ";")
+}
+
+// scope returns the credential scope of the requests signed at the given time.
+func (s Signer) scope(now time.Time) string {
+	return fmt.Sprintf("%s/%s/%s/aws4_request", now.UTC().Format("20060102"), s.Region, s.Service)
+}
+
+// stringToSign combines the request timestamp, the credential scope and the hash of the canonical request.
+func stringToSign(amzDate string, scope string, canonicalRequest string) string {
+	return strings.Join([]string{algorithm, amzDate, scope, HashPayload([]byte(canonicalRequest))}, "\n")
+}
+
+// signature derives the signing key for the day and signs the string.
+func (s Signer) signature(now time.Time, stringToSign string) string {
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), now.UTC().Format("20060102"))
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, s.Service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// hmacSHA256 calculates the HMAC-SHA256 of the data using the given key.
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
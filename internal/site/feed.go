@@ -0,0 +1,198 @@
+package site
+
+import (
+	"encoding/xml"
+	"github.com/wlchs/blog/internal/types"
+	"io"
+	"time"
+)
+
+// Feed contains the latest posts of the site.
+type Feed struct {
+	Site  Config
+	Posts []types.Post
+}
+
+// rss is the document structure of an RSS 2.0 feed.
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DCNS    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+// rssChannel is the channel of an RSS feed.
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Self          atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+// rssItem is a post of an RSS feed.
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Creator     string   `xml:"dc:creator"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description,omitempty"`
+}
+
+// rssGUID is the unique identifier of an RSS item.
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// atomFeed is the document structure of an Atom feed.
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+// atomLink is a link of an Atom feed or entry.
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+// atomEntry is a post of an Atom feed.
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     atomAuthor     `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+	Summary    string         `xml:"summary,omitempty"`
+}
+
+// atomAuthor is the author of an Atom entry.
+type atomAuthor struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri"`
+}
+
+// atomCategory is a tag of an Atom entry.
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// Updated returns the time of the latest change of the posts of the feed.
+func (f Feed) Updated() time.Time {
+	var updated time.Time
+	for _, post := range f.Posts {
+		if t := LastModified(post); t.After(updated) {
+			updated = t
+		}
+	}
+	return updated
+}
+
+// WriteRSS writes the feed in RSS 2.0 format.
+func WriteRSS(w io.Writer, feed Feed) error {
+	page := Page{Site: feed.Site}
+	document := rss{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		DCNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:       feed.Site.Title,
+			Link:        page.URL("/"),
+			Description: feed.Site.Description,
+			Language:    feed.Site.Language,
+			Self:        atomLink{Href: page.URL(RSSPath), Rel: "self", Type: "application/rss+xml"},
+			Items:       make([]rssItem, 0, len(feed.Posts)),
+		},
+	}
+
+	if updated := feed.Updated(); !updated.IsZero() {
+		document.Channel.LastBuildDate = updated.UTC().Format(time.RFC1123Z)
+	}
+
+	for _, post := range feed.Posts {
+		link := page.URL(PostPath(post.URLHandle))
+		document.Channel.Items = append(document.Channel.Items, rssItem{
+			Title:       post.Title,
+			Link:        link,
+			GUID:        rssGUID{IsPermaLink: true, Value: link},
+			PubDate:     post.CreationTime.UTC().Format(time.RFC1123Z),
+			Creator:     post.Author,
+			Categories:  post.Tags,
+			Description: post.Summary,
+		})
+	}
+
+	return writeXML(w, document)
+}
+
+// WriteAtom writes the feed in Atom format.
+func WriteAtom(w io.Writer, feed Feed) error {
+	page := Page{Site: feed.Site}
+	document := atomFeed{
+		ID:       page.URL("/"),
+		Title:    feed.Site.Title,
+		Subtitle: feed.Site.Description,
+		Updated:  feed.Updated().UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: page.URL("/"), Rel: "alternate", Type: "text/html"},
+			{Href: page.URL(AtomPath), Rel: "self", Type: "application/atom+xml"},
+		},
+		Entries: make([]atomEntry, 0, len(feed.Posts)),
+	}
+
+	for _, post := range feed.Posts {
+		link := page.URL(PostPath(post.URLHandle))
+		entry := atomEntry{
+			ID:        link,
+			Title:     post.Title,
+			Link:      atomLink{Href: link, Rel: "alternate", Type: "text/html"},
+			Published: post.CreationTime.UTC().Format(time.RFC3339),
+			Updated:   LastModified(post).UTC().Format(time.RFC3339),
+			Author:    atomAuthor{Name: post.Author, URI: page.URL(AuthorPath(post.Author))},
+			Summary:   post.Summary,
+		}
+		for _, tag := range post.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		document.Entries = append(document.Entries, entry)
+	}
+
+	return writeXML(w, document)
+}
+
+// LastModified returns the time of the latest change of a post, falling back to its creation time.
+func LastModified(post types.Post) time.Time {
+	if post.UpdateTime.After(post.CreationTime) {
+		return post.UpdateTime
+	}
+	return post.CreationTime
+}
+
+// writeXML writes an indented XML document with its declaration.
+func writeXML(w io.Writer, document interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
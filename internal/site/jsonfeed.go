@@ -0,0 +1,101 @@
+package site
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/wlchs/blog/internal/markdown"
+	"io"
+	"time"
+)
+
+// jsonFeedVersion identifies the supported version of the JSON Feed specification.
+const jsonFeedVersion = "https://jsonfeed.org/version/1.1"
+
+// JSONFeedPage is a page of the JSON Feed. The posts of the page contain their full body.
+type JSONFeedPage struct {
+	Feed
+	Number int
+	Total  int
+}
+
+// jsonFeed is the document structure of a JSON Feed.
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description,omitempty"`
+	Language    string         `json:"language,omitempty"`
+	NextURL     string         `json:"next_url,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+// jsonFeedItem is a post of a JSON Feed.
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url"`
+	Title         string           `json:"title"`
+	ContentHTML   string           `json:"content_html"`
+	ContentText   string           `json:"content_text"`
+	Summary       string           `json:"summary,omitempty"`
+	Image         string           `json:"image,omitempty"`
+	DatePublished string           `json:"date_published"`
+	DateModified  string           `json:"date_modified"`
+	Authors       []jsonFeedAuthor `json:"authors"`
+	Tags          []string         `json:"tags,omitempty"`
+	Language      string           `json:"language,omitempty"`
+}
+
+// jsonFeedAuthor is an author of a JSON Feed item.
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// JSONFeedPagePath returns the path of the given page of the JSON Feed. The first page is the feed itself.
+func JSONFeedPagePath(number int) string {
+	if number <= 1 {
+		return JSONFeedPath
+	}
+	return fmt.Sprintf("/feed/%d.json", number)
+}
+
+// WriteJSONFeed writes a page of the feed in JSON Feed 1.1 format. Every page except the last one links the next page.
+func WriteJSONFeed(w io.Writer, page JSONFeedPage) error {
+	base := Page{Site: page.Site}
+	document := jsonFeed{
+		Version:     jsonFeedVersion,
+		Title:       page.Site.Title,
+		HomePageURL: base.URL("/"),
+		FeedURL:     base.URL(JSONFeedPagePath(page.Number)),
+		Description: page.Site.Description,
+		Language:    page.Site.Language,
+		Items:       make([]jsonFeedItem, 0, len(page.Posts)),
+	}
+	if page.Number < page.Total {
+		document.NextURL = base.URL(JSONFeedPagePath(page.Number + 1))
+	}
+
+	for _, post := range page.Posts {
+		link := base.URL(PostPath(post.URLHandle))
+		document.Items = append(document.Items, jsonFeedItem{
+			ID:            link,
+			URL:           link,
+			Title:         post.Title,
+			ContentHTML:   markdown.Render(post.Body),
+			ContentText:   markdown.PlainText(post.Body),
+			Summary:       post.Summary,
+			Image:         PostPage{Page: base, Post: post}.ImageURL(),
+			DatePublished: post.CreationTime.UTC().Format(time.RFC3339),
+			DateModified:  LastModified(post).UTC().Format(time.RFC3339),
+			Authors:       []jsonFeedAuthor{{Name: post.Author, URL: base.URL(AuthorPath(post.Author))}},
+			Tags:          post.Tags,
+			Language:      post.Language,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
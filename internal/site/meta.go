@@ -0,0 +1,78 @@
+package site
+
+import (
+	"github.com/wlchs/blog/internal/types"
+	"strings"
+	"time"
+)
+
+// Meta returns the Open Graph and Twitter Card metadata and the schema.org BlogPosting structured data of the post,
+// used by social networks and search engines to create previews of the page.
+func (p PostPage) Meta() types.PostMeta {
+	post := p.Post
+	url := p.CanonicalURL()
+	image := p.ImageURL()
+	published := post.CreationTime.UTC().Format(time.RFC3339)
+	modified := LastModified(post).UTC().Format(time.RFC3339)
+	authorURL := p.URL(AuthorPath(post.Author))
+
+	meta := types.PostMeta{
+		Title:       post.Title,
+		Description: p.Description,
+		URL:         url,
+		Image:       image,
+		OpenGraph: []types.MetaTag{
+			{Property: "og:type", Content: "article"},
+			{Property: "og:title", Content: post.Title},
+			{Property: "og:description", Content: p.Description},
+			{Property: "og:url", Content: url},
+			{Property: "og:site_name", Content: p.Site.Title},
+			{Property: "og:locale", Content: strings.ReplaceAll(p.Language, "-", "_")},
+			{Property: "article:published_time", Content: published},
+			{Property: "article:modified_time", Content: modified},
+			{Property: "article:author", Content: authorURL},
+		},
+		TwitterCard: []types.MetaTag{
+			{Property: "twitter:card", Content: "summary"},
+			{Property: "twitter:title", Content: post.Title},
+			{Property: "twitter:description", Content: p.Description},
+		},
+		JSONLD: types.BlogPosting{
+			Context:          "https://schema.org",
+			Type:             "BlogPosting",
+			Headline:         post.Title,
+			Description:      p.Description,
+			Image:            image,
+			DatePublished:    published,
+			DateModified:     modified,
+			InLanguage:       p.Language,
+			Keywords:         post.Tags,
+			MainEntityOfPage: url,
+			Author:           types.SchemaEntity{Type: "Person", Name: post.Author, URL: authorURL},
+			Publisher:        types.SchemaEntity{Type: "Organization", Name: p.Site.Title, URL: p.URL("/")},
+		},
+	}
+
+	for _, tag := range post.Tags {
+		meta.OpenGraph = append(meta.OpenGraph, types.MetaTag{Property: "article:tag", Content: tag})
+	}
+	if image != "" {
+		meta.OpenGraph = append(meta.OpenGraph, types.MetaTag{Property: "og:image", Content: image})
+		meta.TwitterCard[0].Content = "summary_large_image"
+		meta.TwitterCard = append(meta.TwitterCard, types.MetaTag{Property: "twitter:image", Content: image})
+	}
+
+	return meta
+}
+
+// ImageURL returns the absolute URL of the cover image of the post. Paths of the blog are resolved using the base URL.
+func (p PostPage) ImageURL() string {
+	if p.Post.CoverImage == nil {
+		return ""
+	}
+	image := *p.Post.CoverImage
+	if strings.HasPrefix(image, "/") {
+		return p.URL(image)
+	}
+	return image
+}
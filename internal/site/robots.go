@@ -0,0 +1,50 @@
+package site
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// defaultRobots allows crawling the whole site, if no custom robots.txt is configured.
+const defaultRobots = "User-agent: *\nAllow: /\n"
+
+// Robots contains the robots.txt of the site.
+// The content is the custom robots.txt of the site, the default rules are used if it's empty.
+type Robots struct {
+	Site    Config
+	Content string
+}
+
+// WriteRobots writes the robots.txt of the site.
+// The sitemap is referenced, unless the robots.txt already contains a Sitemap directive.
+func WriteRobots(w io.Writer, robots Robots) error {
+	content := robots.Content
+	if strings.TrimSpace(content) == "" {
+		content = defaultRobots
+	}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+
+	if _, err := io.WriteString(w, content); err != nil {
+		return err
+	}
+	if hasSitemapDirective(content) {
+		return nil
+	}
+
+	page := Page{Site: robots.Site}
+	_, err := fmt.Fprintf(w, "\nSitemap: %s\n", page.URL(SitemapPath))
+	return err
+}
+
+// hasSitemapDirective checks whether a robots.txt references a sitemap. Directives are case-insensitive.
+func hasSitemapDirective(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(line)), "sitemap:") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+package site
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"github.com/wlchs/blog/internal/i18n"
+	"github.com/wlchs/blog/internal/types"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Paths of the feeds, the sitemap, and the Webmention and Micropub endpoints.
+const (
+	RSSPath           = "/feed.xml"
+	AtomPath          = "/atom.xml"
+	JSONFeedPath      = "/feed.json"
+	SitemapPath       = "/sitemap.xml"
+	RobotsPath        = "/robots.txt"
+	WebmentionPath    = "/webmention"
+	MicropubPath      = "/micropub"
+	MicropubMediaPath = "/micropub/media"
+)
+
+// defaultPageSize is the default number of posts listed on a page of the index.
+const defaultPageSize = 10
+
+//go:embed templates/*.html
+var templateFiles embed.FS
+
+// templates contains the parsed template set of every page type, each combined with the shared layout.
+var templates = map[string]*template.Template{
+	"author": parseTemplate("author"),
+	"index":  parseTemplate("index"),
+	"post":   parseTemplate("post"),
+}
+
+// Config contains the settings of the rendered site.
+// The base URL is used to create the absolute links of the feeds, the sitemap and the canonical page URLs.
+// The robots file is the path of a custom robots.txt.
+type Config struct {
+	BaseURL     string
+	Title       string
+	Description string
+	Language    string
+	PageSize    int
+	RobotsFile  string
+}
+
+// Page contains the data shared by every rendered page.
+// The canonical URL overrides the URL of the page in the canonical link, e.g. for posts republished from another site.
+// Pages marked as noindex ask search engines not to index them.
+type Page struct {
+	Site        Config
+	Title       string
+	Description string
+	Path        string
+	Language    string
+	Canonical   string
+	NoIndex     bool
+}
+
+// IndexPage is a page of the paginated list of posts.
+type IndexPage struct {
+	Page
+	Posts    []types.Post
+	Number   int
+	Total    int
+	Previous string
+	Next     string
+}
+
+// AuthorPage lists the posts of an author.
+type AuthorPage struct {
+	Page
+	Author string
+	Posts  []types.Post
+}
+
+// PostPage contains a post and its rendered body.
+type PostPage struct {
+	Page
+	Post types.Post
+	Body template.HTML
+}
+
+// LoadConfig reads the settings of the site from the SITE_* environment variables.
+func LoadConfig() Config {
+	config := Config{
+		BaseURL:     strings.TrimSuffix(os.Getenv("SITE_URL"), "/"),
+		Title:       os.Getenv("SITE_TITLE"),
+		Description: os.Getenv("SITE_DESCRIPTION"),
+		Language:    i18n.DefaultLanguage,
+		PageSize:    defaultPageSize,
+		RobotsFile:  os.Getenv("SITE_ROBOTS_FILE"),
+	}
+
+	if config.Title == "" {
+		config.Title = "Blog"
+	}
+	if language, ok := i18n.NormalizeTag(os.Getenv("SITE_LANGUAGE")); ok {
+		config.Language = language
+	}
+	if size, err := strconv.Atoi(os.Getenv("SITE_PAGE_SIZE")); err == nil && size > 0 {
+		config.PageSize = size
+	}
+
+	return config
+}
+
+// URL returns the absolute URL of the given path.
+func (p Page) URL(path string) string {
+	return p.Site.BaseURL + path
+}
+
+// CanonicalURL returns the canonical URL of the page, falling back to its own URL.
+func (p Page) CanonicalURL() string {
+	if p.Canonical != "" {
+		return p.Canonical
+	}
+	return p.URL(p.Path)
+}
+
+// IndexPath returns the path of the given page of the index. The first page is the home page.
+func IndexPath(number int) string {
+	if number <= 1 {
+		return "/"
+	}
+	return fmt.Sprintf("/page/%d", number)
+}
+
+// PostPath returns the path of the page of a post.
+func PostPath(urlHandle string) string {
+	return "/posts/" + url.PathEscape(urlHandle)
+}
+
+// AuthorPath returns the path of the page of an author.
+func AuthorPath(userName string) string {
+	return "/authors/" + url.PathEscape(userName)
+}
+
+// RenderAuthor renders the page of an author.
+func RenderAuthor(w io.Writer, page AuthorPage) error {
+	return render(w, "author", page)
+}
+
+// RenderIndex renders a page of the index.
+func RenderIndex(w io.Writer, page IndexPage) error {
+	return render(w, "index", page)
+}
+
+// RenderPost renders the page of a post.
+func RenderPost(w io.Writer, page PostPage) error {
+	return render(w, "post", page)
+}
+
+// Version identifies the templates and the settings used to render the pages.
+// Pages rendered with a different version must be rendered again.
+func Version(config Config) string {
+	hash := sha256.New()
+	_ = fs.WalkDir(templateFiles, ".", func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			content, _ := templateFiles.ReadFile(path)
+			hash.Write([]byte(path))
+			hash.Write(content)
+		}
+		return nil
+	})
+	_, _ = fmt.Fprintf(hash, "%+v", config)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// render executes a template into a buffer first, so nothing is written if rendering fails.
+func render(w io.Writer, name string, data interface{}) error {
+	var buf bytes.Buffer
+	if err := templates[name].ExecuteTemplate(&buf, "layout", data); err != nil {
+		return err
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// parseTemplate parses the template of a page type together with the shared layout.
+// The templates are embedded into the binary, so failing to parse them is a programming error.
+func parseTemplate(name string) *template.Template {
+	funcs := template.FuncMap{
+		"authorPath": AuthorPath,
+		"postPath":   PostPath,
+	}
+	return template.Must(template.New(name).Funcs(funcs).ParseFS(templateFiles, "templates/layout.html", "templates/"+name+".html"))
+}
@@ -0,0 +1,376 @@
+package site_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/site"
+	"github.com/wlchs/blog/internal/types"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testConfig is the site configuration used for testing purposes.
+var testConfig = site.Config{BaseURL: "https://example.com", Title: "Test blog", Description: "Testing", Language: "en", PageSize: 2}
+
+// createTestPost creates a public post for testing purposes.
+func createTestPost(urlHandle string, author string) types.Post {
+	return types.Post{
+		URLHandle:    urlHandle,
+		Title:        "Title of " + urlHandle,
+		Author:       author,
+		Summary:      "Summary of " + urlHandle,
+		CreationTime: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		UpdateTime:   time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC),
+		Language:     "en",
+		Tags:         []string{"go"},
+	}
+}
+
+// TestLoadConfig tests reading the settings of the site from the environment.
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("SITE_URL", "https://example.com/")
+	t.Setenv("SITE_TITLE", "")
+	t.Setenv("SITE_DESCRIPTION", "Testing")
+	t.Setenv("SITE_LANGUAGE", "DE-de")
+	t.Setenv("SITE_PAGE_SIZE", "invalid")
+	t.Setenv("SITE_ROBOTS_FILE", "")
+
+	config := site.LoadConfig()
+
+	expected := site.Config{BaseURL: "https://example.com", Title: "Blog", Description: "Testing", Language: "de-DE", PageSize: 10}
+	assert.Equal(t, expected, config, "config doesn't match")
+}
+
+// TestPaths tests creating the paths of the pages.
+func TestPaths(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "/", site.IndexPath(1), "first page should be the home page")
+	assert.Equal(t, "/page/3", site.IndexPath(3), "incorrect index path")
+	assert.Equal(t, "/posts/hello%20world", site.PostPath("hello world"), "URL handle should be escaped")
+	assert.Equal(t, "/authors/jane", site.AuthorPath("jane"), "incorrect author path")
+	assert.Equal(t, "/feed.json", site.JSONFeedPagePath(1), "first page should be the feed itself")
+	assert.Equal(t, "/feed/2.json", site.JSONFeedPagePath(2), "incorrect JSON Feed path")
+}
+
+// TestRenderIndex tests rendering a page of the index.
+func TestRenderIndex(t *testing.T) {
+	t.Parallel()
+
+	page := site.IndexPage{
+		Page:     site.Page{Site: testConfig, Path: "/page/2", Language: "en"},
+		Posts:    []types.Post{createTestPost("hello", "jane")},
+		Number:   2,
+		Total:    3,
+		Previous: "/",
+		Next:     "/page/3",
+	}
+
+	var buf bytes.Buffer
+	err := site.RenderIndex(&buf, page)
+
+	html := buf.String()
+	assert.Nil(t, err, "should complete without error")
+	assert.Contains(t, html, `<title>Test blog</title>`, "title should be rendered")
+	assert.Contains(t, html, `<link rel="canonical" href="https://example.com/page/2">`, "canonical URL should be rendered")
+	assert.Contains(t, html, `<link rel="micropub" href="https://example.com/micropub">`, "Micropub endpoint should be advertised")
+	assert.Contains(t, html, `<a href="/posts/hello">Title of hello</a>`, "post should be listed")
+	assert.Contains(t, html, `<a href="/authors/jane" rel="author">jane</a>`, "author should be linked")
+	assert.Contains(t, html, `<a href="/" rel="prev">`, "previous page should be linked")
+	assert.Contains(t, html, `<a href="/page/3" rel="next">`, "next page should be linked")
+}
+
+// TestRenderAuthor tests rendering the page of an author without posts.
+func TestRenderAuthor(t *testing.T) {
+	t.Parallel()
+
+	page := site.AuthorPage{Page: site.Page{Site: testConfig, Title: "jane", Path: "/authors/jane", Language: "en"}, Author: "jane"}
+
+	var buf bytes.Buffer
+	err := site.RenderAuthor(&buf, page)
+
+	html := buf.String()
+	assert.Nil(t, err, "should complete without error")
+	assert.Contains(t, html, `<title>jane | Test blog</title>`, "title should be rendered")
+	assert.Contains(t, html, `<h1>Posts by jane</h1>`, "author should be rendered")
+	assert.Contains(t, html, `No posts yet.`, "empty list should be rendered")
+}
+
+// TestRenderPost tests rendering the page of a post with its body and series navigation.
+func TestRenderPost(t *testing.T) {
+	t.Parallel()
+
+	post := createTestPost("hello", "jane")
+	post.Title = "Fish & Chips"
+	post.Series = &types.SeriesNavigation{Title: "Cooking", Position: 2, Total: 3, Previous: &types.SeriesLink{URLHandle: "first", Title: "First"}}
+	page := site.PostPage{
+		Page: site.Page{Site: testConfig, Title: post.Title, Description: post.Summary, Path: "/posts/hello", Language: "en"},
+		Post: post,
+		Body: "<p>Hello world</p>",
+	}
+
+	var buf bytes.Buffer
+	err := site.RenderPost(&buf, page)
+
+	html := buf.String()
+	assert.Nil(t, err, "should complete without error")
+	assert.Contains(t, html, `<h1>Fish &amp; Chips</h1>`, "title should be escaped")
+	assert.Contains(t, html, `<meta name="description" content="Summary of hello">`, "description should be rendered")
+	assert.Contains(t, html, `<p>Hello world</p>`, "body should be rendered as HTML")
+	assert.Contains(t, html, `<a href="/posts/first" rel="prev">First</a>`, "series navigation should be rendered")
+	assert.Contains(t, html, `<li>go</li>`, "tags should be rendered")
+	assert.Contains(t, html, `<link rel="webmention" href="https://example.com/webmention">`, "Webmention endpoint should be advertised")
+}
+
+// TestRenderPost_SEO tests rendering the canonical URL and noindex settings of a page.
+func TestRenderPost_SEO(t *testing.T) {
+	t.Parallel()
+
+	page := site.PostPage{
+		Page: site.Page{Site: testConfig, Path: "/posts/hello", Language: "en", Canonical: "https://example.org/hello", NoIndex: true},
+		Post: createTestPost("hello", "jane"),
+	}
+
+	var buf bytes.Buffer
+	err := site.RenderPost(&buf, page)
+
+	html := buf.String()
+	assert.Nil(t, err, "should complete without error")
+	assert.Contains(t, html, `<meta name="robots" content="noindex">`, "noindex should be rendered")
+	assert.Contains(t, html, `<link rel="canonical" href="https://example.org/hello">`, "canonical URL should be overridden")
+}
+
+// TestPostPage_Meta tests creating the Open Graph, Twitter Card and JSON-LD metadata of a post.
+func TestPostPage_Meta(t *testing.T) {
+	t.Parallel()
+
+	post := createTestPost("hello", "jane")
+	image := "/media/1"
+	post.CoverImage = &image
+	page := site.PostPage{Page: site.Page{Site: testConfig, Description: "Description", Path: "/posts/hello", Language: "en-US"}, Post: post}
+
+	meta := page.Meta()
+
+	assert.Equal(t, "https://example.com/posts/hello", meta.URL, "incorrect URL")
+	assert.Equal(t, "https://example.com/media/1", meta.Image, "cover image should be resolved")
+	assert.Contains(t, meta.OpenGraph, types.MetaTag{Property: "og:locale", Content: "en_US"}, "locale should be converted")
+	assert.Contains(t, meta.OpenGraph, types.MetaTag{Property: "og:image", Content: "https://example.com/media/1"}, "image should be included")
+	assert.Contains(t, meta.OpenGraph, types.MetaTag{Property: "article:tag", Content: "go"}, "tags should be included")
+	assert.Equal(t, types.MetaTag{Property: "twitter:card", Content: "summary_large_image"}, meta.TwitterCard[0], "large image card should be used")
+	assert.Equal(t, "BlogPosting", meta.JSONLD.Type, "incorrect schema type")
+	assert.Equal(t, "2024-01-02T03:04:05Z", meta.JSONLD.DatePublished, "incorrect publication date")
+	assert.Equal(t, "2024-02-03T04:05:06Z", meta.JSONLD.DateModified, "incorrect modification date")
+	assert.Equal(t, types.SchemaEntity{Type: "Person", Name: "jane", URL: "https://example.com/authors/jane"}, meta.JSONLD.Author, "incorrect author")
+}
+
+// TestPostPage_Meta_Without_Image tests creating the metadata of a post without cover image.
+func TestPostPage_Meta_Without_Image(t *testing.T) {
+	t.Parallel()
+
+	page := site.PostPage{Page: site.Page{Site: testConfig, Path: "/posts/hello"}, Post: createTestPost("hello", "jane")}
+
+	meta := page.Meta()
+
+	assert.Empty(t, meta.Image, "there should be no image")
+	assert.Equal(t, types.MetaTag{Property: "twitter:card", Content: "summary"}, meta.TwitterCard[0], "summary card should be used")
+	assert.Equal(t, 3, len(meta.TwitterCard), "image shouldn't be included")
+}
+
+// TestRenderPost_Meta tests rendering the metadata of a post into the head of the page.
+func TestRenderPost_Meta(t *testing.T) {
+	t.Parallel()
+
+	post := createTestPost("hello", "jane")
+	post.Title = "</script><script>alert(1)</script>"
+	image := "https://cdn.example.com/cover.png"
+	post.CoverImage = &image
+	page := site.PostPage{Page: site.Page{Site: testConfig, Path: "/posts/hello", Language: "en"}, Post: post}
+
+	var buf bytes.Buffer
+	err := site.RenderPost(&buf, page)
+
+	html := buf.String()
+	assert.Nil(t, err, "should complete without error")
+	assert.Contains(t, html, `<meta property="og:image" content="https://cdn.example.com/cover.png">`, "Open Graph tags should be rendered")
+	assert.Contains(t, html, `<meta name="twitter:card" content="summary_large_image">`, "Twitter Card tags should be rendered")
+	assert.Contains(t, html, `<script type="application/ld+json">{"@context":"https://schema.org","@type":"BlogPosting"`, "JSON-LD should be rendered")
+	assert.NotContains(t, html, "<script>alert(1)</script>", "title should be escaped")
+}
+
+// TestVersion tests that the version changes with the configuration.
+func TestVersion(t *testing.T) {
+	t.Parallel()
+
+	other := testConfig
+	other.Title = "Other blog"
+
+	assert.Equal(t, site.Version(testConfig), site.Version(testConfig), "version should be stable")
+	assert.NotEqual(t, site.Version(testConfig), site.Version(other), "version should depend on the configuration")
+}
+
+// TestWriteRSS tests writing an RSS feed.
+func TestWriteRSS(t *testing.T) {
+	t.Parallel()
+
+	feed := site.Feed{Site: testConfig, Posts: []types.Post{createTestPost("hello", "jane")}}
+
+	var buf bytes.Buffer
+	err := site.WriteRSS(&buf, feed)
+
+	xml := buf.String()
+	assert.Nil(t, err, "should complete without error")
+	assert.True(t, strings.HasPrefix(xml, `<?xml version="1.0" encoding="UTF-8"?>`), "XML declaration should be written")
+	assert.Contains(t, xml, `<atom:link href="https://example.com/feed.xml" rel="self" type="application/rss+xml"></atom:link>`, "self link should be written")
+	assert.Contains(t, xml, `<lastBuildDate>Sat, 03 Feb 2024 04:05:06 +0000</lastBuildDate>`, "build date should be the latest update")
+	assert.Contains(t, xml, `<guid isPermaLink="true">https://example.com/posts/hello</guid>`, "post should be written")
+	assert.Contains(t, xml, `<dc:creator>jane</dc:creator>`, "author should be written")
+	assert.Contains(t, xml, `<category>go</category>`, "tags should be written")
+}
+
+// TestWriteAtom tests writing an Atom feed.
+func TestWriteAtom(t *testing.T) {
+	t.Parallel()
+
+	feed := site.Feed{Site: testConfig, Posts: []types.Post{createTestPost("hello", "jane")}}
+
+	var buf bytes.Buffer
+	err := site.WriteAtom(&buf, feed)
+
+	xml := buf.String()
+	assert.Nil(t, err, "should complete without error")
+	assert.Contains(t, xml, `<feed xmlns="http://www.w3.org/2005/Atom">`, "Atom namespace should be written")
+	assert.Contains(t, xml, `<updated>2024-02-03T04:05:06Z</updated>`, "update time should be written")
+	assert.Contains(t, xml, `<published>2024-01-02T03:04:05Z</published>`, "publication time should be written")
+	assert.Contains(t, xml, `<uri>https://example.com/authors/jane</uri>`, "author should be linked")
+	assert.Contains(t, xml, `<category term="go"></category>`, "tags should be written")
+}
+
+// TestWriteJSONFeed tests writing a page of the JSON Feed.
+func TestWriteJSONFeed(t *testing.T) {
+	t.Parallel()
+
+	post := createTestPost("hello", "jane")
+	post.Body = "# Hello\n\nTom & Jerry\n\n<script>alert(1)</script>"
+	page := site.JSONFeedPage{Feed: site.Feed{Site: testConfig, Posts: []types.Post{post}}, Number: 1, Total: 2}
+
+	var buf bytes.Buffer
+	err := site.WriteJSONFeed(&buf, page)
+
+	var feed map[string]any
+	assert.Nil(t, err, "should complete without error")
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &feed), "feed should be valid JSON")
+	assert.Equal(t, "https://jsonfeed.org/version/1.1", feed["version"], "incorrect version")
+	assert.Equal(t, "https://example.com/feed.json", feed["feed_url"], "incorrect feed URL")
+	assert.Equal(t, "https://example.com/feed/2.json", feed["next_url"], "next page should be linked")
+
+	items := feed["items"].([]any)
+	assert.Equal(t, 1, len(items), "post should be written")
+	item := items[0].(map[string]any)
+	assert.Equal(t, "https://example.com/posts/hello", item["id"], "incorrect item ID")
+	assert.Equal(t, "<h1>Hello</h1>\n<p>Tom &amp; Jerry</p>\n", item["content_html"], "body should be rendered to sanitized HTML")
+	assert.Equal(t, "Hello Tom & Jerry", item["content_text"], "body should be written as text")
+	assert.Equal(t, "2024-01-02T03:04:05Z", item["date_published"], "incorrect publication date")
+	assert.Equal(t, "2024-02-03T04:05:06Z", item["date_modified"], "incorrect modification date")
+	assert.Equal(t, []any{map[string]any{"name": "jane", "url": "https://example.com/authors/jane"}}, item["authors"], "author should be written")
+	assert.Equal(t, []any{"go"}, item["tags"], "tags should be written")
+}
+
+// TestWriteJSONFeed_Last_Page tests writing the last page of the JSON Feed.
+func TestWriteJSONFeed_Last_Page(t *testing.T) {
+	t.Parallel()
+
+	page := site.JSONFeedPage{Feed: site.Feed{Site: testConfig}, Number: 2, Total: 2}
+
+	var buf bytes.Buffer
+	err := site.WriteJSONFeed(&buf, page)
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Contains(t, buf.String(), `"feed_url": "https://example.com/feed/2.json"`, "incorrect feed URL")
+	assert.Contains(t, buf.String(), `"items": []`, "items should be written even without posts")
+	assert.NotContains(t, buf.String(), "next_url", "last page shouldn't link a next page")
+}
+
+// TestWriteSitemap tests writing a sitemap.
+func TestWriteSitemap(t *testing.T) {
+	t.Parallel()
+
+	urls := []site.SitemapURL{
+		{Loc: "https://example.com/", LastMod: time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC)},
+		{Loc: "https://example.com/page/2"},
+	}
+
+	var buf bytes.Buffer
+	err := site.WriteSitemap(&buf, urls)
+
+	xml := buf.String()
+	assert.Nil(t, err, "should complete without error")
+	assert.Contains(t, xml, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`, "sitemap namespace should be written")
+	assert.Contains(t, xml, "<loc>https://example.com/</loc>\n    <lastmod>2024-02-03T04:05:06Z</lastmod>", "last modification should be written")
+	assert.NotContains(t, xml, "<lastmod></lastmod>", "missing last modification should be omitted")
+}
+
+// TestSitemap_Split tests splitting a sitemap into parts.
+func TestSitemap_Split(t *testing.T) {
+	t.Parallel()
+
+	sitemap := site.Sitemap{URLs: []site.SitemapURL{{Loc: "a"}, {Loc: "b"}, {Loc: "c"}}}
+
+	assert.Equal(t, [][]site.SitemapURL{{{Loc: "a"}, {Loc: "b"}}, {{Loc: "c"}}}, sitemap.Split(2), "parts don't match")
+	assert.Equal(t, [][]site.SitemapURL{sitemap.URLs}, sitemap.Split(3), "sitemap shouldn't be split")
+	assert.Equal(t, [][]site.SitemapURL{{}}, site.Sitemap{}.Split(3), "empty sitemap should have a single part")
+}
+
+// TestWriteSitemapIndex tests writing the index of a split sitemap.
+func TestWriteSitemapIndex(t *testing.T) {
+	t.Parallel()
+
+	parts := [][]site.SitemapURL{
+		{{Loc: "a", LastMod: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}, {Loc: "b", LastMod: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}},
+		{{Loc: "c"}},
+	}
+
+	var buf bytes.Buffer
+	err := site.WriteSitemapIndex(&buf, testConfig, parts)
+
+	xml := buf.String()
+	assert.Nil(t, err, "should complete without error")
+	assert.Contains(t, xml, `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`, "sitemap index should be written")
+	assert.Contains(t, xml, "<loc>https://example.com/sitemaps/1.xml</loc>\n    <lastmod>2024-03-01T00:00:00Z</lastmod>", "latest modification of the part should be used")
+	assert.Contains(t, xml, "<loc>https://example.com/sitemaps/2.xml</loc>\n  </sitemap>", "second part should be listed")
+}
+
+// TestWriteRobots tests writing the default and custom robots.txt.
+func TestWriteRobots(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := site.WriteRobots(&buf, site.Robots{Site: testConfig})
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "User-agent: *\nAllow: /\n\nSitemap: https://example.com/sitemap.xml\n", buf.String(), "default robots.txt doesn't match")
+
+	buf.Reset()
+	err = site.WriteRobots(&buf, site.Robots{Site: testConfig, Content: "User-agent: *\nDisallow: /private"})
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "User-agent: *\nDisallow: /private\n\nSitemap: https://example.com/sitemap.xml\n", buf.String(), "sitemap should be appended")
+
+	buf.Reset()
+	err = site.WriteRobots(&buf, site.Robots{Site: testConfig, Content: "User-agent: *\nSITEMAP: https://cdn.example.com/sitemap.xml\n"})
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "User-agent: *\nSITEMAP: https://cdn.example.com/sitemap.xml\n", buf.String(), "custom sitemap should be kept")
+}
+
+// TestLastModified tests falling back to the creation time of posts without updates.
+func TestLastModified(t *testing.T) {
+	t.Parallel()
+
+	post := createTestPost("hello", "jane")
+	assert.Equal(t, post.UpdateTime, site.LastModified(post), "update time should be used")
+
+	post.UpdateTime = time.Time{}
+	assert.Equal(t, post.CreationTime, site.LastModified(post), "creation time should be used")
+}
@@ -0,0 +1,101 @@
+package site
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// MaxSitemapURLs is the maximum number of URLs of a single sitemap allowed by the sitemap protocol.
+// Larger sitemaps are split into several sitemaps listed by a sitemap index.
+const MaxSitemapURLs = 50000
+
+// SitemapURL is a page listed in the sitemap.
+type SitemapURL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+// Sitemap contains the pages of the site to be crawled by search engines.
+type Sitemap struct {
+	Site Config
+	URLs []SitemapURL
+}
+
+// urlSet is the document structure of a sitemap.
+type urlSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+// sitemapIndex is the document structure of a sitemap index.
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemapindex"`
+	Sitemaps []sitemapURL `xml:"sitemap"`
+}
+
+// sitemapURL is an entry of a sitemap or a sitemap index.
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// SitemapPagePath returns the path of a part of a split sitemap, starting with 1.
+func SitemapPagePath(number int) string {
+	return fmt.Sprintf("/sitemaps/%d.xml", number)
+}
+
+// Split splits the URLs of the sitemap into parts of at most the given size.
+// A sitemap without URLs results in a single empty part.
+func (s Sitemap) Split(size int) [][]SitemapURL {
+	parts := make([][]SitemapURL, 0, len(s.URLs)/size+1)
+	for start := 0; start < len(s.URLs); start += size {
+		end := start + size
+		if end > len(s.URLs) {
+			end = len(s.URLs)
+		}
+		parts = append(parts, s.URLs[start:end])
+	}
+	if len(parts) == 0 {
+		parts = append(parts, []SitemapURL{})
+	}
+	return parts
+}
+
+// WriteSitemap writes the sitemap of the given pages.
+func WriteSitemap(w io.Writer, urls []SitemapURL) error {
+	document := urlSet{URLs: make([]sitemapURL, 0, len(urls))}
+	for _, u := range urls {
+		document.URLs = append(document.URLs, mapSitemapURL(u))
+	}
+
+	return writeXML(w, document)
+}
+
+// WriteSitemapIndex writes the index of the parts of a split sitemap.
+// The last modification of a part is the latest one of its pages.
+func WriteSitemapIndex(w io.Writer, config Config, parts [][]SitemapURL) error {
+	page := Page{Site: config}
+	document := sitemapIndex{Sitemaps: make([]sitemapURL, 0, len(parts))}
+	for i, part := range parts {
+		entry := SitemapURL{Loc: page.URL(SitemapPagePath(i + 1))}
+		for _, u := range part {
+			if u.LastMod.After(entry.LastMod) {
+				entry.LastMod = u.LastMod
+			}
+		}
+		document.Sitemaps = append(document.Sitemaps, mapSitemapURL(entry))
+	}
+
+	return writeXML(w, document)
+}
+
+// mapSitemapURL maps a page to an entry of a sitemap, omitting the unknown last modification.
+func mapSitemapURL(u SitemapURL) sitemapURL {
+	entry := sitemapURL{Loc: u.Loc}
+	if !u.LastMod.IsZero() {
+		entry.LastMod = u.LastMod.UTC().Format(time.RFC3339)
+	}
+	return entry
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"errors"
+	"github.com/wlchs/blog/internal/errortypes"
+	"go.uber.org/zap"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// localStorage is a Storage implementation keeping the files in a directory of the local filesystem.
+type localStorage struct {
+	logger *zap.SugaredLogger
+	root   string
+	mode   fs.FileMode
+}
+
+// CreateLocalStorage instantiates the local filesystem storage using the given root directory.
+// Stored files are only readable by the owner.
+func CreateLocalStorage(logger *zap.SugaredLogger, root string) Storage {
+	return CreateLocalStorageWithMode(logger, root, 0o600)
+}
+
+// CreateLocalStorageWithMode instantiates the local filesystem storage using the given root directory
+// and the permissions of the stored files.
+func CreateLocalStorageWithMode(logger *zap.SugaredLogger, root string, mode fs.FileMode) Storage {
+	return &localStorage{
+		logger: logger,
+		root:   root,
+		mode:   mode,
+	}
+}
+
+// resolve maps a storage key to a path within the root directory.
+// Keys escaping the root directory are rejected.
+func (s localStorage) resolve(key string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Delete removes the file with the given key together with its parent directories, as long as they became empty.
+// Deleting a non-existent file is not an error.
+func (s localStorage) Delete(key string) error {
+	log := s.logger
+
+	p, err := s.resolve(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Debugf("failed to delete file %s: %v", key, err)
+		return err
+	}
+
+	// Removing a directory fails if it still contains other files
+	for dir := filepath.Dir(p); dir != filepath.Clean(s.root); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+
+	log.Debugf("deleted file: %s", key)
+	return nil
+}
+
+// Get opens the file with the given key for reading. The caller is responsible for closing it.
+func (s localStorage) Get(key string) (io.ReadCloser, error) {
+	log := s.logger
+
+	p, err := s.resolve(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, errortypes.StorageObjectNotFoundError{Key: key}
+	} else if err != nil {
+		log.Debugf("failed to open file %s: %v", key, err)
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Put stores the content under the given key, replacing the existing file.
+// The content is written to a temporary file first, so readers never see partially written files.
+func (s localStorage) Put(key string, content io.Reader, _ int64, _ string) error {
+	log := s.logger
+
+	p, err := s.resolve(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		log.Debugf("failed to create directory for file %s: %v", key, err)
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		log.Debugf("failed to create temporary file for %s: %v", key, err)
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, content); err != nil {
+		_ = tmp.Close()
+		log.Debugf("failed to write file %s: %v", key, err)
+		return err
+	}
+
+	if err := tmp.Chmod(s.mode); err != nil {
+		_ = tmp.Close()
+		log.Debugf("failed to set the permissions of file %s: %v", key, err)
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		log.Debugf("failed to move file %s to its final location: %v", key, err)
+		return err
+	}
+
+	log.Debugf("stored file: %s", key)
+	return nil
+}
+
+// URL returns an empty string, since files of the local storage can only be downloaded through the blog engine.
+func (s localStorage) URL(key string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	return "", nil
+}
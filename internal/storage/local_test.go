@@ -0,0 +1,107 @@
+package storage_test
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/storage"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestLocalStorage_Put_Get tests storing a file and reading it back.
+func TestLocalStorage_Put_Get(t *testing.T) {
+	t.Parallel()
+	sut := storage.CreateLocalStorage(logger.CreateLogger(), t.TempDir())
+
+	content := []byte("test content")
+	err := sut.Put("media/2024/01/test.png", bytes.NewReader(content), int64(len(content)), "image/png")
+	assert.Nil(t, err, "should complete without error")
+
+	f, err := sut.Get("media/2024/01/test.png")
+	assert.Nil(t, err, "should complete without error")
+	defer f.Close()
+
+	stored, _ := io.ReadAll(f)
+	assert.Equal(t, content, stored, "stored content should match the input")
+}
+
+// TestLocalStorage_Get_Not_Found tests reading a non-existent file.
+func TestLocalStorage_Get_Not_Found(t *testing.T) {
+	t.Parallel()
+	sut := storage.CreateLocalStorage(logger.CreateLogger(), t.TempDir())
+
+	f, err := sut.Get("missing.png")
+
+	assert.Nil(t, f, "shouldn't return a file")
+	assert.Equal(t, errortypes.StorageObjectNotFoundError{Key: "missing.png"}, err, "error doesn't match expected one")
+}
+
+// TestLocalStorage_Delete tests removing a stored file.
+func TestLocalStorage_Delete(t *testing.T) {
+	t.Parallel()
+	sut := storage.CreateLocalStorage(logger.CreateLogger(), t.TempDir())
+
+	_ = sut.Put("test.png", bytes.NewReader([]byte("test")), 4, "image/png")
+
+	assert.Nil(t, sut.Delete("test.png"), "should complete without error")
+	assert.Nil(t, sut.Delete("test.png"), "deleting a missing file shouldn't fail")
+
+	_, err := sut.Get("test.png")
+	assert.IsType(t, errortypes.StorageObjectNotFoundError{}, err, "file should be deleted")
+}
+
+// TestLocalStorage_Delete_Directories tests removing the parent directories of a file once they became empty.
+func TestLocalStorage_Delete_Directories(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	sut := storage.CreateLocalStorage(logger.CreateLogger(), root)
+
+	_ = sut.Put("posts/a/index.html", bytes.NewReader([]byte("a")), 1, "text/html")
+	_ = sut.Put("posts/b/index.html", bytes.NewReader([]byte("b")), 1, "text/html")
+
+	assert.Nil(t, sut.Delete("posts/a/index.html"), "should complete without error")
+
+	assert.NoDirExists(t, filepath.Join(root, "posts", "a"), "empty directory should be removed")
+	assert.DirExists(t, filepath.Join(root, "posts", "b"), "directory with other files should be kept")
+	assert.DirExists(t, root, "root directory should be kept")
+}
+
+// TestLocalStorage_Mode tests the permissions of the stored files.
+func TestLocalStorage_Mode(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+
+	_ = storage.CreateLocalStorage(logger.CreateLogger(), root).Put("private.md", bytes.NewReader([]byte("a")), 1, "text/markdown")
+	_ = storage.CreateLocalStorageWithMode(logger.CreateLogger(), root, 0o644).Put("public.html", bytes.NewReader([]byte("b")), 1, "text/html")
+
+	private, _ := os.Stat(filepath.Join(root, "private.md"))
+	public, _ := os.Stat(filepath.Join(root, "public.html"))
+	assert.Equal(t, os.FileMode(0o600), private.Mode().Perm(), "file should only be readable by the owner")
+	assert.Equal(t, os.FileMode(0o644), public.Mode().Perm(), "file should be readable by everyone")
+}
+
+// TestLocalStorage_Invalid_Key tests rejecting keys escaping the storage root.
+func TestLocalStorage_Invalid_Key(t *testing.T) {
+	t.Parallel()
+	sut := storage.CreateLocalStorage(logger.CreateLogger(), t.TempDir())
+
+	for _, key := range []string{"", "/etc/passwd", "../secret", "media/../../secret", "media//test.png"} {
+		err := sut.Put(key, bytes.NewReader([]byte("test")), 4, "image/png")
+		assert.Equal(t, errortypes.InvalidStorageKeyError{Key: key}, err, "key %s should be rejected", key)
+	}
+}
+
+// TestLocalStorage_URL tests that the local storage doesn't offer direct downloads.
+func TestLocalStorage_URL(t *testing.T) {
+	t.Parallel()
+	sut := storage.CreateLocalStorage(logger.CreateLogger(), t.TempDir())
+
+	u, err := sut.URL("media/test.png")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "", u, "no URL should be returned")
+}
@@ -0,0 +1,334 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/sigv4"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultPartSize is the default size of the parts of multipart uploads in bytes.
+const defaultPartSize = 8 << 20
+
+// S3Config contains the connection settings of an S3-compatible object storage.
+// Path-style addressing (endpoint/bucket/key) is used by most self-hosted storages, e.g. MinIO,
+// otherwise the bucket is addressed as a subdomain of the endpoint.
+// Presigned download URLs are only created if PresignExpiry is set.
+type S3Config struct {
+	Endpoint      string
+	Region        string
+	Bucket        string
+	AccessKey     string
+	SecretKey     string
+	PathStyle     bool
+	PartSize      int
+	PresignExpiry time.Duration
+}
+
+// s3Storage is a Storage implementation keeping the files in the bucket of an S3-compatible object storage.
+type s3Storage struct {
+	logger *zap.SugaredLogger
+	config S3Config
+	client *http.Client
+	signer sigv4.Signer
+}
+
+// s3Error is the error document returned by the object storage.
+type s3Error struct {
+	XMLName xml.Name `xml:"Error"`
+	Code    string   `xml:"Code"`
+	Message string   `xml:"Message"`
+}
+
+// initiateMultipartUploadResult is the response to starting a multipart upload.
+type initiateMultipartUploadResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+// completedPart references an uploaded part when completing a multipart upload.
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// completeMultipartUpload is the request body of completing a multipart upload.
+type completeMultipartUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []completedPart `xml:"Part"`
+}
+
+// CreateS3Storage instantiates the object storage backend using the given connection settings.
+func CreateS3Storage(logger *zap.SugaredLogger, config S3Config) Storage {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = 30 * time.Second
+
+	if config.PartSize <= 0 {
+		config.PartSize = defaultPartSize
+	}
+
+	return &s3Storage{
+		logger: logger,
+		config: config,
+		client: &http.Client{Transport: transport},
+		signer: sigv4.Signer{
+			AccessKey: config.AccessKey,
+			SecretKey: config.SecretKey,
+			Region:    config.Region,
+			Service:   "s3",
+		},
+	}
+}
+
+// Delete removes the object with the given key. Deleting a non-existent object is not an error.
+func (s s3Storage) Delete(key string) error {
+	log := s.logger
+
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	resp, err := s.send(http.MethodDelete, key, nil, nil, nil)
+	if err != nil {
+		var notFound errortypes.StorageObjectNotFoundError
+		if errors.As(err, &notFound) {
+			return nil
+		}
+		log.Debugf("failed to delete object %s: %v", key, err)
+		return err
+	}
+	_ = resp.Body.Close()
+
+	log.Debugf("deleted object: %s", key)
+	return nil
+}
+
+// Get opens the object with the given key for reading. The caller is responsible for closing it.
+func (s s3Storage) Get(key string) (io.ReadCloser, error) {
+	log := s.logger
+
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.send(http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		log.Debugf("failed to get object %s: %v", key, err)
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+// Put stores the content under the given key, replacing the existing object.
+// Content larger than the part size is uploaded in parts, so it never has to be kept in memory as a whole.
+func (s s3Storage) Put(key string, content io.Reader, _ int64, contentType string) error {
+	log := s.logger
+
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	part, last, err := readPart(content, s.config.PartSize)
+	if err != nil {
+		return err
+	}
+
+	if last {
+		resp, err := s.send(http.MethodPut, key, nil, part, map[string]string{"Content-Type": contentType})
+		if err != nil {
+			log.Debugf("failed to put object %s: %v", key, err)
+			return err
+		}
+		_ = resp.Body.Close()
+
+		log.Debugf("stored object: %s", key)
+		return nil
+	}
+
+	return s.putMultipart(key, content, contentType, part)
+}
+
+// URL creates a presigned URL to download the object directly from the storage.
+// An empty string is returned if presigned URLs are disabled.
+func (s s3Storage) URL(key string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+
+	if s.config.PresignExpiry <= 0 {
+		return "", nil
+	}
+
+	u, err := s.objectURL(key, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return s.signer.Presign(http.MethodGet, u, s.config.PresignExpiry, time.Now()), nil
+}
+
+// putMultipart uploads the content in parts, starting with the already read first part.
+// The upload is aborted if any of the parts fails, so the storage doesn't keep the orphaned parts.
+func (s s3Storage) putMultipart(key string, content io.Reader, contentType string, first []byte) error {
+	log := s.logger
+
+	resp, err := s.send(http.MethodPost, key, url.Values{"uploads": {""}}, nil, map[string]string{"Content-Type": contentType})
+	if err != nil {
+		log.Debugf("failed to start multipart upload of object %s: %v", key, err)
+		return err
+	}
+
+	var initiated initiateMultipartUploadResult
+	err = xml.NewDecoder(resp.Body).Decode(&initiated)
+	_ = resp.Body.Close()
+	if err != nil || initiated.UploadID == "" {
+		log.Debugf("invalid response to starting multipart upload of object %s: %v", key, err)
+		return errortypes.ObjectStorageError{Operation: "initiate multipart upload", Key: key, Message: "missing upload ID"}
+	}
+
+	uploadID := initiated.UploadID
+	if err := s.uploadParts(key, uploadID, content, first); err != nil {
+		log.Debugf("multipart upload of object %s failed, aborting: %v", key, err)
+		if resp, abortErr := s.send(http.MethodDelete, key, url.Values{"uploadId": {uploadID}}, nil, nil); abortErr == nil {
+			_ = resp.Body.Close()
+		} else {
+			log.Errorf("failed to abort multipart upload %s of object %s: %v", uploadID, key, abortErr)
+		}
+		return err
+	}
+
+	log.Debugf("stored object using multipart upload: %s", key)
+	return nil
+}
+
+// uploadParts uploads the parts of a multipart upload and completes it.
+func (s s3Storage) uploadParts(key string, uploadID string, content io.Reader, part []byte) error {
+	var parts []completedPart
+	var last bool
+
+	for number := 1; ; number++ {
+		query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadID}}
+		resp, err := s.send(http.MethodPut, key, query, part, nil)
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+		parts = append(parts, completedPart{PartNumber: number, ETag: resp.Header.Get("ETag")})
+
+		if last {
+			break
+		}
+
+		part, last, err = readPart(content, s.config.PartSize)
+		if err != nil {
+			return err
+		}
+		if len(part) == 0 {
+			break
+		}
+	}
+
+	body, err := xml.Marshal(completeMultipartUpload{Parts: parts})
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.send(http.MethodPost, key, url.Values{"uploadId": {uploadID}}, body, map[string]string{"Content-Type": "application/xml"})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Completing an upload may fail after the response status has already been sent
+	result, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var failure s3Error
+	if xml.Unmarshal(result, &failure) == nil {
+		return errortypes.ObjectStorageError{Operation: "complete multipart upload", Key: key, StatusCode: resp.StatusCode, Code: failure.Code, Message: failure.Message}
+	}
+
+	return nil
+}
+
+// send signs and sends a request concerning the object with the given key.
+// Responses with an error status are converted to errors, the caller is responsible for closing the body of successful responses.
+func (s s3Storage) send(method string, key string, query url.Values, body []byte, headers map[string]string) (*http.Response, error) {
+	u, err := s.objectURL(key, query)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range headers {
+		if value != "" {
+			req.Header.Set(name, value)
+		}
+	}
+	s.signer.Sign(req, sigv4.HashPayload(body), time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound && query == nil {
+		return nil, errortypes.StorageObjectNotFoundError{Key: key}
+	}
+
+	var failure s3Error
+	_ = xml.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&failure)
+	return nil, errortypes.ObjectStorageError{Operation: strings.ToLower(method), Key: key, StatusCode: resp.StatusCode, Code: failure.Code, Message: failure.Message}
+}
+
+// objectURL returns the encoded URL of the object with the given key.
+func (s s3Storage) objectURL(key string, query url.Values) (*url.URL, error) {
+	u, err := url.Parse(s.config.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	objectPath := "/" + key
+	if s.config.PathStyle {
+		objectPath = "/" + s.config.Bucket + objectPath
+	} else {
+		u.Host = s.config.Bucket + "." + u.Host
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + objectPath
+	u.RawPath = sigv4.EncodePath(u.Path)
+	u.RawQuery = sigv4.EncodeQuery(query)
+	return u, nil
+}
+
+// readPart reads the next part of the content. The second return value reports whether the content is exhausted.
+func readPart(content io.Reader, size int) ([]byte, bool, error) {
+	part := make([]byte, size)
+	n, err := io.ReadFull(content, part)
+
+	switch {
+	case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+		return part[:n], true, nil
+	case err != nil:
+		return nil, false, err
+	default:
+		return part, false, nil
+	}
+}
@@ -0,0 +1,287 @@
+package storage_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/errortypes"
+	"github.com/wlchs/blog/internal/logger"
+	"github.com/wlchs/blog/internal/sigv4"
+	"github.com/wlchs/blog/internal/storage"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testBucket is the only bucket of the object storage stand-in.
+const testBucket = "blog"
+
+// testSigner holds the credentials accepted by the object storage stand-in.
+var testSigner = sigv4.Signer{AccessKey: "access", SecretKey: "secret", Region: "us-east-1", Service: "s3"}
+
+// fakeS3 is a minimal in-memory stand-in of an S3-compatible object storage using path-style addressing.
+// Every request is checked by signing it again with the expected credentials.
+type fakeS3 struct {
+	mu          sync.Mutex
+	objects     map[string][]byte
+	uploads     map[string]map[int][]byte
+	requests    []string
+	failPartTwo bool
+}
+
+// createS3Context starts the object storage stand-in and creates a storage connected to it.
+func createS3Context(t *testing.T, config storage.S3Config) (*fakeS3, storage.Storage) {
+	t.Helper()
+
+	fake := &fakeS3{objects: map[string][]byte{}, uploads: map[string]map[int][]byte{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	config.Endpoint = server.URL
+	config.Region = testSigner.Region
+	config.Bucket = testBucket
+	config.PathStyle = true
+	if config.AccessKey == "" {
+		config.AccessKey = testSigner.AccessKey
+		config.SecretKey = testSigner.SecretKey
+	}
+
+	return fake, storage.CreateS3Storage(logger.CreateLogger(), config)
+}
+
+// ServeHTTP handles the object and multipart upload operations of the stand-in.
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	query := r.URL.Query()
+
+	if !f.authorized(r, body) {
+		writeS3Error(w, http.StatusForbidden, "SignatureDoesNotMatch")
+		return
+	}
+
+	key, found := strings.CutPrefix(r.URL.Path, "/"+testBucket+"/")
+	if !found {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		uploadID := fmt.Sprintf("upload-%d", len(f.requests))
+		f.uploads[uploadID] = map[int][]byte{}
+		f.requests = append(f.requests, "initiate")
+		_, _ = fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", uploadID)
+
+	case r.Method == http.MethodPut && query.Has("partNumber"):
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		f.requests = append(f.requests, "part")
+		if number == 2 && f.failPartTwo {
+			writeS3Error(w, http.StatusInternalServerError, "InternalError")
+			return
+		}
+		f.uploads[query.Get("uploadId")][number] = body
+		w.Header().Set("ETag", fmt.Sprintf("\"etag-%d\"", number))
+
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		var complete struct {
+			Parts []struct {
+				PartNumber int
+				ETag       string
+			} `xml:"Part"`
+		}
+		_ = xml.Unmarshal(body, &complete)
+		f.requests = append(f.requests, "complete")
+
+		var object []byte
+		for _, part := range complete.Parts {
+			if part.ETag != fmt.Sprintf("\"etag-%d\"", part.PartNumber) {
+				writeS3Error(w, http.StatusOK, "InvalidPart")
+				return
+			}
+			object = append(object, f.uploads[query.Get("uploadId")][part.PartNumber]...)
+		}
+		f.objects[key] = object
+		delete(f.uploads, query.Get("uploadId"))
+		_, _ = fmt.Fprint(w, "<CompleteMultipartUploadResult></CompleteMultipartUploadResult>")
+
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		f.requests = append(f.requests, "abort")
+		delete(f.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == http.MethodPut:
+		f.requests = append(f.requests, "put")
+		f.objects[key] = body
+
+	case r.Method == http.MethodGet:
+		object, found := f.objects[key]
+		if !found {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		_, _ = w.Write(object)
+
+	case r.Method == http.MethodDelete:
+		f.requests = append(f.requests, "delete")
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// authorized checks the header or query signature of a request by signing it again with the expected credentials.
+func (f *fakeS3) authorized(r *http.Request, body []byte) bool {
+	u := &url.URL{Scheme: "http", Host: r.Host, Path: r.URL.Path}
+
+	if r.URL.Query().Has("X-Amz-Signature") {
+		query := r.URL.Query()
+		signedAt, _ := time.Parse("20060102T150405Z", query.Get("X-Amz-Date"))
+		expiry, _ := strconv.Atoi(query.Get("X-Amz-Expires"))
+		if time.Since(signedAt) > time.Duration(expiry)*time.Second {
+			return false
+		}
+		expected, _ := url.Parse(testSigner.Presign(r.Method, u, time.Duration(expiry)*time.Second, signedAt))
+		return expected.Query().Get("X-Amz-Signature") == query.Get("X-Amz-Signature")
+	}
+
+	authorization := r.Header.Get("Authorization")
+	_, signedHeaders, _ := strings.Cut(authorization, "SignedHeaders=")
+	signedHeaders, _, _ = strings.Cut(signedHeaders, ",")
+
+	u.RawQuery = r.URL.RawQuery
+	req, _ := http.NewRequest(r.Method, u.String(), nil)
+	req.Host = r.Host
+	for _, name := range strings.Split(signedHeaders, ";") {
+		if name != "host" && name != "x-amz-date" && name != "x-amz-content-sha256" {
+			req.Header.Set(name, r.Header.Get(name))
+		}
+	}
+
+	signedAt, _ := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+	testSigner.Sign(req, sigv4.HashPayload(body), signedAt)
+
+	return r.Header.Get("X-Amz-Content-Sha256") == sigv4.HashPayload(body) && req.Header.Get("Authorization") == authorization
+}
+
+// writeS3Error writes an error document the way S3 does.
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+}
+
+// TestS3Storage_Put_Get tests storing a small file with a single request and reading it back.
+func TestS3Storage_Put_Get(t *testing.T) {
+	t.Parallel()
+	fake, sut := createS3Context(t, storage.S3Config{})
+
+	content := []byte("test content")
+	err := sut.Put("media/2024/01/a b+c.png", bytes.NewReader(content), int64(len(content)), "image/png")
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, []string{"put"}, fake.requests, "small files should be stored with a single request")
+
+	f, err := sut.Get("media/2024/01/a b+c.png")
+	assert.Nil(t, err, "should complete without error")
+	defer f.Close()
+
+	stored, _ := io.ReadAll(f)
+	assert.Equal(t, content, stored, "stored content should match the input")
+}
+
+// TestS3Storage_Put_Multipart tests storing a file larger than the part size using a multipart upload.
+func TestS3Storage_Put_Multipart(t *testing.T) {
+	t.Parallel()
+	fake, sut := createS3Context(t, storage.S3Config{PartSize: 5})
+
+	err := sut.Put("export.zip", strings.NewReader("0123456789abc"), -1, "application/zip")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, []string{"initiate", "part", "part", "part", "complete"}, fake.requests, "file should be uploaded in three parts")
+	assert.Equal(t, "0123456789abc", string(fake.objects["export.zip"]), "parts should be assembled in order")
+	assert.Empty(t, fake.uploads, "no upload should be left open")
+}
+
+// TestS3Storage_Put_Multipart_Abort tests aborting a multipart upload if one of its parts fails.
+func TestS3Storage_Put_Multipart_Abort(t *testing.T) {
+	t.Parallel()
+	fake, sut := createS3Context(t, storage.S3Config{PartSize: 5})
+	fake.failPartTwo = true
+
+	err := sut.Put("export.zip", strings.NewReader("0123456789abc"), -1, "application/zip")
+
+	assert.IsType(t, errortypes.ObjectStorageError{}, err, "error doesn't match expected one")
+	assert.Equal(t, []string{"initiate", "part", "part", "abort"}, fake.requests, "upload should be aborted")
+	assert.Empty(t, fake.uploads, "no upload should be left open")
+	assert.Empty(t, fake.objects, "no object should be stored")
+}
+
+// TestS3Storage_Get_Not_Found tests reading a non-existent object.
+func TestS3Storage_Get_Not_Found(t *testing.T) {
+	t.Parallel()
+	_, sut := createS3Context(t, storage.S3Config{})
+
+	f, err := sut.Get("missing.png")
+
+	assert.Nil(t, f, "shouldn't return a file")
+	assert.Equal(t, errortypes.StorageObjectNotFoundError{Key: "missing.png"}, err, "error doesn't match expected one")
+}
+
+// TestS3Storage_Delete tests removing a stored object.
+func TestS3Storage_Delete(t *testing.T) {
+	t.Parallel()
+	fake, sut := createS3Context(t, storage.S3Config{})
+	fake.objects["test.png"] = []byte("test")
+
+	err := sut.Delete("test.png")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Empty(t, fake.objects, "object should be deleted")
+}
+
+// TestS3Storage_Invalid_Credentials tests handling requests rejected by the object storage.
+func TestS3Storage_Invalid_Credentials(t *testing.T) {
+	t.Parallel()
+	_, sut := createS3Context(t, storage.S3Config{AccessKey: testSigner.AccessKey, SecretKey: "wrong"})
+
+	err := sut.Put("test.png", strings.NewReader("test"), 4, "image/png")
+
+	expectedError := errortypes.ObjectStorageError{Operation: "put", Key: "test.png", StatusCode: 403, Code: "SignatureDoesNotMatch", Message: "SignatureDoesNotMatch"}
+	assert.Equal(t, expectedError, err, "error doesn't match expected one")
+}
+
+// TestS3Storage_URL tests downloading an object using a presigned URL.
+func TestS3Storage_URL(t *testing.T) {
+	t.Parallel()
+	fake, sut := createS3Context(t, storage.S3Config{PresignExpiry: time.Minute})
+	fake.objects["media/test.png"] = []byte("test")
+
+	u, err := sut.URL("media/test.png")
+	assert.Nil(t, err, "should complete without error")
+
+	resp, err := http.Get(u)
+	assert.Nil(t, err, "should complete without error")
+	defer resp.Body.Close()
+
+	content, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, 200, resp.StatusCode, "presigned URL should be accepted")
+	assert.Equal(t, "test", string(content), "object should be downloaded")
+}
+
+// TestS3Storage_URL_Disabled tests that no presigned URLs are created unless an expiry is configured.
+func TestS3Storage_URL_Disabled(t *testing.T) {
+	t.Parallel()
+	_, sut := createS3Context(t, storage.S3Config{})
+
+	u, err := sut.URL("media/test.png")
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "", u, "no URL should be returned")
+}
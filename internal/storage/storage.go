@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"github.com/wlchs/blog/internal/errortypes"
+	"io"
+	"path"
+	"strings"
+)
+
+// Storage interface defining the operations of a file storage backend.
+// Files are identified by slash-separated keys, e.g. "media/2023/05/cover.jpg".
+// URL returns a temporary URL to download the file directly from the backend,
+// or an empty string if the backend doesn't support direct downloads.
+type Storage interface {
+	Delete(key string) error
+	Get(key string) (io.ReadCloser, error)
+	Put(key string, content io.Reader, size int64, contentType string) error
+	URL(key string) (string, error)
+}
+
+// validateKey rejects keys which are empty, absolute, not normalized or escape the root of the storage.
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || strings.HasPrefix(key, "../") || key == ".." {
+		return errortypes.InvalidStorageKeyError{Key: key}
+	}
+	return nil
+}
@@ -7,13 +7,14 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 )
 
 // CreateControllerContext creates a Context for mocking HTTP requests.
 func CreateControllerContext() (*gin.Context, *httptest.ResponseRecorder) {
 	recorder := httptest.NewRecorder()
 	ctx, _ := gin.CreateTestContext(recorder)
-	ctx.Request = &http.Request{Header: make(http.Header)}
+	ctx.Request = &http.Request{Header: make(http.Header), URL: &url.URL{}}
 	return ctx, recorder
 }
 
@@ -0,0 +1,108 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Media types of the ActivityPub documents and the WebFinger responses.
+const (
+	ActivityContentType  = "application/activity+json"
+	ActivityProfileType  = `application/ld+json; profile="https://www.w3.org/ns/activitystreams"`
+	WebFingerContentType = "application/jrd+json"
+)
+
+// JSON-LD contexts and the addressing of public activities.
+const (
+	ActivityStreamsContext = "https://www.w3.org/ns/activitystreams"
+	SecurityContext        = "https://w3id.org/security/v1"
+	PublicAudience         = "https://www.w3.org/ns/activitystreams#Public"
+)
+
+type Actor struct {
+	Context           interface{}     `json:"@context,omitempty"`
+	ID                string          `json:"id"`
+	Type              string          `json:"type"`
+	PreferredUsername string          `json:"preferredUsername"`
+	Name              string          `json:"name,omitempty"`
+	URL               string          `json:"url,omitempty"`
+	Inbox             string          `json:"inbox"`
+	Outbox            string          `json:"outbox,omitempty"`
+	Followers         string          `json:"followers,omitempty"`
+	Endpoints         *ActorEndpoints `json:"endpoints,omitempty"`
+	PublicKey         ActorPublicKey  `json:"publicKey"`
+}
+
+type ActorEndpoints struct {
+	SharedInbox string `json:"sharedInbox,omitempty"`
+}
+
+type ActorPublicKey struct {
+	ID           string `json:"id"`
+	Owner        string `json:"owner"`
+	PublicKeyPem string `json:"publicKeyPem"`
+}
+
+type Activity struct {
+	Context   interface{} `json:"@context,omitempty"`
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	Actor     string      `json:"actor"`
+	Object    interface{} `json:"object"`
+	To        []string    `json:"to,omitempty"`
+	Cc        []string    `json:"cc,omitempty"`
+	Published *time.Time  `json:"published,omitempty"`
+}
+
+// IncomingActivity is an activity received in an inbox. The object is either embedded or referenced by its ID.
+type IncomingActivity struct {
+	ID     string          `json:"id"`
+	Type   string          `json:"type"`
+	Actor  string          `json:"actor"`
+	Object json.RawMessage `json:"object"`
+}
+
+type Article struct {
+	ID           string     `json:"id"`
+	Type         string     `json:"type"`
+	AttributedTo string     `json:"attributedTo"`
+	Name         string     `json:"name"`
+	Summary      string     `json:"summary,omitempty"`
+	Content      string     `json:"content"`
+	URL          string     `json:"url"`
+	Published    time.Time  `json:"published"`
+	Updated      *time.Time `json:"updated,omitempty"`
+	To           []string   `json:"to"`
+	Cc           []string   `json:"cc,omitempty"`
+	Tag          []Hashtag  `json:"tag,omitempty"`
+}
+
+type Hashtag struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+type Tombstone struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+type OrderedCollection struct {
+	Context      interface{}   `json:"@context,omitempty"`
+	ID           string        `json:"id"`
+	Type         string        `json:"type"`
+	TotalItems   int           `json:"totalItems"`
+	OrderedItems []interface{} `json:"orderedItems,omitempty"`
+}
+
+type WebFinger struct {
+	Subject string          `json:"subject"`
+	Aliases []string        `json:"aliases,omitempty"`
+	Links   []WebFingerLink `json:"links"`
+}
+
+type WebFingerLink struct {
+	Rel  string `json:"rel"`
+	Type string `json:"type,omitempty"`
+	Href string `json:"href"`
+}
@@ -0,0 +1,56 @@
+package types
+
+import "time"
+
+// Granularities of the view statistics.
+const (
+	AnalyticsPeriodHour = "hour"
+	AnalyticsPeriodDay  = "day"
+)
+
+// AnalyticsQuery selects the statistics to retrieve. The range is given by dates in UTC, both ends included.
+type AnalyticsQuery struct {
+	From   string
+	To     string
+	Period string
+	Limit  int
+}
+
+// PageVisit describes the request of a post page. It is only used to derive the visitor and the referring host, never stored.
+type PageVisit struct {
+	Address   string
+	UserAgent string
+	Referrer  string
+	Host      string
+}
+
+type ViewCount struct {
+	Start    time.Time `json:"start"`
+	Views    int       `json:"views"`
+	Visitors int       `json:"visitors"`
+}
+
+type PostViews struct {
+	URLHandle string `json:"urlHandle"`
+	Title     string `json:"title"`
+	Views     int    `json:"views"`
+	Visitors  int    `json:"visitors"`
+}
+
+type ReferrerViews struct {
+	Host     string `json:"host"`
+	Views    int    `json:"views"`
+	Visitors int    `json:"visitors"`
+}
+
+type AnalyticsStats struct {
+	Post         string          `json:"post,omitempty"`
+	From         time.Time       `json:"from"`
+	To           time.Time       `json:"to"`
+	Period       string          `json:"period"`
+	Views        int             `json:"views"`
+	Visitors     int             `json:"visitors"`
+	Series       []ViewCount     `json:"series"`
+	TopPosts     []PostViews     `json:"topPosts,omitempty"`
+	TopReferrers []ReferrerViews `json:"topReferrers"`
+}
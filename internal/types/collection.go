@@ -0,0 +1,12 @@
+package types
+
+type Collection struct {
+	URLHandle string `json:"urlHandle"`
+	Title     string `json:"title"`
+	Summary   string `json:"summary"`
+	Posts     []Post `json:"posts"`
+}
+
+type CollectionPostsInput struct {
+	Posts []string `json:"posts"`
+}
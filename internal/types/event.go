@@ -0,0 +1,64 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Names of the domain events.
+const (
+	EventPostCreated = "post.created"
+	EventPostUpdated = "post.updated"
+	EventPostDeleted = "post.deleted"
+	EventUserCreated = "user.created"
+)
+
+// DomainEvent describes a committed change of the blog, which is published to the subscribers of the event bus.
+type DomainEvent interface {
+	EventName() string
+}
+
+type PostCreatedEvent struct {
+	Post Post `json:"post"`
+}
+
+type PostUpdatedEvent struct {
+	Post Post `json:"post"`
+}
+
+type PostDeletedEvent struct {
+	Post Post `json:"post"`
+}
+
+type UserCreatedEvent struct {
+	User User `json:"user"`
+}
+
+func (e PostCreatedEvent) EventName() string { return EventPostCreated }
+func (e PostUpdatedEvent) EventName() string { return EventPostUpdated }
+func (e PostDeletedEvent) EventName() string { return EventPostDeleted }
+func (e UserCreatedEvent) EventName() string { return EventUserCreated }
+
+// DecodeEvent restores a domain event from its name and JSON payload, e.g. when reading it from the outbox.
+func DecodeEvent(name string, payload []byte) (DomainEvent, error) {
+	switch name {
+	case EventPostCreated:
+		var event PostCreatedEvent
+		err := json.Unmarshal(payload, &event)
+		return event, err
+	case EventPostUpdated:
+		var event PostUpdatedEvent
+		err := json.Unmarshal(payload, &event)
+		return event, err
+	case EventPostDeleted:
+		var event PostDeletedEvent
+		err := json.Unmarshal(payload, &event)
+		return event, err
+	case EventUserCreated:
+		var event UserCreatedEvent
+		err := json.Unmarshal(payload, &event)
+		return event, err
+	default:
+		return nil, fmt.Errorf("unknown event %s", name)
+	}
+}
@@ -0,0 +1,28 @@
+package types
+
+// Custom field types.
+const (
+	FieldTypeString = "string"
+	FieldTypeNumber = "number"
+	FieldTypeDate   = "date"
+	FieldTypeURL    = "url"
+	FieldTypeEnum   = "enum"
+)
+
+type FieldDefinition struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Required    bool     `json:"required"`
+	Options     []string `json:"options,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// IsValidFieldType checks whether the given type is one of the supported custom field types.
+func IsValidFieldType(fieldType string) bool {
+	switch fieldType {
+	case FieldTypeString, FieldTypeNumber, FieldTypeDate, FieldTypeURL, FieldTypeEnum:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,20 @@
+package types
+
+type ImportReport struct {
+	Created      []string      `json:"created"`
+	Updated      []string      `json:"updated"`
+	Skipped      []ImportIssue `json:"skipped"`
+	Conflicts    []ImportIssue `json:"conflicts,omitempty"`
+	CreatedUsers []string      `json:"createdUsers,omitempty"`
+}
+
+type ImportIssue struct {
+	Item   string `json:"item"`
+	Reason string `json:"reason"`
+}
+
+type BuildReport struct {
+	Written   []string `json:"written"`
+	Removed   []string `json:"removed"`
+	Unchanged int      `json:"unchanged"`
+}
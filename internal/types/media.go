@@ -0,0 +1,30 @@
+package types
+
+import "time"
+
+type Media struct {
+	ID           uint           `json:"id"`
+	FileName     string         `json:"fileName"`
+	ContentType  string         `json:"contentType"`
+	Size         int64          `json:"size"`
+	Width        int            `json:"width,omitempty"`
+	Height       int            `json:"height,omitempty"`
+	Checksum     string         `json:"checksum"`
+	Uploader     string         `json:"uploader"`
+	URL          string         `json:"url"`
+	Variants     []MediaVariant `json:"variants,omitempty"`
+	CreationTime time.Time      `json:"creationTime"`
+	StorageKey   string         `json:"-"`
+}
+
+type MediaVariant struct {
+	Name         string    `json:"name"`
+	ContentType  string    `json:"contentType"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	Size         int64     `json:"size"`
+	Checksum     string    `json:"checksum"`
+	URL          string    `json:"url"`
+	CreationTime time.Time `json:"creationTime"`
+	StorageKey   string    `json:"-"`
+}
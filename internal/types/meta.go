@@ -0,0 +1,37 @@
+package types
+
+type PostMeta struct {
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	URL         string      `json:"url"`
+	Image       string      `json:"image,omitempty"`
+	OpenGraph   []MetaTag   `json:"openGraph"`
+	TwitterCard []MetaTag   `json:"twitterCard"`
+	JSONLD      BlogPosting `json:"jsonLD"`
+}
+
+type MetaTag struct {
+	Property string `json:"property"`
+	Content  string `json:"content"`
+}
+
+type BlogPosting struct {
+	Context          string       `json:"@context"`
+	Type             string       `json:"@type"`
+	Headline         string       `json:"headline"`
+	Description      string       `json:"description,omitempty"`
+	Image            string       `json:"image,omitempty"`
+	DatePublished    string       `json:"datePublished"`
+	DateModified     string       `json:"dateModified"`
+	InLanguage       string       `json:"inLanguage,omitempty"`
+	Keywords         []string     `json:"keywords,omitempty"`
+	MainEntityOfPage string       `json:"mainEntityOfPage"`
+	Author           SchemaEntity `json:"author"`
+	Publisher        SchemaEntity `json:"publisher"`
+}
+
+type SchemaEntity struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
@@ -0,0 +1,50 @@
+package types
+
+// Actions of the Micropub endpoint. Requests without an action create a post.
+const (
+	MicropubActionUpdate = "update"
+	MicropubActionDelete = "delete"
+)
+
+// Publishing states of Micropub posts. Drafts are stored as private posts.
+const (
+	MicropubStatusPublished = "published"
+	MicropubStatusDraft     = "draft"
+)
+
+// MicropubEntry is a post in the Microformats2 JSON syntax, as it's created by and returned to Micropub clients.
+type MicropubEntry struct {
+	Type       []string                 `json:"type,omitempty"`
+	Properties map[string][]interface{} `json:"properties"`
+}
+
+// MicropubUpdate contains the changes of the properties of a post. Deleted properties are listed by name, deleted values
+// are grouped by property.
+type MicropubUpdate struct {
+	URL     string                   `json:"url"`
+	Replace map[string][]interface{} `json:"replace,omitempty"`
+	Add     map[string][]interface{} `json:"add,omitempty"`
+	Delete  interface{}              `json:"delete,omitempty"`
+}
+
+// MicropubRequest is a request of the Micropub endpoint, either creating a post or updating or deleting an existing one.
+type MicropubRequest struct {
+	MicropubEntry
+	MicropubUpdate
+	Action string `json:"action,omitempty"`
+}
+
+type MicropubConfig struct {
+	MediaEndpoint string   `json:"media-endpoint"`
+	SyndicateTo   []string `json:"syndicate-to"`
+	Q             []string `json:"q"`
+}
+
+type MicropubSyndicationTargets struct {
+	SyndicateTo []string `json:"syndicate-to"`
+}
+
+type MicropubError struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
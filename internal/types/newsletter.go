@@ -0,0 +1,17 @@
+package types
+
+// Delivery modes of the newsletter.
+const (
+	NewsletterPerPost = "post"
+	NewsletterDigest  = "digest"
+)
+
+type SubscriptionInput struct {
+	Email string `json:"email"`
+	Mode  string `json:"mode,omitempty"`
+}
+
+// IsValidNewsletterMode checks whether the given string is a supported newsletter delivery mode.
+func IsValidNewsletterMode(mode string) bool {
+	return mode == NewsletterPerPost || mode == NewsletterDigest
+}
@@ -2,11 +2,122 @@ package types
 
 import "time"
 
+// Contributor roles of a post.
+const (
+	ContributorRoleAuthor   = "author"
+	ContributorRoleEditor   = "editor"
+	ContributorRoleReviewer = "reviewer"
+)
+
+// Post visibility settings.
+const (
+	VisibilityPublic   = "public"
+	VisibilityUnlisted = "unlisted"
+	VisibilityPrivate  = "private"
+	VisibilityPassword = "password"
+)
+
 type Post struct {
-	URLHandle    string    `json:"urlHandle"`
-	Title        string    `json:"title"`
-	Author       string    `json:"author"`
-	Summary      string    `json:"summary"`
-	Body         string    `json:"body"`
-	CreationTime time.Time `json:"creationTime"`
+	URLHandle    string                 `json:"urlHandle"`
+	Title        string                 `json:"title"`
+	Author       string                 `json:"author"`
+	Contributors []Contributor          `json:"contributors,omitempty"`
+	Summary      string                 `json:"summary"`
+	Body         string                 `json:"body"`
+	Excerpt      string                 `json:"excerpt,omitempty"`
+	WordCount    int                    `json:"wordCount"`
+	ReadingTime  int                    `json:"readingTime"`
+	CreationTime time.Time              `json:"creationTime"`
+	UpdateTime   time.Time              `json:"updateTime"`
+	Language     string                 `json:"language,omitempty"`
+	Translations []AlternateLink        `json:"translations,omitempty"`
+	Visibility   string                 `json:"visibility,omitempty"`
+	Password     string                 `json:"password,omitempty"`
+	PasswordHash string                 `json:"-"`
+	CustomFields map[string]interface{} `json:"customFields,omitempty"`
+	Tags         []string               `json:"tags,omitempty"`
+	CoverImage   *string                `json:"coverImage,omitempty"`
+	Pinned       bool                   `json:"pinned,omitempty"`
+	Featured     bool                   `json:"featured,omitempty"`
+	SEO          *PostSEO               `json:"seo,omitempty"`
+	Series       *SeriesNavigation      `json:"series,omitempty"`
+	Reactions    map[string]int         `json:"reactions,omitempty"`
+	Related      []RelatedPost          `json:"related,omitempty"`
+}
+
+type RelatedPost struct {
+	URLHandle  string  `json:"urlHandle"`
+	Title      string  `json:"title"`
+	Summary    string  `json:"summary"`
+	CoverImage string  `json:"coverImage,omitempty"`
+	Score      float64 `json:"score"`
+}
+
+type PostSEO struct {
+	MetaDescription string `json:"metaDescription,omitempty"`
+	CanonicalURL    string `json:"canonicalURL,omitempty"`
+	NoIndex         bool   `json:"noIndex,omitempty"`
+}
+
+type PostFilter struct {
+	Language    string
+	Fields      map[string]string
+	Featured    bool
+	Collection  string
+	PinnedFirst bool
+}
+
+type PostHighlight struct {
+	Pinned   bool `json:"pinned"`
+	Featured bool `json:"featured"`
+}
+
+type PostTranslation struct {
+	Language string `json:"language"`
+	Title    string `json:"title"`
+	Summary  string `json:"summary"`
+	Body     string `json:"body"`
+}
+
+type AlternateLink struct {
+	HrefLang string `json:"hreflang"`
+	Href     string `json:"href"`
+}
+
+type PostAccess struct {
+	UserName    string
+	AccessToken string
+}
+
+type PostAccessInput struct {
+	Password string `json:"password"`
+}
+
+type Contributor struct {
+	UserName string `json:"userName"`
+	Role     string `json:"role"`
+}
+
+type PostContributorsInput struct {
+	Contributors []Contributor `json:"contributors"`
+}
+
+// IsValidContributorRole checks whether the given role is one of the supported contributor roles.
+func IsValidContributorRole(role string) bool {
+	switch role {
+	case ContributorRoleAuthor, ContributorRoleEditor, ContributorRoleReviewer:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsValidVisibility checks whether the given value is one of the supported post visibility settings.
+func IsValidVisibility(visibility string) bool {
+	switch visibility {
+	case VisibilityPublic, VisibilityUnlisted, VisibilityPrivate, VisibilityPassword:
+		return true
+	default:
+		return false
+	}
 }
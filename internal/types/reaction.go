@@ -0,0 +1,15 @@
+package types
+
+// PostReactions contains the number of reactions to a post by emoji and the emojis the current reader reacted with.
+type PostReactions struct {
+	Counts  map[string]int `json:"counts"`
+	Reacted []string       `json:"reacted"`
+}
+
+// ReactionReader identifies the reader reacting to a post. Anonymous readers are identified by their address and user
+// agent, which are only used to derive their fingerprint.
+type ReactionReader struct {
+	UserName  string
+	Address   string
+	UserAgent string
+}
@@ -0,0 +1,27 @@
+package types
+
+type Series struct {
+	URLHandle string `json:"urlHandle"`
+	Title     string `json:"title"`
+	Summary   string `json:"summary"`
+	Owner     string `json:"owner"`
+	Posts     []Post `json:"posts"`
+}
+
+type SeriesPostsInput struct {
+	Posts []string `json:"posts"`
+}
+
+type SeriesLink struct {
+	URLHandle string `json:"urlHandle"`
+	Title     string `json:"title"`
+}
+
+type SeriesNavigation struct {
+	URLHandle string      `json:"urlHandle"`
+	Title     string      `json:"title"`
+	Position  int         `json:"position"`
+	Total     int         `json:"total"`
+	Previous  *SeriesLink `json:"previous,omitempty"`
+	Next      *SeriesLink `json:"next,omitempty"`
+}
@@ -13,5 +13,6 @@ type UserUpdateInput struct {
 type User struct {
 	UserName     string   `json:"userName"`
 	PasswordHash string   `json:"-"`
+	Disabled     bool     `json:"disabled,omitempty"`
 	Posts        []string `json:"posts"`
 }
@@ -0,0 +1,50 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// States of webhook deliveries.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+type Webhook struct {
+	ID           uint      `json:"id"`
+	URL          string    `json:"url"`
+	Events       []string  `json:"events"`
+	Secret       string    `json:"secret,omitempty"`
+	CreationTime time.Time `json:"creationTime"`
+}
+
+type WebhookDelivery struct {
+	ID             uint            `json:"id"`
+	Event          string          `json:"event"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"responseStatus,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+	CreationTime   time.Time       `json:"creationTime"`
+	NextAttempt    *time.Time      `json:"nextAttempt,omitempty"`
+	DeliveryTime   *time.Time      `json:"deliveryTime,omitempty"`
+}
+
+type WebhookPayload struct {
+	Event     string      `json:"event"`
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+// IsValidWebhookEvent checks whether the given event is one of the events sent to webhooks.
+func IsValidWebhookEvent(event string) bool {
+	switch event {
+	case EventPostCreated, EventPostUpdated, EventPostDeleted, EventUserCreated:
+		return true
+	default:
+		return false
+	}
+}
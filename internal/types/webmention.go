@@ -0,0 +1,51 @@
+package types
+
+import "time"
+
+// States of received webmentions. Pending mentions wait for the verification of their source, verified ones for
+// moderation. Mentions whose source doesn't link to the target are invalid.
+const (
+	WebmentionPending  = "pending"
+	WebmentionVerified = "verified"
+	WebmentionApproved = "approved"
+	WebmentionRejected = "rejected"
+	WebmentionInvalid  = "invalid"
+)
+
+// Reaction types of webmentions, derived from the microformats classes of the link to the target.
+const (
+	ReactionMention  = "mention"
+	ReactionReply    = "reply"
+	ReactionLike     = "like"
+	ReactionRepost   = "repost"
+	ReactionBookmark = "bookmark"
+)
+
+type Webmention struct {
+	ID               uint       `json:"id"`
+	Post             string     `json:"post"`
+	Source           string     `json:"source"`
+	Target           string     `json:"target"`
+	Type             string     `json:"type"`
+	Status           string     `json:"status"`
+	AuthorName       string     `json:"authorName,omitempty"`
+	AuthorURL        string     `json:"authorURL,omitempty"`
+	Title            string     `json:"title,omitempty"`
+	Content          string     `json:"content,omitempty"`
+	CreationTime     time.Time  `json:"creationTime"`
+	VerificationTime *time.Time `json:"verificationTime,omitempty"`
+}
+
+type WebmentionModerationInput struct {
+	Status string `json:"status"`
+}
+
+// IsValidWebmentionStatus checks whether the given status is one of the states of received webmentions.
+func IsValidWebmentionStatus(status string) bool {
+	switch status {
+	case WebmentionPending, WebmentionVerified, WebmentionApproved, WebmentionRejected, WebmentionInvalid:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,291 @@
+package webmention
+
+import (
+	"github.com/wlchs/blog/internal/types"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxContentLength limits the length of the content extracted from the source of a mention.
+const maxContentLength = 500
+
+// maxFieldLength limits the length of the other details extracted from the source of a mention.
+const maxFieldLength = 255
+
+// reactionClasses maps the microformats classes of links to the reaction types of mentions.
+var reactionClasses = map[string]string{
+	"u-in-reply-to": types.ReactionReply,
+	"u-like-of":     types.ReactionLike,
+	"u-repost-of":   types.ReactionRepost,
+	"u-bookmark-of": types.ReactionBookmark,
+}
+
+// Source contains the details of a mention extracted from its source document.
+type Source struct {
+	Type       string
+	AuthorName string
+	AuthorURL  string
+	Title      string
+	Content    string
+}
+
+// Links returns the absolute HTTP(S) URLs linked from an HTML fragment, e.g. the body of a post, in order of appearance.
+func Links(fragment string) []string {
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return []string{}
+	}
+
+	links := make([]string, 0)
+	seen := map[string]bool{}
+	for _, node := range nodes {
+		walk(node, func(n *html.Node) {
+			if n.DataAtom != atom.A {
+				return
+			}
+			href, ok := attr(n, "href")
+			if !ok || seen[href] || !isAbsoluteURL(href) {
+				return
+			}
+			seen[href] = true
+			links = append(links, href)
+		})
+	}
+	return links
+}
+
+// DiscoverEndpoint finds the Webmention endpoint of a target using the Link header of its response, then the first
+// <link> or <a> element with rel="webmention" of its HTML document. Relative endpoints are resolved against the target.
+// An empty string is returned if the target has no endpoint.
+func DiscoverEndpoint(target *url.URL, header http.Header, body io.Reader) string {
+	for _, link := range header.Values("Link") {
+		if endpoint, ok := parseLinkHeader(link); ok {
+			return resolve(target, endpoint)
+		}
+	}
+
+	if !strings.HasPrefix(header.Get("Content-Type"), "text/html") {
+		return ""
+	}
+
+	doc, err := html.Parse(body)
+	if err != nil {
+		return ""
+	}
+
+	endpoint := ""
+	found := false
+	walk(doc, func(n *html.Node) {
+		if found || (n.DataAtom != atom.Link && n.DataAtom != atom.A) {
+			return
+		}
+		rel, _ := attr(n, "rel")
+		href, ok := attr(n, "href")
+		if ok && hasToken(rel, "webmention") {
+			endpoint, found = href, true
+		}
+	})
+	if !found {
+		return ""
+	}
+	return resolve(target, endpoint)
+}
+
+// ParseSource checks whether the HTML document of a source links to the target and extracts the details of the mention.
+// The type of the mention is derived from the microformats classes of the link, the author, the title and the content
+// are read from the h-entry of the source if it has one.
+func ParseSource(source *url.URL, body io.Reader, target string) (Source, bool) {
+	doc, err := html.Parse(body)
+	if err != nil {
+		return Source{}, false
+	}
+
+	mention := Source{Type: types.ReactionMention}
+	linked, authored := false, false
+	walk(doc, func(n *html.Node) {
+		if n.Type != html.ElementNode {
+			return
+		}
+
+		for _, name := range []string{"href", "src"} {
+			if value, ok := attr(n, name); ok && resolve(source, value) == target {
+				if !linked || mention.Type == types.ReactionMention {
+					mention.Type = reactionType(n)
+				}
+				linked = true
+			}
+		}
+
+		classes, _ := attr(n, "class")
+		switch {
+		case n.DataAtom == atom.Title && mention.Title == "":
+			mention.Title = text(n)
+		case n.DataAtom == atom.Meta && mention.AuthorName == "":
+			if name, _ := attr(n, "name"); name == "author" {
+				mention.AuthorName, _ = attr(n, "content")
+			}
+		}
+		if hasToken(classes, "p-author") && !authored {
+			mention.AuthorName, mention.AuthorURL = author(source, n)
+			authored = true
+		}
+		if (hasToken(classes, "e-content") || hasToken(classes, "p-content")) && mention.Content == "" {
+			mention.Content = truncate(text(n), maxContentLength)
+		}
+		if hasToken(classes, "h-entry") {
+			if name := findClass(n, "p-name"); name != nil {
+				mention.Title = text(name)
+			}
+		}
+	})
+
+	mention.AuthorName = truncate(mention.AuthorName, maxFieldLength)
+	mention.Title = truncate(mention.Title, maxFieldLength)
+	if len(mention.AuthorURL) > maxFieldLength {
+		mention.AuthorURL = ""
+	}
+	return mention, linked
+}
+
+// parseLinkHeader returns the URL of a Link header value whose relation contains "webmention".
+func parseLinkHeader(header string) (string, bool) {
+	for _, link := range strings.Split(header, ",") {
+		parts := strings.Split(link, ";")
+		target := strings.TrimSpace(parts[0])
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+		for _, param := range parts[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "rel") && hasToken(strings.Trim(value, `"`), "webmention") {
+				return target[1 : len(target)-1], true
+			}
+		}
+	}
+	return "", false
+}
+
+// reactionType returns the reaction type of a link based on its microformats classes.
+func reactionType(n *html.Node) string {
+	classes, _ := attr(n, "class")
+	for _, class := range strings.Fields(classes) {
+		if reaction, ok := reactionClasses[class]; ok {
+			return reaction
+		}
+	}
+	return types.ReactionMention
+}
+
+// author returns the name and the URL of the p-author element of an entry, which is usually an h-card.
+func author(source *url.URL, n *html.Node) (string, string) {
+	name := text(n)
+	if element := findClass(n, "p-name"); element != nil {
+		name = text(element)
+	}
+
+	link := n
+	if element := findClass(n, "u-url"); element != nil {
+		link = element
+	}
+	href, ok := attr(link, "href")
+	if !ok {
+		return name, ""
+	}
+	return name, resolve(source, href)
+}
+
+// findClass returns the first descendant of a node with the given class.
+// The descendants of nested microformats objects, e.g. the h-card of the author of an entry, are skipped.
+func findClass(n *html.Node, class string) *html.Node {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		classes, _ := attr(child, "class")
+		if hasToken(classes, class) {
+			return child
+		}
+		if isMicroformat(classes) {
+			continue
+		}
+		if found := findClass(child, class); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// isMicroformat checks whether the classes of an element contain the root class of a microformats object, e.g. h-card.
+func isMicroformat(classes string) bool {
+	for _, class := range strings.Fields(classes) {
+		if strings.HasPrefix(class, "h-") {
+			return true
+		}
+	}
+	return false
+}
+
+// walk calls the visitor with a node and its descendants in document order.
+func walk(n *html.Node, visit func(*html.Node)) {
+	visit(n)
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		walk(child, visit)
+	}
+}
+
+// attr returns the value of an attribute of a node.
+func attr(n *html.Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// text returns the text content of a node with collapsed whitespace.
+func text(n *html.Node) string {
+	var b strings.Builder
+	walk(n, func(c *html.Node) {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+			b.WriteString(" ")
+		}
+	})
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// truncate shortens a text to the given number of characters.
+func truncate(value string, length int) string {
+	if utf8.RuneCountInString(value) <= length {
+		return value
+	}
+	return strings.TrimSpace(string([]rune(value)[:length-1])) + "…"
+}
+
+// hasToken checks whether a space-separated list, e.g. a class or a rel attribute, contains the given token.
+func hasToken(list string, token string) bool {
+	for _, t := range strings.Fields(list) {
+		if strings.EqualFold(t, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve returns the absolute URL of a reference relative to the given base URL.
+func resolve(base *url.URL, ref string) string {
+	u, err := base.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return ""
+	}
+	return u.String()
+}
+
+// isAbsoluteURL checks whether the value is an absolute HTTP(S) URL.
+func isAbsoluteURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,126 @@
+package webmention_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/types"
+	"github.com/wlchs/blog/internal/webmention"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// target is the post mentioned by the test sources.
+const target = "https://blog.example.com/posts/hello"
+
+// TestLinks tests extracting the external links of a post body.
+func TestLinks(t *testing.T) {
+	t.Parallel()
+
+	body := `<p>See <a href="https://a.example.com/1">this</a>, <a href="/posts/other">that</a> and <a href="https://a.example.com/1">this again</a>.</p>
+<p><a href="mailto:jane@example.com">Mail</a> <img src="https://b.example.com/image.png"> <a href="http://b.example.com/2">Other</a></p>`
+
+	links := webmention.Links(body)
+
+	assert.Equal(t, []string{"https://a.example.com/1", "http://b.example.com/2"}, links, "only absolute HTTP(S) links should be returned once")
+}
+
+// TestDiscoverEndpoint tests discovering the endpoint of a target from the Link header and the HTML document.
+func TestDiscoverEndpoint(t *testing.T) {
+	t.Parallel()
+
+	u, _ := url.Parse("https://remote.example.com/posts/1")
+
+	cases := map[string]struct {
+		header   http.Header
+		body     string
+		expected string
+	}{
+		"link header": {
+			header:   http.Header{"Link": {`<https://remote.example.com/other>; rel="other", </webmention>; rel="webmention"`}},
+			expected: "https://remote.example.com/webmention",
+		},
+		"link element": {
+			header:   http.Header{"Content-Type": {"text/html; charset=utf-8"}},
+			body:     `<html><head><link rel="stylesheet" href="/style.css"><link rel="webmention" href="endpoint?version=1"></head></html>`,
+			expected: "https://remote.example.com/posts/endpoint?version=1",
+		},
+		"anchor element": {
+			header:   http.Header{"Content-Type": {"text/html"}},
+			body:     `<body><a rel="me webmention" href="https://mentions.example.com/remote">Webmentions</a></body>`,
+			expected: "https://mentions.example.com/remote",
+		},
+		"empty href": {
+			header:   http.Header{"Content-Type": {"text/html"}},
+			body:     `<link rel="webmention" href="">`,
+			expected: "https://remote.example.com/posts/1",
+		},
+		"missing": {
+			header:   http.Header{"Content-Type": {"text/html"}},
+			body:     `<link rel="pingback" href="/xmlrpc.php">`,
+			expected: "",
+		},
+		"not html": {
+			header:   http.Header{"Content-Type": {"application/json"}},
+			body:     `<link rel="webmention" href="/webmention">`,
+			expected: "",
+		},
+	}
+
+	for name, c := range cases {
+		endpoint := webmention.DiscoverEndpoint(u, c.header, strings.NewReader(c.body))
+
+		assert.Equal(t, c.expected, endpoint, "incorrect endpoint discovered from %s", name)
+	}
+}
+
+// TestParseSource tests extracting the details of a reply from an h-entry.
+func TestParseSource(t *testing.T) {
+	t.Parallel()
+
+	u, _ := url.Parse("https://remote.example.com/notes/1")
+	body := `<html><head><title>Note | Remote</title><meta name="author" content="Site Owner"></head><body>
+<article class="h-entry">
+<a class="p-author h-card" href="/about"><span class="p-name">Alice</span></a>
+<h1 class="p-name">Re: Hello</h1>
+<p>In reply to <a class="u-in-reply-to" href="https://blog.example.com/posts/hello">Hello</a></p>
+<div class="e-content"><p>Great   post!</p></div>
+</article></body></html>`
+
+	source, linked := webmention.ParseSource(u, strings.NewReader(body), target)
+
+	assert.True(t, linked, "the source should link to the target")
+	assert.Equal(t, webmention.Source{
+		Type:       types.ReactionReply,
+		AuthorName: "Alice",
+		AuthorURL:  "https://remote.example.com/about",
+		Title:      "Re: Hello",
+		Content:    "Great post!",
+	}, source, "incorrect source details")
+}
+
+// TestParseSource_Plain_Mention tests extracting the details of a page without microformats.
+func TestParseSource_Plain_Mention(t *testing.T) {
+	t.Parallel()
+
+	u, _ := url.Parse("https://remote.example.com/blog/")
+	body := `<html><head><title>Links</title><meta name="author" content="Bob"></head>
+<body><p>Read <a href="../posts/hello">this</a> and <a href="https://blog.example.com/posts/hello">this</a>.</p></body></html>`
+
+	source, linked := webmention.ParseSource(u, strings.NewReader(body), target)
+
+	assert.True(t, linked, "the source should link to the target")
+	assert.Equal(t, webmention.Source{Type: types.ReactionMention, AuthorName: "Bob", Title: "Links"}, source, "incorrect source details")
+}
+
+// TestParseSource_Not_Linked tests verifying a source which doesn't link to the target.
+func TestParseSource_Not_Linked(t *testing.T) {
+	t.Parallel()
+
+	u, _ := url.Parse("https://remote.example.com/notes/1")
+	body := `<p><a href="https://blog.example.com/posts/hello-world">Hello world</a> <a href="https://blog.example.com/posts/hello#comments">Comments</a></p>`
+
+	_, linked := webmention.ParseSource(u, strings.NewReader(body), target)
+
+	assert.False(t, linked, "the source shouldn't link to the target")
+}
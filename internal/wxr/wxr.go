@@ -0,0 +1,144 @@
+package wxr
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"time"
+)
+
+// dateFormat is the format of the post dates of the export.
+const dateFormat = "2006-01-02 15:04:05"
+
+// contentNamespace is the namespace of the content:encoded elements holding the body of the posts.
+const contentNamespace = "http://purl.org/rss/1.0/modules/content/"
+
+// Export is the content of a WordPress eXtended RSS (WXR) export file.
+type Export struct {
+	Title    string
+	Language string
+	Authors  []Author
+	Items    []Item
+}
+
+// Author is a wp:author entry of the export.
+type Author struct {
+	Login       string `xml:"author_login"`
+	Email       string `xml:"author_email"`
+	DisplayName string `xml:"author_display_name"`
+}
+
+// Item is a post, page, attachment or any other item of the export.
+type Item struct {
+	ID       string
+	Title    string
+	Creator  string
+	Content  string
+	Excerpt  string
+	Date     string
+	DateGMT  string
+	Name     string
+	Status   string
+	Type     string
+	Password string
+	Tags     []string
+}
+
+// rss is the document structure of the export file.
+// WordPress uses different namespace versions for its elements, so they are matched by their local names.
+// The only exception are the content:encoded and excerpt:encoded elements, sharing the same local name.
+type rss struct {
+	Channel struct {
+		Title    string   `xml:"title"`
+		Language string   `xml:"language"`
+		Authors  []Author `xml:"author"`
+		Items    []item   `xml:"item"`
+	} `xml:"channel"`
+}
+
+// item is an element of the channel.
+type item struct {
+	ID         string     `xml:"post_id"`
+	Title      string     `xml:"title"`
+	Creator    string     `xml:"creator"`
+	Encoded    []encoded  `xml:"encoded"`
+	Date       string     `xml:"post_date"`
+	DateGMT    string     `xml:"post_date_gmt"`
+	Name       string     `xml:"post_name"`
+	Status     string     `xml:"status"`
+	Type       string     `xml:"post_type"`
+	Password   string     `xml:"post_password"`
+	Categories []category `xml:"category"`
+}
+
+// encoded is a content:encoded or excerpt:encoded element.
+type encoded struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+// category is a category or tag assigned to an item.
+type category struct {
+	Domain   string `xml:"domain,attr"`
+	NiceName string `xml:"nicename,attr"`
+	Value    string `xml:",chardata"`
+}
+
+// Parse reads a WordPress export file.
+func Parse(r io.Reader) (*Export, error) {
+	var document rss
+	if err := xml.NewDecoder(r).Decode(&document); err != nil {
+		return nil, err
+	}
+
+	export := Export{
+		Title:    strings.TrimSpace(document.Channel.Title),
+		Language: strings.TrimSpace(document.Channel.Language),
+		Authors:  document.Channel.Authors,
+		Items:    make([]Item, 0, len(document.Channel.Items)),
+	}
+
+	for _, i := range document.Channel.Items {
+		result := Item{
+			ID:       strings.TrimSpace(i.ID),
+			Title:    strings.TrimSpace(i.Title),
+			Creator:  strings.TrimSpace(i.Creator),
+			Date:     strings.TrimSpace(i.Date),
+			DateGMT:  strings.TrimSpace(i.DateGMT),
+			Name:     strings.TrimSpace(i.Name),
+			Status:   strings.TrimSpace(i.Status),
+			Type:     strings.TrimSpace(i.Type),
+			Password: i.Password,
+		}
+
+		for _, e := range i.Encoded {
+			if e.XMLName.Space == contentNamespace {
+				result.Content = e.Value
+			} else if strings.Contains(e.XMLName.Space, "/excerpt/") {
+				result.Excerpt = e.Value
+			}
+		}
+
+		for _, c := range i.Categories {
+			if c.Domain == "post_tag" {
+				result.Tags = append(result.Tags, strings.TrimSpace(c.Value))
+			}
+		}
+
+		export.Items = append(export.Items, result)
+	}
+
+	return &export, nil
+}
+
+// PublicationTime returns the publication time of the item.
+// The GMT date is preferred, since the local date of the blog doesn't contain its time zone.
+// Items which were never published have no GMT date, in this case the local date is interpreted as UTC.
+func (i Item) PublicationTime() (time.Time, bool) {
+	for _, date := range []string{i.DateGMT, i.Date} {
+		if t, err := time.Parse(dateFormat, date); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,107 @@
+package wxr_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wlchs/blog/internal/wxr"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testExport is a trimmed WordPress export containing an author, a published post and an attachment.
+const testExport = `<?xml version="1.0" encoding="UTF-8" ?>
+<rss version="2.0"
+	xmlns:excerpt="http://wordpress.org/export/1.2/excerpt/"
+	xmlns:content="http://purl.org/rss/1.0/modules/content/"
+	xmlns:dc="http://purl.org/dc/elements/1.1/"
+	xmlns:wp="http://wordpress.org/export/1.2/">
+<channel>
+	<title>Test blog</title>
+	<language>en-US</language>
+	<wp:author>
+		<wp:author_id>1</wp:author_id>
+		<wp:author_login><![CDATA[jane]]></wp:author_login>
+		<wp:author_email><![CDATA[jane@example.com]]></wp:author_email>
+		<wp:author_display_name><![CDATA[Jane Doe]]></wp:author_display_name>
+	</wp:author>
+	<item>
+		<title>Hello world</title>
+		<dc:creator><![CDATA[jane]]></dc:creator>
+		<content:encoded><![CDATA[<p>Welcome!</p>]]></content:encoded>
+		<excerpt:encoded><![CDATA[The first post]]></excerpt:encoded>
+		<wp:post_id>1</wp:post_id>
+		<wp:post_date><![CDATA[2019-03-01 10:00:00]]></wp:post_date>
+		<wp:post_date_gmt><![CDATA[2019-03-01 09:00:00]]></wp:post_date_gmt>
+		<wp:post_name><![CDATA[hello-world]]></wp:post_name>
+		<wp:status><![CDATA[publish]]></wp:status>
+		<wp:post_type><![CDATA[post]]></wp:post_type>
+		<wp:post_password><![CDATA[]]></wp:post_password>
+		<category domain="category" nicename="news"><![CDATA[News]]></category>
+		<category domain="post_tag" nicename="go"><![CDATA[Go]]></category>
+	</item>
+	<item>
+		<title>logo.png</title>
+		<wp:post_id>2</wp:post_id>
+		<wp:post_date><![CDATA[2019-03-02 10:00:00]]></wp:post_date>
+		<wp:post_date_gmt><![CDATA[0000-00-00 00:00:00]]></wp:post_date_gmt>
+		<wp:post_type><![CDATA[attachment]]></wp:post_type>
+	</item>
+</channel>
+</rss>`
+
+// TestParse tests reading the authors and items of a WordPress export.
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	export, err := wxr.Parse(strings.NewReader(testExport))
+
+	assert.Nil(t, err, "should complete without error")
+	assert.Equal(t, "Test blog", export.Title, "title doesn't match")
+	assert.Equal(t, "en-US", export.Language, "language doesn't match")
+	assert.Equal(t, []wxr.Author{{Login: "jane", Email: "jane@example.com", DisplayName: "Jane Doe"}}, export.Authors, "authors don't match")
+
+	expectedPost := wxr.Item{
+		ID:      "1",
+		Title:   "Hello world",
+		Creator: "jane",
+		Content: "<p>Welcome!</p>",
+		Excerpt: "The first post",
+		Date:    "2019-03-01 10:00:00",
+		DateGMT: "2019-03-01 09:00:00",
+		Name:    "hello-world",
+		Status:  "publish",
+		Type:    "post",
+		Tags:    []string{"Go"},
+	}
+	assert.Equal(t, 2, len(export.Items), "didn't receive the expected number of items")
+	assert.Equal(t, expectedPost, export.Items[0], "post doesn't match")
+	assert.Equal(t, "attachment", export.Items[1].Type, "attachment should be parsed")
+}
+
+// TestParse_Invalid tests reading a malformed export file.
+func TestParse_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := wxr.Parse(strings.NewReader("<rss><channel>"))
+
+	assert.NotNil(t, err, "should return an error")
+}
+
+// TestItem_PublicationTime tests preferring the GMT publication date and falling back to the local one.
+func TestItem_PublicationTime(t *testing.T) {
+	t.Parallel()
+
+	published := wxr.Item{Date: "2019-03-01 10:00:00", DateGMT: "2019-03-01 09:00:00"}
+	draft := wxr.Item{Date: "2019-03-02 10:00:00", DateGMT: "0000-00-00 00:00:00"}
+
+	publicationTime, ok := published.PublicationTime()
+	assert.True(t, ok, "publication time should be found")
+	assert.Equal(t, time.Date(2019, 3, 1, 9, 0, 0, 0, time.UTC), publicationTime, "GMT date should be preferred")
+
+	publicationTime, ok = draft.PublicationTime()
+	assert.True(t, ok, "publication time should be found")
+	assert.Equal(t, time.Date(2019, 3, 2, 10, 0, 0, 0, time.UTC), publicationTime, "local date should be used")
+
+	_, ok = wxr.Item{}.PublicationTime()
+	assert.False(t, ok, "missing dates should be reported")
+}